@@ -0,0 +1,150 @@
+// Copyright 2025 CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"cuelang.org/go/cue/errors"
+	"cuelang.org/go/cue/token"
+)
+
+// writeGitHubAnnotations writes err to w as GitHub Actions workflow
+// commands, which show each error as an annotation on the lines of the
+// files it refers to
+// (https://docs.github.com/en/actions/reference/workflow-commands-for-github-actions).
+// An error is annotated at each of its positions, so that it shows up
+// on the lines which changed, whether in a schema or in data.
+func writeGitHubAnnotations(w io.Writer, err error, severity string, cfg *errors.Config) {
+	for _, e := range errors.Errors(errors.Sanitize(errorList(errors.Errors(err)))) {
+		title := "title=" + escapeGitHubProperty(diagnosticRuleFor(e).description)
+		msg := escapeGitHubData(errors.String(e))
+		positions := filePositions(e)
+		if len(positions) == 0 {
+			fmt.Fprintf(w, "::%s %s::%s\n", severity, title, msg)
+		}
+		for _, p := range positions {
+			file := "file=" + escapeGitHubProperty(filepath.ToSlash(diagnosticFilename(p.Filename, cfg)))
+			fmt.Fprintf(w, "::%s %s,line=%d,col=%d,%s::%s\n", severity, file, p.Line, p.Column, title, msg)
+		}
+	}
+}
+
+var (
+	gitHubDataEscaper     = strings.NewReplacer("%", "%25", "\r", "%0D", "\n", "%0A")
+	gitHubPropertyEscaper = strings.NewReplacer("%", "%25", "\r", "%0D", "\n", "%0A", ":", "%3A", ",", "%2C")
+)
+
+func escapeGitHubData(s string) string     { return gitHubDataEscaper.Replace(s) }
+func escapeGitHubProperty(s string) string { return gitHubPropertyEscaper.Replace(s) }
+
+// The types below model the GitLab code quality report format
+// (https://docs.gitlab.com/ci/testing/code_quality/#code-quality-report-format).
+
+type gitLabIssue struct {
+	Description string         `json:"description"`
+	CheckName   string         `json:"check_name"`
+	Fingerprint string         `json:"fingerprint"`
+	Severity    string         `json:"severity"`
+	Location    gitLabLocation `json:"location"`
+}
+
+type gitLabLocation struct {
+	Path  string      `json:"path"`
+	Lines gitLabLines `json:"lines"`
+}
+
+type gitLabLines struct {
+	Begin int `json:"begin"`
+}
+
+// writeGitLabCodeQuality writes errs and warnings to w as a GitLab code
+// quality report, which shows them in merge requests. Errors have
+// severity major and warnings minor. As with annotations for GitHub, an
+// issue is reported for each position of an error.
+func writeGitLabCodeQuality(w io.Writer, errs, warnings []errors.Error, cfg *errors.Config) error {
+	issues := []gitLabIssue{}
+	seen := map[string]bool{}
+	add := func(err errors.Error, severity string) {
+		positions := filePositions(err)
+		if len(positions) == 0 {
+			// GitLab requires a location.
+			positions = []token.Position{{Line: 1}}
+		}
+		for _, p := range positions {
+			issue := gitLabIssue{
+				Description: errors.String(err),
+				CheckName:   diagnosticRuleFor(err).id,
+				Severity:    severity,
+				Location: gitLabLocation{
+					Lines: gitLabLines{Begin: p.Line},
+				},
+			}
+			if p.Filename != "" {
+				issue.Location.Path = filepath.ToSlash(diagnosticFilename(p.Filename, cfg))
+			}
+			// GitLab requires fingerprints to be unique within a report,
+			// and uses them to track issues between reports, so they
+			// should not depend on the line number.
+			h := sha256.New()
+			fmt.Fprintf(h, "%s\x00%s\x00%s", issue.CheckName, issue.Location.Path, issue.Description)
+			for i := 0; ; i++ {
+				fp := hex.EncodeToString(h.Sum(nil))
+				if !seen[fp] {
+					seen[fp] = true
+					issue.Fingerprint = fp
+					break
+				}
+				fmt.Fprintf(h, "\x00%d", i)
+			}
+			issues = append(issues, issue)
+		}
+	}
+	for _, err := range errs {
+		add(err, "major")
+	}
+	for _, err := range warnings {
+		add(err, "minor")
+	}
+	enc := json.NewEncoder(w)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", "  ")
+	return enc.Encode(issues)
+}
+
+// filePositions returns the valid positions of err within files, without
+// duplicate lines.
+func filePositions(err errors.Error) []token.Position {
+	var positions []token.Position
+	for _, pos := range errors.Positions(err) {
+		p := pos.Position()
+		if p.Filename == "" || !p.IsValid() {
+			continue
+		}
+		if !slices.ContainsFunc(positions, func(q token.Position) bool {
+			return q.Filename == p.Filename && q.Line == p.Line
+		}) {
+			positions = append(positions, p)
+		}
+	}
+	return positions
+}
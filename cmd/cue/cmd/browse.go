@@ -0,0 +1,554 @@
+// Copyright 2025 The CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"cuelang.org/go/cue"
+	"cuelang.org/go/cue/parser"
+	"cuelang.org/go/cue/token"
+	"cuelang.org/go/internal/core/adt"
+	"cuelang.org/go/internal/filetypes"
+	"cuelang.org/go/internal/value"
+)
+
+func newBrowseCmd(c *Command) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "browse [inputs]",
+		Short: "interactively browse an evaluated configuration",
+		Long: `browse evaluates a configuration and shows it as a tree in the terminal,
+in which fields can be expanded and collapsed, searched for by path, and
+traced back to the source positions of the values which define them.
+
+The following keys are supported:
+
+	up, k          move to the previous field
+	down, j        move to the next field
+	right, l       expand a struct or list
+	left, h        collapse a struct or list, or move to its parent
+	enter, space   expand or collapse a struct or list
+	g, G           move to the first or last field
+	/              search for a field whose path contains the given text
+	n              move to the next field matching the search
+	s              show or hide the source positions of the current field
+	y              copy the path of the current field to the clipboard
+	q, ctrl-c      quit
+
+Paths are copied with the OSC 52 terminal escape sequence, which is
+supported by most terminal emulators.
+
+If standard input or output is not a terminal, browse reads keys from
+standard input and writes the final screen to standard output, which
+can be used to script browsing. Newlines are read as the enter key,
+except for a final newline.
+`,
+		RunE: mkRunE(c, runBrowse),
+	}
+
+	addOrphanFlags(cmd.Flags())
+	addInjectionFlags(cmd.Flags(), false, false)
+
+	cmd.Flags().StringArrayP(string(flagExpression), "e", nil, "browse this expression only")
+	return cmd
+}
+
+const (
+	// Size of the screen when browsing without a terminal.
+	browseWidth  = 80
+	browseHeight = 24
+)
+
+func runBrowse(cmd *Command, args []string) error {
+	b, err := parseArgs(cmd, args, &config{mode: filetypes.Input})
+	if err != nil {
+		return err
+	}
+	iter := b.instances()
+	defer iter.close()
+	if !iter.scan() {
+		if err := iter.err(); err != nil {
+			return err
+		}
+		return fmt.Errorf("no value to browse")
+	}
+	v, id := iter.value(), iter.id()
+	if iter.scan() {
+		return fmt.Errorf("browse supports a single instance")
+	}
+	if err := iter.err(); err != nil {
+		return err
+	}
+	switch exprs := flagExpression.StringArray(cmd); len(exprs) {
+	case 0:
+	case 1:
+		expr, err := parser.ParseExpr("--expression", exprs[0])
+		if err != nil {
+			return err
+		}
+		v = v.Context().BuildExpr(expr,
+			cue.Scope(v),
+			cue.InferBuiltins(true),
+			cue.ImportPath(id),
+		)
+	default:
+		return fmt.Errorf("browse supports a single expression")
+	}
+
+	br := newBrowser(v)
+	stdin, stdout := cmd.InOrStdin(), cmd.OutOrStdout()
+	in, inOK := stdin.(*os.File)
+	out, outOK := stdout.(*os.File)
+	if !inOK || !outOK || !isTerminal(in) || !isTerminal(out) {
+		keys, err := io.ReadAll(stdin)
+		if err != nil {
+			return err
+		}
+		// Files of keys usually end with a newline, which is not meant
+		// as a key press.
+		keys = bytes.TrimSuffix(keys, []byte("\n"))
+		for _, k := range parseKeys(keys) {
+			if !br.key(k) {
+				break
+			}
+		}
+		for _, line := range br.render(browseWidth, browseHeight) {
+			fmt.Fprintln(stdout, strings.TrimRight(line, " "))
+		}
+		return nil
+	}
+	return br.run(in, out)
+}
+
+// run browses interactively in the terminal with the given input and
+// output.
+func (br *browser) run(in, out *os.File) error {
+	restore, err := makeRaw(int(in.Fd()))
+	if err != nil {
+		return err
+	}
+	defer restore()
+
+	// Use the alternate screen and hide the cursor while browsing.
+	fmt.Fprint(out, "\x1b[?1049h\x1b[?25l")
+	defer fmt.Fprint(out, "\x1b[?25h\x1b[?1049l")
+
+	buf := make([]byte, 64)
+	for {
+		width, height, err := terminalSize(int(out.Fd()))
+		if err != nil {
+			width, height = browseWidth, browseHeight
+		}
+		var sb strings.Builder
+		sb.WriteString("\x1b[H\x1b[2J")
+		sb.WriteString(strings.Join(br.render(width, height), "\r\n"))
+		if br.clipboard != "" {
+			// OSC 52 sets the clipboard of the terminal.
+			fmt.Fprintf(&sb, "\x1b]52;c;%s\x07", base64.StdEncoding.EncodeToString([]byte(br.clipboard)))
+			br.clipboard = ""
+		}
+		if _, err := io.WriteString(out, sb.String()); err != nil {
+			return err
+		}
+
+		n, err := in.Read(buf)
+		if err != nil {
+			return err
+		}
+		for _, k := range parseKeys(buf[:n]) {
+			if !br.key(k) {
+				return nil
+			}
+		}
+	}
+}
+
+// Keys which are not a single printable character.
+const (
+	keyUp        = "up"
+	keyDown      = "down"
+	keyLeft      = "left"
+	keyRight     = "right"
+	keyEnter     = "enter"
+	keyEscape    = "esc"
+	keyBackspace = "backspace"
+	keyInterrupt = "ctrl-c"
+)
+
+// parseKeys splits the bytes read from a terminal into keys.
+func parseKeys(b []byte) []string {
+	var keys []string
+	for s := string(b); s != ""; {
+		switch {
+		case strings.HasPrefix(s, "\x1b[") && len(s) >= 3:
+			switch s[2] {
+			case 'A':
+				keys = append(keys, keyUp)
+			case 'B':
+				keys = append(keys, keyDown)
+			case 'C':
+				keys = append(keys, keyRight)
+			case 'D':
+				keys = append(keys, keyLeft)
+			}
+			s = s[3:]
+			continue
+		case s[0] == '\x1b':
+			keys = append(keys, keyEscape)
+		case s[0] == '\r' || s[0] == '\n':
+			keys = append(keys, keyEnter)
+		case s[0] == 0x7f || s[0] == '\b':
+			keys = append(keys, keyBackspace)
+		case s[0] == 0x03:
+			keys = append(keys, keyInterrupt)
+		default:
+			r := []rune(s)[0]
+			keys = append(keys, string(r))
+			s = s[len(string(r)):]
+			continue
+		}
+		s = s[1:]
+	}
+	return keys
+}
+
+// A browser holds the state of browsing a value.
+type browser struct {
+	root   *browseNode
+	cursor *browseNode
+
+	// top is the index of the first visible row.
+	top int
+
+	showSources bool
+
+	// searching is set while a search query is being typed.
+	searching bool
+	query     string
+
+	// message is shown in the status line until the next key.
+	message string
+
+	// clipboard holds text to be copied to the clipboard of the terminal.
+	clipboard string
+}
+
+// A browseNode is a field, or the root value, in the browsed tree.
+type browseNode struct {
+	v        cue.Value
+	parent   *browseNode
+	label    string
+	depth    int
+	expanded bool
+
+	// children is only set once the node is loaded, so that only the
+	// values which are shown are evaluated.
+	loaded   bool
+	children []*browseNode
+}
+
+func newBrowser(v cue.Value) *browser {
+	root := &browseNode{v: v, label: "(root)", expanded: true}
+	br := &browser{root: root, cursor: root}
+	if kids := root.kids(); len(kids) > 0 {
+		br.cursor = kids[0]
+	}
+	return br
+}
+
+// kids returns the children of n, loading them if needed.
+func (n *browseNode) kids() []*browseNode {
+	if n.loaded {
+		return n.children
+	}
+	n.loaded = true
+	switch n.v.IncompleteKind() {
+	case cue.StructKind:
+		iter, err := n.v.Fields(cue.Definitions(true), cue.Optional(true), cue.Hidden(true))
+		if err != nil {
+			return nil
+		}
+		for iter.Next() {
+			n.children = append(n.children, &browseNode{
+				v:      iter.Value(),
+				parent: n,
+				label:  iter.Selector().String(),
+				depth:  n.depth + 1,
+			})
+		}
+	case cue.ListKind:
+		iter, err := n.v.List()
+		if err != nil {
+			return nil
+		}
+		for i := 0; iter.Next(); i++ {
+			n.children = append(n.children, &browseNode{
+				v:      iter.Value(),
+				parent: n,
+				label:  fmt.Sprintf("[%d]", i),
+				depth:  n.depth + 1,
+			})
+		}
+	}
+	return n.children
+}
+
+// isComposite reports whether n is a struct or list which can be expanded.
+func (n *browseNode) isComposite() bool {
+	switch n.v.IncompleteKind() {
+	case cue.StructKind, cue.ListKind:
+		return n.v.Err() == nil
+	}
+	return false
+}
+
+// rows returns the visible nodes below the root, in order, or the root
+// itself if it is not a struct or list.
+func (br *browser) rows() []*browseNode {
+	if !br.root.isComposite() {
+		return []*browseNode{br.root}
+	}
+	var rows []*browseNode
+	var add func(n *browseNode)
+	add = func(n *browseNode) {
+		for _, k := range n.kids() {
+			rows = append(rows, k)
+			if k.expanded {
+				add(k)
+			}
+		}
+	}
+	add(br.root)
+	return rows
+}
+
+// key handles a key press, reporting whether to continue browsing.
+func (br *browser) key(k string) bool {
+	br.message = ""
+	if br.searching {
+		switch k {
+		case keyEnter:
+			br.searching = false
+			br.search()
+		case keyEscape, keyInterrupt:
+			br.searching = false
+			br.query = ""
+		case keyBackspace:
+			if br.query != "" {
+				r := []rune(br.query)
+				br.query = string(r[:len(r)-1])
+			}
+		default:
+			if len([]rune(k)) == 1 {
+				br.query += k
+			}
+		}
+		return true
+	}
+
+	rows := br.rows()
+	i := indexOf(rows, br.cursor)
+	switch k {
+	case "q", keyInterrupt:
+		return false
+	case "j", keyDown:
+		if i+1 < len(rows) {
+			br.cursor = rows[i+1]
+		}
+	case "k", keyUp:
+		if i > 0 {
+			br.cursor = rows[i-1]
+		}
+	case "g":
+		if len(rows) > 0 {
+			br.cursor = rows[0]
+		}
+	case "G":
+		if len(rows) > 0 {
+			br.cursor = rows[len(rows)-1]
+		}
+	case "l", keyRight:
+		if br.cursor.isComposite() {
+			br.cursor.expanded = true
+		}
+	case "h", keyLeft:
+		switch {
+		case br.cursor.expanded:
+			br.cursor.expanded = false
+		case br.cursor.parent != br.root && br.cursor.parent != nil:
+			br.cursor = br.cursor.parent
+		}
+	case " ", keyEnter:
+		if br.cursor.isComposite() {
+			br.cursor.expanded = !br.cursor.expanded
+		}
+	case "/":
+		br.searching = true
+		br.query = ""
+	case "n":
+		br.search()
+	case "s":
+		br.showSources = !br.showSources
+	case "y":
+		p := br.cursor.v.Path().String()
+		br.clipboard = p
+		br.message = "copied " + p
+	}
+	return true
+}
+
+func indexOf(rows []*browseNode, n *browseNode) int {
+	for i, r := range rows {
+		if r == n {
+			return i
+		}
+	}
+	return -1
+}
+
+// search moves the cursor to the next field, in depth-first order and
+// after the cursor, whose path contains the query. The fields of
+// collapsed structs and lists are searched too, which evaluates them.
+func (br *browser) search() {
+	if br.query == "" {
+		return
+	}
+	var all []*browseNode
+	var add func(n *browseNode)
+	add = func(n *browseNode) {
+		for _, k := range n.kids() {
+			all = append(all, k)
+			add(k)
+		}
+	}
+	add(br.root)
+
+	start := indexOf(all, br.cursor)
+	for j := 1; j <= len(all); j++ {
+		n := all[(start+j)%len(all)]
+		if strings.Contains(n.v.Path().String(), br.query) {
+			for p := n.parent; p != nil; p = p.parent {
+				p.expanded = true
+			}
+			br.cursor = n
+			return
+		}
+	}
+	br.message = fmt.Sprintf("no field matches %q", br.query)
+}
+
+// render returns the lines of the screen for the given size.
+func (br *browser) render(width, height int) []string {
+	var footer []string
+	if br.showSources {
+		footer = append(footer, strings.Repeat("─", width))
+		footer = append(footer, sourcePositions(br.cursor.v)...)
+	}
+	status := br.cursor.v.Path().String()
+	switch {
+	case br.searching:
+		status = "/" + br.query
+	case br.message != "":
+		status = br.message
+	case status == "":
+		status = "(root)"
+	}
+	footer = append(footer, status)
+
+	treeHeight := max(height-len(footer), 1)
+	rows := br.rows()
+	i := indexOf(rows, br.cursor)
+	if i < br.top {
+		br.top = i
+	} else if i >= br.top+treeHeight {
+		br.top = i - treeHeight + 1
+	}
+	br.top = max(br.top, 0)
+
+	var lines []string
+	for _, n := range rows[br.top:min(br.top+treeHeight, len(rows))] {
+		prefix := "  "
+		if n == br.cursor {
+			prefix = "> "
+		}
+		lines = append(lines, truncate(prefix+strings.Repeat("  ", max(n.depth-1, 0))+n.summary(), width))
+	}
+	for len(lines) < treeHeight {
+		lines = append(lines, "")
+	}
+	for _, l := range footer {
+		lines = append(lines, truncate(l, width))
+	}
+	return lines
+}
+
+// summary returns the line describing n in the tree.
+func (n *browseNode) summary() string {
+	switch {
+	case !n.isComposite():
+		s := strings.Join(strings.Fields(fmt.Sprint(n.v)), " ")
+		return fmt.Sprintf("  %s: %s", n.label, s)
+	case n.expanded:
+		return fmt.Sprintf("▾ %s", n.label)
+	case n.v.IncompleteKind() == cue.ListKind:
+		return fmt.Sprintf("▸ %s: [%d elements]", n.label, len(n.kids()))
+	}
+	return fmt.Sprintf("▸ %s: {%d fields}", n.label, len(n.kids()))
+}
+
+func truncate(s string, width int) string {
+	r := []rune(s)
+	if len(r) <= width {
+		return s
+	}
+	if width < 1 {
+		return ""
+	}
+	return string(r[:width-1]) + "…"
+}
+
+// sourcePositions returns the positions of the conjuncts of v, relative
+// to the current directory.
+func sourcePositions(v cue.Value) []string {
+	_, vx := value.ToInternal(v)
+	var positions []string
+	seen := map[token.Pos]bool{}
+	vx.VisitLeafConjuncts(func(c adt.Conjunct) bool {
+		src := c.Source()
+		if src == nil || !src.Pos().IsValid() || seen[src.Pos()] {
+			return true
+		}
+		pos := src.Pos()
+		seen[pos] = true
+		filename := pos.Filename()
+		if rel, err := filepath.Rel(rootWorkingDir(), filename); err == nil && !strings.HasPrefix(rel, "..") {
+			filename = rel
+		}
+		positions = append(positions, fmt.Sprintf("%s:%d:%d", filepath.ToSlash(filename), pos.Line(), pos.Column()))
+		return true
+	})
+	if len(positions) == 0 {
+		positions = append(positions, "no source positions")
+	}
+	return positions
+}
@@ -0,0 +1,30 @@
+// Copyright 2025 The CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build !(darwin || dragonfly || freebsd || linux || netbsd || openbsd)
+
+package cmd
+
+import (
+	"fmt"
+	"runtime"
+)
+
+func makeRaw(fd int) (restore func(), err error) {
+	return nil, fmt.Errorf("interactive mode is not supported on %s", runtime.GOOS)
+}
+
+func terminalSize(fd int) (width, height int, err error) {
+	return 0, 0, fmt.Errorf("interactive mode is not supported on %s", runtime.GOOS)
+}
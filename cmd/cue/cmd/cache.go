@@ -0,0 +1,266 @@
+// Copyright 2025 CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"github.com/spf13/pflag"
+	"golang.org/x/mod/module"
+	"golang.org/x/mod/semver"
+
+	"cuelang.org/go/cue/ast"
+	"cuelang.org/go/cue/build"
+	"cuelang.org/go/internal/cueconfig"
+	"cuelang.org/go/internal/cueversion"
+	"cuelang.org/go/internal/encoding"
+)
+
+// An outputCache caches the output of a command on disk, keyed by the
+// hash of everything the output depends on: the contents of all input
+// files, including those of imported packages, the flags and arguments,
+// and the version of the cue tool.
+type outputCache struct {
+	b    *buildPlan
+	path string // path of the cache entry
+
+	buf bytes.Buffer // output of the command on a cache miss
+}
+
+// newOutputCache returns the cache for the output of b, or nil if the
+// --cache flag is not set or the output cannot be cached, such as when
+// it depends on system variables or files embedded with @embed, or is
+// written to more than one file.
+func newOutputCache(b *buildPlan, args []string) (*outputCache, error) {
+	if !flagCache.Bool(b.cmd) || b.encConfig.Fidelity || len(b.outFiles) > 1 {
+		return nil, nil
+	}
+	key, ok, err := hashInputs(b, args)
+	if err != nil || !ok {
+		return nil, err
+	}
+	dir, err := cueconfig.CacheDir(os.Getenv)
+	if err != nil {
+		return nil, err
+	}
+	return &outputCache{
+		b:    b,
+		path: filepath.Join(dir, "output", key[:2], key),
+	}, nil
+}
+
+// hashInputs returns the hash of everything the output of b depends on. It
+// reports false if the output depends on inputs which cannot be hashed,
+// such as system variables, injected files, or files embedded with @embed.
+func hashInputs(b *buildPlan, args []string) (key string, ok bool, err error) {
+	if flagInjectVars.Bool(b.cmd) {
+		return "", false, nil
+	}
+	// The contents of injected files and the environment are not hashed.
+	if len(flagInjectFile.StringArray(b.cmd)) > 0 || flagInjectEnv.String(b.cmd) != "" {
+		return "", false, nil
+	}
+	h := sha256.New()
+	if err := hashTool(h); err != nil {
+		return "", false, err
+	}
+	fmt.Fprintf(h, "command %s\n", b.cmd.Name())
+	b.cmd.Flags().Visit(func(f *pflag.Flag) {
+		fmt.Fprintf(h, "flag %s=%q\n", f.Name, f.Value)
+	})
+	for _, arg := range args {
+		fmt.Fprintf(h, "arg %q\n", arg)
+	}
+
+	insts := append([]*build.Instance{b.orphanInstance, b.schemaInst}, b.insts...)
+	if ok, err := hashInstances(h, insts); err != nil || !ok {
+		return "", ok, err
+	}
+	return hex.EncodeToString(h.Sum(nil)), true, nil
+}
+
+// replay writes the cached output, if any, and reports whether it did.
+// Otherwise it directs the output of the command to c, for [outputCache.save]
+// to write it.
+func (c *outputCache) replay() (bool, error) {
+	data, err := os.ReadFile(c.path)
+	if err == nil {
+		return true, encoding.Write(c.b.outFile, c.b.encConfig, data)
+	}
+	if !errors.Is(err, fs.ErrNotExist) {
+		return false, err
+	}
+	c.b.encConfig.Out = &c.buf
+	return false, nil
+}
+
+// save writes the output of the command and adds it to the cache. It is
+// a no-op if c is nil.
+func (c *outputCache) save() error {
+	if c == nil {
+		return nil
+	}
+	data := c.buf.Bytes()
+	if err := encoding.Write(c.b.outFile, c.b.encConfig, data); err != nil {
+		return err
+	}
+	return writeCacheFile(c.path, data)
+}
+
+// writeCacheFile writes data to path, first writing to a temporary file so
+// that concurrent commands never see a partial entry.
+func writeCacheFile(path string, data []byte) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o777); err != nil {
+		return err
+	}
+	f, err := os.CreateTemp(filepath.Dir(path), "tmp-")
+	if err != nil {
+		return err
+	}
+	_, err = f.Write(data)
+	if err1 := f.Close(); err == nil {
+		err = err1
+	}
+	if err == nil {
+		err = os.Rename(f.Name(), path)
+	}
+	if err != nil {
+		os.Remove(f.Name())
+	}
+	return err
+}
+
+// hashInstances writes the contents of all files of insts and the
+// packages they import to h. It reports false if the instances depend on
+// inputs which cannot be hashed.
+func hashInstances(h hash.Hash, insts []*build.Instance) (bool, error) {
+	seen := map[*build.Instance]bool{}
+	moduleFiles := map[string]bool{}
+	var walk func(inst *build.Instance) (bool, error)
+	walk = func(inst *build.Instance) (bool, error) {
+		if inst == nil || seen[inst] {
+			return true, nil
+		}
+		seen[inst] = true
+		for _, f := range inst.Files {
+			if usesExtern(f) {
+				return false, nil
+			}
+		}
+		if inst.Root != "" && !moduleFiles[inst.Root] {
+			moduleFiles[inst.Root] = true
+			err := hashFile(h, &build.File{Filename: filepath.Join(inst.Root, "cue.mod", "module.cue")})
+			if err != nil && !errors.Is(err, fs.ErrNotExist) {
+				return false, err
+			}
+		}
+		for _, files := range [][]*build.File{inst.BuildFiles, inst.OrphanedFiles} {
+			for _, f := range files {
+				if f.Filename == "-" {
+					if _, ok := f.Source.([]byte); !ok {
+						return false, nil
+					}
+				}
+				if err := hashFile(h, f); err != nil {
+					return false, err
+				}
+			}
+		}
+		for _, imp := range inst.Imports {
+			if ok, err := walk(imp); !ok || err != nil {
+				return ok, err
+			}
+		}
+		return true, nil
+	}
+	for _, inst := range insts {
+		if ok, err := walk(inst); !ok || err != nil {
+			return ok, err
+		}
+	}
+	return true, nil
+}
+
+// usesExtern reports whether f uses an extern interpreter, such as
+// @extern(embed), whose inputs are not tracked by the build.
+func usesExtern(f *ast.File) bool {
+	for _, d := range f.Decls {
+		if a, ok := d.(*ast.Attribute); ok {
+			if key, _ := a.Split(); key == "extern" {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func hashFile(h hash.Hash, f *build.File) error {
+	var data []byte
+	switch src := f.Source.(type) {
+	case []byte:
+		data = src
+	case string:
+		data = []byte(src)
+	default:
+		var err error
+		if data, err = os.ReadFile(f.Filename); err != nil {
+			return err
+		}
+	}
+	fmt.Fprintf(h, "file %q %s %s %s %d\n", f.Filename, f.Encoding, f.Interpretation, f.Form, len(data))
+	h.Write(data)
+	return nil
+}
+
+// hashTool writes the version of the cue tool and the environment
+// variables which affect evaluation to h.
+func hashTool(h hash.Hash) error {
+	version := cueversion.ModuleVersion()
+	fmt.Fprintf(h, "cue %s\n", version)
+	if !semver.IsValid(version) || module.IsPseudoVersion(version) {
+		// Development builds may not change their version at all, so
+		// tell them apart by the contents of the executable.
+		if err := hashExecutable(h); err != nil {
+			return err
+		}
+	}
+	for _, env := range []string{"CUE_EXPERIMENT", "CUE_DEBUG"} {
+		fmt.Fprintf(h, "env %s=%q\n", env, os.Getenv(env))
+	}
+	return nil
+}
+
+func hashExecutable(h hash.Hash) error {
+	exe, err := os.Executable()
+	if err != nil {
+		return err
+	}
+	f, err := os.Open(exe)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	_, err = io.Copy(h, f)
+	return err
+}
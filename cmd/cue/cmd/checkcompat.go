@@ -0,0 +1,97 @@
+// Copyright 2025 The CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"cuelang.org/go/cue"
+	"cuelang.org/go/tools/compat"
+)
+
+func newCheckCompatCmd(c *Command) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "check-compat [-d schema] old new",
+		Short: "report whether a new version of a schema is compatible with the old one",
+		Long: `
+check-compat compares two versions of a schema, each given as a CUE file or
+package, and classifies the changes between them. With -d, the schema is
+selected by the given expression in both versions.
+
+A change is backward compatible if the new version accepts all the data the
+old version accepts, so that existing data remains valid, such as when
+adding an optional field or widening a constraint. It is forward compatible
+if the old version accepts all the data the new version accepts, so that
+consumers of the old version can read new data, such as when adding a
+required field or narrowing a constraint. A change which is neither is
+breaking.
+
+Each change which is not fully compatible is reported with its path,
+followed by the compatibility of the new version as a whole. The command
+fails if the new version is breaking, so that it can be used to check
+schemas before they are published.
+
+By default, the closedness of structs is taken into account, so that adding
+an optional field to a closed struct is only backward compatible. With
+--api, the versions are compared as APIs, as by Value.Subsume with the
+cue.Schema option, and closedness is ignored.
+
+For example:
+
+	$ cue check-compat -d '#API' v1/api.cue v2/api.cue
+	port: changed from int to string: breaking
+	tags: added optional field: backward compatible
+	breaking
+`[1:],
+		Args: cobra.ExactArgs(2),
+		RunE: mkRunE(c, runCheckCompat),
+	}
+	cmd.Flags().StringP(string(flagSchema), "d", "", "expression to select the schema in both versions")
+	cmd.Flags().Bool(string(flagAPI), false, "compare the versions as APIs, ignoring the closedness of structs")
+	return cmd
+}
+
+func runCheckCompat(cmd *Command, args []string) error {
+	var versions [2]cue.Value
+	for i, arg := range args {
+		v, err := loadSinglePackage(cmd, []string{arg})
+		if err != nil {
+			return err
+		}
+		if s := flagSchema.String(cmd); s != "" {
+			if v, err = evalServeExpr(cmd.ctx, v, "--schema", s); err != nil {
+				return fmt.Errorf("%s: %w", arg, err)
+			}
+		}
+		versions[i] = v
+	}
+	r := compat.Check(versions[0], versions[1], &compat.Config{
+		API: flagAPI.Bool(cmd),
+	})
+	w := cmd.OutOrStdout()
+	for _, ch := range r.Changes {
+		if p := ch.Path.String(); p != "" {
+			fmt.Fprintf(w, "%s: ", p)
+		}
+		fmt.Fprintf(w, "%s: %s\n", ch.Describe(), ch.Compat)
+	}
+	fmt.Fprintln(w, r.Compat)
+	if r.Compat == compat.Breaking {
+		return ErrPrintedError
+	}
+	return nil
+}
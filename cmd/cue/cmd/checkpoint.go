@@ -0,0 +1,166 @@
+// Copyright 2025 The CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"time"
+
+	"cuelang.org/go/cue"
+	"cuelang.org/go/encoding/cuebin"
+	"cuelang.org/go/internal/cueexperiment"
+)
+
+// A checkpoint records the values exported by a command, so that an
+// interrupted export can resume where it stopped: the values recorded by
+// an earlier run with the same inputs are replayed rather than evaluated
+// again.
+//
+// The values are recorded in a single file, in the directory given by
+// --checkpoint, named after the hash of the inputs of the command. Each
+// value is written as a frame, consisting of its length as a uvarint
+// followed by a cuebin stream holding the value. A frame which was only
+// partly written when the command was interrupted is discarded on resume.
+type checkpoint struct {
+	path string
+	f    *os.File
+	w    *bufio.Writer
+	buf  bytes.Buffer
+
+	lastSync time.Time
+}
+
+// checkpointSyncInterval is the interval at which the recorded values are
+// synced to disk, such that they survive the loss of the machine.
+const checkpointSyncInterval = time.Second
+
+// newCheckpoint returns the checkpoint of the values exported by b, or nil
+// if the --checkpoint flag is not set.
+func newCheckpoint(b *buildPlan, args []string) (*checkpoint, error) {
+	dir := flagCheckpoint.String(b.cmd)
+	if dir == "" {
+		return nil, nil
+	}
+	if !cueexperiment.Flags.ExportCheckpoint {
+		return nil, fmt.Errorf("--%s requires CUE_EXPERIMENT=exportcheckpoint", flagCheckpoint)
+	}
+	if b.encConfig.Fidelity {
+		return nil, fmt.Errorf("--%s cannot be used with --%s", flagCheckpoint, flagFidelity)
+	}
+	key, ok, err := hashInputs(b, args)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, fmt.Errorf("--%s: the output depends on inputs which cannot be recorded, such as system variables or standard input", flagCheckpoint)
+	}
+	if err := os.MkdirAll(dir, 0o777); err != nil {
+		return nil, err
+	}
+	return &checkpoint{path: filepath.Join(dir, key+".cuebin")}, nil
+}
+
+// replay calls f for each value recorded by an earlier run, in order, and
+// returns their number. The checkpoint is then ready to record the values
+// which follow.
+func (c *checkpoint) replay(ctx *cue.Context, f func(v cue.Value) error) (n int, err error) {
+	data, err := os.ReadFile(c.path)
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return 0, err
+	}
+	end := 0 // end of the last complete frame
+	for end < len(data) {
+		size, w := binary.Uvarint(data[end:])
+		if w <= 0 || size == 0 || size > uint64(len(data)-end-w) {
+			break
+		}
+		frame := data[end+w : end+w+int(size)]
+		x, err := cuebin.NewDecoder(bytes.NewReader(frame)).Extract()
+		if err != nil {
+			break
+		}
+		v := ctx.BuildExpr(x)
+		if err := v.Err(); err != nil {
+			return n, err
+		}
+		if err := f(v); err != nil {
+			return n, err
+		}
+		n++
+		end += w + int(size)
+	}
+
+	c.f, err = os.OpenFile(c.path, os.O_RDWR|os.O_CREATE, 0o666)
+	if err != nil {
+		return n, err
+	}
+	// Discard the frame, if any, which was being written when the earlier
+	// run was interrupted.
+	if err := c.f.Truncate(int64(end)); err != nil {
+		return n, err
+	}
+	if _, err := c.f.Seek(int64(end), io.SeekStart); err != nil {
+		return n, err
+	}
+	c.w = bufio.NewWriter(c.f)
+	c.lastSync = time.Now()
+	return n, nil
+}
+
+// record records the concrete value v, which follows the values recorded
+// so far.
+func (c *checkpoint) record(v cue.Value) error {
+	c.buf.Reset()
+	if err := cuebin.NewEncoder(&c.buf).Encode(v); err != nil {
+		return err
+	}
+	c.w.Write(binary.AppendUvarint(nil, uint64(c.buf.Len())))
+	c.w.Write(c.buf.Bytes())
+	if err := c.w.Flush(); err != nil {
+		return err
+	}
+	if time.Since(c.lastSync) < checkpointSyncInterval {
+		return nil
+	}
+	c.lastSync = time.Now()
+	return c.f.Sync()
+}
+
+// close closes the checkpoint. It removes the recorded values if the
+// export completed, as they are then no longer needed.
+func (c *checkpoint) close(completed bool) error {
+	if c == nil || c.f == nil {
+		return nil
+	}
+	err := c.w.Flush()
+	if err == nil {
+		err = c.f.Sync()
+	}
+	if cerr := c.f.Close(); err == nil {
+		err = cerr
+	}
+	if err == nil && completed {
+		err = os.Remove(c.path)
+	}
+	return err
+}
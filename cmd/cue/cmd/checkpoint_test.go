@@ -0,0 +1,72 @@
+// Copyright 2025 CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/go-quicktest/qt"
+
+	"cuelang.org/go/cue"
+	"cuelang.org/go/cue/cuecontext"
+)
+
+func TestCheckpoint(t *testing.T) {
+	ctx := cuecontext.New()
+	path := filepath.Join(t.TempDir(), "values.cuebin")
+
+	replay := func() []string {
+		t.Helper()
+		c := &checkpoint{path: path}
+		var got []string
+		_, err := c.replay(ctx, func(v cue.Value) error {
+			b, err := v.MarshalJSON()
+			got = append(got, string(b))
+			return err
+		})
+		qt.Assert(t, qt.IsNil(err))
+		qt.Assert(t, qt.IsNil(c.close(false)))
+		return got
+	}
+
+	c := &checkpoint{path: path}
+	n, err := c.replay(ctx, nil)
+	qt.Assert(t, qt.IsNil(err))
+	qt.Assert(t, qt.Equals(n, 0))
+	qt.Assert(t, qt.IsNil(c.record(ctx.CompileString(`{a: 1, b: [1.5, "x"]}`))))
+	qt.Assert(t, qt.IsNil(c.record(ctx.CompileString(`"y"`))))
+	qt.Assert(t, qt.IsNil(c.close(false)))
+	want := []string{`{"a":1,"b":[1.5,"x"]}`, `"y"`}
+	qt.Assert(t, qt.DeepEquals(replay(), want))
+
+	// A frame which was only partly written is discarded.
+	data, err := os.ReadFile(path)
+	qt.Assert(t, qt.IsNil(err))
+	qt.Assert(t, qt.IsNil(os.WriteFile(path, append(data, 20, 'C', 'U'), 0o666)))
+	qt.Assert(t, qt.DeepEquals(replay(), want))
+	truncated, err := os.ReadFile(path)
+	qt.Assert(t, qt.IsNil(err))
+	qt.Assert(t, qt.DeepEquals(truncated, data))
+
+	// The values of a completed export are removed.
+	c = &checkpoint{path: path}
+	_, err = c.replay(ctx, func(cue.Value) error { return nil })
+	qt.Assert(t, qt.IsNil(err))
+	qt.Assert(t, qt.IsNil(c.close(true)))
+	_, err = os.Stat(path)
+	qt.Assert(t, qt.ErrorIs(err, os.ErrNotExist))
+}
@@ -15,11 +15,19 @@
 package cmd
 
 import (
+	"bytes"
 	"cmp"
+	"context"
+	"fmt"
+	"io"
+	"io/fs"
 	"os"
 	"path/filepath"
 	"regexp"
+	"runtime"
+	"slices"
 	"strings"
+	"sync"
 
 	"github.com/spf13/pflag"
 	"golang.org/x/text/language"
@@ -28,11 +36,15 @@ import (
 	"cuelang.org/go/cue/ast"
 	"cuelang.org/go/cue/build"
 	"cuelang.org/go/cue/errors"
+	"cuelang.org/go/cue/highlight"
 	"cuelang.org/go/cue/load"
 	"cuelang.org/go/cue/parser"
 	"cuelang.org/go/cue/token"
 	"cuelang.org/go/internal"
+	"cuelang.org/go/internal/cueconfig"
 	"cuelang.org/go/internal/cuedebug"
+	"cuelang.org/go/internal/cueexperiment"
+	"cuelang.org/go/internal/cuetrace"
 	"cuelang.org/go/internal/encoding"
 	"cuelang.org/go/internal/filetypes"
 )
@@ -75,8 +87,55 @@ func getLang() language.Tag {
 	return language.Make(loc)
 }
 
+// localizedFormat returns a function which formats messages in the language
+// of the user, as given by getLang, using x/text as our localizer.
+// Any translations of error messages for the language are first loaded from
+// $CUE_CONFIG_DIR/locale/<language>.json, such as "fr-CA.json" or "fr.json".
+func localizedFormat() func(w io.Writer, format string, args ...interface{}) {
+	lang := getLang()
+	loadTranslationsOnce.Do(func() {
+		if err := loadTranslations(lang); err != nil {
+			fmt.Fprintf(os.Stderr, "warning: invalid translations: %v\n", err)
+		}
+	})
+	return errors.Formatter(lang)
+}
+
+var loadTranslationsOnce sync.Once
+
+func loadTranslations(lang language.Tag) error {
+	if lang == language.Und {
+		return nil
+	}
+	dir, err := cueconfig.ConfigDir(os.Getenv)
+	if err != nil {
+		return nil
+	}
+	names := []string{lang.String()}
+	if base, conf := lang.Base(); conf != language.No && base.String() != lang.String() {
+		names = append(names, base.String())
+	}
+	for _, name := range names {
+		f, err := os.Open(filepath.Join(dir, "locale", name+".json"))
+		if errors.Is(err, fs.ErrNotExist) {
+			continue
+		} else if err != nil {
+			return err
+		}
+		defer f.Close()
+		if err := errors.LoadTranslations(lang, f); err != nil {
+			return fmt.Errorf("%s: %v", f.Name(), err)
+		}
+		return nil
+	}
+	return nil
+}
+
 func loadFromArgs(args []string, cfg *load.Config) []*build.Instance {
+	_, span := cuetrace.Start(context.Background(), "load")
 	binst := load.Instances(args, cfg)
+	span.SetInt("cue.instances", int64(len(binst)))
+	span.End()
 	if len(binst) == 0 {
 		return nil
 	}
@@ -96,6 +155,10 @@ type buildPlan struct {
 	// being processed, which may require a schema to decode.
 	instance *instance
 
+	// schemaInst is the build instance from which instance was compiled,
+	// allowing the schema to be compiled again within another context.
+	schemaInst *build.Instance
+
 	cfg *config
 
 	// If orphanFiles are mixed with CUE files and/or if placement flags are used,
@@ -112,35 +175,59 @@ type buildPlan struct {
 	expressions []ast.Expr // only evaluate these expressions within results
 	schema      ast.Expr   // selects schema in instance for orphaned values
 
+	// skip is the number of leading values which instances does not
+	// evaluate or return, such as those replayed from a checkpoint.
+	skip int
+
 	// orphan placement flags.
 	perFile    bool
 	useList    bool
 	path       []ast.Label
+	transform  ast.Expr // replaces placed values, if not nil
 	useContext bool
 
 	// outFile defines the file to output to. Default is CUE stdout.
 	outFile *build.File
 
+	// outFiles holds all files to output to for commands which accept
+	// several --outfile flags. The first is outFile.
+	outFiles []outputFile
+
 	encConfig *encoding.Config
 }
 
+// An outputFile is a file to output to, along with the mode in which
+// values are written to it.
+type outputFile struct {
+	file *build.File
+	mode filetypes.Mode
+}
+
 // instances iterates either over a list of instances, or a list of
 // data files. In the latter case, there must be either 0 or 1 other
 // instance, with which the data instance may be merged.
 func (b *buildPlan) instances() iterator {
+	// Each value of the underlying iterator results in a value for each
+	// expression.
+	skip, skipExpr := b.skip, 0
+	if n := len(b.expressions); n > 0 {
+		skip, skipExpr = skip/n, skip%n
+	}
 	var i iterator
 	switch {
 	case len(b.orphaned) > 0:
-		i = newStreamingIterator(b)
+		si := newStreamingIterator(b)
+		si.skip = skip
+		i = si
 	case len(b.insts) > 0:
-		insts, err := buildInstances(b.cmd, b.insts, false)
+		insts, err := buildInstances(b.cmd, b.insts[min(skip, len(b.insts)):], false)
 		i = &instanceIterator{
 			inst: b.instance,
 			a:    insts,
 			e:    err,
 			i:    -1,
 		}
-	case b.instance != nil:
+	case b.instance != nil && skip == 0:
 		i = &instanceIterator{
 			a: []*instance{b.instance},
 			i: -1,
@@ -155,9 +242,10 @@ func (b *buildPlan) instances() iterator {
 	}
 	if len(b.expressions) > 0 {
 		return &expressionIter{
-			iter: i,
-			expr: b.expressions,
-			i:    len(b.expressions),
+			iter:  i,
+			expr:  b.expressions,
+			i:     len(b.expressions),
+			first: skipExpr,
 		}
 	}
 	return i
@@ -217,6 +305,8 @@ type streamingIterator struct {
 	v   cue.Value
 	f   *ast.File
 	e   error
+
+	skip int // number of values to skip without evaluating them
 }
 
 func newStreamingIterator(b *buildPlan) *streamingIterator {
@@ -237,29 +327,35 @@ func (i *streamingIterator) scan() bool {
 		return false
 	}
 
-	// advance to next value
-	if i.dec != nil && !i.dec.Done() {
-		i.dec.Next()
-		if i.e = i.dec.Err(); i.e != nil {
-			return false
+	for {
+		// advance to next value
+		if i.dec != nil && !i.dec.Done() {
+			i.dec.Next()
+			if i.e = i.dec.Err(); i.e != nil {
+				return false
+			}
 		}
-	}
 
-	// advance to next stream if necessary
-	for i.dec == nil || i.dec.Done() {
-		if i.dec != nil {
-			i.dec.Close()
-			i.dec = nil
-		}
-		if len(i.a) == 0 {
-			return false
-		}
+		// advance to next stream if necessary
+		for i.dec == nil || i.dec.Done() {
+			if i.dec != nil {
+				i.dec.Close()
+				i.dec = nil
+			}
+			if len(i.a) == 0 {
+				return false
+			}
 
-		i.dec = i.a[0].dec(i.b)
-		if i.e = i.dec.Err(); i.e != nil {
-			return false
+			i.dec = i.a[0].dec(i.b)
+			if i.e = i.dec.Err(); i.e != nil {
+				return false
+			}
+			i.a = i.a[1:]
 		}
-		i.a = i.a[1:]
+		if i.skip == 0 {
+			break
+		}
+		i.skip--
 	}
 
 	// compose value
@@ -304,6 +400,8 @@ type expressionIter struct {
 	iter iterator
 	expr []ast.Expr
 	i    int
+
+	first int // index of the first expression of the first value
 }
 
 func (i *expressionIter) err() error { return i.iter.err() }
@@ -318,7 +416,7 @@ func (i *expressionIter) scan() bool {
 	if !i.iter.scan() {
 		return false
 	}
-	i.i = 0
+	i.i, i.first = i.first, 0
 	return true
 }
 
@@ -348,6 +446,10 @@ type config struct {
 
 	noMerge bool // do not merge individual data files.
 
+	multiOut bool // accept several --outfile flags.
+
+	schema ast.Expr // schema to use instead of the --schema flag.
+
 	loadCfg *load.Config
 }
 
@@ -394,12 +496,110 @@ func (p *buildPlan) matchFile(file string) bool {
 
 func setTags(cfg *load.Config, flags *pflag.FlagSet) {
 	tags, _ := flags.GetStringArray(string(flagInject))
+	cfg.Tags = append(cfg.Tags, envTags(flags, tags)...)
 	cfg.Tags = append(cfg.Tags, tags...)
 	if b, _ := flags.GetBool(string(flagInjectVars)); b {
 		cfg.TagVars = load.DefaultTagVars()
 	}
 }
 
+// envTags returns the tags set by the environment variables with the
+// prefix given by --inject-env, such that CUE_VAR_name=value sets the tag
+// name. Tags which are set explicitly take precedence.
+func envTags(flags *pflag.FlagSet, explicit []string) []string {
+	prefix, _ := flags.GetString(string(flagInjectEnv))
+	if prefix == "" {
+		return nil
+	}
+	set := map[string]bool{}
+	for _, t := range explicit {
+		name, _, _ := strings.Cut(t, "=")
+		name, _, _ = strings.Cut(name, ":")
+		set[name] = true
+	}
+	var tags []string
+	for _, kv := range os.Environ() {
+		name, ok := strings.CutPrefix(kv, prefix)
+		if !ok {
+			continue
+		}
+		if key, _, _ := strings.Cut(name, "="); key != "" && !set[key] {
+			tags = append(tags, name)
+		}
+	}
+	slices.Sort(tags)
+	return tags
+}
+
+// injectFiles unifies the value of each data file given by --inject-file,
+// of the form path=file, with the field at path in each of binsts.
+func injectFiles(cmd *Command, flags *pflag.FlagSet, binsts []*build.Instance) error {
+	specs, _ := flags.GetStringArray(string(flagInjectFile))
+	for _, spec := range specs {
+		path, file, ok := strings.Cut(spec, "=")
+		if !ok || file == "" {
+			return fmt.Errorf("invalid --%s %q: want path=file", flagInjectFile, spec)
+		}
+		var sels []cue.Selector
+		if path != "" {
+			p := cue.ParsePath(path)
+			if err := p.Err(); err != nil {
+				return fmt.Errorf("invalid --%s path %q: %v", flagInjectFile, path, err)
+			}
+			sels = p.Selectors()
+		}
+		// Each instance gets its own copy of the syntax.
+		for _, b := range binsts {
+			x, err := decodeInjectFile(cmd, file)
+			if err != nil {
+				return err
+			}
+			for i := len(sels) - 1; i >= 0; i-- {
+				var label ast.Label
+				switch sel := sels[i]; {
+				case sel.LabelType() == cue.DefinitionLabel:
+					label = ast.NewIdent(sel.String())
+				case sel.LabelType() == cue.StringLabel && sel.ConstraintType() == 0:
+					label = ast.NewString(sel.Unquoted())
+				default:
+					return fmt.Errorf("invalid --%s path %q: %v is not a field", flagInjectFile, path, sel)
+				}
+				x = ast.NewStruct(&ast.Field{Label: label, Value: x})
+			}
+			b.AddSyntax(&ast.File{
+				Filename: file,
+				Decls:    []ast.Decl{&ast.EmbedDecl{Expr: x}},
+			})
+		}
+	}
+	return nil
+}
+
+// decodeInjectFile decodes the data file given to --inject-file, which may
+// be qualified with its type, as in yaml:values.txt.
+func decodeInjectFile(cmd *Command, file string) (ast.Expr, error) {
+	f, err := filetypes.ParseFile(file, filetypes.Input)
+	if err != nil {
+		return nil, err
+	}
+	d := encoding.NewDecoder(cmd.ctx, f, &encoding.Config{})
+	defer d.Close()
+	if d.Done() {
+		if err := d.Err(); err != nil {
+			return nil, err
+		}
+		return nil, fmt.Errorf("%s: no value to inject", file)
+	}
+	x := internal.ToExpr(d.File())
+	if d.Next(); !d.Done() {
+		return nil, fmt.Errorf("%s: cannot inject a stream of several values", file)
+	}
+	if err := d.Err(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
 type decoderInfo struct {
 	file *build.File
 	d    *encoding.Decoder // may be nil if delayed
@@ -446,8 +646,21 @@ func (p *buildPlan) getDecoders(b *build.Instance) (schemas, values []*decoderIn
 			values = append(values, &decoderInfo{f, nil})
 			continue
 		default:
-			return schemas, values, errors.Newf(token.NoPos,
-				"unsupported encoding %q", f.Encoding)
+			if !filetypes.IsRegistered(f.Encoding) {
+				return schemas, values, errors.Newf(token.NoPos,
+					"unsupported encoding %q", f.Encoding)
+			}
+		}
+
+		fi, err := filetypes.FromFile(f, p.cfg.mode)
+		if err != nil {
+			return schemas, values, err
+		}
+		if fi.Form != build.Schema && fi.Form != build.Final {
+			// Data is decoded once any schema is known, which may be used
+			// to resolve the types of its scalars.
+			values = append(values, &decoderInfo{f, nil})
+			continue
 		}
 
 		// We add the module root to the path if there is a module defined.
@@ -457,20 +670,17 @@ func (p *buildPlan) getDecoders(b *build.Instance) (schemas, values []*decoderIn
 		}
 		d := encoding.NewDecoder(p.cmd.ctx, f, &c)
 
-		fi, err := filetypes.FromFile(f, p.cfg.mode)
-		if err != nil {
-			return schemas, values, err
-		}
 		switch {
-		// case !fi.Schema: // TODO: value/schema/auto
-		// 	values = append(values, d)
-		case fi.Form != build.Schema && fi.Form != build.Final:
-			values = append(values, &decoderInfo{f, d})
-
 		case f.Interpretation != build.Auto:
 			schemas = append(schemas, &decoderInfo{f, d})
 
 		case d.Interpretation() == "":
+			// As above, data is decoded again once any schema is known,
+			// unless it cannot be read twice.
+			if f.Filename != "-" || f.Source != nil {
+				d.Close()
+				d = nil
+			}
 			values = append(values, &decoderInfo{f, d})
 
 		default:
@@ -503,10 +713,17 @@ func parseArgs(cmd *Command, args []string, cfg *config) (p *buildPlan, err erro
 		return nil, err
 	}
 
+	if err := p.setStdinType(args); err != nil {
+		return nil, err
+	}
+
 	builds := loadFromArgs(args, cfg.loadCfg)
 	if builds == nil {
 		return nil, errors.Newf(token.NoPos, "invalid args")
 	}
+	if err := injectFiles(cmd, cmd.Flags(), builds); err != nil {
+		return nil, err
+	}
 
 	if err := p.parsePlacementFlags(); err != nil {
 		return nil, err
@@ -534,6 +751,10 @@ func parseArgs(cmd *Command, args []string, cfg *config) (p *buildPlan, err erro
 		}
 	}
 
+	if err := useEvalCache(cmd, builds); err != nil {
+		return nil, err
+	}
+
 	if len(p.insts) == 0 && flagGlob.String(p.cmd) != "" {
 		return nil, errors.Newf(token.NoPos,
 			"use of -n/--name flag without a directory")
@@ -603,7 +824,11 @@ func parseArgs(cmd *Command, args []string, cfg *config) (p *buildPlan, err erro
 				return nil, err
 			}
 			p.instance = inst
+			p.schemaInst = schema
 			p.encConfig.Schema = inst.Value()
+			// The schema applies to the root of data files, unless they
+			// are placed elsewhere.
+			p.encConfig.SchemaScalars = !p.usePlacement()
 			if p.schema != nil {
 				v := cmd.ctx.BuildExpr(p.schema,
 					cue.InferBuiltins(true),
@@ -645,6 +870,27 @@ func parseArgs(cmd *Command, args []string, cfg *config) (p *buildPlan, err erro
 	return p, nil
 }
 
+// setStdinType sets the file type of "-" when given without a qualifier
+// to that of the --stdin-type flag, detecting it from the contents of
+// standard input by default.
+func (p *buildPlan) setStdinType(args []string) error {
+	if !slices.Contains(args, "-") {
+		return nil
+	}
+	typ := flagStdinType.String(p.cmd)
+	if typ == "auto" {
+		data, err := io.ReadAll(p.cmd.InOrStdin())
+		if err != nil {
+			return err
+		}
+		p.cfg.loadCfg.Stdin = bytes.NewReader(data)
+		p.encConfig.Stdin = bytes.NewReader(data)
+		typ = string(encoding.DetectEncoding(data))
+	}
+	p.cfg.loadCfg.StdinType = typ
+	return nil
+}
+
 func (b *buildPlan) parseFlags() (err error) {
 	b.mergeData = !b.cfg.noMerge && flagMerge.Bool(b.cmd)
 
@@ -661,21 +907,43 @@ func (b *buildPlan) parseFlags() (err error) {
 	// For commands with an output mode, like `cue export` or `cue def`.
 	if b.cfg.mode != filetypes.Input {
 		out := flagOut.String(b.cmd)
-		outFile := flagOutFile.String(b.cmd)
-
-		if strings.Contains(out, ":") && strings.Contains(outFile, ":") {
-			return errors.Newf(token.NoPos,
-				"cannot specify qualifier in both --out and --outfile")
+		outFiles := flagOutFile.StringArray(b.cmd)
+		if !b.cfg.multiOut && len(outFiles) > 1 {
+			outFiles = outFiles[len(outFiles)-1:]
 		}
-		if outFile == "" {
-			outFile = "-"
+		if len(outFiles) == 0 {
+			outFiles = []string{"-"}
 		}
-		if out != "" {
-			outFile = out + ":" + outFile
+		for _, outFile := range outFiles {
+			mode := b.cfg.mode
+			if b.cfg.multiOut {
+				if name, ok := strings.CutSuffix(outFile, ":def"); ok {
+					outFile, mode = name, filetypes.Def
+				}
+			}
+			if strings.Contains(out, ":") && strings.Contains(outFile, ":") {
+				return errors.Newf(token.NoPos,
+					"cannot specify qualifier in both --out and --outfile")
+			}
+			if outFile == "" {
+				outFile = "-"
+			}
+			if out != "" {
+				outFile = out + ":" + outFile
+			}
+			f, err := filetypes.ParseFile(outFile, mode)
+			if err != nil {
+				return err
+			}
+			b.outFiles = append(b.outFiles, outputFile{file: f, mode: mode})
 		}
-		b.outFile, err = filetypes.ParseFile(outFile, b.cfg.mode)
-		if err != nil {
-			return err
+		b.outFile = b.outFiles[0].file
+		for _, out := range b.outFiles {
+			if out.file.Filename == "-" && out.file.Encoding == build.CUE &&
+				useColor(flagColor.String(b.cmd), b.encConfig.Stdout) {
+				b.encConfig.Stdout = highlight.NewWriter(b.encConfig.Stdout, nil)
+				break
+			}
 		}
 
 		for _, e := range flagExpression.StringArray(b.cmd) {
@@ -688,7 +956,9 @@ func (b *buildPlan) parseFlags() (err error) {
 		b.encConfig.Force = flagForce.Bool(b.cmd)
 	}
 
-	if s := flagSchema.String(b.cmd); s != "" {
+	if b.cfg.schema != nil {
+		b.schema = b.cfg.schema
+	} else if s := flagSchema.String(b.cmd); s != "" {
 		b.schema, err = parser.ParseExpr("--schema", s)
 		if err != nil {
 			return err
@@ -702,8 +972,14 @@ func (b *buildPlan) parseFlags() (err error) {
 	switch b.cfg.mode {
 	case filetypes.Export:
 		b.encConfig.EscapeHTML = flagEscape.Bool(b.cmd)
+		b.encConfig.Fidelity = flagFidelity.Bool(b.cmd)
+		b.encConfig.Canonical = flagCanonical.Bool(b.cmd)
+		if b.encConfig.Fidelity && b.encConfig.Canonical {
+			return fmt.Errorf("--fidelity cannot be used with --canonical")
+		}
 	case filetypes.Def:
 		b.encConfig.InlineImports = flagInlineImports.Bool(b.cmd)
+		b.encConfig.Canonical = flagCanonical.Bool(b.cmd)
 	}
 	return nil
 }
@@ -712,7 +988,16 @@ func buildInstances(cmd *Command, binst []*build.Instance, ignoreErrors bool) ([
 	// TODO:
 	// If there are no files and User is true, then use those?
 	// Always use all files in user mode?
-	instances, err := cmd.ctx.BuildInstances(binst)
+	// With CUE_EXPERIMENT=parallelpkgs, the instances are compiled
+	// concurrently, using one worker per CPU.
+	parallelism := 1
+	if cueexperiment.Flags.ParallelPkgs {
+		parallelism = runtime.GOMAXPROCS(0)
+	}
+	_, span := cuetrace.Start(cmd.Context(), "build")
+	instances, err := cmd.ctx.BuildInstances(binst, cue.Parallelism(parallelism))
+	span.SetError(err)
+	span.End()
 	if err != nil {
 		return nil, err
 	}
@@ -732,11 +1017,15 @@ func buildInstances(cmd *Command, binst []*build.Instance, ignoreErrors bool) ([
 		return insts, nil
 	}
 
+	_, span = cuetrace.Start(cmd.Context(), "evaluate")
+	defer span.End()
 	for _, inst := range instances {
 		// TODO: consider merging errors of multiple files, but ensure
 		// duplicates are removed.
 		err := inst.Validate()
 		if err != nil {
+			span.SetError(err)
+			err = traceErrors(binst, buildFileNames(binst), inst.Value(), err)
 			if flagIgnore.Bool(cmd) {
 				printError(cmd, err)
 			} else {
@@ -771,18 +1060,27 @@ func buildToolInstances(ctx *cue.Context, binst []*build.Instance) ([]*cue.Insta
 }
 
 func buildTools(cmd *Command, args []string) (*cue.Instance, error) {
+	return buildToolsIn(cmd, cmd.ctx, cmd.cmdCmd.Flags(), args)
+}
+
+// buildToolsIn is like buildTools, but builds the tool files in ctx and
+// injects the values given by flags.
+func buildToolsIn(cmd *Command, ctx *cue.Context, flags *pflag.FlagSet, args []string) (*cue.Instance, error) {
 	cfg, err := defaultConfig()
 	if err != nil {
 		return nil, err
 	}
 	loadCfg := *cfg.loadCfg
 	loadCfg.Tools = true
-	setTags(&loadCfg, cmd.cmdCmd.Flags())
+	setTags(&loadCfg, flags)
 
 	binst := loadFromArgs(args, &loadCfg)
 	if len(binst) == 0 {
 		return nil, nil
 	}
+	if err := injectFiles(cmd, flags, binst); err != nil {
+		return nil, err
+	}
 	included := map[string]bool{}
 
 	ti := binst[0].Context().NewInstance(binst[0].Root, nil)
@@ -806,7 +1104,7 @@ func buildTools(cmd *Command, args []string) (*cue.Instance, error) {
 		inst.Files = inst.Files[:k]
 	}
 
-	insts, err := buildToolInstances(cmd.ctx, binst)
+	insts, err := buildToolInstances(ctx, binst)
 	if err != nil {
 		return nil, err
 	}
@@ -816,7 +1114,7 @@ func buildTools(cmd *Command, args []string) (*cue.Instance, error) {
 		inst = cue.Merge(insts...)
 	}
 
-	ctx := inst.Value().Context()
+	ctx = inst.Value().Context()
 	for _, b := range binst {
 		for _, i := range b.Imports {
 			val := ctx.BuildInstance(i)
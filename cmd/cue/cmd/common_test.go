@@ -0,0 +1,96 @@
+// Copyright 2025 The CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/go-quicktest/qt"
+	"github.com/spf13/cobra"
+
+	"cuelang.org/go/cue/load"
+	"cuelang.org/go/internal/cueexperiment"
+)
+
+// BenchmarkBuildInstances compares compiling packages one after the other
+// with compiling them concurrently, as with CUE_EXPERIMENT=parallelpkgs.
+// The packages share an import, and each takes roughly the same time to
+// evaluate.
+func BenchmarkBuildInstances(b *testing.B) {
+	const numPkgs = 8
+	dir := b.TempDir()
+	files := map[string]string{
+		"cue.mod/module.cue": `module: "test.example", language: version: "v0.11.0"`,
+		"shared/shared.cue": `
+package shared
+
+#Item: {
+	n:    int
+	kind: *"a" | "b" | "c"
+	sq:   n * n
+}
+`,
+	}
+	for i := range numPkgs {
+		files[fmt.Sprintf("p%d/p.cue", i)] = fmt.Sprintf(`
+package p%d
+
+import (
+	"list"
+	"test.example/shared"
+)
+
+items: [for i in list.Range(0, 2000, 1) {shared.#Item & {n: i + %d}}]
+`, i, i)
+	}
+	for name, data := range files {
+		name = filepath.Join(dir, name)
+		qt.Assert(b, qt.IsNil(os.MkdirAll(filepath.Dir(name), 0o777)))
+		qt.Assert(b, qt.IsNil(os.WriteFile(name, []byte(data), 0o666)))
+	}
+	args := make([]string, numPkgs)
+	for i := range args {
+		args[i] = fmt.Sprintf("./p%d", i)
+	}
+
+	defer func(parallel bool) { cueexperiment.Flags.ParallelPkgs = parallel }(cueexperiment.Flags.ParallelPkgs)
+	for _, bench := range []struct {
+		name     string
+		parallel bool
+	}{
+		{"Serial", false},
+		{"Concurrent", true},
+	} {
+		b.Run(bench.name, func(b *testing.B) {
+			cueexperiment.Flags.ParallelPkgs = bench.parallel
+			for range b.N {
+				b.StopTimer()
+				// Each iteration needs new instances and contexts, as
+				// contexts cache the values of the instances they build.
+				binsts := load.Instances(args, &load.Config{Dir: dir})
+				cmd := &Command{Command: &cobra.Command{}, ctx: newContext()}
+				addGlobalFlags(cmd.Flags())
+				cmd.SetContext(context.Background())
+				b.StartTimer()
+				_, err := buildInstances(cmd, binsts, false)
+				qt.Assert(b, qt.IsNil(err))
+			}
+		})
+	}
+}
@@ -16,8 +16,14 @@ package cmd
 
 import (
 	"fmt"
+	"path/filepath"
+	"slices"
+	"strings"
 
 	"github.com/spf13/cobra"
+
+	"cuelang.org/go/cue"
+	"cuelang.org/go/internal/cueexperiment"
 )
 
 var validCompletionArgs = []string{"bash", "zsh", "fish", "powershell"}
@@ -77,3 +83,112 @@ func runCompletion(cmd *Command, args []string) error {
 	}
 	return nil
 }
+
+// registerPathCompletions registers completion functions for the flags of
+// cmd and its subcommands which take CUE paths or expressions, suggesting
+// the fields and definitions of the package given as arguments.
+func registerPathCompletions(cmd *cobra.Command) {
+	for _, f := range []struct {
+		name  flagName
+		label bool // complete a single label rather than a path
+		defs  bool // only complete definitions
+	}{
+		{flagExpression, false, false},
+		{flagPath, true, false},
+		{flagSchema, false, true},
+	} {
+		if cmd.Flags().Lookup(string(f.name)) != nil {
+			cmd.RegisterFlagCompletionFunc(string(f.name), func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+				return completePath(cmd, args, toComplete, f.label, f.defs), cobra.ShellCompDirectiveNoFileComp
+			})
+		}
+	}
+	for _, sub := range cmd.Commands() {
+		registerPathCompletions(sub)
+	}
+}
+
+// completePath returns the completions of toComplete as a path to a field
+// of the package given by args. The package is only evaluated as far as
+// needed to list the fields along the path.
+//
+// If label is set, toComplete is a single label of the fields below the
+// path given by the -l flags preceding it. If defs is set, only definitions
+// are suggested.
+func completePath(cmd *cobra.Command, args []string, toComplete string, label, defs bool) []string {
+	var prefix, partial string
+	var path cue.Path
+	if label {
+		labels, _ := cmd.Flags().GetStringArray(string(flagPath))
+		// Cobra parses the flags twice when completing a flag value,
+		// which repeats the values of array flags.
+		if n := len(labels) / 2; len(labels)%2 == 0 && slices.Equal(labels[:n], labels[n:]) {
+			labels = labels[:n]
+		}
+		path = cue.ParsePath(strings.Join(labels, "."))
+		partial = toComplete
+	} else {
+		if i := strings.LastIndexByte(toComplete, '.'); i >= 0 {
+			prefix, partial = toComplete[:i+1], toComplete[i+1:]
+		} else {
+			partial = toComplete
+		}
+		path = cue.ParsePath(strings.TrimSuffix(prefix, "."))
+	}
+	if path.Err() != nil {
+		return nil
+	}
+
+	v, ok := completionValue(args)
+	if !ok {
+		return nil
+	}
+	v = v.LookupPath(path)
+	if !v.Exists() {
+		return nil
+	}
+	iter, err := v.Fields(cue.Definitions(true), cue.Optional(true))
+	if err != nil {
+		return nil
+	}
+	var comps []string
+	for iter.Next() {
+		sel := iter.Selector()
+		if defs && !sel.IsDefinition() {
+			continue
+		}
+		name := sel.String()
+		if sel.ConstraintType() != 0 {
+			name = strings.TrimRight(name, "?!")
+		}
+		if strings.HasPrefix(name, partial) {
+			comps = append(comps, prefix+name)
+		}
+	}
+	slices.Sort(comps)
+	return comps
+}
+
+// completionValue loads and builds the package given by the arguments of
+// a command for the purpose of completing flags.
+func completionValue(args []string) (cue.Value, bool) {
+	// Non-CUE files are not needed to complete paths within the package.
+	var pkgArgs []string
+	for _, arg := range args {
+		if ext := filepath.Ext(arg); ext == "" || ext == ".cue" || strings.Contains(arg, "...") {
+			pkgArgs = append(pkgArgs, arg)
+		}
+	}
+	if err := cueexperiment.Init(); err != nil {
+		return cue.Value{}, false
+	}
+	cfg, err := defaultConfig()
+	if err != nil {
+		return cue.Value{}, false
+	}
+	insts := loadFromArgs(pkgArgs, cfg.loadCfg)
+	if len(insts) != 1 || insts[0].Err != nil {
+		return cue.Value{}, false
+	}
+	return newContext().BuildInstance(insts[0]), true
+}
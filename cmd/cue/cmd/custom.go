@@ -331,7 +331,9 @@ func newTaskFunc(cmd *Command, didWork *atomic.Bool) flow.TaskFunc {
 			return nil, errors.Promote(err, "errors running task")
 		}
 
-		return flow.RunnerFunc(func(t *flow.Task) error {
+		return flow.RunnerFunc(func(t *flow.Task) (err error) {
+			// Tasks run in goroutines of their own.
+			defer recoverLimit(&err)
 			obj := t.Value()
 
 			if isLegacy {
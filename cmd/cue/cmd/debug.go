@@ -0,0 +1,114 @@
+// Copyright 2025 The CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+
+	"cuelang.org/go/cue/errors"
+	"cuelang.org/go/internal/core/adt"
+	"cuelang.org/go/internal/cuedebug"
+)
+
+// Reports for the --debug flag, which print information to stderr
+// which helps to find performance problems in a configuration.
+const (
+	debugDisjunctions = "disjunctions"
+)
+
+// maxDebugDisjunctions is the number of disjunctions listed by
+// --debug=disjunctions.
+const maxDebugDisjunctions = 10
+
+// debugReports holds a description of each report, for 'cue help debug'.
+var debugReports = map[string]string{
+	debugDisjunctions: `List the disjunctions which caused the most work,
+along with how many of their disjuncts were eliminated.`,
+}
+
+// debugFlag splits the values of the --debug flag into the names of
+// reports and settings of debug flags, in the same form as CUE_DEBUG.
+func debugFlag(cmd *Command) (reports, settings []string) {
+	values, _ := cmd.Flags().GetStringSlice(string(flagDebug))
+	for _, v := range values {
+		if _, ok := debugReports[v]; ok {
+			reports = append(reports, v)
+		} else {
+			settings = append(settings, v)
+		}
+	}
+	return reports, settings
+}
+
+// initDebugFlags applies the settings of debug flags given with the
+// --debug flag on top of those in CUE_DEBUG.
+func initDebugFlags(cmd *Command) error {
+	_, settings := debugFlag(cmd)
+	if len(settings) == 0 {
+		return nil
+	}
+	if err := cuedebug.Set(strings.Join(settings, ",")); err != nil {
+		return fmt.Errorf("invalid --%s value: %w; see 'cue help debug'", flagDebug, err)
+	}
+	return nil
+}
+
+// startDebug starts collecting the information for the reports requested
+// with the --debug flag.
+func startDebug(cmd *Command) {
+	reports, _ := debugFlag(cmd)
+	for _, r := range reports {
+		switch r {
+		case debugDisjunctions:
+			adt.ProfileDisjunctions(true)
+		}
+	}
+}
+
+// printDebug prints the reports requested with the --debug flag to w.
+func printDebug(cmd *Command, w io.Writer) {
+	reports, _ := debugFlag(cmd)
+	for _, r := range reports {
+		switch r {
+		case debugDisjunctions:
+			adt.ProfileDisjunctions(false)
+			printDisjunctionProfiles(w, adt.DisjunctionProfiles())
+		}
+	}
+}
+
+// printDisjunctionProfiles prints the disjunctions which caused the most work,
+// along with how many of their disjuncts were eliminated.
+func printDisjunctionProfiles(w io.Writer, profiles []adt.DisjunctionProfile) {
+	fmt.Fprintf(w, "disjunctions by number of computed disjuncts (top %d of %d):\n", min(len(profiles), maxDebugDisjunctions), len(profiles))
+	if len(profiles) == 0 {
+		return
+	}
+	cfg := &errors.Config{Cwd: rootWorkingDir()}
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintf(tw, "\tcomputed\tpruned\tfailed\tduplicates\tevaluations\tmax cross\t\n")
+	for _, p := range profiles[:min(len(profiles), maxDebugDisjunctions)] {
+		pos := "-"
+		if p.Pos.IsValid() {
+			pos = fmt.Sprintf("%s:%d:%d", diagnosticFilename(p.Pos.Filename(), cfg), p.Pos.Line(), p.Pos.Column())
+		}
+		fmt.Fprintf(tw, "%s\t%d\t%d\t%d\t%d\t%d\t%d\t\n",
+			pos, p.Attempted, p.Pruned, p.Failed, p.Duplicates, p.Evaluations, p.MaxCross)
+	}
+	tw.Flush()
+}
@@ -31,6 +31,11 @@ func newDefCmd(c *Command) *cobra.Command {
 Printing is skipped if validation fails.
 
 The --expression flag is used to only print parts of a configuration.
+
+The --canonical flag prints a canonical form of the definitions, without
+comments, with sorted fields, and with normalized literals, which is the
+same for equal definitions, such as for hashing and signing versions of
+a schema.
 `,
 		RunE: mkRunE(c, runDef),
 	}
@@ -38,9 +43,13 @@ The --expression flag is used to only print parts of a configuration.
 	addOutFlags(cmd.Flags(), true)
 	addOrphanFlags(cmd.Flags())
 	addInjectionFlags(cmd.Flags(), false, false)
+	addCacheFlag(cmd.Flags())
 
 	cmd.Flags().StringArrayP(string(flagExpression), "e", nil, "evaluate this expression only")
 
+	cmd.Flags().Bool(string(flagCanonical), false,
+		"write a canonical form of the output for hashing and signing; see 'cue help export'")
+
 	cmd.Flags().BoolP(string(flagAttributes), "A", false,
 		"display field attributes")
 
@@ -57,6 +66,16 @@ func runDef(cmd *Command, args []string) error {
 		return err
 	}
 
+	cache, err := newOutputCache(b, args)
+	if err != nil {
+		return err
+	}
+	if cache != nil {
+		if ok, err := cache.replay(); ok || err != nil {
+			return err
+		}
+	}
+
 	e, err := encoding.NewEncoder(cmd.ctx, b.outFile, b.encConfig)
 	if err != nil {
 		return err
@@ -82,5 +101,5 @@ func runDef(cmd *Command, args []string) error {
 	if err := e.Close(); err != nil {
 		return err
 	}
-	return nil
+	return cache.save()
 }
@@ -0,0 +1,732 @@
+// Copyright 2025 CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"testing"
+
+	"cuelang.org/go/cue"
+	"cuelang.org/go/cue/errors"
+	"cuelang.org/go/cue/highlight"
+)
+
+func checkDiagnosticsFlag(cmd *Command) error {
+	if err := checkDiagnosticsFormat(flagDiagnostics.String(cmd)); err != nil {
+		return err
+	}
+	if _, err := errorGrouping(flagGroupErrors.String(cmd)); err != nil {
+		return err
+	}
+	if n := flagMaxPerGroup.Int(cmd); n < 0 {
+		return fmt.Errorf("invalid --%s value %d; must not be negative", flagMaxPerGroup, n)
+	}
+	switch f := flagSnippets.String(cmd); f {
+	case "auto", "always", "never":
+	default:
+		return fmt.Errorf("unknown --%s value %q; must be one of %q, %q, or %q", flagSnippets, f, "auto", "always", "never")
+	}
+	switch f := flagColor.String(cmd); f {
+	case "auto", "always", "never":
+	default:
+		return fmt.Errorf("unknown --%s value %q; must be one of %q, %q, or %q", flagColor, f, "auto", "always", "never")
+	}
+	return nil
+}
+
+// errorGrouping returns the grouping for a --group-errors value.
+func errorGrouping(s string) (errors.Grouping, error) {
+	switch s {
+	case "":
+		return errors.NoGrouping, nil
+	case "path":
+		return errors.GroupByPath, nil
+	case "code":
+		return errors.GroupByCode, nil
+	default:
+		return 0, fmt.Errorf("unknown --%s value %q; must be one of %q or %q", flagGroupErrors, s, "path", "code")
+	}
+}
+
+// textErrorConfig returns the configuration for printing errors as text,
+// honoring the global flags which group and truncate errors.
+func textErrorConfig(cmd *Command) *errors.Config {
+	cfg := &errors.Config{
+		Format:  localizedFormat(),
+		Cwd:     rootWorkingDir(),
+		ToSlash: testing.Testing(),
+		Hints:   true,
+	}
+	if cmd == nil || cmd.root == nil {
+		return cfg
+	}
+	f := cmd.root.PersistentFlags()
+	group, _ := f.GetString(string(flagGroupErrors))
+	cfg.GroupBy, _ = errorGrouping(group)
+	cfg.MaxPerGroup, _ = f.GetInt(string(flagMaxPerGroup))
+
+	// Source snippets, and their colors, are meant for humans;
+	// only show them by default when writing to a terminal.
+	tty := isTerminal(cmd.root.ErrOrStderr())
+	switch snippets, _ := f.GetString(string(flagSnippets)); snippets {
+	case "always":
+	case "auto":
+		if !tty {
+			return cfg
+		}
+	default:
+		return cfg
+	}
+	cfg.Source = sourceReader()
+	color, _ := f.GetString(string(flagColor))
+	cfg.Color = useColor(color, cmd.root.ErrOrStderr())
+	cfg.Highlight = func(line string) string {
+		return string(highlight.Source([]byte(line), nil))
+	}
+	return cfg
+}
+
+// useColor reports whether output to w is highlighted with colors, given
+// the value of the --color flag.
+func useColor(color string, w io.Writer) bool {
+	switch color {
+	case "always":
+		return true
+	case "never":
+		return false
+	}
+	return isTerminal(w) && os.Getenv("NO_COLOR") == ""
+}
+
+// sourceReader returns a function which reads files from disk,
+// caching their contents as errors often refer to the same files.
+func sourceReader() func(string) ([]byte, error) {
+	type result struct {
+		data []byte
+		err  error
+	}
+	cache := map[string]result{}
+	return func(filename string) ([]byte, error) {
+		r, ok := cache[filename]
+		if !ok {
+			r.data, r.err = os.ReadFile(filename)
+			cache[filename] = r
+		}
+		return r.data, r.err
+	}
+}
+
+// isTerminal reports whether w is a terminal.
+func isTerminal(w io.Writer) bool {
+	f, ok := w.(*os.File)
+	if !ok {
+		return false
+	}
+	info, err := f.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
+
+func checkDiagnosticsFormat(f string) error {
+	switch f {
+	case diagText, diagJSON:
+		return nil
+	default:
+		return fmt.Errorf("unknown --%s format %q; must be one of %q or %q", flagDiagnostics, f, diagText, diagJSON)
+	}
+}
+
+// jsonDiagnostic is the record written to stderr for each error
+// when --diagnostics=json is used.
+type jsonDiagnostic struct {
+	Severity  string                   `json:"severity"`
+	Code      string                   `json:"code"`
+	URL       string                   `json:"url,omitempty"`
+	Message   string                   `json:"message"`
+	Path      string                   `json:"path,omitempty"`
+	Positions []jsonDiagnosticPosition `json:"positions,omitempty"`
+	Causes    []jsonDiagnosticCause    `json:"causes,omitempty"`
+
+	Suggestions []string              `json:"suggestions,omitempty"`
+	Hints       []jsonDiagnosticHint  `json:"hints,omitempty"`
+	Trace       []jsonDiagnosticFrame `json:"trace,omitempty"`
+}
+
+// jsonDiagnosticFrame describes a site which led to a diagnostic,
+// such as an import of the package in which it occurred.
+type jsonDiagnosticFrame struct {
+	Message  string                  `json:"message"`
+	Position *jsonDiagnosticPosition `json:"position,omitempty"`
+}
+
+// jsonDiagnosticHint describes a way to fix a diagnostic, along with any
+// edits to the source which implement it.
+type jsonDiagnosticHint struct {
+	Message string               `json:"message,omitempty"`
+	Edits   []jsonDiagnosticEdit `json:"edits,omitempty"`
+}
+
+// jsonDiagnosticEdit replaces the source between two positions.
+type jsonDiagnosticEdit struct {
+	Start   jsonDiagnosticPosition `json:"start"`
+	End     jsonDiagnosticPosition `json:"end"`
+	NewText string                 `json:"newText"`
+}
+
+// jsonDiagnosticCause describes an error wrapped by a diagnostic,
+// from the outermost to the innermost.
+type jsonDiagnosticCause struct {
+	Message  string                  `json:"message"`
+	Position *jsonDiagnosticPosition `json:"position,omitempty"`
+}
+
+type jsonDiagnosticPosition struct {
+	File   string `json:"file"`
+	Line   int    `json:"line,omitempty"`
+	Column int    `json:"column,omitempty"`
+}
+
+// writeJSONDiagnostics writes err to w as a sequence of JSON records,
+// one per line, with duplicate errors removed.
+func writeJSONDiagnostics(w io.Writer, err error, severity string, cfg *errors.Config) {
+	enc := json.NewEncoder(w)
+	enc.SetEscapeHTML(false)
+	for _, e := range errors.Errors(errors.Sanitize(errorList(errors.Errors(err)))) {
+		_ = enc.Encode(newJSONDiagnostic(e, severity, cfg))
+	}
+}
+
+func newJSONDiagnostic(err errors.Error, severity string, cfg *errors.Config) jsonDiagnostic {
+	rule := diagnosticRuleFor(err)
+	d := jsonDiagnostic{
+		Severity: severity,
+		Code:     rule.id,
+		URL:      rule.url,
+		Message:  diagnosticMessage(err),
+		Path:     strings.Join(err.Path(), "."),
+
+		Suggestions: errors.Suggestions(err),
+	}
+	for _, h := range errors.Hints(err) {
+		hint := jsonDiagnosticHint{Message: h.Message}
+		for _, e := range h.Edits {
+			start, end := e.Start.Position(), e.End.Position()
+			if !start.IsValid() || !end.IsValid() {
+				continue
+			}
+			hint.Edits = append(hint.Edits, jsonDiagnosticEdit{
+				Start:   jsonDiagnosticPosition{File: diagnosticFilename(start.Filename, cfg), Line: start.Line, Column: start.Column},
+				End:     jsonDiagnosticPosition{File: diagnosticFilename(end.Filename, cfg), Line: end.Line, Column: end.Column},
+				NewText: e.NewText,
+			})
+		}
+		d.Hints = append(d.Hints, hint)
+	}
+	for _, pos := range errors.Positions(err) {
+		p := pos.Position()
+		if p.Filename == "" {
+			continue
+		}
+		d.Positions = append(d.Positions, jsonDiagnosticPosition{
+			File:   diagnosticFilename(p.Filename, cfg),
+			Line:   p.Line,
+			Column: p.Column,
+		})
+	}
+	for cause := errors.Unwrap(err); cause != nil; cause = errors.Unwrap(cause) {
+		ce, ok := cause.(errors.Error)
+		if !ok {
+			d.Causes = append(d.Causes, jsonDiagnosticCause{Message: cause.Error()})
+			continue
+		}
+		format, args := ce.Msg()
+		c := jsonDiagnosticCause{Message: fmt.Sprintf(format, args...)}
+		if p := ce.Position().Position(); p.Filename != "" {
+			c.Position = &jsonDiagnosticPosition{
+				File:   diagnosticFilename(p.Filename, cfg),
+				Line:   p.Line,
+				Column: p.Column,
+			}
+		}
+		d.Causes = append(d.Causes, c)
+	}
+	for _, f := range errors.Trace(err) {
+		frame := jsonDiagnosticFrame{Message: f.Message}
+		if p := f.Pos.Position(); p.Filename != "" {
+			frame.Position = &jsonDiagnosticPosition{
+				File:   diagnosticFilename(p.Filename, cfg),
+				Line:   p.Line,
+				Column: p.Column,
+			}
+		}
+		d.Trace = append(d.Trace, frame)
+	}
+	return d
+}
+
+// diagnosticMessage returns the message for err without its path prefix.
+func diagnosticMessage(err errors.Error) string {
+	var pv *policyViolation
+	if errors.As(err, &pv) {
+		return pv.message
+	}
+	var lf *lintFinding
+	if errors.As(err, &lf) {
+		return lf.message
+	}
+	msg := errors.String(err)
+	if path := strings.Join(err.Path(), "."); path != "" {
+		msg = strings.TrimPrefix(msg, path+": ")
+	}
+	return msg
+}
+
+// diagnosticFilename returns filename relative to cfg.Cwd when possible.
+func diagnosticFilename(filename string, cfg *errors.Config) string {
+	if cfg.Cwd != "" {
+		if rel, err := filepath.Rel(cfg.Cwd, filename); err == nil {
+			filename = rel
+		}
+	}
+	if cfg.ToSlash {
+		filename = filepath.ToSlash(filename)
+	}
+	return filename
+}
+
+// Diagnostics formats. The global --diagnostics flag, which controls how
+// errors are written to stderr, supports text and json. Commands which
+// report validation errors, such as cue vet, also support a short format
+// with one line per error, annotations for GitHub Actions, and writing
+// all errors as a single document in the sarif format or as a GitLab
+// code quality report.
+const (
+	diagText   = "text"
+	diagShort  = "short"
+	diagJSON   = "json"
+	diagSARIF  = "sarif"
+	diagGitHub = "github"
+	diagGitLab = "gitlab"
+)
+
+// Severities of diagnostics. Only errors cause a command to fail.
+const (
+	severityError   = "error"
+	severityWarning = "warning"
+)
+
+// severityAttr is the attribute used to lower the severity of
+// constraint violations on a field and the values nested within it,
+// as in:
+//
+//	replicas: int & <=10 @severity(warning)
+//
+// The attribute nearest to the value in error takes precedence.
+const severityAttr = "severity"
+
+// splitWarnings separates the errors in err which are marked as warnings
+// via a @severity(warning) attribute in v from all other errors.
+func splitWarnings(v cue.Value, err error) (errs, warnings errors.Error) {
+	// Errors are reported with paths from the root of the value v was
+	// selected from, such as #Schema.field, so drop the path of v.
+	var prefix []string
+	for _, sel := range v.Path().Selectors() {
+		prefix = append(prefix, sel.String())
+	}
+	for _, e := range errors.Errors(err) {
+		path := e.Path()
+		if len(prefix) > 0 && len(path) >= len(prefix) && slices.Equal(path[:len(prefix)], prefix) {
+			path = path[len(prefix):]
+		}
+		if severityOf(v, path) == severityWarning {
+			warnings = errors.Append(warnings, e)
+		} else {
+			errs = errors.Append(errs, e)
+		}
+	}
+	return errs, warnings
+}
+
+// severityOf returns the severity declared by the @severity attribute
+// nearest to the value at path in v, defaulting to severityError.
+func severityOf(v cue.Value, path []string) string {
+	// Collect the values along path, so that the nearest attribute
+	// can be found. Optional fields, as commonly used in schemas,
+	// are only found when looked up as such.
+	values := []cue.Value{}
+	for _, elem := range path {
+		var sels []cue.Selector
+		if i, err := strconv.Atoi(elem); err == nil {
+			sels = []cue.Selector{cue.Index(i)}
+		} else if p := cue.ParsePath(elem); p.Err() == nil {
+			sels = p.Selectors()
+		}
+		if len(sels) != 1 {
+			break
+		}
+		next := v.LookupPath(cue.MakePath(sels[0]))
+		if !next.Exists() && sels[0].LabelType() == cue.StringLabel {
+			next = v.LookupPath(cue.MakePath(sels[0].Optional()))
+		}
+		if !next.Exists() {
+			break
+		}
+		values = append(values, next)
+		v = next
+	}
+	for _, v := range slices.Backward(values) {
+		attr := v.Attribute(severityAttr)
+		if attr.Err() != nil {
+			continue
+		}
+		switch s, _ := attr.String(0); s {
+		case severityWarning, severityError:
+			return s
+		}
+	}
+	return severityError
+}
+
+// diagnosticRule describes a class of diagnostics.
+type diagnosticRule struct {
+	id          string
+	description string
+	url         string // where to find remediation advice, if any
+}
+
+var validationRule = diagnosticRule{
+	id:          "validation",
+	description: "CUE validation error",
+}
+
+// diagnosticRuleFor returns the rule which err is an instance of.
+func diagnosticRuleFor(err errors.Error) diagnosticRule {
+	var pv *policyViolation
+	if errors.As(err, &pv) {
+		return pv.rule.diagnosticRule
+	}
+	var lf *lintFinding
+	if errors.As(err, &lf) {
+		return *lf.rule
+	}
+	if code := errors.CodeOf(err); code != "" {
+		return diagnosticRule{
+			id:          string(code),
+			description: code.Description(),
+		}
+	}
+	return validationRule
+}
+
+// diagReporter collects the errors reported by a command and presents
+// them in the diagnostics format requested by the user.
+//
+// The text, short, and json formats print errors to stderr as soon as
+// they are reported, and the github format prints them to stdout, where
+// GitHub Actions looks for workflow commands. The sarif and gitlab
+// formats buffer all errors and write them as a single document to
+// stdout when flush is called.
+type diagReporter struct {
+	cmd    *Command
+	format string
+
+	// strict reports warnings as errors.
+	strict bool
+
+	// maxErrors is the maximum number of errors to report,
+	// or zero if there is no limit.
+	maxErrors int
+
+	exitCodes exitCodes
+
+	errs     []errors.Error // buffered for the sarif and gitlab formats
+	warnings []errors.Error // buffered for the sarif and gitlab formats
+
+	numErrors   int  // the number of errors reported, excluding tool errors
+	numWarnings int  // the number of warnings reported
+	invalid     bool // whether the input was found invalid without an error
+	toolErr     bool // whether any tool errors were reported
+	tooMany     bool // whether errors were dropped due to maxErrors
+
+	// summary, if set, aggregates the reported diagnostics.
+	summary *vetSummary
+
+	// schema names the schema against which the input currently being
+	// checked is validated, for the summary.
+	schema string
+}
+
+func newDiagReporter(cmd *Command, format string) (*diagReporter, error) {
+	switch format {
+	case "":
+		format = diagText
+	case diagText, diagShort, diagJSON, diagSARIF, diagGitHub, diagGitLab:
+	default:
+		return nil, fmt.Errorf("unknown error format %q; must be one of %q, %q, %q, %q, %q, or %q",
+			format, diagText, diagShort, diagJSON, diagSARIF, diagGitHub, diagGitLab)
+	}
+	return &diagReporter{
+		cmd:       cmd,
+		format:    format,
+		exitCodes: defaultExitCodes,
+	}, nil
+}
+
+// report records err, which may be nil, as invalid input.
+func (r *diagReporter) report(err error) {
+	if err == nil {
+		return
+	}
+	var errs []errors.Error
+	for _, e := range errors.Errors(err) {
+		if r.maxErrors > 0 && r.numErrors >= r.maxErrors {
+			r.tooMany = true
+			break
+		}
+		r.numErrors++
+		errs = append(errs, e)
+	}
+	if r.summary != nil {
+		r.summary.add(errs, severityError, r.schema, r.config())
+	}
+	r.emit(errs, severityError)
+}
+
+// toolError records err, which may be nil, as an error which is not
+// due to invalid input, such as failing to load a package. Such errors
+// are always reported, regardless of the maximum number of errors.
+func (r *diagReporter) toolError(err error) {
+	if err == nil {
+		return
+	}
+	r.toolErr = true
+	r.emit(errors.Errors(err), severityError)
+}
+
+// warn records err, which may be nil, as a warning. Warnings do not
+// cause the command to fail unless the reporter is strict.
+func (r *diagReporter) warn(err error) {
+	if err == nil {
+		return
+	}
+	if r.strict {
+		r.report(err)
+		return
+	}
+	errs := errors.Errors(err)
+	r.numWarnings += len(errs)
+	if r.summary != nil {
+		r.summary.add(errs, severityWarning, r.schema, r.config())
+	}
+	r.emit(errs, severityWarning)
+}
+
+// checked records that the given number of files are being checked.
+func (r *diagReporter) checked(files int) {
+	if r.summary != nil {
+		r.summary.files += files
+	}
+}
+
+// markInvalid records that the input is invalid, when the command has
+// told the user so without reporting any specific errors.
+func (r *diagReporter) markInvalid() {
+	r.invalid = true
+}
+
+// full reports whether the maximum number of errors has been reached,
+// so that a command can stop looking for more.
+func (r *diagReporter) full() bool {
+	return r.maxErrors > 0 && r.numErrors >= r.maxErrors
+}
+
+func (r *diagReporter) emit(errs []errors.Error, severity string) {
+	if len(errs) == 0 {
+		return
+	}
+	if severity == severityError && r.format != diagText {
+		// Errors in the text format are counted by printErrorTo.
+		countErrors(errs)
+	}
+	if r.buffered() {
+		if severity == severityWarning {
+			r.warnings = append(r.warnings, errs...)
+		} else {
+			r.errs = append(r.errs, errs...)
+		}
+		return
+	}
+	err := errorList(errs)
+	cfg := r.config()
+	// Use a writer which does not affect the exit code,
+	// as flush decides on the exit code.
+	w := r.cmd.OutOrStderr()
+	switch r.format {
+	case diagShort:
+		writeShortDiagnostics(w, err, severity, cfg)
+	case diagJSON:
+		writeJSONDiagnostics(w, err, severity, cfg)
+	case diagGitHub:
+		writeGitHubAnnotations(r.cmd.OutOrStdout(), err, severity, cfg)
+	default:
+		if severity == severityWarning {
+			printWarning(r.cmd, err)
+		} else {
+			printErrorTo(r.cmd, w, err)
+		}
+	}
+}
+
+// buffered reports whether the format writes all diagnostics as a single
+// document when flush is called.
+func (r *diagReporter) buffered() bool {
+	return r.format == diagSARIF || r.format == diagGitLab
+}
+
+// config returns the configuration for printing diagnostics.
+func (r *diagReporter) config() *errors.Config {
+	return &errors.Config{
+		Cwd:     rootWorkingDir(),
+		ToSlash: testing.Testing(),
+	}
+}
+
+// fail handles an error which prevents the command from continuing.
+func (r *diagReporter) fail(err error) error {
+	r.toolError(err)
+	return r.flush()
+}
+
+// flush writes any buffered diagnostics and determines the outcome of
+// the command. It returns an [*exitError] with the exit code for the
+// kind of diagnostics reported, if any.
+func (r *diagReporter) flush() error {
+	if r.buffered() {
+		errs := errors.Errors(errors.Sanitize(errorList(r.errs)))
+		warnings := errors.Errors(errors.Sanitize(errorList(r.warnings)))
+		write := writeSARIF
+		if r.format == diagGitLab {
+			write = writeGitLabCodeQuality
+		}
+		if err := write(r.cmd.OutOrStdout(), errs, warnings, r.config()); err != nil {
+			return err
+		}
+	}
+	if r.summary != nil {
+		if err := r.summary.write(r.cmd.OutOrStdout()); err != nil {
+			return err
+		}
+	}
+	if r.tooMany {
+		fmt.Fprintf(r.cmd.OutOrStderr(), "too many errors; stopped after %d (see --%s)\n", r.maxErrors, flagMaxErrors)
+	}
+	switch {
+	case r.toolErr:
+		return &exitError{code: r.exitCodes.error}
+	case r.numErrors > 0 || r.invalid:
+		return &exitError{code: r.exitCodes.invalid}
+	case r.numWarnings > 0 && r.exitCodes.warning != 0:
+		return &exitError{code: r.exitCodes.warning}
+	}
+	return nil
+}
+
+// exitCodes holds the exit codes for each kind of failure,
+// as configured via the --exit-code flag.
+type exitCodes struct {
+	invalid int // the input does not satisfy its constraints
+	error   int // the command failed for another reason
+	warning int // only warnings were reported
+}
+
+var defaultExitCodes = exitCodes{invalid: 1, error: 1, warning: 0}
+
+// parseExitCodes parses --exit-code arguments of the form kind=code,
+// overriding the given exit codes.
+func parseExitCodes(codes exitCodes, args []string) (exitCodes, error) {
+	for _, arg := range args {
+		kind, s, ok := strings.Cut(arg, "=")
+		code, err := strconv.Atoi(s)
+		if !ok || err != nil || code < 0 || code > 125 {
+			return codes, fmt.Errorf("invalid --%s argument %q; must be of the form kind=code with a code between 0 and 125", flagExitCode, arg)
+		}
+		switch kind {
+		case "invalid":
+			codes.invalid = code
+		case "error":
+			codes.error = code
+		case "warning":
+			codes.warning = code
+		default:
+			return codes, fmt.Errorf("unknown --%s kind %q; must be one of %q, %q, or %q", flagExitCode, kind, "invalid", "error", "warning")
+		}
+	}
+	return codes, nil
+}
+
+// errorList combines a slice of errors into a single error.
+func errorList(errs []errors.Error) errors.Error {
+	var list errors.Error
+	for _, err := range errs {
+		list = errors.Append(list, err)
+	}
+	return list
+}
+
+// printWarning prints err to stderr as a warning, without causing
+// the command to fail.
+func printWarning(cmd *Command, err error) {
+	w := cmd.OutOrStderr()
+	cfg := &errors.Config{
+		Cwd:     rootWorkingDir(),
+		ToSlash: testing.Testing(),
+	}
+	if flagDiagnostics.String(cmd) == diagJSON {
+		writeJSONDiagnostics(w, err, severityWarning, cfg)
+		return
+	}
+	cfg.Hints = true
+	for _, e := range errors.Errors(errors.Sanitize(errorList(errors.Errors(err)))) {
+		fmt.Fprint(w, "warning: ")
+		errors.Print(w, e, cfg)
+	}
+}
+
+// writeShortDiagnostics writes err to w with one line per error, in the
+// form file:line:column: message, as understood by many editors and CI
+// systems.
+func writeShortDiagnostics(w io.Writer, err error, severity string, cfg *errors.Config) {
+	for _, e := range errors.Errors(errors.Sanitize(errorList(errors.Errors(err)))) {
+		var b strings.Builder
+		pos := e.Position()
+		if ps := errors.Positions(e); !pos.IsValid() && len(ps) > 0 {
+			pos = ps[0]
+		}
+		if p := pos.Position(); p.Filename != "" {
+			fmt.Fprintf(&b, "%s:%d:%d: ", diagnosticFilename(p.Filename, cfg), p.Line, p.Column)
+		}
+		if severity == severityWarning {
+			b.WriteString("warning: ")
+		}
+		b.WriteString(errors.String(e))
+		fmt.Fprintln(w, b.String())
+	}
+}
@@ -0,0 +1,186 @@
+// Copyright 2025 CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+
+	"cuelang.org/go/cue"
+	"cuelang.org/go/cue/ast"
+	"cuelang.org/go/cue/parser"
+	"cuelang.org/go/internal/diff"
+	"cuelang.org/go/internal/filetypes"
+)
+
+func newDiffCmd(c *Command) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "diff [flags] [inputs]",
+		Short: "compare two configurations structurally",
+		Long: `diff evaluates two values and prints the differences between them.
+
+Unlike a textual diff, diff compares the values rather than their source,
+such that differences in formatting, comments, or in how a value is
+written, as with references or comprehensions, are not reported. The
+values are given as two inputs, which may be CUE files, packages, or
+data files, or as two expressions given with -e which are evaluated
+within a single input:
+
+	cue diff old.yaml new.yaml
+	cue diff ./v1 ./v2
+	cue diff -e '#Config' ./v1 ./v2
+	cue diff -e 'staging' -e 'production' .
+
+The differences are printed as the fields and elements which were
+removed, prefixed with -, and added, prefixed with +, along with the
+identical fields around them:
+
+	  {
+	      name: "server"
+	-     port: 8080
+	+     port: 8443
+	  }
+
+Fields whose position relative to the other fields of their struct
+changed are reported as removed and added; --ignore-order ignores the
+order of fields. Hidden fields are not compared.
+
+With --schema, only the differences which affect the schema of the
+values are reported: those in definitions, optional or required fields,
+and constraints which are not concrete, such as a field changed from int
+to string. Changes to concrete data, such as a field changed from 1 to
+2, are ignored.
+
+With --json-patch, the differences are printed as a JSON Patch, as
+defined by RFC 6902, which transforms the JSON encoding of the first
+value into that of the second. Both values must be concrete, and the
+order of fields is ignored, as the fields of a JSON object are unordered.
+
+diff exits with status 1 if the values differ.
+`,
+		RunE: mkRunE(c, runDiff),
+	}
+
+	// The --schema/-d flag for data files is omitted in favor of the
+	// --schema flag selecting the differences to report.
+	orphans := pflag.NewFlagSet("", pflag.ContinueOnError)
+	addOrphanFlags(orphans)
+	orphans.VisitAll(func(f *pflag.Flag) {
+		if f.Name != string(flagSchema) {
+			cmd.Flags().AddFlag(f)
+		}
+	})
+	addInjectionFlags(cmd.Flags(), false, false)
+
+	cmd.Flags().StringArrayP(string(flagExpression), "e", nil, "compare the values of this expression")
+	cmd.Flags().Bool(string(flagIgnoreOrder), false, "ignore the order of fields")
+	cmd.Flags().Bool(string(flagSchema), false, "only report the differences which affect the schema")
+	cmd.Flags().Bool(string(flagJSONPatch), false, "print the differences as a JSON Patch (RFC 6902)")
+	return cmd
+}
+
+func runDiff(cmd *Command, args []string) error {
+	schema, patch := flagSchema.Bool(cmd), flagJSONPatch.Bool(cmd)
+	if schema && patch {
+		return fmt.Errorf("--%s cannot be used with --%s", flagSchema, flagJSONPatch)
+	}
+
+	var exprs []ast.Expr
+	for _, e := range flagExpression.StringArray(cmd) {
+		expr, err := parser.ParseExpr("--expression", e)
+		if err != nil {
+			return err
+		}
+		exprs = append(exprs, expr)
+	}
+
+	// Each input is loaded on its own, such that files of the same package
+	// are not unified.
+	inputs := [][]string{args}
+	if len(args) > 1 {
+		inputs = inputs[:0]
+		for _, arg := range args {
+			inputs = append(inputs, []string{arg})
+		}
+	}
+	var values []cue.Value
+	for _, input := range inputs {
+		b, err := parseArgs(cmd, input, &config{mode: filetypes.Input})
+		if err != nil {
+			return err
+		}
+		iter := b.instances()
+		for iter.scan() {
+			v := iter.value()
+			if len(exprs) == 0 {
+				values = append(values, v)
+			}
+			for _, expr := range exprs {
+				values = append(values, v.Context().BuildExpr(expr,
+					cue.Scope(v),
+					cue.InferBuiltins(true),
+					cue.ImportPath(iter.id()),
+				))
+			}
+		}
+		iter.close()
+		if err := iter.err(); err != nil {
+			return err
+		}
+	}
+	if len(values) != 2 {
+		return fmt.Errorf("diff requires two values, such as two inputs or two expressions given with -e; found %d", len(values))
+	}
+	x, y := values[0], values[1]
+	for _, v := range values {
+		if err := v.Validate(); err != nil {
+			return err
+		}
+	}
+
+	p := &diff.Profile{
+		SkipHidden: true,
+		Ordered:    !flagIgnoreOrder.Bool(cmd) && !patch,
+		SchemaOnly: schema,
+	}
+	kind, es := p.Diff(x, y)
+
+	w := cmd.OutOrStdout()
+	if patch {
+		ops, err := diff.JSONPatch(es)
+		if err != nil {
+			return err
+		}
+		b, err := json.MarshalIndent(ops, "", "    ")
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(w, "%s\n", b)
+		if len(ops) > 0 {
+			return ErrPrintedError
+		}
+		return nil
+	}
+	if kind == diff.Identity {
+		return nil
+	}
+	if err := diff.Print(w, es); err != nil {
+		return err
+	}
+	return ErrPrintedError
+}
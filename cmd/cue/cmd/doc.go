@@ -0,0 +1,235 @@
+// Copyright 2025 The CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"cuelang.org/go/cue"
+	"cuelang.org/go/cue/ast"
+	"cuelang.org/go/cue/build"
+	"cuelang.org/go/internal"
+	"cuelang.org/go/tools/doc"
+)
+
+func newDocCmd(c *Command) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "doc [packages]",
+		Short: "show the documentation of packages",
+		Long: `
+doc prints the documentation of the fields and definitions of the given
+packages: their types, constraints, defaults, and doc comments.
+
+With --http, doc instead serves browsable documentation on the given
+address. The documentation covers the given packages, which default to all
+the packages of the current module, and all the packages they import from
+other modules. Definitions are linked to where they are declared, and the
+search page finds definitions by their name or documentation.
+
+For example:
+
+	$ cue doc --http localhost:6060
+	listening on 127.0.0.1:6060
+`[1:],
+		RunE: mkRunE(c, runDoc),
+	}
+	cmd.Flags().String(string(flagHTTP), "", "serve documentation on this address, such as localhost:6060")
+	return cmd
+}
+
+func runDoc(cmd *Command, args []string) error {
+	addr := flagHTTP.String(cmd)
+	if addr != "" && len(args) == 0 {
+		args = []string{"./..."}
+	}
+	pkgs, err := loadDocPackages(cmd, args, addr != "")
+	if err != nil {
+		return err
+	}
+	if addr == "" {
+		w := cmd.OutOrStdout()
+		for i, p := range pkgs {
+			if i > 0 {
+				fmt.Fprintln(w)
+			}
+			p.writeText(w)
+		}
+		return nil
+	}
+
+	l, err := net.Listen("tcp", addr)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(cmd.OutOrStdout(), "listening on %v\n", l.Addr())
+	srv := &http.Server{
+		Handler:           newDocHandler(pkgs),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	return serveUntilInterrupted(srv, func() error { return srv.Serve(l) })
+}
+
+// A docPackage holds the documentation of a package.
+type docPackage struct {
+	ImportPath string
+	Module     string
+	Name       string
+	Doc        string
+	Fields     []doc.Field
+
+	// Dependency reports whether the package was only loaded as an
+	// import of the requested packages.
+	Dependency bool
+
+	// imports maps the names of the imported packages to their import
+	// paths.
+	imports map[string]string
+}
+
+// loadDocPackages loads the documentation of the packages given by args,
+// followed by the packages they import from other modules if deps is set.
+func loadDocPackages(cmd *Command, args []string, deps bool) ([]*docPackage, error) {
+	cfg, err := defaultConfig()
+	if err != nil {
+		return nil, err
+	}
+	binsts := loadFromArgs(args, cfg.loadCfg)
+	if len(binsts) == 0 {
+		return nil, fmt.Errorf("no packages to document")
+	}
+	var pkgs, depPkgs []*docPackage
+	seen := map[string]bool{}
+	var add func(inst *build.Instance, dep bool) error
+	add = func(inst *build.Instance, dep bool) error {
+		if seen[inst.ImportPath] || len(inst.Files) == 0 {
+			return nil
+		}
+		seen[inst.ImportPath] = true
+		if inst.Err != nil {
+			return inst.Err
+		}
+		p, err := newDocPackage(cmd.ctx, inst)
+		if err != nil {
+			return err
+		}
+		if p.Dependency = dep; dep {
+			depPkgs = append(depPkgs, p)
+		} else {
+			pkgs = append(pkgs, p)
+		}
+		if deps {
+			for _, imp := range inst.Imports {
+				if err := add(imp, dep || imp.Module != inst.Module); err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	}
+	for _, inst := range binsts {
+		if err := add(inst, false); err != nil {
+			return nil, err
+		}
+	}
+	slices.SortFunc(depPkgs, func(a, b *docPackage) int {
+		return strings.Compare(a.ImportPath, b.ImportPath)
+	})
+	return append(pkgs, depPkgs...), nil
+}
+
+func newDocPackage(ctx *cue.Context, inst *build.Instance) (*docPackage, error) {
+	fields, err := doc.Instance(ctx, inst)
+	if err != nil {
+		return nil, err
+	}
+	p := &docPackage{
+		ImportPath: inst.ImportPath,
+		Module:     inst.Module,
+		Name:       inst.PkgName,
+		Fields:     fields,
+		imports:    map[string]string{},
+	}
+	var docs []string
+	for _, f := range inst.Files {
+		pkgDocs, _ := internal.FileComments(f)
+		for _, c := range pkgDocs {
+			if c.Doc {
+				docs = append(docs, strings.TrimSpace(c.Text()))
+			}
+		}
+		for _, spec := range f.Imports {
+			path, err := strconv.Unquote(spec.Path.Value)
+			if err != nil {
+				continue
+			}
+			ip := ast.ParseImportPath(path)
+			name := ip.Qualifier
+			if spec.Name != nil {
+				name = spec.Name.Name
+			}
+			for _, imp := range inst.Imports {
+				if ast.ParseImportPath(imp.ImportPath).Path == ip.Path {
+					p.imports[name] = imp.ImportPath
+				}
+			}
+		}
+	}
+	p.Doc = strings.Join(docs, "\n\n")
+	return p, nil
+}
+
+// writeText writes the documentation of p as plain text.
+func (p *docPackage) writeText(w io.Writer) {
+	fmt.Fprintf(w, "package %s // import %q\n", p.Name, p.ImportPath)
+	if p.Doc != "" {
+		fmt.Fprintf(w, "\n%s\n", p.Doc)
+	}
+	for _, f := range p.Fields {
+		fmt.Fprintf(w, "\n%s: %s\n", docLabel(f), docSummary(f))
+		if f.Doc != "" {
+			fmt.Fprintf(w, "\t%s\n", strings.ReplaceAll(f.Doc, "\n", "\n\t"))
+		}
+	}
+}
+
+// docLabel returns the path of f with its optional or required marker.
+func docLabel(f doc.Field) string {
+	s := f.Path.String()
+	switch {
+	case f.Optional:
+		s += "?"
+	case f.Required:
+		s += "!"
+	}
+	return s
+}
+
+// docSummary returns the type, constraints, and default of f.
+func docSummary(f doc.Field) string {
+	s := strings.Join(append([]string{f.Type}, f.Constraints...), " & ")
+	if f.Default != "" {
+		s += " | *" + f.Default
+	}
+	return s
+}
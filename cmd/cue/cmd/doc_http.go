@@ -0,0 +1,219 @@
+// Copyright 2025 The CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"html/template"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+
+	"cuelang.org/go/tools/doc"
+)
+
+// docHandler serves the documentation of packages as HTML:
+//
+//	/                the list of packages
+//	/pkg/<path>      the documentation of the package with import path <path>
+//	/search?q=<text> the definitions whose name or documentation contains <text>
+type docHandler struct {
+	pkgs   []*docPackage
+	byPath map[string]*docPackage
+	mux    *http.ServeMux
+}
+
+func newDocHandler(pkgs []*docPackage) *docHandler {
+	h := &docHandler{
+		pkgs:   pkgs,
+		byPath: map[string]*docPackage{},
+		mux:    http.NewServeMux(),
+	}
+	for _, p := range pkgs {
+		h.byPath[p.ImportPath] = p
+	}
+	h.mux.HandleFunc("/{$}", h.serveIndex)
+	h.mux.HandleFunc("/pkg/", h.servePackage)
+	h.mux.HandleFunc("/search", h.serveSearch)
+	return h
+}
+
+func (h *docHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	h.mux.ServeHTTP(w, r)
+}
+
+func (h *docHandler) serveIndex(w http.ResponseWriter, r *http.Request) {
+	h.render(w, "index", h.pkgs)
+}
+
+func (h *docHandler) servePackage(w http.ResponseWriter, r *http.Request) {
+	p := h.byPath[strings.TrimPrefix(r.URL.Path, "/pkg/")]
+	if p == nil {
+		http.NotFound(w, r)
+		return
+	}
+	h.render(w, "package", p)
+}
+
+// A docSearchResult is a definition which matches a search.
+type docSearchResult struct {
+	Package *docPackage
+	Field   doc.Field
+}
+
+func (h *docHandler) serveSearch(w http.ResponseWriter, r *http.Request) {
+	q := strings.TrimSpace(r.URL.Query().Get("q"))
+	var results []docSearchResult
+	if q != "" {
+		lq := strings.ToLower(q)
+		for _, p := range h.pkgs {
+			for _, f := range p.Fields {
+				if !f.Definition {
+					continue
+				}
+				if strings.Contains(strings.ToLower(f.Path.String()), lq) ||
+					strings.Contains(strings.ToLower(f.Doc), lq) {
+					results = append(results, docSearchResult{p, f})
+				}
+			}
+		}
+	}
+	h.render(w, "search", map[string]any{"Query": q, "Results": results})
+}
+
+func (h *docHandler) render(w http.ResponseWriter, name string, data any) {
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	if err := docTemplates.ExecuteTemplate(w, name, map[string]any{"H": h, "Data": data}); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+// docRefRE matches references to definitions in types, such as #Port or
+// pkg.#Port.
+var docRefRE = regexp.MustCompile(`(?:([A-Za-z_][A-Za-z0-9_]*)\.)?(#[A-Za-z_][A-Za-z0-9_]*)`)
+
+// typeHTML returns the type of f, with the definitions it refers to linked
+// to their documentation.
+func (h *docHandler) typeHTML(p *docPackage, f doc.Field) template.HTML {
+	var b strings.Builder
+	s := f.Type
+	for _, m := range docRefRE.FindAllStringSubmatchIndex(s, -1) {
+		b.WriteString(template.HTMLEscapeString(s[:m[0]]))
+		ref := s[m[0]:m[1]]
+		href := ""
+		if m[2] < 0 {
+			href = docFieldHref(nil, ref)
+		} else if path, ok := p.imports[s[m[2]:m[3]]]; ok && h.byPath[path] != nil {
+			href = docFieldHref(h.byPath[path], s[m[4]:m[5]])
+		}
+		if href != "" {
+			b.WriteString(`<a href="` + template.HTMLEscapeString(href) + `">` + template.HTMLEscapeString(ref) + `</a>`)
+		} else {
+			b.WriteString(template.HTMLEscapeString(ref))
+		}
+		s = s[m[1]:]
+	}
+	b.WriteString(template.HTMLEscapeString(s))
+	return template.HTML(b.String())
+}
+
+// docPackageHref returns the URL of the documentation of p.
+func docPackageHref(p *docPackage) string {
+	return "/pkg/" + (&url.URL{Path: p.ImportPath}).EscapedPath()
+}
+
+// docFieldHref returns the URL of the documentation of the field with the
+// given path in p, or in the current page if p is nil.
+func docFieldHref(p *docPackage, path string) string {
+	href := "#" + url.PathEscape(path)
+	if p != nil {
+		href = docPackageHref(p) + href
+	}
+	return href
+}
+
+// docFirstSentence returns the first sentence of s.
+func docFirstSentence(s string) string {
+	s, _, _ = strings.Cut(s, "\n\n")
+	if i := strings.Index(s, ". "); i >= 0 {
+		s = s[:i+1]
+	}
+	return strings.Join(strings.Fields(s), " ")
+}
+
+var docTemplates = template.Must(template.New("").Funcs(template.FuncMap{
+	"pkgHref":       docPackageHref,
+	"fieldHref":     docFieldHref,
+	"label":         docLabel,
+	"firstSentence": docFirstSentence,
+	"typeHTML":      (*docHandler).typeHTML,
+	"depth": func(f doc.Field) int {
+		return len(f.Path.Selectors()) - 1
+	},
+}).Parse(`
+{{define "header"}}<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>{{.}}</title>
+<style>
+body { font-family: sans-serif; max-width: 60em; margin: 2em auto; padding: 0 1em; }
+code, pre, .field { font-family: monospace; }
+.field { margin-top: 1.5em; font-weight: bold; }
+.doc { white-space: pre-wrap; margin: 0.3em 0 0 1.5em; }
+.meta { color: #555; }
+nav { border-bottom: 1px solid #ccc; padding-bottom: 0.5em; }
+</style>
+</head>
+<body>
+<nav><a href="/">Packages</a>
+<form action="/search" style="display: inline; float: right">
+<input name="q" placeholder="Search definitions">
+</form></nav>
+{{end}}
+
+{{define "footer"}}</body>
+</html>
+{{end}}
+
+{{define "index"}}{{template "header" "Packages"}}
+<h1>Packages</h1>
+<ul>
+{{range .Data}}{{if not .Dependency}}<li><a href="{{pkgHref .}}">{{.ImportPath}}</a>{{with .Doc}} &ndash; {{firstSentence .}}{{end}}</li>
+{{end}}{{end}}</ul>
+<h2>Dependencies</h2>
+<ul>
+{{range .Data}}{{if .Dependency}}<li><a href="{{pkgHref .}}">{{.ImportPath}}</a>{{with .Doc}} &ndash; {{firstSentence .}}{{end}}</li>
+{{end}}{{end}}</ul>
+{{template "footer"}}{{end}}
+
+{{define "package"}}{{template "header" .Data.ImportPath}}{{$h := .H}}{{$p := .Data}}
+<h1>package {{$p.Name}}</h1>
+<p><code>import "{{$p.ImportPath}}"</code>{{with $p.Module}} <span class="meta">in module {{.}}</span>{{end}}</p>
+{{with $p.Doc}}<div class="doc">{{.}}</div>{{end}}
+{{range $p.Fields}}{{$depth := depth .}}
+<div class="field" id="{{.Path}}" style="margin-left: {{$depth}}em"><a href="{{fieldHref nil (print .Path)}}">{{label .}}</a>: {{typeHTML $h $p .}}{{range .Constraints}} &amp; {{.}}{{end}}{{with .Default}} | *{{.}}{{end}}</div>
+{{with .Doc}}<div class="doc" style="margin-left: {{$depth}}em">{{.}}</div>{{end}}
+{{end}}
+{{template "footer"}}{{end}}
+
+{{define "search"}}{{template "header" "Search"}}
+<h1>Definitions matching &ldquo;{{.Data.Query}}&rdquo;</h1>
+<ul>
+{{range .Data.Results}}<li><a href="{{fieldHref .Package (print .Field.Path)}}">{{.Field.Path}}</a> in {{.Package.ImportPath}}{{with .Field.Doc}} &ndash; {{firstSentence .}}{{end}}</li>
+{{else}}<li>No definitions found.</li>
+{{end}}</ul>
+{{template "footer"}}{{end}}
+`))
@@ -0,0 +1,120 @@
+// Copyright 2025 CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-quicktest/qt"
+
+	"cuelang.org/go/cue/cuecontext"
+	"cuelang.org/go/tools/doc"
+)
+
+func TestDocHandler(t *testing.T) {
+	ctx := cuecontext.New()
+	extract := func(src string) []doc.Field {
+		t.Helper()
+		v := ctx.CompileString(src)
+		qt.Assert(t, qt.IsNil(v.Err()))
+		fields, err := doc.Extract(v)
+		qt.Assert(t, qt.IsNil(err))
+		return fields
+	}
+	server := &docPackage{
+		ImportPath: "test.example/doc/server@v0",
+		Module:     "test.example/doc@v0",
+		Name:       "server",
+		Doc:        "Package server configures servers. It has a schema.",
+		Fields: extract(`
+// #Server configures a server.
+#Server: {
+	// port is the port to listen on.
+	port: #Port | *8080
+}
+#Port: int
+`),
+		imports: map[string]string{"types": "other.example/types@v0"},
+	}
+	// The type of a field referring to an imported package is written as
+	// it is in the source.
+	server.Fields[1].Type = "types.#Port"
+	types := &docPackage{
+		ImportPath: "other.example/types@v0",
+		Module:     "other.example@v0",
+		Name:       "types",
+		Fields: extract(`
+// #Port is a TCP port.
+#Port: int & >=1 & <=65535
+`),
+		Dependency: true,
+	}
+	h := newDocHandler([]*docPackage{server, types})
+
+	get := func(target string) *httptest.ResponseRecorder {
+		t.Helper()
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, httptest.NewRequest("GET", target, nil))
+		return w
+	}
+
+	w := get("/")
+	qt.Assert(t, qt.Equals(w.Code, http.StatusOK))
+	qt.Assert(t, qt.StringContains(w.Body.String(),
+		`<li><a href="/pkg/test.example/doc/server@v0">test.example/doc/server@v0</a> &ndash; Package server configures servers.</li>`))
+	qt.Assert(t, qt.StringContains(w.Body.String(),
+		`<h2>Dependencies</h2>
+<ul>
+<li><a href="/pkg/other.example/types@v0">other.example/types@v0</a></li>`))
+
+	w = get("/pkg/test.example/doc/server@v0")
+	qt.Assert(t, qt.Equals(w.Code, http.StatusOK))
+	qt.Assert(t, qt.StringContains(w.Body.String(), `<h1>package server</h1>`))
+	qt.Assert(t, qt.StringContains(w.Body.String(),
+		`<a href="/pkg/other.example/types@v0#%23Port">types.#Port</a> | *8080`))
+	qt.Assert(t, qt.StringContains(w.Body.String(),
+		`<div class="field" id="#Port" style="margin-left: 0em"><a href="#%23Port">#Port</a>: int</div>`))
+
+	w = get("/pkg/other.example/types@v0")
+	qt.Assert(t, qt.Equals(w.Code, http.StatusOK))
+	qt.Assert(t, qt.StringContains(w.Body.String(), `#Port</a>: int &amp; &gt;=1 &amp; &lt;=65535</div>`))
+
+	w = get("/search?q=tcp")
+	qt.Assert(t, qt.Equals(w.Code, http.StatusOK))
+	qt.Assert(t, qt.StringContains(w.Body.String(),
+		`<li><a href="/pkg/other.example/types@v0#%23Port">#Port</a> in other.example/types@v0 &ndash; #Port is a TCP port.</li>`))
+	qt.Assert(t, qt.Not(qt.StringContains(w.Body.String(), "#Server")))
+
+	w = get("/search?q=nothing")
+	qt.Assert(t, qt.StringContains(w.Body.String(), "No definitions found."))
+
+	w = get("/pkg/unknown.example/pkg")
+	qt.Assert(t, qt.Equals(w.Code, http.StatusNotFound))
+}
+
+// TestServeUntilInterruptedFails checks that cue doc --http, like cue
+// serve, returns when the server fails rather than waiting for an
+// interrupt.
+func TestServeUntilInterruptedFails(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	qt.Assert(t, qt.IsNil(err))
+	l.Close()
+	srv := &http.Server{Handler: newDocHandler(nil)}
+	err = serveUntilInterrupted(srv, func() error { return srv.Serve(l) })
+	qt.Assert(t, qt.ErrorMatches(err, `.*use of closed network connection`))
+}
@@ -48,8 +48,13 @@ Examples:
   $ cue eval foo.cue -e a[0] -e a[2]
   "a"
   "c"
+
+With --watch, eval keeps running after the first evaluation, and
+evaluates and prints the configuration again whenever input files within
+the main module, the current directory, or the directories of the files
+and packages given as arguments change, until interrupted.
 `,
-		RunE: mkRunE(c, runEval),
+		RunE: mkRunE(c, doEval),
 	}
 
 	addOutFlags(cmd.Flags(), true)
@@ -73,6 +78,9 @@ Examples:
 	cmd.Flags().BoolP(string(flagAll), "a", false,
 		"show optional and hidden fields")
 
+	cmd.Flags().Bool(string(flagWatch), false,
+		"evaluate again whenever the inputs change")
+
 	// TODO: Option to include comments in output.
 	return cmd
 }
@@ -84,6 +92,20 @@ const (
 	flagAttributes flagName = "show-attributes"
 )
 
+func doEval(cmd *Command, args []string) error {
+	if !flagWatch.Bool(cmd) {
+		return runEval(cmd, args)
+	}
+	outputs := watchOutputs(cmd, filetypes.Eval)
+	return watchCommand(cmd, watchRoots(args), outputs, "evaluating", func() error {
+		if err := runEval(cmd, args); err != nil {
+			return err
+		}
+		return cmd.Flags().Set(string(flagForce), "true")
+	})
+}
+
+// runEval evaluates the inputs once.
 func runEval(cmd *Command, args []string) error {
 	b, err := parseArgs(cmd, args, &config{mode: filetypes.Eval})
 	if err != nil {
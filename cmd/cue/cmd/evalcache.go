@@ -0,0 +1,326 @@
+// Copyright 2025 CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"cuelang.org/go/cue"
+	"cuelang.org/go/cue/ast"
+	"cuelang.org/go/cue/build"
+	"cuelang.org/go/cue/format"
+	"cuelang.org/go/cue/parser"
+	"cuelang.org/go/cue/token"
+	"cuelang.org/go/internal/core/adt"
+	"cuelang.org/go/internal/core/export"
+	"cuelang.org/go/internal/cueconfig"
+	"cuelang.org/go/internal/cueexperiment"
+	"cuelang.org/go/internal/value"
+)
+
+// useEvalCache replaces the files of the packages imported by insts with
+// their evaluated form, as cached by an earlier invocation, and caches
+// those which were not cached yet. Only packages which do not depend on
+// tags or extern interpreters, directly or through their imports, are
+// cached, as their evaluated form then only depends on the contents of
+// their files.
+//
+// Each cache entry consists of a CUE file with the evaluated form of a
+// package and a JSON file with the positions of its fields and list
+// elements in the original sources, so that errors still refer to those.
+//
+// It is a no-op unless CUE_EXPERIMENT=evalcache is set.
+func useEvalCache(cmd *Command, insts []*build.Instance) error {
+	if !cueexperiment.Flags.EvalCache {
+		return nil
+	}
+	dir, err := cueconfig.CacheDir(os.Getenv)
+	if err != nil {
+		return err
+	}
+	tool := sha256.New()
+	if err := hashTool(tool); err != nil {
+		return err
+	}
+	c := &evalCache{
+		cmd:   cmd,
+		dir:   filepath.Join(dir, "eval"),
+		tool:  tool.Sum(nil),
+		pure:  map[*build.Instance]bool{},
+		files: map[string]*token.File{},
+	}
+	for _, inst := range insts {
+		for _, imp := range inst.Imports {
+			if err := c.use(imp); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+type evalCache struct {
+	cmd  *Command
+	dir  string
+	tool []byte // hash of the cue tool, see [hashTool]
+
+	// pure records the packages visited so far, and whether they
+	// could be cached.
+	pure map[*build.Instance]bool
+
+	// files holds the original source files of the packages visited so
+	// far, by name, to which the positions of cached values refer.
+	files map[string]*token.File
+}
+
+// A cachePos is the position of a value in its original source. Fields
+// and their labels get the position of their value.
+type cachePos struct {
+	File   string `json:"file"`
+	Offset int    `json:"offset"`
+}
+
+// use replaces the files of inst with their cached evaluated form, after
+// doing so for the packages it imports, and records in c.pure whether it
+// could do so.
+func (c *evalCache) use(inst *build.Instance) error {
+	if _, ok := c.pure[inst]; ok {
+		return nil
+	}
+	c.pure[inst] = false
+	if inst.Err != nil || len(inst.Files) == 0 {
+		return nil
+	}
+	for _, f := range inst.Files {
+		if tf := f.Pos().File(); tf != nil {
+			c.files[tf.Name()] = tf
+		}
+	}
+	for _, imp := range inst.Imports {
+		if err := c.use(imp); err != nil {
+			return err
+		}
+		if !c.pure[imp] {
+			return nil
+		}
+	}
+	for _, f := range inst.Files {
+		if usesTag(f) || usesExtern(f) {
+			return nil
+		}
+	}
+
+	h := sha256.New()
+	h.Write(c.tool)
+	// hashInstances reads the original files of inst and its imports,
+	// so the key does not depend on whether those were cached.
+	ok, err := hashInstances(h, []*build.Instance{inst})
+	if err != nil || !ok {
+		return err
+	}
+	key := hex.EncodeToString(h.Sum(nil))
+	path := filepath.Join(c.dir, key[:2], key+".cue")
+	posPath := strings.TrimSuffix(path, ".cue") + ".pos.json"
+
+	var positions map[string]cachePos
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		data, positions, err = c.evaluate(inst)
+		if err != nil || data == nil {
+			return err
+		}
+		posData, err := json.Marshal(positions)
+		if err != nil {
+			return err
+		}
+		// Write the CUE file last, as its presence marks a complete entry.
+		if err := writeCacheFile(posPath, posData); err != nil {
+			return err
+		}
+		if err := writeCacheFile(path, data); err != nil {
+			return err
+		}
+	} else if err != nil {
+		return err
+	} else {
+		posData, err := os.ReadFile(posPath)
+		if err != nil || json.Unmarshal(posData, &positions) != nil {
+			// A corrupt entry; evaluate the package from its sources.
+			return nil
+		}
+	}
+	f, err := parser.ParseFile(path, data, parser.ParseComments)
+	if err != nil {
+		// A corrupt entry; evaluate the package from its sources.
+		return nil
+	}
+	c.setPositions(f, inst.ID(), positions)
+	inst.Files = []*ast.File{f}
+	c.pure[inst] = true
+	return nil
+}
+
+// setPositions sets the positions of the nodes of f, the cached form of
+// the package pkg, to those of the values in the original sources, so
+// that errors refer to those rather than to the cache. Each node gets
+// the position of the innermost field or list element containing it.
+func (c *evalCache) setPositions(f *ast.File, pkg string, positions map[string]cachePos) {
+	walkValuePaths(f, pkg, func(n ast.Node, p cue.Path, _ bool) {
+		cp, ok := positions[p.String()]
+		if !ok {
+			return
+		}
+		if tf := c.files[cp.File]; tf != nil && cp.Offset <= tf.Size() {
+			ast.SetPos(n, tf.Pos(cp.Offset, token.NoRelPos))
+		}
+	})
+}
+
+// evaluate returns the evaluated form of inst, or nil if it has errors,
+// along with the positions of its values keyed by their paths.
+// Packages are evaluated within the context of the command, so that
+// their evaluation is shared with the instances which import them.
+func (c *evalCache) evaluate(inst *build.Instance) ([]byte, map[string]cachePos, error) {
+	v := c.cmd.ctx.BuildInstance(inst)
+	if v.Validate() != nil {
+		return nil, nil, nil
+	}
+	r, vx := value.ToInternal(v)
+	f, xerr := export.All.Vertex(r, inst.ID(), vx)
+	if xerr != nil {
+		return nil, nil, nil
+	}
+	positions := map[string]cachePos{}
+	walkValuePaths(f, inst.ID(), func(_ ast.Node, p cue.Path, own bool) {
+		if !own {
+			return
+		}
+		pos := valuePos(v.LookupPath(p))
+		if tf := pos.File(); tf != nil {
+			positions[p.String()] = cachePos{File: tf.Name(), Offset: pos.Offset()}
+		}
+	})
+	data, err := format.Node(f)
+	return data, positions, err
+}
+
+// valuePos returns the position of the first expression which defines v,
+// which, unlike [cue.Value.Pos], is not the position of its label.
+func valuePos(v cue.Value) token.Pos {
+	pos := token.NoPos
+	if vx := value.Vertex(v); vx != nil {
+		vx.VisitLeafConjuncts(func(c adt.Conjunct) bool {
+			if src := c.Elem().Source(); src != nil {
+				pos = src.Pos()
+			}
+			return !pos.IsValid()
+		})
+	}
+	if !pos.IsValid() {
+		pos = v.Pos()
+	}
+	return pos
+}
+
+// walkValuePaths calls fn for each node of f, the evaluated form of the
+// package pkg, along with the path of the innermost field or list element
+// containing it, and whether the node is that field or element itself.
+// Fields which are not regular fields or definitions, such as pattern
+// constraints, are not visited.
+func walkValuePaths(f *ast.File, pkg string, fn func(n ast.Node, p cue.Path, own bool)) {
+	type frame struct {
+		path  []cue.Selector
+		list  bool // the node is a list, whose elements are counted by index
+		index int
+	}
+	stack := []*frame{{}}
+	ast.Walk(f, func(n ast.Node) bool {
+		top := stack[len(stack)-1]
+		fr := &frame{path: top.path}
+		own := false
+		switch x := n.(type) {
+		case *ast.Field:
+			sel, ok := fieldSelector(x, pkg)
+			if !ok {
+				return false
+			}
+			fr.path, own = append(slices.Clip(top.path), sel), true
+		case *ast.CommentGroup, *ast.Comment:
+		default:
+			if top.list {
+				fr.path, own = append(slices.Clip(top.path), cue.Index(top.index)), true
+				top.index++
+			}
+		}
+		_, fr.list = n.(*ast.ListLit)
+		stack = append(stack, fr)
+		fn(n, cue.MakePath(fr.path...), own)
+		return true
+	}, func(ast.Node) {
+		stack = stack[:len(stack)-1]
+	})
+}
+
+// fieldSelector returns the selector of the field f of the package pkg.
+func fieldSelector(f *ast.Field, pkg string) (cue.Selector, bool) {
+	var sel cue.Selector
+	switch l := f.Label.(type) {
+	case *ast.Ident:
+		if strings.HasPrefix(l.Name, "_") {
+			if !ast.IsValidIdent(l.Name) || l.Name == "_" {
+				return sel, false
+			}
+			sel = cue.Hid(l.Name, pkg)
+		} else {
+			sel = cue.Label(l)
+		}
+	case *ast.BasicLit:
+		sel = cue.Label(l)
+	default:
+		return sel, false
+	}
+	if cue.MakePath(sel).Err() != nil {
+		return sel, false
+	}
+	switch f.Constraint {
+	case token.OPTION:
+		sel = sel.Optional()
+	case token.NOT:
+		sel = sel.Required()
+	}
+	return sel, true
+}
+
+// usesTag reports whether f declares a field which can be set with a tag.
+func usesTag(f *ast.File) bool {
+	found := false
+	ast.Walk(f, func(n ast.Node) bool {
+		if a, ok := n.(*ast.Attribute); ok {
+			if key, _ := a.Split(); key == "tag" {
+				found = true
+			}
+		}
+		return !found
+	}, nil)
+	return found
+}
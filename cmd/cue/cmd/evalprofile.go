@@ -0,0 +1,211 @@
+// Copyright 2025 The CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"compress/gzip"
+	"io"
+	"time"
+
+	"cuelang.org/go/cue/errors"
+	"cuelang.org/go/internal/core/adt"
+)
+
+// writeEvalProfile writes the evaluation profile in samples to w in the
+// gzipped protocol buffer format used by pprof, so that it can be viewed
+// with tools such as "go tool pprof -http". Each value is shown as a
+// function named after its path, located at the position of its first
+// conjunct, with file names shown as configured by cfg.
+//
+// See https://github.com/google/pprof/blob/main/proto/profile.proto.
+func writeEvalProfile(w io.Writer, cfg *errors.Config, samples []adt.EvalSample, start time.Time, duration time.Duration) error {
+	p := &pprofBuilder{
+		strings:   map[string]int64{"": 0},
+		stringTab: []string{""},
+		functions: map[pprofFunction]uint64{},
+		locations: map[pprofLocation]uint64{},
+	}
+	var b protoBuffer
+	p.valueType(&b, 1, "samples", "count")
+	p.valueType(&b, 1, "time", "nanoseconds")
+	for _, s := range samples {
+		ids := make([]uint64, len(s.Stack))
+		for i, f := range s.Stack {
+			name := f.Path
+			if name == "" {
+				name = "(root)"
+			}
+			var file string
+			var line int64
+			if f.Pos.IsValid() {
+				file = diagnosticFilename(f.Pos.Filename(), cfg)
+				line = int64(f.Pos.Line())
+			}
+			ids[i] = p.location(name, file, line)
+		}
+		b.message(2, func(b *protoBuffer) {
+			b.packed(1, ids)
+			b.packed(2, []uint64{uint64(s.Count), uint64(s.Time.Nanoseconds())})
+		})
+	}
+	for _, l := range p.locationTab {
+		b.message(4, func(b *protoBuffer) {
+			b.uint64(1, p.locations[l])
+			b.message(4, func(b *protoBuffer) {
+				b.uint64(1, l.function)
+				b.int64(2, l.line)
+			})
+		})
+	}
+	for _, f := range p.functionTab {
+		b.message(5, func(b *protoBuffer) {
+			b.uint64(1, p.functions[f])
+			b.int64(2, f.name)
+			b.int64(3, f.name)
+			b.int64(4, f.file)
+		})
+	}
+	// The string table must be written last, as the entries above add to it.
+	timeIdx, nanosIdx := p.string("time"), p.string("nanoseconds")
+	for _, s := range p.stringTab {
+		b.string(6, s)
+	}
+	b.int64(9, start.UnixNano())
+	b.int64(10, duration.Nanoseconds())
+	b.message(11, func(b *protoBuffer) {
+		b.int64(1, timeIdx)
+		b.int64(2, nanosIdx)
+	})
+	b.int64(12, 1)
+
+	zw := gzip.NewWriter(w)
+	if _, err := zw.Write(b.buf); err != nil {
+		return err
+	}
+	return zw.Close()
+}
+
+type pprofFunction struct {
+	name, file int64 // indices into the string table
+}
+
+type pprofLocation struct {
+	function uint64
+	line     int64
+}
+
+// pprofBuilder assigns the IDs of the functions, locations, and strings of
+// a profile.
+type pprofBuilder struct {
+	strings   map[string]int64
+	stringTab []string
+
+	functions   map[pprofFunction]uint64
+	functionTab []pprofFunction
+
+	locations   map[pprofLocation]uint64
+	locationTab []pprofLocation
+}
+
+func (p *pprofBuilder) string(s string) int64 {
+	i, ok := p.strings[s]
+	if !ok {
+		i = int64(len(p.stringTab))
+		p.strings[s] = i
+		p.stringTab = append(p.stringTab, s)
+	}
+	return i
+}
+
+func (p *pprofBuilder) location(name, file string, line int64) uint64 {
+	f := pprofFunction{p.string(name), p.string(file)}
+	fid, ok := p.functions[f]
+	if !ok {
+		fid = uint64(len(p.functionTab) + 1)
+		p.functions[f] = fid
+		p.functionTab = append(p.functionTab, f)
+	}
+	l := pprofLocation{fid, line}
+	lid, ok := p.locations[l]
+	if !ok {
+		lid = uint64(len(p.locationTab) + 1)
+		p.locations[l] = lid
+		p.locationTab = append(p.locationTab, l)
+	}
+	return lid
+}
+
+func (p *pprofBuilder) valueType(b *protoBuffer, tag int, typ, unit string) {
+	b.message(tag, func(b *protoBuffer) {
+		b.int64(1, p.string(typ))
+		b.int64(2, p.string(unit))
+	})
+}
+
+// protoBuffer encodes protocol buffer messages.
+type protoBuffer struct {
+	buf []byte
+}
+
+func (b *protoBuffer) varint(x uint64) {
+	for x >= 0x80 {
+		b.buf = append(b.buf, byte(x)|0x80)
+		x >>= 7
+	}
+	b.buf = append(b.buf, byte(x))
+}
+
+func (b *protoBuffer) key(tag, wireType int) {
+	b.varint(uint64(tag)<<3 | uint64(wireType))
+}
+
+// uint64 encodes a varint field, omitting it if it has the default value.
+func (b *protoBuffer) uint64(tag int, x uint64) {
+	if x == 0 {
+		return
+	}
+	b.key(tag, 0)
+	b.varint(x)
+}
+
+func (b *protoBuffer) int64(tag int, x int64) {
+	b.uint64(tag, uint64(x))
+}
+
+// string encodes a string field. It is never omitted, as the entries of
+// the string table are identified by their index.
+func (b *protoBuffer) string(tag int, s string) {
+	b.key(tag, 2)
+	b.varint(uint64(len(s)))
+	b.buf = append(b.buf, s...)
+}
+
+func (b *protoBuffer) packed(tag int, xs []uint64) {
+	var p protoBuffer
+	for _, x := range xs {
+		p.varint(x)
+	}
+	b.key(tag, 2)
+	b.varint(uint64(len(p.buf)))
+	b.buf = append(b.buf, p.buf...)
+}
+
+func (b *protoBuffer) message(tag int, f func(*protoBuffer)) {
+	var m protoBuffer
+	f(&m)
+	b.key(tag, 2)
+	b.varint(uint64(len(m.buf)))
+	b.buf = append(b.buf, m.buf...)
+}
@@ -0,0 +1,57 @@
+// Copyright 2025 The CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+	"testing"
+	"time"
+
+	"cuelang.org/go/cue/errors"
+	"cuelang.org/go/internal/core/adt"
+)
+
+func TestWriteEvalProfile(t *testing.T) {
+	samples := []adt.EvalSample{{
+		Stack: []adt.EvalFrame{{Path: "a.b"}, {Path: "a"}},
+		Count: 2,
+		Time:  300 * time.Microsecond,
+	}, {
+		Stack: []adt.EvalFrame{{Path: "a"}},
+		Count: 1,
+		Time:  time.Millisecond,
+	}}
+	var buf bytes.Buffer
+	if err := writeEvalProfile(&buf, &errors.Config{}, samples, time.Unix(0, 0), time.Second); err != nil {
+		t.Fatal(err)
+	}
+	zr, err := gzip.NewReader(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := io.ReadAll(zr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The string table holds each string as a length-delimited field 6.
+	for _, s := range []string{"samples", "count", "time", "nanoseconds", "a", "a.b"} {
+		field := append([]byte{6<<3 | 2, byte(len(s))}, s...)
+		if n := bytes.Count(data, field); n != 1 {
+			t.Errorf("string %q occurs %d times in the string table; want 1", s, n)
+		}
+	}
+}
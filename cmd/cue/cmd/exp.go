@@ -91,6 +91,12 @@ The default is "zero", representing a missing field as the zero value.
 	nested: {
 		@go(,optional=nillable) // set for all fields under this struct
 	}
+
+Other field attributes are generated as struct tags, so that tags recorded
+by cue get go are restored. A @json attribute replaces the generated json tag.
+Attributes with a meaning in CUE, such as @tag or @embed, are not carried over.
+
+	id: int @json(id,string) @db(user_id) // generates json:"id,string" db:"user_id"
 `[1:],
 		// TODO: write a long help text once the feature set is reasonably stable.
 		RunE: mkRunE(c, runExpGenGoTypes),
@@ -0,0 +1,101 @@
+// Copyright 2025 The CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+
+	"cuelang.org/go/cue/errors"
+	"cuelang.org/go/internal/core/adt"
+)
+
+func newExplainCmd(c *Command) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "explain [code]",
+		Short: "explain an error code",
+		Long: `Explain prints an explanation of an error code, such as E1001,
+along with an example of the error and common ways to fix it.
+
+Error codes are included in machine-readable diagnostics; see
+'cue help diagnostics'. Without arguments, explain lists all error codes.
+
+Example:
+
+	$ cue explain E1001
+`,
+		Args: cobra.MaximumNArgs(1),
+		RunE: mkRunE(c, runExplain),
+	}
+	return cmd
+}
+
+func runExplain(cmd *Command, args []string) error {
+	w := cmd.OutOrStdout()
+	if len(args) == 0 {
+		tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+		for _, code := range errors.Codes() {
+			fmt.Fprintf(tw, "%s\t%s\n", code, code.Description())
+		}
+		return tw.Flush()
+	}
+	code := errors.Code(strings.ToUpper(args[0]))
+	x, ok := explanations[code]
+	if !ok {
+		var codes []string
+		for _, c := range errors.Codes() {
+			codes = append(codes, string(c))
+		}
+		if s := adt.Suggest(string(code), codes); len(s) > 0 {
+			return fmt.Errorf("unknown error code %q; did you mean %s?", args[0], strings.Join(s, " or "))
+		}
+		return fmt.Errorf("unknown error code %q; run 'cue explain' to list all codes", args[0])
+	}
+	x.write(w, code)
+	return nil
+}
+
+// An explanation describes a class of errors identified by a code.
+type explanation struct {
+	// text explains the cause of the error in one or more paragraphs.
+	text string
+
+	// example is CUE which produces the error.
+	example string
+
+	// fixes are common ways of fixing the error.
+	fixes []string
+}
+
+func (x *explanation) write(w io.Writer, code errors.Code) {
+	fmt.Fprintf(w, "%s: %s\n\n", code, code.Description())
+	fmt.Fprintf(w, "%s\n", x.text)
+	if x.example != "" {
+		fmt.Fprintf(w, "\nExample:\n\n")
+		for _, line := range strings.Split(x.example, "\n") {
+			fmt.Fprintf(w, "\t%s\n", line)
+		}
+	}
+	if len(x.fixes) > 0 {
+		fmt.Fprintf(w, "\nCommon fixes:\n\n")
+		for _, fix := range x.fixes {
+			fmt.Fprintf(w, "  - %s\n", fix)
+		}
+	}
+}
@@ -0,0 +1,234 @@
+// Copyright 2025 The CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import "cuelang.org/go/cue/errors"
+
+// explanations holds the explanation printed by cue explain for each error
+// code. Every code returned by [errors.Codes] must have an entry.
+var explanations = map[errors.Code]*explanation{
+	errors.ConflictingValues: {
+		text: `Two or more values were unified which cannot both hold. CUE values
+are never overridden: a field declared in several places must be
+consistent in all of them.`,
+		example: `a: 1
+a: 2 // a: conflicting values 2 and 1`,
+		fixes: []string{
+			"remove or change one of the conflicting declarations",
+			"use a default, such as *1 | int, where a value may be replaced",
+			"check that the types of the values agree, such as string and int",
+		},
+	},
+	errors.OutOfBound: {
+		text: `A value does not satisfy a bound, such as >=0 or <10, which it was
+unified with.`,
+		example: `#Port: >0 & <65536
+port: #Port & 70000 // port: invalid value 70000 (out of bound <65536)`,
+		fixes: []string{
+			"change the value to lie within the bound",
+			"relax the bound if the value should be allowed",
+		},
+	},
+	errors.ValidatorFailed: {
+		text: `A value does not satisfy a validator, such as a builtin like
+strings.MinRunes or a regular expression match with =~.`,
+		example: `name: =~"^[a-z]+$"
+name: "Alice" // name: invalid value "Alice" (out of bound =~"^[a-z]+$")`,
+		fixes: []string{
+			"change the value so that it satisfies the validator",
+			"check the arguments of the validator, such as the regular expression",
+		},
+	},
+	errors.FieldNotAllowed: {
+		text: `A field was added to a closed struct which does not declare it.
+Definitions, such as #Config, are closed: values unified with them may
+only have the fields which the definition declares.`,
+		example: `#Config: name: string
+c: #Config & {nmae: "x"} // c.nmae: field not allowed`,
+		fixes: []string{
+			"check the field name for typos",
+			"declare the field in the definition",
+			"add ... to the definition to allow any field",
+		},
+	},
+	errors.ExplicitError: {
+		text: `The configuration contains an explicit error, written as _|_ or
+produced by a builtin such as error, which was reached during evaluation.`,
+		example: `x: _|_ // explicit error (_|_ literal) in source`,
+		fixes: []string{
+			"remove the error or the path which leads to it",
+			"if the error is in a disjunct, make sure another disjunct applies",
+		},
+	},
+	errors.InvalidOperation: {
+		text: `An operator or builtin was applied to operands of types it does not
+support, such as adding a string to a number.`,
+		example: `a: "1" + 2 // a: invalid operands "1" and 2 to '+' (type string and int)`,
+		fixes: []string{
+			"convert an operand, for example with strconv.Atoi or strconv.FormatInt",
+			"check that the operands are the values you expect",
+		},
+	},
+	errors.IndexOutOfRange: {
+		text: `A list was indexed at a position beyond its length.`,
+		example: `l: [1, 2, 3]
+x: l[3] // x: index out of range [3] with length 3`,
+		fixes: []string{
+			"use an index which is less than the length of the list",
+			"check the length of the list with len before indexing it",
+		},
+	},
+	errors.UndefinedField: {
+		text: `A selector or index refers to a field which the struct does not
+have.`,
+		example: `a: b: 1
+x: a.c // x: undefined field: c`,
+		fixes: []string{
+			"check the field name for typos",
+			"declare the field, or use a conditional to guard the reference",
+		},
+	},
+	errors.ReferenceNotFound: {
+		text: `An identifier does not refer to any field, let, alias, or import in
+scope.`,
+		example: `a: 1
+b: c // b: reference "c" not found`,
+		fixes: []string{
+			"check the identifier for typos",
+			"declare the field in an enclosing scope",
+			"import the package the identifier belongs to",
+			"quote the label, as in \"c\", if it is not meant as a reference",
+		},
+	},
+	errors.EmptyDisjunction: {
+		text: `None of the disjuncts of a disjunction could be unified with a value.
+The errors of the individual disjuncts are reported along with it.`,
+		example: `x: "a" | "b"
+x: "c" // x: 2 errors in empty disjunction`,
+		fixes: []string{
+			"change the value to match one of the disjuncts",
+			"add a disjunct which matches the value",
+		},
+	},
+	errors.NonConcreteValue: {
+		text: `A value which is not concrete, such as a type like int, was used
+where a concrete value is needed, for example as an operand or in an
+export.`,
+		example: `a: int
+b: a + 1 // b: non-concrete value int in operand to +`,
+		fixes: []string{
+			"give the value a concrete value, such as a: 1",
+			"give the value a default, such as a: *0 | int",
+		},
+	},
+	errors.IncompleteValue: {
+		text: `A value was not fully specified when a concrete value was required,
+for example when exporting or when vetting with -c.`,
+		example: `name: string // with cue export: name: incomplete value string`,
+		fixes: []string{
+			"set the field to a concrete value",
+			"provide the missing data, for example with an extra input file or with -t",
+		},
+	},
+	errors.RequiredFieldMissing: {
+		text: `A required field, marked with ! in its declaration, was not set.`,
+		example: `#Person: name!: string
+p: #Person & {} // p.name: field is required but not present`,
+		fixes: []string{
+			"set the required field",
+			"make the field optional with ? if it need not be set",
+		},
+	},
+	errors.UnresolvedDisjunction: {
+		text: `A disjunction with several remaining disjuncts and no default was
+used where a single concrete value is needed.`,
+		example: `x: "a" | "b" // with cue export: x: incomplete value "a" | "b"`,
+		fixes: []string{
+			"set the field to one of the disjuncts",
+			"mark one of the disjuncts as the default with *",
+		},
+	},
+	errors.StructuralCycle: {
+		text: `A value contains itself, so that evaluating it would produce an
+infinitely deep structure.`,
+		example: `a: b: a // a.b: structural cycle`,
+		fixes: []string{
+			"break the cycle, for example by referring to a definition with an optional field",
+			"check references which point to an enclosing struct",
+		},
+	},
+	errors.ReferenceCycle: {
+		text: `The value of a field depends on itself, so it cannot be determined.`,
+		example: `a: b + 1
+b: a - 1 // a: cycle with field: b`,
+		fixes: []string{
+			"set one of the fields in the cycle to a concrete value",
+			"rewrite the expressions so that they do not depend on each other",
+		},
+	},
+	errors.SyntaxError: {
+		text: `The input is not valid CUE.`,
+		example: `a: {
+	b: 1 // expected '}', found 'EOF'`,
+		fixes: []string{
+			"check for unbalanced braces, brackets, and quotes",
+			"run cue fmt to locate the problem",
+		},
+	},
+	errors.InvalidJSON: {
+		text: `The input is not valid JSON. JSON is stricter than CUE: it does not
+allow comments, trailing commas, or unquoted keys.`,
+		example: `{"a": 1,} // invalid JSON: invalid character '}' looking for beginning of object key string`,
+		fixes: []string{
+			"remove comments and trailing commas",
+			"quote object keys",
+			"use a .cue file if the input is meant to be CUE",
+		},
+	},
+	errors.InvalidYAML: {
+		text: `The input is not valid YAML, or it uses a YAML feature which CUE
+does not support.`,
+		example: `a: b: c // mapping values are not allowed in this context`,
+		fixes: []string{
+			"check the indentation of the input",
+			"quote strings which contain a colon followed by a space",
+		},
+	},
+	errors.DeprecatedField: {
+		text: `A field marked as deprecated with a @deprecated attribute is set.
+This is a warning, reported by the Warnings method of values in the Go
+API; the attribute's argument, if any, explains what to use instead.`,
+		example: `#S: {
+	old?: int @deprecated("use new instead")
+	new?: int
+}
+s: #S & {old: 1} // s.old: field is deprecated: use new instead`,
+		fixes: []string{
+			"use the replacement described by the warning",
+			"remove the field if it is no longer needed",
+		},
+	},
+	errors.LossyConversion: {
+		text: `A number cannot be represented exactly as a 64-bit floating point
+number, so it loses precision when converted to one, for example when
+decoded into a Go value or encoded as TOML. This is a warning, reported
+by the Warnings method of values in the Go API.`,
+		example: `pi: 3.141592653589793238462643383279 // pi: number 3.141592653589793238462643383279 loses precision when converted to float64`,
+		fixes: []string{
+			"round the number to the precision of a float64",
+			"use a string if the exact value must be kept",
+		},
+	},
+}
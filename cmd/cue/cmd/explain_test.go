@@ -0,0 +1,37 @@
+// Copyright 2025 The CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"testing"
+
+	"cuelang.org/go/cue/errors"
+)
+
+func TestExplanations(t *testing.T) {
+	for _, code := range errors.Codes() {
+		x, ok := explanations[code]
+		if !ok {
+			t.Errorf("no explanation for %s", code)
+			continue
+		}
+		if x.text == "" || x.example == "" || len(x.fixes) == 0 {
+			t.Errorf("incomplete explanation for %s", code)
+		}
+	}
+	if got, want := len(explanations), len(errors.Codes()); got != want {
+		t.Errorf("got %d explanations, want %d", got, want)
+	}
+}
@@ -17,6 +17,9 @@ package cmd
 import (
 	"github.com/spf13/cobra"
 
+	"cuelang.org/go/cue"
+	_ "cuelang.org/go/encoding/cuebin" // register the cuebin format
+	"cuelang.org/go/internal/cuetrace"
 	"cuelang.org/go/internal/encoding"
 	"cuelang.org/go/internal/filetypes"
 )
@@ -95,46 +98,221 @@ The following formats are recognized:
 
  binary  output as raw binary
               The evaluated value must be of type string or bytes.
+
+ cuebin  output as cuebin
+              Outputs any concrete CUE value in a compact binary
+              encoding which is faster to decode than JSON. See the
+              documentation of package cuelang.org/go/encoding/cuebin
+              for the format and a Go API to read it.
+
+
+Keeping the formatting of existing files
+
+When overwriting an existing YAML or JSON file with --force, the
+--fidelity flag formats the output like the existing file, so that
+only changed values show up in a diff. Comments, the order of keys,
+and the styles of scalars, such as quotes and block scalars, are kept
+in YAML files, and the order of keys, the indentation, and the
+representation of unchanged values are kept in JSON files. New keys
+follow the key which precedes them in the exported value.
+
+	cue export -e config --force --fidelity -o config.yaml
+
+
+Canonical output
+
+The --canonical flag writes a canonical form of the output, which is
+the same for equal values regardless of the order of their fields and
+the way their literals were written, so that it can be hashed and
+signed. It is supported for JSON, which is written on a single line
+without whitespace, with sorted object keys and normalized numbers,
+and for CUE, which is written without comments, with sorted fields,
+normalized number and string literals, and the default formatting.
+The def command supports --canonical as well.
+
+	cue export --canonical ./config | sha256sum
+
+
+Caching output
+
+With --cache, the output is stored on disk, in the output directory
+of the CUE cache directory (see 'cue help environment'), keyed by a
+hash of the contents of all input files, including those of imported
+packages and the module file, the flags and arguments, and the
+version of the cue tool. A later run with the same key writes the
+stored output instead of evaluating the configuration again, which
+makes cue export a cheap step in build systems. The def command
+supports --cache as well.
+
+Output is not cached when system variables are injected with -T,
+when values are injected with --inject-file or --inject-env, when
+--fidelity is set, or when a package uses @extern(embed), as such
+output depends on more than the files of the build. Entries are
+never removed by the cue tool; delete the directory to clear them.
+
+	cue export --cache -o config.json ./config
+
+
+Resuming interrupted exports
+
+With CUE_EXPERIMENT=exportcheckpoint, the --checkpoint flag records
+each exported value in the given directory as soon as it has been
+evaluated. If the export is interrupted, such as when a CI runner is
+preempted, running the same command again replays the recorded
+values rather than evaluating them again, and continues with the
+values which follow. Values are recorded one at a time: each package,
+each value of a data file, such as each line of a JSON Lines file,
+and each expression given with -e. A value which was being evaluated
+when the export was interrupted is evaluated again from the start.
+
+Recorded values are keyed by the same hash of the inputs as --cache,
+so a checkpoint is only used by a run with the same inputs, flags and
+arguments, and the same restrictions apply. The recorded values of an
+export are removed once it completes.
+
+	CUE_EXPERIMENT=exportcheckpoint cue export --checkpoint .ckpt -d '#Item' items.jsonl
+
+
+Writing several files
+
+The --outfile flag may be given more than once to write the same
+evaluation to several files, each in the format of its extension or
+qualifier. An output file with a ":def" suffix holds the definitions
+of the evaluated value as CUE, as written by cue def, rather than its
+exported data. The output is not cached when it is written to more
+than one file.
+
+	cue export -o config.yaml -o config.json -o schema.cue:def
+
+
+Watching for changes
+
+With --watch, export keeps running after the first export, and exports
+again whenever input files within the main module, the current
+directory, or the directories of the files and packages given as
+arguments change, until interrupted. Errors are printed without stopping
+export. After an export succeeds, the files given with --outfile are
+overwritten by the exports which follow, as if --force was given.
+
+	cue export --watch -o config.json ./config
 `,
 		// TODO: some formats are missing for sure, like "jsonl" or "textproto" from internal/filetypes/types.cue.
-		RunE: mkRunE(c, runExport),
+		RunE: mkRunE(c, doExport),
 	}
 
 	addOutFlags(cmd.Flags(), true)
 	addOrphanFlags(cmd.Flags())
 	addInjectionFlags(cmd.Flags(), false, false)
+	addCacheFlag(cmd.Flags())
+	cmd.Flags().String(string(flagCheckpoint), "",
+		"record exported values in this directory to resume an interrupted export (experimental)")
 
 	cmd.Flags().Bool(string(flagEscape), false, "use HTML escaping")
+	cmd.Flags().Bool(string(flagFidelity), false,
+		"keep the comments, key order, and styles of an existing YAML or JSON output file")
+	cmd.Flags().Bool(string(flagCanonical), false,
+		"write a canonical form of the output for hashing and signing (json or cue only)")
 	cmd.Flags().StringArrayP(string(flagExpression), "e", nil, "export this expression only")
+	cmd.Flags().Bool(string(flagWatch), false,
+		"export again whenever the inputs change")
 
 	return cmd
 }
 
+func doExport(cmd *Command, args []string) error {
+	if !flagWatch.Bool(cmd) {
+		return runExport(cmd, args)
+	}
+	outputs := watchOutputs(cmd, filetypes.Export)
+	return watchCommand(cmd, watchRoots(args), outputs, "exporting", func() error {
+		if err := runExport(cmd, args); err != nil {
+			return err
+		}
+		// The output files are now those of an earlier export.
+		return cmd.Flags().Set(string(flagForce), "true")
+	})
+}
+
+// runExport exports the inputs once.
 func runExport(cmd *Command, args []string) error {
-	b, err := parseArgs(cmd, args, &config{mode: filetypes.Export})
+	b, err := parseArgs(cmd, args, &config{mode: filetypes.Export, multiOut: true})
+	if err != nil {
+		return err
+	}
+
+	cache, err := newOutputCache(b, args)
 	if err != nil {
 		return err
 	}
+	if cache != nil {
+		if ok, err := cache.replay(); ok || err != nil {
+			return err
+		}
+	}
 
-	enc, err := encoding.NewEncoder(cmd.ctx, b.outFile, b.encConfig)
+	checkpoint, err := newCheckpoint(b, args)
 	if err != nil {
 		return err
 	}
 
+	// All output files share the single evaluation of each value.
+	var encs []*encoding.Encoder
+	for _, out := range b.outFiles {
+		cfg := *b.encConfig
+		cfg.Mode = out.mode
+		enc, err := encoding.NewEncoder(cmd.ctx, out.file, &cfg)
+		if err != nil {
+			return err
+		}
+		encs = append(encs, enc)
+	}
+	encode := func(v cue.Value) error {
+		for _, enc := range encs {
+			if err := enc.Encode(v); err != nil {
+				return b.traceErrors(v, err)
+			}
+		}
+		return nil
+	}
+
+	if checkpoint != nil {
+		b.skip, err = checkpoint.replay(cmd.ctx, encode)
+		if err != nil {
+			return err
+		}
+	}
+	completed := false
+	defer func() {
+		if err := checkpoint.close(completed); err != nil {
+			printError(cmd, err)
+		}
+	}()
+
 	iter := b.instances()
 	defer iter.close()
+	_, span := cuetrace.Start(cmd.Context(), "encode")
+	defer span.End()
 	for iter.scan() {
 		v := iter.value()
-		err := enc.Encode(v)
-		if err != nil {
+		if err := encode(v); err != nil {
+			span.SetError(err)
 			return err
 		}
+		if checkpoint != nil {
+			if err := checkpoint.record(v); err != nil {
+				return err
+			}
+		}
 	}
 	if err := iter.err(); err != nil {
 		return err
 	}
-	if err := enc.Close(); err != nil {
-		return err
+	for _, enc := range encs {
+		if err := enc.Close(); err != nil {
+			span.SetError(err)
+			return err
+		}
 	}
-	return nil
+	completed = true
+	return cache.save()
 }
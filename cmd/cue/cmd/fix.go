@@ -15,17 +15,24 @@
 package cmd
 
 import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
 	"io/fs"
 	"os"
+	"os/exec"
 	"path/filepath"
 	"strings"
 
+	"cuelang.org/go/cue"
 	"cuelang.org/go/cue/ast"
 	"cuelang.org/go/cue/errors"
 	"cuelang.org/go/cue/format"
 	"cuelang.org/go/cue/load"
 	"cuelang.org/go/cue/token"
 	"cuelang.org/go/tools/fix"
+	"github.com/rogpeppe/go-internal/diff"
 	"github.com/spf13/cobra"
 )
 
@@ -38,21 +45,77 @@ After you update to a new CUE release, fix helps make the necessary changes
 to your program.
 
 Without any packages, fix applies to all files within a module.
+
+Additional rewrite rules can be loaded from CUE files with the --rules
+flag, which may be repeated. This allows organizations to ship their
+own migrations, such as renaming deprecated fields or attributes.
+Each file must declare a list of rules, each with a name and exactly
+one action:
+
+	rules: [{
+		name: "replicas"
+		// Rename the field replicaCount to replicas wherever
+		// it appears directly within a field labeled #Deployment.
+		renameField: {from: "#Deployment.replicaCount", to: "replicas"}
+	}, {
+		name: "go-attr"
+		// Rename @golang(...) attributes to @go(...).
+		renameAttribute: {from: "golang", to: "go"}
+	}]
+
+The from path of renameField is a dot-separated list of labels. The
+last label names the field to rename; any preceding labels must match
+the labels of the directly enclosing fields. The matching is purely
+syntactic. References to renamed fields, such as replicaCount or
+#Deployment.replicaCount, are renamed as well; renaming a referenced
+field to a name which is not a valid identifier fails.
+
+With --dry-run, fix prints a unified diff of the changes to each file
+instead of writing them. With --interactive, fix shows the diff of each
+file it would change and asks whether to apply it:
+
+	y  write the changes to the file
+	n  skip the file
+	e  edit the changes in $VISUAL or $EDITOR, then ask again
+	a  write the changes to this and all remaining files
+	q  skip this and all remaining files
 `,
 		RunE: mkRunE(c, runFixAll),
 	}
 
 	cmd.Flags().BoolP(string(flagForce), "f", false,
 		"rewrite even when there are errors")
+	cmd.Flags().StringArray(string(flagRules), nil,
+		"CUE file declaring additional rewrite rules")
+	cmd.Flags().BoolP(string(flagDryRun), "n", false,
+		"print the diffs of the changes without writing any files")
+	cmd.Flags().Bool(string(flagInteractive), false,
+		"review the changes to each file before writing it")
 
 	return cmd
 }
 
 func runFixAll(cmd *Command, args []string) error {
+	dryRun, interactive := flagDryRun.Bool(cmd), flagInteractive.Bool(cmd)
+	if dryRun && interactive {
+		return fmt.Errorf("cannot specify both --%s and --%s", flagDryRun, flagInteractive)
+	}
+	var review *fixReview
+	if interactive {
+		review = &fixReview{cmd: cmd, in: bufio.NewReader(cmd.InOrStdin())}
+	}
+
 	var opts []fix.Option
 	if flagSimplify.Bool(cmd) {
 		opts = append(opts, fix.Simplify())
 	}
+	for _, file := range flagRules.StringArray(cmd) {
+		rules, err := loadFixRules(cmd, file)
+		if err != nil {
+			return err
+		}
+		opts = append(opts, fix.Rules(rules...))
+	}
 
 	if len(args) == 0 {
 		args = []string{"./..."}
@@ -103,17 +166,137 @@ func runFixAll(cmd *Command, args []string) error {
 				if _, err := cmd.OutOrStdout().Write(b); err != nil {
 					return err
 				}
-			} else {
-				if err := os.WriteFile(f.Filename, b, 0666); err != nil {
-					errs = errors.Append(errs, errors.Promote(err, "write"))
+				continue
+			}
+			if dryRun || interactive {
+				src, err := os.ReadFile(f.Filename)
+				if err != nil {
+					errs = errors.Append(errs, errors.Promote(err, "read"))
+					continue
+				}
+				if bytes.Equal(src, b) {
+					continue
+				}
+				path, err := filepath.Rel(rootWorkingDir(), f.Filename)
+				if err != nil {
+					path = f.Filename
+				}
+				if dryRun {
+					fmt.Fprintln(cmd.OutOrStdout(), string(diff.Diff(path+".orig", src, path, b)))
+					continue
+				}
+				if b, err = review.file(path, src, b); err != nil {
+					return err
+				}
+				if b == nil {
+					continue
 				}
 			}
+			if err := os.WriteFile(f.Filename, b, 0666); err != nil {
+				errs = errors.Append(errs, errors.Promote(err, "write"))
+			}
 		}
 	}
 
 	return errs
 }
 
+// fixReview asks the user whether to apply the changes of cue fix
+// to each file.
+type fixReview struct {
+	cmd  *Command
+	in   *bufio.Reader
+	all  bool // apply the changes to all remaining files
+	quit bool // skip all remaining files
+}
+
+// file shows the diff between the source src of the file at path and
+// its fixed version and asks whether to apply it. It returns the source
+// to write, which may have been edited by the user, or nil if the file
+// is to be skipped.
+func (r *fixReview) file(path string, src, fixed []byte) ([]byte, error) {
+	if r.all {
+		return fixed, nil
+	}
+	w := r.cmd.OutOrStdout()
+	for !r.quit {
+		fmt.Fprintln(w, string(diff.Diff(path+".orig", src, path, fixed)))
+		fmt.Fprintf(w, "Apply this change to %s? [y]es, [n]o, [e]dit, [a]ll, [q]uit: ", path)
+		answer, err := r.in.ReadString('\n')
+		if err == io.EOF && answer == "" {
+			// Without any more answers, leave the remaining files as they are.
+			fmt.Fprintln(w)
+			r.quit = true
+			break
+		} else if err != nil && err != io.EOF {
+			return nil, err
+		}
+		switch strings.ToLower(strings.TrimSpace(answer)) {
+		case "y", "yes":
+			return fixed, nil
+		case "n", "no":
+			return nil, nil
+		case "a", "all":
+			r.all = true
+			return fixed, nil
+		case "q", "quit":
+			r.quit = true
+		case "e", "edit":
+			if fixed, err = r.edit(fixed); err != nil {
+				return nil, err
+			}
+		default:
+			fmt.Fprintf(w, "unknown answer %q\n", strings.TrimSpace(answer))
+		}
+	}
+	return nil, nil
+}
+
+// edit lets the user edit src in the editor named by $VISUAL or $EDITOR,
+// returning the edited source.
+func (r *fixReview) edit(src []byte) ([]byte, error) {
+	editor := os.Getenv("VISUAL")
+	if editor == "" {
+		editor = os.Getenv("EDITOR")
+	}
+	if editor == "" {
+		editor = "vi"
+	}
+	f, err := os.CreateTemp("", "cue-fix-*.cue")
+	if err != nil {
+		return nil, err
+	}
+	defer os.Remove(f.Name())
+	_, err = f.Write(src)
+	if err1 := f.Close(); err == nil {
+		err = err1
+	}
+	if err != nil {
+		return nil, err
+	}
+	args := append(strings.Fields(editor), f.Name())
+	c := exec.CommandContext(r.cmd.Context(), args[0], args[1:]...)
+	c.Stdin = os.Stdin
+	c.Stdout = r.cmd.OutOrStdout()
+	c.Stderr = r.cmd.OutOrStderr()
+	if err := c.Run(); err != nil {
+		return nil, fmt.Errorf("running editor: %w", err)
+	}
+	return os.ReadFile(f.Name())
+}
+
+func loadFixRules(cmd *Command, file string) ([]fix.Rule, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	v := cmd.ctx.CompileBytes(data, cue.Filename(file))
+	if err := v.Err(); err != nil {
+		return nil, err
+	}
+	return fix.ParseRules(v)
+}
+
 func appendDirs(a []string, base string) []string {
 	_ = filepath.WalkDir(base, func(path string, entry fs.DirEntry, err error) error {
 		if err == nil && entry.IsDir() && path != base {
@@ -0,0 +1,161 @@
+// Copyright 2025 The CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+
+	"cuelang.org/go/cue"
+	"cuelang.org/go/cue/cuecontext"
+	"cuelang.org/go/cue/errors"
+)
+
+// flagConfigFile is the name of the file at the root of a module which
+// holds the default flags of commands.
+const flagConfigFile = "cue.config"
+
+var flagConfigHelp = &cobra.Command{
+	Use:   "config",
+	Short: "project-level default flags",
+	Long: `A module may set the default flags of commands run within it in a CUE
+file named cue.config at the root of the module, next to the cue.mod
+directory.
+
+The file holds a struct with a field for each command, named as the command
+is invoked without the leading "cue", whose value maps flag names, without
+their leading dashes, to their values. Boolean flags take a bool, other flags
+a string or a number, and flags which may be repeated, such as --path or
+--inject, a list of values. For example:
+
+	export: {
+		out: "yaml"
+		force: true
+	}
+	vet: concrete: true
+	"mod tidy": check: true
+	eval: inject: ["env=prod", "region=eu"]
+
+The values in cue.config are applied only to the flags which are not given on
+the command line, so the command line always takes precedence. The file is
+looked up from the module containing the current directory, regardless of the
+packages or files given as arguments.
+
+Global flags, such as --diagnostics, may be set for each command individually.
+It is an error for cue.config to refer to a command or flag which does not
+exist.
+`,
+}
+
+// applyFlagConfig sets the flags of cmd which were not given on the command
+// line to the defaults in the cue.config file at the root of the current
+// module, if any.
+func applyFlagConfig(c *Command, cmd *cobra.Command) error {
+	modRoot, err := findModuleRoot()
+	if err != nil {
+		// Outside a module there is no configuration.
+		return nil
+	}
+	path := filepath.Join(modRoot, flagConfigFile)
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+	v := cuecontext.New().CompileBytes(data, cue.Filename(path))
+	if err := v.Validate(cue.Concrete(true)); err != nil {
+		return errors.Wrapf(err, v.Pos(), "invalid %s", flagConfigFile)
+	}
+	iter, err := v.Fields()
+	if err != nil {
+		return errors.Wrapf(err, v.Pos(), "invalid %s", flagConfigFile)
+	}
+	for iter.Next() {
+		name := iter.Selector().Unquoted()
+		sub, _, err := c.root.Find(strings.Fields(name))
+		if err != nil || sub.CommandPath() != c.root.Name()+" "+name {
+			return errors.Newf(iter.Value().Pos(), "%s: unknown command %q", flagConfigFile, name)
+		}
+		if sub != cmd {
+			continue
+		}
+		if err := setConfigFlags(cmd, iter.Value()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// setConfigFlags sets the flags of cmd given in v which were not given on
+// the command line.
+func setConfigFlags(cmd *cobra.Command, v cue.Value) error {
+	iter, err := v.Fields()
+	if err != nil {
+		return errors.Newf(v.Pos(), "%s: flags of %q must be a struct", flagConfigFile, cmd.Name())
+	}
+	for iter.Next() {
+		name := iter.Selector().Unquoted()
+		fv := iter.Value()
+		f := cmd.Flags().Lookup(name)
+		if f == nil {
+			return errors.Newf(fv.Pos(), "%s: unknown flag --%s for %q", flagConfigFile, name, cmd.CommandPath())
+		}
+		if f.Changed {
+			continue
+		}
+		var values []string
+		if list, err := fv.List(); err == nil {
+			if _, ok := f.Value.(pflag.SliceValue); !ok {
+				return errors.Newf(fv.Pos(), "%s: flag --%s cannot be repeated", flagConfigFile, name)
+			}
+			for list.Next() {
+				s, err := flagValueString(list.Value())
+				if err != nil {
+					return err
+				}
+				values = append(values, s)
+			}
+		} else {
+			s, err := flagValueString(fv)
+			if err != nil {
+				return err
+			}
+			values = append(values, s)
+		}
+		for _, s := range values {
+			if err := cmd.Flags().Set(name, s); err != nil {
+				return errors.Newf(fv.Pos(), "%s: invalid value %q for flag --%s: %v", flagConfigFile, s, name, err)
+			}
+		}
+	}
+	return nil
+}
+
+// flagValueString returns the command line form of a flag value.
+func flagValueString(v cue.Value) (string, error) {
+	switch v.Kind() {
+	case cue.StringKind:
+		return v.String()
+	case cue.BoolKind, cue.IntKind, cue.FloatKind:
+		return fmt.Sprint(v), nil
+	}
+	return "", errors.Newf(v.Pos(), "%s: flag value must be a string, number, or bool, found %v", flagConfigFile, v.Kind())
+}
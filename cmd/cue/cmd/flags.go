@@ -22,47 +22,101 @@ import (
 
 // Common flags
 const (
+	flagAddr            flagName = "addr"
+	flagAdmission       flagName = "admission"
+	flagAgainst         flagName = "against"
 	flagAll             flagName = "all"
 	flagAllErrors       flagName = "all-errors"
 	flagAllMajor        flagName = "all-major"
 	flagAllVersions     flagName = "all-versions"
+	flagAPI             flagName = "api"
+	flagBase            flagName = "base"
+	flagCache           flagName = "cache"
+	flagCanonical       flagName = "canonical"
+	flagColor           flagName = "color"
+	flagCount           flagName = "count"
 	flagCheck           flagName = "check"
+	flagCheckpoint      flagName = "checkpoint"
+	flagDebug           flagName = "debug"
+	flagDep             flagName = "dep"
+	flagDiagnostics     flagName = "diagnostics"
 	flagDiff            flagName = "diff"
+	flagDisable         flagName = "disable"
 	flagDryRun          flagName = "dry-run"
+	flagEnable          flagName = "enable"
+	flagErrorFormat     flagName = "error-format"
 	flagEscape          flagName = "escape"
+	flagEvalProfile     flagName = "evalprofile"
 	flagExact           flagName = "exact"
+	flagExitCode        flagName = "exit-code"
 	flagExpression      flagName = "expression"
 	flagExt             flagName = "ext"
+	flagFidelity        flagName = "fidelity"
 	flagFiles           flagName = "files"
 	flagForce           flagName = "force"
 	flagFrom            flagName = "from"
 	flagGlob            flagName = "name"
+	flagGroupErrors     flagName = "group-errors"
+	flagGRPC            flagName = "grpc"
+	flagHTTP            flagName = "http"
 	flagIdent           flagName = "ident"
 	flagIgnore          flagName = "ignore"
+	flagIgnoreOrder     flagName = "ignore-order"
 	flagInject          flagName = "inject"
+	flagInjectEnv       flagName = "inject-env"
+	flagInjectFile      flagName = "inject-file"
 	flagInjectVars      flagName = "inject-vars"
 	flagInlineImports   flagName = "inline-imports"
+	flagInteractive     flagName = "interactive"
+	flagJobs            flagName = "jobs"
 	flagJSON            flagName = "json"
+	flagJSONPatch       flagName = "json-patch"
 	flagLanguageVersion flagName = "language-version"
 	flagList            flagName = "list"
+	flagMap             flagName = "map"
+	flagMaxDisjuncts    flagName = "max-disjuncts"
+	flagMaxErrors       flagName = "max-errors"
+	flagMaxPerGroup     flagName = "max-errors-per-group"
+	flagMaxNodes        flagName = "max-nodes"
+	flagMaxSize         flagName = "max-size"
 	flagMerge           flagName = "merge"
 	flagMod             flagName = "mod"
 	flagNoDeps          flagName = "no-deps"
 	flagOut             flagName = "out"
+	flagOutDir          flagName = "outdir"
 	flagOutFile         flagName = "outfile"
 	flagPackage         flagName = "package"
 	flagPath            flagName = "path"
+	flagPolicy          flagName = "policy"
 	flagProtoEnum       flagName = "proto_enum"
 	flagProtoPath       flagName = "proto_path"
+	flagRandom          flagName = "random"
 	flagRecursive       flagName = "recursive"
+	flagRefs            flagName = "refs"
+	flagREST            flagName = "rest"
+	flagRules           flagName = "rules"
+	flagRun             flagName = "run"
 	flagSchema          flagName = "schema"
+	flagSeed            flagName = "seed"
 	flagSimplify        flagName = "simplify"
+	flagSnippets        flagName = "snippets"
 	flagSource          flagName = "source"
+	flagStdinType       flagName = "stdin-type"
 	flagStrict          flagName = "strict"
+	flagSummary         flagName = "summary"
+	flagTasks           flagName = "tasks"
+	flagTimeout         flagName = "timeout"
+	flagTLSCert         flagName = "tls-cert"
+	flagTLSClientCA     flagName = "tls-client-ca"
+	flagTLSKey          flagName = "tls-key"
 	flagTo              flagName = "to"
+	flagTokenFile       flagName = "token-file"
 	flagTrace           flagName = "trace"
+	flagTree            flagName = "tree"
 	flagUpdateIdent     flagName = "update-ident"
+	flagURL             flagName = "url"
 	flagVerbose         flagName = "verbose"
+	flagWatch           flagName = "watch"
 	flagWithContext     flagName = "with-context"
 
 	// Hidden flags.
@@ -75,11 +129,22 @@ func addOutFlags(f *pflag.FlagSet, allowNonCUE bool) {
 		f.String(string(flagOut), "",
 			`output format (run 'cue help filetypes' for more info)`)
 	}
-	f.StringP(string(flagOutFile), "o", "",
+	f.StringArrayP(string(flagOutFile), "o", nil,
 		`filename or - for stdout with optional file prefix (run 'cue help filetypes' for more info)`)
 	f.BoolP(string(flagForce), "f", false, "force overwriting existing files")
 }
 
+// outFileFlag returns the last value of the --outfile flag, or "" if it is
+// not set. Commands which write a single file use the last value, as they
+// did when the flag could not be repeated.
+func outFileFlag(cmd *Command) string {
+	files := flagOutFile.StringArray(cmd)
+	if len(files) == 0 {
+		return ""
+	}
+	return files[len(files)-1]
+}
+
 func addGlobalFlags(f *pflag.FlagSet) {
 	f.Bool(string(flagTrace), false,
 		"trace computation")
@@ -90,6 +155,26 @@ func addGlobalFlags(f *pflag.FlagSet) {
 	f.BoolP(string(flagVerbose), "v", false,
 		"print information about progress")
 	f.BoolP(string(flagAllErrors), "E", false, "print all available errors")
+	f.String(string(flagDiagnostics), diagText,
+		"format for reporting errors (text|json); see 'cue help diagnostics'")
+	f.String(string(flagGroupErrors), "",
+		"group reported errors by path or code (path|code)")
+	f.Int(string(flagMaxPerGroup), 0,
+		"maximum number of errors to report per group; 0 means no limit")
+	f.String(string(flagSnippets), "auto",
+		"show source lines in reported errors (auto|always|never); auto shows them when stderr is a terminal")
+	f.String(string(flagColor), "auto",
+		"highlight CUE output and source lines in errors (auto|always|never); auto highlights on a terminal unless NO_COLOR is set")
+	f.StringSlice(string(flagDebug), nil,
+		"enable debug flags such as http, or print reports such as disjunctions; see 'cue help debug'")
+	f.Duration(string(flagTimeout), 0,
+		"abort an evaluation after the given duration, such as 30s; 0 means no limit")
+	f.Int64(string(flagMaxNodes), 0,
+		"abort an evaluation after unifying the given number of values; 0 means no limit")
+	f.Int64(string(flagMaxDisjuncts), 0,
+		"abort an evaluation after computing the given number of disjuncts; 0 means no limit")
+	f.String(string(flagEvalProfile), "",
+		"write a profile of the time spent evaluating each CUE value to the specified file in pprof format")
 
 	// Deprecated flags are hidden but still work for now.
 	// TODO(mvdan): make this flag give a warning or error in early 2025.
@@ -114,6 +199,13 @@ func addOrphanFlags(f *pflag.FlagSet) {
 	f.String(string(flagProtoEnum), "int", "mode for rendering enums (int|json)")
 	f.StringP(string(flagGlob), "n", "", "glob filter for non-CUE file names in directories")
 	f.Bool(string(flagMerge), true, "merge non-CUE files")
+	f.String(string(flagStdinType), "auto",
+		"file type of - given without a qualifier, such as yaml; auto detects cue, json, yaml, or toml from the content")
+}
+
+func addCacheFlag(f *pflag.FlagSet) {
+	f.Bool(string(flagCache), false,
+		"reuse the output of an earlier run with the same inputs, caching it on disk otherwise")
 }
 
 func addInjectionFlags(f *pflag.FlagSet, auto, hidden bool) {
@@ -121,9 +213,15 @@ func addInjectionFlags(f *pflag.FlagSet, auto, hidden bool) {
 		"set the value of a tagged field")
 	f.BoolP(string(flagInjectVars), "T", auto,
 		"inject system variables in tags")
+	f.StringArray(string(flagInjectFile), nil,
+		"unify the value of a data file with a field, as in path=file.yaml")
+	f.String(string(flagInjectEnv), "",
+		"set tagged fields from the environment variables with this prefix")
 	if hidden {
 		f.Lookup(string(flagInject)).Hidden = true
 		f.Lookup(string(flagInjectVars)).Hidden = true
+		f.Lookup(string(flagInjectFile)).Hidden = true
+		f.Lookup(string(flagInjectEnv)).Hidden = true
 	}
 }
 
@@ -158,6 +256,12 @@ func (f flagName) Bool(cmd *Command) bool {
 	return v
 }
 
+func (f flagName) Int(cmd *Command) int {
+	f.ensureAdded(cmd)
+	v, _ := cmd.Flags().GetInt(string(f))
+	return v
+}
+
 func (f flagName) String(cmd *Command) string {
 	f.ensureAdded(cmd)
 	v, _ := cmd.Flags().GetString(string(f))
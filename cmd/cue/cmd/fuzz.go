@@ -0,0 +1,176 @@
+// Copyright 2025 The CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"os/exec"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"cuelang.org/go/cue"
+	"cuelang.org/go/tools/sample"
+)
+
+func newFuzzCmd(c *Command) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "fuzz -d schema [packages] (--against expr | -- command [arguments])",
+		Short: "check that a consumer accepts exactly the data a schema allows",
+		Long: `
+fuzz generates many instances of the schema selected with -d from the given
+package, and checks that a consumer of the data agrees with the schema about
+which of them are valid, such as to check that a Go program decodes all the
+configurations the schema allows.
+
+The instances are the data generated by 'cue gen data', followed by random
+data generated with the seeds after --seed, up to --count valid instances
+in total. Each of the fields of the first instance is then changed to be
+just outside what the schema allows, such as numbers just beyond their
+bounds, values of the wrong type, and missing required fields, to give the
+invalid instances.
+
+The consumer is either a command given after --, which receives each
+instance as JSON on its standard input and accepts it if it exits with
+status zero, or a second schema in the same package given with --against,
+which accepts the instances it unifies with.
+
+Each discrepancy is printed, with the instance and the change which made it
+invalid, and the command fails if there are any. For example:
+
+	$ cue fuzz -d '#Config' . -- go run ./cmd/loadconfig
+	valid instance rejected: {"port":65535,"name":"a"}
+		port 65535 out of range
+	invalid instance accepted (port: below the lower bound): {"port":0,"name":"a"}
+	fuzz: 100 valid and 7 invalid instances, 2 discrepancies
+`[1:],
+		RunE: mkRunE(c, runFuzz),
+	}
+	cmd.Flags().StringP(string(flagSchema), "d", "", "expression to select the schema to generate data for (required)")
+	cmd.Flags().String(string(flagAgainst), "", "expression to select the schema which consumes the data")
+	cmd.Flags().Int(string(flagCount), 100, "number of valid instances to generate")
+	cmd.Flags().Uint64(string(flagSeed), 0, "first seed for generating random instances")
+	cmd.MarkFlagRequired(string(flagSchema))
+	return cmd
+}
+
+func runFuzz(cmd *Command, args []string) error {
+	var command []string
+	if n := cmd.ArgsLenAtDash(); n >= 0 {
+		args, command = args[:n], args[n:]
+	}
+	against := flagAgainst.String(cmd)
+	switch {
+	case against == "" && len(command) == 0:
+		return fmt.Errorf("fuzz requires a command after -- or --against")
+	case against != "" && len(command) > 0:
+		return fmt.Errorf("fuzz cannot use both a command and --against")
+	}
+
+	pkg, err := loadSinglePackage(cmd, args)
+	if err != nil {
+		return err
+	}
+	schema, err := evalServeExpr(cmd.ctx, pkg, "--schema", flagSchema.String(cmd))
+	if err != nil {
+		return err
+	}
+	accept := func(v cue.Value, data []byte) (bool, string) {
+		c := exec.CommandContext(cmd.Context(), command[0], command[1:]...)
+		c.Stdin = bytes.NewReader(data)
+		out, err := c.CombinedOutput()
+		return err == nil, strings.TrimSpace(string(out))
+	}
+	if against != "" {
+		consumer, err := evalServeExpr(cmd.ctx, pkg, "--against", against)
+		if err != nil {
+			return err
+		}
+		accept = func(v cue.Value, _ []byte) (bool, string) {
+			if err := consumer.Unify(v).Validate(cue.Concrete(true)); err != nil {
+				return false, err.Error()
+			}
+			return true, ""
+		}
+	}
+	seed, err := cmd.Flags().GetUint64(string(flagSeed))
+	if err != nil {
+		return err
+	}
+
+	w := cmd.OutOrStdout()
+	seen := map[string]bool{}
+	discrepancies := 0
+	check := func(v cue.Value, valid bool, change string) error {
+		data, err := v.MarshalJSON()
+		if err != nil {
+			return err
+		}
+		if seen[string(data)] {
+			return nil
+		}
+		seen[string(data)] = true
+		ok, msg := accept(v, data)
+		switch {
+		case valid && !ok:
+			fmt.Fprintf(w, "valid instance rejected: %s\n", data)
+			for _, line := range strings.Split(msg, "\n") {
+				if line != "" {
+					fmt.Fprintf(w, "\t%s\n", line)
+				}
+			}
+		case !valid && ok:
+			fmt.Fprintf(w, "invalid instance accepted (%s): %s\n", change, data)
+		default:
+			return nil
+		}
+		discrepancies++
+		return nil
+	}
+
+	first, err := sample.Generate(schema, nil)
+	if err != nil {
+		return err
+	}
+	if err := check(first, true, ""); err != nil {
+		return err
+	}
+	for i := 1; i < flagCount.Int(cmd); i++ {
+		v, err := sample.Generate(schema, &sample.Config{Random: true, Seed: seed + uint64(i)})
+		if err != nil {
+			return err
+		}
+		if err := check(v, true, ""); err != nil {
+			return err
+		}
+	}
+	numValid := len(seen)
+	for _, m := range sample.Invalid(schema, first) {
+		change := m.Change
+		if p := m.Path.String(); p != "" {
+			change = p + ": " + change
+		}
+		if err := check(m.Value, false, change); err != nil {
+			return err
+		}
+	}
+	fmt.Fprintf(w, "fuzz: %d valid and %d invalid instances, %d discrepancies\n",
+		numValid, len(seen)-numValid, discrepancies)
+	if discrepancies > 0 {
+		return ErrPrintedError
+	}
+	return nil
+}
@@ -0,0 +1,222 @@
+// Copyright 2025 The CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"cuelang.org/go/cue"
+	"cuelang.org/go/internal/encoding"
+	"cuelang.org/go/internal/filetypes"
+	"cuelang.org/go/tools/sample"
+)
+
+func newGenCmd(c *Command) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "gen [config]",
+		Short: "generate files from CUE",
+		Long: `
+gen generates files for other languages from CUE definitions, as declared
+in a configuration file, gen.cue by default. This allows CUE to be the
+single source of truth for the types shared by the parts of a project
+written in other languages.
+
+The configuration maps the path of each generated file, relative to the
+configuration file, to its format and the package of the definitions to
+generate it from:
+
+	// gen.cue
+	files: {
+		"api/types_gen.go": {
+			format:  "go"
+			package: "./api"
+		}
+		"api/api.proto": {
+			format:       "proto"
+			package:      "./api"
+			definitions: ["#Server", "#Client"]
+			protoPackage: "example.api.v1"
+			goPackage:    "example.com/api/v1"
+		}
+		"schemas/server.json": {
+			format:  "jsonschema"
+			package: "./api"
+			root:    "#Server"
+		}
+		"openapi.yaml": {
+			format:  "openapi"
+			package: "./api"
+			title:   "Example API"
+			version: "v1"
+		}
+	}
+
+The package defaults to the package in the directory of the configuration
+file. As the configuration file has no package clause, it is not part of
+any package. By default, all the exported definitions of the package are
+generated, along with the definitions they refer to; definitions limits
+the file to the definitions listed.
+
+The formats are:
+
+	go          Go types, as generated by 'cue exp gengotypes'. The types
+	            of imported packages are generated in their own
+	            directories. See 'cue help exp gengotypes'.
+	proto       proto3 messages for definitions of structs, and enums for
+	            definitions of disjunctions of strings. Fields are numbered
+	            by their @protobuf attribute, if any, and otherwise in the
+	            order in which they are declared. The file's package and
+	            go_package option are set with protoPackage and goPackage.
+	jsonschema  a JSON Schema (2020-12) with the schemas of the definitions
+	            in $defs. root selects the definition the document itself
+	            refers to.
+	openapi     an OpenAPI document with the schemas of the definitions,
+	            as written by 'cue export --out openapi'. The info section
+	            is set with title and version, and the OpenAPI version
+	            with openapi.
+
+The jsonschema and openapi files are written as JSON or YAML depending
+on their file extension. For these formats, the package may only hold
+definitions, and the info and $version fields used by OpenAPI.
+
+With --check, no files are written, and gen fails if any of the files
+would differ from those which exist, such as to check that generated
+files are up to date in continuous integration.
+
+The commands of gen generate other kinds of files; see 'cue help gen data'.
+`[1:],
+		Args: cobra.MaximumNArgs(1),
+		RunE: mkRunE(c, runGen),
+	}
+	cmd.Flags().Bool(string(flagCheck), false, "check that the generated files are up to date instead of writing them")
+	cmd.AddCommand(newGenDataCmd(c))
+	return cmd
+}
+
+func newGenDataCmd(c *Command) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "data -d schema [packages]",
+		Short: "generate sample data which satisfies a schema",
+		Long: `
+gen data generates a concrete sample of data which satisfies the schema
+selected with -d from the given package, such as for examples in
+documentation or for test fixtures.
+
+Defaults are always used. Otherwise, the first alternative of each
+disjunction is chosen, numbers are the allowed values closest to zero,
+strings are the names of their fields, or are generated to match the
+regular expressions which constrain them, and open lists get a single
+element. Optional fields are left out, and fields which are computed from
+other fields, such as with interpolations, are evaluated.
+
+With --random, values are chosen at random within their constraints, and
+optional fields are included at random. The same --seed always generates the
+same data; it implies --random.
+
+For example:
+
+	$ cat schema.cue
+	package schema
+
+	#Server: {
+		host:  =~"^[a-z]+\\.example\\.com$"
+		port:  int & >1024
+		tls:   *true | bool
+		url:   "https://\(host):\(port)"
+	}
+	$ cue gen data -d '#Server' .
+	{
+	    "host": "a.example.com",
+	    "port": 1025,
+	    "tls": true,
+	    "url": "https://a.example.com:1025"
+	}
+`[1:],
+		RunE: mkRunE(c, runGenData),
+	}
+	cmd.Flags().StringP(string(flagSchema), "d", "", "expression to select the schema to generate data for")
+	cmd.Flags().Bool(string(flagRandom), false, "choose values at random")
+	cmd.Flags().Uint64(string(flagSeed), 0, "seed for choosing values at random; implies --random")
+	cmd.Flags().String(string(flagOut), "", "output format, which defaults to json (run 'cue help filetypes' for more info)")
+	cmd.Flags().StringP(string(flagOutFile), "o", "", "file to write the data to instead of stdout")
+	return cmd
+}
+
+func runGenData(cmd *Command, args []string) error {
+	pkg, err := loadSinglePackage(cmd, args)
+	if err != nil {
+		return err
+	}
+	schema := pkg
+	if s := flagSchema.String(cmd); s != "" {
+		if schema, err = evalServeExpr(cmd.ctx, pkg, "--schema", s); err != nil {
+			return err
+		}
+	}
+	seed, err := cmd.Flags().GetUint64(string(flagSeed))
+	if err != nil {
+		return err
+	}
+	data, err := sample.Generate(schema, &sample.Config{
+		Random: flagRandom.Bool(cmd) || flagSeed.IsSet(cmd),
+		Seed:   seed,
+	})
+	if err != nil {
+		return err
+	}
+	return writeValue(cmd, data, flagOut.String(cmd), flagOutFile.String(cmd))
+}
+
+// writeValue writes v in the encoding out to the file dst, or to stdout
+// if dst is empty or "-". If out is empty, the encoding is that of dst,
+// or JSON for stdout.
+func writeValue(cmd *Command, v cue.Value, out, dst string) error {
+	if dst == "" {
+		dst = "-"
+	}
+	if out == "" && dst == "-" {
+		out = "json"
+	}
+	spec := dst
+	if out != "" {
+		spec = out + ":" + dst
+	}
+	f, err := filetypes.ParseFile(spec, filetypes.Export)
+	if err != nil {
+		return err
+	}
+	w := cmd.OutOrStdout()
+	if dst != "-" {
+		file, err := os.Create(dst)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+		w = file
+	}
+	enc, err := encoding.NewEncoder(cmd.ctx, f, &encoding.Config{
+		Mode: filetypes.Export,
+		Out:  w,
+	})
+	if err != nil {
+		return err
+	}
+	if err := enc.Encode(v); err != nil {
+		return err
+	}
+	return enc.Close()
+}
@@ -0,0 +1,379 @@
+// Copyright 2025 The CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"cuelang.org/go/cue"
+	"cuelang.org/go/cue/ast"
+	"cuelang.org/go/cue/build"
+	"cuelang.org/go/cue/literal"
+	"cuelang.org/go/encoding/openapi"
+	"cuelang.org/go/internal/encoding"
+	"cuelang.org/go/internal/encoding/gotypes"
+	"cuelang.org/go/internal/encoding/protogen"
+	"cuelang.org/go/internal/filetypes"
+)
+
+// genConfigFile is the default configuration file of cue gen.
+const genConfigFile = "gen.cue"
+
+// genGenerator is the command named in the header of generated files.
+const genGenerator = "cue gen"
+
+// genSchema is the schema of the configuration file of cue gen.
+const genSchema = `
+#Config: files: [string]: {
+	format!:      "go" | "jsonschema" | "openapi" | "proto"
+	package:      *"." | string
+	definitions?: [...=~"^#"]
+
+	if format == "proto" {
+		protoPackage?: string
+		goPackage?:    string
+	}
+	if format == "openapi" {
+		title?:   string
+		version?: string
+		openapi?: "3.0.0" | "3.1.0"
+	}
+	if format == "jsonschema" {
+		root?: =~"^#"
+	}
+}
+`
+
+// A genTarget declares a file generated by cue gen.
+type genTarget struct {
+	Format       string   `json:"format"`
+	Package      string   `json:"package"`
+	Definitions  []string `json:"definitions"`
+	ProtoPackage string   `json:"protoPackage"`
+	GoPackage    string   `json:"goPackage"`
+	Title        string   `json:"title"`
+	Version      string   `json:"version"`
+	OpenAPI      string   `json:"openapi"`
+	Root         string   `json:"root"`
+}
+
+// includes reports whether the definition sel is to be generated.
+func (t *genTarget) includes(sel cue.Selector) bool {
+	return t.Definitions == nil || slices.Contains(t.Definitions, sel.String())
+}
+
+func runGen(cmd *Command, args []string) error {
+	file := genConfigFile
+	if len(args) > 0 {
+		file = args[0]
+	}
+	targets, err := loadGenConfig(cmd, file)
+	if err != nil {
+		return err
+	}
+	dir := filepath.Dir(file)
+
+	// Files are only written once all of them were generated.
+	files := map[string][]byte{}
+	for _, name := range slices.Sorted(maps.Keys(targets)) {
+		if err := genFile(cmd, dir, name, targets[name], files); err != nil {
+			return fmt.Errorf("generating %s: %w", name, err)
+		}
+	}
+
+	var stale []string
+	for _, path := range slices.Sorted(maps.Keys(files)) {
+		if flagCheck.Bool(cmd) {
+			if old, err := os.ReadFile(path); err != nil || !bytes.Equal(old, files[path]) {
+				stale = append(stale, path)
+			}
+			continue
+		}
+		if err := os.MkdirAll(filepath.Dir(path), 0o777); err != nil {
+			return err
+		}
+		if err := os.WriteFile(path, files[path], 0o666); err != nil {
+			return err
+		}
+	}
+	if len(stale) > 0 {
+		return fmt.Errorf("generated files are out of date, use 'cue gen': %s", strings.Join(stale, ", "))
+	}
+	return nil
+}
+
+// loadGenConfig loads the configuration file of cue gen, returning its
+// targets by the path of the generated files.
+func loadGenConfig(cmd *Command, file string) (map[string]*genTarget, error) {
+	if _, err := os.Stat(file); os.IsNotExist(err) {
+		return nil, fmt.Errorf("%s not found; see 'cue help gen'", file)
+	} else if err != nil {
+		return nil, err
+	}
+	cfg, err := defaultConfig()
+	if err != nil {
+		return nil, err
+	}
+	binsts := loadFromArgs([]string{file}, cfg.loadCfg)
+	if err := binsts[0].Err; err != nil {
+		return nil, err
+	}
+	v := cmd.ctx.BuildInstance(binsts[0])
+	schema := cmd.ctx.CompileString(genSchema).LookupPath(cue.ParsePath("#Config"))
+	v = schema.Unify(v)
+	if err := v.Validate(cue.Concrete(true)); err != nil {
+		return nil, err
+	}
+	var targets map[string]*genTarget
+	if err := v.LookupPath(cue.ParsePath("files")).Decode(&targets); err != nil {
+		return nil, err
+	}
+	if len(targets) == 0 {
+		return nil, fmt.Errorf("%s declares no files to generate", file)
+	}
+	return targets, nil
+}
+
+// genFile generates the file with the given name, relative to dir, adding
+// the files to write to files.
+func genFile(cmd *Command, dir, name string, t *genTarget, files map[string][]byte) error {
+	path := filepath.Join(dir, name)
+	if _, ok := files[path]; ok {
+		return fmt.Errorf("file is generated more than once")
+	}
+	cfg, err := defaultConfig()
+	if err != nil {
+		return err
+	}
+	cfg.loadCfg.Dir, err = filepath.Abs(dir)
+	if err != nil {
+		return err
+	}
+	binsts := loadFromArgs([]string{t.Package}, cfg.loadCfg)
+	if len(binsts) != 1 {
+		return fmt.Errorf("package %s must be a single package", t.Package)
+	}
+	binst := binsts[0]
+	if err := binst.Err; err != nil {
+		return err
+	}
+	v := cmd.ctx.BuildInstance(binst)
+	if err := v.Validate(); err != nil {
+		return err
+	}
+	for _, def := range t.Definitions {
+		if !v.LookupPath(cue.ParsePath(def)).Exists() {
+			return fmt.Errorf("definition %s not found in package %s", def, t.Package)
+		}
+	}
+
+	switch t.Format {
+	case "go":
+		// The types of imported packages are generated in their own
+		// directories, as gengotypes does.
+		return gotypes.GenerateWithConfig(cmd.ctx, &gotypes.Config{
+			Generator: genGenerator,
+			Filename: func(inst *build.Instance) string {
+				if inst == binst {
+					return path
+				}
+				return ""
+			},
+			Definition: func(inst *build.Instance, sel cue.Selector) bool {
+				return inst != binst || t.includes(sel)
+			},
+			WriteFile: func(path string, data []byte) error {
+				files[path] = data
+				return nil
+			},
+		}, binst)
+
+	case "proto":
+		files[path], err = protogen.Generate(v, &protogen.Config{
+			Package:    t.ProtoPackage,
+			GoPackage:  t.GoPackage,
+			Generator:  genGenerator,
+			Definition: t.includes,
+		})
+		return err
+
+	case "openapi":
+		var info any
+		if t.Title != "" || t.Version != "" {
+			info = ast.NewStruct("title", ast.NewString(t.Title), "version", ast.NewString(t.Version))
+		}
+		f, err := openapi.Generate(v, &openapi.Config{Info: info, Version: t.OpenAPI})
+		if err != nil {
+			return err
+		}
+		if t.Definitions != nil {
+			filterSchemas(lookupStruct(f.Decls, "components", "schemas"), t.Definitions)
+		}
+		files[path], err = encodeGenFile(cmd, path, f)
+		return err
+
+	case "jsonschema":
+		f, err := openapi.Generate(v, &openapi.Config{Version: "3.1.0"})
+		if err != nil {
+			return err
+		}
+		schemas := lookupStruct(f.Decls, "components", "schemas")
+		if schemas == nil {
+			schemas = ast.NewStruct()
+		}
+		keep := t.Definitions
+		if keep != nil && t.Root != "" {
+			keep = append(keep, t.Root)
+		}
+		if keep != nil {
+			filterSchemas(schemas, keep)
+		}
+		rewriteRefs(schemas, "#/components/schemas/", "#/$defs/")
+		decls := []any{"$schema", ast.NewString("https://json-schema.org/draft/2020-12/schema")}
+		if t.Root != "" {
+			decls = append(decls, "$ref", ast.NewString("#/$defs/"+strings.TrimPrefix(t.Root, "#")))
+		}
+		decls = append(decls, "$defs", schemas)
+		files[path], err = encodeGenFile(cmd, path, &ast.File{Decls: ast.NewStruct(decls...).Elts})
+		return err
+	}
+	panic("unreachable")
+}
+
+// encodeGenFile encodes f in the encoding of the file at path.
+func encodeGenFile(cmd *Command, path string, f *ast.File) ([]byte, error) {
+	v := cmd.ctx.BuildFile(f)
+	if err := v.Err(); err != nil {
+		return nil, err
+	}
+	file, err := filetypes.ParseFile(path, filetypes.Export)
+	if err != nil {
+		return nil, err
+	}
+	var buf bytes.Buffer
+	enc, err := encoding.NewEncoder(cmd.ctx, file, &encoding.Config{
+		Mode: filetypes.Export,
+		Out:  &buf,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if err := enc.Encode(v); err != nil {
+		return nil, err
+	}
+	if err := enc.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// lookupStruct returns the struct at the given path of fields in decls, or
+// nil if there is none.
+func lookupStruct(decls []ast.Decl, path ...string) *ast.StructLit {
+	var s *ast.StructLit
+	for _, name := range path {
+		var next *ast.StructLit
+		for _, d := range decls {
+			if f, ok := d.(*ast.Field); ok {
+				if label, _, _ := ast.LabelName(f.Label); label == name {
+					next, _ = f.Value.(*ast.StructLit)
+				}
+			}
+		}
+		if next == nil {
+			return nil
+		}
+		s, decls = next, next.Elts
+	}
+	return s
+}
+
+// filterSchemas removes the schemas other than those of the given
+// definitions and the schemas they refer to.
+func filterSchemas(schemas *ast.StructLit, defs []string) {
+	if schemas == nil {
+		return
+	}
+	byName := map[string]*ast.Field{}
+	for _, d := range schemas.Elts {
+		if f, ok := d.(*ast.Field); ok {
+			name, _, _ := ast.LabelName(f.Label)
+			byName[name] = f
+		}
+	}
+	keep := map[string]bool{}
+	var todo []string
+	for _, def := range defs {
+		todo = append(todo, strings.TrimPrefix(def, "#"))
+	}
+	for len(todo) > 0 {
+		name := todo[0]
+		todo = todo[1:]
+		f, ok := byName[name]
+		if !ok || keep[name] {
+			continue
+		}
+		keep[name] = true
+		ast.Walk(f.Value, func(n ast.Node) bool {
+			if ref, ok := schemaRef(n); ok {
+				todo = append(todo, strings.TrimPrefix(ref, "#/components/schemas/"))
+			}
+			return true
+		}, nil)
+	}
+	schemas.Elts = slices.DeleteFunc(schemas.Elts, func(d ast.Decl) bool {
+		f, ok := d.(*ast.Field)
+		if !ok {
+			return false
+		}
+		name, _, _ := ast.LabelName(f.Label)
+		return !keep[name]
+	})
+}
+
+// rewriteRefs replaces the prefix old of the references within n by new.
+func rewriteRefs(n ast.Node, old, new string) {
+	ast.Walk(n, func(n ast.Node) bool {
+		if ref, ok := schemaRef(n); ok {
+			if rest, ok := strings.CutPrefix(ref, old); ok {
+				n.(*ast.Field).Value = ast.NewString(new + rest)
+			}
+		}
+		return true
+	}, nil)
+}
+
+// schemaRef returns the reference of a $ref field.
+func schemaRef(n ast.Node) (string, bool) {
+	f, ok := n.(*ast.Field)
+	if !ok {
+		return "", false
+	}
+	if name, _, _ := ast.LabelName(f.Label); name != "$ref" {
+		return "", false
+	}
+	lit, ok := f.Value.(*ast.BasicLit)
+	if !ok {
+		return "", false
+	}
+	s, err := literal.Unquote(lit.Value)
+	return s, err == nil
+}
@@ -37,5 +37,7 @@ For information on native CUE modules:
 `,
 	})
 	cmd.AddCommand(newGoCmd(c))
+	cmd.AddCommand(newK8sCmd(c))
+	cmd.AddCommand(newContainerCmd(c))
 	return cmd
 }
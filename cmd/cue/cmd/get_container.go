@@ -0,0 +1,79 @@
+// Copyright 2025 CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"cuelang.org/go/internal/container"
+)
+
+func newContainerCmd(c *Command) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "container",
+		Short: "add Docker Compose and OCI image schemas to the current module",
+		Long: `container adds the schemas for container configuration files
+
+The command "cue get container" writes the CUE definitions built into
+the cue command for Docker Compose files and OCI image configurations to
+the CUE module's gen directory, at the import path of the Go package
+that defines the corresponding types:
+
+	import "github.com/compose-spec/compose-go/v2/types"
+
+	project: types.#Project
+
+	import "github.com/opencontainers/image-spec/specs-go/v1"
+
+	image: v1.#Image
+
+Once added, they can be used like any other schema, for instance with
+the -d, --map, and $schema mechanisms of cue vet. Compose files can also
+be checked directly, without adding the schemas, using cue vet compose.
+
+Each package is written to a file ending in _gen.cue, which is replaced
+each time the command is run.
+`,
+		Args: cobra.NoArgs,
+		RunE: mkRunE(c, runGetContainer),
+	}
+	return cmd
+}
+
+func runGetContainer(cmd *Command, args []string) error {
+	binst := loadFromArgs([]string{"."}, nil)[0]
+	if binst.Module == "" {
+		return fmt.Errorf("no CUE module found; create one with cue mod init")
+	}
+
+	header := "// Code generated by cue get container. DO NOT EDIT.\n\n" +
+		"//cue:generate cue get container\n\n"
+	for _, p := range container.Packages {
+		dir := filepath.Join(binst.Root, "cue.mod", "gen", filepath.FromSlash(p.ImportPath))
+		if err := os.MkdirAll(dir, 0o777); err != nil {
+			return err
+		}
+		name := strings.TrimSuffix(p.Filename, ".cue") + "_gen.cue"
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(header+p.Source()), 0o666); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -119,7 +119,10 @@ Go structs are converted to cue structs adhering to the following conventions:
 	- Field tags are translated to CUE's field attributes. In some cases,
 	  the contents are rewritten to reflect the corresponding types in CUE.
 	  The @go attribute is added if the field name or type definition differs
-	  between the generated CUE and the original Go.
+	  between the generated CUE and the original Go. Tags other than json,
+	  such as yaml or db tags, are carried over verbatim, as is the json tag
+	  if it has options other than omitempty, so that cue exp gengotypes can
+	  restore them.
 
 
 Native CUE Constraints
@@ -1451,12 +1454,70 @@ func (e *extractor) addFields(x *types.Struct, st *cueast.StructLit) {
 			e.addAttr(field, "toml", t)
 		}
 
-		// TODO: should we in general carry over any unknown tag verbatim?
+		// Carry over the json tag if its options cannot be derived from
+		// the name and optionality of the field.
+		if t, ok := tags.Lookup("json"); ok && !jsonTagDerivable(t) {
+			e.addAttr(field, "json", attrBody(t))
+		}
+
+		// Carry over all other tags verbatim, so that cue exp gengotypes
+		// can restore them.
+		for _, key := range structTagKeys(tag) {
+			switch key {
+			case "json", "cue", "protobuf", "protobuf_key", "protobuf_val", "xml", "toml":
+				continue
+			}
+			e.addAttr(field, key, attrBody(tags.Get(key)))
+		}
 
 		count++
 	}
 }
 
+// jsonTagDerivable reports whether the json tag t only uses options which
+// are reflected in the name and optionality of the generated field.
+func jsonTagDerivable(t string) bool {
+	_, opts, _ := strings.Cut(t, ",")
+	for _, opt := range strings.Split(opts, ",") {
+		switch opt {
+		case "", "omitempty", "omitzero", "inline":
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// structTagKeys returns the keys of the struct tag in the order in which
+// they appear, following the conventional format parsed by
+// [reflect.StructTag.Get].
+func structTagKeys(tag string) []string {
+	var keys []string
+	for {
+		tag = strings.TrimLeft(tag, " ")
+		i := strings.Index(tag, ":\"")
+		if i <= 0 || strings.ContainsAny(tag[:i], " \"") {
+			return keys
+		}
+		keys = append(keys, tag[:i])
+		tag = tag[i+1:]
+		value, err := strconv.QuotedPrefix(tag)
+		if err != nil {
+			return keys
+		}
+		tag = tag[len(value):]
+	}
+}
+
+// attrBody returns the value of a struct tag as the body of an
+// attribute, quoting it if it would not otherwise parse as one.
+func attrBody(value string) string {
+	if strings.ContainsAny(value, "()[]{}\"'`\\") {
+		return literal.String.Quote(value)
+	}
+	return value
+}
+
 func (e *extractor) isInline(tag string) bool {
 	return hasFlag(tag, "json", "inline", 1) ||
 		hasFlag(tag, "yaml", "inline", 1)
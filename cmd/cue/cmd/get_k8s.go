@@ -0,0 +1,337 @@
+// Copyright 2025 CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"cmp"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"golang.org/x/mod/semver"
+
+	"cuelang.org/go/cue"
+	"cuelang.org/go/cue/ast"
+	"cuelang.org/go/cue/ast/astutil"
+	"cuelang.org/go/cue/format"
+	"cuelang.org/go/cue/token"
+	"cuelang.org/go/encoding/json"
+	"cuelang.org/go/encoding/jsonschema"
+	"cuelang.org/go/internal/cueconfig"
+)
+
+func newK8sCmd(c *Command) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "k8s [version]",
+		Short: "add Kubernetes API schemas to the current module",
+		Long: `k8s generates CUE definitions for the Kubernetes API
+
+The command "cue get k8s" converts the OpenAPI description of the
+Kubernetes API of the given version into CUE definitions. The version is
+a Kubernetes release such as v1.29 or v1.29.3; a version without a patch
+number selects the first release of that minor version, as the API types
+do not change between patch releases.
+
+The OpenAPI description is downloaded from the Kubernetes source
+repository and cached in the "k8s" directory of the CUE cache directory,
+so that generating the schemas for a version again does not require
+network access. With --spec, the description is read from a local file
+instead, for example one obtained from a running cluster with
+
+	kubectl get --raw /openapi/v2 > swagger.json
+
+in which case the version may be omitted.
+
+The definitions are put in the CUE module's gen directory at the import
+path of the Go package that defines the corresponding types. For instance,
+the Deployment type of the apps/v1 API group is available as
+
+	import "k8s.io/api/apps/v1"
+
+	deployment: v1.#Deployment & {
+		...
+	}
+
+The definitions of a package are written to a file named
+openapi_k8s_gen.cue, which is replaced each time the command is run.
+Their apiVersion and kind fields are fixed to the values for the
+corresponding API group, so a misspelled kind is reported as an error.
+Fields are closed unless the API allows unknown fields.
+
+As "cue get go" writes to the same directories, running both commands
+for the same Kubernetes packages results in conflicting definitions.
+`,
+		Args: cobra.MaximumNArgs(1),
+		RunE: mkRunE(c, runGetK8s),
+	}
+
+	cmd.Flags().String(string(flagSpec), "",
+		"read the OpenAPI description from the given file")
+
+	return cmd
+}
+
+const flagSpec flagName = "spec"
+
+// k8sSpecURL is the location of the OpenAPI description of the Kubernetes
+// API for a release tag.
+var k8sSpecURL = "https://raw.githubusercontent.com/kubernetes/kubernetes/%s/api/openapi-spec/swagger.json"
+
+func runGetK8s(cmd *Command, args []string) error {
+	spec := flagSpec.String(cmd)
+	version := ""
+	if len(args) > 0 {
+		version = semver.Canonical(args[0])
+		if version == "" {
+			return fmt.Errorf("invalid Kubernetes version %q", args[0])
+		}
+	}
+
+	var data []byte
+	var err error
+	switch {
+	case spec != "":
+		data, err = os.ReadFile(spec)
+	case version != "":
+		data, err = k8sSpec(cmd, version)
+	default:
+		return fmt.Errorf("get k8s requires a version or --spec")
+	}
+	if err != nil {
+		return err
+	}
+
+	binst := loadFromArgs([]string{"."}, nil)[0]
+	if binst.Module == "" {
+		return fmt.Errorf("no CUE module found; create one with cue mod init")
+	}
+
+	filename := cmp.Or(spec, "swagger.json")
+	pkgs, err := extractK8s(cmd.ctx, filename, data)
+	if err != nil {
+		return err
+	}
+
+	generate := append([]string{"cue", "get", "k8s"}, args...)
+	if spec != "" {
+		generate = append(generate, "--spec", spec)
+	}
+	header := "// Code generated by cue get k8s. DO NOT EDIT.\n\n" +
+		"//cue:generate " + strings.Join(generate, " ") + "\n\n"
+	for _, p := range pkgs {
+		dir := filepath.Join(binst.Root, "cue.mod", "gen", filepath.FromSlash(p.importPath))
+		if err := os.MkdirAll(dir, 0o777); err != nil {
+			return err
+		}
+		b, err := format.Node(p.file, format.Simplify())
+		if err != nil {
+			return err
+		}
+		b = append([]byte(header), b...)
+		if err := os.WriteFile(filepath.Join(dir, "openapi_k8s_gen.cue"), b, 0o666); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// k8sSpec returns the OpenAPI description of the Kubernetes API for the
+// given canonical version, downloading it if it is not already cached.
+func k8sSpec(cmd *Command, version string) ([]byte, error) {
+	dir, err := cueconfig.CacheDir(os.Getenv)
+	if err != nil {
+		return nil, err
+	}
+	file := filepath.Join(dir, "k8s", version, "swagger.json")
+	if data, err := os.ReadFile(file); err == nil {
+		return data, nil
+	}
+
+	req, err := http.NewRequestWithContext(cmd.Context(), "GET", fmt.Sprintf(k8sSpecURL, version), nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("cannot download Kubernetes %s API description: %v", version, err)
+	}
+	defer resp.Body.Close()
+	switch resp.StatusCode {
+	case http.StatusOK:
+	case http.StatusNotFound:
+		return nil, fmt.Errorf("Kubernetes version %s not found", version)
+	default:
+		return nil, fmt.Errorf("cannot download Kubernetes %s API description: %s", version, resp.Status)
+	}
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("cannot download Kubernetes %s API description: %v", version, err)
+	}
+
+	// Write to a temporary file first so that an interrupted or concurrent
+	// download never leaves a truncated description in the cache.
+	if err := os.MkdirAll(filepath.Dir(file), 0o777); err != nil {
+		return nil, err
+	}
+	f, err := os.CreateTemp(filepath.Dir(file), "swagger-*.json")
+	if err != nil {
+		return nil, err
+	}
+	_, err = f.Write(data)
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err == nil {
+		err = os.Rename(f.Name(), file)
+	}
+	if err != nil {
+		os.Remove(f.Name())
+		return nil, err
+	}
+	return data, nil
+}
+
+// k8sOverrides holds the schemas of types that accept more JSON values
+// than their OpenAPI description states, as they implement custom JSON
+// unmarshaling.
+var k8sOverrides = map[string]func() ast.Expr{
+	"k8s.io/apimachinery/pkg/util/intstr.#IntOrString": func() ast.Expr {
+		return ast.NewBinExpr(token.OR, ast.NewIdent("int"), ast.NewIdent("string"))
+	},
+	"k8s.io/apimachinery/pkg/api/resource.#Quantity": func() ast.Expr {
+		return ast.NewBinExpr(token.OR, ast.NewIdent("number"), ast.NewIdent("string"))
+	},
+}
+
+type k8sPackage struct {
+	importPath string
+	file       *ast.File
+}
+
+// extractK8s converts the definitions of a Kubernetes OpenAPI description
+// into CUE files, one per package, sorted by import path.
+func extractK8s(ctx *cue.Context, filename string, data []byte) ([]k8sPackage, error) {
+	expr, err := json.Extract(filename, data)
+	if err != nil {
+		return nil, err
+	}
+	v := ctx.BuildExpr(expr)
+	if err := v.Err(); err != nil {
+		return nil, err
+	}
+
+	// Version 2 of the description, as used by the Kubernetes repository and
+	// served by /openapi/v2, holds all types in the definitions section.
+	// Version 3 descriptions are split per API group.
+	root := "#/definitions"
+	if !v.LookupPath(cue.ParsePath("definitions")).Exists() {
+		root = "#/components/schemas"
+	}
+
+	decls := map[string][]ast.Decl{}
+	_, err = jsonschema.Extract(v, &jsonschema.Config{
+		Root:           root,
+		DefaultVersion: jsonschema.VersionKubernetesAPI,
+		MapRef: func(loc jsonschema.SchemaLoc) (string, cue.Path, error) {
+			if !loc.IsLocal {
+				return "", cue.Path{}, fmt.Errorf("external reference %v not supported", loc.ID)
+			}
+			sels := loc.Path.Selectors()
+			if len(sels) == 0 {
+				return "", cue.Path{}, nil
+			}
+			return k8sDefinition(sels[len(sels)-1].Unquoted())
+		},
+		DefineSchema: func(importPath string, path cue.Path, e ast.Expr, doc *ast.CommentGroup) {
+			if x, ok := k8sOverrides[importPath+"."+path.String()]; ok {
+				e = x()
+			}
+			f := &ast.Field{
+				Label: ast.NewIdent(path.String()),
+				Value: localRefs(e, importPath),
+			}
+			if doc != nil {
+				ast.AddComment(f, doc)
+			}
+			decls[importPath] = append(decls[importPath], f)
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var pkgs []k8sPackage
+	for importPath, decls := range decls {
+		slices.SortFunc(decls, func(a, b ast.Decl) int {
+			return cmp.Compare(a.(*ast.Field).Label.(*ast.Ident).Name, b.(*ast.Field).Label.(*ast.Ident).Name)
+		})
+		f := &ast.File{Decls: append([]ast.Decl{
+			&ast.Package{Name: ast.NewIdent(path.Base(importPath))},
+		}, decls...)}
+		if err := astutil.Sanitize(f); err != nil {
+			return nil, err
+		}
+		pkgs = append(pkgs, k8sPackage{importPath, f})
+	}
+	slices.SortFunc(pkgs, func(a, b k8sPackage) int {
+		return cmp.Compare(a.importPath, b.importPath)
+	})
+	return pkgs, nil
+}
+
+// k8sDefinition maps the name of a Kubernetes OpenAPI definition, which
+// is the Go package path in reverse domain notation followed by the type
+// name, to the import path of the Go package and the definition of the
+// type. For instance, io.k8s.api.apps.v1.Deployment maps to #Deployment in
+// k8s.io/api/apps/v1.
+func k8sDefinition(name string) (string, cue.Path, error) {
+	elems := strings.Split(name, ".")
+	if len(elems) < 4 || elems[0] != "io" || elems[1] != "k8s" {
+		return "", cue.Path{}, fmt.Errorf("unexpected Kubernetes definition name %q", name)
+	}
+	typ := elems[len(elems)-1]
+	if !ast.IsValidIdent(typ) {
+		return "", cue.Path{}, fmt.Errorf("invalid type name in Kubernetes definition %q", name)
+	}
+	importPath := "k8s.io/" + strings.Join(elems[2:len(elems)-1], "/")
+	return importPath, cue.MakePath(cue.Def(typ)), nil
+}
+
+// localRefs replaces references to definitions in the package importPath,
+// which the jsonschema decoder expresses as imports, by plain references.
+func localRefs(e ast.Expr, importPath string) ast.Expr {
+	return astutil.Apply(e, func(c astutil.Cursor) bool {
+		sel, ok := c.Node().(*ast.SelectorExpr)
+		if !ok {
+			return true
+		}
+		x, ok := sel.X.(*ast.Ident)
+		if !ok {
+			return true
+		}
+		spec, ok := x.Node.(*ast.ImportSpec)
+		if !ok || spec.Path.Value != fmt.Sprintf("%q", importPath) {
+			return true
+		}
+		c.Replace(ast.NewIdent(sel.Sel.(*ast.Ident).Name))
+		return false
+	}, nil).(ast.Expr)
+}
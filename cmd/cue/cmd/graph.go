@@ -0,0 +1,364 @@
+// Copyright 2025 CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"cuelang.org/go/cue/ast"
+	"cuelang.org/go/cue/build"
+	"cuelang.org/go/cue/literal"
+	"cuelang.org/go/internal/mod/modpkgload"
+)
+
+func newGraphCmd(c *Command) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "graph [flags] [packages]",
+		Short: "print the dependency graph of packages",
+		Long: `graph prints the graph of the imports between the given packages and the
+packages they import, directly or indirectly. If no packages are given,
+the graph of all packages of the current module, as with ./..., is
+printed. Packages of the standard library are not included.
+
+With --refs, graph instead prints the references between the top-level
+fields of the given packages: there is an edge from a field to each
+top-level field it refers to, either of its own package or, through an
+import, of another package. References via let clauses are attributed to
+the fields which use them. For example, for the package
+
+	package app
+
+	import "example.com/schema"
+
+	#Port: int & <65536
+	server: schema.#Server & {port: #Port}
+
+the edges are from server to #Port and to example.com/schema.#Server.
+
+The graph is printed in the format given by --out:
+
+	dot      the DOT language of Graphviz, as in
+	         cue graph | dot -Tsvg > graph.svg
+	mermaid  a Mermaid flowchart, which can be embedded in Markdown
+	json     a JSON object holding the nodes and the edges of the graph:
+
+	         {
+	             "nodes": [{"id": "example.com/app"}, ...],
+	             "edges": [{"from": "example.com/app", "to": "example.com/schema"}, ...]
+	         }
+
+	         With --refs, the id of a node is the import path of its package
+	         followed by a dot and the name of the field, and nodes also have
+	         a label, the name of the field, and a package.
+`,
+		RunE: mkRunE(c, runGraph),
+	}
+	cmd.Flags().String(string(flagOut), "dot", "output format (dot|mermaid|json)")
+	cmd.Flags().Bool(string(flagRefs), false, "print the references between top-level fields")
+	return cmd
+}
+
+// A depGraph is a directed graph of packages or of the fields of packages.
+type depGraph struct {
+	Nodes []*graphNode `json:"nodes"`
+	Edges []graphEdge  `json:"edges"`
+
+	byID map[string]*graphNode
+}
+
+type graphNode struct {
+	ID      string `json:"id"`
+	Label   string `json:"label,omitempty"`
+	Package string `json:"package,omitempty"`
+}
+
+type graphEdge struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+}
+
+func (g *depGraph) node(n *graphNode) {
+	if g.byID[n.ID] == nil {
+		g.byID[n.ID] = n
+		g.Nodes = append(g.Nodes, n)
+	}
+}
+
+func (g *depGraph) edge(from, to string) {
+	if from != to {
+		g.Edges = append(g.Edges, graphEdge{From: from, To: to})
+	}
+}
+
+// sort sorts the nodes and edges of g and removes duplicate edges, so
+// that the output does not depend on the order of the input.
+func (g *depGraph) sort() {
+	slices.SortFunc(g.Nodes, func(a, b *graphNode) int {
+		return strings.Compare(a.ID, b.ID)
+	})
+	slices.SortFunc(g.Edges, func(a, b graphEdge) int {
+		if c := strings.Compare(a.From, b.From); c != 0 {
+			return c
+		}
+		return strings.Compare(a.To, b.To)
+	})
+	g.Edges = slices.Compact(g.Edges)
+}
+
+func runGraph(cmd *Command, args []string) error {
+	write, ok := graphWriters[flagOut.String(cmd)]
+	if !ok {
+		return fmt.Errorf("unknown output format %q; must be one of dot, mermaid, or json", flagOut.String(cmd))
+	}
+	if len(args) == 0 {
+		args = []string{"./..."}
+	}
+	cfg, err := defaultConfig()
+	if err != nil {
+		return err
+	}
+	binsts := loadFromArgs(args, cfg.loadCfg)
+	for _, binst := range binsts {
+		if err := binst.Err; err != nil {
+			return err
+		}
+	}
+
+	g := &depGraph{Nodes: []*graphNode{}, Edges: []graphEdge{}, byID: map[string]*graphNode{}}
+	if flagRefs.Bool(cmd) {
+		for _, binst := range binsts {
+			addRefGraph(g, binst)
+		}
+	} else {
+		seen := map[*build.Instance]bool{}
+		for _, binst := range binsts {
+			addImportGraph(g, binst, seen)
+		}
+	}
+	g.sort()
+	return write(cmd.OutOrStdout(), g)
+}
+
+// addImportGraph adds inst and the packages it imports to g.
+func addImportGraph(g *depGraph, inst *build.Instance, seen map[*build.Instance]bool) {
+	if seen[inst] {
+		return
+	}
+	seen[inst] = true
+	pkg := graphPackage(inst.ImportPath)
+	g.node(&graphNode{ID: pkg})
+	for _, imp := range inst.Imports {
+		g.edge(pkg, graphPackage(imp.ImportPath))
+		addImportGraph(g, imp, seen)
+	}
+}
+
+// graphPackage returns the import path of a package without its major
+// version, which is only part of the import paths of the packages given
+// on the command line.
+func graphPackage(importPath string) string {
+	ip := ast.ParseImportPath(importPath)
+	ip.Version = ""
+	return ip.String()
+}
+
+// addRefGraph adds the top-level fields of inst and the references between
+// them to g.
+func addRefGraph(g *depGraph, inst *build.Instance) {
+	pkg := graphPackage(inst.ImportPath)
+	fieldNode := func(pkg, name string) string {
+		id := pkg + "." + name
+		g.node(&graphNode{ID: id, Label: name, Package: pkg})
+		return id
+	}
+
+	// Identifiers which refer to fields of other files of the package are
+	// not resolved by the parser.
+	fields := map[string]bool{}
+	for _, f := range inst.Files {
+		for _, d := range f.Decls {
+			if name, ok := graphFieldName(d); ok {
+				fields[name] = true
+			}
+		}
+	}
+
+	for _, f := range inst.Files {
+		for _, d := range f.Decls {
+			name, ok := graphFieldName(d)
+			if !ok {
+				continue
+			}
+			from := fieldNode(pkg, name)
+			seen := map[ast.Node]bool{}
+			var walk func(n ast.Node)
+			walk = func(n ast.Node) {
+				ast.Walk(n, func(n ast.Node) bool {
+					switch x := n.(type) {
+					case *ast.SelectorExpr:
+						ident, ok := x.X.(*ast.Ident)
+						if !ok {
+							break
+						}
+						spec, ok := ident.Node.(*ast.ImportSpec)
+						if !ok {
+							break
+						}
+						path, _ := literal.Unquote(spec.Path.Value)
+						sel, _, err := ast.LabelName(x.Sel)
+						if err == nil && !modpkgload.IsStdlibPackage(path) {
+							g.edge(from, fieldNode(graphPackage(path), sel))
+						}
+						return false
+
+					case *ast.Ident:
+						// The parser resolves references to fields to their
+						// values, and those to aliases to their fields.
+						if x.Node != nil && x.Scope != f {
+							break
+						}
+						switch decl := x.Node.(type) {
+						case *ast.LetClause:
+							if !seen[decl] {
+								seen[decl] = true
+								walk(decl.Expr)
+							}
+						case *ast.Field:
+							if name, ok := graphFieldName(decl); ok {
+								g.edge(from, fieldNode(pkg, name))
+							}
+						default:
+							if fields[x.Name] {
+								g.edge(from, fieldNode(pkg, x.Name))
+							}
+						}
+					}
+					return true
+				}, nil)
+			}
+			walk(d.(*ast.Field).Value)
+		}
+	}
+}
+
+// graphFieldName returns the name of d if it is a field whose label is an
+// identifier.
+func graphFieldName(d ast.Decl) (string, bool) {
+	f, ok := d.(*ast.Field)
+	if !ok {
+		return "", false
+	}
+	label := f.Label
+	if a, ok := label.(*ast.Alias); ok {
+		label, _ = a.Expr.(ast.Label)
+	}
+	ident, ok := label.(*ast.Ident)
+	if !ok {
+		return "", false
+	}
+	return ident.Name, true
+}
+
+var graphWriters = map[string]func(io.Writer, *depGraph) error{
+	"dot":     writeDOTGraph,
+	"mermaid": writeMermaidGraph,
+	"json":    writeJSONGraph,
+}
+
+// graphClusters returns the packages of the nodes of g, if they are fields,
+// along with their nodes.
+func graphClusters(g *depGraph) (pkgs []string, nodes map[string][]*graphNode) {
+	nodes = map[string][]*graphNode{}
+	for _, n := range g.Nodes {
+		if n.Package == "" {
+			continue
+		}
+		if nodes[n.Package] == nil {
+			pkgs = append(pkgs, n.Package)
+		}
+		nodes[n.Package] = append(nodes[n.Package], n)
+	}
+	slices.Sort(pkgs)
+	return pkgs, nodes
+}
+
+func writeDOTGraph(w io.Writer, g *depGraph) error {
+	fmt.Fprintln(w, "digraph {")
+	pkgs, clusters := graphClusters(g)
+	if len(pkgs) == 0 {
+		for _, n := range g.Nodes {
+			fmt.Fprintf(w, "\t%s;\n", strconv.Quote(n.ID))
+		}
+	}
+	for i, pkg := range pkgs {
+		fmt.Fprintf(w, "\tsubgraph cluster_%d {\n", i)
+		fmt.Fprintf(w, "\t\tlabel = %s;\n", strconv.Quote(pkg))
+		for _, n := range clusters[pkg] {
+			fmt.Fprintf(w, "\t\t%s [label=%s];\n", strconv.Quote(n.ID), strconv.Quote(n.Label))
+		}
+		fmt.Fprintln(w, "\t}")
+	}
+	for _, e := range g.Edges {
+		fmt.Fprintf(w, "\t%s -> %s;\n", strconv.Quote(e.From), strconv.Quote(e.To))
+	}
+	_, err := fmt.Fprintln(w, "}")
+	return err
+}
+
+// mermaidEscaper escapes the characters which Mermaid interprets within
+// quoted labels.
+var mermaidEscaper = strings.NewReplacer("#", "#35;", `"`, "#quot;")
+
+func writeMermaidGraph(w io.Writer, g *depGraph) error {
+	// Mermaid restricts the characters of node ids, so nodes are numbered.
+	ids := map[string]string{}
+	for i, n := range g.Nodes {
+		ids[n.ID] = fmt.Sprintf("n%d", i)
+	}
+	fmt.Fprintln(w, "flowchart LR")
+	pkgs, clusters := graphClusters(g)
+	if len(pkgs) == 0 {
+		for _, n := range g.Nodes {
+			fmt.Fprintf(w, "\t%s[\"%s\"]\n", ids[n.ID], mermaidEscaper.Replace(n.ID))
+		}
+	}
+	for i, pkg := range pkgs {
+		fmt.Fprintf(w, "\tsubgraph p%d[\"%s\"]\n", i, mermaidEscaper.Replace(pkg))
+		for _, n := range clusters[pkg] {
+			fmt.Fprintf(w, "\t\t%s[\"%s\"]\n", ids[n.ID], mermaidEscaper.Replace(n.Label))
+		}
+		fmt.Fprintln(w, "\tend")
+	}
+	for _, e := range g.Edges {
+		fmt.Fprintf(w, "\t%s --> %s\n", ids[e.From], ids[e.To])
+	}
+	return nil
+}
+
+func writeJSONGraph(w io.Writer, g *depGraph) error {
+	b, err := json.MarshalIndent(g, "", "    ")
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(w, "%s\n", b)
+	return err
+}
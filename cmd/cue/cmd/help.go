@@ -17,10 +17,14 @@ package cmd
 import (
 	"bufio"
 	"fmt"
+	"maps"
+	"reflect"
+	"slices"
 	"strings"
 
 	"github.com/spf13/cobra"
 
+	"cuelang.org/go/internal/cuedebug"
 	"cuelang.org/go/internal/mod/modresolve"
 	"cuelang.org/go/mod/modconfig"
 )
@@ -123,6 +127,9 @@ Additional help topics:{{range .Commands}}{{if eq .Name "help"}}{{range .Command
 
 var helpTopics = []*cobra.Command{
 	commandsHelp,
+	flagConfigHelp,
+	debugHelp,
+	diagnosticsHelp,
 	embedHelp,
 	environmentHelp,
 	filetypeHelp,
@@ -181,8 +188,8 @@ Directory and file names that begin with "." or "_" are ignored,
 unless explicitly listed as inputs. File with names ending "_tool.cue"
 are ignored unless running "cue cmd" and they are in packages
 explicitly mentioned on the command line. Files with names ending
-"_test.cue" are ignored for the time being; they are reserved for
-future testing functionality.
+"_test.cue" are ignored unless running "cue test", which evaluates
+the test cases they declare.
 
 A package may also be specified as a list of .cue files.
 The special symbol '-' denotes stdin or stdout and defaults to
@@ -262,6 +269,103 @@ https://cuelang.org/discussion/3264.
 `[1:],
 }
 
+var diagnosticsHelp = &cobra.Command{
+	Use:   "diagnostics",
+	Short: "machine-readable error output",
+	Long: `
+By default, the cue command prints errors to stderr in a human-readable form.
+Editors, CI systems, and other tools can instead request errors in a
+machine-readable form with the global --diagnostics flag:
+
+	--diagnostics=text
+		Human-readable errors, as described above. This is the default.
+
+	--diagnostics=json
+		One JSON object per error, each on its own line (JSON Lines).
+
+Large numbers of similar errors in text output can be condensed with
+two further global flags:
+
+	--group-errors=path
+		Group errors by the path of the value in error.
+	--group-errors=code
+		Group errors by their code, such as E1001 for conflicting values.
+	--max-errors-per-group=N
+		Report at most N errors per group, or N errors in total if errors
+		are not grouped, followed by the number of errors omitted.
+
+Each group starts with a line giving its name and number of errors.
+Groups and the errors within them are sorted by file and position.
+
+When stderr is a terminal, text output also shows the source line of
+each error position, with a caret marking the offending column, and
+highlights it with colors unless the NO_COLOR environment variable is set.
+The global --snippets flag controls this: --snippets=always shows source
+lines even when stderr is not a terminal, without colors, and
+--snippets=never disables them. The global --color flag controls the
+colors: --color=always highlights source lines regardless of the terminal
+and NO_COLOR, and --color=never disables colors. The same flag controls
+the syntax highlighting of CUE written to stdout, such as by cue eval and
+cue def.
+
+Each JSON object has the following fields:
+
+	severity
+		Either "error" or "warning". Only errors cause a command to fail;
+		see "cue help vet" for how constraints can be marked as warnings.
+	code
+		A stable code for the kind of error, such as "E1001" for
+		conflicting values, or the identifier of a violated policy rule;
+		see "cue help vet". The first digit of an error code gives its
+		category: 1 for invalid values, 2 for incomplete values, 3 for
+		cycles, and 4 for syntax and encoding errors. Errors without a
+		code, such as failures to load a package, use "validation".
+		Run "cue explain <code>" for an explanation of an error code.
+	url
+		Where to find more information about the error, if known.
+	message
+		The error message, without the path or positions.
+	path
+		The dot-separated path of the value in error, if any.
+	positions
+		A list of the source positions related to the error,
+		each with "file", "line", and "column" fields.
+		File names are relative to the current directory when possible.
+	causes
+		The errors which caused this one, if any, from the outermost
+		to the innermost, each with a "message" and an optional
+		"position" field. The message of the error itself includes
+		the messages of its causes.
+	suggestions
+		Alternatives for a mistyped field or reference, if any,
+		such as the names of similar fields in a closed struct.
+	hints
+		Ways to fix the error, if any are known, each with a "message"
+		and an optional list of "edits" which implement the fix, such as
+		removing a trailing comma from a JSON file. Each edit replaces the
+		source between its "start" and "end" positions with "newText".
+		In text output, hints are printed after the error.
+	trace
+		For an error which occurred inside an imported package, the sites
+		which led to it, starting in the files or packages given on the
+		command line: each import on the way to the failing package,
+		followed by the field which was unified with the failing value.
+		Each site has a "message" and an optional "position" field.
+		In text output, the trace is printed after the error's positions.
+
+For example:
+
+	$ cue vet --diagnostics=json x.cue
+	{"severity":"error","code":"E1001","message":"conflicting values 2 and 1","path":"a","positions":[{"file":"x.cue","line":1,"column":4},{"file":"x.cue","line":2,"column":4}]}
+
+Non-error output, such as the results of cue export, is not affected.
+Programs which run the cue command via its Go API can enable this format
+with the SetDiagnostics method of cuelang.org/go/cmd/cue/cmd.Command.
+The cue vet command can also write all errors as a single SARIF document
+to stdout; see "cue help vet".
+`[1:],
+}
+
 var environmentHelp = &cobra.Command{
 	Use:   "environment",
 	Short: "environment variables",
@@ -290,10 +394,29 @@ If an environment variable is unset or empty, sensible default setting is used.
 		- "$HOME/Library/Application Support/cue" on MacOS
 		- "%AppData%/cue" on Windows
 
+	LC_ALL, LANG
+		The language in which to report errors, such as "fr_FR.UTF-8".
+		Translations of error messages are loaded from the file
+		"$CUE_CONFIG_DIR/locale/<language>.json", such as "fr-FR.json",
+		or else from the file for the base language, such as "fr.json".
+		The file holds a JSON object mapping the English formats of
+		messages to their translations, which must use the same formatting
+		verbs in the same order:
+
+		{"conflicting values %s and %s": "valeurs incompatibles %s et %s"}
+
+		Programs using the Go API can register translations with
+		RegisterTranslations in cuelang.org/go/cue/errors.
+
 	CUE_REGISTRY
 		The configuration to use when downloading and publishing modules.
 		See "cue help registryconfig" for details.
 
+	CUE_TELEMETRY_URL
+		The URL to which 'cue telemetry upload' sends reports of usage
+		counters when the --url flag is not given.
+		See 'cue help telemetry' for details.
+
 	CUE_EXPERIMENT
 		Comma-separated list of experiment flags to enable or disable:
 
@@ -302,19 +425,52 @@ If an environment variable is unset or empty, sensible default setting is used.
 			and bringing a better disjunction algorithm.
 		cmdreferencepkg (default true)
 			Require referencing imported tool packages to declare "cue cmd" tasks.
+		parallelpkgs (default false)
+			Compile the packages given to commands such as "cue export"
+			and "cue vet" concurrently, using one worker per CPU. The
+			packages are still evaluated one after the other, so this
+			only speeds up commands given several large packages.
+		evalcache (default false)
+			Cache the evaluated form of imported packages which do not
+			use tags or extern interpreters, keyed by the contents of
+			the package and its dependencies, under $CUE_CACHE_DIR/eval.
+			Positions in errors refer to the fields and list elements of
+			the original sources.
+		externprocess (default false)
+			Enable @extern(process), which implements functions with programs
+			run as subprocesses. Only enable it when evaluating trusted CUE,
+			as CUE files may then run any program within their module.
+			Programs must respond to each call within ten seconds, and are
+			killed when the command finishes.
+		externjs (default false)
+			Enable @extern(js), which implements functions in JavaScript
+			run by an embedded interpreter, with a timeout of ten seconds
+			per call.
+		externstarlark (default false)
+			Enable @extern(starlark), which implements functions in Starlark
+			run by an embedded interpreter, with a timeout of ten seconds
+			and a limit of 100 million steps per call.
+		embedremote (default false)
+			Enable @embed(url=..., sha256=...), which fetches and embeds
+			remote content pinned to its SHA-256 hash. Content is cached
+			in the module cache, under $CUE_CACHE_DIR/mod/embed.
+		exportcheckpoint (default false)
+			Enable the --checkpoint flag of "cue export", which records
+			exported values so that an interrupted export can resume.
 
 	CUE_DEBUG
-		Comma-separated list of debug flags to enable or disable, such as:
-
-		http
-			Log a JSON message per HTTP request and response made
-			when interacting with module registries.
-		sortfields
-			Force fields in stucts to be sorted lexicographically.
-		toolsflow
-			Print task dependency mermaid graphs in 'cue cmd'.
-		parsertrace
-			Print a trace of parsed CUE productions.
+		Comma-separated list of debug flags to enable or disable,
+		such as "http" to log requests to module registries.
+		See 'cue help debug' for the full list.
+
+	OTEL_EXPORTER_OTLP_ENDPOINT, OTEL_EXPORTER_OTLP_TRACES_ENDPOINT
+		When set, an OpenTelemetry trace of the command is exported
+		to the given OTLP endpoint over HTTP, with spans for phases
+		such as loading, building, evaluating, and encoding, and for
+		each request to a module registry. Only the http/json protocol
+		is supported. The standard OTEL_EXPORTER_OTLP_HEADERS,
+		OTEL_SERVICE_NAME, OTEL_SDK_DISABLED, and TRACEPARENT
+		variables are also supported.
 
 CUE_EXPERIMENT and CUE_DEBUG are comma-separated lists of key-value strings,
 where the value is a boolean "true" or "1" if omitted. For example:
@@ -323,8 +479,64 @@ where the value is a boolean "true" or "1" if omitted. For example:
 `[1:],
 }
 
-// Please keep the CUE_EXPERIMENT and CUE_DEBUG lists above in sync with
-// the cueexperiment and cuedebug packages.
+// Please keep the CUE_EXPERIMENT list above in sync with
+// the cueexperiment package.
+
+var debugHelp = &cobra.Command{
+	Use:   "debug",
+	Short: "debug flags and reports",
+	Long:  debugHelpText(),
+}
+
+// debugHelpText returns the text of 'cue help debug', which lists the
+// flags in the cuedebug package along with their default values.
+func debugHelpText() string {
+	var b strings.Builder
+	b.WriteString(`
+The --debug flag and the CUE_DEBUG environment variable enable features
+which help to debug the cue command, module registries, or the evaluation
+of a configuration. Both take a comma-separated list of debug flags,
+where the value of a boolean flag is "true" if omitted. Settings given
+with --debug take precedence over those in CUE_DEBUG. For example:
+
+	cue mod tidy --debug=http,httplevel=headers
+	CUE_DEBUG=sortfields cue export
+
+The available debug flags, along with their types and default values, are:
+
+`[1:])
+	for _, f := range cuedebug.Describe() {
+		if f.Kind == reflect.String {
+			fmt.Fprintf(&b, "\t%s (%s, default %q)\n", f.Name, f.Kind, f.Default)
+		} else {
+			fmt.Fprintf(&b, "\t%s (%s, default %v)\n", f.Name, f.Kind, f.Default)
+		}
+		writeHelpIndented(&b, cuedebug.Docs[f.Name])
+	}
+	b.WriteString(`
+The --debug flag also accepts the names of reports, which are printed
+to stderr before the command exits:
+
+`)
+	for _, name := range slices.Sorted(maps.Keys(debugReports)) {
+		fmt.Fprintf(&b, "\t%s\n", name)
+		writeHelpIndented(&b, debugReports[name])
+	}
+	b.WriteString(`
+Debug flags and reports are not covered by any compatibility guarantee;
+they may change or be removed in any release.
+`)
+	return b.String()
+}
+
+// writeHelpIndented writes each line of text to b with two tabs of indent.
+func writeHelpIndented(b *strings.Builder, text string) {
+	for _, line := range strings.Split(text, "\n") {
+		b.WriteString("\t\t")
+		b.WriteString(line)
+		b.WriteString("\n")
+	}
+}
 
 var modulesHelp = &cobra.Command{
 	Use:   "modules",
@@ -489,9 +701,16 @@ specify the path at increasingly deeper nesting. An expression
 may refer to builtin packages as long as the name can be uniquely
 identified.
 
+A CUE field given to the last -l flag may be followed by a value,
+which then replaces the value placed at the path. Like the labels,
+the value is evaluated within the original value, so it can be used
+to select, rename, or compute fields while importing, without a
+separate pass over the result. The labels are computed from the
+original value, not from the replacement.
+
 The --with-context flag can be used to evaluate the label
-expression within a struct of contextual data, instead of
-within the value itself. This struct has the following fields:
+expressions and value within a struct of contextual data, instead
+of within the value itself. This struct has the following fields:
 
 {
 	// data holds the original source data
@@ -535,6 +754,12 @@ $ cue eval -l myschema: schema: foo.json
 
 # Base the path values on its kind and file name.
 $ cue eval --with-context -l 'path.Base(filename)' -l data.kind foo.yaml
+
+# Place only the name, as id, and image of each record, keyed by its name.
+$ cue import -l '(name): {id: name, image: spec.image}' foo.yaml
+
+# Drop the status field of each record, keyed by its kind.
+$ cue import --with-context -l '(data.kind): {for k, v in data if k != "status" {(k): v}}' foo.yaml
 `,
 }
 
@@ -590,6 +815,31 @@ A qualifier applies to all files following it on the command line
 until the next qualifier. The cue tool does not allow a ':' in
 filenames.
 
+Within a module, the filetypes field of cue.mod/module.cue may map
+patterns matching file names to qualifiers (without the ':'). The
+type of an input file without a qualifier is then determined by
+the mapping instead of its extension:
+
+	language: version: "v0.14.0"
+	filetypes: {
+		"*.tmpl.yaml": "text"
+		"*.conf":      "json"
+	}
+
+Patterns are matched against the base name of a file using the
+syntax of Go's path.Match. If more than one pattern matches a
+file, the longest is used. The filetypes field requires language
+version v0.14.0 or later.
+
+Standard input, given as '-', has no extension. Without a
+qualifier, its type is detected from its content as one of cue,
+json, jsonl, yaml, or toml. Content which is valid CUE, but which
+refers to undefined fields, such as 'name: web', is read as YAML.
+The --stdin-type flag sets the type to use instead:
+
+	cat data.yaml | cue vet schema.cue -
+	cat data.txt | cue vet --stdin-type yaml schema.cue -
+
 The following tags can be used in qualifiers to further
 influence input or output. For input these act as
 restrictions, validating the input. For output these act
@@ -704,6 +954,12 @@ the flag "-t key=2" modifies the field to
 
 Valid values for type are "int", "number", "bool", and "string".
 
+The type may also be given on the command line, overriding the type
+of the tag attribute. For instance, "-t 'key:int=2'" modifies any
+field tagged with key to
+
+	field: x & 2
+
 A tag attribute can also define shorthand values, which can be
 injected into the fields without having to specify the key. For
 instance, for
@@ -721,6 +977,33 @@ field. For instance
 ensures the user may only specify "prod" or "staging".
 
 
+Injecting values from the environment
+
+The --inject-env flag sets tags from the environment variables with
+the given prefix, as if each variable PREFIXkey=value was passed as
+"-t key=value". For instance, with
+
+	$ CUE_VAR_env=prod cue export --inject-env CUE_VAR_
+
+the field tagged with env is set to "prod". As with --inject, it is
+an error if no field is tagged with key. Values set with --inject/-t
+take precedence over those from the environment.
+
+
+Injecting data files
+
+The --inject-file flag unifies the value of a data file, such as a
+YAML or JSON file, with a field of each package. For instance,
+
+	$ cue export --inject-file values=env/prod.yaml
+
+unifies the contents of env/prod.yaml with the field values. The path
+is a CUE path, such as a.b or #Def.field, and may be empty to unify
+the data with the package as a whole. As with other files, the type
+of the file may be given explicitly, as in values=yaml:env/prod.txt.
+The file must contain a single value.
+
+
 Tag variables
 
 The injection mechanism allows for the injection of system variables:
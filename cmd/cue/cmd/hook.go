@@ -0,0 +1,504 @@
+// Copyright 2025 CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"cuelang.org/go/cue"
+	"cuelang.org/go/cue/build"
+	"cuelang.org/go/cue/errors"
+	"cuelang.org/go/cue/format"
+	"cuelang.org/go/cue/parser"
+	"cuelang.org/go/internal/mod/modload"
+)
+
+func newHookCmd(c *Command) *cobra.Command {
+	cmd := commandGroup(&cobra.Command{
+		Use:   "hook <cmd> [arguments]",
+		Short: "run CUE checks from git hooks",
+		Long: `Hook groups commands which run CUE checks from git hooks.
+
+The checks replace hand-written hook scripts: "cue hook install" sets up
+the hooks of a git repository to call "cue hook run", which checks the
+CUE files that are about to be committed or pushed.
+
+The available checks are
+
+	fmt   cue fmt --check on the changed CUE files
+	vet   cue vet on the packages containing changed CUE files, and on
+	      the packages of the same module which import them
+	tidy  cue mod tidy --check on the modules containing changed CUE files
+
+Checks only consider the changed CUE files, and are skipped altogether if
+no CUE files changed. Packages which neither contain nor import changed
+files are not vetted.
+`,
+	})
+	cmd.AddCommand(newHookInstallCmd(c))
+	cmd.AddCommand(newHookRunCmd(c))
+	return cmd
+}
+
+func newHookInstallCmd(c *Command) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "install [hooks]",
+		Short: "install git hooks that run CUE checks",
+		Long: `Install installs git hooks that call "cue hook run".
+
+The hooks to install are pre-commit, pre-push, or both. If no hook is
+given, the pre-commit hook is installed. The hooks are written to the
+hooks directory of the git repository containing the current directory,
+taking the core.hooksPath setting into account.
+
+Install refuses to replace an existing hook that was not installed by
+cue, unless --force is given.
+`,
+		RunE: mkRunE(c, runHookInstall),
+	}
+	cmd.Flags().String(string(flagChecks), strings.Join(hookChecks, ","),
+		"comma-separated list of checks to run")
+	cmd.Flags().BoolP(string(flagForce), "f", false, "replace existing hooks")
+	return cmd
+}
+
+func newHookRunCmd(c *Command) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "run <hook> [arguments]",
+		Short: "run the CUE checks of a git hook",
+		Long: `Run runs the CUE checks for the given git hook.
+
+For pre-commit, the files staged in the git index are checked, rather
+than those in the working tree, so that the result reflects what is
+being committed. For pre-push, the commits being pushed are checked; git
+passes these on standard input. Any further arguments, such as those git
+passes to the pre-push hook, are ignored.
+
+The checks are run on a copy of the files being checked, in a temporary
+directory. Error positions are relative to the root of the repository.
+`,
+		Args: cobra.MinimumNArgs(1),
+		RunE: mkRunE(c, runHookRun),
+	}
+	cmd.Flags().String(string(flagChecks), strings.Join(hookChecks, ","),
+		"comma-separated list of checks to run")
+	return cmd
+}
+
+const flagChecks flagName = "checks"
+
+var (
+	gitHooks   = []string{"pre-commit", "pre-push"}
+	hookChecks = []string{"fmt", "vet", "tidy"}
+)
+
+// hookMarker identifies hook scripts written by cue hook install.
+const hookMarker = "# Installed by cue hook install."
+
+func parseHookChecks(cmd *Command) ([]string, error) {
+	var checks []string
+	for _, check := range strings.Split(flagChecks.String(cmd), ",") {
+		if check == "" {
+			continue
+		}
+		if !slices.Contains(hookChecks, check) {
+			return nil, fmt.Errorf("unknown check %q; available checks are %s", check, strings.Join(hookChecks, ", "))
+		}
+		checks = append(checks, check)
+	}
+	return checks, nil
+}
+
+func checkHookName(hook string) error {
+	if !slices.Contains(gitHooks, hook) {
+		return fmt.Errorf("unsupported hook %q; supported hooks are %s", hook, strings.Join(gitHooks, ", "))
+	}
+	return nil
+}
+
+func runHookInstall(cmd *Command, args []string) error {
+	checks, err := parseHookChecks(cmd)
+	if err != nil {
+		return err
+	}
+	if len(args) == 0 {
+		args = []string{"pre-commit"}
+	}
+	for _, hook := range args {
+		if err := checkHookName(hook); err != nil {
+			return err
+		}
+	}
+	out, err := git(cmd.Context(), rootWorkingDir(), nil, "rev-parse", "--git-path", "hooks")
+	if err != nil {
+		return err
+	}
+	dir := strings.TrimSpace(out)
+	if !filepath.IsAbs(dir) {
+		dir = filepath.Join(rootWorkingDir(), dir)
+	}
+	if err := os.MkdirAll(dir, 0o777); err != nil {
+		return err
+	}
+	for _, hook := range args {
+		file := filepath.Join(dir, hook)
+		data, err := os.ReadFile(file)
+		if err == nil && !bytes.Contains(data, []byte(hookMarker)) && !flagForce.Bool(cmd) {
+			return fmt.Errorf("%s already exists; use --force to replace it", file)
+		}
+		script := fmt.Sprintf("#!/bin/sh\n%s\nexec cue hook run %s --checks=%s \"$@\"\n",
+			hookMarker, hook, strings.Join(checks, ","))
+		if err := os.WriteFile(file, []byte(script), 0o777); err != nil {
+			return err
+		}
+		// WriteFile does not change the permissions of an existing file.
+		if err := os.Chmod(file, 0o777); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// A hookTree is a set of files to check: those in the index if rev is
+// empty, or those of the commit rev otherwise.
+type hookTree struct {
+	rev   string
+	files []string // changed files, relative to the repository root
+}
+
+func runHookRun(cmd *Command, args []string) error {
+	hook := args[0]
+	if err := checkHookName(hook); err != nil {
+		return err
+	}
+	checks, err := parseHookChecks(cmd)
+	if err != nil {
+		return err
+	}
+	ctx := cmd.Context()
+	out, err := git(ctx, rootWorkingDir(), nil, "rev-parse", "--show-toplevel")
+	if err != nil {
+		return err
+	}
+	top := strings.TrimSpace(out)
+
+	var trees []hookTree
+	switch hook {
+	case "pre-commit":
+		files, err := gitFiles(ctx, top, "diff", "--cached", "--name-only", "-z", "--diff-filter=ACMR")
+		if err != nil {
+			return err
+		}
+		trees = append(trees, hookTree{files: files})
+
+	case "pre-push":
+		// Git passes a line "<local ref> <local sha> <remote ref> <remote sha>"
+		// for each ref being pushed.
+		scanner := bufio.NewScanner(cmd.InOrStdin())
+		for scanner.Scan() {
+			fields := strings.Fields(scanner.Text())
+			if len(fields) != 4 {
+				return fmt.Errorf("unexpected pre-push input %q", scanner.Text())
+			}
+			local, remote := fields[1], fields[3]
+			if isZeroRev(local) {
+				continue // deleting a ref
+			}
+			var files []string
+			if isZeroRev(remote) {
+				files, err = gitFiles(ctx, top, "ls-tree", "-r", "--name-only", "-z", local)
+			} else {
+				files, err = gitFiles(ctx, top, "diff", "--name-only", "-z", "--diff-filter=ACMR", remote, local)
+			}
+			if err != nil {
+				return err
+			}
+			trees = append(trees, hookTree{rev: local, files: files})
+		}
+		if err := scanner.Err(); err != nil {
+			return err
+		}
+	}
+
+	failed := false
+	for _, t := range trees {
+		ok, err := runHookChecks(cmd, top, t, checks)
+		if err != nil {
+			return err
+		}
+		failed = failed || !ok
+	}
+	if failed {
+		return fmt.Errorf("%s checks failed", hook)
+	}
+	return nil
+}
+
+// runHookChecks runs the checks for the CUE files of the tree t, and
+// reports whether they all succeeded.
+func runHookChecks(cmd *Command, top string, t hookTree, checks []string) (bool, error) {
+	var files []string
+	for _, file := range t.files {
+		if strings.HasSuffix(file, ".cue") {
+			files = append(files, file)
+		}
+	}
+	if len(files) == 0 {
+		return true, nil
+	}
+
+	tmp, err := os.MkdirTemp("", "cue-hook-")
+	if err != nil {
+		return false, err
+	}
+	defer os.RemoveAll(tmp)
+	root := filepath.Join(tmp, "tree")
+
+	// Check out a copy of the files, so that changes in the working tree
+	// that are not being committed or pushed do not affect the result.
+	// For a commit, a temporary index is used to leave the real one alone.
+	var env []string
+	if t.rev != "" {
+		env = []string{"GIT_INDEX_FILE=" + filepath.Join(tmp, "index")}
+		if _, err := git(cmd.Context(), top, env, "read-tree", t.rev); err != nil {
+			return false, err
+		}
+	}
+	if _, err := git(cmd.Context(), top, env, "checkout-index", "--all", "--prefix="+root+string(filepath.Separator)); err != nil {
+		return false, err
+	}
+
+	// Error positions are printed relative to the copy of the tree, which
+	// mirrors the repository root.
+	errCfg := textErrorConfig(cmd)
+	errCfg.Cwd = root
+	ok := true
+	for _, check := range checks {
+		var res bool
+		switch check {
+		case "fmt":
+			res, err = hookFmt(cmd, root, files, errCfg)
+		case "vet":
+			res, err = hookVet(cmd, root, files, errCfg)
+		case "tidy":
+			res, err = hookTidy(cmd, root, files, errCfg)
+		}
+		if err != nil {
+			return false, err
+		}
+		ok = ok && res
+	}
+	return ok, nil
+}
+
+// hookFmt checks that the given files within root are formatted,
+// printing the names of those which are not.
+func hookFmt(cmd *Command, root string, files []string, errCfg *errors.Config) (bool, error) {
+	ok := true
+	for _, file := range files {
+		filename := filepath.Join(root, filepath.FromSlash(file))
+		src, err := os.ReadFile(filename)
+		if err != nil {
+			return false, err
+		}
+		syntax, err := parser.ParseFile(filename, src, parser.ParseComments)
+		if err != nil {
+			errors.Print(cmd.Stderr(), err, errCfg)
+			ok = false
+			continue
+		}
+		formatted, err := format.Node(syntax)
+		if err != nil {
+			return false, err
+		}
+		if !bytes.Equal(formatted, src) {
+			fmt.Fprintln(cmd.OutOrStdout(), filepath.FromSlash(file))
+			ok = false
+		}
+	}
+	return ok, nil
+}
+
+// hookVet vets the packages affected by the given files within root:
+// those containing any of the files, and those importing such packages,
+// directly or indirectly, from within the same module.
+func hookVet(cmd *Command, root string, files []string, errCfg *errors.Config) (bool, error) {
+	changed := map[string]bool{}
+	for _, dir := range hookPackageDirs(files) {
+		changed[filepath.Join(root, filepath.FromSlash(dir))] = true
+	}
+	if len(changed) == 0 {
+		return true, nil
+	}
+	var insts []*build.Instance
+	loadPkgs := func(dir string, args ...string) error {
+		cfg, err := defaultConfig()
+		if err != nil {
+			return err
+		}
+		cfg.loadCfg.Dir = dir
+		insts = append(insts, loadFromArgs(args, cfg.loadCfg)...)
+		return nil
+	}
+	modRoots := hookModuleRoots(root, files)
+	for _, dir := range modRoots {
+		if err := loadPkgs(filepath.Join(root, filepath.FromSlash(dir)), "./..."); err != nil {
+			return false, err
+		}
+	}
+	// Packages outside of any module are loaded on their own.
+	for _, dir := range hookPackageDirs(files) {
+		inModule := slices.ContainsFunc(modRoots, func(modRoot string) bool {
+			return modRoot == "." || dir == modRoot || strings.HasPrefix(dir, modRoot+"/")
+		})
+		if !inModule {
+			if err := loadPkgs(root, "./"+dir); err != nil {
+				return false, err
+			}
+		}
+	}
+
+	affected := map[*build.Instance]bool{}
+	var isAffected func(inst *build.Instance) bool
+	isAffected = func(inst *build.Instance) bool {
+		if res, ok := affected[inst]; ok {
+			return res
+		}
+		affected[inst] = false // guard against import cycles
+		res := changed[inst.Dir] || slices.ContainsFunc(inst.Imports, isAffected)
+		affected[inst] = res
+		return res
+	}
+	ok := true
+	for _, inst := range insts {
+		if !isAffected(inst) {
+			continue
+		}
+		if err := inst.Err; err != nil {
+			errors.Print(cmd.Stderr(), err, errCfg)
+			ok = false
+			continue
+		}
+		v := cmd.ctx.BuildInstance(inst)
+		err := v.Validate(
+			cue.Attributes(true),
+			cue.Definitions(true),
+			cue.Hidden(true),
+			cue.Concrete(true),
+		)
+		own := []*build.Instance{inst}
+		if err := traceErrors(own, buildFileNames(own), v, err); err != nil {
+			errors.Print(cmd.Stderr(), err, errCfg)
+			ok = false
+		}
+	}
+	return ok, nil
+}
+
+// hookTidy checks that the modules containing the given files within
+// root are tidy.
+func hookTidy(cmd *Command, root string, files []string, errCfg *errors.Config) (bool, error) {
+	reg, err := getCachedRegistry()
+	if err != nil {
+		return false, err
+	}
+	ok := true
+	for _, dir := range hookModuleRoots(root, files) {
+		modRoot := filepath.Join(root, filepath.FromSlash(dir))
+		if err := modload.CheckTidy(cmd.Context(), os.DirFS(modRoot), ".", reg); err != nil {
+			errors.Print(cmd.Stderr(), suggestModCommand(err), errCfg)
+			ok = false
+		}
+	}
+	return ok, nil
+}
+
+// hookPackageDirs returns the directories of the given files, skipping
+// those within cue.mod directories, which do not hold packages of the
+// module itself.
+func hookPackageDirs(files []string) []string {
+	var dirs []string
+	for _, file := range files {
+		dir := path.Dir(file)
+		if slices.Contains(strings.Split(dir, "/"), "cue.mod") {
+			continue
+		}
+		dirs = append(dirs, dir)
+	}
+	slices.Sort(dirs)
+	return slices.Compact(dirs)
+}
+
+// hookModuleRoots returns the roots of the modules containing the given
+// files, relative to root.
+func hookModuleRoots(root string, files []string) []string {
+	var roots []string
+	for _, file := range files {
+		for dir := path.Dir(file); ; dir = path.Dir(dir) {
+			if _, err := os.Stat(filepath.Join(root, filepath.FromSlash(dir), "cue.mod", "module.cue")); err == nil {
+				roots = append(roots, dir)
+				break
+			}
+			if dir == "." {
+				break
+			}
+		}
+	}
+	slices.Sort(roots)
+	return slices.Compact(roots)
+}
+
+func git(ctx context.Context, dir string, env []string, args ...string) (string, error) {
+	c := exec.CommandContext(ctx, "git", args...)
+	c.Dir = dir
+	if env != nil {
+		c.Env = append(os.Environ(), env...)
+	}
+	out, err := c.Output()
+	if exitErr, ok := err.(*exec.ExitError); ok {
+		return "", fmt.Errorf("git %s: %v: %s", args[0], err, bytes.TrimSpace(exitErr.Stderr))
+	} else if err != nil {
+		return "", fmt.Errorf("git %s: %v", args[0], err)
+	}
+	return string(out), nil
+}
+
+// gitFiles runs a git command that prints NUL-separated file names.
+func gitFiles(ctx context.Context, dir string, args ...string) ([]string, error) {
+	out, err := git(ctx, dir, nil, args...)
+	if err != nil {
+		return nil, err
+	}
+	out = strings.TrimSuffix(out, "\x00")
+	if out == "" {
+		return nil, nil
+	}
+	return strings.Split(out, "\x00"), nil
+}
+
+func isZeroRev(rev string) bool {
+	return strings.Trim(rev, "0") == ""
+}
@@ -68,6 +68,7 @@ the following modes:
               as data.
    proto      Convert Protocol buffer definition files and
               transitive dependencies.
+   helm       Translate a Helm chart to CUE.
 
 Using the --ext flag in combination with a mode causes matched files to be
 interpreted as the format indicated by the mode, overriding any other meaning
@@ -107,6 +108,64 @@ subdirectories as well all dependencies.
 The module root is implicitly added as an import path.
 
 
+Helm mode
+
+Helm mode translates the Helm chart in the given directory to a CUE
+package, to support migrating off Helm. The package is written to the
+chart directory, in the files
+
+   values.cue     the values field, holding the values of values.yaml
+                  as defaults, which can be overridden
+   chart.cue      the chart field, holding the contents of Chart.yaml,
+                  and the release field, holding the release name,
+                  namespace and revision
+   templates.cue  the templates field, holding the objects of each
+                  template, at the name of the template file without
+                  its extension
+
+or to a single file if the --outfile/-o flag is given. The package name
+is derived from the name of the chart, unless the -p flag is given.
+
+Templates are translated statically rather than rendered: references
+to .Values, .Release and .Chart become references to the corresponding
+fields, and if actions enclosing whole YAML values become comprehensions.
+As CUE has no notion of a value being empty, the condition of an if
+action depends on the type of its value in values.yaml. A limited set
+of functions, such as default, quote, toYaml, eq and not, is supported.
+Templates using any other construct, such as range or include, are
+reported and not imported. Files starting with "_", which hold named
+templates, and subcharts are not imported either.
+
+Example:
+
+   $ cat chart/templates/service.yaml
+   apiVersion: v1
+   kind: Service
+   metadata:
+     name: {{ .Release.Name }}
+   spec:
+     {{- if .Values.service.nodePort }}
+     type: NodePort
+     {{- end }}
+     ports:
+     - port: {{ .Values.service.port }}
+
+   $ cue import helm ./chart
+   $ cat chart/templates.cue
+   ...
+   templates: service: {
+       apiVersion: "v1"
+       kind:       "Service"
+       metadata: name: Release.name
+       spec: {
+           if Values.service.nodePort {
+               type: "NodePort"
+           }
+           ports: [{port: Values.service.port}]
+       }
+   }
+
+
 Binary mode
 
 Loads matched files as binary.
@@ -223,6 +282,48 @@ Examples:
   }]
 
 
+Directory trees
+
+The --tree flag imports each directory argument as a tree of data
+files, which need not be part of a CUE module or package. All files
+in the tree matching the mode, or the -n flag, are imported, skipping
+directories and files starting with "." or "_", and cue.mod
+directories. The value of each file is placed at a label derived
+from its file name without the extension, such that a directory of
+data files becomes a struct. Where this label collides with that of
+another file or subdirectory in the same directory, the full file
+name is used instead. Files containing multiple values, such as YAML
+streams, result in a list.
+
+By default, a CUE file is written next to each data file, belonging
+to a package named after its directory. If the --outfile/-o flag is
+given, the whole tree is instead written to a single file, where the
+path of each value is derived from the directory of the file as well.
+This file also contains an #index definition mapping each imported
+file to the path of its value. The package name can be set with the
+-p flag in both cases.
+
+Example:
+  $ find config -type f
+  config/app.yaml
+  config/db/primary.json
+  config/db/replica.json
+
+  $ cue import --tree -o config.cue ./config
+  $ cat config.cue
+  package config
+
+  // #index maps each imported file to the path of its value.
+  #index: {
+      "app.yaml":        "app"
+      "db/primary.json": "db.primary"
+      "db/replica.json": "db.replica"
+  }
+  app: {...}
+  db: primary: {...}
+  db: replica: {...}
+
+
 Embedded data files
 
 The --recursive or -R flag enables the parsing of fields that are string
@@ -261,6 +362,7 @@ Example:
 	cmd.Flags().Bool(string(flagDryRun), false, "show what files would be created")
 	cmd.Flags().BoolP(string(flagRecursive), "R", false, "recursively parse string values")
 	cmd.Flags().StringArray(string(flagExt), nil, "match files with these extensions")
+	cmd.Flags().Bool(string(flagTree), false, "import directory trees of data files")
 
 	return cmd
 }
@@ -301,6 +403,12 @@ func runImport(cmd *Command, args []string) (err error) {
 				return errors.Newf(token.NoPos,
 					"use of --ext flag required in binary mode")
 			}
+		case "helm":
+			if flagTree.Bool(cmd) {
+				return errors.Newf(token.NoPos,
+					"--%s flag is not supported in helm mode", flagTree)
+			}
+			return helmMode(cmd, c, args)
 		case "auto", "openapi", "jsonschema":
 			c.interpretation = build.Interpretation(mode)
 			c.encoding = "yaml"
@@ -315,6 +423,14 @@ func runImport(cmd *Command, args []string) (err error) {
 		c.fileFilter = `\.(` + strings.Join(extensions, "|") + `)$`
 	}
 
+	if flagTree.Bool(cmd) {
+		if mode == "proto" {
+			return errors.Newf(token.NoPos,
+				"--%s flag is not supported in proto mode", flagTree)
+		}
+		return treeMode(cmd, c, args)
+	}
+
 	b, err := parseArgs(cmd, args, c)
 	if err != nil {
 		return err
@@ -433,7 +549,7 @@ func genericMode(cmd *Command, b *buildPlan) error {
 }
 
 func getFilename(b *buildPlan, f *ast.File, root string, force bool) (filename string, err error) {
-	cueFile := cmp.Or(flagOutFile.String(b.cmd), f.Filename)
+	cueFile := cmp.Or(outFileFlag(b.cmd), f.Filename)
 
 	if cueFile != "-" {
 		switch _, err := os.Stat(cueFile); {
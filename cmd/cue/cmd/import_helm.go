@@ -0,0 +1,1030 @@
+// Copyright 2025 CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"io/fs"
+	"maps"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"text/template/parse"
+	"unicode"
+	"unicode/utf8"
+
+	"cuelang.org/go/cue"
+	"cuelang.org/go/cue/ast"
+	"cuelang.org/go/cue/ast/astutil"
+	"cuelang.org/go/cue/errors"
+	"cuelang.org/go/cue/literal"
+	"cuelang.org/go/cue/token"
+	"cuelang.org/go/internal"
+	"cuelang.org/go/internal/encoding/yaml"
+)
+
+// This file contains the logic for importing Helm charts in helm mode.
+
+// A helmChart is a Helm chart being imported.
+type helmChart struct {
+	dir    string
+	meta   ast.Expr  // contents of Chart.yaml
+	values ast.Expr  // contents of values.yaml
+	metaV  cue.Value // meta, to look up the kind of .Chart references
+	valueV cue.Value // values, to look up the kind of .Values references
+
+	imports map[string]bool // packages used by translated templates
+}
+
+// A helmBlock is the contents of an if action, or its else branch, in
+// the YAML text of a template.
+type helmBlock struct {
+	node       *parse.IfNode
+	cond       ast.Expr
+	start, end int // offsets of the contents in the YAML text
+	depth      int // number of enclosing if actions
+}
+
+// A helmExpr is the translation of a template pipeline.
+type helmExpr struct {
+	expr ast.Expr
+	kind cue.Kind // kind of the value, or cue.BottomKind if unknown
+}
+
+// A helmTemplate translates a single template of a chart to CUE.
+//
+// Each action which yields a value is replaced by a placeholder in the
+// template text, so that the result can be parsed as YAML. Placeholders
+// are then replaced by the translated pipelines in the resulting syntax
+// tree. If actions are translated to comprehensions around the values
+// on the lines they enclose.
+type helmTemplate struct {
+	chart  *helmChart
+	tree   *parse.Tree
+	buf    strings.Builder // YAML text with placeholders
+	exprs  []helmExpr      // translation of each placeholder
+	quoted []bool          // whether each placeholder is quoted in YAML
+	blocks []helmBlock
+	issues []string
+}
+
+// helmPlaceholder is the prefix of the placeholders in the YAML text
+// of a template.
+const helmPlaceholder = "__cue_helm_"
+
+var helmPlaceholderRe = regexp.MustCompile(helmPlaceholder + `(\d+)__`)
+
+func helmMode(cmd *Command, c *config, args []string) error {
+	for _, f := range []flagName{flagPath, flagList, flagFiles, flagWithContext, flagSchema, flagRecursive, flagExt} {
+		if cmd.Flags().Changed(string(f)) {
+			return errors.Newf(token.NoPos,
+				"cannot combine helm mode with flag %q", f)
+		}
+	}
+	if len(args) == 0 {
+		return errors.Newf(token.NoPos, "helm mode requires a chart directory")
+	}
+	p, err := newBuildPlan(cmd, c)
+	if err != nil {
+		return err
+	}
+	single := outFileFlag(cmd) != ""
+	if single && len(args) > 1 {
+		return errors.Newf(token.NoPos,
+			"helm mode with --%s requires a single chart directory", flagOutFile)
+	}
+
+	for _, dir := range args {
+		h, err := readHelmChart(cmd, dir)
+		if err != nil {
+			return err
+		}
+		files, err := h.files(p)
+		if err != nil {
+			return err
+		}
+		if single {
+			// Imports must precede all other declarations.
+			var imports, decls []ast.Decl
+			for _, f := range files {
+				for _, d := range f.Decls {
+					if _, ok := d.(*ast.ImportDecl); ok {
+						imports = append(imports, d)
+					} else {
+						decls = append(decls, d)
+					}
+				}
+			}
+			files = []*ast.File{{Decls: append(imports, decls...)}}
+		}
+		for _, f := range files {
+			internal.SetPackage(f, p.encConfig.PkgName, false)
+			if err := handleFile(p, f); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func readHelmChart(cmd *Command, dir string) (*helmChart, error) {
+	h := &helmChart{dir: dir, imports: map[string]bool{}}
+	file := filepath.Join(dir, "Chart.yaml")
+	b, err := os.ReadFile(file)
+	if os.IsNotExist(err) {
+		return nil, errors.Newf(token.NoPos,
+			"%s is not a Helm chart: no Chart.yaml file", dir)
+	} else if err != nil {
+		return nil, err
+	}
+	if h.meta, err = yaml.Unmarshal(file, b); err != nil {
+		return nil, err
+	}
+	if _, ok := h.meta.(*ast.StructLit); !ok {
+		return nil, errors.Newf(token.NoPos, "%s: expected a mapping", file)
+	}
+
+	file = filepath.Join(dir, "values.yaml")
+	switch b, err := os.ReadFile(file); {
+	case os.IsNotExist(err):
+		h.values = ast.NewStruct()
+	case err != nil:
+		return nil, err
+	default:
+		if h.values, err = yaml.Unmarshal(file, b); err != nil {
+			return nil, err
+		}
+		// An empty file yields *null | _.
+		if _, ok := h.values.(*ast.StructLit); !ok {
+			if _, ok := h.values.(*ast.BinaryExpr); !ok {
+				return nil, errors.Newf(token.NoPos, "%s: expected a mapping", file)
+			}
+			h.values = ast.NewStruct()
+		}
+	}
+
+	h.metaV = cmd.ctx.BuildExpr(h.meta)
+	h.valueV = cmd.ctx.BuildExpr(h.values)
+	if err := h.valueV.Err(); err != nil {
+		return nil, err
+	}
+	return h, h.metaV.Err()
+}
+
+// files translates the chart to the files values.cue, holding the
+// values of values.yaml as defaults, chart.cue, holding Chart.yaml and
+// the release information, and templates.cue, holding the templates.
+func (h *helmChart) files(p *buildPlan) ([]*ast.File, error) {
+	if p.encConfig.PkgName == "" {
+		name, _ := h.metaV.LookupPath(cue.MakePath(cue.Str("name"))).String()
+		pkg := packageNameOf(name)
+		if pkg == "" {
+			return nil, errors.Newf(token.NoPos,
+				"cannot derive package name from chart name %q; use the -p flag", name)
+		}
+		p.encConfig.PkgName = pkg
+	}
+
+	values := &ast.Field{Label: ast.NewIdent("values"), Value: helmDefaults(h.values)}
+	ast.AddComment(values, internal.NewComment(true,
+		"values holds the values of the chart, defaulting to those of values.yaml."))
+
+	chart := &ast.Field{Label: ast.NewIdent("chart"), Value: h.meta}
+	ast.AddComment(chart, internal.NewComment(true,
+		"chart holds the contents of Chart.yaml."))
+	release := &ast.Field{Label: ast.NewIdent("release"), Value: helmDefaults(ast.NewStruct(
+		"name", ast.NewString("release-name"),
+		"namespace", ast.NewString("default"),
+		"revision", ast.NewLit(token.INT, "1"),
+		"service", ast.NewString("Helm"),
+	))}
+	doc := internal.NewComment(true, "release holds the information Helm provides about the release.")
+	ast.SetRelPos(doc, token.NewSection)
+	ast.AddComment(release, doc)
+
+	templates, err := h.templates(p)
+	if err != nil {
+		return nil, err
+	}
+	var decls []ast.Decl
+	if len(h.imports) > 0 {
+		imports := &ast.ImportDecl{}
+		for _, path := range slices.Sorted(maps.Keys(h.imports)) {
+			imports.Specs = append(imports.Specs, ast.NewImport(nil, path))
+		}
+		decls = append(decls, imports)
+	}
+	// The template references are bound to let clauses, which cannot be
+	// shadowed by the fields of the Kubernetes objects.
+	for _, name := range []string{"Values", "Release", "Chart"} {
+		decls = append(decls, &ast.LetClause{
+			Ident: ast.NewIdent(name),
+			Expr:  ast.NewIdent(strings.ToLower(name)),
+		})
+	}
+	templatesField := &ast.Field{Label: ast.NewIdent("templates"), Value: templates}
+	doc = internal.NewComment(true, "templates holds the objects defined by the templates of the chart.")
+	ast.SetRelPos(doc, token.NewSection)
+	ast.AddComment(templatesField, doc)
+	decls = append(decls, templatesField)
+
+	return []*ast.File{
+		{Filename: filepath.Join(h.dir, "values.cue"), Decls: []ast.Decl{values}},
+		{Filename: filepath.Join(h.dir, "chart.cue"), Decls: []ast.Decl{chart, release}},
+		{Filename: filepath.Join(h.dir, "templates.cue"), Decls: decls},
+	}, nil
+}
+
+// templates translates the templates of the chart, reporting those it
+// cannot translate.
+func (h *helmChart) templates(p *buildPlan) (*ast.StructLit, error) {
+	stderr := p.cmd.OutOrStderr()
+	if entries, _ := os.ReadDir(filepath.Join(h.dir, "charts")); len(entries) > 0 {
+		fmt.Fprintf(stderr, "Skipping subcharts in %q: not supported.\n",
+			filepath.ToSlash(filepath.Join(h.dir, "charts")))
+	}
+
+	s := &ast.StructLit{}
+	root := filepath.Join(h.dir, "templates")
+	err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if os.IsNotExist(err) && path == root {
+			return filepath.SkipDir
+		}
+		if err != nil {
+			return err
+		}
+		// Helm does not render files starting with "_" either; they
+		// hold the definitions of named templates.
+		name := d.Name()
+		if d.IsDir() || strings.HasPrefix(name, "_") || strings.HasPrefix(name, ".") {
+			return nil
+		}
+		if ext := filepath.Ext(name); ext != ".yaml" && ext != ".yml" {
+			return nil
+		}
+		src, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(root, path)
+		if err != nil {
+			return err
+		}
+		label := filepath.ToSlash(strings.TrimSuffix(rel, filepath.Ext(rel)))
+		docs, issues := h.translate(filepath.ToSlash(path), string(src))
+		if len(issues) > 0 {
+			fmt.Fprintf(stderr, "Skipping template %q: cannot translate to CUE:\n",
+				filepath.ToSlash(path))
+			for _, issue := range issues {
+				fmt.Fprintf(stderr, "\t%s\n", issue)
+			}
+			return nil
+		}
+		s.Elts = append(s.Elts, helmTemplateDecl(label, docs))
+		return nil
+	})
+	return s, err
+}
+
+// helmTemplateDecl returns the declaration of the objects of a template
+// at label: a single object, or a list if the template yields several.
+// An object that is conditional as a whole makes the declaration of a
+// single object conditional.
+func helmTemplateDecl(label string, docs []ast.Expr) ast.Decl {
+	if len(docs) != 1 {
+		return &ast.Field{Label: ast.NewString(label), Value: ast.NewList(docs...)}
+	}
+	c, ok := docs[0].(*ast.Comprehension)
+	if !ok {
+		return &ast.Field{Label: ast.NewString(label), Value: docs[0]}
+	}
+	obj := c.Value
+	if s, ok := obj.(*ast.StructLit); ok && len(s.Elts) == 1 {
+		if e, ok := s.Elts[0].(*ast.EmbedDecl); ok {
+			obj = e.Expr
+		}
+	}
+	c.Value = ast.NewStruct(&ast.Field{Label: ast.NewString(label), Value: obj})
+	return c
+}
+
+// translate translates the template in file to the CUE expressions of
+// the YAML documents it yields, or reports why it cannot.
+func (h *helmChart) translate(file, src string) ([]ast.Expr, []string) {
+	tree := parse.New(file)
+	tree.Mode = parse.SkipFuncCheck
+	trees := map[string]*parse.Tree{}
+	if _, err := tree.Parse(src, "", "", trees); err != nil {
+		return nil, []string{strings.TrimPrefix(err.Error(), "template: ")}
+	}
+	t := &helmTemplate{chart: h, tree: tree}
+	for name, d := range trees {
+		if name != file {
+			t.issuef(d.Root, "define action is not supported")
+		}
+	}
+	t.walk(tree.Root, 0)
+	if len(t.issues) > 0 {
+		return nil, t.issues
+	}
+
+	text := t.buf.String()
+	for i := range t.exprs {
+		tok := fmt.Sprintf("%s%d__", helmPlaceholder, i)
+		j := strings.Index(text, tok)
+		k := j + len(tok)
+		t.quoted = append(t.quoted, j > 0 && k < len(text) &&
+			strings.ContainsRune(`"'`, rune(text[j-1])) && text[k] == text[j-1])
+	}
+
+	docs := &ast.ListLit{}
+	d := yaml.NewDecoder(file, []byte(text))
+	for {
+		x, err := d.Decode()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, []string{fmt.Sprintf("%s: template is not valid YAML: %v", file, errors.Details(err, nil))}
+		}
+		if lit, ok := x.(*ast.BasicLit); ok && lit.Kind == token.NULL {
+			continue // empty document
+		}
+		if _, ok := x.(*ast.BinaryExpr); ok {
+			continue // empty input, yielding *null | _
+		}
+		docs.Elts = append(docs.Elts, x)
+	}
+
+	// Wrap inner blocks first, so that outer blocks enclose their
+	// comprehensions.
+	slices.SortStableFunc(t.blocks, func(a, b helmBlock) int { return b.depth - a.depth })
+	for _, b := range t.blocks {
+		first, last, ok := t.lines(text, b)
+		if !ok {
+			t.issuef(b.node, "if action must enclose whole lines")
+			continue
+		}
+		if first == 0 {
+			continue // nothing but whitespace
+		}
+		if !wrapHelmBlock(docs, b.cond, first, last) {
+			t.issuef(b.node, "if action must enclose complete YAML values")
+		}
+	}
+	if len(t.issues) > 0 {
+		return nil, t.issues
+	}
+	t.replacePlaceholders(docs)
+	return docs.Elts, nil
+}
+
+// lines returns the first and last line of the contents of the block b
+// in text, or 0 if it only contains whitespace. It reports false if the
+// block starts or ends within a line.
+func (t *helmTemplate) lines(text string, b helmBlock) (first, last int, ok bool) {
+	s := text[b.start:b.end]
+	if b.start > 0 && text[b.start-1] != '\n' && !strings.HasPrefix(strings.TrimLeft(s, " \t"), "\n") {
+		return 0, 0, false
+	}
+	if b.end < len(text) && text[b.end] != '\n' && !strings.HasSuffix(strings.TrimRight(s, " \t"), "\n") {
+		return 0, 0, false
+	}
+	trimmed := strings.TrimSpace(s)
+	if trimmed == "" {
+		return 0, 0, true
+	}
+	start := b.start + strings.Index(s, trimmed)
+	first = 1 + strings.Count(text[:start], "\n")
+	last = first + strings.Count(trimmed, "\n")
+	return first, last, true
+}
+
+// walk writes the YAML text for the nodes of list, which are enclosed
+// by depth if actions.
+func (t *helmTemplate) walk(list *parse.ListNode, depth int) {
+	if list == nil {
+		return
+	}
+	for _, n := range list.Nodes {
+		switch n := n.(type) {
+		case *parse.TextNode:
+			t.buf.Write(n.Text)
+
+		case *parse.CommentNode:
+
+		case *parse.ActionNode:
+			if len(n.Pipe.Decl) > 0 {
+				t.issuef(n, "variable declarations are not supported")
+				continue
+			}
+			x, indent, ok := t.pipe(n.Pipe)
+			if !ok {
+				continue
+			}
+			fmt.Fprintf(&t.buf, "%s%s%d__", indent, helmPlaceholder, len(t.exprs))
+			t.exprs = append(t.exprs, x)
+
+		case *parse.IfNode:
+			cond, ok := t.cond(n.Pipe)
+			if !ok {
+				continue
+			}
+			t.block(n, cond, n.List, depth)
+			if n.ElseList != nil {
+				t.block(n, helmNot(cond), n.ElseList, depth)
+			}
+
+		case *parse.RangeNode:
+			t.issuef(n, "range action is not supported")
+		case *parse.WithNode:
+			t.issuef(n, "with action is not supported")
+		case *parse.TemplateNode:
+			t.issuef(n, "template action is not supported")
+		default:
+			t.issuef(n, "%s is not supported", n)
+		}
+	}
+}
+
+func (t *helmTemplate) block(n *parse.IfNode, cond ast.Expr, list *parse.ListNode, depth int) {
+	start := t.buf.Len()
+	t.walk(list, depth+1)
+	t.blocks = append(t.blocks, helmBlock{
+		node:  n,
+		cond:  cond,
+		start: start,
+		end:   t.buf.Len(),
+		depth: depth,
+	})
+}
+
+func (t *helmTemplate) issuef(n parse.Node, format string, args ...any) {
+	loc, _ := t.tree.ErrorContext(n)
+	t.issues = append(t.issues, loc+": "+fmt.Sprintf(format, args...))
+}
+
+// pipe translates the pipeline p. It returns the indentation added by
+// a trailing indent or nindent function, which is not part of the value.
+func (t *helmTemplate) pipe(p *parse.PipeNode) (x helmExpr, indent string, ok bool) {
+	for i, c := range p.Cmds {
+		id, isFunc := c.Args[0].(*parse.IdentifierNode)
+		if !isFunc {
+			if i > 0 || len(c.Args) > 1 {
+				t.issuef(c, "cannot translate %s", c)
+				return x, "", false
+			}
+			if x, ok = t.operand(c.Args[0]); !ok {
+				return x, "", false
+			}
+			continue
+		}
+		indent = ""
+		args := c.Args[1:]
+		if id.Ident == "indent" || id.Ident == "nindent" {
+			n, isNum := args[0].(*parse.NumberNode)
+			if !isNum || !n.IsInt || n.Int64 < 0 {
+				t.issuef(c, "%s requires a constant width", id.Ident)
+				return x, "", false
+			}
+			indent = strings.Repeat(" ", int(n.Int64))
+			if id.Ident == "nindent" {
+				indent = "\n" + indent
+			}
+			args = args[1:]
+		}
+		var in []helmExpr
+		for _, a := range args {
+			v, ok := t.operand(a)
+			if !ok {
+				return x, "", false
+			}
+			in = append(in, v)
+		}
+		if i > 0 {
+			in = append(in, x)
+		}
+		if x, ok = t.call(c, id.Ident, in); !ok {
+			return x, "", false
+		}
+	}
+	return x, indent, true
+}
+
+// helmFuncs holds the number of arguments of the template functions
+// that can be translated, or -1 for those taking two or more.
+var helmFuncs = map[string]int{
+	"and":      -1,
+	"b64enc":   1,
+	"default":  2,
+	"empty":    1,
+	"eq":       2,
+	"indent":   1,
+	"lower":    1,
+	"ne":       2,
+	"nindent":  1,
+	"not":      1,
+	"or":       -1,
+	"quote":    1,
+	"required": 2,
+	"squote":   1,
+	"toString": 1,
+	"toYaml":   1,
+	"trim":     1,
+	"upper":    1,
+}
+
+func (t *helmTemplate) call(n parse.Node, name string, args []helmExpr) (helmExpr, bool) {
+	want, ok := helmFuncs[name]
+	if !ok {
+		t.issuef(n, "function %s is not supported", name)
+		return helmExpr{}, false
+	}
+	if want >= 0 && len(args) != want || want < 0 && len(args) < 2 {
+		t.issuef(n, "wrong number of arguments for %s", name)
+		return helmExpr{}, false
+	}
+	x := args[len(args)-1]
+	switch name {
+	case "indent", "nindent", "required", "toYaml":
+		return x, true
+
+	case "quote", "squote", "toString":
+		if x.kind == cue.StringKind {
+			return x, true
+		}
+		return helmExpr{helmInterpolation([]string{"", ""}, []ast.Expr{x.expr}), cue.StringKind}, true
+
+	case "lower", "upper", "trim":
+		fn := map[string]string{"lower": "ToLower", "upper": "ToUpper", "trim": "TrimSpace"}[name]
+		return helmExpr{t.chart.importCall("strings", fn, x.expr), cue.StringKind}, true
+
+	case "b64enc":
+		return helmExpr{t.chart.importCall("encoding/base64", "Encode", ast.NewNull(), x.expr), cue.StringKind}, true
+
+	case "default":
+		// default yields the default unless the value is set to a
+		// non-empty value.
+		cond, ok := t.truthy(n, x)
+		if !ok {
+			return helmExpr{}, false
+		}
+		list := ast.NewList(&ast.Comprehension{
+			Clauses: []ast.Clause{&ast.IfClause{Condition: cond}},
+			Value:   &ast.StructLit{Elts: []ast.Decl{&ast.EmbedDecl{Expr: x.expr}}},
+		}, args[0].expr)
+		return helmExpr{&ast.IndexExpr{X: list, Index: ast.NewLit(token.INT, "0")}, x.kind}, true
+
+	case "eq", "ne":
+		op := token.EQL
+		if name == "ne" {
+			op = token.NEQ
+		}
+		return helmExpr{ast.NewBinExpr(op, helmParen(args[0].expr), helmParen(x.expr)), cue.BoolKind}, true
+
+	case "not", "empty":
+		cond, ok := t.truthy(n, x)
+		if !ok {
+			return helmExpr{}, false
+		}
+		return helmExpr{helmNot(cond), cue.BoolKind}, true
+
+	default: // and, or
+		op := token.LAND
+		if name == "or" {
+			op = token.LOR
+		}
+		var conds []ast.Expr
+		for _, a := range args {
+			cond, ok := t.truthy(n, a)
+			if !ok {
+				return helmExpr{}, false
+			}
+			conds = append(conds, helmParen(cond))
+		}
+		return helmExpr{ast.NewBinExpr(op, conds...), cue.BoolKind}, true
+	}
+}
+
+// cond translates the condition of an if action.
+func (t *helmTemplate) cond(p *parse.PipeNode) (ast.Expr, bool) {
+	if len(p.Decl) > 0 {
+		t.issuef(p, "variable declarations are not supported")
+		return nil, false
+	}
+	x, _, ok := t.pipe(p)
+	if !ok {
+		return nil, false
+	}
+	return t.truthy(p, x)
+}
+
+// truthy returns the condition under which a template considers x to
+// be true: x is set to a value other than false, 0, or an empty string,
+// list or map. As CUE has no such notion, the condition depends on the
+// kind of x.
+func (t *helmTemplate) truthy(n parse.Node, x helmExpr) (ast.Expr, bool) {
+	e := helmParen(x.expr)
+	switch x.kind {
+	case cue.BoolKind:
+		return x.expr, true
+	case cue.StringKind:
+		return ast.NewBinExpr(token.NEQ, e, ast.NewString("")), true
+	case cue.IntKind, cue.FloatKind, cue.NumberKind:
+		return ast.NewBinExpr(token.NEQ, e, ast.NewLit(token.INT, "0")), true
+	case cue.ListKind, cue.StructKind:
+		return ast.NewBinExpr(token.GTR, ast.NewCall(ast.NewIdent("len"), x.expr), ast.NewLit(token.INT, "0")), true
+	case cue.NullKind:
+		return ast.NewBinExpr(token.NEQ, e, ast.NewNull()), true
+	}
+	t.issuef(n, "cannot determine the type of %s", n)
+	return nil, false
+}
+
+func (t *helmTemplate) operand(n parse.Node) (helmExpr, bool) {
+	switch n := n.(type) {
+	case *parse.FieldNode:
+		return t.ref(n, n.Ident)
+	case *parse.VariableNode:
+		if n.Ident[0] == "$" && len(n.Ident) > 1 {
+			return t.ref(n, n.Ident[1:])
+		}
+	case *parse.DotNode:
+		t.issuef(n, "references to . are not supported")
+		return helmExpr{}, false
+	case *parse.StringNode:
+		return helmExpr{ast.NewString(n.Text), cue.StringKind}, true
+	case *parse.NumberNode:
+		switch {
+		case n.IsInt:
+			return helmExpr{ast.NewLit(token.INT, n.Text), cue.IntKind}, true
+		case n.IsFloat:
+			return helmExpr{ast.NewLit(token.FLOAT, n.Text), cue.FloatKind}, true
+		}
+	case *parse.BoolNode:
+		return helmExpr{ast.NewBool(n.True), cue.BoolKind}, true
+	case *parse.NilNode:
+		return helmExpr{ast.NewNull(), cue.NullKind}, true
+	case *parse.PipeNode:
+		if len(n.Decl) > 0 {
+			break
+		}
+		x, indent, ok := t.pipe(n)
+		if ok && indent != "" {
+			t.issuef(n, "indentation within a pipeline is not supported")
+			return x, false
+		}
+		return x, ok
+	}
+	t.issuef(n, "cannot translate %s", n)
+	return helmExpr{}, false
+}
+
+// helmReleaseFields maps the supported fields of .Release to those of
+// the release field.
+var helmReleaseFields = map[string]helmExpr{
+	"Name":      {ast.NewIdent("name"), cue.StringKind},
+	"Namespace": {ast.NewIdent("namespace"), cue.StringKind},
+	"Revision":  {ast.NewIdent("revision"), cue.IntKind},
+	"Service":   {ast.NewIdent("service"), cue.StringKind},
+}
+
+// ref translates a reference to the built-in object .Values, .Release,
+// or .Chart, or a field thereof.
+func (t *helmTemplate) ref(n parse.Node, idents []string) (helmExpr, bool) {
+	switch idents[0] {
+	case "Values":
+		x := helmExpr{ast.NewIdent("Values"), cue.StructKind}
+		var sels []cue.Selector
+		for _, id := range idents[1:] {
+			x.expr = helmSelector(x.expr, id)
+			sels = append(sels, cue.Str(id))
+		}
+		x.kind = cue.BottomKind
+		if v := t.chart.valueV.LookupPath(cue.MakePath(sels...)); v.Exists() {
+			x.kind = v.Kind()
+		}
+		return x, true
+
+	case "Release":
+		if f, ok := helmReleaseFields[idents[len(idents)-1]]; ok && len(idents) == 2 {
+			return helmExpr{ast.NewSel(ast.NewIdent("Release"), f.expr.(*ast.Ident).Name), f.kind}, true
+		}
+
+	case "Chart":
+		if len(idents) == 2 {
+			// The fields of Chart.yaml start with a lower-case letter.
+			r, size := utf8.DecodeRuneInString(idents[1])
+			name := string(unicode.ToLower(r)) + idents[1][size:]
+			x := helmExpr{helmSelector(ast.NewIdent("Chart"), name), cue.BottomKind}
+			if v := t.chart.metaV.LookupPath(cue.MakePath(cue.Str(name))); v.Exists() {
+				x.kind = v.Kind()
+			}
+			return x, true
+		}
+	}
+	t.issuef(n, "reference to .%s is not supported", strings.Join(idents, "."))
+	return helmExpr{}, false
+}
+
+// replacePlaceholders replaces the placeholders in the labels and
+// strings of x by the translations of their actions.
+func (t *helmTemplate) replacePlaceholders(x ast.Node) {
+	astutil.Apply(x, func(c astutil.Cursor) bool {
+		switch n := c.Node().(type) {
+		case *ast.Field:
+			name, _, err := ast.LabelName(n.Label)
+			if err != nil || !strings.Contains(name, helmPlaceholder) {
+				break
+			}
+			if e, ok := t.placeholder(name); ok {
+				n.Label = &ast.ParenExpr{X: e.expr}
+			} else {
+				n.Label = t.interpolation(name)
+			}
+
+		case *ast.BasicLit:
+			if n.Kind != token.STRING {
+				break
+			}
+			s, err := literal.Unquote(n.Value)
+			if err != nil || !strings.Contains(s, helmPlaceholder) {
+				break
+			}
+			if e, ok := t.placeholder(s); ok {
+				if i, _ := strconv.Atoi(helmPlaceholderRe.FindStringSubmatch(s)[1]); !t.quoted[i] || e.kind == cue.StringKind {
+					c.Replace(e.expr)
+					break
+				}
+			}
+			c.Replace(t.interpolation(s))
+		}
+		return true
+	}, nil)
+}
+
+// placeholder returns the translation for s if it consists of a single
+// placeholder.
+func (t *helmTemplate) placeholder(s string) (helmExpr, bool) {
+	m := helmPlaceholderRe.FindStringSubmatch(s)
+	if m == nil || m[0] != s {
+		return helmExpr{}, false
+	}
+	i, _ := strconv.Atoi(m[1])
+	return t.exprs[i], true
+}
+
+// interpolation returns an interpolation for the string s with
+// placeholders.
+func (t *helmTemplate) interpolation(s string) *ast.Interpolation {
+	var (
+		parts []string
+		exprs []ast.Expr
+	)
+	last := 0
+	for _, m := range helmPlaceholderRe.FindAllStringSubmatchIndex(s, -1) {
+		i, _ := strconv.Atoi(s[m[2]:m[3]])
+		parts = append(parts, s[last:m[0]])
+		exprs = append(exprs, t.exprs[i].expr)
+		last = m[1]
+	}
+	parts = append(parts, s[last:])
+	return helmInterpolation(parts, exprs)
+}
+
+// helmInterpolation returns an interpolation of the expressions in exprs
+// interleaved with the strings in parts, which has one more element.
+func helmInterpolation(parts []string, exprs []ast.Expr) *ast.Interpolation {
+	x := &ast.Interpolation{}
+	for i, s := range parts {
+		lit := string(literal.String.AppendEscaped(nil, s))
+		switch {
+		case i == 0:
+			lit = `"` + lit
+		default:
+			lit = ")" + lit
+		}
+		if i < len(exprs) {
+			lit += `\(`
+		} else {
+			lit += `"`
+		}
+		x.Elts = append(x.Elts, &ast.BasicLit{Kind: token.STRING, Value: lit})
+		if i < len(exprs) {
+			x.Elts = append(x.Elts, exprs[i])
+		}
+	}
+	return x
+}
+
+// importCall returns a call to the function fn of the package with the
+// given import path.
+func (h *helmChart) importCall(path, fn string, args ...ast.Expr) ast.Expr {
+	h.imports[path] = true
+	return ast.NewCall(ast.NewSel(ast.NewIdent(filepath.Base(path)), fn), args...)
+}
+
+// helmSelector selects the field name of x.
+func helmSelector(x ast.Expr, name string) ast.Expr {
+	if ast.IsValidIdent(name) && !strings.HasPrefix(name, "_") && !strings.HasPrefix(name, "#") {
+		return ast.NewSel(x, name)
+	}
+	return &ast.IndexExpr{X: x, Index: ast.NewString(name)}
+}
+
+// helmNot returns the negation of the condition x.
+func helmNot(x ast.Expr) ast.Expr {
+	switch x := x.(type) {
+	case *ast.UnaryExpr:
+		if x.Op == token.NOT {
+			return x.X
+		}
+	case *ast.BinaryExpr:
+		switch x.Op {
+		case token.EQL:
+			return &ast.BinaryExpr{X: x.X, Op: token.NEQ, Y: x.Y}
+		case token.NEQ:
+			return &ast.BinaryExpr{X: x.X, Op: token.EQL, Y: x.Y}
+		}
+	}
+	return &ast.UnaryExpr{Op: token.NOT, X: helmParen(x)}
+}
+
+// helmParen parenthesizes x if it is a binary expression.
+func helmParen(x ast.Expr) ast.Expr {
+	if _, ok := x.(*ast.BinaryExpr); ok {
+		return &ast.ParenExpr{X: x}
+	}
+	return x
+}
+
+// helmDefaults returns x with its scalars and lists turned into defaults
+// of their kind, so that the values of a chart can be set like those of
+// values.yaml.
+func helmDefaults(x ast.Expr) ast.Expr {
+	switch x := x.(type) {
+	case *ast.StructLit:
+		for _, d := range x.Elts {
+			if f, ok := d.(*ast.Field); ok {
+				f.Value = helmDefaults(f.Value)
+			}
+		}
+		return x
+	case *ast.ListLit:
+		return ast.NewBinExpr(token.OR, &ast.UnaryExpr{Op: token.MUL, X: x}, ast.NewList(&ast.Ellipsis{}))
+	case *ast.BasicLit:
+		kind := map[token.Token]string{
+			token.STRING: "string",
+			token.INT:    "int",
+			token.FLOAT:  "number",
+			token.TRUE:   "bool",
+			token.FALSE:  "bool",
+			token.NULL:   "_",
+		}[x.Kind]
+		if kind == "" {
+			return x
+		}
+		return ast.NewBinExpr(token.OR, &ast.UnaryExpr{Op: token.MUL, X: x}, ast.NewIdent(kind))
+	}
+	return x
+}
+
+// helmSpan returns the first and last line of the source of x, or 0 if
+// x has no position.
+func helmSpan(x ast.Node) (first, last int) {
+	ast.Walk(x, func(n ast.Node) bool {
+		if _, ok := n.(*ast.CommentGroup); ok {
+			return false
+		}
+		// Generated nodes may have a relative position without a line.
+		if line := n.Pos().Line(); line > 0 {
+			if first == 0 || line < first {
+				first = line
+			}
+			last = max(last, line)
+		}
+		return true
+	}, nil)
+	return first, last
+}
+
+// wrapHelmBlock wraps the values of the list or struct x which lie on
+// the lines first to last in comprehensions with the condition cond. It
+// reports false if the lines do not enclose complete values.
+func wrapHelmBlock(x ast.Node, cond ast.Expr, first, last int) bool {
+	switch x := x.(type) {
+	case *ast.ListLit:
+		for i, e := range x.Elts {
+			lo, hi := helmSpan(e)
+			switch {
+			case lo == 0 || hi < first || lo > last:
+			case lo >= first && hi <= last:
+				x.Elts[i] = helmComprehension(cond, &ast.EmbedDecl{Expr: e})
+			case lo < first && hi >= last:
+				return wrapHelmBlock(e, cond, first, last)
+			default:
+				return false
+			}
+		}
+		return true
+
+	case *ast.StructLit:
+		var decls []ast.Decl
+		at := -1
+		for _, d := range x.Elts {
+			lo, hi := helmSpan(d)
+			switch {
+			case lo == 0 || hi < first || lo > last:
+				decls = append(decls, d)
+			case lo >= first && hi <= last:
+				if at < 0 {
+					at = len(decls)
+					decls = append(decls, nil)
+				}
+				c, ok := decls[at].(*ast.Comprehension)
+				if !ok {
+					c = helmComprehension(cond)
+					decls[at] = c
+				}
+				s := c.Value.(*ast.StructLit)
+				s.Elts = append(s.Elts, d)
+			case lo < first && hi >= last:
+				if f, ok := d.(*ast.Field); ok {
+					return wrapHelmBlock(f.Value, cond, first, last)
+				}
+				return false
+			default:
+				return false
+			}
+		}
+		// Merge conditions rather than nesting a single comprehension.
+		if at >= 0 {
+			c := decls[at].(*ast.Comprehension)
+			s := c.Value.(*ast.StructLit)
+			if len(s.Elts) == 1 {
+				if inner, ok := s.Elts[0].(*ast.Comprehension); ok {
+					inner.Clauses = append(c.Clauses, inner.Clauses...)
+					decls[at] = inner
+				}
+			}
+		}
+		x.Elts = decls
+		return true
+	}
+	return false
+}
+
+// helmComprehension returns a comprehension yielding the declarations
+// in decls if cond holds. A declaration which is itself a conditional
+// value gets the condition added to its own instead, and an embedded
+// struct is yielded as is.
+func helmComprehension(cond ast.Expr, decls ...ast.Decl) *ast.Comprehension {
+	if len(decls) == 1 {
+		if e, ok := decls[0].(*ast.EmbedDecl); ok {
+			switch x := e.Expr.(type) {
+			case *ast.Comprehension:
+				x.Clauses = append([]ast.Clause{&ast.IfClause{Condition: cond}}, x.Clauses...)
+				return x
+			case *ast.StructLit:
+				return &ast.Comprehension{
+					Clauses: []ast.Clause{&ast.IfClause{Condition: cond}},
+					Value:   x,
+				}
+			}
+		}
+	}
+	return &ast.Comprehension{
+		Clauses: []ast.Clause{&ast.IfClause{Condition: cond}},
+		Value:   &ast.StructLit{Elts: decls},
+	}
+}
+
+// packageNameOf returns a package name derived from name, or "" if
+// there is none.
+func packageNameOf(name string) string {
+	name = strings.Map(func(r rune) rune {
+		if unicode.In(r, unicode.L, unicode.N) {
+			return r
+		}
+		return '_'
+	}, name)
+	if !ast.IsValidIdent(name) || strings.HasPrefix(name, "_") {
+		return ""
+	}
+	return name
+}
@@ -0,0 +1,274 @@
+// Copyright 2025 CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"cmp"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"cuelang.org/go/cue"
+	"cuelang.org/go/cue/ast"
+	"cuelang.org/go/cue/errors"
+	"cuelang.org/go/cue/token"
+	"cuelang.org/go/internal"
+	"cuelang.org/go/internal/encoding"
+	"cuelang.org/go/internal/filetypes"
+)
+
+// This file contains the logic for importing trees of data files with
+// the --tree flag.
+
+// A treeFile is a data file found in a directory tree.
+type treeFile struct {
+	path  string   // path of the data file
+	dir   string   // directory of the data file, relative to the root
+	label string   // label of the value of the file within its directory
+	value ast.Expr // decoded value
+}
+
+func treeMode(cmd *Command, c *config, args []string) error {
+	for _, f := range []flagName{flagPath, flagList, flagFiles, flagWithContext, flagSchema} {
+		if cmd.Flags().Changed(string(f)) {
+			return errors.Newf(token.NoPos,
+				"cannot combine --%s flag with flag %q", flagTree, f)
+		}
+	}
+	if len(args) == 0 {
+		return errors.Newf(token.NoPos,
+			"--%s flag requires at least one directory", flagTree)
+	}
+	p, err := newBuildPlan(cmd, c)
+	if err != nil {
+		return err
+	}
+	single := outFileFlag(cmd) != ""
+	if single && len(args) > 1 {
+		return errors.Newf(token.NoPos,
+			"--%s flag with --%s requires a single directory", flagTree, flagOutFile)
+	}
+
+	for _, root := range args {
+		files, err := p.readTree(root)
+		if err != nil {
+			return err
+		}
+		if single {
+			err = p.writeTreePackage(root, files)
+		} else {
+			err = p.writeTreeFiles(files)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// readTree decodes all data files in the directory tree rooted at root
+// and assigns each a label unique within its directory.
+func (p *buildPlan) readTree(root string) ([]*treeFile, error) {
+	info, err := os.Stat(root)
+	if err != nil {
+		return nil, err
+	}
+	if !info.IsDir() {
+		return nil, errors.Newf(token.NoPos,
+			"%s is not a directory; --%s requires directories", root, flagTree)
+	}
+
+	var files []*treeFile
+	// labels holds the labels used by subdirectories of each directory.
+	labels := map[string]map[string]bool{}
+	err = filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		name := d.Name()
+		rel, err := filepath.Rel(root, path)
+		if err != nil {
+			return err
+		}
+		if rel == "." {
+			return nil
+		}
+		// Skip the same directories and files as the loader does.
+		if strings.HasPrefix(name, ".") || strings.HasPrefix(name, "_") || name == "cue.mod" {
+			if d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		dir := filepath.ToSlash(filepath.Dir(rel))
+		if d.IsDir() {
+			if labels[dir] == nil {
+				labels[dir] = map[string]bool{}
+			}
+			labels[dir][name] = true
+			return nil
+		}
+		if !p.matchFile(path) {
+			return nil
+		}
+		v, err := p.decodeTreeFile(path)
+		if err != nil {
+			return err
+		}
+		files = append(files, &treeFile{
+			path:  path,
+			dir:   dir,
+			label: strings.TrimSuffix(name, filepath.Ext(name)),
+			value: v,
+		})
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// Files whose label collides with that of another file or directory
+	// in the same directory use their full name instead.
+	count := map[[2]string]int{}
+	for _, f := range files {
+		count[[2]string{f.dir, f.label}]++
+	}
+	for _, f := range files {
+		if count[[2]string{f.dir, f.label}] > 1 || labels[f.dir][f.label] {
+			f.label = filepath.Base(f.path)
+		}
+	}
+	return files, nil
+}
+
+// decodeTreeFile decodes the data file at path. Files with more than one
+// value, such as YAML streams, result in a list.
+func (p *buildPlan) decodeTreeFile(path string) (ast.Expr, error) {
+	f, err := filetypes.ParseFile(path, filetypes.Input)
+	if err != nil {
+		return nil, err
+	}
+	if p.cfg.encoding != "" {
+		f.Encoding = p.cfg.encoding
+	}
+	f.Interpretation = p.cfg.interpretation
+
+	d := encoding.NewDecoder(p.cmd.ctx, f, p.encConfig)
+	defer d.Close()
+	var values []ast.Expr
+	for ; !d.Done(); d.Next() {
+		values = append(values, internal.ToExpr(d.File()))
+	}
+	if err := d.Err(); err != nil {
+		return nil, err
+	}
+	if len(values) == 1 {
+		return values[0], nil
+	}
+	return ast.NewList(values...), nil
+}
+
+// writeTreeFiles writes a CUE file next to each data file, placing its
+// value at the label of the file within the package of its directory.
+func (p *buildPlan) writeTreeFiles(files []*treeFile) error {
+	for _, tf := range files {
+		pkg, err := treePackageName(p, filepath.Dir(tf.path))
+		if err != nil {
+			return err
+		}
+		f := &ast.File{Decls: []ast.Decl{
+			&ast.Field{Label: ast.NewString(tf.label), Value: tf.value},
+		}}
+		internal.SetPackage(f, pkg, false)
+		if tf.label == filepath.Base(tf.path) {
+			f.Filename = tf.path + ".cue"
+		} else {
+			f.Filename = newName(tf.path, 0)
+		}
+		if err := handleFile(p, f); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// writeTreePackage writes the values of all files to a single CUE file,
+// placing each at the path derived from its directory and label. It
+// adds an #index definition mapping the name of each data file to the
+// path of its value.
+func (p *buildPlan) writeTreePackage(root string, files []*treeFile) error {
+	pkg, err := treePackageName(p, root)
+	if err != nil {
+		return err
+	}
+	slices.SortFunc(files, func(a, b *treeFile) int {
+		return cmp.Compare(a.path, b.path)
+	})
+
+	index := &ast.StructLit{}
+	f := &ast.File{}
+	for _, tf := range files {
+		var labels []string
+		if tf.dir != "." {
+			labels = strings.Split(tf.dir, "/")
+		}
+		labels = append(labels, tf.label)
+
+		sels := make([]cue.Selector, len(labels))
+		for i, l := range labels {
+			sels[i] = cue.Str(l)
+		}
+		rel, err := filepath.Rel(root, tf.path)
+		if err != nil {
+			return err
+		}
+		index.Elts = append(index.Elts, &ast.Field{
+			Label: ast.NewString(filepath.ToSlash(rel)),
+			Value: ast.NewString(cue.MakePath(sels...).String()),
+		})
+
+		var value ast.Expr = tf.value
+		for i := len(labels) - 1; i > 0; i-- {
+			value = ast.NewStruct(&ast.Field{Label: ast.NewString(labels[i]), Value: value})
+		}
+		f.Decls = append(f.Decls, &ast.Field{Label: ast.NewString(labels[0]), Value: value})
+	}
+	indexField := &ast.Field{Label: ast.NewIdent("#index"), Value: index}
+	ast.AddComment(indexField, internal.NewComment(true,
+		"#index maps each imported file to the path of its value."))
+	f.Decls = append([]ast.Decl{indexField}, f.Decls...)
+	internal.SetPackage(f, pkg, false)
+	return handleFile(p, f)
+}
+
+// treePackageName returns the package name given by the -p flag, or
+// else one derived from the name of dir.
+func treePackageName(p *buildPlan, dir string) (string, error) {
+	if pkg := p.encConfig.PkgName; pkg != "" {
+		return pkg, nil
+	}
+	abs, err := filepath.Abs(dir)
+	if err != nil {
+		return "", err
+	}
+	name := packageNameOf(filepath.Base(abs))
+	if name == "" {
+		return "", errors.Newf(token.NoPos,
+			"cannot derive package name from directory %s; use the -p flag", dir)
+	}
+	return name, nil
+}
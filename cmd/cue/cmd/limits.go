@@ -0,0 +1,102 @@
+// Copyright 2025 The CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"fmt"
+
+	"cuelang.org/go/cue"
+	"cuelang.org/go/cue/errors"
+	"cuelang.org/go/internal/core/adt"
+	"cuelang.org/go/internal/core/runtime"
+)
+
+// evalLimits holds the limits on evaluation set with the --timeout,
+// --max-nodes, and --max-disjuncts flags, which [newContext] applies to
+// each context.
+var evalLimits adt.Limits
+
+// runLimited runs f with the limits on evaluation set with the --timeout,
+// --max-nodes, and --max-disjuncts flags. The limits apply to each
+// evaluation separately, such as building an instance or validating a
+// value, so that each request of cue serve gets the full timeout. If
+// evaluation exceeds a limit, the command is aborted with an error naming
+// the value being evaluated.
+//
+// Evaluation which is not part of the configuration, such as that of the
+// schema of cue.mod/module.cue, uses contexts of its own and does not
+// count towards the limits.
+func runLimited(cmd *Command, f runFunction, args []string) (err error) {
+	flags := cmd.Flags()
+	timeout, _ := flags.GetDuration(string(flagTimeout))
+	maxNodes, _ := flags.GetInt64(string(flagMaxNodes))
+	maxDisjuncts, _ := flags.GetInt64(string(flagMaxDisjuncts))
+	switch {
+	case timeout < 0:
+		return fmt.Errorf("--%s must not be negative", flagTimeout)
+	case maxNodes < 0:
+		return fmt.Errorf("--%s must not be negative", flagMaxNodes)
+	case maxDisjuncts < 0:
+		return fmt.Errorf("--%s must not be negative", flagMaxDisjuncts)
+	}
+	limits := adt.Limits{
+		Timeout:      timeout,
+		MaxNodes:     maxNodes,
+		MaxDisjuncts: maxDisjuncts,
+	}
+	if limits == (adt.Limits{}) {
+		return f(cmd, args)
+	}
+
+	evalLimits = limits
+	defer func() { evalLimits = adt.Limits{} }()
+	setLimits(cmd.ctx)
+	defer recoverLimit(&err)
+	return f(cmd, args)
+}
+
+// setLimits applies the limits set with the flags to ctx.
+func setLimits(ctx *cue.Context) {
+	(*runtime.Runtime)(ctx).SetLimits(evalLimits)
+}
+
+// recoverLimit recovers from evaluation exceeding a limit, setting *err to
+// an error describing the limit. The evaluator panics in the goroutine
+// which evaluates, so every goroutine which may evaluate with limits must
+// defer a call to recoverLimit.
+func recoverLimit(err *error) {
+	switch r := recover().(type) {
+	case nil:
+	case *adt.LimitError:
+		*err = limitError(r)
+	default:
+		panic(r)
+	}
+}
+
+func limitError(e *adt.LimitError) errors.Error {
+	var flag flagName
+	switch e.Kind {
+	case adt.TimeoutLimit:
+		flag = flagTimeout
+	case adt.NodeLimit:
+		flag = flagMaxNodes
+	case adt.DisjunctLimit:
+		flag = flagMaxDisjuncts
+	}
+	return errors.WithHints(errors.Newf(e.Pos, "%v", e), errors.Hint{
+		Message: fmt.Sprintf("raise the limit with --%s, or simplify the configuration", flag),
+	})
+}
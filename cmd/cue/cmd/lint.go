@@ -0,0 +1,601 @@
+// Copyright 2025 CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"cuelang.org/go/cue/ast"
+	"cuelang.org/go/cue/errors"
+	"cuelang.org/go/cue/literal"
+	"cuelang.org/go/cue/token"
+)
+
+// lintRules are the rules checked by cue lint, in the order in which they
+// are documented.
+var lintRules = []*diagnosticRule{{
+	id:          "unused-import",
+	description: "import which is never referenced",
+}, {
+	id:          "unused-let",
+	description: "let clause or alias which is never referenced",
+}, {
+	id:          "shadowed-field",
+	description: "reference to a declaration which shadows another of the same name",
+}, {
+	id:          "deprecated",
+	description: "field marked with @deprecated which is set",
+}, {
+	id:          "regexp",
+	description: "invalid or non-canonical regular expression constraint",
+}}
+
+func newLintCmd(c *Command) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "lint [flags] [packages]",
+		Short: "check CUE packages for common mistakes and style issues",
+		Long: `lint checks CUE packages for mistakes and style issues which, unlike the
+errors reported by vet, do not make a configuration invalid.
+
+The following rules are checked:
+
+  unused-import   imports which are never referenced
+  unused-let      let clauses and aliases which are never referenced
+  shadowed-field  references which resolve to a field, let clause, or
+                  alias that shadows a declaration of the same name in
+                  an enclosing scope, as with b in
+
+                      b: 1
+                      x: {
+                          b: 2
+                          c: b // refers to x.b
+                      }
+
+  deprecated      fields marked with a @deprecated attribute which are
+                  set to a concrete value
+  regexp          regular expressions used with =~ or !~ which are
+                  invalid, which start or end with a redundant .*, or
+                  which contain backslashes but are not written as a raw
+                  string such as #"\d+"#
+
+All rules are checked by default. With --enable, only the given rules are
+checked, and --disable skips the given rules. Both flags may be repeated
+or take a comma-separated list of rules. As with any command, the rules
+of a module may be configured in its cue.config file:
+
+  lint: disable: ["shadowed-field"]
+
+The deprecated rule requires the packages to evaluate; use cue vet to
+report their errors.
+
+Each finding is reported as a warning with the name of its rule. The
+--error-format flag selects the format of the findings, such as json or
+sarif for machine-readable output, as described in 'cue help vet'.
+Findings which can be fixed mechanically carry a hint with the edit to
+make. lint exits with status 1 if there are any findings, which may be
+changed with --exit-code warning=0.
+`,
+		RunE: mkRunE(c, runLint),
+	}
+	cmd.Flags().StringArray(string(flagEnable), nil,
+		"only check these rules")
+	cmd.Flags().StringArray(string(flagDisable), nil,
+		"do not check these rules")
+	cmd.Flags().String(string(flagErrorFormat), diagText,
+		"format for reporting findings (text|short|json|sarif|github|gitlab)")
+	cmd.Flags().StringArray(string(flagExitCode), nil,
+		"exit code for a kind of failure (invalid|error|warning=code)")
+	return cmd
+}
+
+func runLint(cmd *Command, args []string) error {
+	r, err := newDiagReporter(cmd, flagErrorFormat.String(cmd))
+	if err != nil {
+		return err
+	}
+	codes := defaultExitCodes
+	codes.warning = 1
+	if r.exitCodes, err = parseExitCodes(codes, flagExitCode.StringArray(cmd)); err != nil {
+		return err
+	}
+	rules, err := lintRulesFor(flagEnable.StringArray(cmd), flagDisable.StringArray(cmd))
+	if err != nil {
+		return r.fail(err)
+	}
+	cfg, err := defaultConfig()
+	if err != nil {
+		return r.fail(err)
+	}
+	for _, binst := range loadFromArgs(args, cfg.loadCfg) {
+		if err := binst.Err; err != nil {
+			r.toolError(err)
+			continue
+		}
+		l := &linter{rules: rules, skip: map[*ast.Ident]bool{}}
+		l.pkg(binst.Files)
+		if rule := rules["deprecated"]; rule != nil {
+			v := cmd.ctx.BuildInstance(binst)
+			if v.Err() == nil {
+				for _, w := range errors.Errors(v.Warnings()) {
+					if errors.CodeOf(w) != errors.DeprecatedField {
+						continue
+					}
+					l.findings = append(l.findings, &lintFinding{
+						rule:    rule,
+						pos:     w.Position(),
+						path:    w.Path(),
+						message: diagnosticMessage(w),
+					})
+				}
+			}
+		}
+		var findings errors.Error
+		for _, f := range l.findings {
+			findings = errors.Append(findings, f)
+		}
+		r.warn(findings)
+	}
+	return r.flush()
+}
+
+// lintRulesFor returns the rules to check by their names, given the
+// values of the --enable and --disable flags.
+func lintRulesFor(enable, disable []string) (map[string]*diagnosticRule, error) {
+	byName := map[string]*diagnosticRule{}
+	for _, rule := range lintRules {
+		byName[rule.id] = rule
+	}
+	names := func(args []string) (map[string]bool, error) {
+		m := map[string]bool{}
+		for _, arg := range args {
+			for _, name := range strings.Split(arg, ",") {
+				name = strings.TrimSpace(name)
+				if byName[name] == nil {
+					return nil, fmt.Errorf("unknown lint rule %q; see 'cue help lint'", name)
+				}
+				m[name] = true
+			}
+		}
+		return m, nil
+	}
+	enabled, err := names(enable)
+	if err != nil {
+		return nil, err
+	}
+	disabled, err := names(disable)
+	if err != nil {
+		return nil, err
+	}
+	rules := map[string]*diagnosticRule{}
+	for name, rule := range byName {
+		if (len(enabled) == 0 || enabled[name]) && !disabled[name] {
+			rules[name] = rule
+		}
+	}
+	return rules, nil
+}
+
+// lintFinding is a warning reported by a lint rule.
+type lintFinding struct {
+	rule    *diagnosticRule
+	pos     token.Pos
+	related []token.Pos
+	path    []string
+	message string
+	hints   []errors.Hint
+}
+
+func (f *lintFinding) Position() token.Pos         { return f.pos }
+func (f *lintFinding) InputPositions() []token.Pos { return f.related }
+func (f *lintFinding) Path() []string              { return f.path }
+func (f *lintFinding) Hints() []errors.Hint        { return f.hints }
+
+func (f *lintFinding) Msg() (string, []interface{}) {
+	return "%s (lint %s)", []interface{}{f.message, f.rule.id}
+}
+
+func (f *lintFinding) Error() string {
+	format, args := f.Msg()
+	return fmt.Sprintf(format, args...)
+}
+
+// A lintDecl is a declaration which identifiers may refer to.
+type lintDecl struct {
+	kind   string // field, let, alias, import, or variable
+	name   string
+	pos    token.Pos
+	used   bool
+	unused string       // the message to report if unused, if any
+	hint   *errors.Hint // how to remove the declaration if unused
+	shadow bool         // whether a shadowing reference was reported
+}
+
+// A lintScope holds the declarations of a struct, file, package, or
+// comprehension in the order in which they are declared.
+type lintScope []*lintDecl
+
+func (s lintScope) lookup(name string) *lintDecl {
+	for _, d := range s {
+		if d.name == name {
+			return d
+		}
+	}
+	return nil
+}
+
+// linter checks the syntax of the files of a package.
+type linter struct {
+	rules    map[string]*diagnosticRule
+	scopes   []lintScope
+	skip     map[*ast.Ident]bool // identifiers which are not references
+	findings []*lintFinding
+}
+
+func (l *linter) report(rule string, pos token.Pos, related []token.Pos, hint *errors.Hint, format string, args ...interface{}) {
+	f := &lintFinding{
+		rule:    l.rules[rule],
+		pos:     pos,
+		related: related,
+		message: fmt.Sprintf(format, args...),
+	}
+	if f.rule == nil {
+		return
+	}
+	if hint != nil {
+		f.hints = []errors.Hint{*hint}
+	}
+	l.findings = append(l.findings, f)
+}
+
+// pkg checks the files of a package. The top-level fields of all files
+// are in scope within each of them.
+func (l *linter) pkg(files []*ast.File) {
+	var pkg lintScope
+	for _, f := range files {
+		for _, d := range f.Decls {
+			pkg = append(pkg, l.fieldDecls(d, false)...)
+		}
+	}
+	l.push(pkg)
+	for _, f := range files {
+		var scope lintScope
+		for _, d := range f.Decls {
+			switch d := d.(type) {
+			case *ast.ImportDecl:
+				for _, spec := range d.Specs {
+					scope = append(scope, l.importDecl(d, spec))
+				}
+			case *ast.Field:
+				scope = append(scope, l.aliasDecl(d)...)
+			case *ast.LetClause:
+				scope = append(scope, l.letDecl(d))
+			}
+		}
+		l.push(scope)
+		for _, d := range f.Decls {
+			switch d.(type) {
+			case *ast.Package, *ast.ImportDecl:
+				continue
+			}
+			l.walk(d)
+		}
+		l.pop()
+	}
+	l.pop()
+}
+
+// fieldDecls returns the declaration of the field d, if it is a field
+// whose label is an identifier, and of its alias if withAlias is set.
+func (l *linter) fieldDecls(d ast.Decl, withAlias bool) lintScope {
+	f, ok := d.(*ast.Field)
+	if !ok {
+		return nil
+	}
+	var scope lintScope
+	label := f.Label
+	if a, ok := label.(*ast.Alias); ok {
+		if withAlias {
+			scope = append(scope, l.aliasDecl(f)...)
+		}
+		label, _ = a.Expr.(ast.Label)
+	}
+	if ident, ok := label.(*ast.Ident); ok {
+		l.skip[ident] = true
+		scope = append(scope, &lintDecl{kind: "field", name: ident.Name, pos: ident.Pos()})
+	}
+	return scope
+}
+
+// aliasDecl returns the declaration of the label alias of f, if any.
+func (l *linter) aliasDecl(f *ast.Field) lintScope {
+	a, ok := f.Label.(*ast.Alias)
+	if !ok {
+		return nil
+	}
+	l.skip[a.Ident] = true
+	return lintScope{{
+		kind:   "alias",
+		name:   a.Ident.Name,
+		pos:    a.Ident.Pos(),
+		unused: fmt.Sprintf("alias %s is not used", a.Ident.Name),
+		hint: &errors.Hint{
+			Message: fmt.Sprintf("remove the alias %s", a.Ident.Name),
+			Edits:   []errors.Edit{{Start: a.Ident.Pos(), End: a.Expr.Pos()}},
+		},
+	}}
+}
+
+func (l *linter) letDecl(let *ast.LetClause) *lintDecl {
+	l.skip[let.Ident] = true
+	return &lintDecl{
+		kind:   "let",
+		name:   let.Ident.Name,
+		pos:    let.Ident.Pos(),
+		unused: fmt.Sprintf("let %s is not used", let.Ident.Name),
+		hint: &errors.Hint{
+			Message: fmt.Sprintf("remove the let clause %s", let.Ident.Name),
+			Edits:   []errors.Edit{{Start: let.Pos(), End: let.End()}},
+		},
+	}
+}
+
+func (l *linter) importDecl(d *ast.ImportDecl, spec *ast.ImportSpec) *lintDecl {
+	path, _ := literal.Unquote(spec.Path.Value)
+	decl := &lintDecl{
+		kind:   "import",
+		name:   ast.ParseImportPath(path).Qualifier,
+		pos:    spec.Pos(),
+		unused: fmt.Sprintf("%q is imported but not used", path),
+		hint:   &errors.Hint{Message: fmt.Sprintf("remove the import %q", path)},
+	}
+	if spec.Name != nil {
+		decl.name = spec.Name.Name
+	}
+	// Remove the whole import declaration along with its last import.
+	var remove ast.Node = spec
+	if len(d.Specs) == 1 {
+		remove = d
+	}
+	decl.hint.Edits = []errors.Edit{{Start: remove.Pos(), End: remove.End()}}
+	return decl
+}
+
+func (l *linter) push(s lintScope) {
+	l.scopes = append(l.scopes, s)
+}
+
+// pop leaves the innermost scope, reporting its unused declarations.
+func (l *linter) pop() {
+	scope := l.scopes[len(l.scopes)-1]
+	l.scopes = l.scopes[:len(l.scopes)-1]
+	for _, d := range scope {
+		if d.used || d.unused == "" {
+			continue
+		}
+		rule := "unused-let"
+		if d.kind == "import" {
+			rule = "unused-import"
+		}
+		l.report(rule, d.pos, nil, d.hint, "%s", d.unused)
+	}
+}
+
+func (l *linter) walk(n ast.Node) {
+	ast.Walk(n, l.before, l.after)
+}
+
+func (l *linter) before(n ast.Node) bool {
+	switch n := n.(type) {
+	case *ast.StructLit:
+		var scope lintScope
+		for _, d := range n.Elts {
+			scope = append(scope, l.fieldDecls(d, true)...)
+			if let, ok := d.(*ast.LetClause); ok {
+				scope = append(scope, l.letDecl(let))
+			}
+		}
+		l.push(scope)
+
+	case *ast.Field:
+		l.field(n)
+		return false
+
+	case *ast.Comprehension:
+		l.comprehension(n)
+		return false
+
+	case *ast.LetClause:
+		l.skip[n.Ident] = true
+
+	case *ast.Alias:
+		l.skip[n.Ident] = true
+
+	case *ast.SelectorExpr:
+		if ident, ok := n.Sel.(*ast.Ident); ok {
+			l.skip[ident] = true
+		}
+
+	case *ast.Ident:
+		if !l.skip[n] {
+			l.resolve(n)
+		}
+
+	case *ast.BinaryExpr:
+		if n.Op == token.MAT || n.Op == token.NMAT {
+			l.regexp(n.Y)
+		}
+
+	case *ast.UnaryExpr:
+		if n.Op == token.MAT || n.Op == token.NMAT {
+			l.regexp(n.X)
+		}
+	}
+	return true
+}
+
+func (l *linter) after(n ast.Node) {
+	if _, ok := n.(*ast.StructLit); ok {
+		l.pop()
+	}
+}
+
+// field walks the label and value of f. The alias of a pattern constraint,
+// as in [X=string]: v, and a value alias, as in a: X=v, are in scope
+// within the value.
+func (l *linter) field(f *ast.Field) {
+	label := ast.Node(f.Label)
+	if a, ok := label.(*ast.Alias); ok {
+		l.skip[a.Ident] = true
+		label = a.Expr
+	}
+	var scope lintScope
+	switch x := label.(type) {
+	case *ast.Ident:
+		l.skip[x] = true
+	case *ast.ListLit:
+		if len(x.Elts) == 1 {
+			if a, ok := x.Elts[0].(*ast.Alias); ok {
+				l.skip[a.Ident] = true
+				scope = append(scope, &lintDecl{kind: "alias", name: a.Ident.Name, pos: a.Ident.Pos()})
+				label = a.Expr
+			}
+		}
+	}
+	l.walk(label)
+
+	value := f.Value
+	if a, ok := value.(*ast.Alias); ok {
+		l.skip[a.Ident] = true
+		scope = append(scope, &lintDecl{kind: "alias", name: a.Ident.Name, pos: a.Ident.Pos()})
+		value = a.Expr
+	}
+	l.push(scope)
+	l.walk(value)
+	l.pop()
+}
+
+// comprehension walks the clauses of c, each of which is in the scope of
+// the variables declared by the clauses before it, and then its value.
+func (l *linter) comprehension(c *ast.Comprehension) {
+	l.push(nil)
+	scope := &l.scopes[len(l.scopes)-1]
+	for _, clause := range c.Clauses {
+		switch clause := clause.(type) {
+		case *ast.ForClause:
+			l.walk(clause.Source)
+			for _, ident := range []*ast.Ident{clause.Key, clause.Value} {
+				if ident != nil {
+					l.skip[ident] = true
+					*scope = append(*scope, &lintDecl{kind: "variable", name: ident.Name, pos: ident.Pos()})
+				}
+			}
+		case *ast.LetClause:
+			l.walk(clause.Expr)
+			*scope = append(*scope, l.letDecl(clause))
+		default:
+			l.walk(clause)
+		}
+	}
+	l.walk(c.Value)
+	l.pop()
+}
+
+// resolve marks the declaration ident refers to as used, and reports it
+// if it shadows another declaration of the same name.
+func (l *linter) resolve(ident *ast.Ident) {
+	name := ident.Name
+	if name == "_" || strings.HasPrefix(name, "__") {
+		return
+	}
+	for i := len(l.scopes) - 1; i >= 0; i-- {
+		d := l.scopes[i].lookup(name)
+		if d == nil {
+			continue
+		}
+		d.used = true
+		if d.shadow {
+			return
+		}
+		for _, outer := range slices.Backward(l.scopes[:i]) {
+			if o := outer.lookup(name); o != nil {
+				d.shadow = true
+				l.report("shadowed-field", ident.Pos(), []token.Pos{o.pos, d.pos}, nil,
+					"%s refers to the %s declared in an inner scope, which shadows the %s of the same name", name, d.kind, o.kind)
+				break
+			}
+		}
+		return
+	}
+}
+
+// regexp checks the regular expression of a =~ or !~ constraint, if it
+// is given as a string literal.
+func (l *linter) regexp(x ast.Expr) {
+	lit, ok := x.(*ast.BasicLit)
+	if !ok || lit.Kind != token.STRING {
+		return
+	}
+	info, _, _, err := literal.ParseQuotes(lit.Value, lit.Value)
+	if err != nil || info.IsMulti() || !info.IsDouble() {
+		return
+	}
+	s, err := literal.Unquote(lit.Value)
+	if err != nil {
+		return
+	}
+	if _, err := regexp.Compile(s); err != nil {
+		l.report("regexp", lit.Pos(), nil, nil, "invalid regular expression: %v", err)
+		return
+	}
+
+	// An unanchored regular expression matches anywhere in a string, so a
+	// leading or trailing .* only makes it harder to read.
+	p := s
+	if rest, ok := strings.CutPrefix(p, ".*"); ok && rest != "" && !strings.HasPrefix(rest, "?") {
+		p = rest
+	}
+	if rest, ok := strings.CutSuffix(p, ".*"); ok && rest != "" && !escaped(rest) {
+		p = rest
+	}
+	raw := strings.HasPrefix(lit.Value, "#")
+	var canonical string
+	switch {
+	case (raw || strings.Contains(p, `\`)) && !strings.Contains(p, `"#`):
+		canonical = `#"` + p + `"#`
+	case raw && p == s:
+		canonical = lit.Value
+	default:
+		canonical = literal.String.Quote(p)
+	}
+	if canonical == lit.Value {
+		return
+	}
+	l.report("regexp", lit.Pos(), nil, &errors.Hint{
+		Message: fmt.Sprintf("use %s", canonical),
+		Edits:   []errors.Edit{{Start: lit.Pos(), End: lit.End(), NewText: canonical}},
+	}, "regular expression %s is not in canonical form", lit.Value)
+}
+
+// escaped reports whether s ends with an unescaped backslash, which would
+// escape a character following it.
+func escaped(s string) bool {
+	n := len(s) - len(strings.TrimRight(s, `\`))
+	return n%2 == 1
+}
@@ -16,10 +16,15 @@ package cmd
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"io/fs"
+	"maps"
 	"net/http"
 	"os"
+	"slices"
 	"strings"
+	"text/tabwriter"
 	"time"
 
 	"github.com/spf13/cobra"
@@ -57,30 +62,29 @@ Use the --token flag to provide a token generated via the web interface,
 removing the need for a human to interact with the OAuth device flow.
 
 Once the authorization is successful, a token is stored in a logins.json file
-inside $CUE_CONFIG_DIR; see 'cue help environment'.
+inside $CUE_CONFIG_DIR; see 'cue help environment'. Logins for any number of
+registries may be stored at once, and are used for the registry hosts they
+were stored for.
+
+Use the --credential-helper flag to keep the tokens in a native credential
+store, such as the macOS keychain, rather than in plain text in logins.json.
+Its value names a Docker credential helper program without its
+"docker-credential-" prefix, such as "osxkeychain", "secretservice", "wincred"
+or "pass", which must be in $PATH. All existing tokens are moved to the
+credential helper, and logins.json then only holds the registry names and the
+expiry times of their tokens. Use --credential-helper=file to move the tokens
+back into logins.json.
+
+The subcommands list the stored logins, remove them, and refresh their tokens.
 `[1:],
 		Args: cobra.MaximumNArgs(1),
 		RunE: mkRunE(c, func(cmd *Command, args []string) error {
-			var locResolver modresolve.LocationResolver
-			var err error
-			if len(args) > 0 {
-				locResolver, err = modresolve.ParseCUERegistry(args[0], "")
-				if err != nil {
-					return err
-				}
-			} else {
-				locResolver, err = getRegistryResolver()
-				if err != nil {
-					return err
-				}
-			}
-			registryHosts := locResolver.AllHosts()
-			if len(registryHosts) > 1 {
-				return fmt.Errorf("need a single CUE registry to log into")
+			host, err := loginRegistryHost(args)
+			if err != nil {
+				return err
 			}
 			// TODO(mvdan): should we refuse to log into a CUE registry where host.Insecure==true?
 			// It is useful for local testing or debugging, but is otherwise pretty dangerous.
-			host := registryHosts[0]
 			loginsPath, err := cueconfig.LoginConfigPath(os.Getenv)
 			if err != nil {
 				return fmt.Errorf("cannot find the path to store CUE registry logins: %v", err)
@@ -96,16 +100,7 @@ inside $CUE_CONFIG_DIR; see 'cue help environment'.
 					return fmt.Errorf("the --token flag needs a non-empty string")
 				}
 
-				ctx := cmd.Context()
-				// Cause the oauth2 logic to log HTTP requests when logging is enabled.
-				// TODO(mvdan): test that these debug logs actually work.
-				ctx = context.WithValue(ctx, oauth2.HTTPClient, &http.Client{
-					Transport: httpTransport(),
-				})
-				// Elide request and response bodies because they're likely to include sensitive information.
-				ctx = httplog.RedactRequestBody(ctx, "request body can contain sensitive data when logging in")
-				ctx = httplog.RedactResponseBody(ctx, "response body can contain sensitive data when logging in")
-
+				ctx := loginContext(cmd)
 				oauthCfg := cueconfig.RegistryOAuthConfig(host)
 				resp, err := oauthCfg.DeviceAuth(ctx)
 				if err != nil {
@@ -141,26 +136,243 @@ inside $CUE_CONFIG_DIR; see 'cue help environment'.
 				return fmt.Errorf("unknown token format, expected an appv1_ prefix")
 			}
 
-			// For consistency, store timestamps in UTC.
-			tok.Expiry = tok.Expiry.UTC()
-			// OAuth2 measures expiry in seconds via the expires_in JSON wire format field,
-			// so any sub-second units add unnecessary verbosity.
-			tok.Expiry = tok.Expiry.Truncate(time.Second)
-
-			_, err = cueconfig.UpdateRegistryLogin(loginsPath, host.Name, tok)
+			if helper := flagCredentialHelper.String(cmd); helper != "" {
+				if helper == "file" {
+					helper = ""
+				}
+				if _, err := cueconfig.SetCredentialHelper(loginsPath, helper); err != nil {
+					return fmt.Errorf("cannot move CUE registry logins: %v", err)
+				}
+			}
+			logins, err := storeLogin(loginsPath, host.Name, tok)
 			if err != nil {
-				return fmt.Errorf("cannot store CUE registry logins: %v", err)
+				return err
+			}
+			if helper := logins.CredentialHelper; helper != "" {
+				fmt.Printf("Login for %s stored with credential helper %s\n", host.Name, helper)
+			} else {
+				fmt.Printf("Login for %s stored in %s\n", host.Name, loginsPath)
 			}
-			fmt.Printf("Login for %s stored in %s\n", host.Name, loginsPath)
-			// TODO: Once we support encryption, we should print a warning if it's not available.
 			return nil
 		}),
 	}
 	cmd.Flags().String(string(flagToken), "",
 		"provide an access token rather than starting the OAuth device flow")
+	cmd.Flags().String(string(flagCredentialHelper), "",
+		"store tokens with the given Docker credential helper, or in logins.json with \"file\"")
+	cmd.AddCommand(
+		newLoginListCmd(c),
+		newLoginLogoutCmd(c),
+		newLoginRefreshCmd(c),
+	)
 	return cmd
 }
 
+func newLoginListCmd(c *Command) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "list",
+		Short: "list the stored registry logins",
+		Long: `
+List the registries with a stored login, along with the expiry time of their
+tokens and whether they can be refreshed. The expiry time of tokens given with
+--token is not known, and is shown as "-".
+`[1:],
+		Args: cobra.NoArgs,
+		RunE: mkRunE(c, func(cmd *Command, args []string) error {
+			loginsPath, err := cueconfig.LoginConfigPath(os.Getenv)
+			if err != nil {
+				return fmt.Errorf("cannot find the path to CUE registry logins: %v", err)
+			}
+			logins, err := cueconfig.ReadLogins(loginsPath)
+			if errors.Is(err, fs.ErrNotExist) || (err == nil && len(logins.Registries) == 0) {
+				fmt.Fprintln(cmd.OutOrStdout(), "no registry logins")
+				return nil
+			} else if err != nil {
+				return fmt.Errorf("cannot load CUE registry logins: %v", err)
+			}
+			tw := tabwriter.NewWriter(cmd.OutOrStdout(), 0, 8, 2, ' ', 0)
+			fmt.Fprintln(tw, "REGISTRY\tEXPIRES\tREFRESHABLE")
+			for _, name := range slices.Sorted(maps.Keys(logins.Registries)) {
+				login := logins.Registries[name]
+				refreshable := "no"
+				if login.RefreshToken != "" {
+					refreshable = "yes"
+				}
+				fmt.Fprintf(tw, "%s\t%s\t%s\n", name, loginExpiry(login), refreshable)
+			}
+			if err := tw.Flush(); err != nil {
+				return err
+			}
+			if helper := logins.CredentialHelper; helper != "" {
+				fmt.Fprintf(cmd.OutOrStdout(), "\nTokens are stored with credential helper %s\n", helper)
+			} else {
+				fmt.Fprintf(cmd.OutOrStdout(), "\nTokens are stored in %s\n", loginsPath)
+			}
+			return nil
+		}),
+	}
+	return cmd
+}
+
+func newLoginLogoutCmd(c *Command) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "logout [registry]",
+		Short: "remove the stored login for a registry",
+		Long: `
+Remove the stored login for a registry, including its token in a credential
+helper. Without an argument, CUE_REGISTRY is used if it points to a single
+registry. The token itself is not revoked; use the registry's web interface
+to do so.
+`[1:],
+		Args: cobra.MaximumNArgs(1),
+		RunE: mkRunE(c, func(cmd *Command, args []string) error {
+			host, err := loginRegistryHost(args)
+			if err != nil {
+				return err
+			}
+			loginsPath, err := cueconfig.LoginConfigPath(os.Getenv)
+			if err != nil {
+				return fmt.Errorf("cannot find the path to CUE registry logins: %v", err)
+			}
+			ok, err := cueconfig.RemoveRegistryLogin(loginsPath, host.Name)
+			if err != nil {
+				return fmt.Errorf("cannot remove CUE registry login: %v", err)
+			}
+			if !ok {
+				return fmt.Errorf("no login stored for %s", host.Name)
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "Login for %s removed\n", host.Name)
+			return nil
+		}),
+	}
+	return cmd
+}
+
+func newLoginRefreshCmd(c *Command) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "refresh [registry]",
+		Short: "refresh the token for a registry",
+		Long: `
+Obtain a new access token for a registry with the refresh token of its stored
+login, and store it. Without an argument, CUE_REGISTRY is used if it points to
+a single registry.
+
+Tokens are refreshed automatically when they expire while they are in use, so
+this is only needed to renew a token ahead of time, such as before a long
+offline period or to check that a login is still valid.
+`[1:],
+		Args: cobra.MaximumNArgs(1),
+		RunE: mkRunE(c, func(cmd *Command, args []string) error {
+			host, err := loginRegistryHost(args)
+			if err != nil {
+				return err
+			}
+			loginsPath, err := cueconfig.LoginConfigPath(os.Getenv)
+			if err != nil {
+				return fmt.Errorf("cannot find the path to CUE registry logins: %v", err)
+			}
+			logins, err := cueconfig.ReadLogins(loginsPath)
+			if err != nil && !errors.Is(err, fs.ErrNotExist) {
+				return fmt.Errorf("cannot load CUE registry logins: %v", err)
+			}
+			var login cueconfig.RegistryLogin
+			ok := false
+			if logins != nil {
+				login, ok = logins.Registries[host.Name]
+			}
+			if !ok {
+				return fmt.Errorf("no login stored for %s; run 'cue login' first", host.Name)
+			}
+			if login.RefreshToken == "" {
+				return fmt.Errorf("the login for %s has no refresh token; run 'cue login' again", host.Name)
+			}
+			oauthCfg := cueconfig.RegistryOAuthConfig(host)
+			// Leave out the access token, so that a new one is always obtained.
+			tok, err := oauthCfg.TokenSource(loginContext(cmd), &oauth2.Token{
+				RefreshToken: login.RefreshToken,
+			}).Token()
+			if err != nil {
+				return fmt.Errorf("cannot refresh the OAuth2 token: %v", err)
+			}
+			logins, err = storeLogin(loginsPath, host.Name, tok)
+			if err != nil {
+				return err
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "Login for %s refreshed; expires %s\n",
+				host.Name, loginExpiry(logins.Registries[host.Name]))
+			return nil
+		}),
+	}
+	return cmd
+}
+
+// loginRegistryHost returns the registry host given as an argument, or
+// the single host CUE_REGISTRY points to.
+func loginRegistryHost(args []string) (modresolve.Host, error) {
+	var locResolver modresolve.LocationResolver
+	var err error
+	if len(args) > 0 {
+		locResolver, err = modresolve.ParseCUERegistry(args[0], "")
+		if err != nil {
+			return modresolve.Host{}, err
+		}
+	} else {
+		locResolver, err = getRegistryResolver()
+		if err != nil {
+			return modresolve.Host{}, err
+		}
+	}
+	registryHosts := locResolver.AllHosts()
+	if len(registryHosts) > 1 {
+		return modresolve.Host{}, fmt.Errorf("need a single CUE registry to log into")
+	}
+	return registryHosts[0], nil
+}
+
+// loginContext returns the context for the OAuth2 requests of cmd.
+func loginContext(cmd *Command) context.Context {
+	ctx := cmd.Context()
+	// Cause the oauth2 logic to log HTTP requests when logging is enabled.
+	// TODO(mvdan): test that these debug logs actually work.
+	ctx = context.WithValue(ctx, oauth2.HTTPClient, &http.Client{
+		Transport: httpTransport(),
+	})
+	// Elide request and response bodies because they're likely to include sensitive information.
+	ctx = httplog.RedactRequestBody(ctx, "request body can contain sensitive data when logging in")
+	ctx = httplog.RedactResponseBody(ctx, "response body can contain sensitive data when logging in")
+	return ctx
+}
+
+// storeLogin stores tok as the login for the registry host.
+func storeLogin(loginsPath, host string, tok *oauth2.Token) (*cueconfig.Logins, error) {
+	// For consistency, store timestamps in UTC.
+	tok.Expiry = tok.Expiry.UTC()
+	// OAuth2 measures expiry in seconds via the expires_in JSON wire format field,
+	// so any sub-second units add unnecessary verbosity.
+	tok.Expiry = tok.Expiry.Truncate(time.Second)
+
+	logins, err := cueconfig.UpdateRegistryLogin(loginsPath, host, tok)
+	if err != nil {
+		return nil, fmt.Errorf("cannot store CUE registry logins: %v", err)
+	}
+	return logins, nil
+}
+
+// loginExpiry describes when the token of login expires.
+func loginExpiry(login cueconfig.RegistryLogin) string {
+	if login.Expiry == nil {
+		return "-"
+	}
+	s := login.Expiry.UTC().Format(time.RFC3339)
+	if left := time.Until(*login.Expiry); left <= 0 {
+		s += " (expired)"
+	} else {
+		s += fmt.Sprintf(" (in %v)", left.Round(time.Minute))
+	}
+	return s
+}
+
 const (
-	flagToken flagName = "token"
+	flagToken            flagName = "token"
+	flagCredentialHelper flagName = "credential-helper"
 )
@@ -0,0 +1,150 @@
+// Copyright 2025 The CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+
+	"cuelang.org/go/cue/ast"
+	"cuelang.org/go/cue/parser"
+	"cuelang.org/go/tools/merge"
+)
+
+func newMergeCmd(c *Command) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "merge --base base.cue ours.cue theirs.cue",
+		Short: "merge the changes made to a file on two sides",
+		Long: `
+merge combines the changes made from a base version of a CUE or YAML file
+to two other versions, such as the versions of a file on two branches and
+their common ancestor, and writes the result to standard output or to the
+file given with --outfile.
+
+Unlike a line-based merge, the files are merged by their structure: changes
+only conflict if they change the same field differently, even if they are
+on adjacent lines. Changes to the fields of a struct which was changed on
+both sides are merged field by field. Lists and other values are merged as
+a whole.
+
+Each conflict is shown between the usual conflict markers, around the
+complete declarations of both sides:
+
+	<<<<<<< ours
+	replicas: 2
+	=======
+	replicas: 3
+	>>>>>>> theirs
+
+If there are conflicts, their paths are printed to standard error and the
+command exits with a non-zero status.
+
+YAML files are recognized by a .yaml or .yml extension of the ours file, or
+with --out yaml. Their result keeps the formatting and comments of ours.
+
+merge can be used as a git merge driver. For example, with this in
+.gitattributes:
+
+	*.cue merge=cue
+	*.yaml merge=cue-yaml
+
+and this in the git configuration:
+
+	[merge "cue"]
+		name = CUE structural merge
+		driver = cue merge --base %O -o %A %A %B
+	[merge "cue-yaml"]
+		name = YAML structural merge
+		driver = cue merge --out yaml --base %O -o %A %A %B
+`[1:],
+		Args: cobra.ExactArgs(2),
+		RunE: mkRunE(c, runMerge),
+	}
+	cmd.Flags().String(string(flagBase), "", "the common ancestor of the files to merge (required)")
+	cmd.Flags().String(string(flagOut), "", "the type of the files: cue or yaml")
+	cmd.Flags().StringP(string(flagOutFile), "o", "", "file to write the result to instead of stdout")
+	cmd.MarkFlagRequired(string(flagBase))
+	return cmd
+}
+
+func runMerge(cmd *Command, args []string) error {
+	names := []string{flagBase.String(cmd), args[0], args[1]}
+	var data [3][]byte
+	for i, name := range names {
+		b, err := os.ReadFile(name)
+		if err != nil {
+			return err
+		}
+		data[i] = b
+	}
+
+	out := flagOut.String(cmd)
+	if out == "" {
+		switch filepath.Ext(args[0]) {
+		case ".yaml", ".yml":
+			out = "yaml"
+		default:
+			out = "cue"
+		}
+	}
+	var result *merge.Result
+	switch out {
+	case "cue":
+		files := make([]*ast.File, 3)
+		for i, name := range names {
+			f, err := parser.ParseFile(name, data[i], parser.ParseComments)
+			if err != nil {
+				return err
+			}
+			files[i] = f
+		}
+		r, err := merge.Files(files[0], files[1], files[2], nil)
+		if err != nil {
+			return err
+		}
+		result = r
+	case "yaml":
+		r, err := merge.YAML(data[0], data[1], data[2], nil)
+		if err != nil {
+			return err
+		}
+		result = r
+	default:
+		return fmt.Errorf("unknown --out %q; must be cue or yaml", out)
+	}
+
+	if dst := flagOutFile.String(cmd); dst != "" && dst != "-" {
+		if err := os.WriteFile(dst, result.Data, 0o666); err != nil {
+			return err
+		}
+	} else if _, err := cmd.OutOrStdout().Write(result.Data); err != nil {
+		return err
+	}
+	if len(result.Conflicts) == 0 {
+		return nil
+	}
+	w := cmd.Stderr()
+	for _, c := range result.Conflicts {
+		path := c.Path
+		if path == "" {
+			path = "top level"
+		}
+		fmt.Fprintf(w, "conflict: %s\n", path)
+	}
+	return ErrPrintedError
+}
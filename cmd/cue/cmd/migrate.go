@@ -0,0 +1,201 @@
+// Copyright 2025 The CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+
+	"cuelang.org/go/cue"
+	cuejson "cuelang.org/go/encoding/json"
+	"cuelang.org/go/encoding/yaml"
+	"cuelang.org/go/tools/migrate"
+)
+
+func newMigrateCmd(c *Command) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "migrate -d schema [packages] data.yaml ...",
+		Short: "upgrade data files to the latest version of a schema",
+		Long: `
+migrate upgrades JSON and YAML data files to the latest version of a
+versioned schema, which is selected from the given CUE packages with -d.
+
+A versioned schema has a field for each version of the schema, named v1,
+v2, and so on. Each version declares how to migrate data from the version
+before it in a field named from_ followed by the name of that version. Its
+"in" field receives the data, and its "out" field produces the data for
+the new version:
+
+	#Config: {
+		v1: {
+			name: string
+			port: int
+		}
+		v2: {
+			name: string
+			server: port: int
+
+			from_v1: {
+				in: v1
+				out: {
+					name: in.name
+					server: port: in.port
+				}
+			}
+		}
+	}
+
+The version of each data file is the latest version it is valid for,
+unless it is given with --from. The migrations from that version to the
+latest one are applied in turn, and the file is rewritten in place, keeping
+the formatting and comments of YAML files. Use --dry-run to only report
+what would change.
+
+Fields whose values have no effect on the output of a migration are
+reported as unmapped, as the data they hold is lost.
+
+For example:
+
+	$ cue migrate -d '#Config' ./schema config.yaml
+	config.yaml: migrated from v1 to v2
+`[1:],
+		RunE: mkRunE(c, runMigrate),
+	}
+	cmd.Flags().StringP(string(flagSchema), "d", "", "expression to select the versioned schema (required)")
+	cmd.Flags().String(string(flagFrom), "", "version of the data files, instead of detecting it")
+	cmd.Flags().BoolP(string(flagDryRun), "n", false, "report what would change without writing any files")
+	cmd.MarkFlagRequired(string(flagSchema))
+	return cmd
+}
+
+func runMigrate(cmd *Command, args []string) error {
+	var pkgArgs, dataFiles []string
+	for _, arg := range args {
+		switch filepath.Ext(arg) {
+		case ".json", ".yaml", ".yml":
+			dataFiles = append(dataFiles, arg)
+		default:
+			pkgArgs = append(pkgArgs, arg)
+		}
+	}
+	if len(dataFiles) == 0 {
+		return fmt.Errorf("no JSON or YAML data files to migrate")
+	}
+	pkg, err := loadSinglePackage(cmd, pkgArgs)
+	if err != nil {
+		return err
+	}
+	v, err := evalServeExpr(cmd.ctx, pkg, "--schema", flagSchema.String(cmd))
+	if err != nil {
+		return err
+	}
+	schema, err := migrate.NewSchema(v)
+	if err != nil {
+		return err
+	}
+
+	w := cmd.OutOrStdout()
+	for _, file := range dataFiles {
+		src, err := os.ReadFile(file)
+		if err != nil {
+			return err
+		}
+		data, err := decodeMigrateData(cmd.ctx, file, src)
+		if err != nil {
+			return err
+		}
+		r, err := schema.Migrate(data, flagFrom.String(cmd))
+		if err != nil {
+			return fmt.Errorf("%s: %w", file, err)
+		}
+		if r.From == r.To {
+			fmt.Fprintf(w, "%s: already at %s\n", file, r.To)
+			continue
+		}
+		fmt.Fprintf(w, "%s: migrated from %s to %s\n", file, r.From, r.To)
+		for _, u := range r.Unmapped {
+			fmt.Fprintf(w, "%s: unmapped %s field %s\n", file, u.Version, u.Path)
+		}
+		if flagDryRun.Bool(cmd) {
+			continue
+		}
+		b, err := encodeMigrateData(file, r.Value, src)
+		if err != nil {
+			return fmt.Errorf("%s: %w", file, err)
+		}
+		if err := os.WriteFile(file, b, 0o666); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// loadSinglePackage loads the single package given by args.
+func loadSinglePackage(cmd *Command, args []string) (cue.Value, error) {
+	cfg, err := defaultConfig()
+	if err != nil {
+		return cue.Value{}, err
+	}
+	binsts := loadFromArgs(args, cfg.loadCfg)
+	if len(binsts) != 1 {
+		return cue.Value{}, fmt.Errorf("%s requires a single package", cmd.Name())
+	}
+	if err := binsts[0].Err; err != nil {
+		return cue.Value{}, err
+	}
+	insts, err := buildInstances(cmd, binsts, false)
+	if err != nil {
+		return cue.Value{}, err
+	}
+	return insts[0].Value(), nil
+}
+
+func decodeMigrateData(ctx *cue.Context, file string, src []byte) (cue.Value, error) {
+	if filepath.Ext(file) == ".json" {
+		expr, err := cuejson.Extract(file, src)
+		if err != nil {
+			return cue.Value{}, err
+		}
+		v := ctx.BuildExpr(expr)
+		return v, v.Err()
+	}
+	f, err := yaml.Extract(file, src)
+	if err != nil {
+		return cue.Value{}, err
+	}
+	v := ctx.BuildFile(f)
+	return v, v.Err()
+}
+
+func encodeMigrateData(file string, v cue.Value, original []byte) ([]byte, error) {
+	if filepath.Ext(file) != ".json" {
+		return yaml.EncodeLike(v, original)
+	}
+	b, err := v.MarshalJSON()
+	if err != nil {
+		return nil, err
+	}
+	var buf bytes.Buffer
+	if err := json.Indent(&buf, b, "", "    "); err != nil {
+		return nil, err
+	}
+	buf.WriteByte('\n')
+	return buf.Bytes(), nil
+}
@@ -114,9 +114,13 @@ func readModuleFile() (string, *modfile.File, []byte, error) {
 }
 
 func findModuleRoot() (string, error) {
+	return findModuleRootFrom(rootWorkingDir())
+}
+
+// findModuleRootFrom returns the root of the module containing dir.
+func findModuleRootFrom(dir string) (string, error) {
 	// TODO this logic is duplicated in multiple places. We should
 	// consider deduplicating it.
-	dir := rootWorkingDir()
 	for {
 		if _, err := os.Stat(filepath.Join(dir, "cue.mod")); err == nil {
 			return dir, nil
@@ -27,6 +27,7 @@ import (
 	"cuelang.org/go/cue/load"
 	"cuelang.org/go/cue/parser"
 	"cuelang.org/go/internal/mod/semver"
+	"cuelang.org/go/internal/source"
 	"cuelang.org/go/mod/modfile"
 	"cuelang.org/go/mod/module"
 	"github.com/spf13/cobra"
@@ -57,6 +58,24 @@ type modRenamer struct {
 	newModuleQualifier string
 }
 
+// newModRenamer returns a renamer of imports of packages in the module
+// oldModule to imports of packages in newModule.
+func newModRenamer(oldModule, newModule string) (*modRenamer, error) {
+	var mr modRenamer
+	var err error
+	mr.oldModulePath, mr.oldModuleMajor, err = splitModulePath(oldModule)
+	if err != nil {
+		return nil, err
+	}
+	mr.oldModuleQualifier = ast.ParseImportPath(mr.oldModulePath).Qualifier
+	mr.newModulePath, mr.newModuleMajor, err = splitModulePath(newModule)
+	if err != nil {
+		return nil, err
+	}
+	mr.newModuleQualifier = ast.ParseImportPath(mr.newModulePath).Qualifier
+	return &mr, nil
+}
+
 func runModRename(cmd *Command, args []string) error {
 	modFilePath, mf, _, err := readModuleFile()
 	if err != nil {
@@ -66,18 +85,11 @@ func runModRename(cmd *Command, args []string) error {
 		// Nothing to do
 		return nil
 	}
-	var mr modRenamer
-	mr.oldModulePath, mr.oldModuleMajor, err = splitModulePath(mf.Module)
+	mr, err := newModRenamer(mf.Module, args[0])
 	if err != nil {
 		return err
 	}
-	mr.oldModuleQualifier = ast.ParseImportPath(mr.oldModulePath).Qualifier
 	mf.Module = args[0]
-	mr.newModulePath, mr.newModuleMajor, err = splitModulePath(mf.Module)
-	if err != nil {
-		return err
-	}
-	mr.newModuleQualifier = ast.ParseImportPath(mr.newModulePath).Qualifier
 
 	// TODO if we're renaming to a module that we currently depend on,
 	// perhaps we should detect that and give an error.
@@ -93,6 +105,16 @@ func runModRename(cmd *Command, args []string) error {
 	if err != nil {
 		return err
 	}
+	return rewriteModuleImports(modRoot, mr.rewriteImport, func(filename string, _, data []byte) error {
+		return os.WriteFile(filename, data, 0o666)
+	})
+}
+
+// rewriteModuleImports calls rewrite for each import spec in every CUE
+// file of the module rooted at modRoot, including test and tool files,
+// and calls write with the old and new contents of each file for which
+// rewrite reported a change.
+func rewriteModuleImports(modRoot string, rewrite func(spec *ast.ImportSpec) (bool, error), write func(filename string, oldData, newData []byte) error) error {
 	binst := load.Instances([]string{"./..."}, &load.Config{
 		Dir:         modRoot,
 		ModuleRoot:  modRoot,
@@ -120,7 +142,7 @@ func runModRename(cmd *Command, args []string) error {
 				// Avoid processing files which are inherited from parent directories.
 				continue
 			}
-			if err := mr.renameFile(file); err != nil {
+			if err := rewriteFileImports(file, rewrite, write); err != nil {
 				return err
 			}
 		}
@@ -128,8 +150,12 @@ func runModRename(cmd *Command, args []string) error {
 	return nil
 }
 
-func (mr *modRenamer) renameFile(file *build.File) error {
-	syntax, err := parser.ParseFile(file.Filename, file.Source, parser.ParseComments)
+func rewriteFileImports(file *build.File, rewrite func(spec *ast.ImportSpec) (bool, error), write func(filename string, oldData, newData []byte) error) error {
+	src, err := source.ReadAll(file.Filename, file.Source)
+	if err != nil {
+		return err
+	}
+	syntax, err := parser.ParseFile(file.Filename, src, parser.ParseComments)
 	if err != nil {
 		return err
 	}
@@ -138,7 +164,7 @@ func (mr *modRenamer) renameFile(file *build.File) error {
 	for _, decl := range syntax.Preamble() {
 		if decl, ok := decl.(*ast.ImportDecl); ok {
 			for _, spec := range decl.Specs {
-				ch, err := mr.rewriteImport(spec)
+				ch, err := rewrite(spec)
 				if err != nil {
 					return err
 				}
@@ -153,10 +179,7 @@ func (mr *modRenamer) renameFile(file *build.File) error {
 	if err != nil {
 		return err
 	}
-	if err := os.WriteFile(file.Filename, data, 0o666); err != nil {
-		return err
-	}
-	return nil
+	return write(file.Filename, src, data)
 }
 
 func (mr *modRenamer) rewriteImport(spec *ast.ImportSpec) (changed bool, err error) {
@@ -18,6 +18,7 @@ import (
 	"fmt"
 	"path/filepath"
 	"strconv"
+	"strings"
 
 	"cuelang.org/go/cue"
 	"cuelang.org/go/cue/ast"
@@ -48,14 +49,18 @@ func (b *buildPlan) parsePlacementFlags() error {
 	b.useContext = flagWithContext.Bool(cmd)
 
 	for _, str := range flagPath.StringArray(cmd) {
+		if b.transform != nil {
+			return fmt.Errorf("no labels may follow a value in the %s flag", flagPath)
+		}
 		l, err := parser.ParseExpr("--path", str)
 		if err != nil {
-			labels, err := parseFullPath(str)
+			labels, value, err := parseFullPath(str)
 			if err != nil {
 				return fmt.Errorf(
 					`labels must be expressions (-l foo -l 'strings.ToLower(bar)') or full paths (-l '"foo": "\(strings.ToLower(bar))":) : %v`, err)
 			}
 			b.path = append(b.path, labels...)
+			b.transform = value
 			continue
 		}
 
@@ -175,9 +180,9 @@ func placeOrphans(b *buildPlan, d *encoding.Decoder, pkg string, objs ...*ast.Fi
 
 		switch {
 		case len(b.path) > 0:
-			expr := expr
+			data := expr
 			if b.useContext {
-				expr = ast.NewStruct(
+				data = ast.NewStruct(
 					"data", expr,
 					"filename", ast.NewString(filename),
 					"index", ast.NewLit(token.INT, strconv.Itoa(i)),
@@ -185,10 +190,10 @@ func placeOrphans(b *buildPlan, d *encoding.Decoder, pkg string, objs ...*ast.Fi
 				)
 			}
 			var f *ast.File
-			if s, ok := expr.(*ast.StructLit); ok {
+			if s, ok := data.(*ast.StructLit); ok {
 				f = &ast.File{Decls: s.Elts}
 			} else {
-				f = &ast.File{Decls: []ast.Decl{&ast.EmbedDecl{Expr: expr}}}
+				f = &ast.File{Decls: []ast.Decl{&ast.EmbedDecl{Expr: data}}}
 			}
 			err := astutil.Sanitize(f)
 			if err != nil {
@@ -233,6 +238,17 @@ func placeOrphans(b *buildPlan, d *encoding.Decoder, pkg string, objs ...*ast.Fi
 			}
 
 			path = cue.MakePath(a...)
+
+			if b.transform != nil {
+				v := ctx.BuildExpr(b.transform,
+					cue.InferBuiltins(true),
+					cue.Scope(inst))
+				if err := v.Validate(cue.Concrete(true)); err != nil {
+					return nil, fmt.Errorf(`error evaluating value %v: %v`,
+						astinternal.DebugStr(b.transform), err)
+				}
+				expr = v.Syntax(cue.Final()).(ast.Expr)
+			}
 		}
 
 		switch d.Interpretation() {
@@ -307,21 +323,29 @@ func placeOrphans(b *buildPlan, d *encoding.Decoder, pkg string, objs ...*ast.Fi
 	return f, astutil.Sanitize(f)
 }
 
-func parseFullPath(exprs string) (p []ast.Label, err error) {
-	f, err := parser.ParseFile("--path", exprs+"_")
+// parseFullPath parses a sequence of labels, each followed by a colon.
+// If the last colon is followed by an expression, it is returned as the
+// value with which to replace the placed value.
+func parseFullPath(exprs string) (p []ast.Label, value ast.Expr, err error) {
+	src := strings.TrimSpace(exprs)
+	labelsOnly := strings.HasSuffix(src, ":")
+	if labelsOnly {
+		src += "_"
+	}
+	f, err := parser.ParseFile("--path", src)
 	if err != nil {
-		return p, fmt.Errorf("parser error in path %q: %v", exprs, err)
+		return p, nil, fmt.Errorf("parser error in path %q: %v", exprs, err)
 	}
 
 	if len(f.Decls) != 1 {
-		return p, errors.New("path flag must be a space-separated sequence of labels")
+		return p, nil, errors.New("path flag must be a space-separated sequence of labels")
 	}
 
 	for d := f.Decls[0]; ; {
 		field, ok := d.(*ast.Field)
 		if !ok {
 			// This should never happen
-			return p, errors.New("%q not a sequence of labels")
+			return p, nil, errors.New("%q not a sequence of labels")
 		}
 
 		switch x := field.Label.(type) {
@@ -332,21 +356,25 @@ func parseFullPath(exprs string) (p []ast.Label, err error) {
 			p = append(p, &ast.ParenExpr{X: x})
 
 		default:
-			return p, fmt.Errorf("unsupported label type %T", x)
+			return p, nil, fmt.Errorf("unsupported label type %T", x)
 		}
 
+		// A struct without braces continues the sequence of labels.
 		v, ok := field.Value.(*ast.StructLit)
-		if !ok {
+		if !ok || v.Lbrace.IsValid() {
+			if !labelsOnly {
+				value = field.Value
+			}
 			break
 		}
 
 		if len(v.Elts) != 1 {
-			return p, errors.New("path value may not contain a struct")
+			return p, nil, errors.New("path value may not contain a struct")
 		}
 
 		d = v.Elts[0]
 	}
-	return p, nil
+	return p, value, nil
 }
 
 type listIndex struct {
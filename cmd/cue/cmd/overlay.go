@@ -0,0 +1,382 @@
+// Copyright 2025 CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+
+	"cuelang.org/go/cue"
+	"cuelang.org/go/cue/build"
+	"cuelang.org/go/cue/errors"
+	"cuelang.org/go/cue/token"
+	"cuelang.org/go/internal/encoding"
+	"cuelang.org/go/internal/filetypes"
+)
+
+// overlayAttr is the name of the attribute controlling how overlay fields
+// are merged into the base.
+const overlayAttr = "overlay"
+
+// overlayPatch is the field an overlay list element uses to request a
+// patch other than a merge.
+const overlayPatch = "$patch"
+
+func newOverlayCmd(c *Command) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "overlay <base> <overlay>...",
+		Short: "compose a base package with environment overlays",
+		Long: `overlay composes a base package with one or more overlay packages
+and exports the result for each overlay.
+
+The first argument names the base package; each of the remaining
+arguments names an overlay package, typically one per environment.
+Each overlay is applied to the base independently, and the result is
+named after the directory of the overlay package.
+
+	cue overlay ./base ./overlays/dev ./overlays/prod
+
+By default the results are written as a single struct with a field per
+environment. With --outdir, each result is written to its own file
+named after the environment, with an extension matching --out.
+
+Unlike unification, overlays may change values set by the base. Fields
+are merged as follows:
+
+  - structs are merged field by field, adding fields only present
+    in the overlay;
+  - a concrete base value is replaced by the overlay value;
+  - any other base value is unified with the overlay value, so that
+    constraints declared in the base still apply;
+  - lists are replaced, unless a key is declared (see below).
+
+The @overlay attribute changes how a field is merged:
+
+	@overlay(replace)   replace the base value entirely
+	@overlay(delete)    remove the field from the base
+	@overlay(key=name)  merge lists of structs by their name field
+
+A list key may be declared on the base or the overlay field. Overlay
+elements are merged into the base element with the same key, and
+elements with a new key are appended. An overlay element with the
+field $patch: "delete" removes the base element with the same key.
+
+	// base
+	containers: [{name: "app", image: "app:v1"}] @overlay(key=name)
+
+	// overlay
+	containers: [{name: "app", image: "app:v2"}, {name: "proxy"}]
+
+References within the base are resolved before the overlay is
+applied: an overlay changing a field does not update base values
+computed from it.
+`,
+		RunE: mkRunE(c, runOverlay),
+	}
+
+	addOutFlags(cmd.Flags(), true)
+	addInjectionFlags(cmd.Flags(), false, false)
+
+	cmd.Flags().String(string(flagOutDir), "",
+		"write each environment to a separate file in this directory")
+
+	return cmd
+}
+
+var overlayExts = map[build.Encoding]string{
+	build.CUE:  ".cue",
+	build.JSON: ".json",
+	build.YAML: ".yaml",
+	build.TOML: ".toml",
+}
+
+func runOverlay(cmd *Command, args []string) error {
+	if len(args) < 2 {
+		return errors.Newf(token.NoPos, "overlay requires a base package and at least one overlay")
+	}
+	outDir := flagOutDir.String(cmd)
+	if outDir != "" && outFileFlag(cmd) != "" {
+		return errors.Newf(token.NoPos, "cannot combine --%s and --%s", flagOutDir, flagOutFile)
+	}
+
+	cfg, err := defaultConfig()
+	if err != nil {
+		return err
+	}
+	setTags(cfg.loadCfg, cmd.Flags())
+	binsts := loadFromArgs(args, cfg.loadCfg)
+	if err := injectFiles(cmd, cmd.Flags(), binsts); err != nil {
+		return err
+	}
+	if len(binsts) < 2 {
+		return errors.Newf(token.NoPos, "overlay requires a base package and at least one overlay")
+	}
+	for _, binst := range binsts {
+		if len(binst.OrphanedFiles) > 0 {
+			return errors.Newf(token.NoPos, "overlay arguments must be CUE packages")
+		}
+	}
+	insts, err := buildInstances(cmd, binsts, false)
+	if err != nil {
+		return err
+	}
+
+	outFile := outFileFlag(cmd)
+	if outFile == "" {
+		outFile = "-"
+	}
+	if out := flagOut.String(cmd); out != "" {
+		outFile = out + ":" + outFile
+	}
+	f, err := filetypes.ParseFile(outFile, filetypes.Export)
+	if err != nil {
+		return err
+	}
+	encConfig := &encoding.Config{
+		Mode:   filetypes.Export,
+		Stdout: cmd.OutOrStdout(),
+		Force:  flagForce.Bool(cmd),
+	}
+
+	base := insts[0].Value()
+	envs := cmd.ctx.CompileString("{}")
+	var names []string
+	for i, inst := range insts[1:] {
+		name := filepath.Base(binsts[i+1].Dir)
+		if envs.LookupPath(cue.MakePath(cue.Str(name))).Exists() {
+			return errors.Newf(token.NoPos, "duplicate environment %q", name)
+		}
+		v, err := applyOverlay(base, inst.Value())
+		if err != nil {
+			return err
+		}
+		envs = envs.FillPath(cue.MakePath(cue.Str(name)), v)
+		names = append(names, name)
+	}
+
+	if outDir == "" {
+		return writeOverlay(cmd, f, encConfig, envs)
+	}
+
+	ext, ok := overlayExts[f.Encoding]
+	if !ok {
+		return errors.Newf(token.NoPos, "--%s does not support %s output", flagOutDir, f.Encoding)
+	}
+	if err := os.MkdirAll(outDir, 0o777); err != nil {
+		return err
+	}
+	for _, name := range names {
+		f := *f
+		f.Filename = filepath.Join(outDir, name+ext)
+		v := envs.LookupPath(cue.MakePath(cue.Str(name)))
+		if err := writeOverlay(cmd, &f, encConfig, v); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func writeOverlay(cmd *Command, f *build.File, cfg *encoding.Config, v cue.Value) error {
+	enc, err := encoding.NewEncoder(cmd.ctx, f, cfg)
+	if err != nil {
+		return err
+	}
+	if err := enc.Encode(v); err != nil {
+		return err
+	}
+	return enc.Close()
+}
+
+// applyOverlay returns the result of applying overlay o to base value b.
+func applyOverlay(b, o cue.Value) (cue.Value, error) {
+	if !o.Exists() {
+		return b, nil
+	}
+	attr := o.Attribute(overlayAttr)
+	if attr.Err() == nil {
+		if ok, err := attr.Flag(0, "replace"); err != nil {
+			return cue.Value{}, err
+		} else if ok {
+			return o, nil
+		}
+	}
+
+	switch {
+	case b.IncompleteKind() == cue.StructKind && o.IncompleteKind() == cue.StructKind:
+		return overlayStruct(b, o)
+
+	case b.IncompleteKind() == cue.ListKind && o.IncompleteKind() == cue.ListKind:
+		key, err := overlayKey(b, o)
+		if err != nil {
+			return cue.Value{}, err
+		}
+		if key != "" {
+			return overlayList(b, o, key)
+		}
+		return o, nil
+	}
+
+	u := b.Unify(o)
+	if u.Err() != nil && b.IsConcrete() {
+		return o, nil
+	}
+	return u, nil
+}
+
+func overlayStruct(b, o cue.Value) (cue.Value, error) {
+	result := b.Context().CompileString("{}")
+	seen := map[string]bool{}
+
+	iter, err := b.Fields()
+	if err != nil {
+		return cue.Value{}, err
+	}
+	for iter.Next() {
+		sel := iter.Selector()
+		seen[sel.String()] = true
+		path := cue.MakePath(sel)
+		ov := o.LookupPath(path)
+		if overlayDelete(ov) {
+			continue
+		}
+		v, err := applyOverlay(iter.Value(), ov)
+		if err != nil {
+			return cue.Value{}, err
+		}
+		result = result.FillPath(path, v)
+	}
+
+	iter, err = o.Fields()
+	if err != nil {
+		return cue.Value{}, err
+	}
+	for iter.Next() {
+		sel := iter.Selector()
+		if seen[sel.String()] || overlayDelete(iter.Value()) {
+			continue
+		}
+		result = result.FillPath(cue.MakePath(sel), iter.Value())
+	}
+	return result, nil
+}
+
+// overlayDelete reports whether v is an overlay field marked for deletion.
+func overlayDelete(v cue.Value) bool {
+	if !v.Exists() {
+		return false
+	}
+	attr := v.Attribute(overlayAttr)
+	if attr.Err() != nil {
+		return false
+	}
+	ok, _ := attr.Flag(0, "delete")
+	return ok
+}
+
+// overlayKey returns the list key declared for a list field, preferring the
+// one declared in the overlay.
+func overlayKey(b, o cue.Value) (string, error) {
+	for _, v := range []cue.Value{o, b} {
+		attr := v.Attribute(overlayAttr)
+		if attr.Err() != nil {
+			continue
+		}
+		key, ok, err := attr.Lookup(0, "key")
+		if err != nil {
+			return "", err
+		}
+		if ok {
+			return key, nil
+		}
+	}
+	return "", nil
+}
+
+func overlayList(b, o cue.Value, key string) (cue.Value, error) {
+	keyPath := cue.ParsePath(key)
+	if err := keyPath.Err(); err != nil {
+		return cue.Value{}, err
+	}
+	keyOf := func(v cue.Value) (string, bool) {
+		k := v.LookupPath(keyPath)
+		if !k.IsConcrete() {
+			return "", false
+		}
+		return fmt.Sprint(k), true
+	}
+
+	var elems []cue.Value
+	index := map[string]int{}
+	iter, err := b.List()
+	if err != nil {
+		return cue.Value{}, err
+	}
+	for iter.Next() {
+		v := iter.Value()
+		if k, ok := keyOf(v); ok {
+			index[k] = len(elems)
+		}
+		elems = append(elems, v)
+	}
+
+	deleted := make([]bool, len(elems))
+	iter, err = o.List()
+	if err != nil {
+		return cue.Value{}, err
+	}
+	for iter.Next() {
+		v := iter.Value()
+		k, ok := keyOf(v)
+		if !ok {
+			return cue.Value{}, errors.Newf(v.Pos(),
+				"overlay list element has no concrete value for key %q", key)
+		}
+		i, found := index[k]
+
+		if p := v.LookupPath(cue.MakePath(cue.Str(overlayPatch))); p.Exists() {
+			s, err := p.String()
+			if err != nil || s != "delete" {
+				return cue.Value{}, errors.Newf(p.Pos(),
+					`unsupported %s value; only "delete" is supported`, overlayPatch)
+			}
+			if found {
+				deleted[i] = true
+			}
+			continue
+		}
+
+		if !found {
+			index[k] = len(elems)
+			elems = append(elems, v)
+			deleted = append(deleted, false)
+			continue
+		}
+		elems[i], err = applyOverlay(elems[i], v)
+		if err != nil {
+			return cue.Value{}, err
+		}
+	}
+
+	var list []cue.Value
+	for i, v := range elems {
+		if !deleted[i] {
+			list = append(list, v)
+		}
+	}
+	return b.Context().NewList(list...), nil
+}
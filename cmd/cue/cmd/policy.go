@@ -0,0 +1,213 @@
+// Copyright 2025 CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"fmt"
+
+	"cuelang.org/go/cue"
+	"cuelang.org/go/cue/errors"
+	"cuelang.org/go/cue/token"
+)
+
+// Fields of a policy pack and its rules.
+var (
+	policyRulesPath   = cue.MakePath(cue.Str("rules"))
+	policyInputPath   = cue.MakePath(cue.Str("input"))
+	policyCheckPath   = cue.MakePath(cue.Str("check"))
+	policyMessagePath = cue.MakePath(cue.Str("message"))
+	policyHintPath    = cue.MakePath(cue.Str("hint"))
+)
+
+// policyRule is a named rule declared by a policy pack.
+type policyRule struct {
+	diagnosticRule
+	severity string
+	pack     cue.Value // the policy pack declaring the rule
+	path     cue.Path  // the path of the rule within pack
+}
+
+// policyRuleSpec defines the metadata of a rule, as decoded from CUE.
+type policyRuleSpec struct {
+	Severity    string `json:"severity"`
+	Description string `json:"description"`
+	URL         string `json:"url"`
+}
+
+// loadPolicies loads the rules of the policy packs named by the
+// --policy flags, in the order in which they are declared, and builds
+// them within ctx.
+func loadPolicies(cmd *Command, ctx *cue.Context) ([]policyRule, error) {
+	args := flagPolicy.StringArray(cmd)
+	if len(args) == 0 {
+		return nil, nil
+	}
+	cfg, err := defaultConfig()
+	if err != nil {
+		return nil, err
+	}
+	var rules []policyRule
+	seen := map[string]string{}
+	for _, binst := range loadFromArgs(args, cfg.loadCfg) {
+		if err := binst.Err; err != nil {
+			return nil, err
+		}
+		v := ctx.BuildInstance(binst)
+		if err := v.Err(); err != nil {
+			return nil, err
+		}
+		iter, err := v.LookupPath(policyRulesPath).Fields()
+		if err != nil {
+			return nil, errors.Wrapf(err, v.Pos(), "invalid policy pack %s", binst.ImportPath)
+		}
+		for iter.Next() {
+			id := iter.Selector().Unquoted()
+			if pkg, ok := seen[id]; ok {
+				return nil, fmt.Errorf("policy rule %q declared by both %s and %s", id, pkg, binst.ImportPath)
+			}
+			seen[id] = binst.ImportPath
+			r, err := newPolicyRule(v, id, iter.Value())
+			if err != nil {
+				return nil, err
+			}
+			rules = append(rules, r)
+		}
+	}
+	return rules, nil
+}
+
+func newPolicyRule(pack cue.Value, id string, v cue.Value) (policyRule, error) {
+	var spec policyRuleSpec
+	for _, f := range []struct {
+		name string
+		dst  *string
+	}{
+		{"severity", &spec.Severity},
+		{"description", &spec.Description},
+		{"url", &spec.URL},
+	} {
+		fv := v.LookupPath(cue.MakePath(cue.Str(f.name)))
+		if !fv.Exists() {
+			continue
+		}
+		if err := fv.Decode(f.dst); err != nil {
+			return policyRule{}, errors.Wrapf(err, fv.Pos(), "invalid policy rule %s", id)
+		}
+	}
+	switch spec.Severity {
+	case "":
+		spec.Severity = severityError
+	case severityError, severityWarning:
+	default:
+		return policyRule{}, errors.Newf(v.Pos(), "invalid policy rule %s: severity must be %q or %q", id, severityError, severityWarning)
+	}
+	if !v.LookupPath(policyCheckPath).Exists() {
+		return policyRule{}, errors.Newf(v.Pos(), "invalid policy rule %s: missing check field", id)
+	}
+	if spec.Description == "" {
+		spec.Description = fmt.Sprintf("policy rule %s", id)
+	}
+	return policyRule{
+		diagnosticRule: diagnosticRule{
+			id:          id,
+			description: spec.Description,
+			url:         spec.URL,
+		},
+		severity: spec.Severity,
+		pack:     pack,
+		path:     v.Path(),
+	}, nil
+}
+
+// checkPolicies checks v against each of the rules, reporting any
+// violations to r according to the severity of their rule.
+func checkPolicies(v cue.Value, rules []policyRule, r *diagReporter) {
+	if len(rules) == 0 {
+		return
+	}
+	// Only require the checks to be concrete if v is, so that policies
+	// can be applied to schemas as well as data.
+	concrete := v.Validate(cue.Concrete(true)) == nil
+	for _, rule := range rules {
+		errs := rule.check(v, concrete)
+		if errs == nil {
+			continue
+		}
+		if rule.severity == severityWarning {
+			r.warn(errs)
+		} else {
+			r.report(errs)
+		}
+	}
+}
+
+// check returns a violation of the rule for each error found when
+// unifying v with the rule's check. The input field of the policy pack
+// is set to v first, so that both the check and the message can depend
+// on it, for example to only apply to certain kinds of values or to name
+// the offending value.
+func (rule *policyRule) check(v cue.Value, concrete bool) errors.Error {
+	rv := rule.pack.FillPath(policyInputPath, v).LookupPath(rule.path)
+	err := v.Unify(rv.LookupPath(policyCheckPath)).Validate(cue.Concrete(concrete))
+	if err == nil {
+		return nil
+	}
+	msg, merr := rv.LookupPath(policyMessagePath).String()
+	var hints []errors.Hint
+	if h, _ := rv.LookupPath(policyHintPath).String(); h != "" {
+		hints = append(hints, errors.Hint{Message: h})
+	}
+	var violations errors.Error
+	for _, e := range errors.Errors(err) {
+		m := msg
+		if merr != nil || m == "" {
+			m = diagnosticMessage(e)
+		}
+		violations = errors.Append(violations, &policyViolation{
+			err:     e,
+			rule:    rule,
+			message: m,
+			hints:   hints,
+		})
+	}
+	return violations
+}
+
+// policyViolation is an error reported when a value does not satisfy
+// a policy rule. It takes its path and positions from the underlying
+// validation error.
+type policyViolation struct {
+	err     errors.Error
+	rule    *policyRule
+	message string
+	hints   []errors.Hint
+}
+
+func (v *policyViolation) Position() token.Pos         { return v.err.Position() }
+func (v *policyViolation) InputPositions() []token.Pos { return v.err.InputPositions() }
+func (v *policyViolation) Path() []string              { return v.err.Path() }
+func (v *policyViolation) Hints() []errors.Hint        { return v.hints }
+
+func (v *policyViolation) Msg() (string, []interface{}) {
+	if v.rule.url != "" {
+		return "%s (policy %s; see %s)", []interface{}{v.message, v.rule.id, v.rule.url}
+	}
+	return "%s (policy %s)", []interface{}{v.message, v.rule.id}
+}
+
+func (v *policyViolation) Error() string {
+	format, args := v.Msg()
+	return fmt.Sprintf(format, args...)
+}
@@ -0,0 +1,421 @@
+// Copyright 2025 CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"cuelang.org/go/cue"
+	"cuelang.org/go/cue/ast"
+	"cuelang.org/go/cue/parser"
+	"cuelang.org/go/internal/encoding"
+	"cuelang.org/go/internal/filetypes"
+)
+
+func newQueryCmd(c *Command) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "query [flags] <query> [inputs]",
+		Short: "extract parts of a configuration with a query",
+		Long: `query evaluates the inputs, like export, and outputs the parts of the
+result selected by a query.
+
+A query is a path of fields, such as services.web.port, extended with
+steps which select several values at once:
+
+	a, "a-b", #A   the field with this name
+	[n]            the element of a list at index n, counting from the
+	               end of the list if n is negative
+	* or [*]       all the fields of a struct or all the elements of a
+	               list
+	[?cond]        the fields or elements for which the CUE expression
+	               cond evaluates to true; cond is evaluated as if it
+	               were within the field or element, and it refers to
+	               the field or element itself
+
+The steps following a step which selects several values are applied to
+each of them, skipping those to which they do not apply, and the
+results are output as a list. An empty query, or ".", selects the whole
+value. For instance, given
+
+	services: {
+		web: {port: 8080, public: true}
+		db:  {port: 5432, public: false}
+	}
+
+the query services.*.port results in [8080, 5432], and the query
+services[?public && port > 1024] results in [{port: 8080, public: true}].
+The filter tags[?it =~ "^prod"] selects the strings of a list which
+start with prod.
+
+The query is applied to each value exported, such as the value of each
+expression given with -e. The output is written in any encoding
+supported by export, selected with --out or --outfile:
+
+	cue query 'services[?public].port' ./config
+	cue query --out yaml 'deployments[0].spec' k8s.cue
+`,
+		Args: cobra.MinimumNArgs(1),
+		RunE: mkRunE(c, runQuery),
+	}
+
+	addOutFlags(cmd.Flags(), true)
+	addOrphanFlags(cmd.Flags())
+	addInjectionFlags(cmd.Flags(), false, false)
+
+	cmd.Flags().Bool(string(flagEscape), false, "use HTML escaping")
+	cmd.Flags().Bool(string(flagFidelity), false,
+		"keep the comments, key order, and styles of an existing YAML or JSON output file")
+	cmd.Flags().Bool(string(flagCanonical), false,
+		"write a canonical form of the output for hashing and signing (json or cue only)")
+	cmd.Flags().StringArrayP(string(flagExpression), "e", nil, "query the value of this expression")
+	return cmd
+}
+
+func runQuery(cmd *Command, args []string) error {
+	q, err := parseQuery(args[0])
+	if err != nil {
+		return err
+	}
+	b, err := parseArgs(cmd, args[1:], &config{mode: filetypes.Export})
+	if err != nil {
+		return err
+	}
+	enc, err := encoding.NewEncoder(cmd.ctx, b.outFile, b.encConfig)
+	if err != nil {
+		return err
+	}
+	iter := b.instances()
+	defer iter.close()
+	for iter.scan() {
+		v, err := q.apply(iter.value())
+		if err != nil {
+			return err
+		}
+		if err := enc.Encode(v); err != nil {
+			return b.traceErrors(v, err)
+		}
+	}
+	if err := iter.err(); err != nil {
+		return err
+	}
+	return enc.Close()
+}
+
+// A query selects parts of a value, as described in the help of cue query.
+type query struct {
+	src   string
+	steps []queryStep
+}
+
+type queryStepKind int
+
+const (
+	queryField  queryStepKind = iota // the field sel
+	queryIndex                       // the element at index
+	queryAll                         // all fields or elements
+	queryFilter                      // the fields or elements matching filter
+)
+
+type queryStep struct {
+	kind   queryStepKind
+	sel    cue.Selector
+	index  int
+	filter ast.Expr // a struct with the result of the filter
+
+	// end is the offset in the query just after the step.
+	end int
+}
+
+// parseQuery parses a query such as services[?public].port.
+func parseQuery(src string) (*query, error) {
+	q := &query{src: src}
+	s := strings.TrimSpace(src)
+	offset := len(src) - len(strings.TrimLeft(src, " \t"))
+	i := 0
+	if strings.HasPrefix(s, ".") {
+		i++
+	}
+	for first := true; i < len(s); first = false {
+		var (
+			step queryStep
+			n    int
+			err  error
+		)
+		switch {
+		case s[i] == '[':
+			step, n, err = parseQueryBracket(s[i:])
+		case first:
+			step, n, err = parseQueryName(s[i:])
+		case s[i] == '.':
+			i++
+			step, n, err = parseQueryName(s[i:])
+		default:
+			err = fmt.Errorf("unexpected %q", s[i])
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid query %q: %v at offset %d", src, err, offset+i)
+		}
+		i += n
+		step.end = offset + i
+		q.steps = append(q.steps, step)
+	}
+	return q, nil
+}
+
+// parseQueryName parses a step selecting fields by name, returning the
+// number of bytes it takes.
+func parseQueryName(s string) (queryStep, int, error) {
+	if strings.HasPrefix(s, "*") {
+		return queryStep{kind: queryAll}, 1, nil
+	}
+	var name string
+	n := 0
+	if strings.HasPrefix(s, `"`) {
+		var err error
+		n = quotedLen(s)
+		if name, err = strconv.Unquote(s[:n]); err != nil {
+			return queryStep{}, 0, fmt.Errorf("invalid string %s", s[:n])
+		}
+		return queryStep{kind: queryField, sel: cue.Str(name)}, n, nil
+	}
+	for n < len(s) && isQueryIdent(s[n]) {
+		n++
+	}
+	if n == 0 {
+		return queryStep{}, 0, errors.New("expected a field name")
+	}
+	name = s[:n]
+	p := cue.ParsePath(name)
+	if p.Err() != nil || len(p.Selectors()) != 1 {
+		return queryStep{}, 0, fmt.Errorf("invalid field name %q", name)
+	}
+	sel := p.Selectors()[0]
+	if t := sel.LabelType(); t != cue.StringLabel && t != cue.DefinitionLabel {
+		return queryStep{}, 0, fmt.Errorf("invalid field name %q", name)
+	}
+	return queryStep{kind: queryField, sel: sel}, n, nil
+}
+
+func isQueryIdent(c byte) bool {
+	return c == '_' || c == '#' || c == '$' ||
+		'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z' || '0' <= c && c <= '9'
+}
+
+// quotedLen returns the length of the double-quoted string s starts with,
+// or len(s) if it is not terminated.
+func quotedLen(s string) int {
+	for i := 1; i < len(s); i++ {
+		switch s[i] {
+		case '\\':
+			i++
+		case '"':
+			return i + 1
+		}
+	}
+	return len(s)
+}
+
+// parseQueryBracket parses a step enclosed in brackets, returning the
+// number of bytes it takes.
+func parseQueryBracket(s string) (queryStep, int, error) {
+	depth := 0
+	end := -1
+	for i := 0; i < len(s) && end < 0; i++ {
+		switch s[i] {
+		case '"':
+			i += quotedLen(s[i:]) - 1
+		case '[', '(', '{':
+			depth++
+		case ']', ')', '}':
+			if depth--; depth == 0 {
+				end = i
+			}
+		}
+	}
+	if end < 0 {
+		return queryStep{}, 0, errors.New("missing ]")
+	}
+	inner := strings.TrimSpace(s[1:end])
+	n := end + 1
+	switch {
+	case inner == "*":
+		return queryStep{kind: queryAll}, n, nil
+	case strings.HasPrefix(inner, "?"):
+		cond := inner[1:]
+		if _, err := parser.ParseExpr("query filter", cond); err != nil {
+			return queryStep{}, 0, err
+		}
+		// The filter is evaluated as a field of a struct declaring it,
+		// such that it refers to the field or element being filtered.
+		expr, err := parser.ParseExpr("query filter",
+			fmt.Sprintf("{it: _, %q: (%s)}", queryResultLabel, cond))
+		if err != nil {
+			return queryStep{}, 0, err
+		}
+		return queryStep{kind: queryFilter, filter: expr}, n, nil
+	case strings.HasPrefix(inner, `"`):
+		step, m, err := parseQueryName(inner)
+		if err == nil && m != len(inner) {
+			err = fmt.Errorf("unexpected %q", inner[m:])
+		}
+		return step, n, err
+	}
+	index, err := strconv.Atoi(inner)
+	if err != nil {
+		return queryStep{}, 0, fmt.Errorf("invalid index %q", inner)
+	}
+	return queryStep{kind: queryIndex, index: index}, n, nil
+}
+
+// apply returns the parts of v selected by the query. If a step selects
+// several values, these are returned as a list.
+func (q *query) apply(v cue.Value) (cue.Value, error) {
+	values := []cue.Value{v}
+	several := false
+	for _, step := range q.steps {
+		var next []cue.Value
+		for _, v := range values {
+			selected, err := step.apply(v)
+			if err != nil {
+				// Filters which do not evaluate to a boolean are
+				// reported even when skipping the values to which a
+				// step does not apply.
+				var filterErr *queryFilterError
+				if several && !errors.As(err, &filterErr) {
+					continue
+				}
+				return cue.Value{}, fmt.Errorf("query %s: %v", q.src[:step.end], err)
+			}
+			next = append(next, selected...)
+		}
+		values = next
+		several = several || step.kind == queryAll || step.kind == queryFilter
+	}
+	if several {
+		return v.Context().NewList(values...), nil
+	}
+	return values[0], nil
+}
+
+func (step *queryStep) apply(v cue.Value) ([]cue.Value, error) {
+	switch step.kind {
+	case queryField:
+		w := v.LookupPath(cue.MakePath(step.sel))
+		if !w.Exists() {
+			return nil, fmt.Errorf("field %v not found", step.sel)
+		}
+		return []cue.Value{w}, nil
+
+	case queryIndex:
+		if v.Kind() != cue.ListKind {
+			return nil, fmt.Errorf("cannot index %v", v.Kind())
+		}
+		elems, err := queryElems(v)
+		if err != nil {
+			return nil, err
+		}
+		i := step.index
+		if i < 0 {
+			i += len(elems)
+		}
+		if i < 0 || i >= len(elems) {
+			return nil, fmt.Errorf("index %d out of range for list of length %d", step.index, len(elems))
+		}
+		return elems[i : i+1], nil
+
+	case queryAll:
+		return queryElems(v)
+
+	case queryFilter:
+		elems, err := queryElems(v)
+		if err != nil {
+			return nil, err
+		}
+		var matched []cue.Value
+		for _, elem := range elems {
+			ok, err := step.match(elem)
+			if err != nil {
+				return nil, err
+			}
+			if ok {
+				matched = append(matched, elem)
+			}
+		}
+		return matched, nil
+	}
+	panic("unreachable")
+}
+
+// queryElems returns the regular fields of a struct or the elements of
+// a list.
+func queryElems(v cue.Value) ([]cue.Value, error) {
+	var elems []cue.Value
+	switch v.Kind() {
+	case cue.StructKind:
+		iter, err := v.Fields()
+		if err != nil {
+			return nil, err
+		}
+		for iter.Next() {
+			elems = append(elems, iter.Value())
+		}
+	case cue.ListKind:
+		iter, err := v.List()
+		if err != nil {
+			return nil, err
+		}
+		for iter.Next() {
+			elems = append(elems, iter.Value())
+		}
+	default:
+		return nil, fmt.Errorf("cannot select the fields or elements of %v", v.Kind())
+	}
+	return elems, nil
+}
+
+// queryResultLabel labels the result of a filter, which the filter cannot
+// refer to as it is not an identifier.
+const queryResultLabel = "query result"
+
+// match reports whether elem matches the filter of the step. A filter
+// which cannot be evaluated, such as one referring to a field elem does
+// not have, does not match.
+func (step *queryStep) match(elem cue.Value) (bool, error) {
+	v := elem.Context().BuildExpr(step.filter, cue.Scope(elem), cue.InferBuiltins(true))
+	v = v.FillPath(cue.MakePath(cue.Str("it")), elem)
+	result := v.LookupPath(cue.MakePath(cue.Str(queryResultLabel)))
+	if result.Err() != nil || !result.IsConcrete() {
+		return false, nil
+	}
+	ok, err := result.Bool()
+	if err != nil {
+		return false, &queryFilterError{result.Kind()}
+	}
+	return ok, nil
+}
+
+// A queryFilterError reports a filter which does not evaluate to a boolean.
+type queryFilterError struct {
+	kind cue.Kind
+}
+
+func (e *queryFilterError) Error() string {
+	return fmt.Sprintf("filter must evaluate to a boolean, found %v", e.kind)
+}
@@ -32,5 +32,7 @@ to altering code within the current CUE module.
 	})
 
 	cmd.AddCommand(newRefactorImportsCmd(c))
+	cmd.AddCommand(newRefactorModuleCmd(c))
+	cmd.AddCommand(newRefactorSplitCmd(c))
 	return cmd
 }
@@ -0,0 +1,242 @@
+// Copyright 2025 CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/rogpeppe/go-internal/diff"
+	"github.com/spf13/cobra"
+
+	"cuelang.org/go/cue/ast"
+	"cuelang.org/go/cue/literal"
+	"cuelang.org/go/mod/modfile"
+	"cuelang.org/go/mod/module"
+)
+
+func newRefactorModuleCmd(c *Command) *cobra.Command {
+	cmd := &cobra.Command{
+		// Experimental so far.
+		Hidden: true,
+
+		Use:   "module <newModulePath>",
+		Short: "rename the current module and rewrite imports",
+		Long: `
+WARNING: THIS COMMAND IS EXPERIMENTAL.
+
+This command renames the current module to newModulePath, updating
+the module field in cue.mod/module.cue as well as every import of a
+package in the current module, across all packages in the module,
+including test and tool files.
+
+Dependency module paths can also be moved to a new prefix with the
+--dep flag, which takes an argument of the form oldPrefix=newPrefix
+and may be repeated. Each dependency in cue.mod/module.cue whose
+module path is underneath oldPrefix is renamed so that the prefix is
+replaced with newPrefix, and imports of packages in those modules are
+rewritten to match. The versions of the dependencies are left as
+they are.
+
+Imports are rewritten so that the identifiers they are referred to by
+within each file stay the same, adding an explicit package qualifier
+to the import path where necessary.
+
+With --dry-run, no files are written; instead, a diff is printed for
+each file that would be changed.
+
+For example:
+
+	# Move the current module to a new host.
+	cue refactor module github.com/neworg/config
+
+	# Also move dependencies which live under the old organization.
+	cue refactor module --dep github.com/oldorg=github.com/neworg github.com/neworg/config
+
+	# Show what would be changed without changing anything.
+	cue refactor module --dry-run github.com/neworg/config
+`[1:],
+		RunE: mkRunE(c, runRefactorModule),
+		Args: cobra.ExactArgs(1),
+	}
+	cmd.Flags().StringArray(string(flagDep), nil, "rewrite dependency module paths with prefix old to prefix new (old=new)")
+	cmd.Flags().Bool(string(flagDryRun), false, "only display a diff of the changes that would be made")
+
+	return cmd
+}
+
+// modulePrefixRewrite describes a rewrite of all module paths
+// underneath oldPrefix to be underneath newPrefix instead.
+type modulePrefixRewrite struct {
+	oldPrefix string
+	newPrefix string
+}
+
+func (r modulePrefixRewrite) rewrite(path string) (string, bool) {
+	if !pkgIsUnderneath(path, r.oldPrefix) {
+		return path, false
+	}
+	return r.newPrefix + strings.TrimPrefix(path, r.oldPrefix), true
+}
+
+func parseModulePrefixRewrites(args []string) ([]modulePrefixRewrite, error) {
+	var rewrites []modulePrefixRewrite
+	for _, arg := range args {
+		oldPrefix, newPrefix, ok := strings.Cut(arg, "=")
+		if !ok {
+			return nil, fmt.Errorf("invalid --%s argument %q; must be of the form old=new", flagDep, arg)
+		}
+		for _, p := range []string{oldPrefix, newPrefix} {
+			if err := module.CheckPathWithoutVersion(p); err != nil {
+				return nil, fmt.Errorf("invalid --%s argument %q: %v", flagDep, arg, err)
+			}
+		}
+		rewrites = append(rewrites, modulePrefixRewrite{oldPrefix, newPrefix})
+	}
+	return rewrites, nil
+}
+
+func runRefactorModule(cmd *Command, args []string) error {
+	dryRun := flagDryRun.Bool(cmd)
+	depRewrites, err := parseModulePrefixRewrites(flagDep.StringArray(cmd))
+	if err != nil {
+		return err
+	}
+	modFilePath, mf, modFileData, err := readModuleFile()
+	if err != nil {
+		return err
+	}
+	if mf.Module == "" {
+		return fmt.Errorf("no current module to rename")
+	}
+	mr, err := newModRenamer(mf.Module, args[0])
+	if err != nil {
+		return err
+	}
+	mf.Module = args[0]
+	renameModule := mr.oldModulePath != mr.newModulePath || mr.oldModuleMajor != mr.newModuleMajor
+	modFileChanged := renameModule
+
+	if len(depRewrites) > 0 {
+		deps := make(map[string]*modfile.Dep, len(mf.Deps))
+		for mpath, dep := range mf.Deps {
+			newPath, err := rewriteDepModulePath(mpath, depRewrites)
+			if err != nil {
+				return err
+			}
+			if _, ok := deps[newPath]; ok {
+				return fmt.Errorf("dependency %q conflicts with another dependency after rewriting", newPath)
+			}
+			modFileChanged = modFileChanged || newPath != mpath
+			deps[newPath] = dep
+		}
+		mf.Deps = deps
+	}
+	if !modFileChanged {
+		// Nothing to do.
+		return nil
+	}
+	newModFileData, err := modfile.Format(mf)
+	if err != nil {
+		return fmt.Errorf("invalid resulting module.cue file after edits: %v", err)
+	}
+
+	modRoot, err := findModuleRoot()
+	if err != nil {
+		return err
+	}
+	w := &refactorWriter{cmd: cmd, dryRun: dryRun}
+	err = rewriteModuleImports(modRoot, func(spec *ast.ImportSpec) (bool, error) {
+		if renameModule {
+			if ch, err := mr.rewriteImport(spec); ch || err != nil {
+				return ch, err
+			}
+		}
+		return rewriteDepImport(spec, depRewrites)
+	}, w.write)
+	if err != nil {
+		return err
+	}
+	// Write the module file last so that a failure above leaves
+	// the module in a consistent state.
+	return w.write(modFilePath, modFileData, newModFileData)
+}
+
+// rewriteDepModulePath applies the first matching rewrite
+// to the given qualified dependency module path.
+func rewriteDepModulePath(mpath string, rewrites []modulePrefixRewrite) (string, error) {
+	base, major, _ := ast.SplitPackageVersion(mpath)
+	for _, r := range rewrites {
+		newBase, ok := r.rewrite(base)
+		if !ok {
+			continue
+		}
+		newPath := newBase + "@" + major
+		if err := module.CheckPath(newPath); err != nil {
+			return "", fmt.Errorf("invalid dependency module path %q after rewriting: %v", newPath, err)
+		}
+		return newPath, nil
+	}
+	return mpath, nil
+}
+
+// rewriteDepImport rewrites an import spec referring to a package
+// in a dependency module according to the first matching rewrite.
+// As with [modRenamer.rewriteImport], the identifier that the
+// package is imported as is left unchanged.
+func rewriteDepImport(spec *ast.ImportSpec, rewrites []modulePrefixRewrite) (changed bool, err error) {
+	importPath, err := literal.Unquote(spec.Path.Value)
+	if err != nil {
+		return false, fmt.Errorf("malformed import path in AST: %v", err)
+	}
+	ip := ast.ParseImportPath(importPath)
+	for _, r := range rewrites {
+		newPath, ok := r.rewrite(ip.Path)
+		if !ok {
+			continue
+		}
+		ip.Path = newPath
+		ip.ExplicitQualifier = false // Only include if needed.
+		spec.Path.Value = literal.String.Quote(ip.String())
+		return true, nil
+	}
+	return false, nil
+}
+
+// refactorWriter writes the results of a refactoring, or shows
+// them as a diff when dryRun is set.
+type refactorWriter struct {
+	cmd    *Command
+	dryRun bool
+}
+
+func (w *refactorWriter) write(filename string, oldData, newData []byte) error {
+	if bytes.Equal(oldData, newData) {
+		return nil
+	}
+	if !w.dryRun {
+		return os.WriteFile(filename, newData, 0o666)
+	}
+	path, err := filepath.Rel(rootWorkingDir(), filename)
+	if err != nil {
+		path = filename
+	}
+	path = filepath.ToSlash(path)
+	fmt.Fprintln(w.cmd.OutOrStdout(), string(diff.Diff(path+".orig", oldData, path, newData)))
+	return nil
+}
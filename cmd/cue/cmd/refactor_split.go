@@ -0,0 +1,446 @@
+// Copyright 2025 CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"cuelang.org/go/cue/ast"
+	"cuelang.org/go/cue/format"
+	"cuelang.org/go/cue/parser"
+	"cuelang.org/go/cue/token"
+)
+
+func newRefactorSplitCmd(c *Command) *cobra.Command {
+	cmd := &cobra.Command{
+		// Experimental so far.
+		Hidden: true,
+
+		Use:   "split [--max-size n | --map field=file...] <file.cue>",
+		Short: "split a CUE file into multiple files",
+		Long: `
+WARNING: THIS COMMAND IS EXPERIMENTAL.
+
+This command splits a single CUE file into multiple files in the same
+package, moving top-level fields out of the original file. Each new
+file has the same package clause and file-level attributes (such as
+@if build constraints) as the original, and imports only the packages
+used by the fields it contains. Imports which are no longer used are
+removed from the original file. Comments attached to a field move
+along with it.
+
+The split is driven either by a target size or by an explicit
+mapping.
+
+With --max-size, top-level fields are grouped in order into files of
+at most the given number of bytes, where possible. The first group
+stays in the original file and subsequent groups are written to files
+named after the original: for example, splitting config.cue results
+in config_1.cue, config_2.cue and so on.
+
+With --map, which may be repeated, the named top-level field is moved
+to the given file, which must be in the directory of the original file.
+Multiple fields may be mapped to the same file. Splitting into
+subpackages is not supported, as references to the moved fields would
+then have to be rewritten to refer to an imported package.
+
+Top-level let clauses and label aliases, such as X in X=foo: bar, are
+only visible within the file which declares them. A field which refers
+to such an identifier cannot be moved away from its declaration, and a
+field with an alias cannot be moved away from the declarations which
+refer to the alias. With --max-size such fields are left in the
+original file; with --map an error is reported.
+
+The target files must not already exist. With --dry-run, no files are
+written; instead, a diff is printed for each file that would be
+created or changed.
+
+For example:
+
+	# Split schema.cue into files of at most 4KiB each.
+	cue refactor split --max-size 4096 schema.cue
+
+	# Move the #Deployment and #Service definitions into their own files.
+	cue refactor split --map '#Deployment=deployment.cue' --map '#Service=service.cue' schema.cue
+`[1:],
+		RunE: mkRunE(c, runRefactorSplit),
+		Args: cobra.ExactArgs(1),
+	}
+	cmd.Flags().Int(string(flagMaxSize), 0, "maximum size in bytes of each resulting file")
+	cmd.Flags().StringArray(string(flagMap), nil, "move the top-level field to the given file (field=file)")
+	cmd.Flags().Bool(string(flagDryRun), false, "only display a diff of the changes that would be made")
+
+	return cmd
+}
+
+func runRefactorSplit(cmd *Command, args []string) error {
+	maxSize := flagMaxSize.Int(cmd)
+	mapping := make(map[string]string)
+	for _, arg := range flagMap.StringArray(cmd) {
+		name, file, ok := strings.Cut(arg, "=")
+		if !ok || name == "" || file == "" {
+			return fmt.Errorf("invalid --%s argument %q; must be of the form field=file", flagMap, arg)
+		}
+		if filepath.Ext(file) != ".cue" {
+			return fmt.Errorf("invalid --%s argument %q: target file must have a .cue extension", flagMap, arg)
+		}
+		if filepath.Base(file) != file {
+			return fmt.Errorf("invalid --%s argument %q: target file must be in the directory of the original file; splitting into subpackages is not supported", flagMap, arg)
+		}
+		mapping[name] = file
+	}
+	switch {
+	case maxSize > 0 && len(mapping) > 0:
+		return fmt.Errorf("cannot specify both --%s and --%s", flagMaxSize, flagMap)
+	case maxSize <= 0 && len(mapping) == 0:
+		return fmt.Errorf("one of --%s or --%s must be specified", flagMaxSize, flagMap)
+	}
+
+	filename := args[0]
+	src, err := os.ReadFile(filename)
+	if err != nil {
+		return err
+	}
+	f, err := parser.ParseFile(filename, src, parser.ParseComments)
+	if err != nil {
+		return err
+	}
+	pkgName := f.PackageName()
+	if pkgName == "" {
+		return fmt.Errorf("%s: cannot split a file without a package clause", filename)
+	}
+	s := newFileSplitter(f)
+
+	dir := filepath.Dir(filename)
+	if len(mapping) > 0 {
+		err = s.assignByName(mapping, dir, filename)
+	} else {
+		base := strings.TrimSuffix(filepath.Base(filename), ".cue")
+		err = s.assignBySize(maxSize, func(part int) string {
+			return filepath.Join(dir, fmt.Sprintf("%s_%d.cue", base, part))
+		})
+	}
+	if err != nil {
+		return err
+	}
+
+	w := &refactorWriter{cmd: cmd, dryRun: flagDryRun.Bool(cmd)}
+	for _, target := range s.targets {
+		if _, err := os.Stat(target); err == nil {
+			return fmt.Errorf("target file %s already exists", target)
+		}
+	}
+	for _, target := range s.targets {
+		data, err := format.Node(s.newFile(target, pkgName))
+		if err != nil {
+			return err
+		}
+		if err := w.write(target, nil, data); err != nil {
+			return err
+		}
+	}
+	s.trimOriginal()
+	data, err := format.Node(f)
+	if err != nil {
+		return err
+	}
+	return w.write(filename, src, data)
+}
+
+// fileSplitter holds the state for splitting the top-level
+// declarations of a single file across multiple files.
+type fileSplitter struct {
+	file     *ast.File
+	preamble []ast.Decl
+	body     []ast.Decl
+
+	// dest holds the target file for each element of body,
+	// or the empty string if it stays in the original file.
+	dest []string
+
+	// targets holds all target files in order of first use.
+	targets []string
+
+	// refs holds, for each element of body, the indices of the other
+	// elements of body which declare identifiers it refers to that are
+	// only visible within the original file, such as let clauses and
+	// label aliases.
+	refs [][]int
+}
+
+func newFileSplitter(f *ast.File) *fileSplitter {
+	preamble := f.Preamble()
+	body := f.Decls[len(preamble):]
+
+	// The parser resolves references to let clauses to their clauses,
+	// and those to label aliases to their fields.
+	fileScoped := make(map[ast.Node]int)
+	for i, d := range body {
+		switch d := d.(type) {
+		case *ast.LetClause:
+			fileScoped[d] = i
+		case *ast.Field:
+			if _, ok := d.Label.(*ast.Alias); ok {
+				fileScoped[d] = i
+			}
+		}
+	}
+	refs := make([][]int, len(body))
+	for i, d := range body {
+		ast.Walk(d, func(n ast.Node) bool {
+			if id, ok := n.(*ast.Ident); ok {
+				if j, ok := fileScoped[id.Node]; ok && j != i && !slices.Contains(refs[i], j) {
+					refs[i] = append(refs[i], j)
+				}
+			}
+			return true
+		}, nil)
+	}
+	return &fileSplitter{
+		file:     f,
+		preamble: preamble,
+		body:     body,
+		dest:     make([]string, len(body)),
+		refs:     refs,
+	}
+}
+
+func (s *fileSplitter) setDest(i int, target string) {
+	s.dest[i] = target
+	if !slices.Contains(s.targets, target) {
+		s.targets = append(s.targets, target)
+	}
+}
+
+// assignByName moves the fields named in mapping to their target files.
+// Fields mapped to the original file are left where they are.
+func (s *fileSplitter) assignByName(mapping map[string]string, dir, original string) error {
+	found := make(map[string]bool)
+	for i, d := range s.body {
+		name := splitFieldName(d)
+		target, ok := mapping[name]
+		if !ok {
+			continue
+		}
+		found[name] = true
+		if target := filepath.Join(dir, target); target != filepath.Clean(original) {
+			s.setDest(i, target)
+		}
+	}
+	for name := range mapping {
+		if !found[name] {
+			return fmt.Errorf("no top-level field %s found", name)
+		}
+	}
+	for i, refs := range s.refs {
+		for _, j := range refs {
+			if s.dest[i] == s.dest[j] {
+				continue
+			}
+			if s.dest[i] != "" {
+				return fmt.Errorf("cannot move field %s: it refers to the file-scoped identifier %s", splitFieldName(s.body[i]), fileScopedName(s.body[j]))
+			}
+			return fmt.Errorf("cannot move field %s: its alias %s is referred to by %s", splitFieldName(s.body[j]), fileScopedName(s.body[j]), splitDeclName(s.body[i]))
+		}
+	}
+	return nil
+}
+
+// assignBySize groups movable fields into files of at most maxSize
+// bytes each, with the first group remaining in the original file.
+func (s *fileSplitter) assignBySize(maxSize int, partName func(part int) string) error {
+	part, size := 0, 0
+	for i, d := range s.body {
+		if splitFieldName(d) == "" || s.pinned(i) {
+			// Stays in the original file.
+			continue
+		}
+		data, err := format.Node(d)
+		if err != nil {
+			return err
+		}
+		n := len(data) + 1
+		if size > 0 && size+n > maxSize {
+			part++
+			size = 0
+		}
+		size += n
+		if part > 0 {
+			s.setDest(i, partName(part))
+		}
+	}
+	return nil
+}
+
+// pinned reports whether the i'th element of body must stay in the
+// original file, as it refers to a file-scoped identifier declared by
+// another element, or declares one referred to by another element.
+func (s *fileSplitter) pinned(i int) bool {
+	if len(s.refs[i]) > 0 {
+		return true
+	}
+	for _, refs := range s.refs {
+		if slices.Contains(refs, i) {
+			return true
+		}
+	}
+	return false
+}
+
+// newFile returns a file holding all the declarations
+// destined for target.
+func (s *fileSplitter) newFile(target, pkgName string) *ast.File {
+	var decls []ast.Decl
+	for i, d := range s.body {
+		if s.dest[i] == target {
+			decls = append(decls, d)
+		}
+	}
+	var preamble []ast.Decl
+	for _, d := range s.preamble {
+		if a, ok := d.(*ast.Attribute); ok {
+			// File-level attributes, such as build constraints,
+			// apply equally to all parts of the original file.
+			preamble = append(preamble, &ast.Attribute{Text: a.Text})
+		}
+	}
+	pkg := &ast.Package{Name: ast.NewIdent(pkgName)}
+	if len(preamble) > 0 {
+		pkg.PackagePos = token.NoPos.WithRel(token.NewSection)
+	}
+	preamble = append(preamble, pkg)
+	if specs := usedImports(s.file, decls); len(specs) > 0 {
+		imports := &ast.ImportDecl{}
+		for _, spec := range specs {
+			var name *ast.Ident
+			if spec.Name != nil {
+				name = ast.NewIdent(spec.Name.Name)
+			}
+			imports.Specs = append(imports.Specs, &ast.ImportSpec{
+				Name: name,
+				Path: &ast.BasicLit{Kind: token.STRING, Value: spec.Path.Value},
+			})
+		}
+		preamble = append(preamble, imports)
+	}
+	if len(decls) > 0 {
+		ast.SetRelPos(decls[0], token.NewSection)
+	}
+	return &ast.File{Decls: append(preamble, decls...)}
+}
+
+// trimOriginal removes all moved declarations from the original file,
+// along with any imports that are no longer used.
+func (s *fileSplitter) trimOriginal() {
+	var remaining []ast.Decl
+	for i, d := range s.body {
+		if s.dest[i] == "" {
+			remaining = append(remaining, d)
+		}
+	}
+	used := usedImports(s.file, remaining)
+	var preamble []ast.Decl
+	for _, d := range s.preamble {
+		if imports, ok := d.(*ast.ImportDecl); ok {
+			imports.Specs = slices.DeleteFunc(imports.Specs, func(spec *ast.ImportSpec) bool {
+				return !slices.Contains(used, spec)
+			})
+			if len(imports.Specs) == 0 {
+				continue
+			}
+		}
+		preamble = append(preamble, d)
+	}
+	s.file.Decls = append(preamble, remaining...)
+	s.file.Imports = used
+}
+
+// usedImports returns the import specs in f that are referred
+// to by decls, in the order they appear in f.
+func usedImports(f *ast.File, decls []ast.Decl) []*ast.ImportSpec {
+	used := make(map[*ast.ImportSpec]bool)
+	for _, d := range decls {
+		ast.Walk(d, func(n ast.Node) bool {
+			if id, ok := n.(*ast.Ident); ok {
+				if spec, ok := id.Node.(*ast.ImportSpec); ok {
+					used[spec] = true
+				}
+			}
+			return true
+		}, nil)
+	}
+	var specs []*ast.ImportSpec
+	f.VisitImports(func(d *ast.ImportDecl) {
+		for _, spec := range d.Specs {
+			if used[spec] {
+				specs = append(specs, spec)
+			}
+		}
+	})
+	return specs
+}
+
+// fileScopedName returns the name of the file-scoped identifier declared
+// by d, which is either a let clause or a field with a label alias.
+func fileScopedName(d ast.Decl) string {
+	switch d := d.(type) {
+	case *ast.LetClause:
+		return d.Ident.Name
+	case *ast.Field:
+		if a, ok := d.Label.(*ast.Alias); ok {
+			return a.Ident.Name
+		}
+	}
+	return ""
+}
+
+// splitDeclName describes the top-level declaration d in error messages.
+func splitDeclName(d ast.Decl) string {
+	if name := splitFieldName(d); name != "" {
+		return "field " + name
+	}
+	if name := fileScopedName(d); name != "" {
+		return "let " + name
+	}
+	return "a declaration"
+}
+
+// splitFieldName returns the name of the label of d if it is
+// a field that may be moved to another file, or the empty string
+// otherwise.
+func splitFieldName(d ast.Decl) string {
+	field, ok := d.(*ast.Field)
+	if !ok {
+		return ""
+	}
+	label := field.Label
+	if a, ok := label.(*ast.Alias); ok {
+		label, _ = a.Expr.(ast.Label)
+	}
+	if label == nil {
+		return ""
+	}
+	name, _, err := ast.LabelName(label)
+	if err != nil {
+		return ""
+	}
+	return name
+}
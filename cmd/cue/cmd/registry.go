@@ -1,11 +1,16 @@
 package cmd
 
 import (
+	"fmt"
+	"io"
 	"log/slog"
 	"net/http"
 	"os"
+	"regexp"
+	"sync"
 
 	"cuelang.org/go/internal/cuedebug"
+	"cuelang.org/go/internal/cuetrace"
 	"cuelang.org/go/internal/httplog"
 	"cuelang.org/go/internal/mod/modload"
 	"cuelang.org/go/mod/modconfig"
@@ -30,16 +35,53 @@ func newModConfig(registry string) *modconfig.Config {
 }
 
 func httpTransport() http.RoundTripper {
+	cfg, err := httpLogConfig()
+	if err != nil || cfg == nil {
+		// Any error is reported when the command starts.
+		return cuetrace.Transport("registry fetch", http.DefaultTransport)
+	}
+	return cuetrace.Transport("registry fetch", httplog.Transport(cfg))
+}
+
+// httpLogConfig returns the configuration for logging HTTP requests
+// to registries as given by $CUE_DEBUG, or nil if they are not logged.
+var httpLogConfig = sync.OnceValues(func() (*httplog.TransportConfig, error) {
 	cuedebug.Init()
-	if !cuedebug.Flags.HTTP {
-		return http.DefaultTransport
+	flags := cuedebug.Flags
+	if !flags.HTTP && flags.HTTPFile == "" {
+		return nil, nil
+	}
+	level, err := httplog.ParseLevel(flags.HTTPLevel)
+	if err != nil {
+		return nil, fmt.Errorf("invalid CUE_DEBUG: %v", err)
 	}
-	return httplog.Transport(&httplog.TransportConfig{
+	var redact []*regexp.Regexp
+	if flags.HTTPRedact != "" {
+		re, err := regexp.Compile(flags.HTTPRedact)
+		if err != nil {
+			return nil, fmt.Errorf("invalid CUE_DEBUG httpredact: %v", err)
+		}
+		redact = append(redact, re)
+	}
+	var w io.Writer = os.Stderr
+	if flags.HTTPFile != "" {
+		// The file is left open for the remainder of the process,
+		// as registry requests may be made at any point.
+		f, err := os.OpenFile(flags.HTTPFile, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0o666)
+		if err != nil {
+			return nil, fmt.Errorf("cannot open HTTP log file: %v", err)
+		}
+		w = f
+	}
+	return &httplog.TransportConfig{
 		// It would be nice to use the default slog logger,
 		// but that does a terrible job of printing structured
 		// values, so use JSON output instead.
 		Logger: httplog.SlogLogger{
-			Logger: slog.New(slog.NewJSONHandler(os.Stderr, nil)),
+			Logger: slog.New(slog.NewJSONHandler(w, nil)),
 		},
-	})
-}
+		MaxBodySize: flags.HTTPMaxBody,
+		Level:       level,
+		Redact:      redact,
+	}, nil
+})
@@ -20,39 +20,90 @@ import (
 	"fmt"
 	"io"
 	"os"
+	"path/filepath"
 	"runtime"
 	"runtime/pprof"
+	"strconv"
+	"strings"
 	"sync"
 	"testing"
 	"time"
 
 	"github.com/spf13/cobra"
-	"golang.org/x/text/message"
 
 	"cuelang.org/go/cue"
 	"cuelang.org/go/cue/cuecontext"
 	"cuelang.org/go/cue/errors"
 	"cuelang.org/go/cue/interpreter/embed"
+	"cuelang.org/go/cue/interpreter/js"
+	"cuelang.org/go/cue/interpreter/process"
+	"cuelang.org/go/cue/interpreter/starlark"
 	"cuelang.org/go/cue/stats"
 	"cuelang.org/go/internal/core/adt"
+	"cuelang.org/go/internal/cueconfig"
 	"cuelang.org/go/internal/cueexperiment"
+	"cuelang.org/go/internal/cuetelemetry"
+	"cuelang.org/go/internal/cuetrace"
+	"cuelang.org/go/internal/cueversion"
 	"cuelang.org/go/internal/encoding"
 	"cuelang.org/go/internal/filetypes"
 	"cuelang.org/go/internal/httplog"
+	"cuelang.org/go/internal/value"
 )
 
 // TODO: commands
 //   fix:      rewrite/refactor configuration files
-//             -i interactive: open diff and ask to update
-//   serve:    like cmd, but for servers
 //   get:      convert cue from other languages, like proto and go.
-//   gen:      generate files for other languages
 //   generate  like go generate (also convert cue to go doc)
-//   test      load and fully evaluate test files.
 //
 // TODO: documentation of concepts
 //   tasks     the key element for cmd, serve, and fix
 
+// newContext returns a new CUE context with the interpreters
+// supported by the cue command.
+func newContext() *cue.Context {
+	var opts []cuecontext.Option
+	if wasmInterp != nil {
+		opts = append(opts, cuecontext.Interpreter(wasmInterp))
+	}
+	// Embedding should work with [cuecontext.New] too.
+	// Currently that causes an import cycle.
+	// See: https://cuelang.org/issue/3613
+	var embedOpts []embed.Option
+	if cueexperiment.Flags.EmbedRemote {
+		embedOpts = append(embedOpts, embed.Remote(embedCacheDir(), nil))
+	}
+	opts = append(opts, cuecontext.Interpreter(embed.New(embedOpts...)))
+	if cueexperiment.Flags.ExternJS {
+		opts = append(opts, cuecontext.Interpreter(js.New()))
+	}
+	if cueexperiment.Flags.ExternStarlark {
+		opts = append(opts, cuecontext.Interpreter(starlark.New()))
+	}
+	if cueexperiment.Flags.ExternProcess {
+		opts = append(opts, cuecontext.Interpreter(processInterp))
+	}
+	ctx := cuecontext.New(opts...)
+	setLimits(ctx)
+	return ctx
+}
+
+// processInterp runs the programs of @extern(process). It is shared by all
+// contexts, so that the programs started by a command can be stopped
+// once it finishes.
+var processInterp = process.New()
+
+// embedCacheDir returns the directory in which remote content embedded
+// with @embed(url=...) is cached, or the empty string if there is no
+// cache directory.
+func embedCacheDir() string {
+	dir, err := cueconfig.CacheDir(os.Getenv)
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(dir, "mod", "embed")
+}
+
 type runFunction func(cmd *Command, args []string) error
 
 // wasmInterp is set when the cuewasm build tag is enbabled.
@@ -75,6 +126,34 @@ func statsEncoder(cmd *Command) (*encoding.Encoder, error) {
 	})
 }
 
+// statsBreakdown parses $CUE_STATS_BREAKDOWN, a comma-separated list which
+// adds a breakdown of the stats written to $CUE_STATS_FILE. "packages" adds
+// the stats per package, and "paths" or "paths=N" adds the stats of the N
+// paths, 10 by default, which caused the most work.
+func statsBreakdown() (packages bool, paths int, err error) {
+	s := os.Getenv("CUE_STATS_BREAKDOWN")
+	if s == "" {
+		return false, 0, nil
+	}
+	for _, elem := range strings.Split(s, ",") {
+		name, arg, hasArg := strings.Cut(elem, "=")
+		switch {
+		case name == "packages" && !hasArg:
+			packages = true
+		case name == "paths" && !hasArg:
+			paths = 10
+		case name == "paths":
+			paths, err = strconv.Atoi(arg)
+			if err != nil || paths <= 0 {
+				return false, 0, fmt.Errorf("invalid CUE_STATS_BREAKDOWN value %q: paths must be a positive number", elem)
+			}
+		default:
+			return false, 0, fmt.Errorf("invalid CUE_STATS_BREAKDOWN value %q; must be \"packages\" or \"paths[=N]\"", elem)
+		}
+	}
+	return packages, paths, nil
+}
+
 // Stats expands [stats.Counts] with counters obtained from other sources,
 // such as the Go runtime. The stats are grouped by category to clarify their source.
 type Stats struct {
@@ -86,6 +165,35 @@ type Stats struct {
 		AllocBytes   uint64
 		AllocObjects uint64
 	}
+
+	// Packages breaks the CUE stats down per package.
+	// It is only set when requested via $CUE_STATS_BREAKDOWN.
+	Packages []PackageStats `json:",omitempty"`
+
+	// Paths holds the CUE stats of the paths which caused the most work.
+	// It is only set when requested via $CUE_STATS_BREAKDOWN.
+	Paths []PathStats `json:",omitempty"`
+}
+
+// PackageStats holds the stats of a single package. Values which do not
+// belong to a package loaded from disk, such as those of builtin packages,
+// are grouped under an empty Path.
+type PackageStats struct {
+	Path         string
+	Unifications int64
+	Disjuncts    int64
+
+	// TimeNanos is the time spent evaluating the package in nanoseconds,
+	// excluding the time spent on other packages it imports.
+	TimeNanos int64
+}
+
+// PathStats holds the stats of a single path within a package.
+type PathStats struct {
+	Package      string
+	Path         string
+	Unifications int64
+	Disjuncts    int64
 }
 
 // commandGroup makes cmd runnable in a way that implements commands grouping more subcommands,
@@ -124,25 +232,40 @@ func mkRunE(c *Command, f runFunction) func(*cobra.Command, []string) error {
 		// However, users of the exposed Go API may be creating and running many commands,
 		// so we can't panic or fail if this setup work happens twice.
 
+		if err := applyFlagConfig(c, cmd); err != nil {
+			return err
+		}
+		if err := checkDiagnosticsFlag(c); err != nil {
+			return err
+		}
 		statsEnc, err := statsEncoder(c)
 		if err != nil {
 			return err
 		}
+		var statsPackages bool
+		var statsPaths int
+		if statsEnc != nil {
+			if statsPackages, statsPaths, err = statsBreakdown(); err != nil {
+				return err
+			}
+		}
 		if err := cueexperiment.Init(); err != nil {
 			return err
 		}
-		var opts []cuecontext.Option
-		if wasmInterp != nil {
-			opts = append(opts, cuecontext.Interpreter(wasmInterp))
+		if err := initDebugFlags(c); err != nil {
+			return err
 		}
-		// Embedding should work with [cuecontext.New] too.
-		// Currently that causes an import cycle.
-		// See: https://cuelang.org/issue/3613
-		opts = append(opts, cuecontext.Interpreter(embed.New()))
-		c.ctx = cuecontext.New(opts...)
+		countUsage(c, cmd)
+		if _, err := httpLogConfig(); err != nil {
+			return err
+		}
+		c.ctx = newContext()
 		// Some init work, such as in internal/filetypes, evaluates CUE by design.
 		// We don't want that work to count towards $CUE_STATS.
 		adt.ResetStats()
+		if statsPackages || statsPaths > 0 {
+			adt.ProfileNodes(true)
+		}
 
 		if cpuprofile := flagCpuProfile.String(c); cpuprofile != "" {
 			f, err := os.Create(cpuprofile)
@@ -156,7 +279,44 @@ func mkRunE(c *Command, f runFunction) func(*cobra.Command, []string) error {
 			defer pprof.StopCPUProfile()
 		}
 
-		err = f(c, args)
+		var evalProfile *os.File
+		var evalStart time.Time
+		if name := flagEvalProfile.String(c); name != "" {
+			evalProfile, err = os.Create(name)
+			if err != nil {
+				return fmt.Errorf("could not create evaluation profile: %v", err)
+			}
+			defer evalProfile.Close()
+			adt.ProfileEval(true)
+			evalStart = time.Now()
+		}
+
+		startDebug(c)
+		flushTrace, err := cuetrace.Init(os.Getenv)
+		if err != nil {
+			return err
+		}
+		ctx, span := cuetrace.Start(cmd.Context(), cmd.CommandPath())
+		cmd.SetContext(ctx)
+		err = runLimited(c, f, args)
+		// Stop the programs of @extern(process) rather than leaving them
+		// running after the cue command exits.
+		processInterp.(io.Closer).Close()
+		countEvalStats()
+		span.SetError(err)
+		span.End()
+		if err := flushTrace(ctx); err != nil {
+			fmt.Fprintf(c.Stderr(), "warning: %v\n", err)
+		}
+		// Reports are not errors, so they must not cause a non-zero exit code.
+		printDebug(c, c.Command.OutOrStderr())
+
+		if evalProfile != nil {
+			adt.ProfileEval(false)
+			if err := writeEvalProfile(evalProfile, &errors.Config{Cwd: rootWorkingDir()}, adt.EvalProfile(), evalStart, time.Since(evalStart)); err != nil {
+				return fmt.Errorf("could not write evaluation profile: %v", err)
+			}
+		}
 
 		// TODO(mvdan): support -memprofilerate like `go help testflag`.
 		if memprofile := flagMemProfile.String(c); memprofile != "" {
@@ -194,6 +354,24 @@ func mkRunE(c *Command, f runFunction) func(*cobra.Command, []string) error {
 			stats.Go.AllocBytes = m.TotalAlloc
 			stats.Go.AllocObjects = m.Mallocs
 
+			if statsPackages || statsPaths > 0 {
+				adt.ProfileNodes(false)
+			}
+			rt := value.OpContext(c.ctx).Runtime
+			if statsPackages {
+				for _, p := range adt.PackageProfiles(rt) {
+					stats.Packages = append(stats.Packages, PackageStats{
+						Path:         p.Path,
+						Unifications: p.Unifications,
+						Disjuncts:    p.Disjuncts,
+						TimeNanos:    p.Time.Nanoseconds(),
+					})
+				}
+			}
+			for _, p := range adt.PathProfiles(rt, statsPaths) {
+				stats.Paths = append(stats.Paths, PathStats(p))
+			}
+
 			statsEnc.Encode(c.ctx.Encode(stats))
 			statsEnc.Close()
 		}
@@ -260,18 +438,35 @@ func New(args []string) (*Command, error) {
 	cmd.SetHelpTemplate(helpTemplate)
 
 	for _, sub := range []*cobra.Command{
+		newBrowseCmd(c),
+		newCheckCompatCmd(c),
 		c.cmdCmd,
 		newCompletionCmd(c),
 		newEvalCmd(c),
 		newDefCmd(c),
+		newDiffCmd(c),
+		newDocCmd(c),
+		newExplainCmd(c),
 		newExportCmd(c),
 		newFixCmd(c),
 		newFmtCmd(c),
+		newFuzzCmd(c),
+		newGenCmd(c),
 		newGetCmd(c),
+		newGraphCmd(c),
+		newHookCmd(c),
 		newImportCmd(c),
+		newLintCmd(c),
 		newLoginCmd(c),
+		newMergeCmd(c),
+		newMigrateCmd(c),
 		newModCmd(c),
+		newOverlayCmd(c),
+		newQueryCmd(c),
 		newRefactorCmd(c),
+		newServeCmd(c),
+		newTelemetryCmd(c),
+		newTestCmd(c),
 		newTrimCmd(c),
 		newVersionCmd(c),
 		newVetCmd(c),
@@ -283,6 +478,7 @@ func New(args []string) (*Command, error) {
 	} {
 		cmd.AddCommand(sub)
 	}
+	registerPathCompletions(cmd)
 
 	cmd.SetArgs(args)
 	return c, nil
@@ -291,6 +487,9 @@ func New(args []string) (*Command, error) {
 // Main runs the cue tool and returns the code for passing to os.Exit.
 func Main() int {
 	start := time.Now()
+	// Telemetry must never break the cue command, so errors are ignored.
+	_ = cuetelemetry.Start(os.Getenv, cueversion.ModuleVersion())
+	defer func() { _ = cuetelemetry.Flush(time.Now()) }()
 	cmd, _ := New(os.Args[1:])
 	// CUE_BENCH makes the cue tool act like a `go test -bench=. -benchmem` benchmark,
 	// doing all of its work and then only printing a benchmark result line to stdout
@@ -308,11 +507,12 @@ func Main() int {
 		allowURLQueryParam,
 	)
 	if err := cmd.Run(ctx); err != nil {
+		var exitErr *exitError
+		if errors.As(err, &exitErr) {
+			return exitErr.code
+		}
 		if err != ErrPrintedError {
-			errors.Print(os.Stderr, err, &errors.Config{
-				Cwd:     rootWorkingDir(),
-				ToSlash: testing.Testing(),
-			})
+			errors.Print(os.Stderr, err, textErrorConfig(cmd))
 		}
 		return 1
 	}
@@ -380,26 +580,50 @@ func (c *Command) SetInput(r io.Reader) {
 	c.root.SetIn(r)
 }
 
+// SetDiagnostics sets the format in which the command reports errors,
+// as if the --diagnostics flag was used. The format must be "text",
+// the default, or "json", which writes one JSON object per error to
+// stderr, as described by 'cue help diagnostics'.
+func (c *Command) SetDiagnostics(format string) error {
+	if err := checkDiagnosticsFormat(format); err != nil {
+		return err
+	}
+	return c.root.PersistentFlags().Set(string(flagDiagnostics), format)
+}
+
 // ErrPrintedError indicates error messages have been printed directly to stderr,
 // and can be used so that the returned error itself isn't printed as well.
 var ErrPrintedError = errors.New("terminating because of errors")
 
+// exitError is like [ErrPrintedError], but also determines the exit code
+// of the cue command, which may even be zero.
+type exitError struct {
+	code int
+}
+
+func (e *exitError) Error() string { return ErrPrintedError.Error() }
+
 // printError uses cue/errors to print an error to stderr when non-nil.
 func printError(cmd *Command, err error) {
+	printErrorTo(cmd, cmd.Stderr(), err)
+}
+
+// printErrorTo is like printError, but writes to w.
+func printErrorTo(cmd *Command, w io.Writer, err error) {
 	if err == nil {
 		return
 	}
+	countErrors(errors.Errors(err))
 
-	// Link x/text as our localizer.
-	p := message.NewPrinter(getLang())
-	format := func(w io.Writer, format string, args ...interface{}) {
-		p.Fprintf(w, format, args...)
+	if flagDiagnostics.String(cmd) == diagJSON {
+		writeJSONDiagnostics(w, err, severityError, &errors.Config{
+			Cwd:     rootWorkingDir(),
+			ToSlash: testing.Testing(),
+		})
+		return
 	}
-	errors.Print(cmd.Stderr(), err, &errors.Config{
-		Format:  format,
-		Cwd:     rootWorkingDir(),
-		ToSlash: testing.Testing(),
-	})
+
+	errors.Print(w, err, textErrorConfig(cmd))
 }
 
 func (c *Command) Run(ctx context.Context) (err error) {
@@ -409,6 +633,20 @@ func (c *Command) Run(ctx context.Context) (err error) {
 	// - help
 	// For the latter two, we need to use the default loading.
 	if err := c.root.ExecuteContext(ctx); err != nil {
+		var exitErr *exitError
+		if err != ErrPrintedError && !errors.As(err, &exitErr) {
+			countErrors(errors.Errors(err))
+		}
+		// With machine-readable diagnostics, all errors are written by the
+		// command itself, so that callers need not format them.
+		f, _ := c.root.PersistentFlags().GetString(string(flagDiagnostics))
+		if f == diagJSON && err != ErrPrintedError && !errors.As(err, &exitErr) {
+			writeJSONDiagnostics(c.root.ErrOrStderr(), err, severityError, &errors.Config{
+				Cwd:     rootWorkingDir(),
+				ToSlash: testing.Testing(),
+			})
+			return ErrPrintedError
+		}
 		return err
 	}
 	if c.hasErr {
@@ -56,6 +56,19 @@ func TestCommand(t *testing.T) {
 	qt.Assert(t, qt.IsNil(err))
 	qt.Assert(t, qt.Equals(buf.String(), "{\n    \"foo\": 123\n}\n"))
 
+	// Verify that SetDiagnostics makes errors machine-readable.
+	c, err = cmd.New([]string{"export", "-"})
+	qt.Assert(t, qt.IsNil(err))
+	qt.Assert(t, qt.IsNotNil(c.SetDiagnostics("yaml")))
+	qt.Assert(t, qt.IsNil(c.SetDiagnostics("json")))
+	c.SetInput(strings.NewReader("foo: 1\nfoo: 2\n"))
+	buf.Reset()
+	c.SetOutput(io.Discard)
+	c.SetErr(&buf)
+	err = c.Run(ctx)
+	qt.Assert(t, qt.ErrorIs(err, cmd.ErrPrintedError))
+	qt.Assert(t, qt.StringContains(buf.String(), `{"severity":"error","code":"E1001","message":"conflicting values 2 and 1","path":"foo"`))
+
 	// Verify that we can use the API exposed by the embedded cobra command.
 	c, err = cmd.New([]string{"fmt", "nosuchfile.cue"})
 	qt.Assert(t, qt.IsNil(err))
@@ -0,0 +1,200 @@
+// Copyright 2025 CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"encoding/json"
+	"io"
+	"path/filepath"
+	"slices"
+
+	"cuelang.org/go/cue/errors"
+)
+
+// The types below model the subset of the SARIF 2.1.0 format
+// (https://docs.oasis-open.org/sarif/sarif/v2.1.0/sarif-v2.1.0.html)
+// which we need to report CUE errors.
+
+type sarifLog struct {
+	Schema  string     `json:"$schema"`
+	Version string     `json:"version"`
+	Runs    []sarifRun `json:"runs"`
+}
+
+type sarifRun struct {
+	Tool    sarifTool     `json:"tool"`
+	Results []sarifResult `json:"results"`
+}
+
+type sarifTool struct {
+	Driver sarifDriver `json:"driver"`
+}
+
+type sarifDriver struct {
+	Name           string      `json:"name"`
+	InformationURI string      `json:"informationUri"`
+	Rules          []sarifRule `json:"rules"`
+}
+
+type sarifRule struct {
+	ID               string       `json:"id"`
+	ShortDescription sarifMessage `json:"shortDescription"`
+	HelpURI          string       `json:"helpUri,omitempty"`
+}
+
+type sarifResult struct {
+	RuleID    string          `json:"ruleId"`
+	Level     string          `json:"level"`
+	Message   sarifMessage    `json:"message"`
+	Locations []sarifLocation `json:"locations,omitempty"`
+	Fixes     []sarifFix      `json:"fixes,omitempty"`
+}
+
+type sarifFix struct {
+	Description     sarifMessage          `json:"description"`
+	ArtifactChanges []sarifArtifactChange `json:"artifactChanges"`
+}
+
+type sarifArtifactChange struct {
+	ArtifactLocation sarifArtifactLocation `json:"artifactLocation"`
+	Replacements     []sarifReplacement    `json:"replacements"`
+}
+
+type sarifReplacement struct {
+	DeletedRegion   sarifRegion  `json:"deletedRegion"`
+	InsertedContent sarifMessage `json:"insertedContent"`
+}
+
+type sarifMessage struct {
+	Text string `json:"text"`
+}
+
+type sarifLocation struct {
+	PhysicalLocation sarifPhysicalLocation `json:"physicalLocation"`
+}
+
+type sarifPhysicalLocation struct {
+	ArtifactLocation sarifArtifactLocation `json:"artifactLocation"`
+	Region           *sarifRegion          `json:"region,omitempty"`
+}
+
+type sarifArtifactLocation struct {
+	URI string `json:"uri"`
+}
+
+type sarifRegion struct {
+	StartLine   int `json:"startLine"`
+	StartColumn int `json:"startColumn,omitempty"`
+	EndLine     int `json:"endLine,omitempty"`
+	EndColumn   int `json:"endColumn,omitempty"`
+}
+
+// writeSARIF writes errs and warnings to w as a SARIF 2.1.0 log with
+// a single run. File locations are made relative to cfg.Cwd when possible.
+func writeSARIF(w io.Writer, errs, warnings []errors.Error, cfg *errors.Config) error {
+	run := sarifRun{
+		Tool: sarifTool{Driver: sarifDriver{
+			Name:           "cue",
+			InformationURI: "https://cuelang.org",
+			Rules:          []sarifRule{},
+		}},
+		Results: []sarifResult{},
+	}
+	add := func(err errors.Error, level string) {
+		rule := diagnosticRuleFor(err)
+		if !slices.ContainsFunc(run.Tool.Driver.Rules, func(r sarifRule) bool { return r.ID == rule.id }) {
+			run.Tool.Driver.Rules = append(run.Tool.Driver.Rules, sarifRule{
+				ID:               rule.id,
+				ShortDescription: sarifMessage{Text: rule.description},
+				HelpURI:          rule.url,
+			})
+		}
+		result := sarifResult{
+			RuleID:  rule.id,
+			Level:   level,
+			Message: sarifMessage{Text: errors.String(err)},
+		}
+		for _, pos := range errors.Positions(err) {
+			p := pos.Position()
+			if p.Filename == "" {
+				continue
+			}
+			loc := sarifLocation{PhysicalLocation: sarifPhysicalLocation{
+				ArtifactLocation: sarifArtifactLocation{URI: sarifURI(p.Filename, cfg)},
+			}}
+			if p.IsValid() {
+				loc.PhysicalLocation.Region = &sarifRegion{
+					StartLine:   p.Line,
+					StartColumn: p.Column,
+				}
+			}
+			result.Locations = append(result.Locations, loc)
+		}
+		for _, h := range errors.Hints(err) {
+			if fix, ok := sarifFixFor(h, cfg); ok {
+				result.Fixes = append(result.Fixes, fix)
+			}
+		}
+		run.Results = append(run.Results, result)
+	}
+	for _, err := range errs {
+		add(err, severityError)
+	}
+	for _, err := range warnings {
+		add(err, severityWarning)
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(sarifLog{
+		Schema:  "https://json.schemastore.org/sarif-2.1.0.json",
+		Version: "2.1.0",
+		Runs:    []sarifRun{run},
+	})
+}
+
+// sarifFixFor converts a hint to a SARIF fix. Only hints with edits
+// can be represented as fixes.
+func sarifFixFor(h errors.Hint, cfg *errors.Config) (sarifFix, bool) {
+	fix := sarifFix{Description: sarifMessage{Text: h.Message}}
+	for _, e := range h.Edits {
+		start, end := e.Start.Position(), e.End.Position()
+		if !start.IsValid() || !end.IsValid() {
+			continue
+		}
+		uri := sarifURI(start.Filename, cfg)
+		i := slices.IndexFunc(fix.ArtifactChanges, func(c sarifArtifactChange) bool { return c.ArtifactLocation.URI == uri })
+		if i < 0 {
+			i = len(fix.ArtifactChanges)
+			fix.ArtifactChanges = append(fix.ArtifactChanges, sarifArtifactChange{
+				ArtifactLocation: sarifArtifactLocation{URI: uri},
+			})
+		}
+		fix.ArtifactChanges[i].Replacements = append(fix.ArtifactChanges[i].Replacements, sarifReplacement{
+			DeletedRegion: sarifRegion{
+				StartLine:   start.Line,
+				StartColumn: start.Column,
+				EndLine:     end.Line,
+				EndColumn:   end.Column,
+			},
+			InsertedContent: sarifMessage{Text: e.NewText},
+		})
+	}
+	return fix, len(fix.ArtifactChanges) > 0
+}
+
+// sarifURI returns a relative URI reference for filename.
+func sarifURI(filename string, cfg *errors.Config) string {
+	return filepath.ToSlash(diagnosticFilename(filename, cfg))
+}
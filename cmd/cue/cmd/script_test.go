@@ -204,6 +204,8 @@ func TestScript(t *testing.T) {
 			// * pending-success: polling for a token with device_code
 			//   responds with [tokenErrorCodePending] once, and then succeeds
 			// * immediate-success: polling for a token with device_code succeeds right away
+			// * refresh-success: like immediate-success, but the token comes with
+			//   a refresh token, which can be used to obtain a new token
 			"oauthregistry": func(ts *testscript.TestScript, neg bool, args []string) {
 				if neg || len(args) != 1 {
 					ts.Fatalf("usage: oauthregistry <mode>")
@@ -443,9 +445,49 @@ func TestMain(m *testing.M) {
 			err = enc.Encode(v)
 			check(err)
 		},
+		// A Docker credential helper which keeps each credential in a file
+		// in $CREDENTIAL_STORE_DIR, to test `cue login --credential-helper`.
+		"docker-credential-testing": func() { check(credentialHelper()) },
 	})
 }
 
+// credentialHelper implements the get, store and erase commands of the
+// Docker credential helper protocol on top of files.
+func credentialHelper() error {
+	dir := os.Getenv("CREDENTIAL_STORE_DIR")
+	input, err := io.ReadAll(os.Stdin)
+	if err != nil {
+		return err
+	}
+	file := func(serverURL string) string {
+		return filepath.Join(dir, url.PathEscape(serverURL))
+	}
+	switch os.Args[1] {
+	case "store":
+		var creds struct{ ServerURL, Username, Secret string }
+		if err := json.Unmarshal(input, &creds); err != nil {
+			return err
+		}
+		return os.WriteFile(file(creds.ServerURL), input, 0o600)
+	case "get":
+		data, err := os.ReadFile(file(string(input)))
+		if errors.Is(err, fs.ErrNotExist) {
+			fmt.Println("credentials not found in native keychain")
+			os.Exit(1)
+		}
+		os.Stdout.Write(data)
+		return err
+	case "erase":
+		err := os.Remove(file(string(input)))
+		if errors.Is(err, fs.ErrNotExist) {
+			fmt.Println("credentials not found in native keychain")
+			os.Exit(1)
+		}
+		return err
+	}
+	return fmt.Errorf("unknown credential helper command %q", os.Args[1])
+}
+
 func tsExpand(ts *testscript.TestScript, s string) string {
 	return os.Expand(s, func(key string) string {
 		return ts.Getenv(key)
@@ -507,6 +549,7 @@ func newMockRegistryOauth(mode string) *httptest.Server {
 		staticUserCode    = "user-code"
 		staticDeviceCode  = "device-code-longer-string"
 		staticAccessToken = "secret-access-token"
+		staticRefreshTok  = "secret-refresh-token"
 		intervalSecs      = 1 // 1s to keep the tests fast
 	)
 	// OAuth2 Device Authorization Request endpoint: https://datatracker.ietf.org/doc/html/rfc8628#section-3.1
@@ -525,6 +568,19 @@ func newMockRegistryOauth(mode string) *httptest.Server {
 	// OAuth2 Token endpoint: https://datatracker.ietf.org/doc/html/rfc6749#section-3.2
 	var tokenRequestCounter atomic.Int64
 	mux.HandleFunc("/login/oauth/token", func(w http.ResponseWriter, r *http.Request) {
+		if r.FormValue("grant_type") == "refresh_token" {
+			if mode != "refresh-success" || r.FormValue("refresh_token") != staticRefreshTok {
+				writeJSON(w, http.StatusBadRequest, tokenError{ErrorCode: "invalid_grant"})
+				return
+			}
+			writeJSON(w, http.StatusOK, oauth2.Token{
+				AccessToken:  "refreshed-access-token",
+				TokenType:    "Bearer",
+				RefreshToken: staticRefreshTok,
+				ExpiresIn:    int64(2 * time.Hour / time.Second), // 2h in seconds
+			})
+			return
+		}
 		deviceCode := r.FormValue("device_code")
 		if deviceCode != staticDeviceCode {
 			writeJSON(w, http.StatusBadRequest, tokenError{ErrorCode: tokenErrorCodeDenied})
@@ -546,6 +602,13 @@ func newMockRegistryOauth(mode string) *httptest.Server {
 				TokenType:   "Bearer",
 				ExpiresIn:   int64(time.Hour / time.Second), // 1h in seconds
 			})
+		case "refresh-success":
+			writeJSON(w, http.StatusOK, oauth2.Token{
+				AccessToken:  staticAccessToken,
+				TokenType:    "Bearer",
+				RefreshToken: staticRefreshTok,
+				ExpiresIn:    int64(time.Hour / time.Second), // 1h in seconds
+			})
 		default:
 			panic(fmt.Sprintf("unknown mode: %q", mode))
 		}
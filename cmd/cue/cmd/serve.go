@@ -0,0 +1,719 @@
+// Copyright 2025 CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"bytes"
+	"cmp"
+	"context"
+	"crypto/subtle"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/spf13/cobra"
+	"golang.org/x/net/http2"
+	"golang.org/x/net/http2/h2c"
+
+	"cuelang.org/go/cue"
+	"cuelang.org/go/cue/ast"
+	cueerrors "cuelang.org/go/cue/errors"
+	"cuelang.org/go/cue/parser"
+	cuejson "cuelang.org/go/encoding/json"
+	"cuelang.org/go/internal/core/adt"
+	"cuelang.org/go/internal/evalservice"
+)
+
+func newServeCmd(c *Command) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "serve (--admission | --rest | --grpc | --tasks name) [flags] [package]",
+		Short: "serve CUE evaluation and validation over HTTP",
+		Long: `serve starts a server which evaluates or validates CUE values on
+behalf of its clients.
+
+With --admission, the server implements the Kubernetes validating
+admission webhook protocol: it accepts AdmissionReview requests, and
+allows or denies the object of each request depending on whether it
+is valid. This makes it possible to enforce CUE schemas and policies
+in a cluster without deploying a separate policy engine.
+
+By default, an object is validated against the field of the package
+named after the kind of the object, such as Deployment or
+#Deployment, and is allowed if there is no such field. With -d, all
+objects are validated against the given expression instead:
+
+	cue serve --admission -d '#Object' ./schemas
+
+The object is also checked against the rules of the policy packs
+given with --policy, as with cue vet. Violations of rules with
+severity "warning", and of fields marked with @severity(warning), are
+returned to the client as warnings instead of denying the object.
+Deletions are always allowed.
+
+The API server requires webhooks to use HTTPS. Use --tls-cert and
+--tls-key to serve HTTPS directly, or serve plain HTTP behind a proxy
+which terminates TLS. The path /healthz responds with 200 OK, for use
+in readiness and liveness probes.
+
+	cue serve --admission --addr :8443 \
+		--tls-cert tls.crt --tls-key tls.key \
+		--policy ./policies ./schemas
+
+With --grpc, the server implements a gRPC service, which lets
+programs written in any language compile, unify, validate and export
+CUE values over a persistent connection, without embedding Go or
+running the cue command for each request. Values are kept on the
+server and referred to by handles, so that a schema only needs to be
+compiled once, and large values are exported as a stream of chunks.
+Each connection may hold up to 10000 handles; clients should release
+handles they no longer need.
+The service, cue.eval.v1.Evaluator, is defined in
+internal/evalservice/evalservice.proto in the CUE repository. Without
+TLS, the server accepts HTTP/2 without prior upgrade, as gRPC clients
+use for insecure connections.
+
+	cue serve --grpc --addr localhost:8443
+
+With --rest, the server implements a REST API for a CUE package,
+suitable for putting behind an API gateway:
+
+	POST /v1/validate  validate the request body against the package
+	                   or the schema given by the schema parameter,
+	                   such as ?schema=%23Deployment, or by -d
+	POST /v1/export    export the package unified with the request body
+	GET  /v1/query     export the value of the expression parameter
+	GET  /openapi.json the OpenAPI document describing the API
+
+Request bodies may be JSON, YAML or TOML, as given by their
+Content-Type or the encoding parameter, and the out parameter selects
+the encoding of exported values. Errors are reported in the format of
+--diagnostics=json. Policies given with --policy also apply to
+/v1/validate.
+
+With --tasks, the server runs the tasks of the _tool.cue files of the
+package, like cue cmd, for each request it receives. The routes of the
+server are declared in the server section of the tool files, keyed by
+the given name. Each route is keyed by a pattern of the form accepted
+by Go's net/http package, such as "GET /items/{id}", and declares the
+tasks to run for a request, along with the response to it:
+
+	package items
+
+	import "tool/exec"
+
+	server: api: {
+		addr: ":8080" // optional, overridden by --addr
+		route: "GET /greet/{name}": {
+			request: _
+			run: exec.Run & {
+				cmd:    ["echo", "hello", request.params.name]
+				stdout: string
+			}
+			response: {
+				status: 200 // default 200
+				header: "Content-Type": "text/plain"
+				body: run.stdout
+			}
+		}
+	}
+
+The request field of a route holds the method, path, params (the values
+of the wildcards of the pattern), query, header and body of the request,
+where query and header map names to strings and the body is a string.
+The response body may be a string or bytes, or any other value, which
+is encoded as JSON. A route without a response responds with no content,
+and a route whose tasks fail responds with status 500 and the errors.
+
+The tool files are evaluated anew for each request, such that requests
+are served concurrently and changes to the tool files apply to the next
+request, except for the set of routes which is fixed when the server
+starts. Tasks run until they complete, the client goes away or the
+server shuts down.
+
+	cue serve --tasks api ./items
+
+The server stops when it receives an interrupt or SIGTERM. It waits up
+to five seconds for the requests in progress to complete, and cancels
+them after that.
+
+Authentication
+
+All requests except those to /healthz can be authenticated with
+bearer tokens or client certificates. --token-file names a file with
+the tokens which are accepted, one per line. --tls-client-ca names a
+file with the PEM-encoded certificates of the authorities which sign
+client certificates; clients without such a certificate are rejected
+during the TLS handshake.
+
+	cue serve --rest --tls-cert tls.crt --tls-key tls.key \
+		--token-file tokens.txt ./schemas
+`,
+		RunE: mkRunE(c, runServe),
+	}
+
+	addInjectionFlags(cmd.Flags(), false, false)
+
+	cmd.Flags().Bool(string(flagAdmission), false,
+		"serve the Kubernetes validating admission webhook protocol")
+	cmd.Flags().Bool(string(flagGRPC), false,
+		"serve the CUE evaluation service over gRPC")
+	cmd.Flags().Bool(string(flagREST), false,
+		"serve a REST API to validate, export and query a package")
+	cmd.Flags().String(string(flagTasks), "",
+		"serve the routes of the named server of the tool files, running their tasks")
+	cmd.Flags().String(string(flagAddr), ":8443", "address to listen on")
+	cmd.Flags().String(string(flagTLSCert), "", "certificate file for serving HTTPS")
+	cmd.Flags().String(string(flagTLSKey), "", "private key file for serving HTTPS")
+	cmd.Flags().String(string(flagTLSClientCA), "",
+		"file with certificates of authorities for verifying client certificates")
+	cmd.Flags().String(string(flagTokenFile), "",
+		"file with bearer tokens accepted by the server, one per line")
+	cmd.Flags().StringP(string(flagSchema), "d", "",
+		"expression to select the schema for all objects, instead of selecting it by kind or using the package")
+	cmd.Flags().StringArray(string(flagPolicy), nil,
+		"check values against the rules of a policy pack package")
+
+	return cmd
+}
+
+func runServe(cmd *Command, args []string) error {
+	admission, grpc, rest := flagAdmission.Bool(cmd), flagGRPC.Bool(cmd), flagREST.Bool(cmd)
+	tasks := flagTasks.String(cmd)
+	if n := btoi(admission) + btoi(grpc) + btoi(rest) + btoi(tasks != ""); n != 1 {
+		return fmt.Errorf("serve requires one of --%s, --%s, --%s or --%s", flagAdmission, flagGRPC, flagREST, flagTasks)
+	}
+	certFile, keyFile := flagTLSCert.String(cmd), flagTLSKey.String(cmd)
+	if (certFile == "") != (keyFile == "") {
+		return fmt.Errorf("--%s and --%s must be used together", flagTLSCert, flagTLSKey)
+	}
+	var tlsConfig *tls.Config
+	if caFile := flagTLSClientCA.String(cmd); caFile != "" {
+		if certFile == "" {
+			return fmt.Errorf("--%s requires --%s and --%s", flagTLSClientCA, flagTLSCert, flagTLSKey)
+		}
+		pem, err := os.ReadFile(caFile)
+		if err != nil {
+			return err
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return fmt.Errorf("no certificates found in %s", caFile)
+		}
+		tlsConfig = &tls.Config{
+			ClientCAs:  pool,
+			ClientAuth: tls.RequireAndVerifyClientCert,
+		}
+	}
+	var tokens [][]byte
+	if tokenFile := flagTokenFile.String(cmd); tokenFile != "" {
+		var err error
+		if tokens, err = readTokens(tokenFile); err != nil {
+			return err
+		}
+	}
+
+	addr := flagAddr.String(cmd)
+	var h http.Handler
+	switch {
+	case admission:
+		ah, err := newAdmissionHandler(cmd, args)
+		if err != nil {
+			return err
+		}
+		h = ah
+	case grpc:
+		if len(args) > 0 {
+			return fmt.Errorf("serve --%s does not take arguments", flagGRPC)
+		}
+		h = evalservice.NewServer(newContext)
+	case rest:
+		rh, err := newRESTHandler(cmd, args)
+		if err != nil {
+			return err
+		}
+		rh.auth = tokens != nil
+		h = rh
+	case tasks != "":
+		ts, err := newTaskServer(cmd, tasks, func(ctx *cue.Context) (cue.Value, error) {
+			inst, err := buildToolsIn(cmd, ctx, cmd.Flags(), args)
+			if err != nil {
+				return cue.Value{}, err
+			}
+			if inst == nil {
+				return cue.Value{}, fmt.Errorf("no tool files found")
+			}
+			return inst.Value(), nil
+		})
+		if err != nil {
+			return err
+		}
+		ts.cfg = textErrorConfig(cmd)
+		if ts.addr != "" && !cmd.Flags().Changed(string(flagAddr)) {
+			addr = ts.addr
+		}
+		h = ts
+	}
+	if tokens != nil {
+		h = requireToken(h, tokens)
+	}
+
+	l, err := net.Listen("tcp", addr)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(cmd.OutOrStdout(), "listening on %v\n", l.Addr())
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/healthz", func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, "ok\n")
+	})
+	mux.Handle("/", recoverLimitHandler(h, textErrorConfig(cmd)))
+	srv := http.Server{
+		Handler:           mux,
+		TLSConfig:         tlsConfig,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	if grpc && certFile == "" {
+		// gRPC clients use HTTP/2 with prior knowledge over insecure
+		// connections; with TLS, HTTP/2 is negotiated by net/http.
+		srv.Handler = h2c.NewHandler(mux, &http2.Server{})
+	}
+	return serveUntilInterrupted(&srv, func() error {
+		if certFile != "" {
+			return srv.ServeTLS(l, certFile, keyFile)
+		}
+		return srv.Serve(l)
+	})
+}
+
+// serveUntilInterrupted runs serve, which serves HTTP requests with srv,
+// until it fails or the process is interrupted, and then shuts srv down.
+// The contexts of the requests still in progress when shutting down times
+// out are canceled.
+func serveUntilInterrupted(srv *http.Server, serve func() error) error {
+	base, cancelRequests := context.WithCancel(context.Background())
+	defer cancelRequests()
+	srv.BaseContext = func(net.Listener) context.Context { return base }
+
+	sigint := make(chan os.Signal, 1)
+	signal.Notify(sigint, os.Interrupt, syscall.SIGTERM)
+	defer signal.Stop(sigint)
+
+	// serve fails right away if it cannot start, such as when the TLS
+	// certificate cannot be read.
+	errc := make(chan error, 1)
+	go func() { errc <- serve() }()
+
+	select {
+	case err := <-errc:
+		return err
+	case <-sigint:
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := srv.Shutdown(ctx); err != nil {
+		return err
+	}
+	if err := <-errc; !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
+
+// recoverLimitHandler returns a handler which serves requests with h. If
+// evaluation for a request exceeds a limit set with --timeout,
+// --max-nodes, or --max-disjuncts, it responds with 503 Service
+// Unavailable rather than letting the panic of the evaluator tear down
+// the connection.
+func recoverLimitHandler(h http.Handler, cfg *cueerrors.Config) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var err error
+		func() {
+			defer recoverLimit(&err)
+			h.ServeHTTP(w, r)
+		}()
+		if err != nil {
+			var buf bytes.Buffer
+			cueerrors.Print(&buf, err, cfg)
+			http.Error(w, buf.String(), http.StatusServiceUnavailable)
+		}
+	})
+}
+
+// newAdmissionHandler returns the handler for serve --admission, which
+// validates objects against the package given by args.
+func newAdmissionHandler(cmd *Command, args []string) (*admissionHandler, error) {
+	h := &admissionHandler{}
+	h.served.load = func() (*servedPackage, error) {
+		return loadServed(cmd, args)
+	}
+	// Load the package upfront, so that errors in it are reported on
+	// startup rather than by the first request.
+	if _, err := h.served.get(); err != nil {
+		return nil, err
+	}
+	if flagVerbose.Bool(cmd) {
+		h.log = cmd.Stderr()
+	}
+	return h, nil
+}
+
+// servedPackage holds the values served by serve --admission and
+// serve --rest, which are built within ctx.
+type servedPackage struct {
+	ctx      *cue.Context
+	pkg      cue.Value
+	schema   cue.Value // schema selected with -d, if any
+	policies []policyRule
+}
+
+// loadServed loads the package given by args and the policy packs named
+// by the --policy flags, and builds them within a new context.
+func loadServed(cmd *Command, args []string) (*servedPackage, error) {
+	ctx := newContext()
+	pkg, schema, err := loadServePackage(cmd, ctx, args)
+	if err != nil {
+		return nil, err
+	}
+	policies, err := loadPolicies(cmd, ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &servedPackage{
+		ctx:      ctx,
+		pkg:      pkg,
+		schema:   schema,
+		policies: policies,
+	}, nil
+}
+
+// servedState holds the package of a server, which is used by one request
+// at a time, as values of a context may not be evaluated concurrently.
+//
+// Evaluation which exceeds a limit panics midway, which may leave the
+// values of the context partially evaluated. The package is therefore
+// loaded anew, within a new context, for the request following one which
+// exceeded a limit.
+type servedState struct {
+	load func() (*servedPackage, error)
+
+	mu  sync.Mutex
+	pkg *servedPackage // nil if the package needs to be loaded
+}
+
+// get returns the package, loading it if needed. Once s is in use by
+// requests, it must be called with s.mu held.
+func (s *servedState) get() (*servedPackage, error) {
+	if s.pkg == nil {
+		p, err := s.load()
+		if err != nil {
+			return nil, err
+		}
+		s.pkg = p
+	}
+	return s.pkg, nil
+}
+
+// use calls f with the package. If f exceeds a limit on evaluation, the
+// package is dropped, and the panic is passed on to [recoverLimitHandler].
+func (s *servedState) use(f func(p *servedPackage)) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	p, err := s.get()
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if r := recover(); r != nil {
+			if _, ok := r.(*adt.LimitError); ok {
+				s.pkg = nil
+			}
+			panic(r)
+		}
+	}()
+	f(p)
+	return nil
+}
+
+// loadServePackage loads the package given by args and builds it within
+// ctx. It also returns the schema selected with -d, if any.
+func loadServePackage(cmd *Command, ctx *cue.Context, args []string) (pkg, schema cue.Value, err error) {
+	cfg, err := defaultConfig()
+	if err != nil {
+		return pkg, schema, err
+	}
+	setTags(cfg.loadCfg, cmd.Flags())
+	binsts := loadFromArgs(args, cfg.loadCfg)
+	if err := injectFiles(cmd, cmd.Flags(), binsts); err != nil {
+		return pkg, schema, err
+	}
+	if len(binsts) != 1 {
+		return pkg, schema, fmt.Errorf("serve requires a single package")
+	}
+	if err := binsts[0].Err; err != nil {
+		return pkg, schema, err
+	}
+	pkg = ctx.BuildInstance(binsts[0])
+	if err := pkg.Validate(); err != nil {
+		return pkg, schema, traceErrors(binsts, buildFileNames(binsts), pkg, err)
+	}
+	if s := flagSchema.String(cmd); s != "" {
+		if schema, err = evalServeExpr(ctx, pkg, "--schema", s); err != nil {
+			return pkg, schema, err
+		}
+	}
+	return pkg, schema, nil
+}
+
+// evalServeExpr evaluates the CUE expression src in the scope of v.
+func evalServeExpr(ctx *cue.Context, v cue.Value, name, src string) (cue.Value, error) {
+	expr, err := parser.ParseExpr(name, src)
+	if err != nil {
+		return cue.Value{}, err
+	}
+	x := ctx.BuildExpr(expr, cue.InferBuiltins(true), cue.Scope(v))
+	if err := x.Validate(); err != nil {
+		return cue.Value{}, err
+	}
+	return x, nil
+}
+
+func btoi(b bool) int {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+// readTokens reads the bearer tokens accepted by the server from file,
+// one per line. Empty lines and lines starting with # are ignored.
+func readTokens(file string) ([][]byte, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	var tokens [][]byte
+	for _, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimSpace(line)
+		if line != "" && !strings.HasPrefix(line, "#") {
+			tokens = append(tokens, []byte(line))
+		}
+	}
+	if len(tokens) == 0 {
+		return nil, fmt.Errorf("no tokens found in %s", file)
+	}
+	return tokens, nil
+}
+
+// requireToken returns a handler which serves requests with one of the
+// given bearer tokens with h, and rejects all others.
+func requireToken(h http.Handler, tokens [][]byte) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		auth, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if ok {
+			for _, t := range tokens {
+				// Compare in constant time, so as not to reveal how
+				// much of a token matched.
+				if subtle.ConstantTimeCompare([]byte(auth), t) == 1 {
+					h.ServeHTTP(w, r)
+					return
+				}
+			}
+		}
+		w.Header().Set("WWW-Authenticate", "Bearer")
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+	})
+}
+
+// maxAdmissionReview limits the size of an AdmissionReview request.
+// The API server limits objects to a few megabytes.
+const maxAdmissionReview = 16 << 20
+
+// admissionReview is an AdmissionReview of the admission.k8s.io/v1 API.
+type admissionReview struct {
+	APIVersion string             `json:"apiVersion"`
+	Kind       string             `json:"kind"`
+	Request    *admissionRequest  `json:"request,omitempty"`
+	Response   *admissionResponse `json:"response,omitempty"`
+}
+
+type admissionRequest struct {
+	UID  string `json:"uid"`
+	Kind struct {
+		Group   string `json:"group"`
+		Version string `json:"version"`
+		Kind    string `json:"kind"`
+	} `json:"kind"`
+	Name      string          `json:"name"`
+	Namespace string          `json:"namespace"`
+	Operation string          `json:"operation"`
+	Object    json.RawMessage `json:"object"`
+}
+
+type admissionResponse struct {
+	UID      string           `json:"uid"`
+	Allowed  bool             `json:"allowed"`
+	Status   *admissionStatus `json:"status,omitempty"`
+	Warnings []string         `json:"warnings,omitempty"`
+}
+
+type admissionStatus struct {
+	Code    int    `json:"code,omitempty"`
+	Message string `json:"message,omitempty"`
+}
+
+// admissionHandler serves the validating admission webhook protocol.
+type admissionHandler struct {
+	served servedState
+	log    io.Writer // if not nil, decisions are logged to log
+}
+
+func (h *admissionHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	var review admissionReview
+	d := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxAdmissionReview))
+	if err := d.Decode(&review); err != nil {
+		http.Error(w, fmt.Sprintf("invalid AdmissionReview: %v", err), http.StatusBadRequest)
+		return
+	}
+	if review.Request == nil {
+		http.Error(w, "invalid AdmissionReview: missing request", http.StatusBadRequest)
+		return
+	}
+	resp := h.review(review.Request)
+	if h.log != nil {
+		req := review.Request
+		decision := "allowed"
+		if !resp.Allowed {
+			decision = "denied"
+		}
+		fmt.Fprintf(h.log, "%s %s %s/%s: %s\n", req.Operation, req.Kind.Kind, req.Namespace, req.Name, decision)
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(admissionReview{
+		APIVersion: cmp.Or(review.APIVersion, "admission.k8s.io/v1"),
+		Kind:       "AdmissionReview",
+		Response:   resp,
+	})
+}
+
+// review validates the object of req.
+func (h *admissionHandler) review(req *admissionRequest) *admissionResponse {
+	resp := &admissionResponse{UID: req.UID, Allowed: true}
+	if len(req.Object) == 0 || string(req.Object) == "null" {
+		// Deletions have no object to validate.
+		return resp
+	}
+	deny := func(code int, err error) *admissionResponse {
+		resp.Allowed = false
+		resp.Status = &admissionStatus{Code: code, Message: admissionMessage(err)}
+		return resp
+	}
+	expr, err := cuejson.Extract("object", req.Object)
+	if err != nil {
+		return deny(http.StatusBadRequest, err)
+	}
+
+	var objErr error
+	var errs, warnings cueerrors.Error
+	err = h.served.use(func(p *servedPackage) {
+		obj := p.ctx.BuildExpr(expr)
+		if objErr = obj.Err(); objErr != nil {
+			return
+		}
+		// Without a schema selected with -d, objects are validated against
+		// the field of the package named after their kind.
+		schema := p.schema
+		if !schema.Exists() {
+			schema = schemaForKind(p.pkg, req.Kind.Kind)
+		}
+		errs, warnings = checkObject(schema, obj, p.policies, true)
+	})
+	if err != nil {
+		return deny(http.StatusInternalServerError, err)
+	}
+	if objErr != nil {
+		return deny(http.StatusBadRequest, objErr)
+	}
+	for _, e := range cueerrors.Errors(warnings) {
+		resp.Warnings = append(resp.Warnings, e.Error())
+	}
+	if errs != nil {
+		return deny(http.StatusForbidden, errs)
+	}
+	return resp
+}
+
+// checkObject validates obj against schema, if it exists, and checks it
+// against policies. It returns errors and warnings separately.
+func checkObject(schema, obj cue.Value, policies []policyRule, concrete bool) (errs, warnings cueerrors.Error) {
+	v := obj
+	if schema.Exists() {
+		v = schema.Unify(obj)
+	}
+	errs, warnings = splitWarnings(v, v.Validate(cue.Concrete(concrete)))
+	// Check the policies against the object as it was submitted, so that
+	// schema errors are not reported again as policy violations.
+	for _, rule := range policies {
+		violations := rule.check(obj, concrete)
+		if rule.severity == severityWarning {
+			warnings = cueerrors.Append(warnings, violations)
+		} else {
+			errs = cueerrors.Append(errs, violations)
+		}
+	}
+	return errs, warnings
+}
+
+// schemaForKind returns the field of v named after kind, either as a
+// regular field or a definition, if any.
+func schemaForKind(v cue.Value, kind string) cue.Value {
+	if kind == "" || !ast.IsValidIdent(kind) {
+		return cue.Value{}
+	}
+	if f := v.LookupPath(cue.MakePath(cue.Str(kind))); f.Exists() {
+		return f
+	}
+	return v.LookupPath(cue.MakePath(cue.Def("#" + kind)))
+}
+
+// admissionMessage returns the message for a denied object, listing
+// each error on its own line.
+func admissionMessage(err error) string {
+	var b strings.Builder
+	for i, e := range cueerrors.Errors(err) {
+		if i > 0 {
+			b.WriteString("\n")
+		}
+		b.WriteString(e.Error())
+	}
+	return b.String()
+}
@@ -0,0 +1,462 @@
+// Copyright 2025 CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"strconv"
+	"testing"
+
+	"cuelang.org/go/cue"
+	"cuelang.org/go/cue/build"
+	"cuelang.org/go/cue/errors"
+	"cuelang.org/go/cue/token"
+	"cuelang.org/go/internal"
+	"cuelang.org/go/internal/cueversion"
+	"cuelang.org/go/internal/encoding"
+	"cuelang.org/go/internal/filetypes"
+)
+
+// maxRESTBody limits the size of the body of a REST request.
+const maxRESTBody = 16 << 20
+
+// restHandler serves the REST API of serve --rest, which is described by
+// the OpenAPI document returned by [restHandler.openAPI].
+type restHandler struct {
+	served servedState
+	auth   bool // whether requests require a bearer token
+	cfg    *errors.Config
+}
+
+// newRESTHandler returns the handler for serve --rest, which serves the
+// package given by args.
+func newRESTHandler(cmd *Command, args []string) (*restHandler, error) {
+	h := &restHandler{
+		cfg: &errors.Config{
+			Cwd:     rootWorkingDir(),
+			ToSlash: testing.Testing(),
+		},
+	}
+	h.served.load = func() (*servedPackage, error) {
+		return loadServed(cmd, args)
+	}
+	// Load the package upfront, so that errors in it are reported on
+	// startup rather than by the first request.
+	if _, err := h.served.get(); err != nil {
+		return nil, err
+	}
+	return h, nil
+}
+
+func (h *restHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	var serve http.HandlerFunc
+	method := http.MethodPost
+	switch r.URL.Path {
+	case "/v1/validate":
+		serve = h.validate
+	case "/v1/export":
+		serve = h.export
+	case "/v1/query":
+		serve, method = h.query, http.MethodGet
+	case "/openapi.json":
+		serve, method = h.openAPI, http.MethodGet
+	default:
+		http.NotFound(w, r)
+		return
+	}
+	if r.Method != method {
+		w.Header().Set("Allow", method)
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	serve(w, r)
+}
+
+// restValidation is the response of /v1/validate.
+type restValidation struct {
+	Valid       bool             `json:"valid"`
+	Diagnostics []jsonDiagnostic `json:"diagnostics,omitempty"`
+}
+
+// restError is the response of a failed request.
+type restError struct {
+	Error       string           `json:"error"`
+	Diagnostics []jsonDiagnostic `json:"diagnostics,omitempty"`
+}
+
+// validate validates the request body against the schema given by the
+// schema parameter, the schema selected with -d, or the package.
+func (h *restHandler) validate(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	concrete := true
+	if s := q.Get("concrete"); s != "" {
+		var err error
+		if concrete, err = strconv.ParseBool(s); err != nil {
+			h.fail(w, http.StatusBadRequest, fmt.Errorf("invalid concrete parameter %q", s))
+			return
+		}
+	}
+
+	h.use(w, func(p *servedPackage) {
+		obj, status, err := h.decodeBody(p.ctx, r)
+		if err == nil && !obj.Exists() {
+			status, err = http.StatusBadRequest, fmt.Errorf("missing request body")
+		}
+		if err != nil {
+			h.fail(w, status, err)
+			return
+		}
+		schema := p.schema
+		if s := q.Get("schema"); s != "" {
+			if schema, err = evalServeExpr(p.ctx, p.pkg, "schema", s); err != nil {
+				h.fail(w, http.StatusUnprocessableEntity, err)
+				return
+			}
+		} else if !schema.Exists() {
+			schema = p.pkg
+		}
+		errs, warnings := checkObject(schema, obj, p.policies, concrete)
+		resp := restValidation{Valid: errs == nil}
+		resp.Diagnostics = append(h.diagnostics(errs, severityError), h.diagnostics(warnings, severityWarning)...)
+		writeRESTJSON(w, http.StatusOK, resp)
+	})
+}
+
+// export exports the package, unified with the request body, if any.
+func (h *restHandler) export(w http.ResponseWriter, r *http.Request) {
+	h.use(w, func(p *servedPackage) {
+		v := p.pkg
+		obj, status, err := h.decodeBody(p.ctx, r)
+		if err != nil {
+			h.fail(w, status, err)
+			return
+		}
+		if obj.Exists() {
+			v = v.Unify(obj)
+		}
+		h.encode(w, r, p.ctx, v)
+	})
+}
+
+// query exports the value of the expression given by the expression
+// parameter.
+func (h *restHandler) query(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Query().Get("expression") == "" {
+		h.fail(w, http.StatusBadRequest, fmt.Errorf("missing expression parameter"))
+		return
+	}
+	h.use(w, func(p *servedPackage) {
+		h.encode(w, r, p.ctx, p.pkg)
+	})
+}
+
+// use calls f with the served package, failing the request if the
+// package cannot be loaded.
+func (h *restHandler) use(w http.ResponseWriter, f func(p *servedPackage)) {
+	if err := h.served.use(f); err != nil {
+		h.fail(w, http.StatusInternalServerError, err)
+	}
+}
+
+// encode writes v, or the value of the expression parameter evaluated
+// within v, in the encoding given by the out parameter. The value v must
+// belong to ctx.
+func (h *restHandler) encode(w http.ResponseWriter, r *http.Request, ctx *cue.Context, v cue.Value) {
+	q := r.URL.Query()
+	out := q.Get("out")
+	if out == "" {
+		out = "json"
+	}
+	f, err := filetypes.ParseFile(out+":-", filetypes.Export)
+	if err != nil {
+		h.fail(w, http.StatusBadRequest, fmt.Errorf("invalid out parameter: %v", err))
+		return
+	}
+	if s := q.Get("expression"); s != "" {
+		if v, err = evalServeExpr(ctx, v, "expression", s); err != nil {
+			h.fail(w, http.StatusUnprocessableEntity, err)
+			return
+		}
+	}
+	var buf bytes.Buffer
+	enc, err := encoding.NewEncoder(ctx, f, &encoding.Config{
+		Mode: filetypes.Export,
+		Out:  &buf,
+	})
+	if err == nil {
+		err = enc.Encode(v)
+	}
+	if err == nil {
+		err = enc.Close()
+	}
+	if err != nil {
+		h.fail(w, http.StatusUnprocessableEntity, err)
+		return
+	}
+	w.Header().Set("Content-Type", restContentType(f.Encoding))
+	w.Write(buf.Bytes())
+}
+
+// restContentType returns the media type for values in encoding e.
+func restContentType(e build.Encoding) string {
+	switch e {
+	case build.JSON:
+		return "application/json"
+	case build.JSONL:
+		return "application/jsonl"
+	case build.YAML:
+		return "application/yaml"
+	case build.TOML:
+		return "application/toml"
+	}
+	return "text/plain; charset=utf-8"
+}
+
+// decodeBody decodes the value in the body of r, if there is one, within
+// ctx. Its encoding is given by the encoding parameter or the Content-Type
+// header. If decoding fails, decodeBody also returns the status of the
+// response.
+func (h *restHandler) decodeBody(ctx *cue.Context, r *http.Request) (cue.Value, int, error) {
+	data, err := io.ReadAll(http.MaxBytesReader(nil, r.Body, maxRESTBody))
+	if err != nil {
+		return cue.Value{}, http.StatusRequestEntityTooLarge, err
+	}
+	if len(data) == 0 {
+		return cue.Value{}, 0, nil
+	}
+	enc := r.URL.Query().Get("encoding")
+	if enc == "" {
+		mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+		switch mediaType {
+		case "", "application/json":
+			enc = "json"
+		case "application/yaml", "application/x-yaml", "text/yaml":
+			enc = "yaml"
+		case "application/toml":
+			enc = "toml"
+		default:
+			return cue.Value{}, http.StatusUnsupportedMediaType, fmt.Errorf("unsupported content type %q", mediaType)
+		}
+	}
+	f, err := filetypes.ParseFileAndType("body", enc, filetypes.Input)
+	if err != nil {
+		return cue.Value{}, http.StatusBadRequest, fmt.Errorf("invalid encoding parameter: %v", err)
+	}
+	f.Source = data
+	d := encoding.NewDecoder(ctx, f, &encoding.Config{Mode: filetypes.Input})
+	defer d.Close()
+	if err := d.Err(); err != nil {
+		return cue.Value{}, http.StatusUnprocessableEntity, err
+	}
+	// Build the body as an expression, as the context keeps every file
+	// built within it. Only CUE with imports needs to be built as a file.
+	var v cue.Value
+	if file := d.File(); len(file.Imports) == 0 {
+		v = ctx.BuildExpr(internal.ToExpr(file))
+	} else {
+		v = ctx.BuildFile(file)
+	}
+	if d.Next(); d.Err() == nil && !d.Done() {
+		err = errors.Newf(token.NoPos, "request body contains more than one value")
+	} else if err = d.Err(); err == nil {
+		err = v.Err()
+	}
+	if err != nil {
+		return cue.Value{}, http.StatusUnprocessableEntity, err
+	}
+	return v, 0, nil
+}
+
+// diagnostics returns the diagnostics for err, like --diagnostics=json.
+func (h *restHandler) diagnostics(err errors.Error, severity string) []jsonDiagnostic {
+	if err == nil {
+		return nil
+	}
+	var diags []jsonDiagnostic
+	for _, e := range errors.Errors(errors.Sanitize(errorList(errors.Errors(err)))) {
+		diags = append(diags, newJSONDiagnostic(e, severity, h.cfg))
+	}
+	return diags
+}
+
+func (h *restHandler) fail(w http.ResponseWriter, status int, err error) {
+	resp := restError{Error: err.Error()}
+	if status == http.StatusUnprocessableEntity {
+		resp.Error = "invalid value"
+		resp.Diagnostics = h.diagnostics(errors.Promote(err, ""), severityError)
+	}
+	writeRESTJSON(w, status, resp)
+}
+
+func writeRESTJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	enc := json.NewEncoder(w)
+	enc.SetEscapeHTML(false)
+	enc.Encode(v)
+}
+
+// openAPI serves the OpenAPI document describing the API.
+func (h *restHandler) openAPI(w http.ResponseWriter, r *http.Request) {
+	writeRESTJSON(w, http.StatusOK, h.openAPIDocument())
+}
+
+func (h *restHandler) openAPIDocument() map[string]any {
+	type obj = map[string]any
+	ref := func(name string) obj {
+		return obj{"$ref": "#/components/schemas/" + name}
+	}
+	param := func(name, description string, required bool) obj {
+		return obj{
+			"name":        name,
+			"in":          "query",
+			"description": description,
+			"required":    required,
+			"schema":      obj{"type": "string"},
+		}
+	}
+	encodingParam := param("encoding", "Encoding of the request body, such as json, yaml or toml, if not given by its Content-Type.", false)
+	outParam := param("out", "Encoding of the response, such as json, yaml, toml or cue. Defaults to json.", false)
+	body := obj{
+		"description": "A value encoded as JSON, YAML or TOML.",
+		"content": obj{
+			"application/json": obj{"schema": obj{}},
+			"application/yaml": obj{"schema": obj{"type": "string"}},
+			"application/toml": obj{"schema": obj{"type": "string"}},
+		},
+	}
+	errorResponse := func(description string) obj {
+		return obj{
+			"description": description,
+			"content":     obj{"application/json": obj{"schema": ref("Error")}},
+		}
+	}
+	valueResponses := obj{
+		"200": obj{
+			"description": "The value in the requested encoding.",
+			"content": obj{
+				"application/json":          obj{"schema": obj{}},
+				"application/yaml":          obj{"schema": obj{"type": "string"}},
+				"application/toml":          obj{"schema": obj{"type": "string"}},
+				"text/plain; charset=utf-8": obj{"schema": obj{"type": "string"}},
+			},
+		},
+		"400": errorResponse("The request is invalid."),
+		"422": errorResponse("The value is invalid or cannot be exported."),
+	}
+	position := obj{
+		"type": "object",
+		"properties": obj{
+			"file":   obj{"type": "string"},
+			"line":   obj{"type": "integer"},
+			"column": obj{"type": "integer"},
+		},
+		"required": []string{"file"},
+	}
+	doc := obj{
+		"openapi": "3.0.3",
+		"info": obj{
+			"title":       "CUE",
+			"description": "Validates, exports and queries values of a CUE package.",
+			"version":     cueversion.ModuleVersion(),
+		},
+		"paths": obj{
+			"/v1/validate": obj{"post": obj{
+				"operationId": "validate",
+				"summary":     "Validate a value against a schema.",
+				"description": "Validates the request body against the schema given by the schema parameter, the schema selected when starting the server, or the package, and checks it against the policies of the server.",
+				"parameters": []obj{
+					param("schema", "CUE expression selecting the schema within the package, such as #Deployment.", false),
+					param("concrete", "Whether the value must be concrete. Defaults to true.", false),
+					encodingParam,
+				},
+				"requestBody": body,
+				"responses": obj{
+					"200": obj{
+						"description": "The result of the validation.",
+						"content":     obj{"application/json": obj{"schema": ref("Validation")}},
+					},
+					"400": errorResponse("The request is invalid."),
+					"422": errorResponse("The body or schema is invalid."),
+				},
+			}},
+			"/v1/export": obj{"post": obj{
+				"operationId": "export",
+				"summary":     "Export the package, unified with a value.",
+				"parameters": []obj{
+					param("expression", "CUE expression to export instead of the whole value.", false),
+					outParam,
+					encodingParam,
+				},
+				"requestBody": body,
+				"responses":   valueResponses,
+			}},
+			"/v1/query": obj{"get": obj{
+				"operationId": "query",
+				"summary":     "Export the value of an expression within the package.",
+				"parameters": []obj{
+					param("expression", "CUE expression to evaluate within the package.", true),
+					outParam,
+				},
+				"responses": valueResponses,
+			}},
+		},
+		"components": obj{
+			"schemas": obj{
+				"Validation": obj{
+					"type": "object",
+					"properties": obj{
+						"valid":       obj{"type": "boolean"},
+						"diagnostics": obj{"type": "array", "items": ref("Diagnostic")},
+					},
+					"required": []string{"valid"},
+				},
+				"Error": obj{
+					"type": "object",
+					"properties": obj{
+						"error":       obj{"type": "string"},
+						"diagnostics": obj{"type": "array", "items": ref("Diagnostic")},
+					},
+					"required": []string{"error"},
+				},
+				"Diagnostic": obj{
+					"type": "object",
+					"properties": obj{
+						"severity":  obj{"type": "string", "enum": []string{severityError, severityWarning}},
+						"code":      obj{"type": "string"},
+						"url":       obj{"type": "string"},
+						"message":   obj{"type": "string"},
+						"path":      obj{"type": "string"},
+						"positions": obj{"type": "array", "items": ref("Position")},
+					},
+					"required": []string{"severity", "code", "message"},
+				},
+				"Position": position,
+			},
+		},
+	}
+	if h.auth {
+		doc["components"].(obj)["securitySchemes"] = obj{
+			"bearer": obj{"type": "http", "scheme": "bearer"},
+		}
+		doc["security"] = []obj{{"bearer": []string{}}}
+	}
+	return doc
+}
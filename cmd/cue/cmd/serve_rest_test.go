@@ -0,0 +1,220 @@
+// Copyright 2025 CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/go-quicktest/qt"
+
+	"cuelang.org/go/cue"
+	"cuelang.org/go/cue/cuecontext"
+	"cuelang.org/go/cue/errors"
+	"cuelang.org/go/internal/core/adt"
+	"cuelang.org/go/internal/core/runtime"
+)
+
+func TestRESTHandler(t *testing.T) {
+	ctx := cuecontext.New()
+	pkg := ctx.CompileString(`
+#Service: {
+	name!: string
+	port:  int & >=1024 | *8080
+	replicas?: int & <=3 @severity(warning)
+}
+services: [string]: #Service
+services: web: name: "web"
+`, cue.Filename("schema.cue"))
+	qt.Assert(t, qt.IsNil(pkg.Err()))
+	h := &restHandler{cfg: &errors.Config{}}
+	h.served.pkg = &servedPackage{ctx: ctx, pkg: pkg}
+
+	do := func(method, target, contentType, body string) *httptest.ResponseRecorder {
+		t.Helper()
+		r := httptest.NewRequest(method, target, strings.NewReader(body))
+		if contentType != "" {
+			r.Header.Set("Content-Type", contentType)
+		}
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, r)
+		return w
+	}
+	validation := func(w *httptest.ResponseRecorder) restValidation {
+		t.Helper()
+		qt.Assert(t, qt.Equals(w.Code, http.StatusOK), qt.Commentf("%s", w.Body))
+		var v restValidation
+		qt.Assert(t, qt.IsNil(json.Unmarshal(w.Body.Bytes(), &v)))
+		return v
+	}
+
+	v := validation(do("POST", "/v1/validate?schema=%23Service", "application/json", `{"name": "api", "port": 9000}`))
+	qt.Check(t, qt.DeepEquals(v, restValidation{Valid: true}))
+
+	v = validation(do("POST", "/v1/validate?schema=%23Service", "application/yaml", "name: 1\nport: 9000\n"))
+	qt.Check(t, qt.IsFalse(v.Valid))
+	qt.Assert(t, qt.HasLen(v.Diagnostics, 1))
+	qt.Check(t, qt.Equals(v.Diagnostics[0].Severity, severityError))
+	qt.Check(t, qt.Equals(v.Diagnostics[0].Path, "#Service.name"))
+	qt.Check(t, qt.Equals(v.Diagnostics[0].Message, "conflicting values string and 1 (mismatched types string and int)"))
+	qt.Check(t, qt.DeepEquals(v.Diagnostics[0].Positions, []jsonDiagnosticPosition{
+		{File: "body", Line: 1, Column: 7},
+		{File: "schema.cue", Line: 3, Column: 9},
+	}))
+
+	// Warnings do not make a value invalid.
+	v = validation(do("POST", "/v1/validate?schema=%23Service&encoding=toml", "", "name = \"api\"\nreplicas = 5\n"))
+	qt.Check(t, qt.IsTrue(v.Valid))
+	qt.Assert(t, qt.HasLen(v.Diagnostics, 1))
+	qt.Check(t, qt.Equals(v.Diagnostics[0].Severity, severityWarning))
+
+	// Without a schema, values are validated against the package.
+	v = validation(do("POST", "/v1/validate", "", `{"services": {"db": {"port": 5432}}}`))
+	qt.Check(t, qt.IsFalse(v.Valid))
+	v = validation(do("POST", "/v1/validate?concrete=false", "", `{"services": {"db": {"port": 5432}}}`))
+	qt.Check(t, qt.IsTrue(v.Valid))
+
+	w := do("POST", "/v1/export?out=yaml", "application/json", `{"services": {"db": {"name": "db"}}}`)
+	qt.Check(t, qt.Equals(w.Code, http.StatusOK))
+	qt.Check(t, qt.Equals(w.Header().Get("Content-Type"), "application/yaml"))
+	qt.Check(t, qt.Equals(w.Body.String(), "services:\n  db:\n    name: db\n    port: 8080\n  web:\n    name: web\n    port: 8080\n"))
+
+	w = do("GET", "/v1/query?expression=services.web.port", "", "")
+	qt.Check(t, qt.Equals(w.Code, http.StatusOK))
+	qt.Check(t, qt.Equals(w.Body.String(), "8080\n"))
+
+	w = do("GET", "/v1/query?expression=services.web&out=cue", "", "")
+	qt.Check(t, qt.Equals(w.Code, http.StatusOK))
+	qt.Check(t, qt.Equals(w.Header().Get("Content-Type"), "text/plain; charset=utf-8"))
+
+	w = do("GET", "/openapi.json", "", "")
+	qt.Check(t, qt.Equals(w.Code, http.StatusOK))
+	var doc struct {
+		OpenAPI string                    `json:"openapi"`
+		Paths   map[string]map[string]any `json:"paths"`
+	}
+	qt.Assert(t, qt.IsNil(json.Unmarshal(w.Body.Bytes(), &doc)))
+	qt.Check(t, qt.Equals(doc.OpenAPI, "3.0.3"))
+	for path, method := range map[string]string{
+		"/v1/validate": "post",
+		"/v1/export":   "post",
+		"/v1/query":    "get",
+	} {
+		qt.Check(t, qt.IsNotNil(doc.Paths[path][method]), qt.Commentf("%s %s", method, path))
+	}
+
+	// Errors.
+	for _, test := range []struct {
+		method, target, contentType, body string
+		code                              int
+	}{
+		{"GET", "/v1/query", "", "", http.StatusBadRequest},
+		{"GET", "/v1/query?expression=services.db", "", "", http.StatusUnprocessableEntity},
+		{"GET", "/v1/query?expression=%23Service", "", "", http.StatusUnprocessableEntity},
+		{"GET", "/v1/query?expression=1&out=nope", "", "", http.StatusBadRequest},
+		{"POST", "/v1/validate", "", "", http.StatusBadRequest},
+		{"POST", "/v1/validate", "", `{"a": `, http.StatusUnprocessableEntity},
+		{"POST", "/v1/validate", "text/html", `<p>`, http.StatusUnsupportedMediaType},
+		{"POST", "/v1/validate?concrete=maybe", "", `{}`, http.StatusBadRequest},
+		{"POST", "/v1/validate?schema=%23Missing", "", `{}`, http.StatusUnprocessableEntity},
+		{"POST", "/v1/validate", "application/yaml", "a: 1\n---\na: 2\n", http.StatusUnprocessableEntity},
+		{"GET", "/v1/validate", "", "", http.StatusMethodNotAllowed},
+		{"GET", "/v2/validate", "", "", http.StatusNotFound},
+	} {
+		w := do(test.method, test.target, test.contentType, test.body)
+		qt.Check(t, qt.Equals(w.Code, test.code), qt.Commentf("%s %s: %s", test.method, test.target, w.Body))
+		if test.code == http.StatusUnprocessableEntity {
+			var e restError
+			qt.Check(t, qt.IsNil(json.Unmarshal(w.Body.Bytes(), &e)))
+			qt.Check(t, qt.Not(qt.HasLen(e.Diagnostics, 0)), qt.Commentf("%s %s", test.method, test.target))
+		}
+	}
+}
+
+func TestRESTHandlerLimit(t *testing.T) {
+	loads := 0
+	h := &restHandler{cfg: &errors.Config{}}
+	h.served.load = func() (*servedPackage, error) {
+		loads++
+		ctx := cuecontext.New()
+		(*runtime.Runtime)(ctx).SetLimits(adt.Limits{MaxNodes: 100})
+		pkg := ctx.CompileString(`[string]: string`)
+		return &servedPackage{ctx: ctx, pkg: pkg}, pkg.Err()
+	}
+	srv := recoverLimitHandler(h, &errors.Config{})
+	validate := func(body string) *httptest.ResponseRecorder {
+		t.Helper()
+		w := httptest.NewRecorder()
+		srv.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/v1/validate", strings.NewReader(body)))
+		return w
+	}
+
+	var body strings.Builder
+	body.WriteString("{")
+	for i := range 200 {
+		if i > 0 {
+			body.WriteString(", ")
+		}
+		fmt.Fprintf(&body, `"k%d": "v"`, i)
+	}
+	body.WriteString("}")
+	w := validate(body.String())
+	qt.Assert(t, qt.Equals(w.Code, http.StatusServiceUnavailable))
+	qt.Check(t, qt.StringContains(w.Body.String(), "evaluation exceeded the limit of 100 nodes"))
+
+	// The package is loaded anew for the next request, as the values of
+	// the context it was built in may be partially evaluated.
+	w = validate(`{"a": "b"}`)
+	qt.Assert(t, qt.Equals(w.Code, http.StatusOK), qt.Commentf("%s", w.Body))
+	qt.Check(t, qt.JSONEquals(w.Body.Bytes(), restValidation{Valid: true}))
+	qt.Check(t, qt.Equals(loads, 2))
+}
+
+func TestRequireToken(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "tokens")
+	err := os.WriteFile(file, []byte("# tokens\nsecret1\n\n  secret2  \n"), 0o666)
+	qt.Assert(t, qt.IsNil(err))
+	tokens, err := readTokens(file)
+	qt.Assert(t, qt.IsNil(err))
+	qt.Assert(t, qt.DeepEquals(tokens, [][]byte{[]byte("secret1"), []byte("secret2")}))
+
+	h := requireToken(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}), tokens)
+	for auth, code := range map[string]int{
+		"":               http.StatusUnauthorized,
+		"Bearer secret2": http.StatusOK,
+		"Bearer secret":  http.StatusUnauthorized,
+		"Basic secret1":  http.StatusUnauthorized,
+	} {
+		r := httptest.NewRequest("GET", "/", nil)
+		if auth != "" {
+			r.Header.Set("Authorization", auth)
+		}
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, r)
+		qt.Check(t, qt.Equals(w.Code, code), qt.Commentf("%q", auth))
+	}
+
+	err = os.WriteFile(file, []byte("# no tokens\n"), 0o666)
+	qt.Assert(t, qt.IsNil(err))
+	_, err = readTokens(file)
+	qt.Assert(t, qt.ErrorMatches(err, `no tokens found in .*`))
+}
@@ -0,0 +1,277 @@
+// Copyright 2025 CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+	"strings"
+	"sync/atomic"
+
+	"cuelang.org/go/cue"
+	cueerrors "cuelang.org/go/cue/errors"
+	"cuelang.org/go/tools/flow"
+)
+
+// serverSection is the field of the tool files which declares the
+// servers of serve --tasks.
+const serverSection = "server"
+
+// maxTaskBody limits the size of the body of a request to a route.
+const maxTaskBody = 16 << 20
+
+// A taskServer serves the routes of a server declared in the tool files,
+// running the tasks of a route for each request to it.
+type taskServer struct {
+	cmd  *Command
+	name string
+
+	// load builds the tool files in ctx. As a context may not be used
+	// concurrently, the tool files are built anew for each request, which
+	// also picks up any changes made to them while serving.
+	load func(ctx *cue.Context) (cue.Value, error)
+
+	// addr is the address to listen on declared by the server, if any.
+	addr string
+
+	// cfg configures how the errors of failing routes are printed.
+	cfg *cueerrors.Config
+
+	mux *http.ServeMux
+}
+
+// newTaskServer returns the handler for serve --tasks, which serves the
+// routes of the server with the given name.
+func newTaskServer(cmd *Command, name string, load func(ctx *cue.Context) (cue.Value, error)) (*taskServer, error) {
+	v, err := load(cmd.ctx)
+	if err != nil {
+		return nil, err
+	}
+	server := v.LookupPath(cue.MakePath(cue.Str(serverSection), cue.Str(name)))
+	if !server.Exists() {
+		return nil, fmt.Errorf("server %q not found in the tool files", name)
+	}
+	s := &taskServer{cmd: cmd, name: name, load: load, cfg: &cueerrors.Config{}, mux: http.NewServeMux()}
+	if addr := server.LookupPath(cue.MakePath(cue.Str("addr"))); addr.Exists() {
+		if s.addr, err = addr.String(); err != nil {
+			return nil, err
+		}
+	}
+
+	iter, err := server.LookupPath(cue.MakePath(cue.Str("route"))).Fields()
+	if err != nil {
+		return nil, err
+	}
+	n := 0
+	for iter.Next() {
+		pattern := iter.Selector().Unquoted()
+		if err := s.handle(pattern); err != nil {
+			return nil, fmt.Errorf("invalid route %q: %v", pattern, err)
+		}
+		n++
+	}
+	if n == 0 {
+		return nil, fmt.Errorf("server %q declares no routes", name)
+	}
+	return s, nil
+}
+
+// handle registers the route with the given pattern, reporting the
+// errors for which [http.ServeMux.HandleFunc] panics.
+func (s *taskServer) handle(pattern string) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("%v", r)
+		}
+	}()
+	params := patternParams(pattern)
+	s.mux.HandleFunc(pattern, func(w http.ResponseWriter, r *http.Request) {
+		s.serveRoute(w, r, pattern, params)
+	})
+	return nil
+}
+
+func (s *taskServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mux.ServeHTTP(w, r)
+}
+
+// serveRoute runs the tasks of the route with the given pattern for r,
+// and responds with the response of the route.
+func (s *taskServer) serveRoute(w http.ResponseWriter, r *http.Request, pattern string, params []string) {
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxTaskBody))
+	if err != nil {
+		status := http.StatusBadRequest
+		if _, ok := err.(*http.MaxBytesError); ok {
+			status = http.StatusRequestEntityTooLarge
+		}
+		http.Error(w, err.Error(), status)
+		return
+	}
+	v, err := s.load(newContext())
+	if err != nil {
+		s.fail(w, err)
+		return
+	}
+
+	route := []cue.Selector{cue.Str(serverSection), cue.Str(s.name), cue.Str("route"), cue.Str(pattern)}
+	field := func(name string) cue.Path {
+		return cue.MakePath(append(slices.Clip(route), cue.Str(name))...)
+	}
+	v = v.FillPath(field("request"), taskRequest(r, params, body))
+	cfg := &flow.Config{
+		Root:           cue.MakePath(route...),
+		InferTasks:     true,
+		IgnoreConcrete: true,
+	}
+	var didWork atomic.Bool
+	c := flow.New(cfg, v, newTaskFunc(s.cmd, &didWork))
+	// The context of the request is canceled when the client goes away
+	// or the server shuts down, which stops the tasks which are running.
+	if err := c.Run(r.Context()); err != nil {
+		s.fail(w, err)
+		return
+	}
+
+	resp, err := decodeTaskResponse(c.Value().LookupPath(field("response")))
+	if err != nil {
+		s.fail(w, err)
+		return
+	}
+	for k, v := range resp.header {
+		w.Header().Set(k, v)
+	}
+	w.WriteHeader(resp.status)
+	w.Write(resp.body)
+}
+
+// fail responds with the errors of running a route.
+func (s *taskServer) fail(w http.ResponseWriter, err error) {
+	var buf bytes.Buffer
+	cueerrors.Print(&buf, err, s.cfg)
+	http.Error(w, buf.String(), http.StatusInternalServerError)
+}
+
+// taskRequest returns the value of the request field of a route for r.
+func taskRequest(r *http.Request, params []string, body []byte) map[string]any {
+	values := map[string]string{}
+	for _, name := range params {
+		values[name] = r.PathValue(name)
+	}
+	query := map[string]string{}
+	for k := range r.URL.Query() {
+		query[k] = r.URL.Query().Get(k)
+	}
+	header := map[string]string{}
+	for k, v := range r.Header {
+		header[k] = strings.Join(v, ", ")
+	}
+	return map[string]any{
+		"method": r.Method,
+		"path":   r.URL.Path,
+		"params": values,
+		"query":  query,
+		"header": header,
+		"body":   string(body),
+	}
+}
+
+// patternParams returns the names of the wildcards of a route pattern,
+// such as id for "GET /items/{id}".
+func patternParams(pattern string) []string {
+	var names []string
+	for {
+		i := strings.IndexByte(pattern, '{')
+		if i < 0 {
+			return names
+		}
+		pattern = pattern[i+1:]
+		j := strings.IndexByte(pattern, '}')
+		if j < 0 {
+			return names
+		}
+		if name := strings.TrimSuffix(pattern[:j], "..."); name != "$" {
+			names = append(names, name)
+		}
+		pattern = pattern[j+1:]
+	}
+}
+
+// A taskResponse is the response of a route.
+type taskResponse struct {
+	status int
+	header map[string]string
+	body   []byte
+}
+
+// decodeTaskResponse decodes the response field of a route, which
+// responds with no content if it does not exist. A body which is neither
+// a string nor bytes is encoded as JSON.
+func decodeTaskResponse(v cue.Value) (*taskResponse, error) {
+	resp := &taskResponse{status: http.StatusOK, header: map[string]string{}}
+	if !v.Exists() {
+		resp.status = http.StatusNoContent
+		return resp, nil
+	}
+	if status := v.LookupPath(cue.MakePath(cue.Str("status"))); status.Exists() {
+		n, err := status.Int64()
+		if err != nil {
+			return nil, err
+		}
+		if n < 100 || n > 999 {
+			return nil, fmt.Errorf("invalid response status %d", n)
+		}
+		resp.status = int(n)
+	}
+	if header := v.LookupPath(cue.MakePath(cue.Str("header"))); header.Exists() {
+		iter, err := header.Fields()
+		if err != nil {
+			return nil, err
+		}
+		for iter.Next() {
+			s, err := iter.Value().String()
+			if err != nil {
+				return nil, err
+			}
+			resp.header[http.CanonicalHeaderKey(iter.Selector().Unquoted())] = s
+		}
+	}
+	body := v.LookupPath(cue.MakePath(cue.Str("body")))
+	if !body.Exists() {
+		return resp, nil
+	}
+	var err error
+	switch body.IncompleteKind() {
+	case cue.StringKind:
+		var s string
+		s, err = body.String()
+		resp.body = []byte(s)
+	case cue.BytesKind:
+		resp.body, err = body.Bytes()
+	default:
+		if resp.body, err = body.MarshalJSON(); err == nil {
+			resp.body = append(resp.body, '\n')
+			if _, ok := resp.header["Content-Type"]; !ok {
+				resp.header["Content-Type"] = "application/json"
+			}
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
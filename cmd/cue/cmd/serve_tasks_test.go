@@ -0,0 +1,132 @@
+// Copyright 2025 CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/go-quicktest/qt"
+	"github.com/spf13/cobra"
+
+	"cuelang.org/go/cue"
+	"cuelang.org/go/cue/cuecontext"
+)
+
+func TestTaskServer(t *testing.T) {
+	dir := t.TempDir()
+	qt.Assert(t, qt.IsNil(os.WriteFile(filepath.Join(dir, "a.txt"), []byte("contents of a"), 0o666)))
+
+	src := `
+import (
+	"path"
+	"tool/file"
+)
+
+dir: string
+
+server: api: {
+	addr: "localhost:0"
+	route: "GET /files/{name}": {
+		request: _
+		read: file.Read & {
+			filename: path.Join([dir, request.params.name])
+			contents: string
+		}
+		response: {
+			header: "content-type": "text/plain"
+			body: read.contents
+		}
+	}
+	route: "POST /echo": {
+		request: _
+		response: {
+			status: 201
+			body: {
+				method: request.method
+				query:  request.query
+				body:   request.body
+			}
+		}
+	}
+	route: "DELETE /files/{name}": {}
+}
+`
+	loads := 0
+	load := func(ctx *cue.Context) (cue.Value, error) {
+		loads++
+		v := ctx.CompileString(src).FillPath(cue.ParsePath("dir"), dir)
+		return v, v.Err()
+	}
+	cmd := &Command{Command: &cobra.Command{}, ctx: cuecontext.New()}
+	s, err := newTaskServer(cmd, "api", load)
+	qt.Assert(t, qt.IsNil(err))
+	qt.Assert(t, qt.Equals(s.addr, "localhost:0"))
+
+	do := func(method, target, body string) *http.Response {
+		t.Helper()
+		w := httptest.NewRecorder()
+		s.ServeHTTP(w, httptest.NewRequest(method, target, strings.NewReader(body)))
+		return w.Result()
+	}
+	read := func(resp *http.Response) string {
+		t.Helper()
+		b, err := io.ReadAll(resp.Body)
+		qt.Assert(t, qt.IsNil(err))
+		return string(b)
+	}
+
+	resp := do("GET", "/files/a.txt", "")
+	qt.Assert(t, qt.Equals(resp.StatusCode, http.StatusOK))
+	qt.Assert(t, qt.Equals(resp.Header.Get("Content-Type"), "text/plain"))
+	qt.Assert(t, qt.Equals(read(resp), "contents of a"))
+
+	// The errors of failing tasks are returned to the client.
+	resp = do("GET", "/files/b.txt", "")
+	qt.Assert(t, qt.Equals(resp.StatusCode, http.StatusInternalServerError))
+	qt.Assert(t, qt.StringContains(read(resp), "b.txt"))
+
+	resp = do("POST", "/echo?x=1&x=2", "hello")
+	qt.Assert(t, qt.Equals(resp.StatusCode, http.StatusCreated))
+	qt.Assert(t, qt.Equals(resp.Header.Get("Content-Type"), "application/json"))
+	qt.Assert(t, qt.Equals(read(resp), `{"method":"POST","query":{"x":"1"},"body":"hello"}`+"\n"))
+
+	resp = do("DELETE", "/files/a.txt", "")
+	qt.Assert(t, qt.Equals(resp.StatusCode, http.StatusNoContent))
+
+	resp = do("GET", "/echo", "")
+	qt.Assert(t, qt.Equals(resp.StatusCode, http.StatusMethodNotAllowed))
+
+	// Bodies which exceed the limit are rejected before running the tasks.
+	resp = do("POST", "/echo", strings.Repeat("x", maxTaskBody+1))
+	qt.Assert(t, qt.Equals(resp.StatusCode, http.StatusRequestEntityTooLarge))
+
+	// The tool files are loaded once to start the server, and once for
+	// each request to a route.
+	qt.Assert(t, qt.Equals(loads, 5))
+
+	_, err = newTaskServer(cmd, "missing", load)
+	qt.Assert(t, qt.ErrorMatches(err, `server "missing" not found in the tool files`))
+}
+
+func TestPatternParams(t *testing.T) {
+	qt.Assert(t, qt.DeepEquals(patternParams("GET /items/{id}/parts/{part...}"), []string{"id", "part"}))
+	qt.Assert(t, qt.DeepEquals(patternParams("/static/{$}"), []string(nil)))
+}
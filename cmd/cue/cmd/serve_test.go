@@ -0,0 +1,186 @@
+// Copyright 2025 CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-quicktest/qt"
+
+	"cuelang.org/go/cue"
+	"cuelang.org/go/cue/cuecontext"
+	cueerrors "cuelang.org/go/cue/errors"
+	"cuelang.org/go/internal/core/adt"
+	"cuelang.org/go/internal/core/runtime"
+)
+
+func TestAdmissionHandler(t *testing.T) {
+	ctx := cuecontext.New()
+	schema := ctx.CompileString(`
+#Deployment: {
+	kind!: "Deployment"
+	spec: replicas: int & <=5
+	spec: minReadySeconds?: int & <=60 @severity(warning)
+	...
+}
+ConfigMap: {
+	data?: [string]: string
+	...
+}
+`)
+	qt.Assert(t, qt.IsNil(schema.Err()))
+	pack := ctx.CompileString(`
+input: _
+rules: {
+	"no-latest": {
+		check: spec?: template?: spec?: containers?: [...{image?: !~":latest$"}]
+		message: "images must not use the latest tag"
+	}
+	"owner-label": {
+		severity: "warning"
+		check: metadata: labels: owner!: string
+		message: "objects should have an owner label"
+	}
+}
+`)
+	qt.Assert(t, qt.IsNil(pack.Err()))
+	var policies []policyRule
+	for _, id := range []string{"no-latest", "owner-label"} {
+		r, err := newPolicyRule(pack, id, pack.LookupPath(cue.ParsePath(`rules."`+id+`"`)))
+		qt.Assert(t, qt.IsNil(err))
+		policies = append(policies, r)
+	}
+	h := &admissionHandler{}
+	h.served.pkg = &servedPackage{ctx: ctx, pkg: schema, policies: policies}
+
+	review := func(kind, operation, object string) *admissionResponse {
+		t.Helper()
+		body := `{
+			"apiVersion": "admission.k8s.io/v1",
+			"kind": "AdmissionReview",
+			"request": {
+				"uid": "1234",
+				"kind": {"group": "apps", "version": "v1", "kind": "` + kind + `"},
+				"operation": "` + operation + `",
+				"object": ` + object + `
+			}
+		}`
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/", strings.NewReader(body)))
+		qt.Assert(t, qt.Equals(w.Code, http.StatusOK))
+		var resp admissionReview
+		qt.Assert(t, qt.IsNil(json.Unmarshal(w.Body.Bytes(), &resp)))
+		qt.Assert(t, qt.Equals(resp.Kind, "AdmissionReview"))
+		qt.Assert(t, qt.Equals(resp.Response.UID, "1234"))
+		return resp.Response
+	}
+
+	labels := `"metadata": {"labels": {"owner": "team"}}`
+
+	resp := review("Deployment", "CREATE", `{"kind": "Deployment", `+labels+`, "spec": {"replicas": 3}}`)
+	qt.Check(t, qt.DeepEquals(resp, &admissionResponse{UID: "1234", Allowed: true}))
+
+	resp = review("Deployment", "CREATE", `{"kind": "Deployment", `+labels+`, "spec": {"replicas": 10}}`)
+	qt.Check(t, qt.IsFalse(resp.Allowed))
+	qt.Check(t, qt.Equals(resp.Status.Code, http.StatusForbidden))
+	qt.Check(t, qt.Equals(resp.Status.Message, "#Deployment.spec.replicas: invalid value 10 (out of bound <=5)"))
+
+	resp = review("Deployment", "UPDATE", `{"kind": "Deployment", `+labels+`, "spec": {"replicas": 1, "template": {"spec": {"containers": [{"image": "app:latest"}]}}}}`)
+	qt.Check(t, qt.IsFalse(resp.Allowed))
+	qt.Check(t, qt.Matches(resp.Status.Message, `.*images must not use the latest tag \(policy no-latest\)`))
+
+	// Warnings do not deny the object.
+	resp = review("Deployment", "CREATE", `{"kind": "Deployment", `+labels+`, "spec": {"replicas": 1, "minReadySeconds": 100}}`)
+	qt.Check(t, qt.IsTrue(resp.Allowed))
+	qt.Check(t, qt.DeepEquals(resp.Warnings, []string{"#Deployment.spec.minReadySeconds: invalid value 100 (out of bound <=60)"}))
+
+	resp = review("ConfigMap", "CREATE", `{"kind": "ConfigMap", "data": {"a": "b"}}`)
+	qt.Check(t, qt.IsTrue(resp.Allowed))
+	qt.Check(t, qt.DeepEquals(resp.Warnings, []string{"objects should have an owner label (policy owner-label)"}))
+
+	resp = review("ConfigMap", "CREATE", `{"kind": "ConfigMap", `+labels+`, "data": {"a": 1}}`)
+	qt.Check(t, qt.IsFalse(resp.Allowed))
+
+	// Kinds without a schema are only checked against the policies.
+	resp = review("Secret", "CREATE", `{"kind": "Secret", `+labels+`}`)
+	qt.Check(t, qt.IsTrue(resp.Allowed))
+
+	// Deletions have no object.
+	resp = review("Deployment", "DELETE", `null`)
+	qt.Check(t, qt.IsTrue(resp.Allowed))
+
+	// Invalid requests are rejected.
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+	qt.Check(t, qt.Equals(w.Code, http.StatusMethodNotAllowed))
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`{"kind": "AdmissionReview"}`)))
+	qt.Check(t, qt.Equals(w.Code, http.StatusBadRequest))
+}
+
+func TestAdmissionHandlerLimit(t *testing.T) {
+	loads := 0
+	h := &admissionHandler{}
+	h.served.load = func() (*servedPackage, error) {
+		loads++
+		ctx := cuecontext.New()
+		(*runtime.Runtime)(ctx).SetLimits(adt.Limits{MaxNodes: 100})
+		pkg := ctx.CompileString(`ConfigMap: data?: [string]: string`)
+		return &servedPackage{ctx: ctx, pkg: pkg}, pkg.Err()
+	}
+	srv := recoverLimitHandler(h, &cueerrors.Config{})
+
+	review := func(object string) *httptest.ResponseRecorder {
+		t.Helper()
+		body := `{
+			"apiVersion": "admission.k8s.io/v1",
+			"kind": "AdmissionReview",
+			"request": {
+				"uid": "1234",
+				"kind": {"version": "v1", "kind": "ConfigMap"},
+				"operation": "CREATE",
+				"object": ` + object + `
+			}
+		}`
+		w := httptest.NewRecorder()
+		srv.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/", strings.NewReader(body)))
+		return w
+	}
+
+	var data strings.Builder
+	for i := range 200 {
+		if i > 0 {
+			data.WriteString(", ")
+		}
+		fmt.Fprintf(&data, `"k%d": "v"`, i)
+	}
+	w := review(`{"kind": "ConfigMap", "data": {` + data.String() + `}}`)
+	qt.Assert(t, qt.Equals(w.Code, http.StatusServiceUnavailable))
+	qt.Check(t, qt.StringContains(w.Body.String(), "evaluation exceeded the limit of 100 nodes"))
+
+	// The package is loaded anew for the next request, as the values of
+	// the context it was built in may be partially evaluated.
+	w = review(`{"kind": "ConfigMap", "data": {"a": "b"}}`)
+	qt.Assert(t, qt.Equals(w.Code, http.StatusOK), qt.Commentf("%s", w.Body))
+	var resp admissionReview
+	qt.Assert(t, qt.IsNil(json.Unmarshal(w.Body.Bytes(), &resp)))
+	qt.Check(t, qt.IsTrue(resp.Response.Allowed))
+	qt.Check(t, qt.Equals(loads, 2))
+}
@@ -0,0 +1,246 @@
+// Copyright 2025 The CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"maps"
+	"net/http"
+	"os"
+	"slices"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+
+	"cuelang.org/go/cue/errors"
+	"cuelang.org/go/internal/core/adt"
+	"cuelang.org/go/internal/cuetelemetry"
+	"cuelang.org/go/internal/cueversion"
+)
+
+func newTelemetryCmd(c *Command) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "telemetry",
+		Short: "manage the recording of usage counters",
+		Long: `
+Telemetry records counters of how the cue command is used, to help the
+maintainers of CUE, and the platform teams which provide it to their users,
+understand how it is used in practice. It is off by default, and nothing is
+recorded or sent anywhere unless you opt in.
+
+Counters only record which commands and flags are used, the codes of the
+errors which are reported, the cue version, and how much work evaluations
+take in coarse buckets. They never record file names, CUE values, flag
+values, or the names of user-defined commands.
+
+The telemetry mode is one of:
+
+	off	record nothing; the default
+	local	record counters in the telemetry directory
+	on	record counters, and allow uploading them with 'cue telemetry upload'
+
+Counters are kept in one file per week under the telemetry directory in
+$CUE_CONFIG_DIR; see 'cue help environment'. Use 'cue telemetry view' to
+see them.
+
+Without a subcommand, the current mode and the telemetry directory are shown.
+`[1:],
+		Args: cobra.NoArgs,
+		RunE: mkRunE(c, func(cmd *Command, args []string) error {
+			dir, mode, err := telemetryMode()
+			if err != nil {
+				return err
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "mode: %s\ndirectory: %s\n", mode, dir)
+			return nil
+		}),
+	}
+	for _, mode := range []cuetelemetry.Mode{cuetelemetry.ModeOn, cuetelemetry.ModeLocal, cuetelemetry.ModeOff} {
+		cmd.AddCommand(newTelemetryModeCmd(c, mode))
+	}
+	cmd.AddCommand(
+		newTelemetryViewCmd(c),
+		newTelemetryUploadCmd(c),
+	)
+	return cmd
+}
+
+var telemetryModeShort = map[cuetelemetry.Mode]string{
+	cuetelemetry.ModeOn:    "record usage counters and allow uploading them",
+	cuetelemetry.ModeLocal: "record usage counters locally only",
+	cuetelemetry.ModeOff:   "stop recording usage counters",
+}
+
+func newTelemetryModeCmd(c *Command, mode cuetelemetry.Mode) *cobra.Command {
+	return &cobra.Command{
+		Use:   string(mode),
+		Short: telemetryModeShort[mode],
+		Long: fmt.Sprintf(`
+Set the telemetry mode to %q; see 'cue help telemetry'.
+Counters which were already recorded are kept.
+`[1:], mode),
+		Args: cobra.NoArgs,
+		RunE: mkRunE(c, func(cmd *Command, args []string) error {
+			dir, err := cuetelemetry.Dir(os.Getenv)
+			if err != nil {
+				return err
+			}
+			if err := cuetelemetry.WriteMode(dir, mode); err != nil {
+				return fmt.Errorf("cannot set telemetry mode: %v", err)
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "telemetry mode set to %s\n", mode)
+			return nil
+		}),
+	}
+}
+
+func newTelemetryViewCmd(c *Command) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "view",
+		Short: "show the recorded usage counters",
+		Long: `
+Show the counters recorded for each week which have not been uploaded.
+Use --out json to print the reports as they would be uploaded.
+`[1:],
+		Args: cobra.NoArgs,
+		RunE: mkRunE(c, func(cmd *Command, args []string) error {
+			dir, err := cuetelemetry.Dir(os.Getenv)
+			if err != nil {
+				return err
+			}
+			reports, err := cuetelemetry.Reports(dir)
+			if err != nil {
+				return err
+			}
+			w := cmd.OutOrStdout()
+			switch out := flagOut.String(cmd); out {
+			case "json":
+				enc := json.NewEncoder(w)
+				enc.SetEscapeHTML(false)
+				enc.SetIndent("", "\t")
+				if reports == nil {
+					reports = []*cuetelemetry.Report{}
+				}
+				return enc.Encode(reports)
+			case "text":
+			default:
+				return fmt.Errorf("unknown --out %q; must be text or json", out)
+			}
+			if len(reports) == 0 {
+				fmt.Fprintln(w, "no recorded counters")
+				return nil
+			}
+			tw := tabwriter.NewWriter(w, 0, 8, 2, ' ', 0)
+			for i, report := range reports {
+				if i > 0 {
+					fmt.Fprintln(tw)
+				}
+				fmt.Fprintf(tw, "week of %s (%s/%s)\n", report.Week, report.GOOS, report.GOARCH)
+				for _, name := range slices.Sorted(maps.Keys(report.Counters)) {
+					fmt.Fprintf(tw, "  %s\t%d\n", name, report.Counters[name])
+				}
+			}
+			return tw.Flush()
+		}),
+	}
+	cmd.Flags().String(string(flagOut), "text", "output format: text or json")
+	return cmd
+}
+
+func newTelemetryUploadCmd(c *Command) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "upload",
+		Short: "upload the usage counters of past weeks",
+		Long: `
+Upload the counters of each week which is over, and which was not uploaded
+yet, as a JSON report in the form shown by 'cue telemetry view --out json'.
+Each report is sent in a POST request to the URL given with --url, or by
+$CUE_TELEMETRY_URL, such as a collector run by a platform team.
+
+Uploading requires the telemetry mode to be "on".
+`[1:],
+		Args: cobra.NoArgs,
+		RunE: mkRunE(c, func(cmd *Command, args []string) error {
+			url := flagURL.String(cmd)
+			if url == "" {
+				url = os.Getenv("CUE_TELEMETRY_URL")
+			}
+			if url == "" {
+				return fmt.Errorf("no URL to upload to; use --url or set CUE_TELEMETRY_URL")
+			}
+			dir, err := cuetelemetry.Dir(os.Getenv)
+			if err != nil {
+				return err
+			}
+			client := &http.Client{
+				Transport: cueversion.NewTransport("cmd/cue", http.DefaultTransport),
+			}
+			n, err := cuetelemetry.Upload(cmd.Context(), client, dir, url, time.Now())
+			if err != nil {
+				return err
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "uploaded %d reports\n", n)
+			return nil
+		}),
+	}
+	cmd.Flags().String(string(flagURL), "", "URL to upload the reports to")
+	return cmd
+}
+
+// telemetryMode returns the telemetry directory and its mode.
+func telemetryMode() (string, cuetelemetry.Mode, error) {
+	dir, err := cuetelemetry.Dir(os.Getenv)
+	if err != nil {
+		return "", "", err
+	}
+	mode, err := cuetelemetry.ReadMode(dir)
+	return dir, mode, err
+}
+
+// countUsage records the command being run and the names of the flags
+// which were set. The names of user-defined commands are not recorded,
+// as they are chosen by users.
+func countUsage(c *Command, cmd *cobra.Command) {
+	var path []string
+	for p := cmd; p != nil && p != c.root; p = p.Parent() {
+		if p == c.cmdCmd {
+			path = []string{"cmd"}
+			break
+		}
+		path = append([]string{p.Name()}, path...)
+	}
+	cuetelemetry.Inc("cmd:" + strings.Join(path, "/"))
+	cmd.Flags().Visit(func(f *pflag.Flag) {
+		cuetelemetry.Inc("flag:" + f.Name)
+	})
+}
+
+// countEvalStats records the amount of evaluation work done.
+func countEvalStats() {
+	counts := adt.TotalStats()
+	cuetelemetry.Inc(cuetelemetry.Bucket("eval/unifications", counts.Unifications))
+	cuetelemetry.Inc(cuetelemetry.Bucket("eval/disjuncts", counts.Disjuncts))
+}
+
+// countErrors records the codes of the reported errors.
+func countErrors(errs []errors.Error) {
+	for _, err := range errs {
+		cuetelemetry.Inc("error:" + diagnosticRuleFor(err).id)
+	}
+}
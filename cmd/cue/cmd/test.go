@@ -0,0 +1,251 @@
+// Copyright 2025 The CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"cuelang.org/go/cue"
+	"cuelang.org/go/cue/ast"
+	"cuelang.org/go/cue/build"
+	"cuelang.org/go/cue/errors"
+)
+
+func newTestCmd(c *Command) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "test [packages]",
+		Short: "evaluate the test files of packages",
+		Long: `
+test loads the given packages, which default to the package in the current
+directory, together with their test files, the files whose names end in
+_test.cue, and reports whether each of their test cases passes.
+
+Each regular field declared at the top level of a test file is a test case,
+named after its label, while definitions and hidden fields can be used as
+helpers. As test files are part of their package, test cases can refer to
+the fields and definitions of the package:
+
+	// port_test.cue
+	package server
+
+	defaultPort: #Server & {}
+	customPort: #Server & {port: 8443}
+
+A test case passes if its value evaluates without errors and is concrete,
+such that a test case which conflicts with a constraint of the package
+fails. The package itself must evaluate without errors as well.
+
+For each package, test prints the test cases which fail along with their
+errors, followed by a line reporting whether the package passed. With -v,
+it also prints the name of each test case as it runs, and whether it
+passed. The --run flag selects the test cases to run with a regular
+expression matched against their names. With --json, test instead prints
+a stream of JSON objects, one per line, each describing an event: the
+start of a test case ("run"), its result ("pass" or "fail") along with
+its errors, or the result of a package.
+
+test fails if any test case or package fails.
+
+For example:
+
+	$ cue test -v ./...
+	=== RUN   defaultPort
+	--- PASS: defaultPort
+	=== RUN   customPort
+	--- PASS: customPort
+	ok  	example.com/server
+`[1:],
+		RunE: mkRunE(c, runTest),
+	}
+	cmd.Flags().String(string(flagRun), "", "only run the test cases whose names match this regular expression")
+	cmd.Flags().Bool(string(flagJSON), false, "print test events as a stream of JSON objects")
+	return cmd
+}
+
+// A testEvent is an event printed by cue test --json.
+type testEvent struct {
+	Action  string // run, pass, fail, or skip
+	Package string
+	Test    string `json:",omitempty"`
+	Output  string `json:",omitempty"` // the errors of a failure
+}
+
+// A testReporter prints the results of cue test.
+type testReporter struct {
+	cmd     *Command
+	w       io.Writer
+	json    bool
+	verbose bool
+	failed  bool
+}
+
+func runTest(cmd *Command, args []string) error {
+	var run *regexp.Regexp
+	if s := flagRun.String(cmd); s != "" {
+		var err error
+		if run, err = regexp.Compile(s); err != nil {
+			return fmt.Errorf("invalid --%s: %v", flagRun, err)
+		}
+	}
+	cfg, err := defaultConfig()
+	if err != nil {
+		return err
+	}
+	cfg.loadCfg.Tests = true
+	binsts := loadFromArgs(args, cfg.loadCfg)
+	if len(binsts) == 0 {
+		return fmt.Errorf("no packages to test")
+	}
+	for _, b := range binsts {
+		if b.Err != nil {
+			return b.Err
+		}
+	}
+
+	r := &testReporter{
+		cmd:     cmd,
+		w:       cmd.OutOrStdout(),
+		json:    flagJSON.Bool(cmd),
+		verbose: flagVerbose.Bool(cmd),
+	}
+	for _, b := range binsts {
+		r.testPackage(b, run)
+	}
+	if r.failed {
+		return ErrPrintedError
+	}
+	return nil
+}
+
+// testPackage runs the test cases of b which match run, if it is not nil.
+func (r *testReporter) testPackage(b *build.Instance, run *regexp.Regexp) {
+	tests := testCases(b)
+	if len(tests) == 0 {
+		r.event(testEvent{Action: "skip", Package: b.ImportPath}, "?   \t%s\t[no test files]\n", b.ImportPath)
+		return
+	}
+	v := r.cmd.ctx.BuildInstance(b)
+
+	failed := false
+	isTest := map[string]bool{}
+	for _, name := range tests {
+		isTest[name] = true
+		if run != nil && !run.MatchString(name) {
+			continue
+		}
+		r.event(testEvent{Action: "run", Package: b.ImportPath, Test: name}, "=== RUN   %s\n", name)
+		tv := v.LookupPath(cue.MakePath(cue.Str(name)))
+		err := tv.Err()
+		if err == nil {
+			err = tv.Validate(cue.Concrete(true))
+		}
+		if err != nil {
+			failed = true
+			r.fail(testEvent{Action: "fail", Package: b.ImportPath, Test: name}, err, "--- FAIL: %s\n", name)
+			continue
+		}
+		r.event(testEvent{Action: "pass", Package: b.ImportPath, Test: name}, "--- PASS: %s\n", name)
+	}
+
+	// The rest of the package must be valid as well. The errors of the
+	// test cases are reported by the fields which hold them.
+	iter, err := v.Fields(cue.Definitions(true), cue.Hidden(true))
+	for err == nil && iter.Next() {
+		sel := iter.Selector()
+		if sel.LabelType() == cue.StringLabel && isTest[sel.Unquoted()] {
+			continue
+		}
+		err = iter.Value().Validate()
+	}
+	if err != nil || failed {
+		r.failed = true
+		r.fail(testEvent{Action: "fail", Package: b.ImportPath}, err, "FAIL\t%s\n", b.ImportPath)
+		return
+	}
+	r.event(testEvent{Action: "pass", Package: b.ImportPath}, "ok  \t%s\n", b.ImportPath)
+}
+
+// testCases returns the names of the regular fields declared at the top
+// level of the test files of b, in order.
+func testCases(b *build.Instance) []string {
+	var names []string
+	seen := map[string]bool{}
+	for _, f := range b.Files {
+		if !strings.HasSuffix(f.Filename, "_test.cue") {
+			continue
+		}
+		for _, d := range f.Decls {
+			field, ok := d.(*ast.Field)
+			if !ok || field.Constraint != 0 {
+				continue
+			}
+			name, isIdent, err := ast.LabelName(field.Label)
+			if err != nil || (isIdent && (strings.HasPrefix(name, "#") || strings.HasPrefix(name, "_"))) {
+				continue
+			}
+			if !seen[name] {
+				seen[name] = true
+				names = append(names, name)
+			}
+		}
+	}
+	return names
+}
+
+// event prints e, or the text given by format and args unless it is the
+// start or the success of a test case which is only printed with -v.
+func (r *testReporter) event(e testEvent, format string, args ...any) {
+	if r.json {
+		b, _ := json.Marshal(e)
+		fmt.Fprintf(r.w, "%s\n", b)
+		return
+	}
+	if e.Test != "" && !r.verbose && e.Action != "fail" {
+		return
+	}
+	fmt.Fprintf(r.w, format, args...)
+}
+
+// fail prints the failure e along with err, if it is not nil.
+func (r *testReporter) fail(e testEvent, err error, format string, args ...any) {
+	var buf bytes.Buffer
+	if err != nil {
+		errors.Print(&buf, err, textErrorConfig(r.cmd))
+	}
+	if r.json {
+		e.Output = buf.String()
+		r.event(e, format, args...)
+		return
+	}
+	if e.Test == "" {
+		// The errors of the package precede its result.
+		r.w.Write(buf.Bytes())
+		r.event(e, format, args...)
+		return
+	}
+	r.event(e, format, args...)
+	for _, line := range strings.Split(strings.TrimRight(buf.String(), "\n"), "\n") {
+		if line != "" {
+			fmt.Fprintf(r.w, "    %s\n", line)
+		}
+	}
+}
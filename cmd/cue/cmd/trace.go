@@ -0,0 +1,157 @@
+// Copyright 2025 The CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"cuelang.org/go/cue"
+	"cuelang.org/go/cue/build"
+	"cuelang.org/go/cue/errors"
+	"cuelang.org/go/cue/token"
+	"cuelang.org/go/internal/core/adt"
+	"cuelang.org/go/internal/value"
+)
+
+// traceErrors adds a trace to each error in err which is only reported at
+// positions outside of the files named on the command line, such as an error
+// inside an imported module. See [traceErrors].
+func (b *buildPlan) traceErrors(v cue.Value, err error) error {
+	insts := b.insts
+	if b.schemaInst != nil && !slices.Contains(insts, b.schemaInst) {
+		insts = append(slices.Clip(insts), b.schemaInst)
+	}
+	own := buildFileNames(insts)
+	for _, d := range b.orphaned {
+		own[d.file.Filename] = true
+	}
+	return traceErrors(insts, own, v, err)
+}
+
+// traceErrors adds a trace to each error in err, which results from
+// evaluating v, which is not reported at any of the own positions.
+// The trace leads from the own files to the failure: the chain of imports
+// from insts to the package in which the error occurred, followed by the
+// field of v which was unified with the failing value.
+// Other errors are returned unchanged.
+func traceErrors(insts []*build.Instance, own map[string]bool, v cue.Value, err error) error {
+	if err == nil {
+		return nil
+	}
+	var cueErr errors.Error
+	if !errors.As(err, &cueErr) {
+		return err
+	}
+	var traced errors.Error
+	for _, e := range errors.Errors(err) {
+		positions := errors.Positions(e)
+		if len(positions) > 0 && !slices.ContainsFunc(positions, func(p token.Pos) bool {
+			return p.Filename() == "" || own[p.Filename()]
+		}) {
+			frames := importChain(insts, positions[0].Filename())
+			if f, ok := fieldSite(v, e.Path(), own); ok {
+				frames = append(frames, f)
+			}
+			e = errors.WithTrace(e, frames...)
+		}
+		traced = errors.Append(traced, e)
+	}
+	return traced
+}
+
+// buildFileNames returns the names of the files which make up insts.
+func buildFileNames(insts []*build.Instance) map[string]bool {
+	names := map[string]bool{}
+	for _, inst := range insts {
+		for _, f := range inst.BuildFiles {
+			names[f.Filename] = true
+		}
+	}
+	return names
+}
+
+// importChain returns a frame for each import leading from one of insts
+// to the package containing filename, using the shortest such chain.
+func importChain(insts []*build.Instance, filename string) []errors.Frame {
+	type step struct {
+		inst   *build.Instance
+		parent *step
+	}
+	var queue []*step
+	seen := map[*build.Instance]bool{}
+	for _, inst := range insts {
+		queue = append(queue, &step{inst: inst})
+		seen[inst] = true
+	}
+	for len(queue) > 0 {
+		s := queue[0]
+		queue = queue[1:]
+		if s.parent != nil && slices.ContainsFunc(s.inst.BuildFiles, func(f *build.File) bool {
+			return f.Filename == filename
+		}) {
+			var frames []errors.Frame
+			for ; s.parent != nil; s = s.parent {
+				var pos token.Pos
+				if p := s.parent.inst.ImportPos[s.inst.ImportPath]; len(p) > 0 {
+					pos = p[0]
+				}
+				frames = append(frames, errors.Frame{
+					Pos:     pos,
+					Message: fmt.Sprintf("import %q", s.inst.ImportPath),
+				})
+			}
+			slices.Reverse(frames)
+			return frames
+		}
+		for _, imp := range s.inst.Imports {
+			if !seen[imp] {
+				seen[imp] = true
+				queue = append(queue, &step{inst: imp, parent: s})
+			}
+		}
+	}
+	return nil
+}
+
+// fieldSite returns a frame for the deepest field along path in v which
+// is declared in one of the own files.
+func fieldSite(v cue.Value, path []string, own map[string]bool) (errors.Frame, bool) {
+	var site errors.Frame
+	found := false
+	for i := 1; i <= len(path); i++ {
+		label := strings.Join(path[:i], ".")
+		p := cue.ParsePath(label)
+		if p.Err() != nil {
+			break
+		}
+		fv := v.LookupPath(p)
+		if !fv.Exists() {
+			break
+		}
+		_, vx := value.ToInternal(fv)
+		vx.VisitLeafConjuncts(func(c adt.Conjunct) bool {
+			src := c.Source()
+			if src == nil || !own[src.Pos().Filename()] {
+				return true
+			}
+			site = errors.Frame{Pos: src.Pos(), Message: "field " + label}
+			found = true
+			return false
+		})
+	}
+	return site, found
+}
@@ -106,7 +106,7 @@ func runTrim(cmd *Command, args []string) error {
 		return err
 	}
 
-	dst := flagOutFile.String(cmd)
+	dst := outFileFlag(cmd)
 	if dst != "" && dst != "-" && !flagForce.Bool(cmd) {
 		switch _, err := os.Stat(dst); {
 		case os.IsNotExist(err):
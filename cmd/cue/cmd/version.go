@@ -21,20 +21,43 @@ import (
 	"os"
 	"runtime"
 	"runtime/debug"
+	"strconv"
 	"testing"
 
 	"github.com/spf13/cobra"
 
+	"cuelang.org/go/internal/cueexperiment"
 	"cuelang.org/go/internal/cueversion"
+	"cuelang.org/go/internal/filetypes"
 )
 
 func newVersionCmd(c *Command) *cobra.Command {
 	cmd := &cobra.Command{
 		Use:   "version",
 		Short: "print CUE version",
-		Long:  ``,
-		RunE:  mkRunE(c, runVersion),
+		Long: `version prints the version of the cue tool, along with the
+version of Go it was built with and its build settings.
+
+With --out json, the output is a JSON object which also holds the
+version control information of the build, the state of each CUE_EXPERIMENT
+flag, the registry configuration, and the supported file types, which is
+useful for auditing the cue binaries installed across many machines:
+
+	{
+	    "version": "v0.14.0",
+	    "goVersion": "go1.24.4",
+	    "languageVersion": "v0.14.0",
+	    "module": {"path": "cuelang.org/go", "version": "v0.14.0"},
+	    "vcs": {"system": "git", "revision": "...", "time": "...", "modified": false},
+	    "settings": {"GOOS": "linux", ...},
+	    "experiments": {"evalv3": true, ...},
+	    "registry": {"CUE_REGISTRY": "...", "hosts": [{"name": "registry.cue.works"}]},
+	    "fileTypes": ["binary", "cue", "json", ...]
 	}
+`,
+		RunE: mkRunE(c, runVersion),
+	}
+	cmd.Flags().String(string(flagOut), "text", "output format: text or json")
 	return cmd
 }
 
@@ -52,12 +75,21 @@ func runVersion(cmd *Command, args []string) error {
 		// shouldn't happen
 		return errors.New("unknown error reading build-info")
 	}
-	fmt.Fprintf(w, "cue version %s\n\n", cueModuleVersion())
-	fmt.Fprintf(w, "go version %s\n", runtime.Version())
 	bi.Settings = append(bi.Settings, debug.BuildSetting{
 		Key:   "cue.lang.version",
 		Value: cueversion.LanguageVersion(),
 	})
+	switch out := flagOut.String(cmd); out {
+	case "text":
+	case "json":
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "    ")
+		return enc.Encode(newVersionInfo(bi))
+	default:
+		return fmt.Errorf("unknown output format %q; must be text or json", out)
+	}
+	fmt.Fprintf(w, "cue version %s\n\n", cueModuleVersion())
+	fmt.Fprintf(w, "go version %s\n", runtime.Version())
 	for _, s := range bi.Settings {
 		if s.Value == "" {
 			// skip empty build settings
@@ -75,6 +107,86 @@ func runVersion(cmd *Command, args []string) error {
 	return nil
 }
 
+// versionInfo is the output of cue version --out json.
+type versionInfo struct {
+	Version         string            `json:"version"`
+	GoVersion       string            `json:"goVersion"`
+	LanguageVersion string            `json:"languageVersion"`
+	Module          moduleInfo        `json:"module"`
+	VCS             *vcsInfo          `json:"vcs,omitempty"`
+	Settings        map[string]string `json:"settings"`
+	Experiments     map[string]bool   `json:"experiments"`
+	Registry        registryInfo      `json:"registry"`
+	FileTypes       []string          `json:"fileTypes"`
+}
+
+type moduleInfo struct {
+	Path    string `json:"path"`
+	Version string `json:"version,omitempty"`
+}
+
+type vcsInfo struct {
+	System   string `json:"system"`
+	Revision string `json:"revision,omitempty"`
+	Time     string `json:"time,omitempty"`
+	Modified bool   `json:"modified"`
+}
+
+type registryInfo struct {
+	CUERegistry string         `json:"CUE_REGISTRY,omitempty"`
+	Hosts       []registryHost `json:"hosts,omitempty"`
+	// Error holds the reason why the registry configuration is invalid.
+	Error string `json:"error,omitempty"`
+}
+
+type registryHost struct {
+	Name     string `json:"name"`
+	Insecure bool   `json:"insecure,omitempty"`
+}
+
+func newVersionInfo(bi *debug.BuildInfo) *versionInfo {
+	info := &versionInfo{
+		Version:         cueModuleVersion(),
+		GoVersion:       runtime.Version(),
+		LanguageVersion: cueversion.LanguageVersion(),
+		Module:          moduleInfo{Path: bi.Main.Path, Version: bi.Main.Version},
+		Settings:        make(map[string]string),
+		Experiments:     cueexperiment.Values(),
+		Registry:        registryInfo{CUERegistry: os.Getenv("CUE_REGISTRY")},
+		FileTypes:       filetypes.Encodings(),
+	}
+	var vcs vcsInfo
+	for _, s := range bi.Settings {
+		switch s.Key {
+		case "vcs":
+			vcs.System = s.Value
+		case "vcs.revision":
+			vcs.Revision = s.Value
+		case "vcs.time":
+			vcs.Time = s.Value
+		case "vcs.modified":
+			vcs.Modified, _ = strconv.ParseBool(s.Value)
+		case "cue.lang.version":
+		default:
+			if s.Value != "" {
+				info.Settings[s.Key] = s.Value
+			}
+		}
+	}
+	if vcs.System != "" {
+		info.VCS = &vcs
+	}
+	resolver, err := getRegistryResolver()
+	if err != nil {
+		info.Registry.Error = err.Error()
+	} else {
+		for _, h := range resolver.AllHosts() {
+			info.Registry.Hosts = append(info.Registry.Hosts, registryHost{Name: h.Name, Insecure: h.Insecure})
+		}
+	}
+	return info
+}
+
 // cueModuleVersion returns the version of the cuelang.org/go module as much
 // as can reasonably be determined. If no version can be determined,
 // it returns the empty string.
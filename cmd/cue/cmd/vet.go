@@ -15,11 +15,13 @@
 package cmd
 
 import (
+	"fmt"
+
 	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
 	"golang.org/x/text/message"
 
 	"cuelang.org/go/cue"
-	"cuelang.org/go/cue/errors"
 )
 
 const vetDoc = `The vet command validates CUE and other data files.
@@ -62,6 +64,15 @@ The -d can be used to only verify files against the result of an expression
 evaluated within the CUE files. This can be useful if the CUE files contain
 a set of definitions to pick from.
 
+The schema is also used to resolve the types of YAML and JSON scalars
+which the source leaves ambiguous. A plain YAML scalar such as 1.0, 0755,
+or on, whose type is not allowed by the schema, is decoded as a bool, int,
+float, or string instead, where the schema allows it. Similarly, a JSON
+number such as 1.0 is decoded as an int where only ints are allowed, and
+1 as a float where only floats are allowed. Quoted YAML scalars are not
+affected. The same applies to other commands which take a schema for
+data files, such as eval and export.
+
 Examples:
 
   # Check files against a CUE file:
@@ -72,6 +83,188 @@ Examples:
 
 More than one expression may be given using multiple -d flags. Each non-CUE
 file must match all expression values.
+
+Many data files can be checked concurrently with --jobs. The schema is loaded
+once, and each worker checks a share of the files against its own copy of the
+schema. Errors are reported in the same order as the files were given, and
+each file is checked in full even if another file has errors:
+
+  cue vet -c schema.cue manifests/*.yaml --jobs 8
+
+
+Self-describing data files
+
+Data files given without any CUE files may name the schema they must satisfy
+in a top-level $schema field, which is removed before validation. Its value is
+a package path followed by a definition, as in "foo.example/schemas#Service".
+The package may be within the current module or one of its dependencies, or
+carry a version such as @v1.2.3 or @latest to be fetched from the registry:
+
+  $schema: foo.example/schemas@v1.2.3#Service
+  name: web
+  port: 80
+
+For example:
+
+  cue vet service.yaml deploy.json
+
+
+Mapping data files to schemas
+
+A tree of heterogeneous data files can be checked with a single invocation
+using --map flags of the form pattern=expression. Each data file within the
+current directory whose slash-separated relative path matches the pattern is
+checked against the schema given by the expression, which is evaluated within
+the CUE package or files given as arguments. A pattern element "**" matches
+any number of directories, and other elements are as in Go's path.Match.
+A file which matches more than one pattern must satisfy all their schemas.
+Hidden directories and cue.mod directories are skipped.
+
+For example:
+
+  cue vet . --map 'data/*.yaml=#Service' --map 'k8s/**/*.yaml=#Object'
+
+
+Container configuration
+
+Schemas for Docker Compose files and OCI image configurations are built
+into the cue command. The compose subcommand checks Compose files against
+them without the need for any CUE files:
+
+  cue vet compose
+
+Run "cue get container" to add the schemas to the current module, so that
+they can be used with -d, --map, and $schema like any other schema.
+
+
+Warnings
+
+Constraints can be marked as warnings with a @severity(warning) attribute
+on a field. Violations of such constraints, within the field or any value
+nested in it, are reported as warnings and do not cause vet to fail:
+
+  replicas: int & <=10 @severity(warning)
+
+This is useful when rolling out new constraints gradually. A nested
+@severity(error) attribute restores the default severity. The --strict flag
+reports all warnings as errors.
+
+
+Watching for changes
+
+With --watch, vet keeps running after the first check, and checks again
+whenever files within the main module, the current directory, or the
+directories of the files and packages given as arguments change, until
+interrupted. Only the data files and packages which may be affected by each
+change are checked again, and schemas for data files are kept in memory if
+they have not changed. Combined with --error-format json, this provides live
+validation for editors and other tools.
+
+
+Policy packs
+
+A policy pack is a CUE package which declares named rules in a rules field.
+The --policy flag, which may be repeated, checks each value validated by vet
+against the rules of the given packages, in addition to its own constraints.
+The input field of a policy pack is set to the value being checked, so that
+rules can refer to it. Each rule has the following fields:
+
+  check        a constraint which the value must satisfy (required)
+  message      the message reported for violations, which may refer to input
+  hint         how to fix violations, which may also refer to input
+  severity     "error" (the default) or "warning"
+  description  a short description of the rule, used in SARIF output
+  url          where to find more information or remediation advice
+
+For example:
+
+  package policy
+
+  input: _
+
+  rules: "no-latest-tag": {
+  	check: image: !~":latest$"
+  	message: "image \(input.image) must use a pinned version"
+  	hint:    "use an image tag such as a version number or digest"
+  	url:     "https://example.com/policies#no-latest-tag"
+  }
+  rules: "replica-limit": {
+  	check: {
+  		if input.kind == "Deployment" {replicas: <=10}
+  	}
+  	severity: "warning"
+  	message:  "deployments should not exceed 10 replicas"
+  }
+
+Violations are reported with the rule identifier, which also serves as the
+code or rule ID in machine-readable diagnostics formats.
+
+The terraform subcommand applies policy packs to the resources of Terraform
+plans, as produced by terraform show -json:
+
+  cue vet terraform plan.json --policy ./policies --error-format sarif
+
+
+Diagnostics formats
+
+By default, errors are printed to stderr in a human-readable form. The
+--error-format flag, or its older name --out, selects a different format:
+
+  Format       Description
+  text         human-readable errors on stderr (the default)
+  short        one line per error on stderr, as file:line:column: message
+  json         one JSON object per error on stderr; see 'cue help diagnostics'
+  sarif        SARIF 2.1.0 on stdout, as used by GitHub code scanning and other
+               dashboards, written as a single document once vet has finished
+  github       GitHub Actions workflow commands on stdout, one per error, which
+               show errors as annotations on the lines of pull requests
+  gitlab       a GitLab code quality report on stdout, written as a single
+               document once vet has finished, which shows errors in merge
+               requests
+
+For example:
+
+  # Produce a SARIF report for code scanning tools:
+  cue vet --error-format sarif ./... > results.sarif
+
+  # Annotate pull requests from a GitHub Actions step:
+  cue vet --error-format github ./...
+
+  # Produce a report for the codequality artifact of a GitLab CI job:
+  cue vet --error-format gitlab ./... > gl-code-quality-report.json
+
+The --max-errors flag stops vet once the given number of errors have been
+reported, which can save time when checking large amounts of data.
+
+
+Summary reports
+
+The --summary flag writes a report to stdout once vet has finished, which
+aggregates the results to help track the quality of many inputs over time.
+The report lists the number of files checked, the number of errors and
+warnings by rule, schema, and file, and the fields with the most errors and
+warnings. The report can be formatted as text, json, or html:
+
+  cue vet --summary=html -d '#Deployment' schema.cue ./deploy/*.yaml > report.html
+
+The summary cannot be combined with --error-format sarif or gitlab.
+
+
+Exit codes
+
+By default, vet exits with code 1 if any errors are found, and 0 otherwise.
+The --exit-code flag, which may be repeated, sets the exit code for a kind of
+failure, allowing scripts to tell them apart:
+
+  Kind         Description
+  invalid      the input does not satisfy its constraints (default 1)
+  error        vet failed for another reason, such as a syntax error in a
+               CUE file or a package which cannot be loaded (default 1)
+  warning      only warnings were reported (default 0)
+
+For example:
+
+  cue vet --exit-code invalid=2 --exit-code warning=3 ./...
 `
 
 func newVetCmd(c *Command) *cobra.Command {
@@ -87,6 +280,34 @@ func newVetCmd(c *Command) *cobra.Command {
 
 	cmd.Flags().BoolP(string(flagConcrete), "c", false,
 		"require the evaluation to be concrete, or set -c=false to allow incomplete values")
+	cmd.Flags().String(string(flagErrorFormat), diagText,
+		"format for reporting errors (text|short|json|sarif|github|gitlab)")
+	cmd.Flags().Int(string(flagMaxErrors), 0,
+		"stop after reporting this many errors, or 0 for no limit")
+	cmd.Flags().StringArray(string(flagExitCode), nil,
+		"exit code for a kind of failure (invalid|error|warning=code)")
+	cmd.Flags().Int(string(flagJobs), 1,
+		"number of data files to check concurrently")
+	cmd.Flags().StringArray(string(flagMap), nil,
+		"check data files matching a pattern against a schema (pattern=expression)")
+	cmd.Flags().StringArray(string(flagPolicy), nil,
+		"check values against the rules of a policy pack package")
+	cmd.Flags().String(string(flagSummary), "",
+		"write a summary report of the results to stdout (text|json|html)")
+	cmd.Flags().Bool(string(flagWatch), false,
+		"check again whenever the inputs change")
+
+	// --out was the name of --error-format before it gained formats
+	// other than sarif.
+	cmd.Flags().SetNormalizeFunc(func(f *pflag.FlagSet, name string) pflag.NormalizedName {
+		if name == string(flagOut) {
+			name = string(flagErrorFormat)
+		}
+		return pflag.NormalizedName(name)
+	})
+
+	cmd.AddCommand(newVetComposeCmd(c))
+	cmd.AddCommand(newVetTerraformCmd(c))
 
 	return cmd
 }
@@ -100,26 +321,78 @@ func newVetCmd(c *Command) *cobra.Command {
 // TODO: allow unrooted schema, such as JSON schema to compare against
 // other values.
 func doVet(cmd *Command, args []string) error {
+	if flagWatch.Bool(cmd) {
+		return watchVet(cmd, args)
+	}
+	return runVet(cmd, args, nil)
+}
+
+// runVet runs vet once. When watching for changes, ws holds the state
+// kept between runs, which allows only validating what may be affected
+// by the changes since the last run.
+func runVet(cmd *Command, args []string, ws *vetWatch) error {
+	r, err := newDiagReporter(cmd, flagErrorFormat.String(cmd))
+	if err != nil {
+		return err
+	}
+	r.strict = flagStrict.Bool(cmd)
+	r.maxErrors = flagMaxErrors.Int(cmd)
+	if r.exitCodes, err = parseExitCodes(defaultExitCodes, flagExitCode.StringArray(cmd)); err != nil {
+		return err
+	}
+	if format := flagSummary.String(cmd); format != "" {
+		if r.buffered() {
+			return fmt.Errorf("cannot specify both --%s and --%s=%s", flagSummary, flagErrorFormat, r.format)
+		}
+		if r.summary, err = newVetSummary(format); err != nil {
+			return err
+		}
+	}
+	policies, err := loadPolicies(cmd, cmd.ctx)
+	if err != nil {
+		return r.fail(err)
+	}
+	if len(policies) > 0 && flagJobs.Int(cmd) > 1 {
+		return r.fail(fmt.Errorf("cannot specify both --%s and --%s", flagPolicy, flagJobs))
+	}
+	mappings, err := parseVetMappings(cmd)
+	if err != nil {
+		return r.fail(err)
+	}
+	if len(mappings) > 0 {
+		return vetMapped(cmd, args, mappings, policies, r)
+	}
+	if b := ws.changedDataFiles(); b != nil {
+		// Only data files have changed, so the schema can be reused.
+		vetFiles(cmd, b, policies, r)
+		return r.flush()
+	}
 	b, err := parseArgs(cmd, args, &config{
 		noMerge: true,
 	})
 	if err != nil {
-		return err
+		return r.fail(err)
 	}
+	ws.update(b)
 
 	// Go into a special vet mode if the user explicitly specified non-cue
 	// files on the command line.
 	// TODO: unify these two modes.
 	if len(b.orphaned) > 0 {
-		return vetFiles(cmd, b)
+		vetFiles(cmd, b, policies, r)
+		return r.flush()
 	}
 
 	shown := false
 
+	for _, inst := range b.insts {
+		r.checked(len(inst.BuildFiles))
+	}
 	iter := b.instances()
 	defer iter.close()
-	for iter.scan() {
+	for !r.full() && iter.scan() {
 		v := iter.value()
+		r.schema = iter.id()
 		// TODO: use ImportPath or some other sanitized path.
 
 		concrete := true
@@ -135,10 +408,13 @@ func doVet(cmd *Command, args []string) error {
 			cue.Definitions(true),
 			cue.Hidden(true),
 		}
-		w := cmd.Stderr()
+		w := cmd.OutOrStderr()
 		err := v.Validate(append(opt, cue.Concrete(concrete))...)
 		if err != nil && !hasFlag {
 			err = v.Validate(append(opt, cue.Concrete(false))...)
+			if err == nil {
+				r.markInvalid()
+			}
 			if !shown && err == nil {
 				shown = true
 				p := message.NewPrinter(getLang())
@@ -146,32 +422,50 @@ func doVet(cmd *Command, args []string) error {
 					"some instances are incomplete; use the -c flag to show errors or -c=false to allow incomplete instances")
 			}
 		}
-		printError(cmd, err)
+		errs, warnings := splitWarnings(v, b.traceErrors(v, err))
+		r.warn(warnings)
+		r.report(errs)
+		checkPolicies(v, policies, r)
 	}
 	if err := iter.err(); err != nil {
-		return err
+		return r.fail(err)
 	}
-	return nil
+	return r.flush()
 }
 
-func vetFiles(cmd *Command, b *buildPlan) error {
+// vetFiles validates the data files in b against its schema,
+// reporting any errors to r.
+func vetFiles(cmd *Command, b *buildPlan, policies []policyRule, r *diagReporter) {
 	// Use -r type root, instead of -e
 
+	r.checked(len(b.orphaned))
 	if !b.encConfig.Schema.Exists() {
-		return errors.New("data files specified without a schema")
+		vetSelfDescribed(cmd, b, policies, r)
+		return
+	}
+	r.schema = schemaLabel(b)
+	if jobs := flagJobs.Int(cmd); jobs > 1 && b.schemaInst != nil {
+		vetFilesConcurrently(b, jobs, r)
+		return
 	}
 
 	iter := b.instances()
 	defer iter.close()
-	for iter.scan() {
+	for !r.full() && iter.scan() {
 		v := iter.value()
 
 		// Always concrete when checking against concrete files.
 		err := v.Validate(cue.Concrete(true))
-		printError(cmd, err)
+		errs, warnings := splitWarnings(v, b.traceErrors(v, err))
+		r.warn(warnings)
+		r.report(errs)
+		checkPolicies(v, policies, r)
 	}
 	if err := iter.err(); err != nil {
-		return err
+		// Data which conflicts with the schema stops the iteration,
+		// so report the conflicts according to their severity.
+		errs, warnings := splitWarnings(b.encConfig.Schema, err)
+		r.warn(warnings)
+		r.report(errs)
 	}
-	return nil
 }
@@ -0,0 +1,139 @@
+// Copyright 2025 CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"cuelang.org/go/cue"
+	"cuelang.org/go/cue/errors"
+	"cuelang.org/go/cue/token"
+	"cuelang.org/go/encoding/yaml"
+	"cuelang.org/go/internal/container"
+)
+
+func newVetComposeCmd(c *Command) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "compose [files]",
+		Short: "validate Docker Compose files",
+		Long: `compose validates Docker Compose files against the Compose Specification.
+
+The files are checked against the #Project definition built into the cue
+command, without the need for any CUE files. If no files are given, the
+Compose file in the current directory is checked, looking for the same
+names as docker compose does: compose.yaml, compose.yml,
+docker-compose.yaml, and docker-compose.yml. An override file next to it,
+such as compose.override.yaml, is checked as well.
+
+Values set by variable interpolation, such as ${REPLICAS:-1}, are
+accepted wherever the specification allows a number or boolean.
+
+For example:
+
+  cue vet compose
+  cue vet compose deploy/compose.yaml deploy/compose.prod.yaml
+
+To check a Compose file in combination with other constraints, add the
+schema to the current module with "cue get container".
+
+To check a package in a directory named compose, use ./compose.
+`,
+		RunE: mkRunE(c, runVetCompose),
+	}
+
+	cmd.Flags().String(string(flagErrorFormat), diagText,
+		"format for reporting errors (text|short|json|sarif|github|gitlab)")
+	cmd.Flags().Int(string(flagMaxErrors), 0,
+		"stop after reporting this many errors, or 0 for no limit")
+	cmd.Flags().StringArray(string(flagExitCode), nil,
+		"exit code for a kind of failure (invalid|error|warning=code)")
+
+	return cmd
+}
+
+// composeFiles lists the names of the Compose files docker compose looks
+// for by default, in order of preference.
+var composeFiles = []string{
+	"compose.yaml",
+	"compose.yml",
+	"docker-compose.yaml",
+	"docker-compose.yml",
+}
+
+func runVetCompose(cmd *Command, args []string) error {
+	r, err := newDiagReporter(cmd, flagErrorFormat.String(cmd))
+	if err != nil {
+		return err
+	}
+	r.maxErrors = flagMaxErrors.Int(cmd)
+	if r.exitCodes, err = parseExitCodes(defaultExitCodes, flagExitCode.StringArray(cmd)); err != nil {
+		return err
+	}
+
+	files := args
+	if len(files) == 0 {
+		if files, err = defaultComposeFiles(); err != nil {
+			return r.fail(err)
+		}
+	}
+
+	schema := container.Compose.Value(cmd.ctx).LookupPath(cue.ParsePath("#Project"))
+	if err := schema.Err(); err != nil {
+		return r.fail(err)
+	}
+	r.schema = container.Compose.ImportPath + "#Project"
+	r.checked(len(files))
+	for _, file := range files {
+		if r.full() {
+			break
+		}
+		data, err := os.ReadFile(file)
+		if err != nil {
+			r.toolError(err)
+			continue
+		}
+		f, err := yaml.Extract(file, data)
+		if err != nil {
+			r.toolError(err)
+			continue
+		}
+		v := cmd.ctx.BuildFile(f).Unify(schema)
+		r.report(v.Validate(cue.Concrete(true)))
+	}
+	return r.flush()
+}
+
+// defaultComposeFiles returns the Compose file in the current directory,
+// followed by its override file if there is one.
+func defaultComposeFiles() ([]string, error) {
+	for _, name := range composeFiles {
+		if _, err := os.Stat(name); err != nil {
+			continue
+		}
+		files := []string{name}
+		ext := filepath.Ext(name)
+		override := strings.TrimSuffix(name, ext) + ".override" + ext
+		if _, err := os.Stat(override); err == nil {
+			files = append(files, override)
+		}
+		return files, nil
+	}
+	return nil, errors.Newf(token.NoPos, "no Compose file found in the current directory; looked for %s",
+		strings.Join(composeFiles, ", "))
+}
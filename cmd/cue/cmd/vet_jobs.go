@@ -0,0 +1,145 @@
+// Copyright 2025 CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"sync"
+
+	"cuelang.org/go/cue"
+	"cuelang.org/go/cue/errors"
+	"cuelang.org/go/internal/encoding"
+)
+
+// vetResult holds the diagnostics for a single data file.
+type vetResult struct {
+	errs     errors.Error
+	warnings errors.Error
+
+	// limit is set if validating the file exceeded a limit on evaluation.
+	limit error
+}
+
+// vetFilesConcurrently validates the data files in b using the given number
+// of workers. As values from the same [cue.Context] are not safe for
+// concurrent use, each worker compiles the schema within its own context.
+// The results are reported in the order of the files in b, so that the
+// output does not depend on scheduling. As when vetting sequentially,
+// exceeding a limit on evaluation aborts vetting; the files after the
+// first file exceeding a limit are not reported.
+func vetFilesConcurrently(b *buildPlan, jobs int, r *diagReporter) {
+	jobs = min(jobs, len(b.orphaned))
+
+	// Compile the schemas up front, as compiling shares the syntax trees
+	// of the schema instance between contexts.
+	ctxs := make([]*cue.Context, jobs)
+	schemas := make([]cue.Value, jobs)
+	for w := range jobs {
+		ctxs[w] = newContext()
+		schema, err := compileVetSchema(ctxs[w], b)
+		if err != nil {
+			r.toolError(err)
+			return
+		}
+		schemas[w] = schema
+	}
+
+	results := make([]vetResult, len(b.orphaned))
+	work := make(chan int)
+	var wg sync.WaitGroup
+	for w := range jobs {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			failed := false
+			for i := range work {
+				// Values of a context which exceeded a limit may be
+				// partially evaluated, so stop using it.
+				if failed {
+					continue
+				}
+				results[i] = vetFile(ctxs[w], schemas[w], b, b.orphaned[i])
+				failed = results[i].limit != nil
+			}
+		}()
+	}
+	for i := range b.orphaned {
+		work <- i
+	}
+	close(work)
+	wg.Wait()
+
+	for _, res := range results {
+		if res.limit != nil {
+			r.toolError(res.limit)
+			return
+		}
+		r.warn(res.warnings)
+		r.report(res.errs)
+	}
+}
+
+// compileVetSchema compiles the schema of b within ctx.
+func compileVetSchema(ctx *cue.Context, b *buildPlan) (cue.Value, error) {
+	v := ctx.BuildInstance(b.schemaInst)
+	if err := v.Err(); err != nil {
+		return cue.Value{}, err
+	}
+	if b.schema != nil {
+		v = ctx.BuildExpr(b.schema,
+			cue.InferBuiltins(true),
+			cue.Scope(v))
+		if err := v.Validate(); err != nil {
+			return cue.Value{}, err
+		}
+	}
+	return v, nil
+}
+
+// vetFile validates each value in the data file d against schema,
+// mirroring how the values are checked by [vetFiles].
+func vetFile(ctx *cue.Context, schema cue.Value, b *buildPlan, d *decoderInfo) (res vetResult) {
+	defer recoverLimit(&res.limit)
+	dec := d.d
+	if dec == nil {
+		// Use the schema of this worker to resolve the types of scalars.
+		cfg := *b.encConfig
+		cfg.Schema = schema
+		dec = encoding.NewDecoder(ctx, d.file, &cfg)
+	}
+	defer dec.Close()
+	for ; !dec.Done(); dec.Next() {
+		v := ctx.BuildFile(dec.File())
+		if err := v.Err(); err != nil {
+			res.errs = errors.Append(res.errs, errors.Promote(err, ""))
+			return res
+		}
+		v = v.Unify(schema)
+		if v.Err() != nil {
+			// As with the sequential iterator, stop at the first value
+			// which conflicts with the schema.
+			errs, warnings := splitWarnings(schema, v.Validate())
+			res.errs = errors.Append(res.errs, errs)
+			res.warnings = errors.Append(res.warnings, warnings)
+			return res
+		}
+		errs, warnings := splitWarnings(v, v.Validate(cue.Concrete(true)))
+		res.errs = errors.Append(res.errs, errs)
+		res.warnings = errors.Append(res.warnings, warnings)
+	}
+	if err := dec.Err(); err != nil {
+		res.errs = errors.Append(res.errs, errors.Promote(err, ""))
+	}
+	return res
+}
@@ -0,0 +1,146 @@
+// Copyright 2025 CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"fmt"
+	"io/fs"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"cuelang.org/go/cue/ast"
+	"cuelang.org/go/cue/errors"
+	"cuelang.org/go/cue/parser"
+	"cuelang.org/go/cue/token"
+)
+
+// A vetMapping associates the data files matching a glob pattern
+// with the schema they must satisfy, as given by a --map flag.
+type vetMapping struct {
+	pattern []string // slash-separated pattern elements
+	arg     string   // the original pattern, for error messages
+	schema  ast.Expr
+	files   []string
+}
+
+// parseVetMappings parses the --map flags of the form pattern=expr.
+func parseVetMappings(cmd *Command) ([]*vetMapping, error) {
+	var mappings []*vetMapping
+	for _, arg := range flagMap.StringArray(cmd) {
+		pattern, expr, ok := strings.Cut(arg, "=")
+		if !ok || pattern == "" || expr == "" {
+			return nil, fmt.Errorf("invalid --%s argument %q; must be of the form pattern=expression", flagMap, arg)
+		}
+		if _, err := path.Match(pattern, ""); err != nil {
+			return nil, fmt.Errorf("invalid --%s pattern %q: %v", flagMap, pattern, err)
+		}
+		schema, err := parser.ParseExpr("--map", expr)
+		if err != nil {
+			return nil, err
+		}
+		mappings = append(mappings, &vetMapping{
+			pattern: strings.Split(path.Clean(pattern), "/"),
+			arg:     pattern,
+			schema:  schema,
+		})
+	}
+	return mappings, nil
+}
+
+// findMappedFiles walks dir and adds each file to the mappings whose
+// pattern matches its slash-separated path relative to dir.
+// Hidden directories and cue.mod directories are skipped.
+func findMappedFiles(dir string, mappings []*vetMapping) error {
+	return filepath.WalkDir(dir, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			if p != dir && (strings.HasPrefix(d.Name(), ".") || d.Name() == "cue.mod") {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		rel, err := filepath.Rel(dir, p)
+		if err != nil {
+			return err
+		}
+		name := strings.Split(filepath.ToSlash(rel), "/")
+		for _, m := range mappings {
+			if matchGlob(m.pattern, name) {
+				m.files = append(m.files, p)
+			}
+		}
+		return nil
+	})
+}
+
+// matchGlob reports whether the path elements in name match those in
+// pattern, where each pattern element is as in [path.Match], except that
+// "**" matches any number of path elements, including none.
+func matchGlob(pattern, name []string) bool {
+	for len(pattern) > 0 {
+		if pattern[0] == "**" {
+			for i := range len(name) + 1 {
+				if matchGlob(pattern[1:], name[i:]) {
+					return true
+				}
+			}
+			return false
+		}
+		if len(name) == 0 {
+			return false
+		}
+		if ok, _ := path.Match(pattern[0], name[0]); !ok {
+			return false
+		}
+		pattern, name = pattern[1:], name[1:]
+	}
+	return len(name) == 0
+}
+
+// vetMapped validates the data files in the current directory against
+// the schemas given by mappings, which are evaluated within the CUE
+// package or files given by args. A file which matches more than one
+// pattern must satisfy all the corresponding schemas.
+func vetMapped(cmd *Command, args []string, mappings []*vetMapping, policies []policyRule, r *diagReporter) error {
+	if flagSchema.IsSet(cmd) {
+		return r.fail(fmt.Errorf("cannot specify both --%s and --%s", flagSchema, flagMap))
+	}
+	if err := findMappedFiles(rootWorkingDir(), mappings); err != nil {
+		return r.fail(err)
+	}
+	for _, m := range mappings {
+		if r.full() {
+			break
+		}
+		if len(m.files) == 0 {
+			r.toolError(errors.Newf(token.NoPos, "--%s pattern %q matches no files", flagMap, m.arg))
+			continue
+		}
+		b, err := parseArgs(cmd, append(slices.Clone(args), m.files...), &config{
+			noMerge: true,
+			schema:  m.schema,
+		})
+		if err != nil {
+			r.toolError(err)
+			continue
+		}
+		vetFiles(cmd, b, policies, r)
+	}
+	return r.flush()
+}
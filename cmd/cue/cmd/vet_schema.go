@@ -0,0 +1,160 @@
+// Copyright 2025 CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"slices"
+	"strconv"
+	"strings"
+
+	"cuelang.org/go/cue"
+	"cuelang.org/go/cue/ast"
+	"cuelang.org/go/cue/errors"
+	"cuelang.org/go/cue/token"
+)
+
+// schemaField is the name of the top-level field by which a data file
+// declares the schema it must satisfy.
+const schemaField = "$schema"
+
+// schemaResolver resolves the schema references found in data files,
+// loading each referenced package only once.
+type schemaResolver struct {
+	cmd     *Command
+	schemas map[string]resolvedSchema
+}
+
+type resolvedSchema struct {
+	v   cue.Value
+	err error
+}
+
+// vetSelfDescribed validates the data files in b, which were specified
+// without any CUE files, against the schemas named by their $schema fields.
+func vetSelfDescribed(cmd *Command, b *buildPlan, policies []policyRule, r *diagReporter) {
+	sr := &schemaResolver{cmd: cmd, schemas: map[string]resolvedSchema{}}
+	for _, d := range b.orphaned {
+		if r.full() {
+			break
+		}
+		dec := d.dec(b)
+		for ; !r.full() && !dec.Done(); dec.Next() {
+			f := dec.File()
+			ref, ok, err := removeSchemaRef(f)
+			if err != nil {
+				r.report(err)
+				continue
+			}
+			if !ok {
+				r.toolError(errors.Newf(f.Pos(), "data file specified without a schema; add a %s field or specify CUE files", schemaField))
+				continue
+			}
+			schema, err := sr.resolve(ref)
+			if err != nil {
+				r.toolError(errors.Wrapf(err, f.Pos(), "cannot resolve %s %q", schemaField, ref))
+				continue
+			}
+			r.schema = ref
+			v := cmd.ctx.BuildFile(f).Unify(schema)
+			err = v.Validate(cue.Concrete(true))
+			errs, warnings := splitWarnings(v, err)
+			r.warn(warnings)
+			r.report(errs)
+			checkPolicies(v, policies, r)
+		}
+		if err := dec.Err(); err != nil {
+			r.toolError(err)
+		}
+		dec.Close()
+	}
+}
+
+// removeSchemaRef removes the top-level $schema field from f and returns
+// its value. It reports false if f has no such field.
+func removeSchemaRef(f *ast.File) (ref string, ok bool, err error) {
+	decls := &f.Decls
+	if len(f.Decls) == 1 {
+		// Some encodings, such as JSON, decode into a single struct.
+		if e, ok := f.Decls[0].(*ast.EmbedDecl); ok {
+			if s, ok := e.Expr.(*ast.StructLit); ok {
+				decls = &s.Elts
+			}
+		}
+	}
+	for i, d := range *decls {
+		field, ok := d.(*ast.Field)
+		if !ok {
+			continue
+		}
+		if name, _, _ := ast.LabelName(field.Label); name != schemaField {
+			continue
+		}
+		lit, ok := field.Value.(*ast.BasicLit)
+		if ok {
+			ref, err = strconv.Unquote(lit.Value)
+		}
+		if !ok || err != nil {
+			return "", false, errors.Newf(field.Value.Pos(), "%s field must be a string", schemaField)
+		}
+		*decls = slices.Delete(*decls, i, i+1)
+		return ref, true, nil
+	}
+	return "", false, nil
+}
+
+// resolve returns the schema for a reference of the form pkg#Def, where
+// pkg is a package path as accepted on the command line, optionally with
+// a version such as @v1.2.3 or @latest to fetch the module from the
+// registry, and Def is a path within the package. The path may be omitted to use the
+// package as a whole.
+func (sr *schemaResolver) resolve(ref string) (cue.Value, error) {
+	s, ok := sr.schemas[ref]
+	if !ok {
+		s.v, s.err = sr.load(ref)
+		sr.schemas[ref] = s
+	}
+	return s.v, s.err
+}
+
+func (sr *schemaResolver) load(ref string) (cue.Value, error) {
+	pkg, frag, _ := strings.Cut(ref, "#")
+	cfg, err := defaultConfig()
+	if err != nil {
+		return cue.Value{}, err
+	}
+	binsts := loadFromArgs([]string{pkg}, cfg.loadCfg)
+	if len(binsts) != 1 {
+		return cue.Value{}, errors.Newf(token.NoPos, "%s does not refer to a single package", pkg)
+	}
+	if err := binsts[0].Err; err != nil {
+		return cue.Value{}, err
+	}
+	v := sr.cmd.ctx.BuildInstance(binsts[0])
+	if err := v.Err(); err != nil {
+		return cue.Value{}, err
+	}
+	if frag == "" {
+		return v, nil
+	}
+	p := cue.ParsePath("#" + frag)
+	if err := p.Err(); err != nil {
+		return cue.Value{}, err
+	}
+	v = v.LookupPath(p)
+	if !v.Exists() {
+		return cue.Value{}, errors.Newf(token.NoPos, "definition #%s not found in %s", frag, pkg)
+	}
+	return v, nil
+}
@@ -0,0 +1,269 @@
+// Copyright 2025 CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"cmp"
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"io"
+	"path/filepath"
+	"slices"
+	"strings"
+	"text/tabwriter"
+
+	"cuelang.org/go/cue/ast"
+	"cuelang.org/go/cue/errors"
+	"cuelang.org/go/cue/format"
+	"cuelang.org/go/cue/token"
+)
+
+// Summary formats.
+const (
+	summaryText = "text"
+	summaryJSON = "json"
+	summaryHTML = "html"
+)
+
+// maxSummaryFields is the number of offending fields listed in a summary.
+const maxSummaryFields = 10
+
+// vetSummary aggregates the results of cue vet, to give an overview of
+// the quality of a large number of inputs.
+type vetSummary struct {
+	format string
+
+	files    int // the number of files checked
+	errors   int
+	warnings int
+
+	rules   map[string]*summaryCount
+	schemas map[string]*summaryCount
+	inFiles map[string]*summaryCount
+	fields  map[string]*summaryCount
+}
+
+// summaryCount holds the number of diagnostics for one rule, schema,
+// file, or field.
+type summaryCount struct {
+	Name     string `json:"name"`
+	Errors   int    `json:"errors"`
+	Warnings int    `json:"warnings"`
+}
+
+func (c *summaryCount) total() int { return c.Errors + c.Warnings }
+
+func newVetSummary(format string) (*vetSummary, error) {
+	switch format {
+	case summaryText, summaryJSON, summaryHTML:
+	default:
+		return nil, fmt.Errorf("unknown summary format %q; must be one of %q, %q, or %q",
+			format, summaryText, summaryJSON, summaryHTML)
+	}
+	return &vetSummary{
+		format:  format,
+		rules:   map[string]*summaryCount{},
+		schemas: map[string]*summaryCount{},
+		inFiles: map[string]*summaryCount{},
+		fields:  map[string]*summaryCount{},
+	}, nil
+}
+
+// add records the diagnostics in errs, found when checking against the
+// given schema, which may be empty if unknown.
+func (s *vetSummary) add(errs []errors.Error, severity, schema string, cfg *errors.Config) {
+	for _, e := range errs {
+		inc := func(m map[string]*summaryCount, name string) {
+			if name == "" {
+				return
+			}
+			c := m[name]
+			if c == nil {
+				c = &summaryCount{Name: name}
+				m[name] = c
+			}
+			if severity == severityWarning {
+				c.Warnings++
+			} else {
+				c.Errors++
+			}
+		}
+		if severity == severityWarning {
+			s.warnings++
+		} else {
+			s.errors++
+		}
+		inc(s.rules, diagnosticRuleFor(e).id)
+		inc(s.schemas, schema)
+		if pos := failingPos(e); pos.IsValid() {
+			inc(s.inFiles, diagnosticFilename(pos.Filename(), cfg))
+		}
+		inc(s.fields, strings.Join(e.Path(), "."))
+	}
+}
+
+// failingPos returns the position of the input which caused e.
+// Errors in data files usually refer to both the data and the schema,
+// so positions within data files are preferred over those within
+// CUE files.
+func failingPos(e errors.Error) token.Pos {
+	positions := append([]token.Pos{e.Position()}, e.InputPositions()...)
+	positions = slices.DeleteFunc(positions, func(p token.Pos) bool {
+		return !p.IsValid()
+	})
+	for _, p := range positions {
+		if filepath.Ext(p.Filename()) != ".cue" {
+			return p
+		}
+	}
+	if len(positions) > 0 {
+		return positions[0]
+	}
+	return token.NoPos
+}
+
+// sortedCounts returns the counts in m, with the most diagnostics first.
+func sortedCounts(m map[string]*summaryCount) []*summaryCount {
+	a := make([]*summaryCount, 0, len(m))
+	for _, c := range m {
+		a = append(a, c)
+	}
+	slices.SortFunc(a, func(a, b *summaryCount) int {
+		if c := cmp.Compare(b.total(), a.total()); c != 0 {
+			return c
+		}
+		return cmp.Compare(a.Name, b.Name)
+	})
+	return a
+}
+
+// vetSummaryReport is the rendered form of a vetSummary.
+type vetSummaryReport struct {
+	Files    int             `json:"files"`
+	Errors   int             `json:"errors"`
+	Warnings int             `json:"warnings"`
+	Rules    []*summaryCount `json:"rules"`
+	Schemas  []*summaryCount `json:"schemas"`
+	InFiles  []*summaryCount `json:"failingFiles"`
+	Fields   []*summaryCount `json:"topFields"`
+}
+
+func (s *vetSummary) report() *vetSummaryReport {
+	fields := sortedCounts(s.fields)
+	if len(fields) > maxSummaryFields {
+		fields = fields[:maxSummaryFields]
+	}
+	return &vetSummaryReport{
+		Files:    s.files,
+		Errors:   s.errors,
+		Warnings: s.warnings,
+		Rules:    sortedCounts(s.rules),
+		Schemas:  sortedCounts(s.schemas),
+		InFiles:  sortedCounts(s.inFiles),
+		Fields:   fields,
+	}
+}
+
+// summarySection is a table of counts within a summary.
+type summarySection struct {
+	Title  string
+	Counts []*summaryCount
+}
+
+func (r *vetSummaryReport) sections() []summarySection {
+	return []summarySection{
+		{"rule", r.Rules},
+		{"schema", r.Schemas},
+		{"file", r.InFiles},
+		{"field", r.Fields},
+	}
+}
+
+// write writes the summary in its format to w.
+func (s *vetSummary) write(w io.Writer) error {
+	rep := s.report()
+	switch s.format {
+	case summaryJSON:
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "    ")
+		enc.SetEscapeHTML(false)
+		return enc.Encode(rep)
+	case summaryHTML:
+		return summaryHTMLTemplate.Execute(w, struct {
+			*vetSummaryReport
+			Sections []summarySection
+		}{rep, rep.sections()})
+	}
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	fmt.Fprintf(tw, "files checked:\t%d\n", rep.Files)
+	fmt.Fprintf(tw, "errors:\t%d\n", rep.Errors)
+	fmt.Fprintf(tw, "warnings:\t%d\n", rep.Warnings)
+	for _, section := range rep.sections() {
+		if len(section.Counts) == 0 {
+			continue
+		}
+		fmt.Fprintf(tw, "\n%s\terrors\twarnings\n", section.Title)
+		for _, c := range section.Counts {
+			fmt.Fprintf(tw, "%s\t%d\t%d\n", c.Name, c.Errors, c.Warnings)
+		}
+	}
+	return tw.Flush()
+}
+
+var summaryHTMLTemplate = template.Must(template.New("summary").Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>cue vet summary</title>
+</head>
+<body>
+<h1>cue vet summary</h1>
+<table>
+<tr><th>files checked</th><td>{{.Files}}</td></tr>
+<tr><th>errors</th><td>{{.Errors}}</td></tr>
+<tr><th>warnings</th><td>{{.Warnings}}</td></tr>
+</table>
+{{- range .Sections}}{{if .Counts}}
+<table>
+<tr><th>{{.Title}}</th><th>errors</th><th>warnings</th></tr>
+{{- range .Counts}}
+<tr><td>{{.Name}}</td><td>{{.Errors}}</td><td>{{.Warnings}}</td></tr>
+{{- end}}
+</table>
+{{- end}}{{end}}
+</body>
+</html>
+`))
+
+// schemaLabel returns the name by which the schema of b is shown in
+// a summary.
+func schemaLabel(b *buildPlan) string {
+	if b.schema != nil {
+		return exprString(b.schema)
+	}
+	if b.schemaInst != nil {
+		return b.schemaInst.ID()
+	}
+	return ""
+}
+
+func exprString(x ast.Expr) string {
+	data, err := format.Node(x)
+	if err != nil {
+		return ""
+	}
+	return string(data)
+}
@@ -0,0 +1,201 @@
+// Copyright 2025 CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"io"
+	"os"
+	"slices"
+
+	"github.com/spf13/cobra"
+
+	"cuelang.org/go/cue"
+	"cuelang.org/go/cue/errors"
+	"cuelang.org/go/cue/token"
+	"cuelang.org/go/encoding/json"
+)
+
+func newVetTerraformCmd(c *Command) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "terraform <plan.json>...",
+		Short: "validate Terraform plans against policies",
+		Long: `terraform checks the resources of Terraform plans against policy packs.
+
+Each argument is a plan in the JSON format produced by terraform show, or
+- to read it from standard input:
+
+  terraform plan -out plan.tfplan
+  terraform show -json plan.tfplan > plan.json
+  cue vet terraform plan.json --policy ./policies
+
+Every resource which the plan creates, updates, or keeps is checked
+against the rules of the policy packs given with --policy, as described
+in 'cue help vet'. Resources which are only deleted are not checked.
+The input field of a policy pack is set to a struct describing the
+resource:
+
+  address        the address of the resource, such as aws_s3_bucket.logs
+  module_address the address of its module, if not the root module
+  mode           "managed" for resources and "data" for data sources
+  type           the resource type, such as aws_s3_bucket
+  name           the resource name
+  index          the count or for_each index of the resource, if any
+  provider_name  the provider, such as registry.terraform.io/hashicorp/aws
+  actions        the planned actions, such as ["create"]
+  values         the planned attribute values of the resource
+
+Attribute values which are only known after the plan is applied are
+absent from values. Rules typically select the resources they apply to
+using the type field:
+
+  rules: "s3-private": {
+  	check: {
+  		if input.type == "aws_s3_bucket" {values: acl: "private"}
+  	}
+  	message: "bucket \(input.address) must be private"
+  }
+
+Violations are reported with positions within the plan files, so that
+the sarif, json, and other formats of --error-format can be used to
+integrate the checks into Terraform pipelines.
+`,
+		Args: cobra.MinimumNArgs(1),
+		RunE: mkRunE(c, runVetTerraform),
+	}
+
+	cmd.Flags().StringArray(string(flagPolicy), nil,
+		"check resources against the rules of a policy pack package")
+	cmd.Flags().String(string(flagErrorFormat), diagText,
+		"format for reporting errors (text|short|json|sarif|github|gitlab)")
+	cmd.Flags().Int(string(flagMaxErrors), 0,
+		"stop after reporting this many errors, or 0 for no limit")
+	cmd.Flags().StringArray(string(flagExitCode), nil,
+		"exit code for a kind of failure (invalid|error|warning=code)")
+
+	return cmd
+}
+
+// terraformFields lists the fields of a resource change in a plan
+// which are copied to the input of policy packs.
+var terraformFields = []string{
+	"address",
+	"module_address",
+	"mode",
+	"type",
+	"name",
+	"index",
+	"provider_name",
+}
+
+func runVetTerraform(cmd *Command, args []string) error {
+	r, err := newDiagReporter(cmd, flagErrorFormat.String(cmd))
+	if err != nil {
+		return err
+	}
+	r.maxErrors = flagMaxErrors.Int(cmd)
+	if r.exitCodes, err = parseExitCodes(defaultExitCodes, flagExitCode.StringArray(cmd)); err != nil {
+		return err
+	}
+	policies, err := loadPolicies(cmd, cmd.ctx)
+	if err != nil {
+		return r.fail(err)
+	}
+	if len(policies) == 0 {
+		return r.fail(errors.Newf(token.NoPos, "vet terraform requires at least one --%s", flagPolicy))
+	}
+
+	r.checked(len(args))
+	for _, file := range args {
+		if r.full() {
+			break
+		}
+		resources, err := terraformResources(cmd, file)
+		if err != nil {
+			r.toolError(err)
+			continue
+		}
+		for _, v := range resources {
+			if r.full() {
+				break
+			}
+			checkPolicies(v, policies, r)
+		}
+	}
+	return r.flush()
+}
+
+// terraformResources reads the plan in the given file and returns the
+// policy input for each of the resources it does not delete.
+func terraformResources(cmd *Command, file string) ([]cue.Value, error) {
+	var data []byte
+	var err error
+	if file == "-" {
+		data, err = io.ReadAll(cmd.InOrStdin())
+	} else {
+		data, err = os.ReadFile(file)
+	}
+	if err != nil {
+		return nil, err
+	}
+	expr, err := json.Extract(file, data)
+	if err != nil {
+		return nil, err
+	}
+	plan := cmd.ctx.BuildExpr(expr)
+	if !plan.LookupPath(cue.ParsePath("format_version")).Exists() {
+		return nil, errors.Newf(plan.Pos(), "%s is not a Terraform plan in JSON format", file)
+	}
+	changes := plan.LookupPath(cue.ParsePath("resource_changes"))
+	if !changes.Exists() {
+		return nil, nil
+	}
+	iter, err := changes.List()
+	if err != nil {
+		return nil, err
+	}
+
+	var resources []cue.Value
+	for iter.Next() {
+		rc := iter.Value()
+		change := rc.LookupPath(cue.ParsePath("change"))
+		var actions []string
+		if err := change.LookupPath(cue.ParsePath("actions")).Decode(&actions); err != nil {
+			return nil, err
+		}
+		after := change.LookupPath(cue.ParsePath("after"))
+		if slices.Equal(actions, []string{"delete"}) || !after.Exists() || after.IsNull() {
+			continue
+		}
+		input := cmd.ctx.CompileString("{}")
+		for _, name := range terraformFields {
+			p := cue.MakePath(cue.Str(name))
+			if v := rc.LookupPath(p); v.Exists() {
+				input = input.FillPath(p, v)
+			}
+		}
+		input = input.FillPath(cue.ParsePath("actions"), change.LookupPath(cue.ParsePath("actions")))
+		input = input.FillPath(cue.ParsePath("values"), after)
+
+		// Place the input at its address, so that errors identify
+		// the resource by their path.
+		addr, err := rc.LookupPath(cue.ParsePath("address")).String()
+		if err != nil {
+			return nil, err
+		}
+		p := cue.MakePath(cue.Str(addr))
+		resources = append(resources, cmd.ctx.CompileString("{}").FillPath(p, input).LookupPath(p))
+	}
+	return resources, nil
+}
@@ -0,0 +1,157 @@
+// Copyright 2025 CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"fmt"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"cuelang.org/go/cue/build"
+)
+
+// vetWatch holds the state kept between the runs of cue vet --watch.
+type vetWatch struct {
+	// b is the build plan of the last full run.
+	b *buildPlan
+
+	// changed holds the absolute paths of the files which changed since
+	// the last run. It is nil for the first run.
+	changed []string
+}
+
+// watchVet runs vet, and then runs it again whenever the files it may
+// depend on change, until interrupted. Each run which loads the inputs
+// anew uses a new context, such that the values of earlier runs can be
+// freed. Only a run which reuses the schema of the last one, as only data
+// files changed, keeps using its context.
+func watchVet(cmd *Command, args []string) error {
+	ws := &vetWatch{}
+	return watchInputs(cmd, func() []string { return ws.roots(args) }, nil, func(changed []string) error {
+		if changed == nil {
+			return runVet(cmd, args, ws)
+		}
+		ws.changed = changed
+		switch flagErrorFormat.String(cmd) {
+		case diagText, diagShort:
+			fmt.Fprintf(cmd.OutOrStderr(), "--- %d file(s) changed; checking again\n", len(changed))
+		}
+		if ws.changedDataFiles() == nil {
+			cmd.ctx = newContext()
+		}
+		return runVet(cmd, args, ws)
+	})
+}
+
+// roots returns the directories to watch for vet with the given
+// arguments: those returned by watchRoots, along with the roots of any
+// other modules of the packages checked.
+func (ws *vetWatch) roots(args []string) []string {
+	roots := watchRoots(args)
+	if ws.b == nil {
+		return roots
+	}
+	insts := ws.b.insts
+	if ws.b.schemaInst != nil {
+		insts = append(insts[:len(insts):len(insts)], ws.b.schemaInst)
+	}
+	for _, inst := range insts {
+		if inst.Root == "" || slices.ContainsFunc(roots, func(dir string) bool {
+			return within(inst.Root, dir)
+		}) {
+			continue
+		}
+		roots = append(roots, inst.Root)
+	}
+	return roots
+}
+
+// changedDataFiles returns a build plan for only those data files which
+// changed since the last run, if no other files changed. Otherwise it
+// returns nil, and the inputs should be loaded again.
+func (ws *vetWatch) changedDataFiles() *buildPlan {
+	if ws == nil || ws.b == nil || ws.changed == nil || len(ws.b.orphaned) == 0 {
+		return nil
+	}
+	var orphaned []*decoderInfo
+	for _, path := range ws.changed {
+		i := slices.IndexFunc(ws.b.orphaned, func(d *decoderInfo) bool {
+			return absPath(d.file.Filename) == path
+		})
+		if i < 0 {
+			return nil
+		}
+		orphaned = append(orphaned, &decoderInfo{file: ws.b.orphaned[i].file})
+	}
+	b := *ws.b
+	b.orphaned = orphaned
+	return &b
+}
+
+// update records the build plan b of a full run. If only some files
+// changed since the last run, it removes any instances from b which
+// cannot be affected by them.
+func (ws *vetWatch) update(b *buildPlan) {
+	if ws == nil {
+		return
+	}
+	ws.b = b
+	if ws.changed == nil {
+		return
+	}
+	b.insts = slices.DeleteFunc(b.insts, func(inst *build.Instance) bool {
+		return !ws.affects(inst, map[*build.Instance]bool{})
+	})
+}
+
+// affects reports whether any of the changed files may affect inst.
+func (ws *vetWatch) affects(inst *build.Instance, seen map[*build.Instance]bool) bool {
+	if seen[inst] {
+		return false
+	}
+	seen[inst] = true
+	for _, path := range ws.changed {
+		// Files added to or removed from a package directory may change
+		// the package, as may any changes to the module itself.
+		if filepath.Dir(path) == inst.Dir ||
+			(inst.Root != "" && within(path, filepath.Join(inst.Root, "cue.mod"))) {
+			return true
+		}
+		for _, f := range inst.BuildFiles {
+			if absPath(f.Filename) == path {
+				return true
+			}
+		}
+	}
+	for _, imp := range inst.Imports {
+		if ws.affects(imp, seen) {
+			return true
+		}
+	}
+	return false
+}
+
+// within reports whether path is dir or a file within it.
+func within(path, dir string) bool {
+	return path == dir || strings.HasPrefix(path, dir+string(filepath.Separator))
+}
+
+func absPath(path string) string {
+	if p, err := filepath.Abs(path); err == nil {
+		return p
+	}
+	return path
+}
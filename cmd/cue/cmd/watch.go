@@ -0,0 +1,237 @@
+// Copyright 2025 CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"maps"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+
+	"cuelang.org/go/internal/filetypes"
+)
+
+// watchQuiet is how long a fileWatcher waits for further changes after
+// a change before reporting them.
+const watchQuiet = 100 * time.Millisecond
+
+// fileWatcher detects changes to the files within a set of directory
+// trees, using the notifications of the operating system. Hidden
+// directories are not watched.
+type fileWatcher struct {
+	w *fsnotify.Watcher
+}
+
+// newFileWatcher returns a watcher for the files within the given
+// directories. It must be closed once it is no longer used.
+func newFileWatcher(roots ...string) (*fileWatcher, error) {
+	fw, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, err
+	}
+	w := &fileWatcher{w: fw}
+	for _, root := range roots {
+		if _, err := w.addTree(root); err != nil {
+			fw.Close()
+			return nil, err
+		}
+	}
+	return w, nil
+}
+
+func (w *fileWatcher) close() error {
+	return w.w.Close()
+}
+
+// addTree watches the directory root and the directories within it, as
+// notifications are only sent for the files directly within a watched
+// directory. It returns the files found within them.
+func (w *fileWatcher) addTree(root string) ([]string, error) {
+	var files []string
+	err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if path == root {
+				return err
+			}
+			// Files may be removed while walking; ignore them.
+			return nil
+		}
+		if !d.IsDir() {
+			files = append(files, path)
+			return nil
+		}
+		if path != root && strings.HasPrefix(d.Name(), ".") {
+			return filepath.SkipDir
+		}
+		return w.w.Add(path)
+	})
+	return files, err
+}
+
+// wait blocks until any files change, returning the sorted list of the
+// files which were added, modified, or removed, or until ctx is done.
+// Changes which happen in quick succession, such as when an editor saves
+// a file in multiple steps, are combined.
+func (w *fileWatcher) wait(ctx context.Context) ([]string, error) {
+	changed := make(map[string]bool)
+	var quiet <-chan time.Time
+	for {
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case err := <-w.w.Errors:
+			return nil, err
+		case <-quiet:
+			return slices.Sorted(maps.Keys(changed)), nil
+		case ev := <-w.w.Events:
+			if ev.Op == fsnotify.Chmod {
+				continue
+			}
+			changed[ev.Name] = true
+			if info, err := os.Lstat(ev.Name); err == nil && info.IsDir() && ev.Has(fsnotify.Create) &&
+				!strings.HasPrefix(info.Name(), ".") {
+				// Files may have been added to the new directory before
+				// it was watched.
+				files, _ := w.addTree(ev.Name)
+				for _, path := range files {
+					changed[path] = true
+				}
+			}
+			quiet = time.After(watchQuiet)
+		}
+	}
+}
+
+// watchRoots returns the directories to watch for changes to the inputs
+// of a command with the given arguments: the root of the module
+// containing the current directory, or the current directory if there
+// is none, along with those of the files and directories among args
+// outside of it. For the latter, the root of their module is watched, if
+// they are within one.
+func watchRoots(args []string) []string {
+	roots := []string{rootWorkingDir()}
+	if root, err := findModuleRoot(); err == nil {
+		roots[0] = root
+	}
+	for _, arg := range args {
+		path, _ := strings.CutSuffix(filepath.FromSlash(arg), string(filepath.Separator)+"...")
+		info, err := os.Stat(path)
+		if err != nil {
+			// Not a file, such as an import path or a file type.
+			continue
+		}
+		dir := absPath(path)
+		if !info.IsDir() {
+			dir = filepath.Dir(dir)
+		}
+		if root, err := findModuleRootFrom(dir); err == nil {
+			dir = root
+		}
+		if slices.ContainsFunc(roots, func(root string) bool { return within(dir, root) }) {
+			continue
+		}
+		roots = slices.DeleteFunc(roots, func(root string) bool { return within(root, dir) })
+		roots = append(roots, dir)
+	}
+	return roots
+}
+
+// watchCommand runs run, and then runs it again whenever any input files
+// within roots change, until interrupted, as described by [watchInputs].
+// Each run after the first uses a new context, such that the values of
+// earlier runs can be freed.
+func watchCommand(cmd *Command, roots, outputs []string, verb string, run func() error) error {
+	return watchInputs(cmd, func() []string { return roots }, outputs, func(changed []string) error {
+		if changed != nil {
+			fmt.Fprintf(cmd.OutOrStderr(), "--- %d file(s) changed; %s again\n", len(changed), verb)
+			cmd.ctx = newContext()
+		}
+		return run()
+	})
+}
+
+// watchInputs calls run, and then calls it again whenever any input files
+// within the directories returned by roots change, until interrupted.
+// Roots is called after each run, such that the directories watched may
+// depend on the inputs loaded by run. Run is passed the paths of the
+// input files which changed since its last call, or nil on the first
+// call. The errors of a run are printed rather than stopping the command.
+// Changes to outputs, the absolute paths of the files written by run, are
+// ignored.
+func watchInputs(cmd *Command, roots func() []string, outputs []string, run func(changed []string) error) error {
+	ctx, stop := signal.NotifyContext(cmd.Context(), os.Interrupt)
+	defer stop()
+
+	watched := roots()
+	w, err := newFileWatcher(watched...)
+	if err != nil {
+		return err
+	}
+	defer w.close()
+	var changed []string
+	for {
+		var exitErr *exitError
+		if err := run(changed); err != nil && err != ErrPrintedError && !errors.As(err, &exitErr) {
+			printError(cmd, err)
+		}
+		for _, root := range roots() {
+			if slices.ContainsFunc(watched, func(dir string) bool { return within(root, dir) }) {
+				continue
+			}
+			if _, err := w.addTree(root); err != nil {
+				return err
+			}
+			watched = append(watched, root)
+		}
+		changed = nil
+		for len(changed) == 0 {
+			var err error
+			if changed, err = w.wait(ctx); err != nil {
+				if ctx.Err() != nil {
+					return nil // interrupted
+				}
+				return err
+			}
+			changed = slices.DeleteFunc(changed, func(path string) bool {
+				if slices.Contains(outputs, path) {
+					return true
+				}
+				_, err := filetypes.ParseFile(path, filetypes.Input)
+				return err != nil
+			})
+		}
+	}
+}
+
+// watchOutputs returns the absolute paths of the files given with
+// --outfile, which are written by each run of a watched command.
+func watchOutputs(cmd *Command, mode filetypes.Mode) []string {
+	var paths []string
+	for _, out := range flagOutFile.StringArray(cmd) {
+		if f, err := filetypes.ParseFile(out, mode); err == nil && f.Filename != "-" {
+			paths = append(paths, absPath(f.Filename))
+		}
+	}
+	return paths
+}
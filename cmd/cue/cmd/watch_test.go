@@ -0,0 +1,131 @@
+// Copyright 2025 CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/go-quicktest/qt"
+	"github.com/spf13/cobra"
+)
+
+func TestFileWatcherChanges(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, content string) string {
+		path := filepath.Join(dir, name)
+		qt.Assert(t, qt.IsNil(os.MkdirAll(filepath.Dir(path), 0o777)))
+		qt.Assert(t, qt.IsNil(os.WriteFile(path, []byte(content), 0o666)))
+		return path
+	}
+	a := write("a.cue", "a: 1")
+	b := write("sub/b.json", `{"b": 1}`)
+	write(".git/HEAD", "ref")
+
+	w, err := newFileWatcher(dir)
+	qt.Assert(t, qt.IsNil(err))
+	defer w.close()
+	wait := func(timeout time.Duration) ([]string, error) {
+		ctx, cancel := context.WithTimeout(context.Background(), timeout)
+		defer cancel()
+		return w.wait(ctx)
+	}
+
+	write("a.cue", "a: 12")
+	c := write("c.yaml", "c: 1")
+	qt.Assert(t, qt.IsNil(os.Remove(b)))
+	write(".git/HEAD", "other ref")
+	// Files within new directories are watched as well.
+	d := write("new/d.cue", "d: 1")
+	changed, err := wait(10 * time.Second)
+	qt.Assert(t, qt.IsNil(err))
+	qt.Assert(t, qt.DeepEquals(changed, []string{a, c, filepath.Dir(d), d, b}))
+
+	_, err = wait(2 * watchQuiet)
+	qt.Assert(t, qt.ErrorIs(err, context.DeadlineExceeded))
+
+	e := write("new/e.cue", "e: 1")
+	changed, err = wait(10 * time.Second)
+	qt.Assert(t, qt.IsNil(err))
+	qt.Assert(t, qt.DeepEquals(changed, []string{e}))
+}
+
+func TestWatchRoots(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"mod/cue.mod/module.cue", "mod/pkg/x.cue", "other/cue.mod/module.cue", "other/pkg/y.cue", "data/z.json"} {
+		path := filepath.Join(dir, name)
+		qt.Assert(t, qt.IsNil(os.MkdirAll(filepath.Dir(path), 0o777)))
+		qt.Assert(t, qt.IsNil(os.WriteFile(path, nil, 0o666)))
+	}
+	wd, err := os.Getwd()
+	qt.Assert(t, qt.IsNil(err))
+	pkg := filepath.Join(dir, "mod", "pkg")
+	qt.Assert(t, qt.IsNil(os.Chdir(pkg)))
+	defer os.Chdir(wd)
+	defer func(f func() string) { rootWorkingDir = f }(rootWorkingDir)
+	rootWorkingDir = func() string { return pkg }
+
+	roots := watchRoots([]string{"./...", "example.com/foo", "../../other/pkg/...", "../../data/z.json", "json:"})
+	qt.Assert(t, qt.DeepEquals(roots, []string{
+		filepath.Join(dir, "mod"),
+		filepath.Join(dir, "other"),
+		filepath.Join(dir, "data"),
+	}))
+}
+
+func TestWatchCommand(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, content string) string {
+		path := filepath.Join(dir, name)
+		qt.Assert(t, qt.IsNil(os.WriteFile(path, []byte(content), 0o666)))
+		return path
+	}
+	write("a.cue", "a: 1")
+	out := write("out.json", "{}")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	var msgs bytes.Buffer
+	cmd := &Command{Command: &cobra.Command{}}
+	cmd.SetContext(ctx)
+	cmd.SetOut(&msgs)
+
+	runs := make(chan int)
+	done := make(chan error)
+	n := 0
+	go func() {
+		done <- watchCommand(cmd, []string{dir}, []string{out}, "testing", func() error {
+			n++
+			runs <- n
+			return nil
+		})
+	}()
+	qt.Assert(t, qt.Equals(<-runs, 1))
+
+	// Changes to the output files and to files which are not inputs do
+	// not cause another run.
+	write("out.json", `{"a": 1}`)
+	write("notes.unknown", "notes")
+	write("a.cue", "a: 2")
+	qt.Assert(t, qt.Equals(<-runs, 2))
+	qt.Assert(t, qt.Equals(msgs.String(), "--- 1 file(s) changed; testing again\n"))
+
+	cancel()
+	qt.Assert(t, qt.IsNil(<-done))
+}
@@ -16,6 +16,8 @@ package cue
 
 import (
 	"cmp"
+	goruntime "runtime"
+	"sync"
 
 	"cuelang.org/go/cue/ast"
 	"cuelang.org/go/cue/ast/astutil"
@@ -97,6 +99,18 @@ func ImportPath(path string) BuildOption {
 	return func(o *runtime.Config) { o.ImportPath = path }
 }
 
+// Parallelism sets the maximum number of instances that
+// [Context.BuildInstances] builds concurrently. The default, or a value of
+// zero or less, uses the number of available CPUs. A value of 1 builds the
+// instances one after the other.
+//
+// Only compiling the instances runs in parallel. The values returned are
+// evaluated lazily, as usual, by the goroutine which uses them, and, as the
+// values of a Context may not be evaluated concurrently, one at a time.
+func Parallelism(n int) BuildOption {
+	return func(o *runtime.Config) { o.Parallelism = n }
+}
+
 // InferBuiltins allows unresolved references to bind to builtin packages with a
 // unique package name.
 //
@@ -142,19 +156,60 @@ func (c *Context) makeError(err errors.Error) Value {
 
 // BuildInstances creates a [Value] for each of the given [*build.Instance]s and reports
 // the combined errors or nil if there were no errors.
-func (c *Context) BuildInstances(instances []*build.Instance) ([]Value, error) {
-	var errs errors.Error
-	var a []Value
+//
+// Independent instances are compiled concurrently, as limited by the
+// [Parallelism] option, while dependencies which they share are compiled
+// only once.
+func (c *Context) BuildInstances(instances []*build.Instance, options ...BuildOption) ([]Value, error) {
+	cfg := c.parseOptions(options)
+	n := cfg.Parallelism
+	if n <= 0 {
+		n = goruntime.GOMAXPROCS(0)
+	}
+	n = min(n, len(instances))
+
+	// Completing an instance may load its imports, which is not safe for
+	// concurrent use, so do this upfront.
 	for _, b := range instances {
-		v, err := c.runtime().Build(nil, b)
-		if err != nil {
-			errs = errors.Append(errs, err)
-			a = append(a, c.makeError(err))
+		_ = b.Complete()
+	}
+
+	vs := make([]*adt.Vertex, len(instances))
+	errs := make([]errors.Error, len(instances))
+	if n <= 1 {
+		for i, b := range instances {
+			vs[i], errs[i] = c.runtime().Build(&cfg, b)
+		}
+	} else {
+		var wg sync.WaitGroup
+		next := make(chan int)
+		for range n {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for i := range next {
+					vs[i], errs[i] = c.runtime().Build(&cfg, instances[i])
+				}
+			}()
+		}
+		for i := range instances {
+			next <- i
+		}
+		close(next)
+		wg.Wait()
+	}
+
+	var err errors.Error
+	a := make([]Value, len(instances))
+	for i, v := range vs {
+		if errs[i] != nil {
+			err = errors.Append(err, errs[i])
+			a[i] = c.makeError(errs[i])
 		} else {
-			a = append(a, c.make(v))
+			a[i] = c.make(v)
 		}
 	}
-	return a, errs
+	return a, err
 }
 
 // BuildFile creates a [Value] from f.
@@ -16,6 +16,7 @@ package cue_test
 
 import (
 	"fmt"
+	"strings"
 	"testing"
 
 	"cuelang.org/go/cue"
@@ -141,6 +142,42 @@ bar: [
 	}
 }
 
+func TestBuildInstancesConcurrent(t *testing.T) {
+	var buf strings.Builder
+	buf.WriteString(`
+-- cue.mod/module.cue --
+module: "mod.test"
+language: version: "v0.9.0"
+-- lib/lib.cue --
+package lib
+
+#N: int & >=0
+`)
+	var args []string
+	for i := range 20 {
+		fmt.Fprintf(&buf, "-- p%d/p.cue --\npackage p\n\nimport \"mod.test/lib\"\n\nn: lib.#N & %d\n", i, i)
+		args = append(args, fmt.Sprintf("./p%d", i))
+	}
+	buf.WriteString("-- bad/p.cue --\npackage p\n\nn: missing\n")
+	args = append(args, "./bad")
+
+	for _, n := range []int{0, 1, 4} {
+		t.Run(fmt.Sprint(n), func(t *testing.T) {
+			a := txtar.Parse([]byte(buf.String()))
+			insts := cuetxtar.Load(a, t.TempDir(), args...)
+			vs, err := cuecontext.New().BuildInstances(insts, cue.Parallelism(n))
+			qt.Assert(t, qt.ErrorMatches(err, `reference "missing" not found`))
+			qt.Assert(t, qt.HasLen(vs, 21))
+			for i, v := range vs[:20] {
+				got, err := v.LookupPath(cue.ParsePath("n")).Int64()
+				qt.Assert(t, qt.IsNil(err))
+				qt.Assert(t, qt.Equals(got, int64(i)))
+			}
+			qt.Assert(t, qt.IsNotNil(vs[20].Err()))
+		})
+	}
+}
+
 func TestEncodeType(t *testing.T) {
 	type testCase struct {
 		name    string
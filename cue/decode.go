@@ -19,6 +19,7 @@ import (
 	"cmp"
 	"encoding"
 	"encoding/json"
+	"iter"
 	"reflect"
 	"slices"
 	"strconv"
@@ -50,6 +51,59 @@ func (v Value) Decode(x interface{}) error {
 	return d.errs
 }
 
+// DecodeAs validates v and decodes it into a new value of type T.
+//
+// Validation reports any errors in v, including those in fields that
+// are not decoded into T. As with [Value.Decode], T may be any type
+// accepted by Decode, such as a struct, a slice, or a map whose key
+// type is a string, an integer, or an [encoding.TextUnmarshaler].
+// This allows a struct with arbitrary field names to be decoded into a
+// map[K]V with typed keys.
+func DecodeAs[T any](v Value) (T, error) {
+	var x T
+	if err := v.Validate(); err != nil {
+		return x, err
+	}
+	err := v.Decode(&x)
+	return x, err
+}
+
+// Iterate returns an iterator that decodes each element of list v, or
+// each regular field of struct v, into a value of type T using
+// [DecodeAs]. An element which cannot be decoded is yielded along with
+// its error and the zero value of T, after which iteration continues.
+// If v is neither a list nor a struct, the iterator yields a single
+// error.
+func Iterate[T any](v Value) iter.Seq2[T, error] {
+	return func(yield func(T, error) bool) {
+		var elems *Iterator
+		var err error
+		switch v.IncompleteKind() {
+		case ListKind:
+			var list Iterator
+			list, err = v.List()
+			elems = &list
+		case StructKind:
+			elems, err = v.Fields()
+		default:
+			err = v.Err()
+			if err == nil {
+				err = errors.Newf(v.Pos(), "cannot iterate over value of type %v", v.IncompleteKind())
+			}
+		}
+		if err != nil {
+			var zero T
+			yield(zero, err)
+			return
+		}
+		for elems.Next() {
+			if !yield(DecodeAs[T](elems.Value())) {
+				return
+			}
+		}
+	}
+}
+
 type decoder struct {
 	errs errors.Error
 }
@@ -347,7 +401,7 @@ func (d *decoder) convertMap(x reflect.Value, v Value) {
 		if reflect.PointerTo(kt).Implements(textUnmarshalerType) {
 			kv = reflect.New(kt)
 			err := kv.Interface().(encoding.TextUnmarshaler).UnmarshalText([]byte(key))
-			d.addErr(err)
+			d.addErr(keyError(iter.Value(), key, kt, err))
 			kv = kv.Elem()
 		} else {
 			switch kt.Kind() {
@@ -355,7 +409,10 @@ func (d *decoder) convertMap(x reflect.Value, v Value) {
 				kv = reflect.ValueOf(key).Convert(kt)
 			case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
 				n, err := strconv.ParseInt(key, 10, 64)
-				d.addErr(err)
+				if err != nil {
+					d.addErr(keyError(iter.Value(), key, kt, err))
+					break
+				}
 				if kt.OverflowInt(n) {
 					d.addErr(errors.Newf(v.Pos(), "key integer %d overflows %s", n, kt))
 					break
@@ -364,7 +421,10 @@ func (d *decoder) convertMap(x reflect.Value, v Value) {
 
 			case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
 				n, err := strconv.ParseUint(key, 10, 64)
-				d.addErr(err)
+				if err != nil {
+					d.addErr(keyError(iter.Value(), key, kt, err))
+					break
+				}
 				if kt.OverflowUint(n) {
 					d.addErr(errors.Newf(v.Pos(), "key integer %d overflows %s", n, kt))
 					break
@@ -389,6 +449,15 @@ func (d *decoder) convertMap(x reflect.Value, v Value) {
 	}
 }
 
+// keyError returns an error describing why the label of the field with
+// value v could not be decoded as a map key of type t, or nil if err is nil.
+func keyError(v Value, key string, t reflect.Type, err error) error {
+	if err == nil {
+		return nil
+	}
+	return errors.Wrapf(err, v.Pos(), "cannot decode field %q as key of type %v", key, t)
+}
+
 func (d *decoder) convertStruct(x reflect.Value, v Value) {
 	t := x.Type()
 	fields := cachedTypeFields(t)
@@ -320,6 +320,59 @@ func TestDecodeIntoCUEValue(t *testing.T) {
 	})
 }
 
+func TestDecodeAs(t *testing.T) {
+	cuetdtest.FullMatrix.Do(t, func(t *testing.T, m *cuetdtest.M) {
+		type Service struct {
+			Port int `json:"port"`
+		}
+		got, err := cue.DecodeAs[map[string]Service](getValue(m, `
+			web: port: 80
+			db: port: 5432
+			`))
+		qt.Assert(t, qt.IsNil(err))
+		qt.Assert(t, qt.DeepEquals(got, map[string]Service{"web": {80}, "db": {5432}}))
+
+		// Map keys are decoded into their type.
+		ports, err := cue.DecodeAs[map[uint16]string](getValue(m, `"80": "http", "443": "https"`))
+		qt.Assert(t, qt.IsNil(err))
+		qt.Assert(t, qt.DeepEquals(ports, map[uint16]string{80: "http", 443: "https"}))
+
+		_, err = cue.DecodeAs[map[uint16]string](getValue(m, `"80": "http", web: "https"`))
+		qt.Assert(t, qt.ErrorMatches(err, `cannot decode field "web" as key of type uint16: .*invalid syntax`))
+
+		// Errors are reported even in fields which are not decoded.
+		_, err = cue.DecodeAs[Service](getValue(m, `port: 80, other: 1 & 2`))
+		qt.Assert(t, qt.ErrorMatches(err, `other: conflicting values 2 and 1`))
+	})
+}
+
+func TestIterate(t *testing.T) {
+	cuetdtest.FullMatrix.Do(t, func(t *testing.T, m *cuetdtest.M) {
+		var got []int
+		var errs []string
+		for x, err := range cue.Iterate[int](getValue(m, `[1, "two", 3]`)) {
+			if err != nil {
+				errs = append(errs, err.Error())
+				continue
+			}
+			got = append(got, x)
+		}
+		qt.Assert(t, qt.DeepEquals(got, []int{1, 3}))
+		qt.Assert(t, qt.HasLen(errs, 1))
+
+		got = nil
+		for x, err := range cue.Iterate[int](getValue(m, `a: 1, b: 2, #c: 3, d?: 4`)) {
+			qt.Assert(t, qt.IsNil(err))
+			got = append(got, x)
+		}
+		qt.Assert(t, qt.DeepEquals(got, []int{1, 2}))
+
+		for _, err := range cue.Iterate[int](getValue(m, `1`)) {
+			qt.Assert(t, qt.ErrorMatches(err, `cannot iterate over value of type int`))
+		}
+	})
+}
+
 type Duration struct {
 	D time.Duration
 }
@@ -79,6 +79,32 @@ func (e *valueError) Msg() (string, []interface{}) {
 	return e.err.Err.Msg()
 }
 
+// Code returns the stable code of the error, if known.
+func (e *valueError) Code() errors.Code {
+	if code := errors.CodeOf(e.err.Err); code != "" {
+		return code
+	}
+	switch e.err.Code {
+	case adt.StructuralCycleError:
+		return errors.StructuralCycle
+	case adt.CycleError:
+		return errors.ReferenceCycle
+	case adt.IncompleteError:
+		return errors.IncompleteValue
+	}
+	return ""
+}
+
+// Suggestions returns alternatives for a mistyped label, if any.
+func (e *valueError) Suggestions() []string {
+	return errors.Suggestions(e.err.Err)
+}
+
+// Hints returns ways to fix the error, if any.
+func (e *valueError) Hints() []errors.Hint {
+	return errors.Hints(e.err.Err)
+}
+
 func (e *valueError) Path() (a []string) {
 	if e.err.Err != nil {
 		a = e.err.Err.Path()
@@ -0,0 +1,179 @@
+// Copyright 2025 CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package errors
+
+import "cuelang.org/go/cue/token"
+
+// A Code identifies a class of errors. Unlike error messages, which may be
+// reworded over time, codes are stable, so that tools and documentation can
+// refer to them.
+//
+// Codes consist of the letter E followed by four digits, where the first
+// digit indicates the category of the error:
+//
+//	1  the value is invalid, for example because of a conflict
+//	2  the value is incomplete, such as a missing required field
+//	3  the value contains a cycle
+//	4  the input could not be parsed or decoded
+//	5  the value is valid, but warrants a warning
+type Code string
+
+// Evaluation errors.
+const (
+	ConflictingValues Code = "E1001" // conflicting values
+	OutOfBound        Code = "E1002" // value out of bound
+	ValidatorFailed   Code = "E1003" // value does not satisfy a validator
+	FieldNotAllowed   Code = "E1004" // field not allowed by a closed struct
+	ExplicitError     Code = "E1005" // explicit error in source
+	InvalidOperation  Code = "E1006" // invalid operation or operands
+	IndexOutOfRange   Code = "E1007" // index out of range
+	UndefinedField    Code = "E1008" // undefined field
+	ReferenceNotFound Code = "E1009" // reference not found
+	EmptyDisjunction  Code = "E1010" // no disjunct matches
+)
+
+// Incompleteness errors.
+const (
+	NonConcreteValue      Code = "E2001" // non-concrete value used where a concrete one is needed
+	IncompleteValue       Code = "E2002" // incomplete value
+	RequiredFieldMissing  Code = "E2003" // required field not present
+	UnresolvedDisjunction Code = "E2004" // disjunction without a default
+)
+
+// Cycle errors.
+const (
+	StructuralCycle Code = "E3001" // structural cycle
+	ReferenceCycle  Code = "E3002" // reference cycle
+)
+
+// Syntax and encoding errors.
+const (
+	SyntaxError Code = "E4001" // CUE syntax error
+	InvalidJSON Code = "E4002" // invalid JSON
+	InvalidYAML Code = "E4003" // invalid YAML
+)
+
+// Warnings, as reported by [cuelang.org/go/cue.Value.Warnings].
+const (
+	DeprecatedField Code = "E5001" // a deprecated field is set
+	LossyConversion Code = "E5002" // number loses precision when converted
+)
+
+var codeDescriptions = map[Code]string{
+	ConflictingValues:     "conflicting values",
+	OutOfBound:            "value out of bound",
+	ValidatorFailed:       "value does not satisfy a validator",
+	FieldNotAllowed:       "field not allowed",
+	ExplicitError:         "explicit error",
+	InvalidOperation:      "invalid operation",
+	IndexOutOfRange:       "index out of range",
+	UndefinedField:        "undefined field",
+	ReferenceNotFound:     "reference not found",
+	EmptyDisjunction:      "empty disjunction",
+	NonConcreteValue:      "non-concrete value",
+	IncompleteValue:       "incomplete value",
+	RequiredFieldMissing:  "required field missing",
+	UnresolvedDisjunction: "unresolved disjunction",
+	StructuralCycle:       "structural cycle",
+	ReferenceCycle:        "reference cycle",
+	SyntaxError:           "syntax error",
+	InvalidJSON:           "invalid JSON",
+	InvalidYAML:           "invalid YAML",
+	DeprecatedField:       "deprecated field",
+	LossyConversion:       "lossy conversion",
+}
+
+// Description returns a short description of the class of errors
+// identified by c, or the empty string if c is not a known code.
+func (c Code) Description() string {
+	return codeDescriptions[c]
+}
+
+// Codes returns all known error codes, in order.
+func Codes() []Code {
+	return []Code{
+		ConflictingValues,
+		OutOfBound,
+		ValidatorFailed,
+		FieldNotAllowed,
+		ExplicitError,
+		InvalidOperation,
+		IndexOutOfRange,
+		UndefinedField,
+		ReferenceNotFound,
+		EmptyDisjunction,
+		NonConcreteValue,
+		IncompleteValue,
+		RequiredFieldMissing,
+		UnresolvedDisjunction,
+		StructuralCycle,
+		ReferenceCycle,
+		SyntaxError,
+		InvalidJSON,
+		InvalidYAML,
+		DeprecatedField,
+		LossyConversion,
+	}
+}
+
+// coder is implemented by errors which have a code.
+type coder interface {
+	Code() Code
+}
+
+// CodeOf returns the code of the first error in err's chain which has
+// one, or the empty string if there is none. For a list of errors,
+// it reports the code of the first error.
+func CodeOf(err error) Code {
+	var c coder
+	if As(err, &c) {
+		return c.Code()
+	}
+	return ""
+}
+
+// WithCode returns err with the code c, which takes precedence over
+// any code of the errors wrapped by err. If err is a list of errors,
+// each error in the list is given the code.
+func WithCode(err Error, c Code) Error {
+	switch x := err.(type) {
+	case nil:
+		return nil
+	case list:
+		a := make(list, len(x))
+		for i, e := range x {
+			a[i] = WithCode(e, c)
+		}
+		return a
+	}
+	return &codedError{err, c}
+}
+
+// codedError adds a code to an error. It is otherwise transparent:
+// it does not add a level to the chain of wrapped errors.
+type codedError struct {
+	err  Error
+	code Code
+}
+
+func (e *codedError) Code() Code                   { return e.code }
+func (e *codedError) Position() token.Pos          { return e.err.Position() }
+func (e *codedError) InputPositions() []token.Pos  { return e.err.InputPositions() }
+func (e *codedError) Error() string                { return e.err.Error() }
+func (e *codedError) Path() []string               { return e.err.Path() }
+func (e *codedError) Msg() (string, []interface{}) { return e.err.Msg() }
+func (e *codedError) Is(target error) bool         { return Is(e.err, target) }
+func (e *codedError) As(target interface{}) bool   { return As(e.err, target) }
+func (e *codedError) Unwrap() error                { return Unwrap(e.err) }
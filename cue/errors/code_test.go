@@ -0,0 +1,77 @@
+// Copyright 2025 CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package errors
+
+import (
+	"fmt"
+	"regexp"
+	"testing"
+
+	"cuelang.org/go/cue/token"
+)
+
+func TestCodes(t *testing.T) {
+	valid := regexp.MustCompile(`^E[1-5]\d{3}$`)
+	seen := map[Code]bool{}
+	for _, c := range Codes() {
+		if !valid.MatchString(string(c)) {
+			t.Errorf("invalid code %q", c)
+		}
+		if seen[c] {
+			t.Errorf("duplicate code %q", c)
+		}
+		seen[c] = true
+		if c.Description() == "" {
+			t.Errorf("code %q has no description", c)
+		}
+	}
+	if len(seen) != len(codeDescriptions) {
+		t.Errorf("Codes returns %d codes, but %d have descriptions", len(seen), len(codeDescriptions))
+	}
+}
+
+func TestCodeOf(t *testing.T) {
+	base := Newf(token.NoPos, "unexpected token")
+	coded := WithCode(base, SyntaxError)
+	tests := []struct {
+		name string
+		err  error
+		want Code
+	}{
+		{"nil", nil, ""},
+		{"none", base, ""},
+		{"go", fmt.Errorf("plain"), ""},
+		{"coded", coded, SyntaxError},
+		{"wrapped", Wrapf(coded, token.NoPos, "cannot load"), SyntaxError},
+		{"outer", WithCode(Wrapf(coded, token.NoPos, "invalid JSON"), InvalidJSON), InvalidJSON},
+		{"list", Append(WithCode(base, InvalidYAML), base), InvalidYAML},
+	}
+	for _, tc := range tests {
+		if got := CodeOf(tc.err); got != tc.want {
+			t.Errorf("%s: CodeOf() = %q, want %q", tc.name, got, tc.want)
+		}
+	}
+
+	// A code does not change how the error is reported.
+	if got, want := coded.Error(), base.Error(); got != want {
+		t.Errorf("Error() = %q, want %q", got, want)
+	}
+	if Unwrap(Wrapf(coded, token.NoPos, "cannot load")) != coded {
+		t.Errorf("coded error not found in chain")
+	}
+	if Unwrap(coded) != nil {
+		t.Errorf("code added a level to the error chain")
+	}
+}
@@ -171,6 +171,17 @@ func Path(err error) []string {
 	return nil
 }
 
+// Suggestions returns alternatives for the part of the input which caused
+// err, such as the names of similar fields when a field is not allowed, for
+// use in "did you mean" messages. It returns nil if there are none.
+func Suggestions(err error) []string {
+	var s interface{ Suggestions() []string }
+	if As(err, &s) {
+		return s.Suggestions()
+	}
+	return nil
+}
+
 // Newf creates an Error with the associated position and message.
 func Newf(p token.Pos, format string, args ...interface{}) Error {
 	return &posError{
@@ -494,8 +505,51 @@ type Config struct {
 
 	// ToSlash sets whether to use Unix paths. Mostly used for testing.
 	ToSlash bool
+
+	// Hints sets whether to print hints after each error, such as
+	// suggestions for mistyped names and the messages of any [Hint].
+	Hints bool
+
+	// GroupBy sets how errors are grouped when printed. Each group is
+	// preceded by a header line. Groups are ordered by the position of
+	// their first error.
+	GroupBy Grouping
+
+	// MaxPerGroup, if positive, is the maximum number of errors printed per
+	// group, or in total if the errors are not grouped. The number of errors
+	// omitted from a group is reported after it.
+	MaxPerGroup int
+
+	// Source, if non-nil, returns the contents of the named file. It is used
+	// to print the source line of each error position, with a caret marking
+	// the offending column. Positions for which Source fails are printed
+	// without a snippet.
+	Source func(filename string) ([]byte, error)
+
+	// Color sets whether source snippets are highlighted with ANSI escape
+	// sequences.
+	Color bool
+
+	// Highlight, if non-nil and Color is set, returns a source line with
+	// ANSI escape sequences added to highlight its syntax, such as
+	// [cuelang.org/go/cue/highlight.Source] does.
+	Highlight func(line string) string
 }
 
+// Grouping determines how errors are grouped by [Print].
+type Grouping int
+
+const (
+	// NoGrouping prints errors as a flat list.
+	NoGrouping Grouping = iota
+
+	// GroupByPath groups errors by their path.
+	GroupByPath
+
+	// GroupByCode groups errors by their [Code].
+	GroupByCode
+)
+
 var zeroConfig = &Config{}
 
 // Print is a utility function that prints a list of errors to w,
@@ -505,9 +559,74 @@ func Print(w io.Writer, err error, cfg *Config) {
 	if cfg == nil {
 		cfg = zeroConfig
 	}
-	for _, e := range list(Errors(err)).sanitize() {
-		printError(w, e, cfg)
+	errs := list(Errors(err)).sanitize()
+	if cfg.GroupBy == NoGrouping && cfg.MaxPerGroup <= 0 {
+		for _, e := range errs {
+			printError(w, e, cfg)
+			printHints(w, e, cfg)
+		}
+		return
 	}
+	fprintf := cfg.Format
+	if fprintf == nil {
+		fprintf = defaultFprintf
+	}
+	for _, g := range groupErrors(errs, cfg.GroupBy) {
+		if cfg.GroupBy != NoGrouping {
+			if len(g.errs) == 1 {
+				fprintf(w, "--- %s (1 error)\n", g.name)
+			} else {
+				fprintf(w, "--- %s (%d errors)\n", g.name, len(g.errs))
+			}
+		}
+		shown := g.errs
+		if cfg.MaxPerGroup > 0 && len(shown) > cfg.MaxPerGroup {
+			shown = shown[:cfg.MaxPerGroup]
+		}
+		for _, e := range shown {
+			printError(w, e, cfg)
+			printHints(w, e, cfg)
+		}
+		if n := len(g.errs) - len(shown); n > 0 {
+			fprintf(w, "... and %d more\n", n)
+		}
+	}
+}
+
+type errorGroup struct {
+	name string
+	errs []Error
+}
+
+// groupErrors partitions errs, preserving their order.
+func groupErrors(errs []Error, by Grouping) []*errorGroup {
+	var groups []*errorGroup
+	index := map[string]*errorGroup{}
+	for _, e := range errs {
+		var name string
+		switch by {
+		case GroupByPath:
+			name = strings.Join(e.Path(), ".")
+			if name == "" {
+				name = "(root)"
+			}
+		case GroupByCode:
+			code := CodeOf(e)
+			if code == "" {
+				name = "other errors"
+			} else {
+				name = fmt.Sprintf("%s %s", code, code.Description())
+			}
+		}
+		g := index[name]
+		if g == nil {
+			g = &errorGroup{name: name}
+			index[name] = g
+			groups = append(groups, g)
+		}
+		g.errs = append(g.errs, e)
+	}
+	return groups
 }
 
 // Details is a convenience wrapper for Print to return the error text as a
@@ -526,6 +645,11 @@ func String(err Error) string {
 }
 
 func writeErr(w io.Writer, err Error, cfg *Config) {
+	fprintf := cfg.Format
+	if fprintf == nil {
+		fprintf = defaultFprintf
+	}
+
 	if path := strings.Join(err.Path(), "."); path != "" {
 		_, _ = io.WriteString(w, path)
 		_, _ = io.WriteString(w, ": ")
@@ -560,13 +684,15 @@ func writeErr(w io.Writer, err Error, cfg *Config) {
 			args[i] = pos
 		}
 
-		n, _ := fmt.Fprintf(w, msg, args...)
+		var buf strings.Builder
+		fprintf(&buf, msg, args...)
+		_, _ = io.WriteString(w, buf.String())
 
 		if u == nil {
 			break
 		}
 
-		if n > 0 {
+		if buf.Len() > 0 {
 			_, _ = io.WriteString(w, ": ")
 		}
 		err, _ = u.(Error)
@@ -613,7 +739,64 @@ func printError(w io.Writer, err error, cfg *Config) {
 			fprintf(w, "%d:%d", pos.Line, pos.Column)
 		}
 		fprintf(w, "\n")
+		printSnippet(w, pos, cfg)
+	}
+	printTrace(w, err, cfg)
+}
+
+// printTrace prints the sites which led to err, if known.
+func printTrace(w io.Writer, err error, cfg *Config) {
+	frames := Trace(err)
+	if len(frames) == 0 {
+		return
+	}
+	fprintf := cfg.Format
+	if fprintf == nil {
+		fprintf = defaultFprintf
+	}
+	fprintf(w, "  triggered by:\n")
+	for _, f := range frames {
+		pos := f.Pos.Position()
+		path := relPath(pos.Filename, cfg)
+		fprintf(w, "    %s", path)
+		if pos.IsValid() {
+			if path != "" {
+				fprintf(w, ":")
+			}
+			fprintf(w, "%d:%d", pos.Line, pos.Column)
+		}
+		fprintf(w, ": %s\n", f.Message)
+		printSnippet(w, pos, cfg)
+	}
+}
+
+func printHints(w io.Writer, err error, cfg *Config) {
+	if !cfg.Hints {
+		return
+	}
+	fprintf := cfg.Format
+	if fprintf == nil {
+		fprintf = defaultFprintf
+	}
+	if s := Suggestions(err); len(s) > 0 {
+		fprintf(w, "hint: did you mean %s?\n", orList(s))
+	}
+	for _, h := range Hints(err) {
+		if h.Message != "" {
+			fprintf(w, "hint: %s\n", h.Message)
+		}
+	}
+}
+
+// orList joins a list of alternatives, as in "a, b, or c".
+func orList(a []string) string {
+	switch len(a) {
+	case 1:
+		return a[0]
+	case 2:
+		return a[0] + " or " + a[1]
 	}
+	return strings.Join(a[:len(a)-1], ", ") + ", or " + a[len(a)-1]
 }
 
 func relPath(path string, cfg *Config) string {
@@ -17,6 +17,7 @@ package errors
 import (
 	"bytes"
 	"fmt"
+	"strings"
 	"testing"
 
 	"cuelang.org/go/cue/token"
@@ -163,3 +164,167 @@ func TestPrintError(t *testing.T) {
 		})
 	}
 }
+
+// pathError is an error with a path, like those reported by evaluation.
+type pathError struct {
+	err  Error
+	path []string
+}
+
+func (e *pathError) Error() string                { return e.err.Error() }
+func (e *pathError) Position() token.Pos          { return e.err.Position() }
+func (e *pathError) InputPositions() []token.Pos  { return e.err.InputPositions() }
+func (e *pathError) Path() []string               { return e.path }
+func (e *pathError) Msg() (string, []interface{}) { return e.err.Msg() }
+
+func TestPrintGrouped(t *testing.T) {
+	f := token.NewFile("x.cue", -1, 10)
+	f.SetLinesForContent([]byte("a\nb\nc\nd\ne\n"))
+	line := func(n int) token.Pos { return f.Pos(2*(n-1), token.NoRelPos) }
+	newErr := func(n int, path string, code Code) Error {
+		var err Error = &pathError{Newf(line(n), "error on line %d", n), []string{path}}
+		if code != "" {
+			err = WithCode(err, code)
+		}
+		return err
+	}
+	// Added out of order to check that errors are sorted by position.
+	err := Append(newErr(4, "b", ""), newErr(1, "a", ConflictingValues))
+	err = Append(err, newErr(2, "b", ConflictingValues))
+	err = Append(err, newErr(3, "a", ConflictingValues))
+
+	tests := []struct {
+		name string
+		cfg  Config
+		want string
+	}{{
+		name: "Flat",
+		cfg:  Config{},
+		want: `a: error on line 1:
+    x.cue:1:1
+b: error on line 2:
+    x.cue:2:1
+a: error on line 3:
+    x.cue:3:1
+b: error on line 4:
+    x.cue:4:1
+`,
+	}, {
+		name: "Truncated",
+		cfg:  Config{MaxPerGroup: 2},
+		want: `a: error on line 1:
+    x.cue:1:1
+b: error on line 2:
+    x.cue:2:1
+... and 2 more
+`,
+	}, {
+		name: "ByPath",
+		cfg:  Config{GroupBy: GroupByPath, MaxPerGroup: 1},
+		want: `--- a (2 errors)
+a: error on line 1:
+    x.cue:1:1
+... and 1 more
+--- b (2 errors)
+b: error on line 2:
+    x.cue:2:1
+... and 1 more
+`,
+	}, {
+		name: "ByCode",
+		cfg:  Config{GroupBy: GroupByCode},
+		want: `--- E1001 conflicting values (3 errors)
+a: error on line 1:
+    x.cue:1:1
+b: error on line 2:
+    x.cue:2:1
+a: error on line 3:
+    x.cue:3:1
+--- other errors (1 error)
+b: error on line 4:
+    x.cue:4:1
+`,
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := &bytes.Buffer{}
+			Print(w, err, &tt.cfg)
+			if got := w.String(); got != tt.want {
+				t.Errorf("unexpected Print result\ngot:\n%s\nwant:\n%s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPrintSnippet(t *testing.T) {
+	src := "a: \"foo\" & int\n\tb: {\n\t\tc: 1 & 2\n\t}\n"
+	f := token.NewFile("x.cue", -1, len(src))
+	f.SetLinesForContent([]byte(src))
+	source := func(name string) ([]byte, error) {
+		if name != "x.cue" {
+			return nil, fmt.Errorf("no such file")
+		}
+		return []byte(src), nil
+	}
+	tests := []struct {
+		name      string
+		err       Error
+		color     bool
+		highlight func(string) string
+		want      string
+	}{{
+		name: "String",
+		err:  Newf(f.Pos(3, token.NoRelPos), "conflicting values"),
+		want: `conflicting values:
+    x.cue:1:4
+    1 | a: "foo" & int
+      |    ^~~~~
+`,
+	}, {
+		name: "Tabs",
+		err:  Newf(f.Pos(strings.Index(src, "2"), token.NoRelPos), "conflicting values"),
+		want: "conflicting values:\n" +
+			"    x.cue:3:10\n" +
+			"    3 | \t\tc: 1 & 2\n" +
+			"      | \t\t       ^\n",
+	}, {
+		name:  "Color",
+		err:   Newf(f.Pos(strings.Index(src, "int"), token.NoRelPos), "conflicting values"),
+		color: true,
+		want: "conflicting values:\n" +
+			"    x.cue:1:12\n" +
+			"    \x1b[34m1 |\x1b[0m a: \"foo\" & int\n" +
+			"    \x1b[34m  |\x1b[0m            \x1b[1;31m^~~\x1b[0m\n",
+	}, {
+		name:      "Highlight",
+		err:       Newf(f.Pos(strings.Index(src, "int"), token.NoRelPos), "conflicting values"),
+		color:     true,
+		highlight: strings.ToUpper,
+		want: "conflicting values:\n" +
+			"    x.cue:1:12\n" +
+			"    \x1b[34m1 |\x1b[0m A: \"FOO\" & INT\n" +
+			"    \x1b[34m  |\x1b[0m            \x1b[1;31m^~~\x1b[0m\n",
+	}, {
+		name:      "HighlightWithoutColor",
+		err:       Newf(f.Pos(strings.Index(src, "int"), token.NoRelPos), "conflicting values"),
+		highlight: strings.ToUpper,
+		want: `conflicting values:
+    x.cue:1:12
+    1 | a: "foo" & int
+      |            ^~~
+`,
+	}, {
+		name: "NoSource",
+		err:  Newf(token.NewFile("y.cue", -1, 10).Pos(0, token.NoRelPos), "conflicting values"),
+		want: "conflicting values:\n    y.cue:1:1\n",
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := &bytes.Buffer{}
+			Print(w, tt.err, &Config{Source: source, Color: tt.color, Highlight: tt.highlight})
+			if got := w.String(); got != tt.want {
+				t.Errorf("unexpected Print result\ngot:\n%q\nwant:\n%q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,95 @@
+// Copyright 2025 The CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package errors
+
+import "cuelang.org/go/cue/token"
+
+// A Hint describes how an error may be fixed.
+type Hint struct {
+	// Message describes the fix to a user, such as
+	// "remove the trailing comma".
+	Message string
+
+	// Edits, if non-empty, are the changes to the source which
+	// implement the fix, for example as an editor quick fix.
+	Edits []Edit
+}
+
+// An Edit replaces the source between two positions in the same file.
+type Edit struct {
+	// Start and End delimit the source to replace. They are equal
+	// for an insertion.
+	Start, End token.Pos
+
+	// NewText replaces the source between Start and End.
+	// It is empty for a deletion.
+	NewText string
+}
+
+// hinter is implemented by errors which have hints.
+type hinter interface {
+	Hints() []Hint
+}
+
+// Hints returns the hints of the first error in err's chain which has
+// any, or nil if there are none. For a list of errors, it reports the
+// hints of the first error.
+func Hints(err error) []Hint {
+	var h hinter
+	if As(err, &h) {
+		return h.Hints()
+	}
+	return nil
+}
+
+// WithHints returns err with the given hints added to those of the errors
+// wrapped by err. If err is a list of errors, each error in the list is
+// given the hints.
+func WithHints(err Error, hints ...Hint) Error {
+	switch x := err.(type) {
+	case nil:
+		return nil
+	case list:
+		a := make(list, len(x))
+		for i, e := range x {
+			a[i] = WithHints(e, hints...)
+		}
+		return a
+	}
+	if len(hints) == 0 {
+		return err
+	}
+	return &hintedError{err, hints}
+}
+
+// hintedError adds hints to an error. It is otherwise transparent:
+// it does not add a level to the chain of wrapped errors.
+type hintedError struct {
+	err   Error
+	hints []Hint
+}
+
+func (e *hintedError) Hints() []Hint {
+	return append(e.hints[:len(e.hints):len(e.hints)], Hints(e.err)...)
+}
+
+func (e *hintedError) Position() token.Pos          { return e.err.Position() }
+func (e *hintedError) InputPositions() []token.Pos  { return e.err.InputPositions() }
+func (e *hintedError) Error() string                { return e.err.Error() }
+func (e *hintedError) Path() []string               { return e.err.Path() }
+func (e *hintedError) Msg() (string, []interface{}) { return e.err.Msg() }
+func (e *hintedError) Is(target error) bool         { return Is(e.err, target) }
+func (e *hintedError) As(target interface{}) bool   { return As(e.err, target) }
+func (e *hintedError) Unwrap() error                { return Unwrap(e.err) }
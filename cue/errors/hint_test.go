@@ -0,0 +1,61 @@
+// Copyright 2025 The CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package errors
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+
+	"cuelang.org/go/cue/token"
+)
+
+func TestHints(t *testing.T) {
+	base := Newf(token.NoPos, "invalid value")
+	fix := Hint{Message: "use a number"}
+	other := Hint{Message: "see the schema"}
+	hinted := WithHints(base, fix)
+
+	tests := []struct {
+		name string
+		err  error
+		want []Hint
+	}{
+		{"nil", nil, nil},
+		{"none", base, nil},
+		{"hinted", hinted, []Hint{fix}},
+		{"wrapped", Wrapf(hinted, token.NoPos, "cannot load"), []Hint{fix}},
+		{"nested", WithHints(hinted, other), []Hint{other, fix}},
+		{"coded", WithCode(hinted, InvalidJSON), []Hint{fix}},
+		{"list", Append(WithHints(base, other), hinted), []Hint{other}},
+	}
+	for _, tc := range tests {
+		if got := Hints(tc.err); !reflect.DeepEqual(got, tc.want) {
+			t.Errorf("%s: Hints() = %v, want %v", tc.name, got, tc.want)
+		}
+	}
+	if got := WithHints(base); got != base {
+		t.Errorf("WithHints without hints changed the error")
+	}
+	if CodeOf(WithHints(WithCode(base, InvalidJSON), fix)) != InvalidJSON {
+		t.Errorf("hints hide the code of an error")
+	}
+
+	w := &bytes.Buffer{}
+	Print(w, WithHints(base, fix, Hint{}), &Config{Hints: true})
+	if got, want := w.String(), "invalid value\nhint: use a number\n"; got != want {
+		t.Errorf("Print() = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,100 @@
+// Copyright 2025 The CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package errors
+
+import (
+	"encoding/json"
+	"io"
+	"maps"
+	"slices"
+
+	"golang.org/x/text/language"
+	"golang.org/x/text/message"
+
+	"cuelang.org/go/cue/token"
+)
+
+// RegisterTranslations registers translations of error messages into lang.
+// It maps the format strings of messages, as returned by [Error.Msg], to
+// their translations, which must use the same formatting verbs in the same
+// order. For example:
+//
+//	errors.RegisterTranslations(language.French, map[string]string{
+//		"conflicting values %s and %s": "valeurs incompatibles %s et %s",
+//	})
+//
+// Translations are used when printing errors with a [Config] whose
+// Format is returned by [Formatter] for lang. They are registered in
+// the default catalog of [golang.org/x/text/message], so they apply to
+// all printers for lang which use it.
+func RegisterTranslations(lang language.Tag, translations map[string]string) error {
+	var errs Error
+	for _, format := range slices.Sorted(maps.Keys(translations)) {
+		msg := translations[format]
+		if got, want := formatVerbs(msg), formatVerbs(format); got != want {
+			errs = Append(errs, Newf(token.NoPos, "translation of %q into %s has verbs %q, want %q", format, lang, got, want))
+			continue
+		}
+		if err := message.SetString(lang, format, msg); err != nil {
+			errs = Append(errs, Promote(err, "invalid translation"))
+		}
+	}
+	return errs
+}
+
+// LoadTranslations is like [RegisterTranslations], but reads the translations
+// from r, which holds a JSON object mapping formats to their translations.
+func LoadTranslations(lang language.Tag, r io.Reader) error {
+	var translations map[string]string
+	if err := json.NewDecoder(r).Decode(&translations); err != nil {
+		return Wrapf(err, token.NoPos, "invalid translations for %s", lang)
+	}
+	return RegisterTranslations(lang, translations)
+}
+
+// Formatter returns a function, for use as [Config.Format], which
+// translates messages into lang using the registered translations, and
+// formats their arguments, such as numbers, according to lang.
+// Messages without a translation are printed as they are.
+func Formatter(lang language.Tag) func(w io.Writer, format string, args ...interface{}) {
+	p := message.NewPrinter(lang)
+	return func(w io.Writer, format string, args ...interface{}) {
+		p.Fprintf(w, format, args...)
+	}
+}
+
+// formatVerbs returns the formatting verbs of a format string,
+// ignoring any flags, widths, and precisions.
+func formatVerbs(format string) string {
+	var verbs []byte
+	for i := 0; i < len(format); i++ {
+		if format[i] != '%' {
+			continue
+		}
+		for i++; i < len(format); i++ {
+			c := format[i]
+			if c == '%' || ('a' <= c && c <= 'z') || ('A' <= c && c <= 'Z') {
+				if c != '%' {
+					if len(verbs) > 0 {
+						verbs = append(verbs, ' ')
+					}
+					verbs = append(verbs, '%', c)
+				}
+				break
+			}
+		}
+	}
+	return string(verbs)
+}
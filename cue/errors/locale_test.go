@@ -0,0 +1,60 @@
+// Copyright 2025 The CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package errors
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"golang.org/x/text/language"
+
+	"cuelang.org/go/cue/token"
+)
+
+func TestTranslations(t *testing.T) {
+	lang := language.MustParse("eo")
+	err := LoadTranslations(lang, strings.NewReader(`{
+		"conflicting values %s and %s": "konfliktaj valoroj %s kaj %s",
+		"cannot load %q": "ne povas ŝargi %q"
+	}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	e := Wrapf(Newf(token.NoPos, "conflicting values %s and %s", "1", "2"), token.NoPos, "cannot load %q", "x.cue")
+	w := &bytes.Buffer{}
+	Print(w, e, &Config{Format: Formatter(lang)})
+	if got, want := w.String(), "ne povas ŝargi \"x.cue\": konfliktaj valoroj 1 kaj 2\n"; got != want {
+		t.Errorf("Print() = %q, want %q", got, want)
+	}
+
+	// Other languages are not affected.
+	w.Reset()
+	Print(w, e, &Config{Format: Formatter(language.English)})
+	if got, want := w.String(), "cannot load \"x.cue\": conflicting values 1 and 2\n"; got != want {
+		t.Errorf("Print() = %q, want %q", got, want)
+	}
+
+	err = RegisterTranslations(lang, map[string]string{
+		"invalid value %v": "nevalida valoro",
+	})
+	if err == nil || !strings.Contains(err.Error(), `has verbs "", want "%v"`) {
+		t.Errorf("RegisterTranslations() = %v, want error about verbs", err)
+	}
+	if err := LoadTranslations(lang, strings.NewReader(`[]`)); err == nil {
+		t.Errorf("LoadTranslations() succeeded with invalid JSON")
+	}
+}
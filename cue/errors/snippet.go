@@ -0,0 +1,130 @@
+// Copyright 2025 The CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package errors
+
+import (
+	"bytes"
+	"io"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"cuelang.org/go/cue/token"
+)
+
+const (
+	colorGutter = "\x1b[34m"
+	colorCaret  = "\x1b[1;31m"
+	colorReset  = "\x1b[0m"
+)
+
+// printSnippet prints the source line at pos, followed by a line which
+// marks the column of pos and the extent of the token starting there:
+//
+//	2 | a: "foo" & int
+//	  |    ^~~~~
+func printSnippet(w io.Writer, pos token.Position, cfg *Config) {
+	if cfg.Source == nil || !pos.IsValid() || pos.Filename == "" {
+		return
+	}
+	src, err := cfg.Source(pos.Filename)
+	if err != nil {
+		return
+	}
+	line, ok := sourceLine(src, pos.Line)
+	if !ok || pos.Column < 1 || pos.Column > len(line)+1 {
+		return
+	}
+	line = strings.TrimRight(line, " \t\r")
+
+	num := strconv.Itoa(pos.Line)
+	gutter := strings.Repeat(" ", len(num))
+	start, end := "", ""
+	if cfg.Color {
+		start, end = colorGutter, colorReset
+	}
+	text := line
+	if cfg.Color && cfg.Highlight != nil {
+		text = cfg.Highlight(line)
+	}
+	// Write the source directly, rather than through cfg.Format,
+	// as it may contain formatting verbs.
+	io.WriteString(w, "    "+start+num+" |"+end+" "+text+"\n")
+
+	// Keep the tabs in the line, so that the caret lines up
+	// with the source regardless of the tab width.
+	var indent strings.Builder
+	for _, r := range line[:min(pos.Column-1, len(line))] {
+		if r == '\t' {
+			indent.WriteByte('\t')
+		} else {
+			indent.WriteByte(' ')
+		}
+	}
+	marker := "^" + strings.Repeat("~", max(tokenLen(line[min(pos.Column-1, len(line)):])-1, 0))
+	if cfg.Color {
+		marker = colorCaret + marker + colorReset
+	}
+	io.WriteString(w, "    "+start+gutter+" |"+end+" "+indent.String()+marker+"\n")
+}
+
+// sourceLine returns the given 1-based line of src, without its
+// line terminator.
+func sourceLine(src []byte, n int) (string, bool) {
+	for i := 1; i < n; i++ {
+		j := bytes.IndexByte(src, '\n')
+		if j < 0 {
+			return "", false
+		}
+		src = src[j+1:]
+	}
+	if j := bytes.IndexByte(src, '\n'); j >= 0 {
+		src = src[:j]
+	}
+	return string(src), true
+}
+
+// tokenLen approximates the length in runes of the token at the start of s,
+// which is an identifier, number, or string literal. It returns 1 for any
+// other token.
+func tokenLen(s string) int {
+	if s == "" {
+		return 1
+	}
+	r, size := utf8.DecodeRuneInString(s)
+	if r == '"' || r == '\'' {
+		escaped := false
+		for i, c := range s[size:] {
+			switch {
+			case escaped:
+				escaped = false
+			case c == '\\':
+				escaped = true
+			case c == r:
+				return utf8.RuneCountInString(s[:size+i+1])
+			}
+		}
+		return utf8.RuneCountInString(s)
+	}
+	n := 0
+	for _, c := range s {
+		if c != '_' && c != '#' && c != '$' && c != '.' && !unicode.IsLetter(c) && !unicode.IsDigit(c) {
+			break
+		}
+		n++
+	}
+	return max(n, 1)
+}
@@ -0,0 +1,78 @@
+// Copyright 2025 The CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package errors
+
+import "cuelang.org/go/cue/token"
+
+// A Frame is a site which led to an error, such as an import of the package
+// in which the error occurred or a field which was unified with a schema
+// from that package.
+type Frame struct {
+	Pos     token.Pos
+	Message string
+}
+
+// tracer is implemented by errors which have a trace.
+type tracer interface {
+	Trace() []Frame
+}
+
+// Trace returns the trace of the first error in err's chain which has one,
+// or nil if there is none. A trace lists the sites which led to an error,
+// from the outermost, typically in the user's own files, to the innermost.
+func Trace(err error) []Frame {
+	var t tracer
+	if As(err, &t) {
+		return t.Trace()
+	}
+	return nil
+}
+
+// WithTrace returns err with the given trace, which replaces any trace of
+// the errors wrapped by err. If err is a list of errors, each error in the
+// list is given the trace.
+func WithTrace(err Error, frames ...Frame) Error {
+	switch x := err.(type) {
+	case nil:
+		return nil
+	case list:
+		a := make(list, len(x))
+		for i, e := range x {
+			a[i] = WithTrace(e, frames...)
+		}
+		return a
+	}
+	if len(frames) == 0 {
+		return err
+	}
+	return &tracedError{err, frames}
+}
+
+// tracedError adds a trace to an error. It is otherwise transparent:
+// it does not add a level to the chain of wrapped errors.
+type tracedError struct {
+	err    Error
+	frames []Frame
+}
+
+func (e *tracedError) Trace() []Frame               { return e.frames }
+func (e *tracedError) Position() token.Pos          { return e.err.Position() }
+func (e *tracedError) InputPositions() []token.Pos  { return e.err.InputPositions() }
+func (e *tracedError) Error() string                { return e.err.Error() }
+func (e *tracedError) Path() []string               { return e.err.Path() }
+func (e *tracedError) Msg() (string, []interface{}) { return e.err.Msg() }
+func (e *tracedError) Is(target error) bool         { return Is(e.err, target) }
+func (e *tracedError) As(target interface{}) bool   { return As(e.err, target) }
+func (e *tracedError) Unwrap() error                { return Unwrap(e.err) }
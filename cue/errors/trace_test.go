@@ -0,0 +1,62 @@
+// Copyright 2025 The CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package errors
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+
+	"cuelang.org/go/cue/token"
+)
+
+func TestTrace(t *testing.T) {
+	dep := token.NewFile("dep.cue", -1, 10)
+	dep.SetLinesForContent([]byte("a\nb\nc\nd\ne\n"))
+	user := token.NewFile("x.cue", -1, 10)
+	user.SetLinesForContent([]byte("a\nb\nc\nd\ne\n"))
+
+	base := Newf(dep.Pos(2, token.NoRelPos), "invalid value")
+	frames := []Frame{
+		{Pos: user.Pos(0, token.NoRelPos), Message: `import "example.com/dep"`},
+		{Pos: user.Pos(4, token.NoRelPos), Message: "field a"},
+	}
+	traced := WithTrace(base, frames...)
+
+	if got := Trace(base); got != nil {
+		t.Errorf("Trace() = %v, want nil", got)
+	}
+	if got := Trace(Wrapf(traced, token.NoPos, "cannot load")); !reflect.DeepEqual(got, frames) {
+		t.Errorf("Trace() = %v, want %v", got, frames)
+	}
+	if got := Trace(WithTrace(traced, frames[1])); !reflect.DeepEqual(got, frames[1:]) {
+		t.Errorf("Trace() = %v, want %v", got, frames[1:])
+	}
+	if CodeOf(WithTrace(WithCode(base, OutOfBound), frames...)) != OutOfBound {
+		t.Errorf("a trace hides the code of an error")
+	}
+
+	w := &bytes.Buffer{}
+	Print(w, traced, nil)
+	want := `invalid value:
+    dep.cue:2:1
+  triggered by:
+    x.cue:1:1: import "example.com/dep"
+    x.cue:3:1: field a
+`
+	if got := w.String(); got != want {
+		t.Errorf("Print() = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,81 @@
+// Copyright 2025 CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cue_test
+
+import (
+	"testing"
+
+	"cuelang.org/go/cue"
+	"cuelang.org/go/cue/cuecontext"
+	"cuelang.org/go/cue/errors"
+	"github.com/go-quicktest/qt"
+)
+
+func TestErrorCodes(t *testing.T) {
+	tests := []struct {
+		src  string
+		want errors.Code
+	}{
+		{`a: 1, a: 2`, errors.ConflictingValues},
+		{`a: int & "s"`, errors.ConflictingValues},
+		{`a: >10 & 5`, errors.OutOfBound},
+		{`#D: {a: int}, d: #D & {b: 1}`, errors.FieldNotAllowed},
+		{`a: _|_`, errors.ExplicitError},
+		{`a: [1][3]`, errors.IndexOutOfRange},
+		{`a: {}.b`, errors.UndefinedField},
+		{`a: int`, errors.IncompleteValue},
+		{`#x: int, a: #x + 1`, errors.NonConcreteValue},
+		{`a!: int`, errors.RequiredFieldMissing},
+		{`a: {b: a}`, errors.StructuralCycle},
+		{`a: 1 +`, errors.SyntaxError},
+	}
+	ctx := cuecontext.New()
+	for _, tc := range tests {
+		t.Run(tc.src, func(t *testing.T) {
+			v := ctx.CompileString(tc.src)
+			err := v.Err()
+			if err == nil {
+				err = v.Validate(cue.Concrete(true))
+			}
+			qt.Assert(t, qt.IsNotNil(err))
+			qt.Assert(t, qt.Equals(errors.CodeOf(err), tc.want))
+		})
+	}
+}
+
+func TestErrorSuggestions(t *testing.T) {
+	tests := []struct {
+		src  string
+		want []string
+	}{
+		{`#D: {name: string}, d: #D & {nmae: "x"}`, []string{"name"}},
+		{`d: {replicas: 1}, e: d.replcas`, []string{"replicas"}},
+		{`a: strng`, []string{"string"}},
+		{`#Def: int, a: #Deff`, []string{"#Def"}},
+		{`#D: {name: string}, d: #D & {other: "x"}`, nil},
+	}
+	ctx := cuecontext.New()
+	for _, tc := range tests {
+		t.Run(tc.src, func(t *testing.T) {
+			v := ctx.CompileString(tc.src)
+			err := v.Err()
+			if err == nil {
+				err = v.Validate(cue.Concrete(true))
+			}
+			qt.Assert(t, qt.IsNotNil(err))
+			qt.Assert(t, qt.DeepEquals(errors.Suggestions(err), tc.want))
+		})
+	}
+}
@@ -0,0 +1,198 @@
+// Copyright 2025 CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package highlight adds ANSI escape sequences to CUE source to
+// highlight its syntax when it is printed to a terminal.
+//
+// Highlighting is based on the tokens of the source alone, so it also
+// works for fragments of CUE, such as a single line of a file, and for
+// invalid CUE. Removing the escape sequences from the result yields the
+// original source.
+package highlight
+
+import (
+	"bytes"
+	"io"
+	"strings"
+
+	"cuelang.org/go/cue/scanner"
+	"cuelang.org/go/cue/token"
+)
+
+// A Theme holds the ANSI escape sequences which start each class of
+// tokens. An empty sequence leaves the tokens of its class as they are.
+type Theme struct {
+	Keyword    string // keywords, such as if, for, and package
+	Literal    string // true, false, null, and _|_
+	Type       string // predeclared types, such as int and string
+	Definition string // identifiers of definitions, such as #Schema
+	Ident      string // other identifiers
+	String     string // string and bytes literals
+	Number     string // number literals
+	Comment    string // comments
+	Attribute  string // attributes, such as @go(Name)
+	Operator   string // operators and punctuation
+	Illegal    string // characters which are not valid CUE
+}
+
+// DefaultTheme uses the basic ANSI colors, which work in most terminals.
+var DefaultTheme = &Theme{
+	Keyword:    "\x1b[35m",
+	Literal:    "\x1b[36m",
+	Type:       "\x1b[33m",
+	Definition: "\x1b[1;34m",
+	String:     "\x1b[32m",
+	Number:     "\x1b[36m",
+	Comment:    "\x1b[90m",
+	Attribute:  "\x1b[33m",
+	Illegal:    "\x1b[1;31m",
+}
+
+const reset = "\x1b[0m"
+
+// predeclared holds the identifiers of the predeclared types.
+var predeclared = map[string]bool{
+	"_":      true,
+	"bool":   true,
+	"int":    true,
+	"float":  true,
+	"number": true,
+	"string": true,
+	"bytes":  true,
+}
+
+// Source returns src with ANSI escape sequences around its tokens, as
+// given by theme. DefaultTheme is used if theme is nil.
+func Source(src []byte, theme *Theme) []byte {
+	if theme == nil {
+		theme = DefaultTheme
+	}
+	var buf bytes.Buffer
+	buf.Grow(len(src) + len(src)/2)
+
+	var s scanner.Scanner
+	f := token.NewFile("", -1, len(src))
+	s.Init(f, src, nil, scanner.ScanComments|scanner.DontInsertCommas)
+
+	last := 0
+	write := func(off int, lit string, color string) {
+		end := min(off+len(lit), len(src))
+		if off < last || end <= off {
+			return
+		}
+		buf.Write(src[last:off])
+		if color == "" {
+			buf.Write(src[off:end])
+		} else {
+			buf.WriteString(color)
+			buf.Write(src[off:end])
+			buf.WriteString(reset)
+		}
+		last = end
+	}
+
+	// parens holds, for each interpolation being scanned, the number of
+	// parentheses open within it.
+	var parens []int
+	for {
+		pos, tok, lit := s.Scan()
+		if tok == token.EOF {
+			break
+		}
+		off := pos.Offset()
+		switch tok {
+		case token.LPAREN:
+			if off < last {
+				// The opening parenthesis of an interpolation, which was
+				// written as part of the string.
+				parens = append(parens, 0)
+				continue
+			}
+			if n := len(parens); n > 0 {
+				parens[n-1]++
+			}
+		case token.RPAREN:
+			if n := len(parens); n > 0 {
+				if parens[n-1] == 0 {
+					parens = parens[:n-1]
+					write(off, s.ResumeInterpolation(), theme.String)
+					continue
+				}
+				parens[n-1]--
+			}
+		}
+		if lit == "" {
+			lit = tok.String()
+		}
+		write(off, lit, theme.color(tok, lit))
+	}
+	buf.Write(src[last:])
+	return buf.Bytes()
+}
+
+// color returns the escape sequence for a token.
+func (t *Theme) color(tok token.Token, lit string) string {
+	switch tok {
+	case token.IDENT:
+		switch {
+		case lit == "package" || lit == "import":
+			return t.Keyword
+		case predeclared[lit]:
+			return t.Type
+		case strings.HasPrefix(lit, "#") || strings.HasPrefix(lit, "_#"):
+			return t.Definition
+		}
+		return t.Ident
+	case token.TRUE, token.FALSE, token.NULL, token.BOTTOM:
+		return t.Literal
+	case token.STRING, token.INTERPOLATION:
+		return t.String
+	case token.INT, token.FLOAT:
+		return t.Number
+	case token.COMMENT:
+		return t.Comment
+	case token.ATTRIBUTE:
+		return t.Attribute
+	case token.ILLEGAL:
+		return t.Illegal
+	}
+	if tok.IsKeyword() {
+		return t.Keyword
+	}
+	return t.Operator
+}
+
+// A Writer highlights the CUE source written to it, writing the result
+// to an underlying writer.
+type Writer struct {
+	w     io.Writer
+	theme *Theme
+}
+
+// NewWriter returns a Writer which writes to w, highlighting source as
+// given by theme. DefaultTheme is used if theme is nil.
+//
+// Each call to Write is highlighted on its own, so it must consist of
+// whole tokens, as the output of [cuelang.org/go/cue/format.Node] does.
+func NewWriter(w io.Writer, theme *Theme) *Writer {
+	return &Writer{w: w, theme: theme}
+}
+
+// Write writes the highlighted form of p to the underlying writer.
+func (w *Writer) Write(p []byte) (int, error) {
+	if _, err := w.w.Write(Source(p, w.theme)); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
@@ -0,0 +1,98 @@
+// Copyright 2025 CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package highlight
+
+import (
+	"bytes"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/go-quicktest/qt"
+)
+
+// testTheme marks each class with a readable tag. The reset sequence
+// after each token is replaced with "}" by highlight.
+var testTheme = &Theme{
+	Keyword:    "k{",
+	Literal:    "l{",
+	Type:       "t{",
+	Definition: "d{",
+	String:     "s{",
+	Number:     "n{",
+	Comment:    "c{",
+	Attribute:  "a{",
+	Illegal:    "!{",
+}
+
+func highlight(src string) string {
+	return strings.ReplaceAll(string(Source([]byte(src), testTheme)), reset, "}")
+}
+
+func TestSource(t *testing.T) {
+	testCases := []struct {
+		src  string
+		want string
+	}{{
+		src:  "package foo\n\nimport \"strings\"\n",
+		want: "k{package} foo\n\nk{import} s{\"strings\"}\n",
+	}, {
+		src:  "#A: {\n\tx: int | *1 // the x\n\ty?: string @go(Y)\n}\n",
+		want: "d{#A}: {\n\tx: t{int} | *n{1} c{// the x}\n\ty?: t{string} a{@go(Y)}\n}\n",
+	}, {
+		src:  "a: [for x in y if x > 1.5 {x}]\nb: null | true | _|_\n",
+		want: "a: [k{for} x k{in} y k{if} x > n{1.5} {x}]\nb: l{null} | l{true} | l{_|_}\n",
+	}, {
+		src:  `s: "a\(x + (1))b\(y)c"`,
+		want: `s: s{"a\(}x + (n{1})s{)b\(}ys{)c"}`,
+	}, {
+		src:  "s: \"\"\"\n\tx \\(y)\n\t\"\"\"\n",
+		want: "s: s{\"\"\"\n\tx \\(}ys{)\n\t\"\"\"}\n",
+	}, {
+		// Fragments of CUE, such as a line in the middle of a multi-line
+		// string, are highlighted as well as possible.
+		src:  "\tfoo \"bar\n",
+		want: "\tfoo s{\"bar}\n",
+	}, {
+		src:  "a: 1 ` b",
+		want: "a: n{1} !{`} b",
+	}}
+	for _, tc := range testCases {
+		qt.Check(t, qt.Equals(highlight(tc.src), tc.want), qt.Commentf("%q", tc.src))
+	}
+}
+
+var escapes = regexp.MustCompile("\x1b\\[[0-9;]*m")
+
+func TestDefaultThemeKeepsSource(t *testing.T) {
+	for _, src := range []string{
+		"package foo\n\n#A: {\n\ta: int & >0 @go(A)\n\tb: \"x\\(a)y\" // b\n}\n",
+		"a: #\"\\#(x)\"#\nb: '\\x00'\nc: [...]\n",
+		"\"\"\"\n",
+		"a: 1 ` b @",
+	} {
+		got := Source([]byte(src), nil)
+		qt.Check(t, qt.Equals(escapes.ReplaceAllString(string(got), ""), src))
+	}
+}
+
+func TestWriter(t *testing.T) {
+	var buf bytes.Buffer
+	w := NewWriter(&buf, testTheme)
+	n, err := w.Write([]byte("a: 1\n"))
+	qt.Assert(t, qt.IsNil(err))
+	qt.Assert(t, qt.Equals(n, 5))
+	qt.Assert(t, qt.Equals(strings.ReplaceAll(buf.String(), reset, "}"), "a: n{1}\n"))
+}
@@ -174,6 +174,12 @@ func (inst *Instance) ID() string {
 	return inst.inst.ID()
 }
 
+// Warnings reports problems with the configuration which do not make it
+// invalid. See [Value.Warnings].
+func (inst *Instance) Warnings() errors.Error {
+	return inst.Value().Warnings()
+}
+
 // Value returns the root value of the configuration. If the configuration
 // defines in emit value, it will be that value. Otherwise it will be all
 // top-level values.
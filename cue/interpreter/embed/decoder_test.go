@@ -0,0 +1,70 @@
+// Copyright 2025 CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package embed_test
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"testing"
+
+	"cuelang.org/go/cue"
+	"cuelang.org/go/cue/ast"
+	"cuelang.org/go/cue/ast/astutil"
+	"cuelang.org/go/cue/cuecontext"
+	"cuelang.org/go/cue/errors"
+	"cuelang.org/go/cue/format"
+	"cuelang.org/go/cue/interpreter/embed"
+	"cuelang.org/go/internal/cuetxtar"
+)
+
+func decodeCSV(ctx *cue.Context, filename string, data []byte) (cue.Value, error) {
+	records, err := csv.NewReader(bytes.NewReader(data)).ReadAll()
+	if err != nil {
+		return cue.Value{}, err
+	}
+	return ctx.Encode(records), nil
+}
+
+// TestDecoder tests embedding the files in testdata/decoder with a decoder for
+// CSV files.
+func TestDecoder(t *testing.T) {
+	test := cuetxtar.TxTarTest{
+		Root: "./testdata/decoder",
+		Name: "decoder",
+	}
+
+	test.Run(t, func(t *cuetxtar.Test) {
+		interp := embed.New(embed.Decoder("csv", decodeCSV, ".csv"))
+		ctx := cuecontext.New(cuecontext.Interpreter(interp))
+		v := ctx.BuildInstance(t.Instances(".")[0])
+
+		if err := v.Validate(); err != nil {
+			fmt.Fprintln(t, "Errors:")
+			t.WriteErrors(errors.Promote(err, ""))
+			fmt.Fprintln(t, "\nResult:")
+		}
+		syntax := v.Syntax(cue.Attributes(false), cue.Final(), cue.ErrorsAsValues(true))
+		file, err := astutil.ToFile(syntax.(ast.Expr))
+		if err != nil {
+			t.Fatal(err)
+		}
+		b, err := format.Node(file)
+		if err != nil {
+			t.Fatal(err)
+		}
+		t.Write(b)
+	})
+}
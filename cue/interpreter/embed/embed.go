@@ -50,6 +50,39 @@
 // behavior can be overridden by the type argument. See cue help filetypes for
 // the list of supported types. This field is required if a file extension is
 // unknown, or if a wildcard is used for the file extension in the glob pattern.
+// Programs using the Go API may register decoders for additional types with
+// [Decoder], which may then be selected in the same way.
+//
+// as=$form
+//
+// The as argument embeds the contents of a file verbatim, without decoding
+// it, which allows any file, such as a certificate or an icon, to be
+// embedded regardless of its extension. It may not be used in conjunction
+// with a type argument other than binary. The supported forms are:
+//
+//	bytes   the contents as bytes, the same as type=binary
+//	base64  the contents as a string with the standard base64 encoding
+//	sha256  the hexadecimal SHA-256 hash of the contents as a string
+//	sha512  the hexadecimal SHA-512 hash of the contents as a string
+//
+// url=$url
+//
+// The url argument embeds content fetched from an https URL, which may be
+// used, for example, for schemas that track external reference data. It
+// may not be used in conjunction with the file or glob arguments, and
+// requires the sha256 argument. As with files, the content is decoded
+// based on the extension of the URL path, or the type and as arguments.
+// Embedding remote content is disabled by default, as it makes evaluation
+// depend on the network. Programs using the Go API may enable it with
+// [Remote]; the cue command enables it with CUE_EXPERIMENT=embedremote.
+//
+// sha256=$hash
+//
+// The sha256 argument pins the content of a url argument to the given
+// hexadecimal SHA-256 hash. Content which does not match the hash is an
+// error, so that a configuration cannot change when the remote content
+// does. Content is cached by its hash, and only fetched when it is not
+// in the cache.
 //
 // # Limitations
 //
@@ -79,9 +112,20 @@
 //	// include all files in the y directory as a map of file paths to binary
 //	// data. The entries are unified into the same map as above.
 //	files: _ @embed(glob=y/*.*, type=binary)
+//
+//	// include a certificate as base64 along with its hash
+//	cert:       string @embed(file=ca.der, as=base64)
+//	certSHA256: string @embed(file=ca.der, as=sha256)
+//
+//	// include a remote JSON file, pinned to its SHA-256 hash
+//	countries: _ @embed(url="https://example.com/countries.json", sha256=1f3870be274f6c49b3e31a0c6728957f2c8d3f1e4e1b3e7c8a1c1f0d6b5e4a3c)
 package embed
 
 import (
+	"crypto/sha512"
+	"encoding/base64"
+	"encoding/hex"
+	"io"
 	"io/fs"
 	"os"
 	"path"
@@ -105,12 +149,63 @@ import (
 // TODO: record files in build.Instance
 
 // interpreter is a [cuecontext.ExternInterpreter] for embedded files.
-type interpreter struct{}
+type interpreter struct {
+	decoders   map[string]*decoder // by type
+	extensions map[string]*decoder // by file extension
+
+	// remote is set if remote content may be embedded.
+	remote *remote
+}
+
+// A decoder is a decoder registered with [Decoder].
+type decoder struct {
+	typ    string
+	decode DecodeFunc
+}
+
+// Option configures the interpreter returned by [New].
+type Option struct {
+	apply func(i *interpreter)
+}
+
+// A DecodeFunc decodes the contents of an embedded file into a CUE value,
+// which must be created with the given context. The filename is the path of
+// the file relative to the directory of the file containing the attribute.
+type DecodeFunc func(ctx *cue.Context, filename string, data []byte) (cue.Value, error)
+
+// Decoder returns an Option that registers f to decode files of the type
+// typ, which may then be selected with the type argument of the @embed
+// attribute. Files with any of the given extensions, such as ".csv", are
+// decoded with f when no type argument is given.
+//
+// A decoder takes precedence over a built-in file type of the same name or
+// extension. If more than one decoder is registered for a type or extension,
+// the last one is used.
+func Decoder(typ string, f DecodeFunc, extensions ...string) Option {
+	return Option{func(i *interpreter) {
+		d := &decoder{typ: typ, decode: f}
+		if i.decoders == nil {
+			i.decoders = make(map[string]*decoder)
+			i.extensions = make(map[string]*decoder)
+		}
+		i.decoders[typ] = d
+		for _, ext := range extensions {
+			if !strings.HasPrefix(ext, ".") {
+				ext = "." + ext
+			}
+			i.extensions[ext] = d
+		}
+	}}
+}
 
 // New returns a new interpreter for embedded files as a
 // [cuecontext.ExternInterpreter] suitable for passing to [cuecontext.New].
-func New() cuecontext.ExternInterpreter {
-	return &interpreter{}
+func New(options ...Option) cuecontext.ExternInterpreter {
+	i := &interpreter{}
+	for _, o := range options {
+		o.apply(i)
+	}
+	return i
 }
 
 func (i *interpreter) Kind() string {
@@ -129,6 +224,7 @@ func (i *interpreter) NewCompiler(b *build.Instance, r *runtime.Runtime) (runtim
 	return &compiler{
 		b:       b,
 		runtime: (*cue.Context)(r),
+		interp:  i,
 	}, nil
 }
 
@@ -138,11 +234,15 @@ type compiler struct {
 	b       *build.Instance
 	runtime *cue.Context
 	opCtx   *adt.OpContext
+	interp  *interpreter
 
 	// file system cache
 	dir string
 	fs  fs.StatFS
 	pos token.Pos
+
+	// as is the as argument of the attribute being compiled, if any.
+	as string
 }
 
 // Compile interprets an embed attribute to either load a file
@@ -160,11 +260,35 @@ func (c *compiler) Compile(funcName string, scope adt.Value, a *internal.Attr) (
 		return nil, errors.Promote(err, "invalid attribute")
 	}
 
+	url, _, err := a.Lookup(0, "url")
+	if err != nil {
+		return nil, errors.Promote(err, "invalid attribute")
+	}
+
+	sum, _, err := a.Lookup(0, "sha256")
+	if err != nil {
+		return nil, errors.Promote(err, "invalid sha256 argument")
+	}
+
 	typ, _, err := a.Lookup(0, "type")
 	if err != nil {
 		return nil, errors.Promote(err, "invalid type argument")
 	}
 
+	as, _, err := a.Lookup(0, "as")
+	if err != nil {
+		return nil, errors.Promote(err, "invalid as argument")
+	}
+	switch as {
+	case "", "bytes", "base64", "sha256", "sha512":
+	default:
+		return nil, errors.Newf(a.Pos, "invalid as argument %q; must be one of bytes, base64, sha256, or sha512", as)
+	}
+	if as != "" && typ != "" && typ != "binary" {
+		return nil, errors.Newf(a.Pos, "as argument cannot be used with type %q", typ)
+	}
+	c.as = as
+
 	c.opCtx = adt.NewContext((*runtime.Runtime)(c.runtime), nil)
 
 	pos := a.Pos
@@ -180,8 +304,17 @@ func (c *compiler) Compile(funcName string, scope adt.Value, a *internal.Attr) (
 	}
 
 	switch {
+	case url != "":
+		if file != "" || glob != "" {
+			return nil, errors.Newf(a.Pos, "attribute cannot have both url and file or glob field")
+		}
+		return c.processURL(url, sum, typ)
+
+	case sum != "":
+		return nil, errors.Newf(a.Pos, "sha256 argument can only be used with url")
+
 	case file == "" && glob == "":
-		return nil, errors.Newf(a.Pos, "attribute must have file or glob field")
+		return nil, errors.Newf(a.Pos, "attribute must have file, glob, or url field")
 
 	case file != "" && glob != "":
 		return nil, errors.Newf(a.Pos, "attribute cannot have both file and glob field")
@@ -223,7 +356,7 @@ func (c *compiler) processGlob(glob, scope string, schema adt.Value) (adt.Expr,
 	// If we do not have a type, ensure the extension of the base is fully
 	// specified, i.e. does not contain any meta characters as specified by
 	// path.Match.
-	if scope == "" {
+	if scope == "" && c.as == "" {
 		ext := path.Ext(path.Base(glob))
 		if ext == "" || strings.ContainsAny(ext, "*?[\\") {
 			return nil, errors.Newf(c.pos, "extension not fully specified; type argument required")
@@ -324,6 +457,14 @@ func fsGlob(fsys fs.FS, pattern string) ([]string, error) {
 }
 
 func (c *compiler) decodeFile(file, scope string, schema adt.Value) (adt.Expr, errors.Error) {
+	if c.as != "" || c.interp.decoderFor(file, scope) != nil {
+		b, err := c.readFile(file)
+		if err != nil {
+			return nil, err
+		}
+		return c.decodeBytes(file, scope, b)
+	}
+
 	// Do not use the most obvious filetypes.Input in order to disable "auto"
 	// mode.
 	f, err := filetypes.ParseFileAndType(file, scope, filetypes.Def)
@@ -350,6 +491,28 @@ func (c *compiler) decodeFile(file, scope string, schema adt.Value) (adt.Expr, e
 	// TODO: this really should be done at the start of the build process.
 	// c.b.ExternFiles = append(c.b.ExternFiles, f)
 
+	return c.decode(f)
+}
+
+// decodeBytes decodes the contents b of the file with the given name,
+// which need not exist in the file system.
+func (c *compiler) decodeBytes(file, scope string, b []byte) (adt.Expr, errors.Error) {
+	if c.as != "" {
+		return c.embedRaw(b), nil
+	}
+	if d := c.interp.decoderFor(file, scope); d != nil {
+		return c.decodeCustom(file, d, b)
+	}
+	f, err := filetypes.ParseFileAndType(file, scope, filetypes.Def)
+	if err != nil {
+		return nil, errors.Promote(err, "invalid file type")
+	}
+	f.Source = b
+	return c.decode(f)
+}
+
+// decode decodes f, whose Source must be set, with a built-in decoder.
+func (c *compiler) decode(f *build.File) (adt.Expr, errors.Error) {
 	config := &encoding.Config{
 		// TODO: schema is currently the wrong schema, which is a bug in
 		// internal/core/runtime. There is also an outstanding design choice:
@@ -392,3 +555,64 @@ func (c *compiler) decodeFile(file, scope string, schema adt.Value) (adt.Expr, e
 	_, v := value.ToInternal(val)
 	return v, nil
 }
+
+// decoderFor reports the decoder registered for the given type, or for the
+// extension of file if typ is empty, or nil if there is none.
+func (i *interpreter) decoderFor(file, typ string) *decoder {
+	if typ != "" {
+		return i.decoders[typ]
+	}
+	return i.extensions[path.Ext(file)]
+}
+
+// decodeCustom decodes the contents b of file with a decoder registered
+// with [Decoder].
+func (c *compiler) decodeCustom(file string, d *decoder, b []byte) (adt.Expr, errors.Error) {
+	val, derr := d.decode(c.runtime, file, b)
+	if derr == nil {
+		derr = val.Err()
+	}
+	if derr != nil {
+		return nil, errors.Wrapf(derr, c.pos, "failed to decode file %s as %s", file, d.typ)
+	}
+	_, v := value.ToInternal(val)
+	return v, nil
+}
+
+// readFile reads the contents of file.
+func (c *compiler) readFile(file string) ([]byte, errors.Error) {
+	r, err := c.fs.Open(file)
+	if err != nil {
+		return nil, errors.Newf(c.pos, "open %v: no such file or directory", file)
+	}
+	defer r.Close()
+
+	info, err := r.Stat()
+	if err != nil {
+		return nil, errors.Promote(err, "failed to read file")
+	}
+	if info.IsDir() {
+		return nil, errors.Newf(c.pos, "cannot embed directories")
+	}
+	b, err := io.ReadAll(r)
+	if err != nil {
+		return nil, errors.Wrapf(err, c.pos, "failed to read file %s", file)
+	}
+	return b, nil
+}
+
+// embedRaw embeds the contents b of a file verbatim in the form given by
+// the as argument.
+func (c *compiler) embedRaw(b []byte) adt.Expr {
+	switch c.as {
+	case "base64":
+		return &adt.String{Str: base64.StdEncoding.EncodeToString(b)}
+	case "sha256":
+		return &adt.String{Str: hashOf(b)}
+	case "sha512":
+		sum := sha512.Sum512(b)
+		return &adt.String{Str: hex.EncodeToString(sum[:])}
+	default: // "bytes"
+		return &adt.Bytes{B: b}
+	}
+}
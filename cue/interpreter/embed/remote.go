@@ -0,0 +1,155 @@
+// Copyright 2025 CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package embed
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+
+	"cuelang.org/go/cue/errors"
+	"cuelang.org/go/internal/core/adt"
+)
+
+// maxRemoteSize is the maximum size of remote content.
+const maxRemoteSize = 64 << 20
+
+// remote fetches and caches remote content.
+type remote struct {
+	dir    string
+	client *http.Client
+}
+
+// Remote returns an Option that allows embedding remote content with the
+// url argument of the @embed attribute. Content is fetched with client,
+// or [http.DefaultClient] if client is nil.
+//
+// Content is cached in dir by its SHA-256 hash, so that it is only
+// fetched once. If dir is empty, content is fetched every time it is
+// embedded.
+//
+// As embedding remote content makes evaluating CUE depend on the
+// network, it should only be enabled when the user explicitly asks for it.
+func Remote(dir string, client *http.Client) Option {
+	return Option{func(i *interpreter) {
+		if client == nil {
+			client = http.DefaultClient
+		}
+		i.remote = &remote{dir: dir, client: client}
+	}}
+}
+
+// processURL embeds the content at rawURL, which must have the hex-encoded
+// SHA-256 hash sum.
+func (c *compiler) processURL(rawURL, sum, typ string) (adt.Expr, errors.Error) {
+	r := c.interp.remote
+	if r == nil {
+		return nil, errors.Newf(c.pos, "cannot embed %s: embedding remote content is not enabled", rawURL)
+	}
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, errors.Wrapf(err, c.pos, "invalid url argument")
+	}
+	if u.Scheme != "https" || u.Host == "" {
+		return nil, errors.Newf(c.pos, "invalid url argument %q: only https URLs are allowed", rawURL)
+	}
+	if sum == "" {
+		return nil, errors.Newf(c.pos, "cannot embed %s: sha256 argument required", rawURL)
+	}
+	if b, err := hex.DecodeString(sum); err != nil || len(b) != sha256.Size {
+		return nil, errors.Newf(c.pos, "invalid sha256 argument %q: must be a hexadecimal SHA-256 hash", sum)
+	}
+
+	b, err := r.fetch(u, sum)
+	if err != nil {
+		return nil, errors.Wrapf(err, c.pos, "cannot embed %s", rawURL)
+	}
+	// The file type is determined by the extension of the URL path, as for
+	// files.
+	return c.decodeBytes(path.Base(u.Path), typ, b)
+}
+
+// fetch returns the content at u, which must have the hex-encoded SHA-256
+// hash sum, from the cache if possible.
+func (r *remote) fetch(u *url.URL, sum string) ([]byte, error) {
+	var cached string
+	if r.dir != "" {
+		cached = filepath.Join(r.dir, "sha256", sum)
+		// Content in the cache was verified when it was added, but check
+		// again in case the cache was corrupted.
+		if b, err := os.ReadFile(cached); err == nil && hashOf(b) == sum {
+			return b, nil
+		}
+	}
+
+	resp, err := r.client.Get(u.String())
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected HTTP status %s", resp.Status)
+	}
+	b, err := io.ReadAll(io.LimitReader(resp.Body, maxRemoteSize+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(b) > maxRemoteSize {
+		return nil, fmt.Errorf("content larger than %d bytes", maxRemoteSize)
+	}
+	if got := hashOf(b); got != sum {
+		return nil, fmt.Errorf("content has SHA-256 hash %s, but sha256 argument is %s", got, sum)
+	}
+
+	if cached != "" {
+		// Failing to cache content only means that it will be fetched
+		// again, so ignore errors.
+		writeCache(cached, b)
+	}
+	return b, nil
+}
+
+// writeCache atomically writes b to the cache file name.
+func writeCache(name string, b []byte) error {
+	if err := os.MkdirAll(filepath.Dir(name), 0o777); err != nil {
+		return err
+	}
+	f, err := os.CreateTemp(filepath.Dir(name), ".tmp-")
+	if err != nil {
+		return err
+	}
+	_, err = f.Write(b)
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err == nil {
+		err = os.Rename(f.Name(), name)
+	}
+	if err != nil {
+		os.Remove(f.Name())
+	}
+	return err
+}
+
+func hashOf(b []byte) string {
+	sum := sha256.Sum256(b)
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,97 @@
+// Copyright 2025 CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package embed_test
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"cuelang.org/go/cue"
+	"cuelang.org/go/cue/ast"
+	"cuelang.org/go/cue/ast/astutil"
+	"cuelang.org/go/cue/cuecontext"
+	"cuelang.org/go/cue/errors"
+	"cuelang.org/go/cue/format"
+	"cuelang.org/go/cue/interpreter/embed"
+	"cuelang.org/go/internal/cuetxtar"
+)
+
+// remoteServer serves {"codes": ["nl", "se"]} at /data.json of any host,
+// counting the requests. It fails all requests once it is down.
+type remoteServer struct {
+	requests int
+	down     bool
+}
+
+func (s *remoteServer) RoundTrip(req *http.Request) (*http.Response, error) {
+	if s.down {
+		return nil, fmt.Errorf("server is down")
+	}
+	s.requests++
+	w := httptest.NewRecorder()
+	if req.URL.Path != "/data.json" {
+		http.NotFound(w, req)
+	} else {
+		fmt.Fprint(w, `{"codes": ["nl", "se"]}`)
+	}
+	return w.Result(), nil
+}
+
+// TestRemote tests embedding remote content served by remoteServer, and
+// reports the number of requests made. Remote content is not enabled for
+// tests with the #local tag. Tests with the #offline tag are built again
+// once the server is down, which only succeeds with cached content.
+func TestRemote(t *testing.T) {
+	test := cuetxtar.TxTarTest{
+		Root: "./testdata/remote",
+		Name: "remote",
+	}
+
+	test.Run(t, func(t *cuetxtar.Test) {
+		srv := &remoteServer{}
+		var options []embed.Option
+		if !t.HasTag("local") {
+			options = append(options, embed.Remote(t.TempDir(), &http.Client{Transport: srv}))
+		}
+		build := func() {
+			ctx := cuecontext.New(cuecontext.Interpreter(embed.New(options...)))
+			v := ctx.BuildInstance(t.Instances(".")[0])
+			if err := v.Validate(); err != nil {
+				fmt.Fprintln(t, "Errors:")
+				t.WriteErrors(errors.Promote(err, ""))
+				fmt.Fprintln(t, "\nResult:")
+			}
+			syntax := v.Syntax(cue.Attributes(false), cue.Final(), cue.ErrorsAsValues(true))
+			file, err := astutil.ToFile(syntax.(ast.Expr))
+			if err != nil {
+				t.Fatal(err)
+			}
+			b, err := format.Node(file)
+			if err != nil {
+				t.Fatal(err)
+			}
+			t.Write(b)
+			fmt.Fprintf(t, "\nrequests: %d\n", srv.requests)
+		}
+		build()
+		if t.HasTag("offline") {
+			srv.down = true
+			fmt.Fprintln(t, "\nOffline:")
+			build()
+		}
+	})
+}
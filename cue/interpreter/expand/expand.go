@@ -0,0 +1,171 @@
+// Copyright 2025 CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package expand allows Go programs to define attributes which compute
+// the values of fields when a package is built, in the same way as
+// @embed attributes embed files.
+//
+// This package is EXPERIMENTAL and subject to change.
+//
+// # Defining attributes
+//
+// An attribute is defined by passing the result of [New] to
+// [cuelang.org/go/cue/cuecontext.New], giving the name of the attribute
+// and the Go function which computes the value of a field:
+//
+//	ctx := cuecontext.New(cuecontext.Interpreter(expand.New("vault",
+//		func(ctx *cue.Context, f expand.Field) (cue.Value, error) {
+//			path, err := f.Attribute.String(0)
+//			if err != nil {
+//				return cue.Value{}, err
+//			}
+//			secret, err := readSecret(path)
+//			if err != nil {
+//				return cue.Value{}, err
+//			}
+//			return ctx.Encode(secret), nil
+//		})))
+//
+// # Using attributes in CUE
+//
+// As with other external interpreters, CUE files need to declare their
+// intent to use an attribute with a file-level attribute, after which
+// fields may use it:
+//
+//	@extern(vault)
+//	package p
+//
+//	db: password: string @vault("secret/db/password")
+//
+// The value computed for a field is unified with the value of the field
+// as declared, so the declaration can constrain the computed value. A
+// file can only declare one external interpreter, so attributes defined
+// with different calls to New must be used in separate files.
+package expand
+
+import (
+	"cuelang.org/go/cue"
+	"cuelang.org/go/cue/ast"
+	"cuelang.org/go/cue/build"
+	"cuelang.org/go/cue/cuecontext"
+	"cuelang.org/go/cue/errors"
+	"cuelang.org/go/cue/token"
+	"cuelang.org/go/internal"
+	"cuelang.org/go/internal/core/adt"
+	"cuelang.org/go/internal/core/runtime"
+	"cuelang.org/go/internal/value"
+)
+
+// A Field describes a field whose value is computed from its attribute.
+type Field struct {
+	// Instance is the package containing the field.
+	Instance *build.Instance
+
+	// Name is the name of the field, or the value of the name argument
+	// of the attribute, if present.
+	Name string
+
+	// Attribute is the attribute of the field.
+	Attribute cue.Attribute
+
+	// Pos is the position of the attribute.
+	Pos token.Pos
+}
+
+// A Func computes the value of a field from its attribute. The result
+// must be created in ctx.
+type Func func(ctx *cue.Context, f Field) (cue.Value, error)
+
+// interpreter is a [cuecontext.ExternInterpreter] for an attribute
+// defined with New.
+type interpreter struct {
+	name string
+	f    Func
+}
+
+// New returns an interpreter for fields with an attribute of the given
+// name, whose values are computed by f, as a
+// [cuecontext.ExternInterpreter] suitable for passing to
+// [cuecontext.New]. Files using the attribute must be marked with
+// @extern(name).
+func New(name string, f Func) cuecontext.ExternInterpreter {
+	return &interpreter{name: name, f: f}
+}
+
+func (i *interpreter) Kind() string {
+	return i.name
+}
+
+// NewCompiler returns a compiler which computes the values of fields of
+// the given build.Instance.
+func (i *interpreter) NewCompiler(b *build.Instance, r *runtime.Runtime) (runtime.Compiler, errors.Error) {
+	return &compiler{
+		interp: i,
+		b:      b,
+		ctx:    (*cue.Context)(r),
+	}, nil
+}
+
+// A compiler is a [runtime.Compiler] which calls the Func of an
+// interpreter.
+type compiler struct {
+	interp *interpreter
+	b      *build.Instance
+	ctx    *cue.Context
+}
+
+// Compile calls the Func of the interpreter for the field with the given
+// name and returns its result.
+func (c *compiler) Compile(name string, scope adt.Value, a *internal.Attr) (adt.Expr, errors.Error) {
+	attr, err := c.attribute(a)
+	if err != nil {
+		return nil, err
+	}
+	v, ferr := c.interp.f(c.ctx, Field{
+		Instance:  c.b,
+		Name:      name,
+		Attribute: attr,
+		Pos:       a.Pos,
+	})
+	if ferr == nil {
+		ferr = v.Err()
+	}
+	if e, ok := ferr.(errors.Error); ok {
+		return nil, e
+	} else if ferr != nil {
+		return nil, errors.Newf(a.Pos, "%v", ferr)
+	}
+	if v.Context() != c.ctx {
+		return nil, errors.Newf(a.Pos, "value was not created in the given context")
+	}
+	return value.Vertex(v), nil
+}
+
+// attribute returns a as a [cue.Attribute], by looking it up from a
+// field declared with it.
+func (c *compiler) attribute(a *internal.Attr) (cue.Attribute, errors.Error) {
+	label := ast.NewIdent("x")
+	v := c.ctx.BuildExpr(ast.NewStruct(&ast.Field{
+		Label: label,
+		Value: ast.NewIdent("_"),
+		Attrs: []*ast.Attribute{{
+			At:   a.Pos,
+			Text: "@" + c.interp.name + "(" + a.Body + ")",
+		}},
+	}))
+	if err := v.Err(); err != nil {
+		return cue.Attribute{}, errors.Promote(err, "invalid attribute")
+	}
+	return v.LookupPath(cue.MakePath(cue.Str(label.Name))).Attribute(c.interp.name), nil
+}
@@ -0,0 +1,80 @@
+// Copyright 2025 CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package expand_test
+
+import (
+	"fmt"
+	"testing"
+
+	"cuelang.org/go/cue"
+	"cuelang.org/go/cue/ast"
+	"cuelang.org/go/cue/ast/astutil"
+	"cuelang.org/go/cue/cuecontext"
+	"cuelang.org/go/cue/errors"
+	"cuelang.org/go/cue/format"
+	"cuelang.org/go/cue/interpreter/expand"
+	"cuelang.org/go/internal/cuetxtar"
+)
+
+var secrets = map[string]string{
+	"db/password": "hunter2",
+	"db/port":     "5432",
+}
+
+// vault returns the secret at the path given by the first argument of
+// the attribute, converted to the kind given by the as argument.
+func vault(ctx *cue.Context, f expand.Field) (cue.Value, error) {
+	path, err := f.Attribute.String(0)
+	if err != nil {
+		return cue.Value{}, err
+	}
+	s, ok := secrets[path]
+	if !ok {
+		return cue.Value{}, fmt.Errorf("no secret at %s", path)
+	}
+	if as, _, _ := f.Attribute.Lookup(1, "as"); as == "int" {
+		return ctx.CompileString(s), nil
+	}
+	return ctx.Encode(f.Name + "=" + s), nil
+}
+
+// TestExpand tests the expansion of @vault attributes with vault.
+func TestExpand(t *testing.T) {
+	test := cuetxtar.TxTarTest{
+		Root: "./testdata",
+		Name: "expand",
+	}
+
+	test.Run(t, func(t *cuetxtar.Test) {
+		ctx := cuecontext.New(cuecontext.Interpreter(expand.New("vault", vault)))
+		v := ctx.BuildInstance(t.Instance())
+
+		if err := v.Validate(); err != nil {
+			fmt.Fprintln(t, "Errors:")
+			t.WriteErrors(errors.Promote(err, ""))
+			fmt.Fprintln(t, "\nResult:")
+		}
+		syntax := v.Syntax(cue.Attributes(false), cue.Final(), cue.ErrorsAsValues(true))
+		file, err := astutil.ToFile(syntax.(ast.Expr))
+		if err != nil {
+			t.Fatal(err)
+		}
+		b, err := format.Node(file)
+		if err != nil {
+			t.Fatal(err)
+		}
+		t.Write(b)
+	})
+}
@@ -0,0 +1,144 @@
+// Copyright 2025 CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package jsonfunc implements the functions of extern interpreters which
+// pass arguments to and results from their implementations as JSON.
+//
+// All such interpreters use the same field attribute:
+//
+//	_f: _ @extern("file", name=fn, sig="func(#A, string): #B")
+//
+// The file must be relative to the directory of the CUE file, and must
+// not refer to its parent directory; see [File]. The optional name
+// defaults to the name of the field. The sig uses the grammar of the
+// Wasm interpreter, but each expr may refer to any CUE type, including
+// structs and lists; see [ParseSig]. Arguments must be concrete, and
+// are checked against their types before a call, as is the result
+// afterwards.
+//
+// The public documentation of this attribute lives in the package
+// documentation of cuelang.org/go/cue/interpreter/js, which the other
+// interpreters refer to; keep it in sync with this package.
+package jsonfunc
+
+import (
+	"encoding/json"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"cuelang.org/go/cue"
+	"cuelang.org/go/cue/errors"
+	cuejson "cuelang.org/go/encoding/json"
+	"cuelang.org/go/internal"
+	"cuelang.org/go/internal/core/adt"
+	"cuelang.org/go/internal/pkg"
+	"cuelang.org/go/internal/value"
+)
+
+// File returns the absolute path of the file named by the first
+// argument of a, which must be relative to the directory of the CUE
+// file containing a, and must not refer to its parent directory. The
+// kind of file, such as "program", is used in errors.
+func File(a *internal.Attr, kind string) (string, errors.Error) {
+	name, err := a.String(0)
+	if err != nil {
+		return "", errors.Promote(err, "invalid attribute")
+	}
+	clean := path.Clean(name)
+	switch {
+	case name == "":
+		return "", errors.Newf(a.Pos, "missing %s name", kind)
+	case path.IsAbs(clean):
+		return "", errors.Newf(a.Pos, "%s %q: only relative paths are allowed", kind, name)
+	case clean == ".." || strings.HasPrefix(clean, "../"):
+		return "", errors.Newf(a.Pos, "%s %q: cannot refer to parent directory", kind, name)
+	}
+	dir := filepath.Dir(a.Pos.File().Name())
+	file, err := filepath.Abs(filepath.Join(dir, filepath.FromSlash(clean)))
+	if err != nil {
+		return "", errors.Wrapf(err, a.Pos, "%s %q", kind, name)
+	}
+	return file, nil
+}
+
+// A CallFunc calls the named function with arguments encoded as JSON,
+// and returns its result encoded as JSON.
+type CallFunc func(name string, args []json.RawMessage) (json.RawMessage, error)
+
+// Builtin returns a builtin with the given name, which calls f with
+// its arguments. The types in sig, as returned by [ParseSig], may refer
+// to fields in scope, which are only resolved during evaluation, so the
+// arguments and result are checked against them when the builtin is
+// called.
+func Builtin(name string, scope adt.Value, sig []string, f CallFunc) adt.Expr {
+	params := make([]pkg.Param, len(sig)-1)
+	for i := range params {
+		params[i].Kind = adt.TopKind
+	}
+	return pkg.ToBuiltin(&pkg.Builtin{
+		Name:   name,
+		Params: params,
+		Result: adt.TopKind,
+		Func: func(c *pkg.CallCtxt) {
+			types, err := compileTypes(c.OpContext(), adt.ToVertex(scope), sig)
+			if err != nil {
+				c.Err = err
+				return
+			}
+			argsTyp, resTyp := splitLast(types)
+			call(c, f, name, argsTyp, resTyp)
+		},
+	})
+}
+
+// call calls f with the arguments of c, after checking them against
+// argsTyp, and checks the result against resTyp.
+func call(c *pkg.CallCtxt, f CallFunc, name string, argsTyp []cue.Value, resTyp cue.Value) {
+	args := make([]json.RawMessage, len(argsTyp))
+	for i, typ := range argsTyp {
+		v := c.Value(i).Unify(typ)
+		if err := v.Validate(cue.Concrete(true)); err != nil {
+			c.Err = errors.Wrapf(err, c.Pos(), "invalid argument %d to %s", i, name)
+			return
+		}
+		b, err := v.MarshalJSON()
+		if err != nil {
+			c.Err = err
+			return
+		}
+		args[i] = b
+	}
+	if !c.Do() {
+		return
+	}
+	res, err := f(name, args)
+	if err != nil {
+		c.Err = err
+		return
+	}
+	expr, err := cuejson.Extract(name, res)
+	if err != nil {
+		c.Err = errors.Wrapf(err, c.Pos(), "invalid result of %s", name)
+		return
+	}
+	v := resTyp.Context().BuildExpr(expr)
+	if err := v.Unify(resTyp).Validate(cue.Concrete(true)); err != nil {
+		c.Err = errors.Wrapf(err, c.Pos(), "invalid result of %s", name)
+		return
+	}
+	// Return the result itself rather than its unification with resTyp,
+	// which is closed if resTyp is a definition.
+	_, c.Ret = value.ToInternal(v)
+}
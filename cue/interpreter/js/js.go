@@ -0,0 +1,278 @@
+// Copyright 2025 CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package js allows users to implement functions for CUE in
+// JavaScript.
+//
+// To enable JavaScript support, pass the result of [New] to
+// [cuelang.org/go/cue/cuecontext.New]. In the command line tool, it is
+// enabled by the externjs experiment of CUE_EXPERIMENT.
+//
+// This package is EXPERIMENTAL and subject to change.
+//
+// # Using JavaScript in CUE
+//
+// CUE files need to declare their intent by specifying a file-level
+// attribute:
+//
+//	@extern(js)
+//	package p
+//
+// Individual functions can then be imported from JavaScript files
+// using a field attribute:
+//
+//	_isPort: _ @extern("validate.js", sig="func(int): bool")
+//	_slug:   _ @extern("transform.js", name=toSlug, sig="func(string): string")
+//
+// The first attribute argument is the path of the JavaScript file,
+// relative to the directory of the CUE file which uses it; it must not
+// refer to a parent directory. The sig argument gives the types of the
+// arguments and the result of the function, using the grammar described
+// in [cuelang.org/go/cue/interpreter/wasm]. Unlike for Wasm, each type
+// may be any CUE type, including structs and lists. Arguments must be
+// concrete, and are checked against their types before a call, as is
+// the result afterwards. The function called has the name of the field,
+// unless the name argument says otherwise.
+//
+// The process and starlark interpreters use this attribute as well.
+// As functions cannot be exported, fields holding them should be hidden
+// in configurations which are exported.
+//
+// # JavaScript files
+//
+// Each file is run as an ECMAScript 5.1 script, with many later
+// features, by the [goja] engine, and must declare the functions it
+// provides in its global scope:
+//
+//	function isPort(n) {
+//		return n > 0 && n < 65536;
+//	}
+//
+// Arguments are passed as if decoded with JSON.parse, and results are
+// converted as if encoded with JSON.stringify. Exceptions thrown by a
+// function are reported as errors.
+//
+// Functions must be free of observable side effects: the result of a
+// call must depend only on its arguments. Scripts have no access to the
+// file system, the network, or modules, and to keep them deterministic,
+// the current time is always the Unix epoch and Math.random returns the
+// same sequence of numbers in each file. CUE may call a function any
+// number of times with the same arguments, including not at all, and
+// in any order.
+//
+// [goja]: https://github.com/dop251/goja
+package js
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/rand"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/dop251/goja"
+
+	"cuelang.org/go/cue/build"
+	"cuelang.org/go/cue/cuecontext"
+	"cuelang.org/go/cue/errors"
+	"cuelang.org/go/cue/interpreter/internal/jsonfunc"
+	"cuelang.org/go/internal"
+	"cuelang.org/go/internal/core/adt"
+	"cuelang.org/go/internal/core/runtime"
+)
+
+// DefaultTimeout is the time for which a JavaScript file may run when
+// it is loaded, and for which each call of a function may run, unless
+// another timeout is given with [Timeout].
+const DefaultTimeout = 10 * time.Second
+
+// interpreter is a [cuecontext.ExternInterpreter] for JavaScript.
+type interpreter struct {
+	timeout time.Duration
+}
+
+// Option configures the interpreter returned by [New].
+type Option struct {
+	apply func(i *interpreter)
+}
+
+// Timeout returns an Option that limits the time for which a JavaScript
+// file may run when it is loaded, and for which each call of a function
+// may run, to d. A d of zero or less means no limit.
+func Timeout(d time.Duration) Option {
+	return Option{func(i *interpreter) {
+		i.timeout = d
+	}}
+}
+
+// New returns a new JavaScript interpreter as a
+// [cuecontext.ExternInterpreter] suitable for passing to
+// [cuecontext.New].
+func New(options ...Option) cuecontext.ExternInterpreter {
+	i := &interpreter{timeout: DefaultTimeout}
+	for _, o := range options {
+		o.apply(i)
+	}
+	return i
+}
+
+func (i *interpreter) Kind() string {
+	return "js"
+}
+
+// NewCompiler returns a JavaScript compiler that services the specified
+// build.Instance.
+func (i *interpreter) NewCompiler(b *build.Instance, r *runtime.Runtime) (runtime.Compiler, errors.Error) {
+	return &compiler{
+		timeout: i.timeout,
+		scripts: make(map[string]*script),
+	}, nil
+}
+
+// A compiler is a [runtime.Compiler] that provides functions
+// implemented in JavaScript.
+type compiler struct {
+	timeout time.Duration
+
+	// mu serializes access to scripts.
+	mu sync.Mutex
+
+	// scripts maps absolute file names to their loaded scripts.
+	scripts map[string]*script
+}
+
+// Compile returns the function described by the given @extern
+// attribute as an [adt.Builtin] with the given name.
+func (c *compiler) Compile(funcName string, scope adt.Value, a *internal.Attr) (adt.Expr, errors.Error) {
+	file, err := jsonfunc.File(a, "file")
+	if err != nil {
+		return nil, err
+	}
+	sig, serr := jsonfunc.ParseSig(a)
+	if serr != nil {
+		return nil, errors.Newf(a.Pos, "invalid function signature: %v", serr)
+	}
+	s, serr := c.script(file)
+	if serr != nil {
+		return nil, errors.Newf(a.Pos, "can't load JavaScript file: %v", serr)
+	}
+	if _, ok := goja.AssertFunction(s.vm.Get(funcName)); !ok {
+		return nil, errors.Newf(a.Pos, "can't find function %q in JavaScript file %s", funcName, file)
+	}
+	return jsonfunc.Builtin(funcName, scope, sig, s.call), nil
+}
+
+// script returns the script loaded from file, loading it if necessary.
+func (c *compiler) script(file string) (*script, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	s, ok := c.scripts[file]
+	if !ok {
+		var err error
+		s, err = load(file, c.timeout)
+		if err != nil {
+			return nil, err
+		}
+		c.scripts[file] = s
+	}
+	return s, nil
+}
+
+// A script is a JavaScript file loaded into its own runtime.
+type script struct {
+	// mu serializes calls, as a goja.Runtime may only be used by one
+	// goroutine at a time.
+	mu sync.Mutex
+
+	vm               *goja.Runtime
+	parse, stringify goja.Callable
+	timeout          time.Duration
+}
+
+func load(file string, timeout time.Duration) (*script, error) {
+	src, err := os.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	vm := goja.New()
+	vm.SetTimeSource(func() time.Time { return time.Unix(0, 0) })
+	vm.SetRandSource(rand.New(rand.NewSource(1)).Float64)
+	stop := interruptAfter(vm, timeout)
+	_, err = vm.RunScript(file, string(src))
+	stop()
+	if err != nil {
+		return nil, err
+	}
+	s := &script{vm: vm, timeout: timeout}
+	jsonObj := vm.Get("JSON").ToObject(vm)
+	s.parse, _ = goja.AssertFunction(jsonObj.Get("parse"))
+	s.stringify, _ = goja.AssertFunction(jsonObj.Get("stringify"))
+	return s, nil
+}
+
+// call calls the function name with args, and returns its result.
+func (s *script) call(name string, args []json.RawMessage) (json.RawMessage, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	fn, ok := goja.AssertFunction(s.vm.Get(name))
+	if !ok {
+		return nil, fmt.Errorf("%s is not a function", name)
+	}
+	jsArgs := make([]goja.Value, len(args))
+	for i, arg := range args {
+		v, err := s.parse(goja.Undefined(), s.vm.ToValue(string(arg)))
+		if err != nil {
+			return nil, err
+		}
+		jsArgs[i] = v
+	}
+	stop := interruptAfter(s.vm, s.timeout)
+	res, err := fn(goja.Undefined(), jsArgs...)
+	stop()
+	if err != nil {
+		return nil, err
+	}
+	str, err := s.stringify(goja.Undefined(), res)
+	if err != nil {
+		return nil, err
+	}
+	if goja.IsUndefined(str) {
+		return nil, fmt.Errorf("%s returned a value which cannot be represented in JSON, such as undefined", name)
+	}
+	return json.RawMessage(str.String()), nil
+}
+
+// interruptAfter interrupts vm if it is still running after d, and returns
+// a function which must be called once vm has returned, to cancel the
+// interruption.
+func interruptAfter(vm *goja.Runtime, d time.Duration) (stop func()) {
+	if d <= 0 {
+		return func() {}
+	}
+	done := make(chan struct{})
+	t := time.AfterFunc(d, func() {
+		defer close(done)
+		vm.Interrupt(fmt.Sprintf("timed out after %v", d))
+	})
+	return func() {
+		if !t.Stop() {
+			// The interruption may have come too late to stop vm, so
+			// clear it to not interrupt the next call.
+			<-done
+		}
+		vm.ClearInterrupt()
+	}
+}
@@ -0,0 +1,80 @@
+// Copyright 2025 CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package js_test
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"cuelang.org/go/cue"
+	"cuelang.org/go/cue/ast"
+	"cuelang.org/go/cue/ast/astutil"
+	"cuelang.org/go/cue/cuecontext"
+	"cuelang.org/go/cue/errors"
+	"cuelang.org/go/cue/format"
+	"cuelang.org/go/cue/interpreter/js"
+	"cuelang.org/go/internal/cuetxtar"
+)
+
+// TestJS tests the JavaScript interpreter with the functions of
+// testdata/f.js. The #timeout value of a test sets the timeout of the
+// interpreter.
+func TestJS(t *testing.T) {
+	test := cuetxtar.TxTarTest{
+		Root: "./testdata",
+		Name: "js",
+	}
+
+	test.Run(t, func(t *cuetxtar.Test) {
+		var options []js.Option
+		if s, ok := t.Value("timeout"); ok {
+			d, err := time.ParseDuration(s)
+			if err != nil {
+				t.Fatal(err)
+			}
+			options = append(options, js.Timeout(d))
+		}
+		ctx := cuecontext.New(cuecontext.Interpreter(js.New(options...)))
+		v := ctx.BuildInstance(t.Instance())
+
+		var b strings.Builder
+		if err := v.Validate(); err != nil {
+			fmt.Fprintln(&b, "Errors:")
+			errors.Print(&b, err, &errors.Config{Cwd: t.Dir, ToSlash: true})
+			fmt.Fprintln(&b, "\nResult:")
+		}
+		b.Write(formatValue(t, v))
+		// Messages may name the JavaScript file by its absolute path.
+		fmt.Fprint(t, filepath.ToSlash(strings.ReplaceAll(b.String(), t.Dir, "$DIR")))
+	})
+}
+
+// formatValue formats v, with the errors of its fields in place of their
+// values.
+func formatValue(t *cuetxtar.Test, v cue.Value) []byte {
+	syntax := v.Syntax(cue.Final(), cue.ErrorsAsValues(true))
+	file, err := astutil.ToFile(syntax.(ast.Expr))
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := format.Node(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return b
+}
@@ -0,0 +1,21 @@
+function add(a, b) {
+	return a + b;
+}
+
+function greet(p) {
+	return {greeting: "hello, " + p.name};
+}
+
+function now() {
+	return new Date().toISOString();
+}
+
+function fail(reason) {
+	throw new Error(reason);
+}
+
+function nothing() {}
+
+function loop() {
+	for (;;) {}
+}
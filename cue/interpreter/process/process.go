@@ -0,0 +1,228 @@
+// Copyright 2025 CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package process allows users to implement functions for CUE in any
+// language, as programs which CUE runs as subprocesses.
+//
+// To enable this, pass the result of [New] to
+// [cuelang.org/go/cue/cuecontext.New]. In the command line tool, it is
+// enabled with CUE_EXPERIMENT=externprocess.
+//
+// This package is EXPERIMENTAL and subject to change.
+//
+// # Using programs in CUE
+//
+// CUE files need to declare their intent by specifying a file-level
+// attribute:
+//
+//	@extern(process)
+//	package p
+//
+// Individual functions can then be imported from programs using a
+// field attribute:
+//
+//	add:   _ @extern("./plugin", sig="func(int, int): int")
+//	upper: _ @extern("./plugin", name=toUpper, sig="func(string): string")
+//
+// The first attribute argument is the path of the program, relative to
+// the directory of the CUE file which uses it. The sig and name
+// arguments, and the checks of arguments and results against sig, work
+// as for [cuelang.org/go/cue/interpreter/js].
+//
+// # Protocol
+//
+// The program is started the first time one of its functions is
+// called, in its own directory and with the environment of CUE. It is
+// sent requests on its standard input and must write responses to its
+// standard output, using [JSON-RPC 2.0] with one JSON object per line.
+// Anything written to standard error is passed through to that of CUE.
+//
+// A request calls the method with the name of the function, with the
+// arguments encoded as a JSON array:
+//
+//	{"jsonrpc": "2.0", "id": 1, "method": "add", "params": [1, 2]}
+//
+// A response holds the result as JSON, or an error:
+//
+//	{"jsonrpc": "2.0", "id": 1, "result": 3}
+//	{"jsonrpc": "2.0", "id": 2, "error": {"code": -32601, "message": "unknown method"}}
+//
+// Requests are sent one at a time, each waiting for its response. The
+// program is not sent any other messages, and should exit when its
+// standard input is closed. A program which does not respond to a
+// request within the time given by [Timeout], which defaults to
+// [DefaultTimeout], is killed, and started again for the next call.
+//
+// Programs are killed once the functions which use them are garbage
+// collected, or when the interpreter is closed: the value returned by
+// [New] implements [io.Closer], and closing it kills all programs it
+// started, and waits for them to exit.
+//
+// # Requirements for functions
+//
+// Functions must be free of observable side effects: the result of a
+// call must depend only on its arguments. CUE may call a function any
+// number of times with the same arguments, including not at all, and
+// may start a program more than once. Failure to provide these
+// guarantees causes the CUE evaluation to be undefined.
+//
+// [JSON-RPC 2.0]: https://www.jsonrpc.org/specification
+package process
+
+import (
+	"encoding/json"
+	goruntime "runtime"
+	"sync"
+	"time"
+
+	"cuelang.org/go/cue/build"
+	"cuelang.org/go/cue/cuecontext"
+	"cuelang.org/go/cue/errors"
+	"cuelang.org/go/cue/interpreter/internal/jsonfunc"
+	"cuelang.org/go/internal"
+	"cuelang.org/go/internal/core/adt"
+	"cuelang.org/go/internal/core/runtime"
+)
+
+// DefaultTimeout is the time within which a program must respond to each
+// call of a function, unless another timeout is given with [Timeout].
+const DefaultTimeout = 10 * time.Second
+
+// interpreter is a [cuecontext.ExternInterpreter] for programs run as
+// subprocesses.
+type interpreter struct {
+	timeout time.Duration
+
+	// mu guards running.
+	mu sync.Mutex
+
+	// running holds the processes which have been started and not
+	// stopped yet, so that they can be stopped by Close.
+	running map[*process]bool
+}
+
+// Option configures the interpreter returned by [New].
+type Option struct {
+	apply func(i *interpreter)
+}
+
+// Timeout returns an Option that limits the time within which a program
+// must respond to each call of a function to d. A d of zero or less
+// means no limit.
+func Timeout(d time.Duration) Option {
+	return Option{func(i *interpreter) {
+		i.timeout = d
+	}}
+}
+
+// New returns a new interpreter for programs run as subprocesses as a
+// [cuecontext.ExternInterpreter] suitable for passing to
+// [cuecontext.New]. It also implements [io.Closer].
+func New(options ...Option) cuecontext.ExternInterpreter {
+	i := &interpreter{
+		timeout: DefaultTimeout,
+		running: make(map[*process]bool),
+	}
+	for _, o := range options {
+		o.apply(i)
+	}
+	return i
+}
+
+// Close kills all programs started by the interpreter and waits for
+// them to exit. Calls in progress are waited for first. Programs are
+// started again when their functions are called afterwards.
+func (i *interpreter) Close() error {
+	i.mu.Lock()
+	running := make([]*process, 0, len(i.running))
+	for p := range i.running {
+		running = append(running, p)
+	}
+	i.mu.Unlock()
+	for _, p := range running {
+		p.mu.Lock()
+		p.stop()
+		p.mu.Unlock()
+	}
+	return nil
+}
+
+func (i *interpreter) Kind() string {
+	return "process"
+}
+
+// NewCompiler returns a compiler that services the specified
+// build.Instance.
+func (i *interpreter) NewCompiler(b *build.Instance, r *runtime.Runtime) (runtime.Compiler, errors.Error) {
+	return &compiler{
+		interp:   i,
+		programs: make(map[string]*program),
+	}, nil
+}
+
+// A compiler is a [runtime.Compiler] that provides functions
+// implemented by programs run as subprocesses.
+type compiler struct {
+	interp *interpreter
+
+	// mu serializes access to programs.
+	mu sync.Mutex
+
+	// programs maps absolute program names to their programs.
+	programs map[string]*program
+}
+
+// A program refers to the process of a program file. The process is
+// stopped when the program is garbage collected, which happens once the
+// functions calling it are no longer used.
+type program struct {
+	p *process
+}
+
+// Compile returns the function described by the given @extern
+// attribute as an [adt.Builtin] with the given name.
+func (c *compiler) Compile(funcName string, scope adt.Value, a *internal.Attr) (adt.Expr, errors.Error) {
+	file, err := jsonfunc.File(a, "program")
+	if err != nil {
+		return nil, err
+	}
+	sig, serr := jsonfunc.ParseSig(a)
+	if serr != nil {
+		return nil, errors.Newf(a.Pos, "invalid function signature: %v", serr)
+	}
+	prog := c.program(file)
+	return jsonfunc.Builtin(funcName, scope, sig, func(method string, params []json.RawMessage) (json.RawMessage, error) {
+		// Refer to prog rather than its process, so that it is only
+		// garbage collected along with the functions.
+		return prog.p.call(method, params)
+	}), nil
+}
+
+// program returns the program for the file, whose process is started
+// when it is first called.
+func (c *compiler) program(file string) *program {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	prog, ok := c.programs[file]
+	if !ok {
+		prog = &program{p: &process{file: file, interp: c.interp}}
+		goruntime.SetFinalizer(prog, func(prog *program) {
+			prog.p.mu.Lock()
+			defer prog.p.mu.Unlock()
+			prog.p.stop()
+		})
+		c.programs[file] = prog
+	}
+	return prog
+}
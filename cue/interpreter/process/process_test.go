@@ -0,0 +1,156 @@
+// Copyright 2025 CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package process_test
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+
+	"cuelang.org/go/cue"
+	"cuelang.org/go/cue/ast"
+	"cuelang.org/go/cue/ast/astutil"
+	"cuelang.org/go/cue/cuecontext"
+	"cuelang.org/go/cue/errors"
+	"cuelang.org/go/cue/format"
+	"cuelang.org/go/cue/interpreter/process"
+	"cuelang.org/go/internal/cuetxtar"
+)
+
+// TestMain runs the test binary as the plugin used by the tests when
+// CUE_TEST_PROCESS_PLUGIN is set. The program testdata/plugin runs the
+// test binary named by CUE_TEST_PROCESS_EXE in this way.
+func TestMain(m *testing.M) {
+	if os.Getenv("CUE_TEST_PROCESS_PLUGIN") != "" {
+		plugin()
+		return
+	}
+	exe, err := os.Executable()
+	if err != nil {
+		panic(err)
+	}
+	os.Setenv("CUE_TEST_PROCESS_EXE", exe)
+	os.Exit(m.Run())
+}
+
+func plugin() {
+	type request struct {
+		ID     int64             `json:"id"`
+		Method string            `json:"method"`
+		Params []json.RawMessage `json:"params"`
+	}
+	in := bufio.NewScanner(os.Stdin)
+	out := json.NewEncoder(os.Stdout)
+	for in.Scan() {
+		var req request
+		if err := json.Unmarshal(in.Bytes(), &req); err != nil {
+			panic(err)
+		}
+		resp := map[string]any{"jsonrpc": "2.0", "id": req.ID}
+		switch req.Method {
+		case "add":
+			var a, b int
+			json.Unmarshal(req.Params[0], &a)
+			json.Unmarshal(req.Params[1], &b)
+			resp["result"] = a + b
+		case "greet":
+			var p struct{ Name string }
+			json.Unmarshal(req.Params[0], &p)
+			resp["result"] = map[string]string{"greeting": "hello, " + p.Name}
+		case "wrongType":
+			resp["result"] = "not a number"
+		case "exit":
+			os.Exit(1)
+		case "hang":
+			select {}
+		case "pid":
+			resp["result"] = os.Getpid()
+		default:
+			resp["error"] = map[string]any{"code": -32601, "message": "unknown method " + req.Method}
+		}
+		out.Encode(resp)
+	}
+}
+
+// TestProcess tests the process interpreter with testdata/plugin. The
+// #timeout value of a test sets the timeout of the interpreter. With the
+// #close tag, a test reports whether the program of the call of x, which
+// returns its process ID, is still running after closing the interpreter.
+func TestProcess(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("the plugin is a shell script")
+	}
+	test := cuetxtar.TxTarTest{
+		Root: "./testdata",
+		Name: "process",
+	}
+
+	test.Run(t, func(t *cuetxtar.Test) {
+		var options []process.Option
+		if s, ok := t.Value("timeout"); ok {
+			d, err := time.ParseDuration(s)
+			if err != nil {
+				t.Fatal(err)
+			}
+			options = append(options, process.Timeout(d))
+		}
+		interp := process.New(options...)
+		defer interp.(io.Closer).Close()
+		ctx := cuecontext.New(cuecontext.Interpreter(interp))
+		v := ctx.BuildInstance(t.Instance())
+
+		if t.HasTag("close") {
+			pid, err := v.LookupPath(cue.ParsePath("x")).Int64()
+			if err != nil {
+				t.Fatal(err)
+			}
+			proc, err := os.FindProcess(int(pid))
+			if err != nil {
+				t.Fatal(err)
+			}
+			fmt.Fprintln(t, "running:", proc.Signal(syscall.Signal(0)) == nil)
+			interp.(io.Closer).Close()
+			fmt.Fprintln(t, "running after close:", proc.Signal(syscall.Signal(0)) == nil)
+			return
+		}
+
+		var b strings.Builder
+		if err := v.Validate(); err != nil {
+			fmt.Fprintln(&b, "Errors:")
+			errors.Print(&b, err, &errors.Config{Cwd: t.Dir, ToSlash: true})
+			fmt.Fprintln(&b, "\nResult:")
+		}
+		syntax := v.Syntax(cue.Attributes(false), cue.Final(), cue.ErrorsAsValues(true))
+		file, err := astutil.ToFile(syntax.(ast.Expr))
+		if err != nil {
+			t.Fatal(err)
+		}
+		out, err := format.Node(file)
+		if err != nil {
+			t.Fatal(err)
+		}
+		b.Write(out)
+		// Messages may name programs by their absolute path.
+		fmt.Fprint(t, filepath.ToSlash(strings.ReplaceAll(b.String(), t.Dir, "$DIR")))
+	})
+}
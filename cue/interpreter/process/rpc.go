@@ -0,0 +1,189 @@
+// Copyright 2025 CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package process
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// A process is a program run as a subprocess, which is started on the
+// first call.
+type process struct {
+	file   string
+	interp *interpreter
+
+	// mu serializes calls, and guards the fields below.
+	mu     sync.Mutex
+	cmd    *exec.Cmd
+	stdin  io.WriteCloser
+	stdout *bufio.Reader
+	id     int64
+
+	// err is set if the process could not be started or failed,
+	// after which all calls fail with it.
+	err error
+}
+
+type request struct {
+	JSONRPC string            `json:"jsonrpc"`
+	ID      int64             `json:"id"`
+	Method  string            `json:"method"`
+	Params  []json.RawMessage `json:"params"`
+}
+
+type response struct {
+	ID     int64           `json:"id"`
+	Result json.RawMessage `json:"result"`
+	Error  *responseError  `json:"error"`
+}
+
+type responseError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+// call calls method with params, and returns its JSON result.
+func (p *process) call(method string, params []json.RawMessage) (json.RawMessage, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.err != nil {
+		return nil, p.err
+	}
+	if p.cmd == nil {
+		if err := p.start(); err != nil {
+			p.err = fmt.Errorf("cannot start %s: %w", p.file, err)
+			return nil, p.err
+		}
+	}
+
+	p.id++
+	req, err := json.Marshal(request{
+		JSONRPC: "2.0",
+		ID:      p.id,
+		Method:  method,
+		Params:  params,
+	})
+	if err != nil {
+		return nil, err
+	}
+	// Kill the process if it does not respond in time, which makes
+	// the write or read below fail.
+	var timedOut atomic.Bool
+	if timeout := p.interp.timeout; timeout > 0 {
+		proc := p.cmd.Process
+		t := time.AfterFunc(timeout, func() {
+			timedOut.Store(true)
+			proc.Kill()
+		})
+		defer t.Stop()
+	}
+	line, err := p.roundTrip(req)
+	if timedOut.Load() {
+		// The process is started again for the next call.
+		p.stop()
+		return nil, fmt.Errorf("%s: timed out after %v", method, p.interp.timeout)
+	}
+	if err != nil {
+		return nil, p.fail(err)
+	}
+	var resp response
+	if err := json.Unmarshal(line, &resp); err != nil {
+		return nil, p.fail(fmt.Errorf("invalid response: %v", err))
+	}
+	if resp.ID != p.id {
+		return nil, p.fail(fmt.Errorf("response has id %d; want %d", resp.ID, p.id))
+	}
+	if resp.Error != nil {
+		return nil, fmt.Errorf("%s: %s (code %d)", method, resp.Error.Message, resp.Error.Code)
+	}
+	if resp.Result == nil {
+		return nil, p.fail(fmt.Errorf("response has neither result nor error"))
+	}
+	return resp.Result, nil
+}
+
+// roundTrip sends the request req and returns the line of its response.
+func (p *process) roundTrip(req []byte) ([]byte, error) {
+	if _, err := p.stdin.Write(append(req, '\n')); err != nil {
+		return nil, err
+	}
+	line, err := p.stdout.ReadBytes('\n')
+	if err == io.EOF {
+		err = io.ErrUnexpectedEOF
+	}
+	return line, err
+}
+
+func (p *process) start() error {
+	cmd := exec.Command(p.file)
+	cmd.Dir = filepath.Dir(p.file)
+	cmd.Stderr = os.Stderr
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return err
+	}
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return err
+	}
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+	p.cmd = cmd
+	p.stdin = stdin
+	p.stdout = bufio.NewReader(stdout)
+	p.interp.mu.Lock()
+	p.interp.running[p] = true
+	p.interp.mu.Unlock()
+	return nil
+}
+
+// stop kills the process, if it is running, and waits for it to exit.
+// It returns the error with which the process exited. The process is
+// started again on the next call, unless it failed.
+func (p *process) stop() error {
+	if p.cmd == nil {
+		return nil
+	}
+	p.stdin.Close()
+	p.cmd.Process.Kill()
+	err := p.cmd.Wait()
+	p.cmd = nil
+	p.interp.mu.Lock()
+	delete(p.interp.running, p)
+	p.interp.mu.Unlock()
+	return err
+}
+
+// fail stops the process after it failed with err, as it may be out of
+// sync with the protocol, and returns the error to report for this and
+// any later calls.
+func (p *process) fail(err error) error {
+	if werr := p.stop(); werr != nil {
+		err = fmt.Errorf("%v (%v)", err, werr)
+	}
+	p.err = fmt.Errorf("%s failed: %w", p.file, err)
+	return p.err
+}
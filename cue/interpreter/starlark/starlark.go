@@ -0,0 +1,293 @@
+// Copyright 2025 CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package starlark allows users to implement functions for CUE in
+// [Starlark], a deterministic dialect of Python designed for
+// configuration.
+//
+// To enable Starlark support, pass the result of [New] to
+// [cuelang.org/go/cue/cuecontext.New]. In the command line tool, it is
+// enabled by the externstarlark experiment of CUE_EXPERIMENT.
+//
+// This package is EXPERIMENTAL and subject to change.
+//
+// # Using Starlark in CUE
+//
+// CUE files need to declare their intent by specifying a file-level
+// attribute:
+//
+//	@extern(starlark)
+//	package p
+//
+// Individual functions can then be imported from Starlark files using
+// a field attribute:
+//
+//	_merge: _ @extern("lib.star", sig="func(#Defaults, #Config): #Config")
+//	_slug:  _ @extern("lib.star", name=to_slug, sig="func(string): string")
+//
+// The first attribute argument is the path of the Starlark file,
+// relative to the directory of the CUE file which uses it. The
+// attribute takes the same arguments as that of the JavaScript
+// interpreter; see [cuelang.org/go/cue/interpreter/js].
+//
+// # Starlark files
+//
+// Each file is executed once, and must define the functions it provides
+// as global functions:
+//
+//	def to_slug(s):
+//	    return "-".join(s.lower().split())
+//
+// A function must accept as many positional arguments as its signature
+// declares, which is checked when the CUE file is compiled.
+//
+// Arguments are passed as if decoded with json.decode, so that structs
+// become dicts, and results are converted as if encoded with
+// json.encode. Errors, such as those reported with fail, are reported
+// as errors of the call.
+//
+// Starlark is deterministic and has no access to the file system, the
+// network, or the clock, so functions are free of side effects. Files
+// may use the json module, but the load statement is not supported.
+//
+// Executing a file and each call of a function fail with an error if
+// they take more computation steps than given by [MaxSteps], or run for
+// longer than given by [Timeout]; these default to [DefaultMaxSteps]
+// and [DefaultTimeout].
+//
+// [Starlark]: https://github.com/bazelbuild/starlark
+package starlark
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	starlarkjson "go.starlark.net/lib/json"
+	"go.starlark.net/starlark"
+	"go.starlark.net/syntax"
+
+	"cuelang.org/go/cue/build"
+	"cuelang.org/go/cue/cuecontext"
+	"cuelang.org/go/cue/errors"
+	"cuelang.org/go/cue/interpreter/internal/jsonfunc"
+	"cuelang.org/go/internal"
+	"cuelang.org/go/internal/core/adt"
+	"cuelang.org/go/internal/core/runtime"
+)
+
+const (
+	// DefaultMaxSteps is the number of computation steps which executing
+	// a Starlark file, and each call of a function, may take, unless
+	// another limit is given with [MaxSteps].
+	DefaultMaxSteps = 100_000_000
+
+	// DefaultTimeout is the time for which executing a Starlark file, and
+	// each call of a function, may run, unless another timeout is given
+	// with [Timeout].
+	DefaultTimeout = 10 * time.Second
+)
+
+// interpreter is a [cuecontext.ExternInterpreter] for Starlark.
+type interpreter struct {
+	limits limits
+}
+
+// limits holds the limits of the Starlark threads of an interpreter.
+type limits struct {
+	maxSteps uint64
+	timeout  time.Duration
+}
+
+// Option configures the interpreter returned by [New].
+type Option struct {
+	apply func(i *interpreter)
+}
+
+// MaxSteps returns an Option that limits the number of computation
+// steps which executing a Starlark file, and each call of a function,
+// may take, to n. An n of zero means no limit.
+func MaxSteps(n uint64) Option {
+	return Option{func(i *interpreter) {
+		i.limits.maxSteps = n
+	}}
+}
+
+// Timeout returns an Option that limits the time for which executing a
+// Starlark file, and each call of a function, may run, to d. A d of zero
+// or less means no limit.
+func Timeout(d time.Duration) Option {
+	return Option{func(i *interpreter) {
+		i.limits.timeout = d
+	}}
+}
+
+// New returns a new Starlark interpreter as a
+// [cuecontext.ExternInterpreter] suitable for passing to
+// [cuecontext.New].
+func New(options ...Option) cuecontext.ExternInterpreter {
+	i := &interpreter{limits: limits{
+		maxSteps: DefaultMaxSteps,
+		timeout:  DefaultTimeout,
+	}}
+	for _, o := range options {
+		o.apply(i)
+	}
+	return i
+}
+
+func (i *interpreter) Kind() string {
+	return "starlark"
+}
+
+// NewCompiler returns a Starlark compiler that services the specified
+// build.Instance.
+func (i *interpreter) NewCompiler(b *build.Instance, r *runtime.Runtime) (runtime.Compiler, errors.Error) {
+	return &compiler{
+		limits: i.limits,
+		files:  make(map[string]starlark.StringDict),
+	}, nil
+}
+
+// A compiler is a [runtime.Compiler] that provides functions
+// implemented in Starlark.
+type compiler struct {
+	limits limits
+
+	// mu serializes access to files.
+	mu sync.Mutex
+
+	// files maps absolute file names to their frozen globals.
+	files map[string]starlark.StringDict
+}
+
+// Compile returns the function described by the given @extern
+// attribute as an [adt.Builtin] with the given name.
+func (c *compiler) Compile(funcName string, scope adt.Value, a *internal.Attr) (adt.Expr, errors.Error) {
+	file, err := jsonfunc.File(a, "file")
+	if err != nil {
+		return nil, err
+	}
+	sig, serr := jsonfunc.ParseSig(a)
+	if serr != nil {
+		return nil, errors.Newf(a.Pos, "invalid function signature: %v", serr)
+	}
+	globals, serr := c.load(file)
+	if serr != nil {
+		return nil, errors.Newf(a.Pos, "can't load Starlark file: %v", serr)
+	}
+	fn, ok := globals[funcName].(starlark.Callable)
+	if !ok {
+		return nil, errors.Newf(a.Pos, "can't find function %q in Starlark file %s", funcName, file)
+	}
+	if f, ok := fn.(*starlark.Function); ok && !acceptsArgs(f, len(sig)-1) {
+		return nil, errors.Newf(a.Pos, "function %q in Starlark file %s does not accept the %d arguments of its signature", funcName, file, len(sig)-1)
+	}
+	return jsonfunc.Builtin(funcName, scope, sig, func(name string, args []json.RawMessage) (json.RawMessage, error) {
+		return c.limits.call(fn, args)
+	}), nil
+}
+
+// acceptsArgs reports whether f can be called with n positional
+// arguments and no keyword arguments.
+func acceptsArgs(f *starlark.Function, n int) bool {
+	// Parameters are ordered as positional, keyword-only, *args, and
+	// **kwargs.
+	positional := f.NumParams() - f.NumKwonlyParams()
+	if f.HasVarargs() {
+		positional--
+	}
+	if f.HasKwargs() {
+		positional--
+	}
+	for i := 0; i < positional+f.NumKwonlyParams(); i++ {
+		if f.ParamDefault(i) == nil && (i >= n || i >= positional) {
+			return false
+		}
+	}
+	return n <= positional || f.HasVarargs()
+}
+
+// load returns the globals of file, executing it if necessary.
+func (c *compiler) load(file string) (starlark.StringDict, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	globals, ok := c.files[file]
+	if !ok {
+		src, err := os.ReadFile(file)
+		if err != nil {
+			return nil, err
+		}
+		thread, stop := c.limits.thread(file)
+		globals, err = starlark.ExecFileOptions(&syntax.FileOptions{}, thread, file, src, predeclared)
+		stop()
+		if err != nil {
+			return nil, err
+		}
+		// Frozen values may be used by several threads at once.
+		globals.Freeze()
+		c.files[file] = globals
+	}
+	return globals, nil
+}
+
+var predeclared = starlark.StringDict{
+	"json": starlarkjson.Module,
+}
+
+var (
+	decode = starlarkjson.Module.Members["decode"]
+	encode = starlarkjson.Module.Members["encode"]
+)
+
+// thread returns a new thread with the given name and limits l, and a
+// function which must be called once the thread is no longer used.
+func (l limits) thread(name string) (thread *starlark.Thread, stop func()) {
+	thread = &starlark.Thread{Name: name}
+	if l.maxSteps > 0 {
+		thread.SetMaxExecutionSteps(l.maxSteps)
+	}
+	if l.timeout <= 0 {
+		return thread, func() {}
+	}
+	t := time.AfterFunc(l.timeout, func() {
+		thread.Cancel(fmt.Sprintf("timed out after %v", l.timeout))
+	})
+	return thread, func() { t.Stop() }
+}
+
+// call calls fn with args in a new thread, and returns its result.
+func (l limits) call(fn starlark.Callable, args []json.RawMessage) (json.RawMessage, error) {
+	thread, stop := l.thread(fn.Name())
+	defer stop()
+	slArgs := make(starlark.Tuple, len(args))
+	for i, arg := range args {
+		v, err := starlark.Call(thread, decode, starlark.Tuple{starlark.String(arg)}, nil)
+		if err != nil {
+			return nil, err
+		}
+		slArgs[i] = v
+	}
+	res, err := starlark.Call(thread, fn, slArgs, nil)
+	if err != nil {
+		return nil, err
+	}
+	str, err := starlark.Call(thread, encode, starlark.Tuple{res}, nil)
+	if err != nil {
+		return nil, fmt.Errorf("invalid result of %s: %v", fn.Name(), err)
+	}
+	return json.RawMessage(str.(starlark.String)), nil
+}
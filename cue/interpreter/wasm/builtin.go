@@ -47,7 +47,7 @@ import (
 // problem.
 func generateCallThatReturnsBuiltin(name string, scope adt.Value, args []string, i *instance) (adt.Expr, error) {
 	// ensure that the function exists before trying to call it.
-	_, err := i.load(name)
+	fn, err := i.load(name)
 	if err != nil {
 		return nil, err
 	}
@@ -58,14 +58,18 @@ func generateCallThatReturnsBuiltin(name string, scope adt.Value, args []string,
 		Func: func(call *adt.CallContext) adt.Expr {
 			opctx := call.OpContext()
 
-			scope := value.Make(opctx, scope)
-			sig := compileStringsInScope(args, scope)
+			sig := compileStringsInScope(args, value.Make(opctx, scope))
+			// Check the signature before the function is called, as
+			// mismatched types would corrupt its arguments.
+			if err := checkSig(fn.Definition(), sig); err != nil {
+				return opctx.NewErrf("invalid function signature: %v", err)
+			}
 			args, result := splitLast(sig)
 			b := &pkg.Builtin{
 				Name:   name,
 				Params: params(args),
 				Result: result.Kind(),
-				Func:   cABIFunc(i, name, sig),
+				Func:   cABIFunc(i, name, scope, sig),
 			}
 			return pkg.ToBuiltin(b)
 		},
@@ -16,8 +16,10 @@ package wasm
 
 import (
 	"fmt"
+	"strings"
 
 	"cuelang.org/go/cue"
+	"cuelang.org/go/internal/core/adt"
 	"cuelang.org/go/internal/pkg"
 	"github.com/tetratelabs/wazero/api"
 )
@@ -126,8 +128,9 @@ func encBytes(i *instance, b []byte) *memory {
 // the specified sig type, will be called by the runtime after its
 // arguments will be converted according to the ABI. The result of the
 // call will be then also be converted back into a Go value and handed
-// to the runtime.
-func cABIFunc(i *instance, name string, sig []cue.Value) func(*pkg.CallCtxt) {
+// to the runtime. Host functions called by the function look up values
+// in scope.
+func cABIFunc(i *instance, name string, scope adt.Value, sig []cue.Value) func(*pkg.CallCtxt) {
 	// Compute the layout of all encountered structs (arguments
 	// and result) such that we will have it available at the time
 	// of an actual call.
@@ -147,7 +150,17 @@ func cABIFunc(i *instance, name string, sig []cue.Value) func(*pkg.CallCtxt) {
 	}
 
 	fn, _ := i.load(name)
+	hasStructs := retLayout != nil
+	for _, l := range argLayouts {
+		hasStructs = hasStructs || l != nil
+	}
 	return func(c *pkg.CallCtxt) {
+		if hasStructs {
+			if err := i.canAlloc(); err != nil {
+				c.Err = err
+				return
+			}
+		}
 		argsTyp, resTyp := splitLast(sig)
 		args := make([]uint64, 0, len(argsTyp))
 		for k, typ := range argsTyp {
@@ -175,7 +188,12 @@ func cABIFunc(i *instance, name string, sig []cue.Value) func(*pkg.CallCtxt) {
 		}
 
 		if c.Do() {
-			res, err := fn.Call(i.ctx, args...)
+			h := &host{
+				ctx:    c.OpContext(),
+				scope:  adt.ToVertex(scope),
+				active: i.active,
+			}
+			res, err := fn.Call(withHost(i.ctx, h), args...)
 			if err != nil {
 				c.Err = err
 				return
@@ -193,3 +211,103 @@ func cABIFunc(i *instance, name string, sig []cue.Value) func(*pkg.CallCtxt) {
 		}
 	}
 }
+
+// checkSig checks that the types of the arguments and result in sig
+// are supported by the ABI, and that the Wasm function fn takes and
+// returns the Wasm values that they are passed as.
+func checkSig(fn api.FunctionDefinition, sig []cue.Value) error {
+	argsTyp, resTyp := splitLast(sig)
+	var params, results []api.ValueType
+	for k, typ := range argsTyp {
+		t, err := valueType(typ)
+		if err != nil {
+			return fmt.Errorf("argument %d: %v", k, err)
+		}
+		params = append(params, t)
+	}
+	t, err := valueType(resTyp)
+	if err != nil {
+		return fmt.Errorf("result: %v", err)
+	}
+	if resTyp.IncompleteKind() == cue.StructKind {
+		// Struct results are written to memory pointed to by an
+		// additional, final argument.
+		params = append(params, t)
+	} else {
+		results = append(results, t)
+	}
+
+	want := funcType(params, results)
+	if got := funcType(fn.ParamTypes(), fn.ResultTypes()); got != want {
+		return fmt.Errorf("function %q has type %s, but its signature requires %s", fn.Name(), got, want)
+	}
+	return nil
+}
+
+// valueType returns the type of the Wasm value with which a value of
+// the CUE type typ is passed, or an error if the ABI does not support
+// typ.
+func valueType(typ cue.Value) (api.ValueType, error) {
+	switch typ.IncompleteKind() {
+	case cue.BoolKind:
+		return api.ValueTypeI32, nil
+	case cue.IntKind, cue.FloatKind, cue.NumberKind:
+		switch typNum(typ) {
+		case typInt8, typUint8, typInt16, typUint16, typInt32, typUint32:
+			return api.ValueTypeI32, nil
+		case typInt64, typUint64:
+			return api.ValueTypeI64, nil
+		case typFloat32:
+			return api.ValueTypeF32, nil
+		case typFloat64:
+			return api.ValueTypeF64, nil
+		}
+		return 0, fmt.Errorf("%v is not a sized number type, such as int32 or float64", typ)
+	case cue.StructKind:
+		if err := checkStruct(typ); err != nil {
+			return 0, err
+		}
+		// Structs are passed as pointers.
+		return api.ValueTypeI32, nil
+	case cue.ListKind:
+		return 0, fmt.Errorf("lists are not supported, use a struct instead")
+	}
+	return 0, fmt.Errorf("unsupported type %v", typ)
+}
+
+// checkStruct checks that the fields of the struct type typ can be laid
+// out in memory.
+func checkStruct(typ cue.Value) error {
+	for i, _ := typ.Fields(); i.Next(); {
+		f := i.Value()
+		switch f.IncompleteKind() {
+		case cue.StructKind:
+			if err := checkStruct(f); err != nil {
+				return fmt.Errorf("field %v: %v", i.Selector(), err)
+			}
+		case cue.BoolKind:
+		case cue.IntKind, cue.FloatKind, cue.NumberKind:
+			if typNum(f) == typErr {
+				return fmt.Errorf("field %v: %v is not a sized number type, such as int32 or float64", i.Selector(), f)
+			}
+		default:
+			return fmt.Errorf("field %v: unsupported type %v", i.Selector(), f)
+		}
+	}
+	return nil
+}
+
+// funcType returns the Wasm text format of a function type.
+func funcType(params, results []api.ValueType) string {
+	var b strings.Builder
+	b.WriteString("(param")
+	for _, t := range params {
+		b.WriteString(" " + api.ValueTypeName(t))
+	}
+	b.WriteString(") (result")
+	for _, t := range results {
+		b.WriteString(" " + api.ValueTypeName(t))
+	}
+	b.WriteString(")")
+	return b.String()
+}
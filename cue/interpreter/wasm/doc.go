@@ -78,6 +78,20 @@
 //
 // Wasm code must always terminate and return a result.
 //
+// Modules may import functions from [WASI] preview 1, as produced by
+// standard toolchains targeting WASI. To keep functions free of side
+// effects, they run without arguments, environment variables, or file
+// system access, anything written to standard output or standard error
+// is discarded, and the clocks and random number source are fake and
+// deterministic. A module built as a WASI reactor (library) is
+// initialized by calling its exported _initialize function before any
+// other function is called. A module built as a WASI command cannot be
+// used, as it exits after running its main function.
+//
+// The [component model] is not supported: modules must be core Wasm
+// modules, and functions are called using the ABI described below, not
+// through WIT interfaces.
+//
 // Failure to provide the above guarantees will break the internal
 // logic of CUE and will cause the CUE evaluation to be undefined.
 //
@@ -89,34 +103,87 @@
 // # ABI requirements for Wasm modules
 //
 // Currently only the [System V ABI] (also known as the C ABI) is
-// supported. Furthermore, only scalar data types and structs containing
+// supported, selected with abi=c. This ABI is stable: modules built
+// against it will continue to work with future versions of CUE, and
+// other ABIs will be added under different names. Furthermore, only scalar data types and structs containing
 // either scalar types or other structs can be exchanged between CUE
 // and Wasm. Scalar means booleans, sized integers, and sized floats.
 // The sig field in the attribute refers to these data types by their
 // CUE names, such as bool, uint16, float64.
 //
-// Additionally the Wasm module must export two functions with the
-// following C type signature:
+// Arguments and results of scalar types are passed directly as Wasm
+// values. Struct arguments are passed as a pointer to a copy of the
+// struct in guest memory, laid out as C would lay it out, and struct
+// results are returned by writing them to memory pointed to by an
+// additional, final pointer argument.
+//
+// Before a function is first called, its signature is checked against
+// the types of the Wasm function, and the types of struct fields are
+// checked to be supported, so that a mismatch is reported as an error
+// rather than corrupting the arguments. For example, a signature of
+// func(int64, #Point): float64 requires a Wasm function of type
+// (param i64 i32) (result f64).
+//
+// To pass structs, the Wasm module must additionally export two
+// functions with the following C type signature:
 //
 //	void*	allocate(int n);
 //	void	deallocate(void *ptr, int n);
 //
 // Allocate returns a Wasm pointer to a buffer of size n. Deallocate
 // takes a Wasm pointer and the size of the buffer it points to and
-// frees it.
+// frees it. If a module does not export allocate, the C functions
+// malloc and free are used instead if they are exported, as they are
+// by TinyGo.
+//
+// # Looking up values in CUE
+//
+// Functions may look up values elsewhere in the package from which
+// they are called, which allows, for example, resolving references by
+// name without passing every possible target as an argument. To do so,
+// a module imports the following host function from the module "cue":
+//
+//	int32_t lookup(char *path, int32_t path_len, char *buf, int32_t buf_len);
+//
+// Lookup evaluates the CUE path of path_len bytes at path, such as
+// "a.b[0]" or "#Def.x", relative to the package. If the value is
+// concrete, its JSON encoding is written to buf if it fits in buf_len
+// bytes, and its size is returned in any case, so that a function may
+// call lookup again with a larger buffer. Otherwise lookup returns a
+// negative result:
+//
+//	-1	the path is invalid, or refers to a field which does not exist
+//	-2	the value is not concrete, or is an error
+//	-3	the value is already being looked up, so that it depends on itself
+//	-4	lookup was called outside a call from CUE, such as during initialization
+//
+// Lookups are read-only: a function cannot change the configuration.
+// Hidden fields cannot be looked up. The result of a function must
+// still depend only on its arguments and the values it looks up.
+//
+// In Rust, the host function can be imported as follows:
+//
+//	#[link(wasm_import_module = "cue")]
+//	extern "C" {
+//	    fn lookup(path: *const u8, path_len: i32, buf: *mut u8, buf_len: i32) -> i32;
+//	}
+//
+// In TinyGo, it can be imported with the //go:wasmimport directive:
+//
+//	//go:wasmimport cue lookup
+//	func lookup(path *byte, pathLen int32, buf *byte, bufLen int32) int32
 //
 // # How to compile Rust for use in CUE
 //
 // To compile Rust code into a Wasm module usable by CUE, make sure
-// you have either the wasm32-unknown-unknown or wasm32-wasi targets
+// you have either the wasm32-unknown-unknown or wasm32-wasip1 targets
 // installed:
 //
-//	rustup target add wasm32-wasi
+//	rustup target add wasm32-wasip1
 //
-// Note that even with wasm32-wasi, you should assume a [no_std]
-// environment. Even though CUE can load [WASI] modules, the loaded
-// modules do not currently have access to a WASI environment. This
-// might change in the future.
+// With wasm32-wasip1, the standard library may be used, subject to
+// the restrictions on the WASI environment described above. With
+// wasm32-unknown-unknown, you should assume a [no_std] environment.
 //
 // Compile your Rust crate using a cdynlib crate type as your [cargo target]
 // targeting the installed Wasm target and make sure the functions you
@@ -152,8 +219,27 @@
 //	    let _ = Vec::from_raw_parts(ptr, 0, size);
 //	}
 //
+// # How to compile Go for use in CUE
+//
+// Go code can be compiled into a Wasm module usable by CUE with
+// [TinyGo], exporting functions with the //export directive:
+//
+//	//export add
+//	func add(a, b int64) int64 {
+//		return a + b
+//	}
+//
+// The module must be built as a WASI reactor:
+//
+//	tinygo build -target=wasip1 -buildmode=c-shared -o foo.wasm .
+//
+// TinyGo exports malloc and free, so no allocation functions need to
+// be written.
+//
 // [System V ABI]: https://github.com/WebAssembly/tool-conventions/blob/main/BasicCABI.md
 // [no_std]: https://docs.rust-embedded.org/book/intro/no-std.html
 // [WASI]: https://wasi.dev
+// [component model]: https://component-model.bytecodealliance.org
+// [TinyGo]: https://tinygo.org
 // [cargo target]: https://doc.rust-lang.org/cargo/reference/cargo-targets.html
 package wasm
@@ -0,0 +1,179 @@
+// Copyright 2025 CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package wasm
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/tetratelabs/wazero"
+	"github.com/tetratelabs/wazero/api"
+
+	"cuelang.org/go/cue"
+	"cuelang.org/go/internal/core/adt"
+	"cuelang.org/go/internal/value"
+)
+
+// hostModule is the name of the module from which Wasm modules
+// import the host functions provided by CUE.
+const hostModule = "cue"
+
+// Results of the lookup host function, other than the size of a
+// value.
+const (
+	lookupNotFound    = -1
+	lookupIncomplete  = -2
+	lookupCycle       = -3
+	lookupUnavailable = -4
+)
+
+// instantiateHost instantiates the host module in r.
+func instantiateHost(ctx context.Context, r wazero.Runtime) {
+	i32 := api.ValueTypeI32
+	_, err := r.NewHostModuleBuilder(hostModule).
+		NewFunctionBuilder().
+		WithGoModuleFunction(api.GoModuleFunc(hostLookup), []api.ValueType{i32, i32, i32, i32}, []api.ValueType{i32}).
+		WithParameterNames("path", "path_len", "buf", "buf_len").
+		Export("lookup").
+		Instantiate(ctx)
+	if err != nil {
+		panic(err)
+	}
+}
+
+type hostKey struct{}
+
+// A host gives the host functions called by a Wasm function access to
+// the configuration from which the function was called.
+type host struct {
+	ctx   *adt.OpContext
+	scope *adt.Vertex
+
+	// active holds the paths being looked up.
+	active *activeLookups
+}
+
+// activeLookups holds the paths being looked up by all Wasm functions
+// of a package, so that lookups that depend on themselves are detected.
+type activeLookups struct {
+	mu    sync.Mutex
+	paths map[string]bool
+}
+
+// add adds path to the set, and reports whether it was absent.
+func (a *activeLookups) add(path string) bool {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.paths[path] {
+		return false
+	}
+	if a.paths == nil {
+		a.paths = make(map[string]bool)
+	}
+	a.paths[path] = true
+	return true
+}
+
+func (a *activeLookups) remove(path string) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	delete(a.paths, path)
+}
+
+// withHost returns a copy of ctx which makes h available to host
+// functions.
+func withHost(ctx context.Context, h *host) context.Context {
+	return context.WithValue(ctx, hostKey{}, h)
+}
+
+// hostLookup implements the lookup host function. It looks up the
+// CUE path held in guest memory at path, and writes the JSON encoding of
+// its value to buf if it fits in buf_len bytes. It returns the size of
+// the encoding, or one of the negative lookup results.
+func hostLookup(ctx context.Context, m api.Module, stack []uint64) {
+	path, pathLen := api.DecodeU32(stack[0]), api.DecodeU32(stack[1])
+	buf, bufLen := api.DecodeU32(stack[2]), api.DecodeU32(stack[3])
+
+	b, ok := m.Memory().Read(path, pathLen)
+	if !ok {
+		panic(fmt.Sprintf("can't read %d bytes from Wasm address %#x", pathLen, path))
+	}
+	h, _ := ctx.Value(hostKey{}).(*host)
+	if h == nil {
+		stack[0] = api.EncodeI32(lookupUnavailable)
+		return
+	}
+	data, res := h.lookup(string(b))
+	if res < 0 || uint32(len(data)) > bufLen {
+		stack[0] = api.EncodeI32(res)
+		return
+	}
+	if !m.Memory().Write(buf, data) {
+		panic(fmt.Sprintf("can't write %d bytes to Wasm address %#x", len(data), buf))
+	}
+	stack[0] = api.EncodeI32(res)
+}
+
+// lookup returns the JSON encoding of the value at path, relative to
+// the package, and its size, or nil and one of the negative lookup
+// results.
+func (h *host) lookup(path string) ([]byte, int32) {
+	p := cue.ParsePath(path)
+	sels := p.Selectors()
+	if p.Err() != nil || len(sels) == 0 {
+		return nil, lookupNotFound
+	}
+	var f adt.Feature
+	switch sel := sels[0]; sel.LabelType() {
+	case cue.StringLabel:
+		f = adt.MakeStringLabel(h.ctx, sel.Unquoted())
+	case cue.DefinitionLabel:
+		f = adt.MakeIdentLabel(h.ctx, sel.String(), "")
+	default:
+		// Hidden fields are private to the package, and a package
+		// cannot be indexed.
+		return nil, lookupNotFound
+	}
+
+	if !h.active.add(p.String()) {
+		return nil, lookupCycle
+	}
+	defer h.active.remove(p.String())
+
+	// Resolve the first selector as a reference in the package, so that
+	// only the fields needed are evaluated, rather than the whole package,
+	// which may still be being evaluated.
+	n := &adt.Vertex{}
+	env := &adt.Environment{Vertex: h.scope}
+	n.AddConjunct(adt.MakeRootConjunct(env, &adt.FieldReference{Label: f}))
+	n.Finalize(h.ctx)
+	if h.scope.Lookup(f) == nil {
+		return nil, lookupNotFound
+	}
+
+	v := value.Make(h.ctx, n).LookupPath(cue.MakePath(sels[1:]...))
+	if !v.Exists() {
+		return nil, lookupNotFound
+	}
+	if err := v.Validate(cue.Concrete(true)); err != nil {
+		return nil, lookupIncomplete
+	}
+	data, err := v.MarshalJSON()
+	if err != nil {
+		return nil, lookupIncomplete
+	}
+	return data, int32(len(data))
+}
@@ -286,6 +286,8 @@ func typVal(v cue.Value) typ {
 	}
 }
 
+// typNum returns the sized number type that t fits in, or typErr if
+// there is none.
 func typNum(t cue.Value) typ {
 	ctx := t.Context()
 
@@ -339,5 +341,5 @@ func typNum(t cue.Value) typ {
 		return typFloat64
 	}
 
-	panic("unreachable")
+	return typErr
 }
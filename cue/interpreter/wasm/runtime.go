@@ -33,6 +33,10 @@ type runtime struct {
 	// functions, but it's unused otherwise.
 	ctx context.Context
 
+	// active holds the paths being looked up by the Wasm functions
+	// run by the runtime.
+	active *activeLookups
+
 	wazero.Runtime
 }
 
@@ -40,9 +44,11 @@ func newRuntime() runtime {
 	ctx := context.Background()
 	r := wazero.NewRuntime(ctx)
 	wasi_snapshot_preview1.MustInstantiate(ctx, r)
+	instantiateHost(ctx, r)
 
 	return runtime{
 		ctx:     ctx,
+		active:  &activeLookups{},
 		Runtime: r,
 	}
 }
@@ -55,6 +61,9 @@ func (r *runtime) compile(name string) (*module, error) {
 		return nil, fmt.Errorf("can't compile Wasm module: %w", err)
 	}
 
+	if isComponent(buf) {
+		return nil, fmt.Errorf("can't compile Wasm module: %s is a Wasm component, but only core Wasm modules are supported", name)
+	}
 	mod, err := r.Runtime.CompileModule(r.ctx, buf)
 	if err != nil {
 		return nil, fmt.Errorf("can't compile Wasm module: %w", err)
@@ -66,6 +75,14 @@ func (r *runtime) compile(name string) (*module, error) {
 	}, nil
 }
 
+// isComponent reports whether buf holds a binary Wasm component, as
+// defined by the component model, rather than a core Wasm module. Both
+// start with the same magic number, but components have a different
+// layer field following the version.
+func isComponent(buf []byte) bool {
+	return len(buf) >= 8 && string(buf[:4]) == "\x00asm" && buf[6] == 1 && buf[7] == 0
+}
+
 // compileAndLoad is a convenience method that compiles a module then
 // loads it into memory returning the loaded instance, or an error.
 func (r *runtime) compileAndLoad(name string) (*instance, error) {
@@ -90,12 +107,25 @@ type module struct {
 // load loads the compiled module into memory, returning a new instance
 // that can be called into, or an error. Different instances of the
 // same module do not share memory.
+//
+// Modules may import WASI functions. They run without arguments,
+// environment variables, or file system access, their standard output
+// and error are discarded, and the clocks and random source are the
+// deterministic ones provided by Wazero by default, so that functions
+// remain free of side effects. A module built as a WASI reactor is
+// initialized by calling its _initialize function.
 func (m *module) load() (*instance, error) {
-	cfg := wazero.NewModuleConfig().WithName(m.name)
+	cfg := wazero.NewModuleConfig().
+		WithName(m.name).
+		WithStartFunctions("_initialize", "_start")
 	wInst, err := m.Runtime.InstantiateModule(m.ctx, m.CompiledModule, cfg)
 	if err != nil {
 		return nil, fmt.Errorf("can't instantiate Wasm module: %w", err)
 	}
+	if wInst.IsClosed() {
+		// A WASI command exits after running its main function.
+		return nil, fmt.Errorf("can't instantiate Wasm module: %s exited during initialization; build it as a library (reactor) rather than a command", m.name)
+	}
 
 	inst := instance{
 		module:   m,
@@ -103,6 +133,11 @@ func (m *module) load() (*instance, error) {
 		alloc:    wInst.ExportedFunction("allocate"),
 		free:     wInst.ExportedFunction("deallocate"),
 	}
+	if inst.alloc == nil {
+		// Toolchains such as TinyGo export the C allocator instead.
+		inst.alloc = wInst.ExportedFunction("malloc")
+		inst.free = wInst.ExportedFunction("free")
+	}
 	return &inst, nil
 }
 
@@ -115,7 +150,7 @@ type instance struct {
 	instance api.Module
 
 	// alloc is a guest function that allocates guest memory on
-	// behalf of the host.
+	// behalf of the host. It is nil if the module exports no allocator.
 	alloc api.Function
 
 	// free is a guest function that frees guest memory on
@@ -123,6 +158,15 @@ type instance struct {
 	free api.Function
 }
 
+// canAlloc reports whether the host can allocate guest memory,
+// as required to pass structs.
+func (i *instance) canAlloc() error {
+	if i.alloc == nil || i.free == nil {
+		return fmt.Errorf("can't pass structs: Wasm module %v must export allocate and deallocate, or malloc and free", i.module.Name())
+	}
+	return nil
+}
+
 // load attempts to load the named function from the instance, returning
 // it if found, or an error.
 func (i *instance) load(funcName string) (api.Function, error) {
@@ -158,7 +202,7 @@ func (i *instance) Free(m *memory) {
 	i.mu.Lock()
 	defer i.mu.Unlock()
 
-	i.free.Call(i.ctx, uint64(m.ptr), uint64(m.len))
+	i.callFree(m)
 }
 
 // Free frees several previously allocated guest memories.
@@ -167,10 +211,18 @@ func (i *instance) FreeAll(ms []*memory) {
 	defer i.mu.Unlock()
 
 	for _, m := range ms {
-		i.free.Call(i.ctx, uint64(m.ptr), uint64(m.len))
+		i.callFree(m)
 	}
 }
 
+// callFree calls the guest free function for m. Unlike deallocate,
+// the C free function only takes a pointer.
+func (i *instance) callFree(m *memory) {
+	args := m.Args()
+	args = args[:len(i.free.Definition().ParamTypes())]
+	i.free.Call(i.ctx, args...)
+}
+
 // memory is a read and write reference to guest memory that the host
 // requested.
 type memory struct {
@@ -0,0 +1,257 @@
+// Copyright 2025 CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package wasm
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/go-quicktest/qt"
+	"github.com/tetratelabs/wazero/api"
+
+	"cuelang.org/go/cue/cuecontext"
+	"cuelang.org/go/internal/value"
+)
+
+// The modules below are assembled by hand, so that the tests do not
+// depend on a WASI toolchain.
+
+// reactorModule is a WASI reactor, as produced by TinyGo with
+// -buildmode=c-shared, equivalent to:
+//
+//	(module
+//	  (import "wasi_snapshot_preview1" "args_sizes_get"
+//	    (func $args_sizes_get (param i32 i32) (result i32)))
+//	  (memory (export "memory") 1)
+//	  (global $base (mut i64) (i64.const 0))
+//	  (func (export "_initialize")
+//	    (drop (call $args_sizes_get (i32.const 0) (i32.const 4)))
+//	    (global.set $base (i64.const 40)))
+//	  (func (export "add") (param i64 i64) (result i64)
+//	    (i64.add (i64.add (local.get 0) (local.get 1)) (global.get $base)))
+//	  (func (export "malloc") (param i32) (result i32)
+//	    (i32.const 1024))
+//	  (func (export "free") (param i32)))
+var reactorModule = wasmModule(
+	wasmSection(1, wasmVec( // types
+		[]byte{0x60, 2, 0x7f, 0x7f, 1, 0x7f}, // (i32, i32) -> i32
+		[]byte{0x60, 0, 0},                   // () -> ()
+		[]byte{0x60, 2, 0x7e, 0x7e, 1, 0x7e}, // (i64, i64) -> i64
+		[]byte{0x60, 1, 0x7f, 1, 0x7f},       // (i32) -> i32
+		[]byte{0x60, 1, 0x7f, 0},             // (i32) -> ()
+	)),
+	wasmSection(2, wasmVec( // imports
+		wasmImport("wasi_snapshot_preview1", "args_sizes_get", 0),
+	)),
+	wasmSection(3, wasmVec([]byte{1}, []byte{2}, []byte{3}, []byte{4})), // functions
+	wasmSection(5, wasmVec([]byte{0, 1})),                               // memory
+	wasmSection(6, wasmVec([]byte{0x7e, 1, 0x42, 0, 0x0b})),             // globals
+	wasmSection(7, wasmVec( // exports
+		wasmExport("memory", 2, 0),
+		wasmExport("_initialize", 0, 1),
+		wasmExport("add", 0, 2),
+		wasmExport("malloc", 0, 3),
+		wasmExport("free", 0, 4),
+	)),
+	wasmSection(10, wasmVec( // code
+		wasmCode(0x41, 0, 0x41, 4, 0x10, 0, 0x1a, 0x42, 40, 0x24, 0),
+		wasmCode(0x20, 0, 0x20, 1, 0x7c, 0x23, 0, 0x7c),
+		wasmCode(0x41, 0x80, 0x08),
+		wasmCode(),
+	)),
+)
+
+// commandModule is a WASI command, equivalent to:
+//
+//	(module
+//	  (import "wasi_snapshot_preview1" "proc_exit"
+//	    (func $proc_exit (param i32)))
+//	  (func (export "_start")
+//	    (call $proc_exit (i32.const 0))))
+var commandModule = wasmModule(
+	wasmSection(1, wasmVec(
+		[]byte{0x60, 1, 0x7f, 0}, // (i32) -> ()
+		[]byte{0x60, 0, 0},       // () -> ()
+	)),
+	wasmSection(2, wasmVec(wasmImport("wasi_snapshot_preview1", "proc_exit", 0))),
+	wasmSection(3, wasmVec([]byte{1})),
+	wasmSection(7, wasmVec(wasmExport("_start", 0, 1))),
+	wasmSection(10, wasmVec(wasmCode(0x41, 0, 0x10, 0))),
+)
+
+// lookupModule calls the lookup host function with a buffer of 16
+// bytes at address 1024, equivalent to:
+//
+//	(module
+//	  (import "cue" "lookup"
+//	    (func $lookup (param i32 i32 i32 i32) (result i32)))
+//	  (memory (export "memory") 1)
+//	  (func (export "lookup") (param i32 i32) (result i32)
+//	    (call $lookup (local.get 0) (local.get 1) (i32.const 1024) (i32.const 16))))
+var lookupModule = wasmModule(
+	wasmSection(1, wasmVec(
+		[]byte{0x60, 4, 0x7f, 0x7f, 0x7f, 0x7f, 1, 0x7f}, // (i32, i32, i32, i32) -> i32
+		[]byte{0x60, 2, 0x7f, 0x7f, 1, 0x7f},             // (i32, i32) -> i32
+	)),
+	wasmSection(2, wasmVec(wasmImport("cue", "lookup", 0))),
+	wasmSection(3, wasmVec([]byte{1})),
+	wasmSection(5, wasmVec([]byte{0, 1})),
+	wasmSection(7, wasmVec(
+		wasmExport("memory", 2, 0),
+		wasmExport("lookup", 0, 1),
+	)),
+	wasmSection(10, wasmVec(
+		wasmCode(0x20, 0, 0x20, 1, 0x41, 0x80, 0x08, 0x41, 16, 0x10, 0),
+	)),
+)
+
+// componentModule is the header of an empty Wasm component.
+var componentModule = []byte("\x00asm\x0d\x00\x01\x00")
+
+func TestReactor(t *testing.T) {
+	r := newRuntime()
+	inst, err := r.compileAndLoad(writeModule(t, "reactor.wasm", reactorModule))
+	qt.Assert(t, qt.IsNil(err))
+
+	add, err := inst.load("add")
+	qt.Assert(t, qt.IsNil(err))
+	res, err := add.Call(r.ctx, 1, 1)
+	qt.Assert(t, qt.IsNil(err))
+	qt.Assert(t, qt.DeepEquals(res, []uint64{42}))
+
+	qt.Assert(t, qt.IsNil(inst.canAlloc()))
+	m, err := inst.Alloc(8)
+	qt.Assert(t, qt.IsNil(err))
+	qt.Assert(t, qt.Equals(m.ptr, 1024))
+	inst.Free(m)
+}
+
+func TestCommand(t *testing.T) {
+	r := newRuntime()
+	_, err := r.compileAndLoad(writeModule(t, "command.wasm", commandModule))
+	qt.Assert(t, qt.ErrorMatches(err, `can't instantiate Wasm module: .*command.wasm exited during initialization; build it as a library \(reactor\) rather than a command`))
+}
+
+func TestComponent(t *testing.T) {
+	r := newRuntime()
+	_, err := r.compileAndLoad(writeModule(t, "component.wasm", componentModule))
+	qt.Assert(t, qt.ErrorMatches(err, `can't compile Wasm module: .*component.wasm is a Wasm component, but only core Wasm modules are supported`))
+}
+
+func TestLookup(t *testing.T) {
+	r := newRuntime()
+	inst, err := r.compileAndLoad(writeModule(t, "lookup.wasm", lookupModule))
+	qt.Assert(t, qt.IsNil(err))
+	fn, err := inst.load("lookup")
+	qt.Assert(t, qt.IsNil(err))
+
+	v := cuecontext.New().CompileString(`
+port: 8080
+name: "web"
+list: [1, 2]
+#Def: a: 1
+_hidden: 1
+incomplete: int
+long: "more than sixteen bytes"
+`)
+	h := &host{
+		ctx:    value.OpContext(v),
+		scope:  value.Vertex(v),
+		active: r.active,
+	}
+	lookup := func(ctx context.Context, path string) (int32, string) {
+		mem := inst.instance.Memory()
+		qt.Assert(t, qt.IsTrue(mem.Write(0, []byte(path))))
+		qt.Assert(t, qt.IsTrue(mem.Write(1024, make([]byte, 16))))
+		res, err := fn.Call(ctx, 0, uint64(len(path)))
+		qt.Assert(t, qt.IsNil(err))
+		buf, _ := mem.Read(1024, 16)
+		return api.DecodeI32(res[0]), string(bytes.TrimRight(buf, "\x00"))
+	}
+
+	testCases := []struct {
+		path string
+		res  int32
+		data string
+	}{
+		{"port", 4, "8080"},
+		{"name", 5, `"web"`},
+		{"list[1]", 1, "2"},
+		{"list", 5, "[1,2]"},
+		{"#Def.a", 1, "1"},
+		{"missing", lookupNotFound, ""},
+		{"list[5]", lookupNotFound, ""},
+		{"_hidden", lookupNotFound, ""},
+		{"incomplete", lookupIncomplete, ""},
+		{"long", 25, ""}, // too long for the buffer
+	}
+	ctx := withHost(r.ctx, h)
+	for _, tc := range testCases {
+		res, data := lookup(ctx, tc.path)
+		qt.Check(t, qt.Equals(res, tc.res), qt.Commentf("path %s", tc.path))
+		qt.Check(t, qt.Equals(data, tc.data), qt.Commentf("path %s", tc.path))
+	}
+
+	// A lookup of a path that is already being looked up is a cycle.
+	qt.Assert(t, qt.IsTrue(r.active.add("port")))
+	res, _ := lookup(ctx, "port")
+	qt.Check(t, qt.Equals(res, int32(lookupCycle)))
+	r.active.remove("port")
+
+	// Lookups are only available during calls from CUE.
+	res, _ = lookup(r.ctx, "port")
+	qt.Check(t, qt.Equals(res, int32(lookupUnavailable)))
+}
+
+func writeModule(t *testing.T, name string, buf []byte) string {
+	name = filepath.Join(t.TempDir(), name)
+	qt.Assert(t, qt.IsNil(os.WriteFile(name, buf, 0o666)))
+	return name
+}
+
+func wasmModule(sections ...[]byte) []byte {
+	return append([]byte("\x00asm\x01\x00\x00\x00"), bytes.Join(sections, nil)...)
+}
+
+// wasmSection, wasmVec, and wasmName assume that all sizes fit in a
+// single LEB128 byte, which holds for the small modules above.
+
+func wasmSection(id byte, contents []byte) []byte {
+	return append([]byte{id, byte(len(contents))}, contents...)
+}
+
+func wasmVec(items ...[]byte) []byte {
+	return append([]byte{byte(len(items))}, bytes.Join(items, nil)...)
+}
+
+func wasmName(s string) []byte {
+	return append([]byte{byte(len(s))}, s...)
+}
+
+func wasmImport(module, name string, typ byte) []byte {
+	return bytes.Join([][]byte{wasmName(module), wasmName(name), {0, typ}}, nil)
+}
+
+func wasmExport(name string, kind, index byte) []byte {
+	return append(wasmName(name), kind, index)
+}
+
+func wasmCode(body ...byte) []byte {
+	body = append(append([]byte{0}, body...), 0x0b) // no locals
+	return append([]byte{byte(len(body))}, body...)
+}
@@ -26,6 +26,7 @@ import (
 	"cuelang.org/go/cue/errors"
 	"cuelang.org/go/cue/token"
 	"cuelang.org/go/internal"
+	"cuelang.org/go/internal/filetypes"
 	"cuelang.org/go/mod/modconfig"
 	"cuelang.org/go/mod/modfile"
 	"cuelang.org/go/mod/module"
@@ -149,6 +150,10 @@ type Config struct {
 	// equal to Module.
 	modFile *modfile.File
 
+	// typeMap holds the file types configured by the filetypes field of
+	// the module file.
+	typeMap *filetypes.TypeMap
+
 	// Package defines the name of the package to be loaded. If this is not set,
 	// the package must be uniquely defined from its context. Special values:
 	//    _    load files without a package
@@ -211,6 +216,12 @@ type Config struct {
 	//
 	// Valid values for type are "int", "number", "bool", and "string".
 	//
+	// The type may also be given with the value, as in an entry of the form
+	// "key:type=value", which overrides the type of the @tag attribute. For
+	// instance, "key:int=2" modifies any field tagged with key to
+	//
+	//    field: x & 2
+	//
 	// A @tag attribute can also define shorthand values, which can be injected
 	// into the fields without having to specify the key. For instance, for
 	//
@@ -277,6 +288,10 @@ type Config struct {
 	// the corresponding build.File will be associated with the full buffer.
 	Stdin io.Reader
 
+	// StdinType, if not empty, is the file type qualifier, such as "yaml",
+	// used for the file "-" when it is given without one.
+	StdinType string
+
 	// Registry is used to fetch CUE module dependencies.
 	//
 	// When nil, [modconfig.NewRegistry] will be used to create a
@@ -385,6 +400,11 @@ func (c Config) complete() (cfg *Config, err error) {
 	if err := c.loadModule(); err != nil {
 		return nil, err
 	}
+	if c.StdinType != "" {
+		if c.typeMap, err = c.typeMap.WithStdin(c.StdinType); err != nil {
+			return nil, err
+		}
+	}
 	return &c, nil
 }
 
@@ -429,6 +449,10 @@ func (c *Config) loadModule() error {
 		return err
 	}
 	c.modFile = mf
+	c.typeMap, err = filetypes.NewTypeMap(mf.Filetypes)
+	if err != nil {
+		return errors.Wrapf(err, token.NoPos, "invalid filetypes field in %s", modFile)
+	}
 	if mf.QualifiedModule() == "" {
 		// Backward compatibility: allow empty module.cue file.
 		// TODO maybe check that the rest of the fields are empty too?
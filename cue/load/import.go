@@ -156,7 +156,7 @@ func (l *loader) importPkg(pos token.Pos, p *build.Instance) []*build.Instance {
 				return retErr(errors.Wrapf(err, token.NoPos, "import failed reading dir %v", dir))
 			}
 			for _, name := range sd.filenames {
-				file, err := filetypes.ParseFileAndType(name, "", filetypes.Input)
+				file, err := l.cfg.typeMap.ParseFileAndType(name, "", filetypes.Input)
 				if err != nil {
 					p.UnknownFiles = append(p.UnknownFiles, &build.File{
 						Filename:      name,
@@ -59,10 +59,6 @@ func Instances(args []string, c *Config) []*build.Instance {
 	}
 	pkgArgs := args[:i]
 	otherArgs := args[i:]
-	otherFiles, err := filetypes.ParseArgs(otherArgs)
-	if err != nil {
-		return []*build.Instance{c.newErrInstance(err)}
-	}
 	ctx := context.TODO()
 	if c == nil {
 		c = &Config{}
@@ -72,6 +68,10 @@ func Instances(args []string, c *Config) []*build.Instance {
 		return []*build.Instance{c.newErrInstance(err)}
 	}
 	c = newC
+	otherFiles, err := c.typeMap.ParseArgs(otherArgs)
+	if err != nil {
+		return []*build.Instance{c.newErrInstance(err)}
+	}
 	for _, f := range otherFiles {
 		if err := setFileSource(c, f); err != nil {
 			return []*build.Instance{c.newErrInstance(err)}
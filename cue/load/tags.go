@@ -187,15 +187,7 @@ func parseTag(pos token.Pos, body string) (t *tag, err errors.Error) {
 	}
 
 	if s, ok, _ := a.Lookup(1, "type"); ok {
-		switch s {
-		case "string":
-		case "int":
-			t.kind = cue.IntKind
-		case "number":
-			t.kind = cue.NumberKind
-		case "bool":
-			t.kind = cue.BoolKind
-		default:
+		if t.kind, ok = tagKind(s); !ok {
 			return t, errors.Newf(pos, "invalid type %q", s)
 		}
 	}
@@ -216,8 +208,25 @@ func parseTag(pos token.Pos, body string) (t *tag, err errors.Error) {
 	return t, nil
 }
 
-func (t *tag) inject(value string, tg *tagger) errors.Error {
-	e, err := cli.ParseValue(token.NoPos, t.key, value, t.kind)
+// tagKind returns the kind of values of the given tag type.
+func tagKind(typ string) (cue.Kind, bool) {
+	switch typ {
+	case "string":
+		return cue.StringKind, true
+	case "int":
+		return cue.IntKind, true
+	case "number":
+		return cue.NumberKind, true
+	case "bool":
+		return cue.BoolKind, true
+	}
+	return 0, false
+}
+
+// inject injects value, interpreted as a value of the given kind, into the
+// field of t.
+func (t *tag) inject(value string, kind cue.Kind, tg *tagger) errors.Error {
+	e, err := cli.ParseValue(token.NoPos, t.key, value, kind)
 	t.injectValue(e, tg)
 	return err
 }
@@ -294,10 +303,23 @@ func (tg *tagger) injectTags(tags []string) errors.Error {
 		name, val, ok := strings.Cut(s, "=")
 		found := tg.usedTags[s]
 		if ok { // key-value
+			// The type of the value may be given as key:type=value,
+			// overriding the type of the tag.
+			name, typ, typed := strings.Cut(name, ":")
+			var kind cue.Kind
+			if typed {
+				if kind, typed = tagKind(typ); !typed {
+					return errors.Newf(token.NoPos, "invalid type %q for tag %q", typ, name)
+				}
+			}
 			for _, t := range tg.tags {
 				if t.key == name {
 					found = true
-					if err := t.inject(val, tg); err != nil {
+					k := t.kind
+					if kind != 0 {
+						k = kind
+					}
+					if err := t.inject(val, k, tg); err != nil {
 						return err
 					}
 				}
@@ -310,7 +332,7 @@ func (tg *tagger) injectTags(tags []string) errors.Error {
 				for _, sh := range t.shorthands {
 					if sh == s {
 						found = true
-						if err := t.inject(s, tg); err != nil {
+						if err := t.inject(s, t.kind, tg); err != nil {
 							return err
 						}
 					}
@@ -44,9 +44,10 @@ func TestTags(t *testing.T) {
 	dir := t.TempDir()
 
 	testCases := []struct {
-		in  string
-		out string
-		err string
+		in   string
+		tags []string
+		out  string
+		err  string
 	}{{
 		in: `
 		rand: int    @tag(foo,var=rand)
@@ -84,6 +85,26 @@ func TestTags(t *testing.T) {
 		u1: string @tag(bar,var=user)
 		`,
 		err: `tag variable 'user' not found`,
+	}, {
+		// The type of a value given on the command line overrides the
+		// type of the tag.
+		in: `
+		replicas: int  @tag(replicas)
+		debug:    bool @tag(debug)
+		name:     string @tag(name,type=int)
+		`,
+		tags: []string{"replicas:int=3", "debug:bool=true", "name:string=3"},
+		out: `{
+			replicas: 3
+			debug:    true
+			name:     "3"
+		}`,
+	}, {
+		in: `
+		replicas: int @tag(replicas)
+		`,
+		tags: []string{"replicas:float=3"},
+		err:  `invalid type "float" for tag "replicas"`,
 	}}
 
 	for _, tc := range testCases {
@@ -93,6 +114,7 @@ func TestTags(t *testing.T) {
 				Overlay: map[string]Source{
 					filepath.Join(dir, "foo.cue"): FromString(tc.in),
 				},
+				Tags:    tc.tags,
 				TagVars: testTagVars,
 			}
 			b := Instances([]string{"foo.cue"}, cfg)[0]
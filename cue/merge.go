@@ -0,0 +1,93 @@
+// Copyright 2025 The CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cue
+
+import (
+	"cuelang.org/go/cue/errors"
+	"cuelang.org/go/cue/token"
+	"cuelang.org/go/internal/core/adt"
+)
+
+// A Conflict describes a path at which the values merged by [MergeAll]
+// cannot be unified.
+type Conflict struct {
+	// Path is the path of the conflicting value within the merged value.
+	Path Path
+
+	// Err describes the conflict.
+	Err errors.Error
+
+	// Positions holds the positions of the values contributing to the
+	// conflict.
+	Positions []token.Pos
+}
+
+// MergeAll unifies vals, in order, and returns the result along with all
+// conflicts within it. Unlike [Value.Unify] followed by [Value.Validate],
+// which can be used to fail on the first error, it reports every path at
+// which the values conflict, so that all problems of a layered
+// configuration can be shown at once.
+//
+// Conflicts are reported in the order of the fields of the result,
+// including definitions, optional fields, and hidden fields. A conflict
+// at a path hides any conflicts below it. Values which are merely
+// incomplete are not considered to be conflicts.
+//
+// MergeAll returns the zero Value if vals is empty.
+func MergeAll(vals ...Value) (Value, []Conflict) {
+	if len(vals) == 0 {
+		return Value{}, nil
+	}
+	v := vals[0]
+	for _, w := range vals[1:] {
+		v = v.Unify(w)
+	}
+	var conflicts []Conflict
+	v.conflicts(&conflicts)
+	return v, conflicts
+}
+
+// conflicts adds the conflicts within v to list.
+func (v Value) conflicts(list *[]Conflict) {
+	ctx := v.ctx()
+	switch b := v.checkKind(ctx, adt.BottomKind); {
+	case b == nil, b.ChildError:
+		// The conflicts are reported at the paths of the children.
+	case b.Code == adt.IncompleteError:
+		return
+	default:
+		err := v.toErr(b)
+		*list = append(*list, Conflict{
+			Path:      v.Path(),
+			Err:       err,
+			Positions: errors.Positions(err),
+		})
+		return
+	}
+	var iter *Iterator
+	if v.v.IsList() {
+		i := v.mustList(ctx)
+		iter = &i
+	} else {
+		var err error
+		iter, err = v.Fields(Definitions(true), Optional(true), Hidden(true))
+		if err != nil {
+			return
+		}
+	}
+	for iter.Next() {
+		iter.Value().conflicts(list)
+	}
+}
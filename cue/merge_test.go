@@ -0,0 +1,91 @@
+// Copyright 2025 The CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cue_test
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/go-quicktest/qt"
+
+	"cuelang.org/go/cue"
+	"cuelang.org/go/cue/cuecontext"
+)
+
+func TestMergeAll(t *testing.T) {
+	tests := []struct {
+		name string
+		srcs []string
+		want []string // formatted as "path: message (positions)"
+	}{{
+		name: "None",
+		srcs: []string{`a: int, b: {c: string}`, `a: 1`, `b: c: "x"`},
+	}, {
+		name: "Empty",
+	}, {
+		name: "Conflicts",
+		srcs: []string{
+			`a: 1, b: {c: "x", d: true}, l: [1, 2]`,
+			`a: 2, b: {c: "y"}`,
+			`b: d: false, l: [1, 3]`,
+		},
+		want: []string{
+			"a: conflicting values 2 and 1 (base.cue:1:4 layer1.cue:1:4)",
+			"b.c: conflicting values \"y\" and \"x\" (base.cue:1:14 layer1.cue:1:14)",
+			"b.d: conflicting values false and true (base.cue:1:22 layer2.cue:1:7)",
+			"l[1]: conflicting values 3 and 2 (base.cue:1:36 layer2.cue:1:21)",
+		},
+	}, {
+		name: "Closed",
+		srcs: []string{
+			`#S: {a?: int}, s: #S, t: #S`,
+			`s: {a: 1, b: 2}`,
+			`t: a: "x"`,
+		},
+		want: []string{
+			"s.b: field not allowed (layer1.cue:1:11)",
+			`t.a: conflicting values "x" and int (mismatched types string and int) (base.cue:1:10 layer2.cue:1:7)`,
+		},
+	}, {
+		name: "Incomplete",
+		srcs: []string{`a: int, b: a + 1`, `a: <10`},
+	}}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ctx := cuecontext.New()
+			var vals []cue.Value
+			for i, src := range test.srcs {
+				name := "base.cue"
+				if i > 0 {
+					name = fmt.Sprintf("layer%d.cue", i)
+				}
+				vals = append(vals, ctx.CompileString(src, cue.Filename(name)))
+			}
+			_, conflicts := cue.MergeAll(vals...)
+			var got []string
+			for _, c := range conflicts {
+				var pos []string
+				for _, p := range c.Positions {
+					pos = append(pos, p.String())
+				}
+				format, args := c.Err.Msg()
+				got = append(got, fmt.Sprintf("%v: %s (%s)",
+					c.Path, fmt.Sprintf(format, args...), strings.Join(pos, " ")))
+			}
+			qt.Assert(t, qt.DeepEquals(got, test.want))
+		})
+	}
+}
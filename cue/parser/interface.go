@@ -181,7 +181,7 @@ func ParseFile(filename string, src interface{}, mode ...Option) (f *ast.File, e
 			}
 		}
 
-		err = errors.Sanitize(pp.errors)
+		err = errors.WithCode(errors.Sanitize(pp.errors), errors.SyntaxError)
 	}()
 
 	// parse source
@@ -233,11 +233,14 @@ func ParseExpr(filename string, src interface{}, mode ...Option) (ast.Expr, erro
 	}
 
 	if p.errors != nil {
-		return nil, p.errors
+		return nil, errors.WithCode(p.errors, errors.SyntaxError)
 	}
 	astutil.ResolveExpr(e, p.errf)
 
-	return e, p.errors
+	if p.errors != nil {
+		return e, errors.WithCode(p.errors, errors.SyntaxError)
+	}
+	return e, nil
 }
 
 // parseExprString is a convenience function for obtaining the AST of an
@@ -66,6 +66,15 @@ type Counts struct {
 	CloseIDElems int64
 	NumCloseIDs  int64
 
+	// String counters
+	//
+	// String literals of the same value, such as the values of keys which
+	// are repeated across many objects, share their memory. The following
+	// counters track the savings.
+
+	InternedStrings int64 // Number of string literals sharing an earlier copy.
+	InternedBytes   int64 // Number of bytes saved by sharing string literals.
+
 	// Buffer counters
 	//
 	// Each unification and disjunct operation is associated with an object
@@ -106,6 +115,9 @@ func (c *Counts) Add(other Counts) {
 	c.CloseIDElems += other.CloseIDElems
 	c.NumCloseIDs += other.NumCloseIDs
 
+	c.InternedStrings += other.InternedStrings
+	c.InternedBytes += other.InternedBytes
+
 	c.Freed += other.Freed
 	c.Retained += other.Retained
 	c.Reused += other.Reused
@@ -118,6 +130,8 @@ func (c Counts) Since(start Counts) Counts {
 	c.Disjuncts -= start.Disjuncts
 	c.CloseIDElems -= start.CloseIDElems
 	c.NumCloseIDs -= start.NumCloseIDs
+	c.InternedStrings -= start.InternedStrings
+	c.InternedBytes -= start.InternedBytes
 
 	c.Freed -= start.Freed
 	c.Retained -= start.Retained
@@ -150,7 +164,10 @@ Conjuncts:    {{.Conjuncts}}
 Disjuncts:    {{.Disjuncts}}{{if .NumCloseIDs}}
 
 CloseIDElems: {{.CloseIDElems}}
-NumCloseIDs: {{.NumCloseIDs}}{{end}}`))
+NumCloseIDs: {{.NumCloseIDs}}{{end}}{{if .InternedStrings}}
+
+InternedStrings: {{.InternedStrings}}
+InternedBytes:   {{.InternedBytes}}{{end}}`))
 })
 
 func (s Counts) String() string {
@@ -235,6 +235,47 @@ if true {
 		path:    "#person.children",
 		options: o(cue.Schema(), cue.Raw()),
 		out:     `[...#person]`,
+	}, {
+		name: "defaults up to depth",
+		in: `
+		a: *1 | int
+		b: {
+			c: *"x" | string
+			d: e: *true | bool
+		}
+		l: [*2 | int]
+		`,
+		options: o(cue.Defaults(1)),
+		out: `
+{
+	a: 1
+	b: {
+		c: *"x" | string
+		d: {
+			e: *true | bool
+		}
+	}
+	l: [*2 | int]
+}`,
+	}, {
+		name: "omit optional fields",
+		in: `
+		#A: {
+			a!: int
+			b?: string
+			c: {d?: int, e: int}
+		}
+		`,
+		options: o(cue.Optional(false)),
+		out: `
+{
+	#A: {
+		a!: int
+		c: {
+			e: int
+		}
+	}
+}`,
 	}}
 	for _, tc := range testCases {
 		t.Run(tc.name, func(t *testing.T) {
@@ -835,14 +835,22 @@ func (v Value) Syntax(opts ...Option) ast.Node {
 		Simplify:        !o.raw,
 		TakeDefaults:    o.final,
 		ShowOptional:    !o.omitOptional && !o.concrete,
+		OmitOptional:    o.omitOptional,
 		ShowDefinitions: !o.omitDefinitions && !o.concrete,
 		ShowHidden:      !o.omitHidden && !o.concrete,
 		ShowAttributes:  !o.omitAttrs,
 		ShowDocs:        o.docs,
 		ShowErrors:      o.showErrors,
 		InlineImports:   o.inlineImports,
+		InlineImport:    o.inlineImport,
 		Fragment:        o.raw,
 	}
+	if o.defaults {
+		p.TakeDefaults = true
+		if o.defaultsDepth >= 0 {
+			p.DefaultsDepth = o.defaultsDepth + 1
+		}
+	}
 
 	pkgID := v.instance().ID()
 
@@ -872,7 +880,7 @@ You could file a bug with the above information at:
 	// var expr ast.Expr
 	var err error
 	var f *ast.File
-	if o.concrete || o.final || o.resolveReferences {
+	if o.concrete || o.final || o.resolveReferences || o.defaults {
 		f, err = p.Vertex(v.idx, pkgID, v.v)
 		if err != nil {
 			return bad(`"cuelang.org/go/internal/core/export".Vertex`, err)
@@ -1915,9 +1923,12 @@ type options struct {
 	omitOptional      bool
 	omitAttrs         bool
 	inlineImports     bool
+	inlineImport      func(importPath string) bool
 	resolveReferences bool
 	showErrors        bool
 	final             bool
+	defaults          bool
+	defaultsDepth     int
 	ignoreClosedness  bool // used for comparing APIs
 	docs              bool
 	disallowCycles    bool // implied by concrete
@@ -1969,6 +1980,29 @@ func InlineImports(expand bool) Option {
 	return func(p *options) { p.inlineImports = expand }
 }
 
+// InlineImportsFunc is like [InlineImports], but only inlines references
+// to the imported packages for which inline reports true, given their
+// import path. References to other packages are kept as references to the
+// import.
+func InlineImportsFunc(inline func(importPath string) bool) Option {
+	return func(p *options) { p.inlineImport = inline }
+}
+
+// Defaults causes [Value.Syntax] to select the defaults of values up to
+// the given depth of fields and list elements below the value: a depth of
+// 0 only selects the default of the value itself, 1 also those of its
+// fields, and so on. Deeper values are output with all their disjuncts.
+// A negative depth selects all defaults, as [Final] does, but without
+// omitting definitions, optional fields, or hidden fields.
+//
+// As with [Final], references are resolved.
+func Defaults(depth int) Option {
+	return func(p *options) {
+		p.defaults = true
+		p.defaultsDepth = depth
+	}
+}
+
 // DisallowCycles forces validation in the presence of cycles, even if
 // non-concrete values are allowed. This is implied by [Concrete].
 func DisallowCycles(disallow bool) Option {
@@ -2053,7 +2087,8 @@ func Hidden(include bool) Option {
 	}
 }
 
-// Optional indicates that optional fields should be included.
+// Optional indicates that optional fields should be included. Note that
+// omitting the optional fields of a closed struct changes its meaning.
 func Optional(include bool) Option {
 	return func(p *options) { p.omitOptional = !include }
 }
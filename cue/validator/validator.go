@@ -0,0 +1,329 @@
+// Copyright 2025 CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package validator validates large numbers of documents against a CUE
+// definition, possibly from many goroutines at once.
+//
+// A [cue.Context] and the values created in it may not be used
+// concurrently, and validating a document by unifying it with a schema
+// value adds to the context in which the schema was built. A [Validator]
+// instead keeps a pool of contexts, each holding its own evaluated copy
+// of the definition, and validates each document in a context which is
+// not in use by any other goroutine. The definition is thus only
+// compiled and evaluated once for each context, rather than once for
+// each document.
+//
+// Building a document in a context adds to that context as well, so each
+// context is only used for a limited number of documents, after which it
+// is dropped along with its copy of the definition.
+//
+// Validators also cache whether the definition allows the fields of the
+// documents they validated. This is only done for the parts of the
+// definition that consist of plain fields, so that whether a field is
+// allowed does not depend on the document. A document with a field that
+// is known not to be allowed is rejected without evaluating it.
+package validator
+
+import (
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"cuelang.org/go/cue"
+	"cuelang.org/go/cue/ast"
+	"cuelang.org/go/cue/build"
+	"cuelang.org/go/cue/cuecontext"
+	"cuelang.org/go/cue/errors"
+	"cuelang.org/go/cue/token"
+	"cuelang.org/go/encoding/json"
+	"cuelang.org/go/internal/core/adt"
+	"cuelang.org/go/internal/value"
+)
+
+const (
+	// maxUses is the number of documents validated in a context before it
+	// is dropped.
+	maxUses = 1000
+
+	// maxCached is the maximum number of fields for which a Validator
+	// caches whether they are allowed.
+	maxCached = 10000
+)
+
+// A Validator validates documents against a definition. It is safe for
+// concurrent use by multiple goroutines.
+type Validator struct {
+	path  cue.Path
+	build func(ctx *cue.Context) cue.Value
+
+	// mu serializes building the definition, as building an instance
+	// updates its syntax trees.
+	mu   sync.Mutex
+	pool sync.Pool
+
+	// allowed maps a fieldKey to a closedness, for fields of documents
+	// validated so far. numAllowed counts its entries.
+	allowed    sync.Map
+	numAllowed atomic.Int64
+}
+
+// A worker holds a context, along with the definition evaluated in it.
+// It is only used by one goroutine at a time.
+type worker struct {
+	ctx    *cue.Context
+	schema cue.Value
+	uses   int
+}
+
+// A fieldKey identifies a field of a document by the labels of its
+// enclosing structs, joined by newlines, and its own label.
+type fieldKey struct {
+	parent string
+	label  string
+}
+
+// A closedness records whether the definition allows a field.
+type closedness int8
+
+const (
+	// unknown means the definition is not plain enough to tell without
+	// evaluating the document.
+	unknown closedness = iota
+	allowed
+	notAllowed
+)
+
+// Compile returns a Validator for the definition at path p in the
+// package of inst.
+func Compile(inst *build.Instance, p cue.Path) (*Validator, error) {
+	return newValidator(p, func(ctx *cue.Context) cue.Value {
+		return ctx.BuildInstance(inst)
+	})
+}
+
+// CompileString returns a Validator for the definition at path p in the
+// CUE source src.
+func CompileString(src string, p cue.Path) (*Validator, error) {
+	return newValidator(p, func(ctx *cue.Context) cue.Value {
+		return ctx.CompileString(src)
+	})
+}
+
+func newValidator(p cue.Path, build func(ctx *cue.Context) cue.Value) (*Validator, error) {
+	v := &Validator{path: p, build: build}
+	w, err := v.newWorker()
+	if err != nil {
+		return nil, err
+	}
+	v.pool.Put(w)
+	return v, nil
+}
+
+func (v *Validator) newWorker() (*worker, error) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	ctx := cuecontext.New()
+	schema := v.build(ctx)
+	if err := schema.Err(); err != nil {
+		return nil, err
+	}
+	schema = schema.LookupPath(v.path)
+	if !schema.Exists() {
+		return nil, errors.Newf(token.NoPos, "definition %v not found", v.path)
+	}
+	if err := schema.Err(); err != nil {
+		return nil, err
+	}
+	return &worker{ctx: ctx, schema: schema}, nil
+}
+
+func (v *Validator) get() (*worker, error) {
+	if w, ok := v.pool.Get().(*worker); ok {
+		return w, nil
+	}
+	return v.newWorker()
+}
+
+func (v *Validator) put(w *worker) {
+	if w.uses++; w.uses < maxUses {
+		v.pool.Put(w)
+	}
+}
+
+// Validate reports whether the JSON-encoded data is a concrete instance
+// of the definition.
+func (v *Validator) Validate(data []byte) error {
+	x, err := json.Extract("validator.Validate", data)
+	if err != nil {
+		return errors.Newf(token.NoPos, "validator: invalid JSON")
+	}
+	return v.validate(x)
+}
+
+// ValidateValue reports whether x is a concrete instance of the
+// definition. As x may not be used by any other goroutine while it is
+// validated, it is converted to syntax and rebuilt in the context used
+// for validation, so only its concrete data is considered.
+func (v *Validator) ValidateValue(x cue.Value) error {
+	if err := x.Err(); err != nil {
+		return err
+	}
+	switch n := x.Syntax(cue.Final(), cue.Concrete(true)).(type) {
+	case *ast.File:
+		for _, d := range n.Decls {
+			switch d.(type) {
+			case *ast.Package, *ast.ImportDecl:
+				return errors.Newf(token.NoPos, "validator: value refers to packages")
+			}
+		}
+		return v.validate(&ast.StructLit{Elts: n.Decls})
+	case ast.Expr:
+		return v.validate(n)
+	}
+	return errors.Newf(token.NoPos, "validator: cannot convert value to syntax")
+}
+
+// validate validates the document x. It builds x as an expression, rather
+// than as a file, so that it is not recorded as an instance of the
+// context.
+func (v *Validator) validate(x ast.Expr) error {
+	w, err := v.get()
+	if err != nil {
+		return err
+	}
+	defer v.put(w)
+	if err := v.checkFields(w, nil, x); err != nil {
+		return err
+	}
+	doc := w.ctx.BuildExpr(x)
+	if err := doc.Err(); err != nil {
+		return err
+	}
+	return w.schema.Unify(doc).Validate(cue.Concrete(true))
+}
+
+// checkFields reports the fields of the struct x at the path parent of
+// the document that the definition is known not to allow.
+func (v *Validator) checkFields(w *worker, parent []string, x ast.Expr) errors.Error {
+	s, ok := x.(*ast.StructLit)
+	if !ok {
+		return nil
+	}
+	var errs errors.Error
+	for _, d := range s.Elts {
+		f, ok := d.(*ast.Field)
+		if !ok {
+			continue
+		}
+		name, isIdent, err := ast.LabelName(f.Label)
+		if err != nil || isIdent && (strings.HasPrefix(name, "#") || strings.HasPrefix(name, "_")) {
+			continue
+		}
+		switch v.closedness(w, parent, name) {
+		case notAllowed:
+			errs = errors.Append(errs, &notAllowedError{
+				path: append(append(v.selectors(), parent...), name),
+				pos:  f.Label.Pos(),
+			})
+		case allowed:
+			errs = errors.Append(errs, v.checkFields(w, append(parent[:len(parent):len(parent)], name), f.Value))
+		}
+	}
+	return errs
+}
+
+func (v *Validator) selectors() []string {
+	var a []string
+	for _, sel := range v.path.Selectors() {
+		a = append(a, sel.String())
+	}
+	return a
+}
+
+// closedness reports whether the definition allows the field name at the
+// path parent, using the cache if possible.
+func (v *Validator) closedness(w *worker, parent []string, name string) closedness {
+	key := fieldKey{strings.Join(parent, "\n"), name}
+	if c, ok := v.allowed.Load(key); ok {
+		return c.(closedness)
+	}
+	c := w.closedness(parent, name)
+	if v.numAllowed.Load() < maxCached {
+		if _, loaded := v.allowed.LoadOrStore(key, c); !loaded {
+			v.numAllowed.Add(1)
+		}
+	}
+	return c
+}
+
+func (w *worker) closedness(parent []string, name string) closedness {
+	s := w.schema
+	for _, label := range parent {
+		if !isPlain(s) {
+			return unknown
+		}
+		s = s.LookupPath(cue.MakePath(cue.Str(label)))
+		if !s.Exists() {
+			return unknown
+		}
+	}
+	if !isPlain(s) || s.IncompleteKind() != cue.StructKind {
+		return unknown
+	}
+	if s.Allows(cue.Str(name)) {
+		return allowed
+	}
+	return notAllowed
+}
+
+// isPlain reports whether s is only defined by struct literals holding
+// fields, so that its fields do not depend on the values it is unified
+// with.
+func isPlain(s cue.Value) bool {
+	plain := true
+	value.Vertex(s).VisitLeafConjuncts(func(c adt.Conjunct) bool {
+		x, ok := c.Elem().(*adt.StructLit)
+		if !ok {
+			plain = false
+			return false
+		}
+		for _, d := range x.Decls {
+			switch d.(type) {
+			case *adt.Field, *adt.LetField, *adt.Ellipsis:
+			default:
+				plain = false
+				return false
+			}
+		}
+		return true
+	})
+	return plain
+}
+
+// A notAllowedError reports a field of a document that the definition
+// does not allow, in the same way as the evaluator.
+type notAllowedError struct {
+	path []string
+	pos  token.Pos
+}
+
+func (e *notAllowedError) Position() token.Pos         { return e.pos }
+func (e *notAllowedError) InputPositions() []token.Pos { return nil }
+func (e *notAllowedError) Path() []string              { return e.path }
+func (e *notAllowedError) Error() string               { return errors.String(e) }
+
+func (e *notAllowedError) Msg() (format string, args []interface{}) {
+	return "field not allowed", nil
+}
@@ -0,0 +1,222 @@
+// Copyright 2025 CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package validator_test
+
+import (
+	"fmt"
+	"runtime"
+	"sync"
+	"testing"
+
+	"github.com/go-quicktest/qt"
+	"golang.org/x/tools/txtar"
+
+	"cuelang.org/go/cue"
+	"cuelang.org/go/cue/cuecontext"
+	"cuelang.org/go/cue/validator"
+	"cuelang.org/go/internal/cuetxtar"
+)
+
+const schema = `
+import "strings"
+
+#Event: {
+	id!:   int & >0
+	kind!: "create" | "delete"
+	tags?: [...strings.MinRunes(1)]
+}
+`
+
+func TestValidate(t *testing.T) {
+	v, err := validator.CompileString(schema, cue.ParsePath("#Event"))
+	qt.Assert(t, qt.IsNil(err))
+
+	tests := []struct {
+		data string
+		err  string
+	}{{
+		data: `{"id": 1, "kind": "create", "tags": ["a"]}`,
+	}, {
+		data: `{"id": 0, "kind": "create"}`,
+		err:  `#Event.id: invalid value 0 (out of bound >0)`,
+	}, {
+		data: `{"id": 1, "kind": "update"}`,
+		err:  `kind: 2 errors in empty disjunction`,
+	}, {
+		data: `{"id": 1, "kind": "create", "extra": true}`,
+		err:  `extra: field not allowed`,
+	}, {
+		data: `{"kind": "create"}`,
+		err:  `id: field is required but not present`,
+	}, {
+		data: `{"id": 1,`,
+		err:  `validator: invalid JSON`,
+	}}
+	for _, test := range tests {
+		t.Run(test.data, func(t *testing.T) {
+			err := v.Validate([]byte(test.data))
+			if test.err == "" {
+				qt.Assert(t, qt.IsNil(err))
+				return
+			}
+			qt.Assert(t, qt.IsNotNil(err))
+			qt.Assert(t, qt.StringContains(err.Error(), test.err))
+
+			// Values from another context are validated in the same way.
+			x := cuecontext.New().CompileString(test.data)
+			if x.Err() == nil {
+				err := v.ValidateValue(x)
+				qt.Assert(t, qt.IsNotNil(err))
+				qt.Assert(t, qt.StringContains(err.Error(), test.err))
+			}
+		})
+	}
+}
+
+func TestValidateClosed(t *testing.T) {
+	const schema = `
+#Plain: {
+	a?: {b?: int}
+	c?: [...{d?: int}]
+}
+#Cond: {
+	kind: *"y" | string
+	if kind == "x" {
+		extra?: int
+	}
+}
+`
+	tests := []struct {
+		path string
+		data string
+		err  string
+	}{{
+		path: "#Plain",
+		data: `{"a": {"b": 1}}`,
+	}, {
+		path: "#Plain",
+		data: `{"a": {"b": 1, "e": 1}}`,
+		err:  `#Plain.a.e: field not allowed`,
+	}, {
+		path: "#Plain",
+		data: `{"c": [{"d": 1, "e": 1}]}`,
+		err:  `#Plain.c.0.e: field not allowed`,
+	}, {
+		path: "#Cond",
+		data: `{"kind": "x", "extra": 1}`,
+	}, {
+		path: "#Cond",
+		data: `{"kind": "y", "extra": 1}`,
+		err:  `#Cond.extra: field not allowed`,
+	}}
+	for _, test := range tests {
+		t.Run(test.data, func(t *testing.T) {
+			v, err := validator.CompileString(schema, cue.ParsePath(test.path))
+			qt.Assert(t, qt.IsNil(err))
+			// The second validation uses the cached closedness.
+			for range 2 {
+				err := v.Validate([]byte(test.data))
+				if test.err == "" {
+					qt.Assert(t, qt.IsNil(err))
+				} else {
+					qt.Assert(t, qt.ErrorMatches(err, test.err))
+				}
+			}
+		})
+	}
+}
+
+func TestValidateConcurrent(t *testing.T) {
+	a := txtar.Parse([]byte(`
+-- cue.mod/module.cue --
+module: "mod.test/schema"
+language: version: "v0.12.0"
+-- schema.cue --
+package schema
+
+#Event: {
+	id!:   int & >0
+	kind!: "create" | "delete"
+}
+`))
+	inst := cuetxtar.Load(a, t.TempDir())[0]
+	v, err := validator.Compile(inst, cue.ParsePath("#Event"))
+	qt.Assert(t, qt.IsNil(err))
+
+	var wg sync.WaitGroup
+	errs := make([]error, 200)
+	for i := range errs {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			errs[i] = v.Validate(fmt.Appendf(nil, `{"id": %d, "kind": "create"}`, i))
+		}()
+	}
+	wg.Wait()
+	for i, err := range errs {
+		if i == 0 {
+			qt.Check(t, qt.ErrorMatches(err, `#Event.id: invalid value 0 \(out of bound >0\)`))
+		} else {
+			qt.Check(t, qt.IsNil(err))
+		}
+	}
+}
+
+func TestCompileErrors(t *testing.T) {
+	_, err := validator.CompileString(`#A: int`, cue.ParsePath("#B"))
+	qt.Assert(t, qt.ErrorMatches(err, `definition #B not found`))
+
+	_, err = validator.CompileString(`#A: 1 & 2`, cue.ParsePath("#A"))
+	qt.Assert(t, qt.ErrorMatches(err, `#A: conflicting values 2 and 1`))
+}
+
+func BenchmarkValidate(b *testing.B) {
+	v, err := validator.CompileString(schema, cue.ParsePath("#Event"))
+	qt.Assert(b, qt.IsNil(err))
+	data := []byte(`{"id": 1, "kind": "create", "tags": ["a", "b"]}`)
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			if err := v.Validate(data); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
+
+// TestValidateMemory checks that validating documents does not retain
+// them: the memory in use after many validations is about the same as
+// after a few.
+func TestValidateMemory(t *testing.T) {
+	v, err := validator.CompileString(schema, cue.ParsePath("#Event"))
+	qt.Assert(t, qt.IsNil(err))
+
+	validate := func(start, n int) uint64 {
+		for i := start; i < start+n; i++ {
+			data := fmt.Appendf(nil, `{"id": %d, "kind": "create", "tags": ["tag%d"]}`, i+1, i)
+			qt.Assert(t, qt.IsNil(v.Validate(data)))
+			x := cuecontext.New().CompileBytes(data)
+			qt.Assert(t, qt.IsNil(v.ValidateValue(x)))
+		}
+		runtime.GC()
+		var m runtime.MemStats
+		runtime.ReadMemStats(&m)
+		return m.HeapAlloc
+	}
+	const n = 2000
+	before := validate(0, n)
+	after := validate(n, 4*n)
+	qt.Assert(t, qt.IsTrue(after < before+4<<20),
+		qt.Commentf("heap grew from %d to %d bytes", before, after))
+}
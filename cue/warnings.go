@@ -0,0 +1,81 @@
+// Copyright 2025 The CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cue
+
+import (
+	"github.com/cockroachdb/apd/v3"
+
+	"cuelang.org/go/cue/errors"
+	"cuelang.org/go/internal/core/adt"
+)
+
+// Warnings reports problems with v which, unlike errors, do not make v
+// invalid, so that they can be shown to a user without failing. Like
+// [Value.Walk], it only considers values which are part of the data model.
+//
+// The following are reported, each with its own [errors.Code]:
+//
+//   - fields which are set to a concrete value and are marked as deprecated
+//     with a @deprecated attribute, whose optional argument explains what
+//     to use instead, as in
+//
+//     old?: int @deprecated("use new instead")
+//
+//   - numbers which cannot be represented exactly as a float64 and hence
+//     lose precision when converted to one, as done by [Value.Decode]
+//     and by encodings which use it, such as TOML.
+//
+// Warnings returns nil if there are no warnings.
+func (v Value) Warnings() errors.Error {
+	var warnings errors.Error
+	v.Walk(nil, func(x Value) {
+		if w := x.warning(); w != nil {
+			warnings = errors.Append(warnings, w)
+		}
+	})
+	return warnings
+}
+
+// warning returns the warning for x, not including the values it contains,
+// or nil if there is none.
+func (v Value) warning() errors.Error {
+	if a := v.Attribute("deprecated"); a.Err() == nil && v.IsConcrete() {
+		msg := "field is deprecated"
+		if s, err := a.String(0); err == nil && s != "" {
+			msg += ": " + s
+		}
+		return v.newWarning(errors.DeprecatedField, msg)
+	}
+	x, _ := v.Default()
+	if x.Kind() != FloatKind {
+		return nil
+	}
+	n, err := x.getNum(adt.NumberKind)
+	if err != nil {
+		return nil
+	}
+	f, _ := n.X.Float64()
+	var d apd.Decimal
+	if _, err := d.SetFloat64(f); err == nil && d.Cmp(&n.X) == 0 {
+		return nil
+	}
+	return v.newWarning(errors.LossyConversion,
+		"number %s loses precision when converted to float64", &n.X)
+}
+
+func (v Value) newWarning(c errors.Code, format string, args ...interface{}) errors.Error {
+	err := errors.Newf(v.Pos(), format, args...)
+	return errors.WithCode(&valueError{v: v, err: &adt.Bottom{Err: err}}, c)
+}
@@ -0,0 +1,79 @@
+// Copyright 2025 The CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cue_test
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/go-quicktest/qt"
+
+	"cuelang.org/go/cue/cuecontext"
+	"cuelang.org/go/cue/errors"
+)
+
+func TestWarnings(t *testing.T) {
+	tests := []struct {
+		name string
+		src  string
+		want []string // formatted as "code path: message"
+	}{{
+		name: "None",
+		src:  `a: 1, b: 0.1, c: [1.5, "x"]`,
+	}, {
+		name: "Deprecated",
+		src: `
+			#S: {
+				old?: int @deprecated("use new instead")
+				new?: int
+				gone?: string @deprecated()
+			}
+			s: #S & {old: 1, new: 2}
+			t: #S & {gone: "x"}
+			u: #S & {new: 3}
+		`,
+		want: []string{
+			"E5001 s.old: field is deprecated: use new instead",
+			"E5001 t.gone: field is deprecated",
+		},
+	}, {
+		name: "DeprecatedIncomplete",
+		src:  `a: int @deprecated()`,
+	}, {
+		name: "Lossy",
+		src:  `a: [3.141592653589793238462643383279, 1e400, 2.5]`,
+		want: []string{
+			"E5002 a.0: number 3.141592653589793238462643383279 loses precision when converted to float64",
+			"E5002 a.1: number 1E+400 loses precision when converted to float64",
+		},
+	}}
+	ctx := cuecontext.New()
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			v := ctx.CompileString(tc.src)
+			qt.Assert(t, qt.IsNil(v.Err()))
+			qt.Assert(t, qt.IsNil(v.Validate()))
+
+			var got []string
+			for _, w := range errors.Errors(v.Warnings()) {
+				format, args := w.Msg()
+				got = append(got, string(errors.CodeOf(w))+" "+
+					strings.Join(w.Path(), ".")+": "+fmt.Sprintf(format, args...))
+			}
+			qt.Assert(t, qt.DeepEquals(got, tc.want))
+		})
+	}
+}
@@ -0,0 +1,97 @@
+// Copyright 2025 CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package cuebin implements cuebin, a compact, self-describing binary
+// encoding of concrete CUE values, for programs which consume exported
+// values at a high rate and would rather not pay the cost of encoding
+// and parsing JSON.
+//
+// Importing this package registers the format, under the name cuebin
+// and the extension .cuebin, with [cuelang.org/go/encoding.Register].
+//
+// # Format
+//
+// A stream starts with the four bytes "CUEB" followed by a version
+// byte, currently 1. It is followed by any number of values, each of
+// which starts with a tag byte:
+//
+//	0x00  null
+//	0x01  false
+//	0x02  true
+//	0x03  int: zigzag-encoded varint
+//	0x04  big int: sign byte (0 or 1 for negative), uvarint length,
+//	      big-endian magnitude
+//	0x05  float: IEEE 754 binary64, big-endian
+//	0x06  decimal: uvarint length, decimal text
+//	0x07  string: uvarint length, UTF-8 text
+//	0x08  bytes: uvarint length, bytes
+//	0x09  list: uvarint count, elements
+//	0x0a  struct: uvarint count, fields
+//
+// Ints which fit in 64 bits are encoded as int and others as big int.
+// Floats are encoded as float if the shortest decimal representation
+// of the nearest binary64 number equals their value, and as decimal
+// otherwise, so no precision is lost.
+//
+// Each field of a struct consists of its name followed by its value.
+// A name is encoded as a uvarint n: if n is even, the name is the
+// following n/2 bytes of text, which is added to a table of names; if n
+// is odd, the name is entry (n-1)/2 of the table. The table is empty at
+// the start of each top-level value, which can thus be decoded
+// independently of others, but is shared by all the structs within it,
+// such that lists of similar structs do not repeat their field names.
+//
+// WARNING: THIS PACKAGE IS EXPERIMENTAL.
+// ITS API MAY CHANGE AT ANY TIME.
+package cuebin
+
+import (
+	"io"
+
+	"cuelang.org/go/encoding"
+)
+
+// Magic holds the bytes which start a cuebin stream.
+const Magic = "CUEB"
+
+// Version is the version of the format written by [Encoder].
+const Version = 1
+
+const (
+	tagNull byte = iota
+	tagFalse
+	tagTrue
+	tagInt
+	tagBigInt
+	tagFloat
+	tagDecimal
+	tagString
+	tagBytes
+	tagList
+	tagStruct
+)
+
+func init() {
+	encoding.Register(encoding.Format{
+		Name:       "cuebin",
+		Extensions: []string{".cuebin"},
+		Binary:     true,
+		NewDecoder: func(filename string, r io.Reader) encoding.Decoder {
+			return exprDecoder{NewDecoder(r)}
+		},
+		NewEncoder: func(w io.Writer) encoding.Encoder {
+			return NewEncoder(w)
+		},
+	})
+}
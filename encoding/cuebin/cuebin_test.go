@@ -0,0 +1,163 @@
+// Copyright 2025 CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cuebin_test
+
+import (
+	"bytes"
+	"io"
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/go-quicktest/qt"
+	"github.com/google/go-cmp/cmp"
+
+	"cuelang.org/go/cue/cuecontext"
+	"cuelang.org/go/encoding/cuebin"
+)
+
+func TestRoundTrip(t *testing.T) {
+	testCases := []struct {
+		name string
+		in   string
+		want any
+	}{{
+		name: "Scalars",
+		in:   `[null, true, false, -3, 1.5, 0.1, 2.0, "héllo", '\x00\xff']`,
+		want: []any{nil, true, false, int64(-3), 1.5, 0.1, 2.0, "héllo", []byte{0, 0xff}},
+	}, {
+		name: "BigNumbers",
+		in:   `[123456789012345678901234567890, -9223372036854775809, 1e400, 0.10000000000000000001]`,
+		want: []any{
+			bigInt("123456789012345678901234567890"),
+			bigInt("-9223372036854775809"),
+			cuebin.Decimal("1E+400"),
+			cuebin.Decimal("0.10000000000000000001"),
+		},
+	}, {
+		name: "Structs",
+		in: `{
+			b: [{name: "x", port: 1}, {name: "y", port: 2}]
+			a: {}
+			#def: 1
+			_hidden: 2
+			opt?: 3
+			"with space": 4
+		}`,
+		want: cuebin.Struct{
+			{"b", []any{
+				cuebin.Struct{{"name", "x"}, {"port", int64(1)}},
+				cuebin.Struct{{"name", "y"}, {"port", int64(2)}},
+			}},
+			{"a", cuebin.Struct{}},
+			{"with space", int64(4)},
+		},
+	}}
+	ctx := cuecontext.New()
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			v := ctx.CompileString(tc.in)
+			qt.Assert(t, qt.IsNil(v.Err()))
+
+			var buf bytes.Buffer
+			e := cuebin.NewEncoder(&buf)
+			qt.Assert(t, qt.IsNil(e.Encode(v)))
+			qt.Assert(t, qt.IsNil(e.Encode(v)))
+
+			d := cuebin.NewDecoder(bytes.NewReader(buf.Bytes()))
+			for range 2 {
+				got, err := d.Decode()
+				qt.Assert(t, qt.IsNil(err))
+				qt.Assert(t, qt.CmpEquals(got, tc.want, cmpBigInt))
+			}
+			_, err := d.Decode()
+			qt.Assert(t, qt.Equals(err, io.EOF))
+
+			// The CUE expression of the decoded value exports like the original.
+			d = cuebin.NewDecoder(bytes.NewReader(buf.Bytes()))
+			x, err := d.Extract()
+			qt.Assert(t, qt.IsNil(err))
+			got := ctx.BuildExpr(x)
+			qt.Assert(t, qt.IsNil(got.Err()))
+			gotJSON, err := got.MarshalJSON()
+			qt.Assert(t, qt.IsNil(err))
+			wantJSON, err := v.MarshalJSON()
+			qt.Assert(t, qt.IsNil(err))
+			qt.Assert(t, qt.Equals(string(gotJSON), string(wantJSON)))
+		})
+	}
+}
+
+func TestNameTable(t *testing.T) {
+	ctx := cuecontext.New()
+	v := ctx.CompileString(`[for i in [1, 2, 3, 4] {longFieldName: i}]`)
+	var buf bytes.Buffer
+	qt.Assert(t, qt.IsNil(cuebin.NewEncoder(&buf).Encode(v)))
+	qt.Assert(t, qt.Equals(strings.Count(buf.String(), "longFieldName"), 1))
+}
+
+func TestEncodeIncomplete(t *testing.T) {
+	ctx := cuecontext.New()
+	v := ctx.CompileString(`a: int`)
+	err := cuebin.NewEncoder(io.Discard).Encode(v)
+	qt.Assert(t, qt.ErrorMatches(err, `a: incomplete value int`))
+}
+
+func TestDecodeInvalid(t *testing.T) {
+	testCases := []struct {
+		name string
+		in   string
+		want string
+	}{{
+		name: "Magic",
+		in:   "JSON\x01",
+		want: "cuebin: not a cuebin stream",
+	}, {
+		name: "Version",
+		in:   "CUEB\x02",
+		want: "cuebin: unsupported version 2",
+	}, {
+		name: "ShortHeader",
+		in:   "CUE",
+		want: "cuebin: reading header: unexpected EOF",
+	}, {
+		name: "Tag",
+		in:   "CUEB\x01\x42",
+		want: "cuebin: invalid tag 0x42",
+	}, {
+		name: "Truncated",
+		in:   "CUEB\x01\x07\x05ab",
+		want: "unexpected EOF",
+	}, {
+		name: "NameReference",
+		in:   "CUEB\x01\x0a\x01\x03\x00",
+		want: "cuebin: invalid field name reference 1",
+	}}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := cuebin.NewDecoder(strings.NewReader(tc.in)).Decode()
+			qt.Assert(t, qt.ErrorMatches(err, tc.want))
+		})
+	}
+}
+
+var cmpBigInt = cmp.Comparer(func(x, y *big.Int) bool {
+	return x.Cmp(y) == 0
+})
+
+func bigInt(s string) *big.Int {
+	i, _ := new(big.Int).SetString(s, 10)
+	return i
+}
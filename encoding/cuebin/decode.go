@@ -0,0 +1,328 @@
+// Copyright 2025 CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cuebin
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"math/big"
+	"strconv"
+	"strings"
+
+	"cuelang.org/go/cue/ast"
+	"cuelang.org/go/cue/literal"
+	"cuelang.org/go/cue/token"
+)
+
+// maxPrealloc limits the capacity allocated for lists and structs
+// before their elements have been read.
+const maxPrealloc = 1024
+
+// A Decimal holds the decimal text of a float which cannot be
+// represented as a float64 without losing precision.
+type Decimal string
+
+// A Struct holds the fields of a struct in order.
+type Struct []Field
+
+// A Field is a field of a struct.
+type Field struct {
+	Name  string
+	Value any
+}
+
+// A Decoder reads values from a cuebin stream.
+type Decoder struct {
+	r      *bufio.Reader
+	header bool // whether the header has been read
+
+	names []string // table of field names of the current value
+}
+
+// NewDecoder returns a decoder which reads from r.
+func NewDecoder(r io.Reader) *Decoder {
+	return &Decoder{r: bufio.NewReader(r)}
+}
+
+// Decode returns the next value in the stream, or io.EOF if there are no
+// more values. A value is one of the following Go types:
+//
+//	nil       for null
+//	bool      for bools
+//	int64     for ints which fit in 64 bits
+//	*big.Int  for other ints
+//	float64   for floats which can be represented exactly
+//	Decimal   for other floats
+//	string    for strings
+//	[]byte    for bytes
+//	[]any     for lists
+//	Struct    for structs
+func (d *Decoder) Decode() (any, error) {
+	if err := d.readHeader(); err != nil {
+		return nil, err
+	}
+	tag, err := d.r.ReadByte()
+	if err != nil {
+		return nil, err // io.EOF at the end of the stream
+	}
+	d.names = d.names[:0]
+	v, err := d.decode(tag)
+	return v, noEOF(err)
+}
+
+// Extract is like [Decoder.Decode], but returns the next value as a CUE
+// expression.
+func (d *Decoder) Extract() (ast.Expr, error) {
+	v, err := d.Decode()
+	if err != nil {
+		return nil, err
+	}
+	return toExpr(v), nil
+}
+
+func (d *Decoder) readHeader() error {
+	if d.header {
+		return nil
+	}
+	var b [len(Magic) + 1]byte
+	if _, err := io.ReadFull(d.r, b[:]); err != nil {
+		if err == io.EOF {
+			return err
+		}
+		return fmt.Errorf("cuebin: reading header: %w", noEOF(err))
+	}
+	if string(b[:len(Magic)]) != Magic {
+		return errors.New("cuebin: not a cuebin stream")
+	}
+	if v := b[len(Magic)]; v != Version {
+		return fmt.Errorf("cuebin: unsupported version %d", v)
+	}
+	d.header = true
+	return nil
+}
+
+func (d *Decoder) decode(tag byte) (any, error) {
+	switch tag {
+	case tagNull:
+		return nil, nil
+
+	case tagFalse:
+		return false, nil
+
+	case tagTrue:
+		return true, nil
+
+	case tagInt:
+		return binary.ReadVarint(d.r)
+
+	case tagBigInt:
+		sign, err := d.r.ReadByte()
+		if err != nil {
+			return nil, err
+		}
+		b, err := d.readBytes()
+		if err != nil {
+			return nil, err
+		}
+		i := new(big.Int).SetBytes(b)
+		if sign != 0 {
+			i.Neg(i)
+		}
+		return i, nil
+
+	case tagFloat:
+		var b [8]byte
+		if _, err := io.ReadFull(d.r, b[:]); err != nil {
+			return nil, err
+		}
+		return math.Float64frombits(binary.BigEndian.Uint64(b[:])), nil
+
+	case tagDecimal:
+		b, err := d.readBytes()
+		return Decimal(b), err
+
+	case tagString:
+		b, err := d.readBytes()
+		return string(b), err
+
+	case tagBytes:
+		return d.readBytes()
+
+	case tagList:
+		n, err := d.readCount()
+		if err != nil {
+			return nil, err
+		}
+		list := make([]any, 0, min(n, maxPrealloc))
+		for range n {
+			v, err := d.decodeNext()
+			if err != nil {
+				return nil, err
+			}
+			list = append(list, v)
+		}
+		return list, nil
+
+	case tagStruct:
+		n, err := d.readCount()
+		if err != nil {
+			return nil, err
+		}
+		s := make(Struct, 0, min(n, maxPrealloc))
+		for range n {
+			name, err := d.readName()
+			if err != nil {
+				return nil, err
+			}
+			v, err := d.decodeNext()
+			if err != nil {
+				return nil, err
+			}
+			s = append(s, Field{name, v})
+		}
+		return s, nil
+	}
+	return nil, fmt.Errorf("cuebin: invalid tag 0x%02x", tag)
+}
+
+func (d *Decoder) decodeNext() (any, error) {
+	tag, err := d.r.ReadByte()
+	if err != nil {
+		return nil, err
+	}
+	return d.decode(tag)
+}
+
+func (d *Decoder) readName() (string, error) {
+	n, err := binary.ReadUvarint(d.r)
+	if err != nil {
+		return "", err
+	}
+	if n&1 == 1 {
+		if i := n >> 1; i < uint64(len(d.names)) {
+			return d.names[i], nil
+		}
+		return "", fmt.Errorf("cuebin: invalid field name reference %d", n>>1)
+	}
+	b, err := d.readN(n >> 1)
+	if err != nil {
+		return "", err
+	}
+	d.names = append(d.names, string(b))
+	return string(b), nil
+}
+
+// readCount reads the number of elements of a list or struct.
+func (d *Decoder) readCount() (int, error) {
+	n, err := binary.ReadUvarint(d.r)
+	if err != nil {
+		return 0, err
+	}
+	if n > math.MaxInt32 {
+		return 0, fmt.Errorf("cuebin: invalid count %d", n)
+	}
+	return int(n), nil
+}
+
+func (d *Decoder) readBytes() ([]byte, error) {
+	n, err := binary.ReadUvarint(d.r)
+	if err != nil {
+		return nil, err
+	}
+	return d.readN(n)
+}
+
+func (d *Decoder) readN(n uint64) ([]byte, error) {
+	if n > math.MaxInt32 {
+		return nil, fmt.Errorf("cuebin: invalid length %d", n)
+	}
+	// Grow the buffer as data arrives, rather than trusting n upfront.
+	var buf bytes.Buffer
+	if _, err := io.CopyN(&buf, d.r, int64(n)); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// noEOF turns an unexpected io.EOF within a value into
+// io.ErrUnexpectedEOF.
+func noEOF(err error) error {
+	if err == io.EOF {
+		return io.ErrUnexpectedEOF
+	}
+	return err
+}
+
+func toExpr(v any) ast.Expr {
+	switch v := v.(type) {
+	case nil:
+		return ast.NewNull()
+	case bool:
+		return ast.NewBool(v)
+	case int64:
+		return numLit(token.INT, strconv.FormatInt(v, 10))
+	case *big.Int:
+		return numLit(token.INT, v.String())
+	case float64:
+		s := strconv.FormatFloat(v, 'g', -1, 64)
+		if !strings.ContainsAny(s, ".e") {
+			s += ".0"
+		}
+		return numLit(token.FLOAT, s)
+	case Decimal:
+		return numLit(token.FLOAT, string(v))
+	case string:
+		return ast.NewString(v)
+	case []byte:
+		return ast.NewLit(token.STRING, literal.Bytes.Quote(string(v)))
+	case []any:
+		elems := make([]ast.Expr, len(v))
+		for i, e := range v {
+			elems[i] = toExpr(e)
+		}
+		return ast.NewList(elems...)
+	case Struct:
+		s := &ast.StructLit{}
+		for _, f := range v {
+			s.Elts = append(s.Elts, &ast.Field{
+				Label: ast.NewString(f.Name),
+				Value: toExpr(f.Value),
+			})
+		}
+		return s
+	}
+	panic(fmt.Sprintf("unexpected type %T", v))
+}
+
+func numLit(kind token.Token, s string) ast.Expr {
+	if s, ok := strings.CutPrefix(s, "-"); ok {
+		return &ast.UnaryExpr{Op: token.SUB, X: ast.NewLit(kind, s)}
+	}
+	return ast.NewLit(kind, s)
+}
+
+// exprDecoder implements [encoding.Decoder].
+type exprDecoder struct {
+	d *Decoder
+}
+
+func (d exprDecoder) Decode() (ast.Expr, error) {
+	return d.d.Extract()
+}
@@ -0,0 +1,205 @@
+// Copyright 2025 CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cuebin
+
+import (
+	"bufio"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+	"math/big"
+	"strconv"
+
+	"cuelang.org/go/cue"
+)
+
+// An Encoder writes a cuebin stream of values.
+type Encoder struct {
+	w      *bufio.Writer
+	header bool // whether the header has been written
+
+	names map[string]uint64 // table of field names of the current value
+	buf   [binary.MaxVarintLen64]byte
+}
+
+// NewEncoder returns an encoder which writes to w.
+func NewEncoder(w io.Writer) *Encoder {
+	return &Encoder{w: bufio.NewWriter(w)}
+}
+
+// Encode writes the concrete value v to the stream. Definitions, hidden
+// fields, and optional fields are not encoded.
+func (e *Encoder) Encode(v cue.Value) error {
+	if err := v.Validate(cue.Concrete(true), cue.Final()); err != nil {
+		return err
+	}
+	if !e.header {
+		e.w.WriteString(Magic)
+		e.w.WriteByte(Version)
+		e.header = true
+	}
+	clear(e.names)
+	if e.names == nil {
+		e.names = map[string]uint64{}
+	}
+	if err := e.encode(v); err != nil {
+		return err
+	}
+	return e.w.Flush()
+}
+
+func (e *Encoder) encode(v cue.Value) error {
+	switch v.Kind() {
+	case cue.NullKind:
+		e.w.WriteByte(tagNull)
+
+	case cue.BoolKind:
+		b, err := v.Bool()
+		if err != nil {
+			return err
+		}
+		if b {
+			e.w.WriteByte(tagTrue)
+		} else {
+			e.w.WriteByte(tagFalse)
+		}
+
+	case cue.IntKind:
+		if i, err := v.Int64(); err == nil {
+			e.w.WriteByte(tagInt)
+			e.w.Write(binary.AppendVarint(e.buf[:0], i))
+			break
+		}
+		i, err := v.Int(nil)
+		if err != nil {
+			return err
+		}
+		e.w.WriteByte(tagBigInt)
+		if i.Sign() < 0 {
+			e.w.WriteByte(1)
+		} else {
+			e.w.WriteByte(0)
+		}
+		e.writeBytes(i.Bytes())
+
+	case cue.FloatKind:
+		b, err := v.MarshalJSON()
+		if err != nil {
+			return err
+		}
+		text := string(b)
+		if f, ok := exactFloat64(text); ok {
+			e.w.WriteByte(tagFloat)
+			e.w.Write(binary.BigEndian.AppendUint64(e.buf[:0], math.Float64bits(f)))
+			break
+		}
+		e.w.WriteByte(tagDecimal)
+		e.writeString(text)
+
+	case cue.StringKind:
+		s, err := v.String()
+		if err != nil {
+			return err
+		}
+		e.w.WriteByte(tagString)
+		e.writeString(s)
+
+	case cue.BytesKind:
+		b, err := v.Bytes()
+		if err != nil {
+			return err
+		}
+		e.w.WriteByte(tagBytes)
+		e.writeBytes(b)
+
+	case cue.ListKind:
+		iter, err := v.List()
+		if err != nil {
+			return err
+		}
+		var elems []cue.Value
+		for iter.Next() {
+			elems = append(elems, iter.Value())
+		}
+		e.w.WriteByte(tagList)
+		e.writeUvarint(uint64(len(elems)))
+		for _, elem := range elems {
+			if err := e.encode(elem); err != nil {
+				return err
+			}
+		}
+
+	case cue.StructKind:
+		iter, err := v.Fields()
+		if err != nil {
+			return err
+		}
+		var names []string
+		var values []cue.Value
+		for iter.Next() {
+			names = append(names, iter.Selector().Unquoted())
+			values = append(values, iter.Value())
+		}
+		e.w.WriteByte(tagStruct)
+		e.writeUvarint(uint64(len(names)))
+		for i, name := range names {
+			if n, ok := e.names[name]; ok {
+				e.writeUvarint(n<<1 | 1)
+			} else {
+				e.names[name] = uint64(len(e.names))
+				e.writeUvarint(uint64(len(name)) << 1)
+				e.w.WriteString(name)
+			}
+			if err := e.encode(values[i]); err != nil {
+				return err
+			}
+		}
+
+	default:
+		return fmt.Errorf("cuebin: cannot encode value of kind %v", v.Kind())
+	}
+	return nil
+}
+
+func (e *Encoder) writeUvarint(n uint64) {
+	e.w.Write(binary.AppendUvarint(e.buf[:0], n))
+}
+
+func (e *Encoder) writeString(s string) {
+	e.writeUvarint(uint64(len(s)))
+	e.w.WriteString(s)
+}
+
+func (e *Encoder) writeBytes(b []byte) {
+	e.writeUvarint(uint64(len(b)))
+	e.w.Write(b)
+}
+
+// exactFloat64 returns the binary64 number nearest to the decimal text,
+// and reports whether its shortest decimal representation has the same
+// value as text.
+func exactFloat64(text string) (float64, bool) {
+	f, err := strconv.ParseFloat(text, 64)
+	if err != nil || math.IsInf(f, 0) {
+		return 0, false
+	}
+	want, ok := new(big.Rat).SetString(text)
+	if !ok {
+		return 0, false
+	}
+	got, ok := new(big.Rat).SetString(strconv.FormatFloat(f, 'g', -1, 64))
+	return f, ok && got.Cmp(want) == 0
+}
@@ -33,4 +33,7 @@
 //
 // Unmarshal and Marshal are used if the respective Decoder and Encoder decode
 // and encode from and to a stream of bytes.
+//
+// Programs can add their own data formats to those supported by the cue tool
+// and [cuelang.org/go/cue/load] with [Register].
 package encoding
@@ -0,0 +1,93 @@
+// Copyright 2025 CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package encoding
+
+import (
+	"fmt"
+	"io"
+	"sync"
+
+	"cuelang.org/go/cue"
+	"cuelang.org/go/cue/ast"
+	"cuelang.org/go/internal/filetypes"
+)
+
+// A Format describes a data file format, which can be added to those
+// built into CUE with [Register].
+//
+// Once registered, a format can be used wherever a built-in data format
+// such as JSON can, both by name as a file type qualifier, as in
+// "csv: data.txt", and by file extension. This makes it available to the
+// commands of the cue tool, such as import, export, vet, and def, when
+// built into it, as well as to [cuelang.org/go/cue/load].
+type Format struct {
+	// Name is the name of the format, as used in file type qualifiers.
+	// It must not be that of a built-in tag, such as json or schema.
+	Name string
+
+	// Extensions lists the file extensions, including the leading dot,
+	// of files in the format. They must not be used by a built-in file
+	// type.
+	Extensions []string
+
+	// Binary reports whether files are binary, rather than UTF-8 text.
+	// Text files have any byte order mark removed before they are
+	// decoded.
+	Binary bool
+
+	// NewDecoder returns a decoder for the file with the given name
+	// read from r. It is nil if the format cannot be decoded.
+	NewDecoder func(filename string, r io.Reader) Decoder
+
+	// NewEncoder returns an encoder which writes to w. It is nil if the
+	// format cannot be encoded.
+	NewEncoder func(w io.Writer) Encoder
+}
+
+// A Decoder decodes a stream of values.
+type Decoder interface {
+	// Decode returns the next value in the stream as a CUE expression,
+	// or io.EOF if there are no more values.
+	Decode() (ast.Expr, error)
+}
+
+// An Encoder encodes a stream of values.
+type Encoder interface {
+	// Encode writes the concrete value v.
+	Encode(v cue.Value) error
+}
+
+var formats sync.Map // map[string]Format
+
+// Register registers f for use alongside the built-in formats. It is
+// typically called from an init function.
+//
+// Register panics if f has an invalid or duplicate name or extension.
+func Register(f Format) {
+	if err := filetypes.RegisterEncoding(f.Name, f.Extensions); err != nil {
+		panic(fmt.Sprintf("encoding.Register: %v", err))
+	}
+	formats.Store(f.Name, f)
+}
+
+// Lookup returns the registered format with the given name, and
+// reports whether there is one.
+func Lookup(name string) (Format, bool) {
+	f, ok := formats.Load(name)
+	if !ok {
+		return Format{}, false
+	}
+	return f.(Format), true
+}
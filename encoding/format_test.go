@@ -0,0 +1,195 @@
+// Copyright 2025 CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package encoding_test
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/go-quicktest/qt"
+
+	"cuelang.org/go/cue"
+	"cuelang.org/go/cue/ast"
+	"cuelang.org/go/cue/build"
+	"cuelang.org/go/cue/cuecontext"
+	"cuelang.org/go/encoding"
+	internalencoding "cuelang.org/go/internal/encoding"
+	"cuelang.org/go/internal/filetypes"
+)
+
+// lines is a format with one string value per line.
+var registerLines = sync.OnceFunc(func() {
+	encoding.Register(encoding.Format{
+		Name:       "lines",
+		Extensions: []string{".lines"},
+		NewDecoder: func(filename string, r io.Reader) encoding.Decoder {
+			return &linesDecoder{bufio.NewScanner(r)}
+		},
+		NewEncoder: func(w io.Writer) encoding.Encoder {
+			return linesEncoder{w}
+		},
+	})
+})
+
+type linesDecoder struct {
+	s *bufio.Scanner
+}
+
+func (d *linesDecoder) Decode() (ast.Expr, error) {
+	if !d.s.Scan() {
+		if err := d.s.Err(); err != nil {
+			return nil, err
+		}
+		return nil, io.EOF
+	}
+	return ast.NewString(d.s.Text()), nil
+}
+
+type linesEncoder struct {
+	w io.Writer
+}
+
+func (e linesEncoder) Encode(v cue.Value) error {
+	s, err := v.String()
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintln(e.w, s)
+	return err
+}
+
+func TestRegisterFileTypes(t *testing.T) {
+	registerLines()
+
+	files, err := filetypes.ParseArgs([]string{"x.lines", "lines:", "x.txt", "lines+jsonschema:", "y.txt", "jsonschema:", "y.lines"})
+	qt.Assert(t, qt.IsNil(err))
+	qt.Assert(t, qt.DeepEquals(files, []*build.File{{
+		Filename:       "x.lines",
+		Encoding:       "lines",
+		Interpretation: build.Auto,
+	}, {
+		Filename: "x.txt",
+		Encoding: "lines",
+	}, {
+		Filename:       "y.txt",
+		Encoding:       "lines",
+		Interpretation: build.JSONSchema,
+		Form:           build.Schema,
+		BoolTags: map[string]bool{
+			"strict":         false,
+			"strictFeatures": false,
+			"strictKeywords": false,
+		},
+	}, {
+		Filename:       "y.lines",
+		Encoding:       "lines",
+		Interpretation: build.JSONSchema,
+		Form:           build.Schema,
+		BoolTags: map[string]bool{
+			"strict":         false,
+			"strictFeatures": false,
+			"strictKeywords": false,
+		},
+	}}))
+
+	_, err = filetypes.ParseArgs([]string{"lines+yaml:", "x.txt"})
+	qt.Assert(t, qt.ErrorMatches(err, `cannot combine encodings lines and yaml`))
+
+	// An explicit built-in encoding takes precedence over the extension.
+	files, err = filetypes.ParseArgs([]string{"yaml:", "x.lines"})
+	qt.Assert(t, qt.IsNil(err))
+	qt.Assert(t, qt.Equals(files[0].Encoding, build.YAML))
+
+	f, err := filetypes.ParseFile("lines:-", filetypes.Export)
+	qt.Assert(t, qt.IsNil(err))
+	qt.Assert(t, qt.Equals(f.Encoding, "lines"))
+
+	fi, err := filetypes.FromFile(f, filetypes.Export)
+	qt.Assert(t, qt.IsNil(err))
+	qt.Assert(t, qt.Equals(fi.Encoding, "lines"))
+	qt.Assert(t, qt.IsTrue(fi.Data))
+}
+
+func TestRegisterDecodeEncode(t *testing.T) {
+	registerLines()
+	ctx := cuecontext.New()
+
+	d := internalencoding.NewDecoder(ctx, &build.File{
+		Filename: "x.lines",
+		Encoding: "lines",
+		Source:   "\ufeffone\ntwo\n",
+	}, nil)
+	defer d.Close()
+	var got []string
+	for ; !d.Done(); d.Next() {
+		s, err := ctx.BuildExpr(d.File().Decls[0].(*ast.EmbedDecl).Expr).String()
+		qt.Assert(t, qt.IsNil(err))
+		got = append(got, s)
+	}
+	qt.Assert(t, qt.IsNil(d.Err()))
+	qt.Assert(t, qt.DeepEquals(got, []string{"one", "two"}))
+
+	var buf strings.Builder
+	e, err := internalencoding.NewEncoder(ctx, &build.File{
+		Filename: "-",
+		Encoding: "lines",
+	}, &internalencoding.Config{Out: &buf})
+	qt.Assert(t, qt.IsNil(err))
+	for _, s := range got {
+		qt.Assert(t, qt.IsNil(e.Encode(ctx.Encode(s))))
+	}
+	qt.Assert(t, qt.IsNil(e.Close()))
+	qt.Assert(t, qt.Equals(buf.String(), "one\ntwo\n"))
+}
+
+func TestRegisterInvalid(t *testing.T) {
+	registerLines()
+	testCases := []struct {
+		name   string
+		format encoding.Format
+		want   string
+	}{{
+		name:   "BuiltinName",
+		format: encoding.Format{Name: "json"},
+		want:   `encoding name "json" is already used by a built-in tag`,
+	}, {
+		name:   "BuiltinExtension",
+		format: encoding.Format{Name: "myjson", Extensions: []string{".json"}},
+		want:   `file extension ".json" is already used by a built-in file type`,
+	}, {
+		name:   "Duplicate",
+		format: encoding.Format{Name: "lines"},
+		want:   `encoding "lines" registered twice`,
+	}, {
+		name:   "DuplicateExtension",
+		format: encoding.Format{Name: "lines2", Extensions: []string{".lines"}},
+		want:   `file extension ".lines" is already used by encoding "lines"`,
+	}, {
+		name:   "InvalidName",
+		format: encoding.Format{Name: "a+b"},
+		want:   `invalid encoding name "a\+b"`,
+	}}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			qt.Assert(t, qt.PanicMatches(func() {
+				encoding.Register(tc.format)
+			}, "encoding.Register: "+tc.want))
+		})
+	}
+}
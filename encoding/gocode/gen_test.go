@@ -22,7 +22,10 @@ import (
 	"strings"
 	"testing"
 
+	"cuelang.org/go/cue"
+	"cuelang.org/go/cue/cuecontext"
 	"cuelang.org/go/cue/errors"
+	"cuelang.org/go/cue/load"
 	"cuelang.org/go/encoding/gocode/testdata/pkg1"
 	"cuelang.org/go/encoding/gocode/testdata/pkg2"
 )
@@ -119,3 +122,48 @@ func errStr(err error) string {
 	r := regexp.MustCompile(`.cue:\d+:\d+`)
 	return r.ReplaceAllString(buf.String(), ".cue:x:x")
 }
+
+func TestGenerateOptions(t *testing.T) {
+	insts := load.Instances([]string{"./pkg1"}, &load.Config{
+		Dir: "testdata",
+	})
+	inst := insts[0]
+	if err := inst.Err; err != nil {
+		t.Fatal(err)
+	}
+	ctx := cuecontext.New()
+	v := ctx.BuildInstance(inst)
+
+	b, err := Generate(inst.Dir, v, &Config{
+		PackageName: "other",
+		Header:      "// Custom header.",
+		ContextVar:  "ctx",
+		Strict:      true,
+		Filter: func(name string, v cue.Value) bool {
+			return name != "OtherStruct"
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := string(b)
+	for _, want := range []string{
+		"// Custom header.\n\npackage other\n",
+		"func (x *MyStruct) Validate() error {\n\treturn cuegenCodec.ValidateStrict(",
+		"r = (*cue.Runtime)(ctx)",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("output does not contain %q:\n%s", want, got)
+		}
+	}
+	for _, unwanted := range []string{"Code generated", "OtherStruct"} {
+		if strings.Contains(got, unwanted) {
+			t.Errorf("output contains %q:\n%s", unwanted, got)
+		}
+	}
+
+	_, err = Generate(inst.Dir, v, &Config{RuntimeVar: "r", ContextVar: "ctx"})
+	if err == nil {
+		t.Errorf("expected error when setting both RuntimeVar and ContextVar")
+	}
+}
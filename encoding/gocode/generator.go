@@ -21,6 +21,7 @@ import (
 	"go/ast"
 	"go/format"
 	"go/types"
+	"strings"
 	"text/template"
 
 	"golang.org/x/tools/go/packages"
@@ -47,9 +48,36 @@ type Config struct {
 	// The cue.Runtime variable name to use for initializing Codecs.
 	// A new Runtime is created by default.
 	RuntimeVar string
+
+	// ContextVar is the name of a *cue.Context variable to use for
+	// initializing Codecs, as an alternative to RuntimeVar.
+	ContextVar string
+
+	// PackageName is the name of the package of the generated code. It
+	// defaults to the name of the Go package at the path passed to
+	// Generate or, if there is none, the name of the CUE package.
+	PackageName string
+
+	// Header holds the comments at the top of the generated file. It
+	// defaults to a comment marking the file as generated by Generate.
+	Header string
+
+	// Filter, if non-nil, reports whether to generate code for the
+	// top-level declaration with the given name and value. Declarations
+	// it accepts are still subject to the rules described for Generate.
+	Filter func(name string, v cue.Value) bool
+
+	// Strict makes generated validation functions check values
+	// exhaustively, reporting all errors and requiring the values of all
+	// regular fields to be concrete, rather than only reporting whether a
+	// value conflicts with its definition.
+	Strict bool
 }
 
-const defaultPrefix = "cuegen"
+const (
+	defaultPrefix = "cuegen"
+	defaultHeader = "// Code generated by gocode.Generate; DO NOT EDIT.\n"
+)
 
 // Generate generates Go code for the given instance in the directory of the
 // given package.
@@ -100,6 +128,10 @@ func Generate(pkgPath string, inst cue.InstanceOrValue, c *Config) (b []byte, er
 		c = &Config{}
 	}
 
+	if c.RuntimeVar != "" && c.ContextVar != "" {
+		return nil, fmt.Errorf("cannot set both RuntimeVar and ContextVar")
+	}
+
 	g := &generator{
 		Config:  *c,
 		typeMap: map[string]types.Type{},
@@ -142,8 +174,13 @@ func Generate(pkgPath string, inst cue.InstanceOrValue, c *Config) (b []byte, er
 
 	// TODO: add package doc if there is no existing Go package or if it doesn't
 	// have package documentation already.
+	header := cmp.Or(g.Header, defaultHeader)
+	if !strings.HasSuffix(header, "\n") {
+		header += "\n"
+	}
 	g.exec(headerCode, map[string]string{
-		"pkgName": pkgName,
+		"header":  header,
+		"pkgName": cmp.Or(g.PackageName, pkgName),
 	})
 
 	iter, err := val.Fields(cue.Definitions(true))
@@ -153,7 +190,11 @@ func Generate(pkgPath string, inst cue.InstanceOrValue, c *Config) (b []byte, er
 		// TODO(mvdan): using cue.Definitions above means that we iterate over definitions,
 		// whose selector will not be of string type. Revisit this, because using Unquoted
 		// for definitions is likely not right.
-		g.decl(iter.Selector().Unquoted(), iter.Value())
+		name := iter.Selector().Unquoted()
+		if g.Filter != nil && !g.Filter(name, iter.Value()) {
+			continue
+		}
+		g.decl(name, iter.Value())
 	}
 
 	r := (*cue.Runtime)(val.Context())
@@ -162,6 +203,7 @@ func Generate(pkgPath string, inst cue.InstanceOrValue, c *Config) (b []byte, er
 
 	g.exec(loadCode, map[string]string{
 		"runtime": g.RuntimeVar,
+		"context": g.ContextVar,
 		"prefix":  cmp.Or(g.Prefix, defaultPrefix),
 		"data":    string(b),
 	})
@@ -256,6 +298,7 @@ func (g *generator) decl(name string, v cue.Value) {
 		"func":     isFunc,
 		"validate": lookupName(attr, "validate", cmp.Or(g.ValidateName, "Validate")),
 		"complete": lookupName(attr, "complete", g.CompleteName),
+		"strict":   g.Strict,
 	})
 }
 
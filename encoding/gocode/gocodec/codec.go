@@ -135,6 +135,21 @@ func (c *Codec) Validate(v cue.Value, x interface{}) error {
 	return w.Unify(v).Err()
 }
 
+// ValidateStrict is like Validate, but checks x exhaustively: it reports
+// all errors rather than only the first, and requires the values of all
+// regular fields of the result to be concrete.
+func (c *Codec) ValidateStrict(v cue.Value, x interface{}) error {
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+
+	r := checkAndForkContext(c.runtime, v)
+	w, err := fromGoValue(r, x, false)
+	if err != nil {
+		return err
+	}
+	return w.Unify(v).Validate(cue.Concrete(true))
+}
+
 // Complete sets previously undefined values in x that can be uniquely
 // determined form the constraints defined by v if validation passes, or returns
 // an error, without modifying anything, otherwise.
@@ -37,6 +37,54 @@ func checkErr(t *testing.T, got error, want string) {
 		t.Errorf("error: got %v; want %v", got, want)
 	}
 }
+func TestValidateStrict(t *testing.T) {
+	fail := "some error"
+	testCases := []struct {
+		name        string
+		value       interface{}
+		constraints string
+		err         string
+	}{{
+		name:  "*Sum",
+		value: &Sum{A: 1, B: 4, C: 5},
+	}, {
+		name:  "*Sum: incorrect sum",
+		value: &Sum{A: 1, B: 4, C: 6},
+		err:   fail,
+	}, {
+		name:        "non-concrete field",
+		value:       map[string]int{"a": 1},
+		constraints: "{a: int, b: int}",
+		err:         fail,
+	}, {
+		name:        "concrete fields",
+		value:       map[string]int{"a": 1, "b": 2},
+		constraints: "{a: int, b: int}",
+	}}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx := cuecontext.New()
+			codec := New(ctx, nil)
+
+			v, err := codec.ExtractType(tc.value)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if tc.constraints != "" {
+				v1 := ctx.CompileString(tc.constraints, cue.Filename(tc.name))
+				if err := v1.Err(); err != nil {
+					t.Fatal(err)
+				}
+				v = v.Unify(v1)
+			}
+
+			err = codec.ValidateStrict(v, tc.value)
+			checkErr(t, err, tc.err)
+		})
+	}
+}
+
 func TestValidate(t *testing.T) {
 	fail := "some error"
 	testCases := []struct {
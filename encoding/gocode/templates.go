@@ -19,10 +19,10 @@ import (
 )
 
 // Inputs:
+// .header   the comments at the top of the file
 // .pkgName  the Go package name
 var headerCode = template.Must(template.New("header").Parse(
-	`// Code generated by gocode.Generate; DO NOT EDIT.
-
+	`{{.header}}
 package {{.pkgName}}
 
 import (
@@ -42,6 +42,7 @@ import (
 // .zero      zero value of the Go type; nil indicates no value
 // .validate  name of the validate function; "" means no validate
 // .complete  name of the complete function; "" means no complete
+// .strict    whether to validate exhaustively
 var stubCode = template.Must(template.New("type").Parse(`
 var {{.prefix}}val{{.cueName}} = {{.prefix}}Make("{{.cueName}}", {{.zero}})
 
@@ -50,7 +51,7 @@ var {{.prefix}}val{{.cueName}} = {{.prefix}}Make("{{.cueName}}", {{.zero}})
 // {{.validate}}{{if .func}}{{.cueName}}{{end}} validates x.
 func {{if .func}}{{.validate}}{{.cueName}}{{$sig}}
      {{- else -}}{{$sig}} {{.validate}}(){{end}} error {
-	return {{.prefix}}Codec.Validate({{.prefix}}val{{.cueName}}, x)
+	return {{.prefix}}Codec.{{if .strict}}ValidateStrict{{else}}Validate{{end}}({{.prefix}}val{{.cueName}}, x)
 }
 {{end}}
 {{if .complete}}
@@ -65,11 +66,12 @@ func {{if .func}}{{.complete}}{{.cueName}}{{$sig}}
 // Inputs:
 // .prefix 	  prefix to all generated variable names
 // .runtime   the variable name of a user-supplied runtime, if any
+// .context   the variable name of a user-supplied context, if any
 // .data      bytes obtained from Instance.MarshalBinary
 var loadCode = template.Must(template.New("load").Parse(`
 var {{.prefix}}Codec, {{.prefix}}Instance_, {{.prefix}}Value = func() (*gocodec.Codec, *cue.Instance, cue.Value) {
 	var r *cue.Runtime
-	r = {{if .runtime}}{{.runtime}}{{else}}&cue.Runtime{}{{end}}
+	r = {{if .runtime}}{{.runtime}}{{else if .context}}(*cue.Runtime)({{.context}}){{else}}&cue.Runtime{}{{end}}
 	instances, err := r.Unmarshal({{.prefix}}InstanceData)
 	if err != nil {
 		panic(err)
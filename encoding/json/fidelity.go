@@ -0,0 +1,246 @@
+// Copyright 2025 CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package json
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"reflect"
+	"slices"
+)
+
+// FormatLike formats the JSON value data like the JSON value original,
+// where their structures match, so that a file can be rewritten with a
+// modified value without needless changes. The order of keys, the
+// indentation, and the representation of unchanged values in original,
+// such as 1.0 or "é", are retained. Keys not in original follow
+// the key preceding them in data.
+func FormatLike(data, original []byte) ([]byte, error) {
+	n, err := parseNode(data)
+	if err != nil {
+		return nil, err
+	}
+	orig, err := parseNode(original)
+	if err != nil {
+		return nil, fmt.Errorf("invalid original JSON: %v", err)
+	}
+	n.restyle(orig)
+
+	p := printer{indent: indentOf(original)}
+	p.print(n, 0)
+	if bytes.HasSuffix(original, []byte("\n")) {
+		p.buf.WriteByte('\n')
+	}
+	return p.buf.Bytes(), nil
+}
+
+// A node is a JSON value which retains the order of object keys and
+// the representation of scalars.
+type node struct {
+	kind    byte // '{', '[', or 0 for scalars
+	keys    []string
+	elems   []*node // values of keys for objects
+	raw     json.RawMessage
+	origKey []json.RawMessage // representation of keys
+}
+
+func parseNode(b []byte) (*node, error) {
+	d := json.NewDecoder(bytes.NewReader(b))
+	d.UseNumber()
+	var raw json.RawMessage
+	if err := d.Decode(&raw); err != nil {
+		return nil, err
+	}
+	return newNode(raw)
+}
+
+func newNode(raw json.RawMessage) (*node, error) {
+	raw = bytes.TrimSpace(raw)
+	if len(raw) == 0 || (raw[0] != '{' && raw[0] != '[') {
+		return &node{raw: raw}, nil
+	}
+	n := &node{kind: raw[0]}
+	d := json.NewDecoder(bytes.NewReader(raw))
+	d.UseNumber()
+	if _, err := d.Token(); err != nil {
+		return nil, err
+	}
+	for d.More() {
+		if n.kind == '{' {
+			start := d.InputOffset()
+			tok, err := d.Token()
+			if err != nil {
+				return nil, err
+			}
+			key := bytes.Trim(raw[start:d.InputOffset()], " \t\r\n,:")
+			n.keys = append(n.keys, tok.(string))
+			n.origKey = append(n.origKey, json.RawMessage(key))
+		}
+		var elem json.RawMessage
+		if err := d.Decode(&elem); err != nil {
+			return nil, err
+		}
+		e, err := newNode(elem)
+		if err != nil {
+			return nil, err
+		}
+		n.elems = append(n.elems, e)
+	}
+	return n, nil
+}
+
+// restyle applies the order of keys and the representation of values
+// of orig to n.
+func (n *node) restyle(orig *node) {
+	if n.kind != orig.kind {
+		return
+	}
+	switch n.kind {
+	case 0:
+		if sameValue(n.raw, orig.raw) {
+			n.raw = orig.raw
+		}
+
+	case '[':
+		for i, e := range n.elems {
+			if i < len(orig.elems) {
+				e.restyle(orig.elems[i])
+			}
+		}
+
+	case '{':
+		origIndex := make(map[string]int)
+		for i, k := range orig.keys {
+			origIndex[k] = i
+		}
+		var known, added []int
+		for i, k := range n.keys {
+			if j, ok := origIndex[k]; ok {
+				n.origKey[i] = orig.origKey[j]
+				n.elems[i].restyle(orig.elems[j])
+				known = append(known, i)
+			} else {
+				added = append(added, i)
+			}
+		}
+		slices.SortStableFunc(known, func(a, b int) int {
+			return origIndex[n.keys[a]] - origIndex[n.keys[b]]
+		})
+		order := known
+		for _, a := range added {
+			pos := 0
+			if a > 0 {
+				pos = slices.Index(order, a-1) + 1
+			}
+			order = slices.Insert(order, pos, a)
+		}
+		keys := make([]string, len(order))
+		origKey := make([]json.RawMessage, len(order))
+		elems := make([]*node, len(order))
+		for i, j := range order {
+			keys[i], origKey[i], elems[i] = n.keys[j], n.origKey[j], n.elems[j]
+		}
+		n.keys, n.origKey, n.elems = keys, origKey, elems
+	}
+}
+
+// sameValue reports whether the JSON scalars a and b represent the
+// same value.
+func sameValue(a, b json.RawMessage) bool {
+	var x, y any
+	if err := unmarshalNumber(a, &x); err != nil {
+		return false
+	}
+	if err := unmarshalNumber(b, &y); err != nil {
+		return false
+	}
+	nx, okx := x.(json.Number)
+	ny, oky := y.(json.Number)
+	if okx && oky {
+		fx, _, errx := big.ParseFloat(string(nx), 10, 1024, big.ToNearestEven)
+		fy, _, erry := big.ParseFloat(string(ny), 10, 1024, big.ToNearestEven)
+		return errx == nil && erry == nil && fx.Cmp(fy) == 0
+	}
+	return reflect.DeepEqual(x, y)
+}
+
+func unmarshalNumber(b []byte, v any) error {
+	d := json.NewDecoder(bytes.NewReader(b))
+	d.UseNumber()
+	return d.Decode(v)
+}
+
+// indentOf returns the indentation of the first indented line of b, or
+// "" if b is not indented.
+func indentOf(b []byte) string {
+	for {
+		i := bytes.IndexByte(b, '\n')
+		if i < 0 {
+			return ""
+		}
+		b = b[i+1:]
+		line := b[:len(b)-len(bytes.TrimLeft(b, " \t"))]
+		if len(line) > 0 {
+			return string(line)
+		}
+	}
+}
+
+type printer struct {
+	buf    bytes.Buffer
+	indent string
+}
+
+func (p *printer) newline(depth int) {
+	if p.indent == "" {
+		return
+	}
+	p.buf.WriteByte('\n')
+	for range depth {
+		p.buf.WriteString(p.indent)
+	}
+}
+
+func (p *printer) print(n *node, depth int) {
+	if n.kind == 0 {
+		p.buf.Write(n.raw)
+		return
+	}
+	end := byte('}')
+	if n.kind == '[' {
+		end = ']'
+	}
+	p.buf.WriteByte(n.kind)
+	for i, e := range n.elems {
+		if i > 0 {
+			p.buf.WriteByte(',')
+		}
+		p.newline(depth + 1)
+		if n.kind == '{' {
+			p.buf.Write(n.origKey[i])
+			p.buf.WriteByte(':')
+			if p.indent != "" {
+				p.buf.WriteByte(' ')
+			}
+		}
+		p.print(e, depth+1)
+	}
+	if len(n.elems) > 0 {
+		p.newline(depth)
+	}
+	p.buf.WriteByte(end)
+}
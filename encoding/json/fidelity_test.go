@@ -0,0 +1,60 @@
+// Copyright 2025 CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package json
+
+import (
+	"testing"
+
+	"github.com/go-quicktest/qt"
+)
+
+func TestFormatLike(t *testing.T) {
+	testCases := []struct {
+		name string
+		orig string
+		in   string
+		out  string
+	}{{
+		name: "Indented",
+		orig: "{\n  \"name\": \"caf\\u00e9\",\n  \"port\": 80.0,\n  \"tags\": [\"a\"]\n}\n",
+		in:   `{"port":80,"name":"café","tags":["a","b"],"debug":true}`,
+		out:  "{\n  \"name\": \"caf\\u00e9\",\n  \"port\": 80.0,\n  \"tags\": [\n    \"a\",\n    \"b\"\n  ],\n  \"debug\": true\n}\n",
+	}, {
+		name: "Compact",
+		orig: `{"b":1,"a":{"y":2,"x":1}}`,
+		in:   `{"a":{"x":1,"y":3},"c":[],"b":1}`,
+		out:  `{"b":1,"a":{"y":3,"x":1},"c":[]}`,
+	}, {
+		name: "Tabs",
+		orig: "{\n\t\"\\u0061\": 1e2\n}",
+		in:   `{"a":100,"b":{}}`,
+		out:  "{\n\t\"\\u0061\": 1e2,\n\t\"b\": {}\n}",
+	}, {
+		name: "ChangedKind",
+		orig: `{"a": [1]}`,
+		in:   `{"a":{"x":1}}`,
+		out:  `{"a":{"x":1}}`,
+	}}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			b, err := FormatLike([]byte(tc.in), []byte(tc.orig))
+			qt.Assert(t, qt.IsNil(err))
+			qt.Assert(t, qt.Equals(string(b), tc.out))
+		})
+	}
+
+	_, err := FormatLike([]byte(`{}`), []byte(`{`))
+	qt.Assert(t, qt.ErrorMatches(err, `invalid original JSON: unexpected EOF`))
+}
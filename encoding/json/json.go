@@ -78,17 +78,48 @@ func extract(path string, b []byte) (ast.Expr, error) {
 		err := json.Unmarshal(b, &x)
 
 		// If encoding/json has a position, prefer that, as it relates to json.Unmarshal's error message.
+		var hints []errors.Hint
 		if synErr, ok := err.(*json.SyntaxError); ok && len(b) > 0 {
 			tokFile := token.NewFile(path, 0, len(b))
 			tokFile.SetLinesForContent(b)
 			p = tokFile.Pos(int(synErr.Offset-1), token.NoRelPos)
+			hints = syntaxHints(tokFile, b, int(synErr.Offset-1))
 		}
 
-		return nil, errors.Wrapf(err, p, "invalid JSON for file %q", path)
+		jsonErr := errors.WithCode(errors.Wrapf(err, p, "invalid JSON for file %q", path), errors.InvalidJSON)
+		return nil, errors.WithHints(jsonErr, hints...)
 	}
 	return expr, nil
 }
 
+// syntaxHints returns hints for common mistakes in the JSON input b
+// which cause a syntax error at offset off.
+func syntaxHints(tokFile *token.File, b []byte, off int) []errors.Hint {
+	if off < 0 || off >= len(b) {
+		return nil
+	}
+	switch b[off] {
+	case '}', ']':
+		i := off - 1
+		for i >= 0 && strings.ContainsRune(" \t\r\n", rune(b[i])) {
+			i--
+		}
+		if i < 0 || b[i] != ',' {
+			return nil
+		}
+		return []errors.Hint{{
+			Message: "remove the trailing comma, which JSON does not allow",
+			Edits: []errors.Edit{{
+				Start: tokFile.Pos(i, token.NoRelPos),
+				End:   tokFile.Pos(i+1, token.NoRelPos),
+			}},
+		}}
+	case '/':
+		return []errors.Hint{{Message: "remove the comment, which JSON does not allow"}}
+	}
+	return nil
+}
+
 // NewDecoder configures a JSON decoder. The path is used to associate position
 // information with each node. The runtime may be nil if the decoder
 // is only used to extract to CUE ast objects.
@@ -101,6 +132,7 @@ func NewDecoder(r *cue.Runtime, path string, src io.Reader) *Decoder {
 	return &Decoder{
 		path:       path,
 		dec:        json.NewDecoder(bytes.NewReader(b)),
+		src:        b,
 		tokFile:    tokFile,
 		readAllErr: err,
 	}
@@ -112,6 +144,7 @@ type Decoder struct {
 	dec  *json.Decoder
 
 	startOffset int
+	src         []byte
 	tokFile     *token.File
 	readAllErr  error
 }
@@ -139,11 +172,14 @@ func (d *Decoder) extract() (ast.Expr, error) {
 	}
 	if err != nil {
 		pos := token.NoPos
+		var hints []errors.Hint
 		// When decoding into a RawMessage, encoding/json should only error due to syntax errors.
 		if synErr, ok := err.(*json.SyntaxError); ok {
 			pos = d.tokFile.Pos(int(synErr.Offset-1), token.NoRelPos)
+			hints = syntaxHints(d.tokFile, d.src, int(synErr.Offset-1))
 		}
-		return nil, errors.Wrapf(err, pos, "invalid JSON for file %q", d.path)
+		err := errors.WithCode(errors.Wrapf(err, pos, "invalid JSON for file %q", d.path), errors.InvalidJSON)
+		return nil, errors.WithHints(err, hints...)
 	}
 	expr, err := parser.ParseExpr(d.path, []byte(raw))
 	if err != nil {
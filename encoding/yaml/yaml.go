@@ -75,6 +75,25 @@ func Encode(v cue.Value) ([]byte, error) {
 	return b, err
 }
 
+// EncodeLike is like [Encode], but formats the result like the first
+// document of the YAML stream original, where their structures match,
+// so that a file can be rewritten with a modified value without losing
+// its formatting. Comments, the order of keys, the styles of scalars,
+// mappings and sequences, and the indentation of original are retained.
+// Keys not in original follow the key preceding them in v. Unchanged
+// scalars keep their original representation, such as 'single quotes'.
+func EncodeLike(v cue.Value, original []byte) ([]byte, error) {
+	docs, err := cueyaml.Documents(original)
+	if err != nil {
+		return nil, err
+	}
+	n := v.Syntax(cue.Final())
+	if len(docs) == 0 {
+		return cueyaml.Encode(n)
+	}
+	return cueyaml.EncodeLike(n, docs[0])
+}
+
 // EncodeStream returns the YAML encoding of iter, where consecutive values
 // of iter are separated with a `---`.
 func EncodeStream(iter cue.Iterator) ([]byte, error) {
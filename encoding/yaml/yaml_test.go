@@ -242,3 +242,39 @@ func TestYAMLValues(t *testing.T) {
 		})
 	}
 }
+
+func TestEncodeLike(t *testing.T) {
+	const orig = `# Deployment settings.
+name: 'web' # the service
+replicas: 1
+ports: [80, 443]
+env:
+    DEBUG: "false"
+`
+	// The modified configuration, as it might be produced by a program.
+	v := cuecontext.New().CompileString(`
+		replicas: 3
+		name:     "web"
+		ports: [80, 443]
+		env: {
+			DEBUG: "false"
+			LOG:   "info"
+		}
+		`)
+
+	b, err := EncodeLike(v, []byte(orig))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `# Deployment settings.
+name: 'web' # the service
+replicas: 3
+ports: [80, 443]
+env:
+    DEBUG: "false"
+    LOG: info
+`
+	if got := string(b); got != want {
+		t.Errorf("got:\n%s\nwant:\n%s", got, want)
+	}
+}
@@ -5,7 +5,9 @@ go 1.23.0
 require (
 	cuelabs.dev/go/oci/ociregistry v0.0.0-20250304105642-27e071d2c9b1
 	github.com/cockroachdb/apd/v3 v3.2.1
+	github.com/dop251/goja v0.0.0-20260311135729-065cd970411c
 	github.com/emicklei/proto v1.14.0
+	github.com/fsnotify/fsnotify v1.9.0
 	github.com/go-quicktest/qt v1.101.0
 	github.com/google/go-cmp v0.7.0
 	github.com/google/shlex v0.0.0-20191202100458-e7afc7fbc510
@@ -19,6 +21,7 @@ require (
 	github.com/spf13/pflag v1.0.6
 	github.com/tetratelabs/wazero v1.6.0
 	github.com/yuin/goldmark v1.7.8
+	go.starlark.net v0.0.0-20260210143700-b62fd896b91b
 	golang.org/x/mod v0.24.0
 	golang.org/x/net v0.39.0
 	golang.org/x/oauth2 v0.29.0
@@ -30,6 +33,9 @@ require (
 )
 
 require (
+	github.com/dlclark/regexp2 v1.11.4 // indirect
+	github.com/go-sourcemap/sourcemap v2.1.3+incompatible // indirect
+	github.com/google/pprof v0.0.0-20230207041349-798e818bf904 // indirect
 	github.com/inconshreveable/mousetrap v1.1.0 // indirect
 	github.com/kr/pretty v0.3.1 // indirect
 	github.com/kr/text v0.2.0 // indirect
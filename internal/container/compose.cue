@@ -0,0 +1,371 @@
+// Copyright 2025 CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package types defines the Compose file format of the Compose
+// Specification (https://compose-spec.io), as used by compose.yaml and
+// docker-compose.yaml files.
+//
+// Fields whose values are numbers or booleans also accept strings, as
+// they may be set using variable interpolation, such as ${REPLICAS:-1}.
+package types
+
+import "strings"
+
+// #Project is the contents of a Compose file.
+#Project: {
+	// version is obsolete and ignored by Compose.
+	version?: string
+	name?:    =~"^[a-z0-9][a-z0-9_-]*$" | #Variable
+	include?: [...string | #Include]
+	services?: [#Name]: #Service
+	networks?: [#Name]: #Network | null
+	volumes?: [#Name]:  #Volume | null
+	secrets?: [#Name]:  #Secret
+	configs?: [#Name]:  #Config
+	#Extensions
+}
+
+// #Name is the name of a service or resource.
+#Name: =~"^[a-zA-Z0-9._-]+$"
+
+// #Extensions allows the x- extension fields permitted anywhere by the
+// specification.
+#Extensions: [=~"^x-"]: _
+
+// #Variable is a value set by variable interpolation.
+#Variable: strings.Contains("$")
+
+#Int:  int | #Variable
+#Bool: bool | #Variable
+
+// #Duration is a duration such as 1m30s.
+#Duration: =~"^([0-9]+(\\.[0-9]+)?(us|ms|s|m|h))+$" | #Variable
+
+// #Bytes is a byte size such as 512m.
+#Bytes: int | =~"^[0-9]+(\\.[0-9]+)?[bkmg]?b?$" | #Variable
+
+#StringOrList: string | [...string]
+
+#ListOrDict: [...string] | {[string]: string | number | bool | null}
+
+#External: bool | {
+	name?: string
+	#Extensions
+}
+
+#Include: {
+	path!:              #StringOrList
+	env_file?:          #StringOrList
+	project_directory?: string
+	#Extensions
+}
+
+// #Service is the definition of a service.
+#Service: {
+	image?: string
+	build?: string | #Build
+
+	command?:     #StringOrList | null
+	entrypoint?:  #StringOrList | null
+	working_dir?: string
+	user?:        string
+
+	environment?: #ListOrDict
+	env_file?: string | [...string | {
+		path!:     string
+		required?: #Bool
+		format?:   string
+	}]
+	labels?: #ListOrDict
+
+	ports?: [...#Port]
+	expose?: [...(string | int)]
+	volumes?: [...string | #ServiceVolume]
+	volumes_from?: [...string]
+	tmpfs?: #StringOrList
+
+	depends_on?: [...#Name] | {[#Name]: {
+		condition?: "service_started" | "service_healthy" | "service_completed_successfully"
+		restart?:   #Bool
+		required?:  #Bool
+		#Extensions
+	}}
+	links?: [...string]
+	external_links?: [...string]
+	extends?: string | {
+		service!: string
+		file?:    string
+	}
+
+	networks?: [...#Name] | {[#Name]: null | {
+		aliases?: [...string]
+		ipv4_address?: string
+		ipv6_address?: string
+		link_local_ips?: [...string]
+		mac_address?: string
+		priority?:    #Int
+		#Extensions
+	}}
+	network_mode?: string
+	hostname?:     string
+	domainname?:   string
+	mac_address?:  string
+	dns?:          #StringOrList
+	dns_search?:   #StringOrList
+	dns_opt?: [...string]
+	extra_hosts?: #ListOrDict
+
+	restart?:     "no" | "always" | "on-failure" | "unless-stopped" | =~"^on-failure:[0-9]+$" | #Variable
+	healthcheck?: #Healthcheck
+	deploy?:      #Deploy | null
+	scale?:       #Int
+	profiles?: [...string]
+	pull_policy?:    "always" | "never" | "missing" | "build" | "if_not_present" | #Variable
+	platform?:       string
+	runtime?:        string
+	init?:           #Bool
+	privileged?:     #Bool
+	read_only?:      #Bool
+	stdin_open?:     #Bool
+	tty?:            #Bool
+	container_name?: string
+
+	stop_grace_period?: #Duration
+	stop_signal?:       string
+	logging?: {
+		driver?: string
+		options?: [string]: string | number | null
+		#Extensions
+	}
+
+	secrets?: [...string | #FileReference]
+	configs?: [...string | #FileReference]
+
+	cap_add?: [...string]
+	cap_drop?: [...string]
+	security_opt?: [...string]
+	devices?: [...string]
+	group_add?: [...(string | int)]
+	sysctls?: #ListOrDict
+	ulimits?: [string]: #Int | {
+		soft!: #Int
+		hard!: #Int
+		#Extensions
+	}
+	ipc?:           string
+	pid?:           string | null
+	userns_mode?:   string
+	cgroup?:        "host" | "private"
+	cgroup_parent?: string
+	isolation?:     string
+	oom_score_adj?: #Int
+	shm_size?:      #Bytes
+	mem_limit?:     #Bytes
+	memswap_limit?: #Bytes
+	cpus?:          number | string
+	cpu_shares?:    #Int
+
+	#Extensions
+}
+
+#Build: {
+	context?:           string
+	dockerfile?:        string
+	dockerfile_inline?: string
+	args?:              #ListOrDict
+	labels?:            #ListOrDict
+	target?:            string
+	network?:           string
+	pull?:              #Bool
+	no_cache?:          #Bool
+	privileged?:        #Bool
+	shm_size?:          #Bytes
+	ssh?:               #ListOrDict
+	isolation?:         string
+	cache_from?: [...string]
+	cache_to?: [...string]
+	extra_hosts?: #ListOrDict
+	secrets?: [...string | #FileReference]
+	tags?: [...string]
+	platforms?: [...string]
+	additional_contexts?: #ListOrDict
+	#Extensions
+}
+
+#Port: int | string | {
+	target!:       #Int
+	published?:    int | string
+	host_ip?:      string
+	protocol?:     "tcp" | "udp" | #Variable
+	mode?:         "host" | "ingress" | #Variable
+	name?:         string
+	app_protocol?: string
+	#Extensions
+}
+
+#ServiceVolume: {
+	type!:        "bind" | "volume" | "tmpfs" | "cluster" | "npipe" | "image"
+	source?:      string
+	target?:      string
+	read_only?:   #Bool
+	consistency?: string
+	bind?: {
+		propagation?:      string
+		create_host_path?: #Bool
+		selinux?:          "z" | "Z"
+		#Extensions
+	}
+	volume?: {
+		nocopy?:  #Bool
+		subpath?: string
+		#Extensions
+	}
+	tmpfs?: {
+		size?: #Bytes
+		mode?: #Int
+		#Extensions
+	}
+	#Extensions
+}
+
+#FileReference: {
+	source!: string
+	target?: string
+	uid?:    string
+	gid?:    string
+	mode?:   #Int
+	#Extensions
+}
+
+#Healthcheck: {
+	test?:           #StringOrList
+	interval?:       #Duration
+	timeout?:        #Duration
+	start_period?:   #Duration
+	start_interval?: #Duration
+	retries?:        #Int
+	disable?:        #Bool
+	#Extensions
+}
+
+#Deploy: {
+	mode?:          "replicated" | "global" | "replicated-job" | "global-job" | #Variable
+	replicas?:      #Int
+	endpoint_mode?: "vip" | "dnsrr" | #Variable
+	labels?:        #ListOrDict
+	resources?: {
+		limits?: {
+			cpus?:   number | string
+			memory?: #Bytes
+			pids?:   #Int
+			#Extensions
+		}
+		reservations?: {
+			cpus?:   number | string
+			memory?: #Bytes
+			devices?: [...{
+				capabilities!: [...string]
+				driver?: string
+				count?:  #Int | "all"
+				device_ids?: [...string]
+				options?: #ListOrDict
+				#Extensions
+			}]
+			#Extensions
+		}
+		#Extensions
+	}
+	restart_policy?: {
+		condition?:    "none" | "on-failure" | "any" | #Variable
+		delay?:        #Duration
+		max_attempts?: #Int
+		window?:       #Duration
+		#Extensions
+	}
+	update_config?:   #UpdateConfig
+	rollback_config?: #UpdateConfig
+	placement?: {
+		constraints?: [...string]
+		preferences?: [...{spread!: string}]
+		max_replicas_per_node?: #Int
+		#Extensions
+	}
+	#Extensions
+}
+
+#UpdateConfig: {
+	parallelism?:       #Int
+	delay?:             #Duration
+	failure_action?:    "continue" | "rollback" | "pause" | #Variable
+	monitor?:           #Duration
+	max_failure_ratio?: number | string
+	order?:             "start-first" | "stop-first" | #Variable
+	#Extensions
+}
+
+#Network: {
+	name?:   string
+	driver?: string
+	driver_opts?: [string]: string | number
+	attachable?:  #Bool
+	enable_ipv6?: #Bool
+	internal?:    #Bool
+	external?:    #External
+	labels?:      #ListOrDict
+	ipam?: {
+		driver?: string
+		config?: [...{
+			subnet?:   string
+			ip_range?: string
+			gateway?:  string
+			aux_addresses?: [string]: string
+			#Extensions
+		}]
+		options?: [string]: string
+		#Extensions
+	}
+	#Extensions
+}
+
+#Volume: {
+	name?:   string
+	driver?: string
+	driver_opts?: [string]: string | number
+	external?: #External
+	labels?:   #ListOrDict
+	#Extensions
+}
+
+#Secret: {
+	name?:        string
+	file?:        string
+	environment?: string
+	external?:    #External
+	labels?:      #ListOrDict
+	driver?:      string
+	driver_opts?: [string]: string | number
+	template_driver?: string
+	#Extensions
+}
+
+#Config: {
+	name?:            string
+	file?:            string
+	environment?:     string
+	content?:         string
+	external?:        #External
+	labels?:          #ListOrDict
+	template_driver?: string
+	#Extensions
+}
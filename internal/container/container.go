@@ -0,0 +1,75 @@
+// Copyright 2025 CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package container holds the CUE schemas for container configuration
+// files that are built into the cue command: Docker Compose files and
+// OCI image configurations.
+package container
+
+import (
+	_ "embed"
+	"strings"
+
+	"cuelang.org/go/cue"
+)
+
+// A Package is a CUE package with schemas for a container format.
+type Package struct {
+	// ImportPath is the import path of the Go package defining the
+	// corresponding types, at which the package is put in a module's
+	// gen directory, like cue get go would.
+	ImportPath string
+
+	// Filename is the base name of the file holding the package.
+	Filename string
+
+	data string
+}
+
+//go:embed compose.cue
+var composeData string
+
+//go:embed image.cue
+var imageData string
+
+var (
+	// Compose defines the Compose file format as #Project.
+	Compose = Package{
+		ImportPath: "github.com/compose-spec/compose-go/v2/types",
+		Filename:   "compose.cue",
+		data:       composeData,
+	}
+
+	// Image defines the OCI image configuration as #Image.
+	Image = Package{
+		ImportPath: "github.com/opencontainers/image-spec/specs-go/v1",
+		Filename:   "image.cue",
+		data:       imageData,
+	}
+)
+
+// Packages lists all built-in packages.
+var Packages = []Package{Compose, Image}
+
+// Source returns the CUE source of the package, without its copyright
+// header.
+func (p Package) Source() string {
+	_, src, _ := strings.Cut(p.data, "\n\n")
+	return src
+}
+
+// Value compiles the package in ctx.
+func (p Package) Value(ctx *cue.Context) cue.Value {
+	return ctx.CompileString(p.data, cue.Filename(p.Filename))
+}
@@ -0,0 +1,130 @@
+// Copyright 2025 CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package container
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/go-quicktest/qt"
+
+	"cuelang.org/go/cue"
+	"cuelang.org/go/cue/cuecontext"
+	"cuelang.org/go/encoding/yaml"
+)
+
+func TestSchemas(t *testing.T) {
+	tests := []struct {
+		name string
+		pkg  Package
+		def  string
+		data string
+		err  string
+	}{{
+		name: "ComposeValid",
+		pkg:  Compose,
+		def:  "#Project",
+		data: `
+services:
+  web:
+    image: nginx:1.27
+    ports: ["8080:80", {target: 443, published: "${HTTPS_PORT:-8443}"}]
+    depends_on:
+      db:
+        condition: service_healthy
+    deploy:
+      replicas: ${REPLICAS:-2}
+    x-team: web
+  db:
+    image: postgres:16
+    healthcheck:
+      test: ["CMD", "pg_isready"]
+      interval: 10s
+volumes:
+  data:
+`,
+	}, {
+		name: "ComposeUnknownField",
+		pkg:  Compose,
+		def:  "#Project",
+		data: `
+services:
+  web:
+    imgae: nginx
+`,
+		err: `#Project.services.web.imgae: field not allowed`,
+	}, {
+		name: "ComposeBadCondition",
+		pkg:  Compose,
+		def:  "#Project",
+		data: `
+services:
+  web:
+    depends_on:
+      db:
+        condition: healthy
+`,
+		err: `#Project.services.web.depends_on: 5 errors in empty disjunction`,
+	}, {
+		name: "ImageValid",
+		pkg:  Image,
+		def:  "#Image",
+		data: `
+architecture: amd64
+os: linux
+created: "2025-01-02T03:04:05Z"
+config:
+  Env: ["PATH=/usr/bin"]
+  ExposedPorts: {"8080/tcp": {}}
+  Cmd: ["/app"]
+rootfs:
+  type: layers
+  diff_ids: ["sha256:2d7c1d3c1c3f5b4f4a58ed41e1b4b7c2b0a7c6f06f35a6b1b5b4a3ff4ed3d1a0"]
+container_config: {}
+`,
+	}, {
+		name: "ImageMissingRootFS",
+		pkg:  Image,
+		def:  "#Image",
+		data: `
+architecture: amd64
+os: linux
+`,
+		err: `#Image.rootfs: field is required but not present`,
+	}}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ctx := cuecontext.New()
+			schema := test.pkg.Value(ctx)
+			qt.Assert(t, qt.IsNil(schema.Err()))
+			f, err := yaml.Extract("data.yaml", test.data)
+			qt.Assert(t, qt.IsNil(err))
+			v := schema.LookupPath(cue.ParsePath(test.def)).Unify(ctx.BuildFile(f))
+			err = v.Validate(cue.Concrete(true))
+			if test.err == "" {
+				qt.Assert(t, qt.IsNil(err))
+				return
+			}
+			qt.Assert(t, qt.IsNotNil(err))
+			qt.Assert(t, qt.StringContains(err.Error(), test.err))
+		})
+	}
+}
+
+func TestSource(t *testing.T) {
+	for _, p := range Packages {
+		qt.Assert(t, qt.IsTrue(strings.HasPrefix(p.Source(), "// Package ")))
+	}
+}
@@ -0,0 +1,74 @@
+// Copyright 2025 CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package v1 defines the image configuration of the OCI Image Format
+// Specification (https://github.com/opencontainers/image-spec), with
+// media type application/vnd.oci.image.config.v1+json.
+//
+// As the specification requires implementations to ignore unknown
+// fields, such as those added by Docker, the definitions are open.
+package v1
+
+import "time"
+
+// #Image is an image configuration.
+#Image: {
+	created?:      time.Time
+	author?:       string
+	architecture!: string
+	os!:           string
+	"os.version"?: string
+	"os.features"?: [...string]
+	variant?: string
+	config?:  #ImageConfig
+	rootfs!:  #RootFS
+	history?: [...#History]
+	...
+}
+
+// #ImageConfig holds the parameters used when running a container
+// from the image.
+#ImageConfig: {
+	User?: string
+	ExposedPorts?: [=~"^[0-9]+(/(tcp|udp|sctp))?$"]: {}
+	Env?: [...=~"^[^=]+="]
+	Entrypoint?: [...string] | null
+	Cmd?: [...string] | null
+	Volumes?: [string]: {}
+	WorkingDir?: string
+	Labels?: [string]: string
+	StopSignal?:  string
+	ArgsEscaped?: bool
+	...
+}
+
+// #RootFS references the layers of the image by the digests of their
+// uncompressed content.
+#RootFS: {
+	type!: "layers"
+	diff_ids!: [...#Digest]
+}
+
+// #History describes a layer of the image.
+#History: {
+	created?:     time.Time
+	created_by?:  string
+	author?:      string
+	comment?:     string
+	empty_layer?: bool
+	...
+}
+
+// #Digest is a content digest such as sha256:4f4e...
+#Digest: =~"^[a-z0-9]+([+._-][a-z0-9]+)*:[a-zA-Z0-9=_-]+$"
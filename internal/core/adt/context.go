@@ -18,6 +18,7 @@ import (
 	"fmt"
 	"reflect"
 	"regexp"
+	"time"
 
 	"github.com/cockroachdb/apd/v3"
 	"golang.org/x/text/encoding/unicode"
@@ -72,6 +73,8 @@ func New(v *Vertex, cfg *Config) *OpContext {
 	}
 	cfg.Runtime.ConfigureOpCtx(ctx)
 	ctx.stats.EvalVersion = ctx.Version
+	ctx.profileNodes = profileNodes.Load()
+	ctx.evalProfile = profileEval.Load()
 	if v != nil {
 		ctx.e = &Environment{Up: nil, Vertex: v}
 	}
@@ -107,6 +110,23 @@ type OpContext struct {
 	stats        stats.Counts
 	freeListNode *nodeContext
 
+	// profileNodes reports whether statistics are collected per package
+	// and path. profRoot is the root value of the package being evaluated
+	// since profStart. See ProfileNodes.
+	profileNodes bool
+	profRoot     *Vertex
+	profStart    time.Time
+
+	// evalProfile reports whether an evaluation profile is collected.
+	// evalProfNode is the stack of values being evaluated since
+	// evalProfStart. See ProfileEval.
+	evalProfile   bool
+	evalProfNode  *evalProfNode
+	evalProfStart time.Time
+
+	// limits holds the limits on evaluation, if any. See SetLimits.
+	limits *limitState
+
 	e         *Environment
 	ci        CloseInfo
 	src       ast.Node
@@ -1022,6 +1042,8 @@ func (c *OpContext) lookup(x *Vertex, pos token.Pos, l Feature, flags combinedFl
 				l.Index(), len(x.Elems()))
 		default:
 			err = c.NewPosf(pos, "undefined field: %s", label)
+			r := c.Runtime
+			err.suggest = func() []string { return suggestLabels(r, l, x.Arcs) }
 		}
 		c.AddBottom(&Bottom{
 			Code:      code,
@@ -146,6 +146,9 @@ func (n *nodeContext) expandDisjuncts(
 	unreachableForDev(n.ctx)
 
 	n.ctx.stats.Disjuncts++
+	if n.ctx.limits != nil {
+		n.ctx.addDisjunctLimit(n.node)
+	}
 
 	// refNode is used to collect cyclicReferences for all disjuncts to be
 	// passed up to the parent node. Note that because the node in the parent
@@ -376,6 +376,31 @@ func (n *nodeContext) processDisjunctions() *Bottom {
 // crossProduct computes the cross product of the disjuncts of a disjunction
 // with an existing set of results.
 func (n *nodeContext) crossProduct(dst, cross []*nodeContext, dn *envDisjunct, mode runMode) []*nodeContext {
+	var prof *DisjunctionProfile
+	if profileDisjunctions.Load() {
+		prof = &DisjunctionProfile{
+			Pos:      Pos(dn.src),
+			MaxCross: int64(len(cross) * len(dn.disjuncts)),
+		}
+		defer addDisjunctionProfile(prof)
+	}
+
+	n0 := len(dst)
+	dst, pruned := n.crossProductPrune(dst, cross, dn, mode, true, prof)
+	if len(dst) == n0 && pruned {
+		// All disjuncts failed, including the ones that were pruned. Compute
+		// the pruned disjuncts anyway, so that the errors of the disjunction
+		// are the same as they would have been without pruning.
+		dst, _ = n.crossProductPrune(dst, cross, dn, mode, false, prof)
+	}
+	return dst
+}
+
+// crossProductPrune implements crossProduct. If prune is true, disjuncts which
+// are known to fail because of a discriminator field are not computed; see
+// [nodeContext.isDiscriminated]. It reports whether any disjuncts were pruned.
+// prof, if not nil, is updated with statistics on the evaluation.
+func (n *nodeContext) crossProductPrune(dst, cross []*nodeContext, dn *envDisjunct, mode runMode, prune bool, prof *DisjunctionProfile) (_ []*nodeContext, pruned bool) {
 	defer n.unmarkDepth(n.markDepth())
 	defer n.unmarkOptional(n.markOptional())
 
@@ -396,12 +421,26 @@ func (n *nodeContext) crossProduct(dst, cross []*nodeContext, dn *envDisjunct, m
 		for j, d := range dn.disjuncts {
 			ID.node.nextDisjunct(j, len(dn.disjuncts), d.expr)
 
+			if prune && p.isDiscriminated(d.expr) {
+				pruned = true
+				if prof != nil {
+					prof.Pruned++
+				}
+				continue
+			}
+			if prof != nil {
+				prof.Attempted++
+			}
+
 			c := MakeConjunct(dn.env, d.expr, dn.cloneID)
 			r, err := p.doDisjunct(c, d.mode, mode, n.node)
 
 			if err != nil {
 				// TODO: store more error context
 				dn.disjuncts[j].err = err
+				if prof != nil {
+					prof.Failed++
+				}
 				continue
 			}
 
@@ -421,7 +460,7 @@ func (n *nodeContext) crossProduct(dst, cross []*nodeContext, dn *envDisjunct, m
 		case 0:
 			r.defaultMode = combineDefault2(r.defaultMode, r.origDefaultMode, leftHasDefault, rightHasDefault)
 			// r did not have a nested disjunction.
-			dst = appendDisjunct(n.ctx, dst, r)
+			dst = appendDisjunctProf(n.ctx, dst, r, prof)
 
 		case 1:
 			panic("unexpected number of disjuncts")
@@ -434,12 +473,92 @@ func (n *nodeContext) crossProduct(dst, cross []*nodeContext, dn *envDisjunct, m
 				// not according to the spec, but may result in better user
 				// ergononmics. See Issue #1304.
 				x.defaultMode = combineDefault2(r.defaultMode, m, leftHasDefault, true)
-				dst = appendDisjunct(n.ctx, dst, x)
+				dst = appendDisjunctProf(n.ctx, dst, x, prof)
 			}
 		}
 	}
 
-	return dst
+	return dst, pruned
+}
+
+// appendDisjunctProf is like appendDisjunct, but also counts the duplicates
+// in prof, if it is not nil.
+func appendDisjunctProf(ctx *OpContext, a []*nodeContext, x *nodeContext, prof *DisjunctionProfile) []*nodeContext {
+	n := len(a)
+	a = appendDisjunct(ctx, a, x)
+	if prof != nil && x != nil && len(a) == n {
+		prof.Duplicates++
+	}
+	return a
+}
+
+// isDiscriminated reports whether the disjunct x is certain to fail when
+// unified with n, because it sets a regular field to a scalar value which
+// differs from the concrete scalar value the field of n already has, as in
+//
+//	{kind: "b"} & ({kind: "a", ...} | {kind: "b", ...})
+//
+// Values only become more specific, so a concrete scalar value is final and
+// such a disjunct can be eliminated without computing it. Only disjuncts
+// which are struct literals or evaluated structs are considered.
+func (n *nodeContext) isDiscriminated(x Expr) bool {
+	switch x := x.(type) {
+	case *StructLit:
+		for _, d := range x.Decls {
+			f, ok := d.(*Field)
+			if !ok || f.ArcType != ArcMember {
+				continue
+			}
+			if n.conflictsWithArc(f.Label, f.Value) {
+				return true
+			}
+		}
+	case *Vertex:
+		for _, a := range x.Arcs {
+			if a.ArcType != ArcMember {
+				continue
+			}
+			if v, ok := a.DerefValue().BaseValue.(Value); ok && n.conflictsWithArc(a.Label, v) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// conflictsWithArc reports whether x is a scalar value which differs from the
+// concrete scalar value of the regular field f of n. The field need not be
+// evaluated yet: if one of its conjuncts is a scalar literal, its value can
+// only be that literal.
+func (n *nodeContext) conflictsWithArc(f Feature, x Expr) bool {
+	if !isScalarValue(x) {
+		return false
+	}
+	a := n.node.Lookup(f)
+	if a == nil || a.ArcType != ArcMember {
+		return false
+	}
+	if v, ok := a.BaseValue.(Value); ok && isScalarValue(v) {
+		return !Equal(n.ctx, v, x.(Value), 0)
+	}
+	conflict := false
+	a.VisitLeafConjuncts(func(c Conjunct) bool {
+		if v := c.Expr(); isScalarValue(v) {
+			conflict = !Equal(n.ctx, v.(Value), x.(Value), 0)
+			return false
+		}
+		return true
+	})
+	return conflict
+}
+
+// isScalarValue reports whether x is a concrete scalar value.
+func isScalarValue(x Expr) bool {
+	switch x.(type) {
+	case *Null, *Bool, *Num, *String, *Bytes:
+		return true
+	}
+	return false
 }
 
 func combineDefault2(a, b defaultMode, hasDefaultA, hasDefaultB bool) defaultMode {
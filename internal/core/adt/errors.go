@@ -32,6 +32,8 @@ package adt
 //
 
 import (
+	"strings"
+
 	"cuelang.org/go/cue/ast"
 	"cuelang.org/go/cue/errors"
 	cueformat "cuelang.org/go/cue/format"
@@ -259,9 +261,13 @@ func newRequiredFieldInComprehensionError(ctx *OpContext, x *ForClause, v *Verte
 }
 
 func (v *Vertex) reportFieldIndexError(c *OpContext, pos token.Pos, f Feature) {
-	v.reportFieldError(c, pos, f,
+	b := v.reportFieldError(c, pos, f,
 		"index out of range [%d] with length %d",
 		"undefined field: %s")
+	if err, ok := b.Err.(*ValueError); ok && !f.IsInt() {
+		r := c.Runtime
+		err.suggest = func() []string { return suggestLabels(r, f, v.Arcs) }
+	}
 }
 
 func (v *Vertex) reportFieldCycleError(c *OpContext, pos token.Pos, f Feature) *Bottom {
@@ -302,6 +308,9 @@ type ValueError struct {
 	auxpos  []token.Pos
 	altPath []string
 	errors.Message
+
+	// suggest, if set, computes alternatives for a mistyped label.
+	suggest func() []string
 }
 
 func (v *ValueError) AddPosition(n Node) {
@@ -440,3 +449,89 @@ func (e *ValueError) Path() (a []string) {
 	}
 	return a
 }
+
+// Suggestions returns labels similar to the one which caused the error,
+// for use in "did you mean" messages, if there are any.
+func (e *ValueError) Suggestions() []string {
+	if e.suggest == nil {
+		return nil
+	}
+	return e.suggest()
+}
+
+// Hints returns ways to fix the error, if any are known for its kind.
+func (e *ValueError) Hints() []errors.Hint {
+	var msg string
+	switch e.Code() {
+	case errors.FieldNotAllowed:
+		if len(e.Suggestions()) > 0 {
+			// A mistyped label is the more likely cause.
+			return nil
+		}
+		msg = "declare the field in the closed struct, or add ... to it to allow any field"
+	case errors.RequiredFieldMissing:
+		msg = "set the required field, marked with ! in its declaration"
+	default:
+		return nil
+	}
+	return []errors.Hint{{Message: msg}}
+}
+
+// suggestLabels returns the labels of arcs which are similar to f and of
+// the same type, such as a definition for a definition.
+func suggestLabels(r Runtime, f Feature, arcs []*Vertex) []string {
+	var names []string
+	for _, a := range arcs {
+		if a.Label.Typ() == f.Typ() && a.definitelyExists() {
+			names = append(names, a.Label.SelectorString(r))
+		}
+	}
+	return Suggest(f.SelectorString(r), names)
+}
+
+// errorCodes maps the messages of evaluation errors, by the prefix of
+// their format string, to their stable codes. More specific prefixes
+// must come before more general ones.
+var errorCodes = []struct {
+	prefix string
+	code   errors.Code
+}{
+	{"conflicting values", errors.ConflictingValues},
+	{"incompatible ", errors.ConflictingValues},
+	{"invalid value %v (out of bound", errors.OutOfBound},
+	{"invalid value %s (does not satisfy", errors.ValidatorFailed},
+	{"field not allowed", errors.FieldNotAllowed},
+	{"explicit error", errors.ExplicitError},
+	{"invalid operand %s ('%s' requires concrete value)", errors.NonConcreteValue},
+	{"invalid operand", errors.InvalidOperation},
+	{"invalid operation", errors.InvalidOperation},
+	{"division by zero", errors.InvalidOperation},
+	{"failed arithmetic", errors.InvalidOperation},
+	{"index ", errors.IndexOutOfRange},
+	{"invalid list index", errors.IndexOutOfRange},
+	{"invalid slice index", errors.IndexOutOfRange},
+	{"undefined field", errors.UndefinedField},
+	{"reference %q not found", errors.ReferenceNotFound},
+	{"unresolved identifier", errors.ReferenceNotFound},
+	{"empty disjunction", errors.EmptyDisjunction},
+	{"non-concrete", errors.NonConcreteValue},
+	{"incomplete argument", errors.NonConcreteValue},
+	{"incomplete value", errors.IncompleteValue},
+	{"field is required but not present", errors.RequiredFieldMissing},
+	{"missing required field", errors.RequiredFieldMissing},
+	{"unresolved disjunction", errors.UnresolvedDisjunction},
+	{"structural cycle", errors.StructuralCycle},
+	{"cycle ", errors.ReferenceCycle},
+	{"cyclic ", errors.ReferenceCycle},
+}
+
+// Code returns the stable code of the error, if it has one.
+func (e *ValueError) Code() errors.Code {
+	format, _ := e.Msg()
+	for _, c := range errorCodes {
+		if strings.HasPrefix(format, c.prefix) {
+			return c.code
+		}
+	}
+	return ""
+}
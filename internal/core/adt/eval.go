@@ -224,6 +224,9 @@ func (c *OpContext) unify(v *Vertex, flags combinedFlags) {
 		}
 
 		c.stats.Unifications++
+		if c.limits != nil {
+			c.addNodeLimit(v)
+		}
 
 		// Set the cache to a cycle error to ensure a cyclic reference will result
 		// in an error if applicable. A cyclic error may be ignored for
@@ -65,6 +65,14 @@ type StringIndexer interface {
 	NextUniqueID() uint64
 }
 
+// A StringInterner returns a canonical copy of a string, so that equal
+// strings share memory. A StringIndexer may optionally implement it.
+type StringInterner interface {
+	// InternString returns a string equal to s. It returns the same
+	// string for subsequent calls with equal strings.
+	InternString(s string) string
+}
+
 // SelectorString reports the shortest string representation of f when used as a
 // selector.
 func (f Feature) SelectorString(index StringIndexer) string {
@@ -365,8 +365,9 @@ func (ctx *OpContext) addPositions(c Conjunct) {
 }
 
 // notAllowedError reports a field not allowed error in n and sets the value
-// for arc f to that error.
-func (ctx *OpContext) notAllowedError(arc *Vertex) *Bottom {
+// for arc f to that error. The labels of the allowed arcs are used to suggest
+// alternatives for arc.
+func (ctx *OpContext) notAllowedError(arc *Vertex, allowed []*Vertex) *Bottom {
 	defer ctx.PopArc(ctx.PushArc(arc))
 
 	defer ctx.ReleasePositions(ctx.MarkPositions())
@@ -398,6 +399,10 @@ func (ctx *OpContext) notAllowedError(arc *Vertex) *Bottom {
 	// desirable or not, but it differs, at least from <=v0.6 behavior.
 	err := ctx.NewErrf("field not allowed")
 	err.CloseCheck = true
+	if verr, ok := err.Err.(*ValueError); ok {
+		r := ctx.Runtime
+		verr.suggest = func() []string { return suggestLabels(r, arc.Label, allowed) }
+	}
 	arc.SetValue(ctx, err)
 	if arc.state != nil {
 		arc.state.kind = 0
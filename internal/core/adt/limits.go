@@ -0,0 +1,154 @@
+// Copyright 2025 CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package adt
+
+import (
+	"fmt"
+	"time"
+
+	"cuelang.org/go/cue/token"
+)
+
+// Limits bounds the resources which may be used by each evaluation, that
+// is, by each OpContext. A zero value for a field means that there is no
+// limit.
+type Limits struct {
+	// Timeout is the time after which evaluation is aborted, counting from
+	// the creation of the OpContext.
+	Timeout time.Duration
+
+	// MaxNodes is the maximum number of unifications, as counted by
+	// [stats.Counts.Unifications].
+	MaxNodes int64
+
+	// MaxDisjuncts is the maximum number of disjuncts, as counted by
+	// [stats.Counts.Disjuncts].
+	MaxDisjuncts int64
+}
+
+// A LimitKind identifies one of the fields of Limits.
+type LimitKind int
+
+const (
+	TimeoutLimit LimitKind = iota + 1
+	NodeLimit
+	DisjunctLimit
+)
+
+// A LimitError reports that evaluation exceeded a limit set with
+// [OpContext.SetLimits].
+//
+// The evaluator cannot recover from exceeding a limit, as it leaves values
+// partially evaluated. It therefore panics with a *LimitError. Every
+// goroutine which evaluates with limits must recover from it, and should
+// stop using the values of the Runtime afterwards.
+type LimitError struct {
+	Kind LimitKind
+
+	// Limit describes the limit which was exceeded, such as
+	// "the limit of 1000 nodes".
+	Limit string
+
+	// Path is the path of the value which was being evaluated when the
+	// limit was exceeded, and Pos the position of its first conjunct.
+	Path string
+	Pos  token.Pos
+}
+
+func (e *LimitError) Error() string {
+	path := e.Path
+	if path == "" {
+		path = "the root value"
+	}
+	return fmt.Sprintf("evaluation exceeded %s while evaluating %s", e.Limit, path)
+}
+
+type limitState struct {
+	Limits
+	deadline  time.Time
+	nodes     int64
+	disjuncts int64
+
+	// exceeded holds the first limit that was exceeded, so that all
+	// subsequent checks report the same error.
+	exceeded *LimitError
+}
+
+// SetLimits sets the limits on evaluation with c, resetting any counts and
+// starting the timeout. The zero Limits removes all limits. It is
+// typically called by [Runtime.ConfigureOpCtx].
+//
+// When a limit is exceeded, the evaluator panics with a *[LimitError].
+func (c *OpContext) SetLimits(l Limits) {
+	if l == (Limits{}) {
+		c.limits = nil
+		return
+	}
+	s := &limitState{Limits: l}
+	if l.Timeout > 0 {
+		s.deadline = time.Now().Add(l.Timeout)
+	}
+	c.limits = s
+}
+
+// deadlineInterval is how often, in unifications or disjuncts, the
+// deadline is checked, to keep the cost of reading the clock low.
+const deadlineInterval = 256
+
+// addNodeLimit counts a unification of v towards the limits.
+func (c *OpContext) addNodeLimit(v *Vertex) {
+	s := c.limits
+	s.nodes++
+	n := s.nodes
+	if s.MaxNodes > 0 && n > s.MaxNodes {
+		c.exceedLimit(v, NodeLimit, fmt.Sprintf("the limit of %d nodes", s.MaxNodes))
+	}
+	if n%deadlineInterval == 0 {
+		c.checkDeadline(v)
+	}
+}
+
+// addDisjunctLimit counts a disjunct of v towards the limits.
+func (c *OpContext) addDisjunctLimit(v *Vertex) {
+	s := c.limits
+	s.disjuncts++
+	n := s.disjuncts
+	if s.MaxDisjuncts > 0 && n > s.MaxDisjuncts {
+		c.exceedLimit(v, DisjunctLimit, fmt.Sprintf("the limit of %d disjuncts", s.MaxDisjuncts))
+	}
+	if n%deadlineInterval == 0 {
+		c.checkDeadline(v)
+	}
+}
+
+func (c *OpContext) checkDeadline(v *Vertex) {
+	s := c.limits
+	if !s.deadline.IsZero() && time.Now().After(s.deadline) {
+		c.exceedLimit(v, TimeoutLimit, fmt.Sprintf("the timeout of %v", s.Timeout))
+	}
+}
+
+func (c *OpContext) exceedLimit(v *Vertex, kind LimitKind, limit string) {
+	s := c.limits
+	if s.exceeded == nil {
+		s.exceeded = &LimitError{
+			Kind:  kind,
+			Limit: limit,
+			Path:  c.PathToString(v.Path()),
+			Pos:   vertexPos(v),
+		}
+	}
+	panic(s.exceeded)
+}
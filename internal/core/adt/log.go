@@ -217,6 +217,12 @@ func (n *nodeContext) nextDisjunct(index, num int, expr Node) {
 func (n *nodeContext) logDoDisjunct() *disjunctInfo {
 	c := n.ctx
 	c.stats.Disjuncts++
+	if c.limits != nil {
+		c.addDisjunctLimit(n.node)
+	}
+	if c.profileNodes {
+		c.profileNode(n.node, 0, 1)
+	}
 
 	d := c.currentDisjunct()
 
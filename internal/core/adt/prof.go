@@ -15,9 +15,15 @@
 package adt
 
 import (
+	"cmp"
+	"maps"
+	"slices"
 	"sync"
+	"sync/atomic"
+	"time"
 
 	"cuelang.org/go/cue/stats"
+	"cuelang.org/go/cue/token"
 )
 
 // This file contains stats and profiling functionality.
@@ -26,6 +32,12 @@ var (
 	// counts is a temporary and internal solution for collecting global stats. It is protected with a mutex.
 	counts   stats.Counts
 	countsMu sync.Mutex
+
+	// internedStrings and internedBytes count the savings of string
+	// interning. They are updated during compilation, outside of any
+	// OpContext, and are therefore kept separately from counts.
+	internedStrings atomic.Int64
+	internedBytes   atomic.Int64
 )
 
 // ResetStats sets the global stats counters to zero.
@@ -33,6 +45,8 @@ func ResetStats() {
 	countsMu.Lock()
 	counts = stats.Counts{}
 	countsMu.Unlock()
+	internedStrings.Store(0)
+	internedBytes.Store(0)
 }
 
 // AddStats adds the stats of the given OpContext to the global
@@ -50,5 +64,446 @@ func TotalStats() stats.Counts {
 	// Shallow copy suffices as it only contains counter fields.
 	s := counts
 	countsMu.Unlock()
+	s.InternedStrings += internedStrings.Load()
+	s.InternedBytes += internedBytes.Load()
 	return s
 }
+
+// AddInternedString records that a string of n bytes shares the memory of an
+// earlier, equal string.
+func AddInternedString(n int) {
+	internedStrings.Add(1)
+	internedBytes.Add(int64(n))
+}
+
+// A DisjunctionProfile holds statistics on the evaluation of the disjunctions
+// at a single position, aggregated over all evaluations.
+type DisjunctionProfile struct {
+	Pos token.Pos
+
+	// Evaluations is the number of times the disjunction was evaluated
+	// as part of a cross product.
+	Evaluations int64
+
+	// Attempted is the number of disjuncts which were computed, that is,
+	// the number of results of the disjunction so far times its number of
+	// disjuncts, summed over all evaluations, minus the pruned disjuncts.
+	Attempted int64
+
+	// Pruned is the number of disjuncts which were eliminated without
+	// computing them, because they set a field to a value which conflicts
+	// with a concrete value that the field already has.
+	Pruned int64
+
+	// Failed is the number of computed disjuncts which resulted in an error.
+	Failed int64
+
+	// Duplicates is the number of computed disjuncts which were eliminated
+	// because they were equal to another disjunct.
+	Duplicates int64
+
+	// MaxCross is the largest number of combinations computed in a single
+	// evaluation of the disjunction.
+	MaxCross int64
+}
+
+var (
+	profileDisjunctions atomic.Bool
+	disjunctionProfiles map[token.Pos]*DisjunctionProfile
+	disjunctionsMu      sync.Mutex
+)
+
+// ProfileDisjunctions enables or disables the collection of statistics on
+// the evaluation of disjunctions. Enabling it discards any statistics that
+// were collected before. Collecting them is relatively expensive.
+func ProfileDisjunctions(enable bool) {
+	if enable {
+		disjunctionsMu.Lock()
+		disjunctionProfiles = map[token.Pos]*DisjunctionProfile{}
+		disjunctionsMu.Unlock()
+	}
+	profileDisjunctions.Store(enable)
+}
+
+// DisjunctionProfiles returns the statistics collected since disjunction
+// profiling was enabled, ordered from the disjunctions which caused the most
+// work to those which caused the least.
+func DisjunctionProfiles() []DisjunctionProfile {
+	disjunctionsMu.Lock()
+	defer disjunctionsMu.Unlock()
+	a := make([]DisjunctionProfile, 0, len(disjunctionProfiles))
+	for _, p := range disjunctionProfiles {
+		a = append(a, *p)
+	}
+	slices.SortFunc(a, func(a, b DisjunctionProfile) int {
+		if c := cmp.Compare(b.Attempted, a.Attempted); c != 0 {
+			return c
+		}
+		return a.Pos.Compare(b.Pos)
+	})
+	return a
+}
+
+// addDisjunctionProfile adds the statistics in p of a single evaluation
+// of the disjunction at p.Pos.
+func addDisjunctionProfile(p *DisjunctionProfile) {
+	disjunctionsMu.Lock()
+	defer disjunctionsMu.Unlock()
+	q := disjunctionProfiles[p.Pos]
+	if q == nil {
+		q = &DisjunctionProfile{Pos: p.Pos}
+		disjunctionProfiles[p.Pos] = q
+	}
+	q.Evaluations++
+	q.Attempted += p.Attempted
+	q.Pruned += p.Pruned
+	q.Failed += p.Failed
+	q.Duplicates += p.Duplicates
+	q.MaxCross = max(q.MaxCross, p.MaxCross)
+}
+
+// A PackageProfile holds statistics on the evaluation of the values of a
+// single package.
+type PackageProfile struct {
+	// Path is the import path of the package, or the empty string for
+	// values which do not belong to a known package, such as those of
+	// builtin packages.
+	Path string
+
+	// Unifications and Disjuncts are the number of unifications and
+	// disjuncts, as counted in [stats.Counts], of values in the package.
+	Unifications int64
+	Disjuncts    int64
+
+	// Time is the time spent evaluating values in the package, excluding
+	// the time spent evaluating values of other packages it refers to.
+	Time time.Duration
+}
+
+// A PathProfile holds statistics on the evaluation of the value at a
+// single path within a package.
+type PathProfile struct {
+	Package string
+	Path    string
+
+	Unifications int64
+	Disjuncts    int64
+}
+
+// pathKey identifies a path within the package with the given root value.
+// The import path of a package is only determined when the profiles are
+// reported, as a Runtime may not be able to report it during evaluation.
+type pathKey struct {
+	root *Vertex
+	path string
+}
+
+var (
+	profileNodes    atomic.Bool
+	packageProfiles map[*Vertex]*PackageProfile
+	pathProfiles    map[pathKey]*PathProfile
+	nodesMu         sync.Mutex
+)
+
+// ProfileNodes enables or disables the collection of statistics per
+// package and per path. Enabling it discards any statistics that were
+// collected before. It only affects OpContexts created afterwards.
+// Collecting them is relatively expensive.
+func ProfileNodes(enable bool) {
+	if enable {
+		nodesMu.Lock()
+		packageProfiles = map[*Vertex]*PackageProfile{}
+		pathProfiles = map[pathKey]*PathProfile{}
+		nodesMu.Unlock()
+	}
+	profileNodes.Store(enable)
+}
+
+// A PackagePather reports the import path of the package with the given
+// root value. A Runtime may optionally implement it to allow statistics to
+// be attributed to packages.
+type PackagePather interface {
+	PackagePath(root *Vertex) string
+}
+
+func packagePath(r Runtime, root *Vertex) string {
+	if p, ok := r.(PackagePather); ok {
+		return p.PackagePath(root)
+	}
+	return ""
+}
+
+// PackageProfiles returns the statistics per package collected since node
+// profiling was enabled, ordered by import path. The import paths are
+// determined using r.
+func PackageProfiles(r Runtime) []PackageProfile {
+	nodesMu.Lock()
+	defer nodesMu.Unlock()
+	m := map[string]*PackageProfile{}
+	for root, p := range packageProfiles {
+		path := packagePath(r, root)
+		q := m[path]
+		if q == nil {
+			q = &PackageProfile{Path: path}
+			m[path] = q
+		}
+		q.Unifications += p.Unifications
+		q.Disjuncts += p.Disjuncts
+		q.Time += p.Time
+	}
+	a := make([]PackageProfile, 0, len(m))
+	for _, p := range m {
+		a = append(a, *p)
+	}
+	slices.SortFunc(a, func(a, b PackageProfile) int {
+		return cmp.Compare(a.Path, b.Path)
+	})
+	return a
+}
+
+// PathProfiles returns the statistics of at most n paths collected since
+// node profiling was enabled, ordered from the paths which caused the most
+// work to those which caused the least. The import paths of their packages
+// are determined using r; paths of values which do not belong to a known
+// package are omitted.
+func PathProfiles(r Runtime, n int) []PathProfile {
+	nodesMu.Lock()
+	defer nodesMu.Unlock()
+	m := map[[2]string]*PathProfile{}
+	for key, p := range pathProfiles {
+		pkg := packagePath(r, key.root)
+		if pkg == "" {
+			continue
+		}
+		q := m[[2]string{pkg, key.path}]
+		if q == nil {
+			q = &PathProfile{Package: pkg, Path: key.path}
+			m[[2]string{pkg, key.path}] = q
+		}
+		q.Unifications += p.Unifications
+		q.Disjuncts += p.Disjuncts
+	}
+	a := make([]PathProfile, 0, len(m))
+	for _, p := range m {
+		a = append(a, *p)
+	}
+	slices.SortFunc(a, func(a, b PathProfile) int {
+		if c := cmp.Compare(b.Unifications+b.Disjuncts, a.Unifications+a.Disjuncts); c != 0 {
+			return c
+		}
+		if c := cmp.Compare(a.Package, b.Package); c != 0 {
+			return c
+		}
+		return cmp.Compare(a.Path, b.Path)
+	})
+	if len(a) > n {
+		a = a[:n]
+	}
+	return a
+}
+
+func rootVertex(v *Vertex) *Vertex {
+	for v.Parent != nil {
+		v = v.Parent
+	}
+	return v
+}
+
+// profileNode records a unification or disjunct of v.
+func (c *OpContext) profileNode(v *Vertex, unifications, disjuncts int64) {
+	root := rootVertex(v)
+	key := pathKey{root, c.PathToString(v.Path())}
+
+	nodesMu.Lock()
+	defer nodesMu.Unlock()
+	p := packageProfiles[root]
+	if p == nil {
+		p = &PackageProfile{}
+		packageProfiles[root] = p
+	}
+	p.Unifications += unifications
+	p.Disjuncts += disjuncts
+
+	q := pathProfiles[key]
+	if q == nil {
+		q = &PathProfile{}
+		pathProfiles[key] = q
+	}
+	q.Unifications += unifications
+	q.Disjuncts += disjuncts
+}
+
+// profilePackage attributes the time spent evaluating v to its package
+// until the returned function is called.
+func (c *OpContext) profilePackage(v *Vertex) func() {
+	root := rootVertex(v)
+	prev := c.profRoot
+	if root == prev {
+		return func() {}
+	}
+	c.addPackageTime(prev)
+	c.profRoot = root
+	return func() {
+		c.addPackageTime(root)
+		c.profRoot = prev
+	}
+}
+
+// addPackageTime attributes the time since the last call to the package
+// with the given root value.
+func (c *OpContext) addPackageTime(root *Vertex) {
+	now := time.Now()
+	d := now.Sub(c.profStart)
+	c.profStart = now
+	if root == nil {
+		return
+	}
+
+	nodesMu.Lock()
+	defer nodesMu.Unlock()
+	p := packageProfiles[root]
+	if p == nil {
+		p = &PackageProfile{}
+		packageProfiles[root] = p
+	}
+	p.Time += d
+}
+
+// An EvalFrame identifies a value which the evaluator is working on, for
+// the purpose of an evaluation profile.
+type EvalFrame struct {
+	// Path is the path of the value within its package.
+	Path string
+
+	// Pos is the position of the first conjunct of the value.
+	Pos token.Pos
+}
+
+// An EvalSample holds the time spent evaluating the innermost value of a
+// stack of values, where each value was being evaluated as a result of
+// evaluating the next.
+type EvalSample struct {
+	// Stack holds the frames of the values, innermost first.
+	Stack []EvalFrame
+
+	// Count is the number of times the innermost value was evaluated with
+	// this stack.
+	Count int64
+
+	// Time is the time spent evaluating the innermost value with this
+	// stack, excluding the time spent on values which it caused to be
+	// evaluated.
+	Time time.Duration
+}
+
+// An evalProfNode is a node in the tree of stacks of an evaluation profile.
+type evalProfNode struct {
+	frame    EvalFrame
+	parent   *evalProfNode
+	children map[EvalFrame]*evalProfNode
+	count    int64
+	time     time.Duration
+}
+
+func (n *evalProfNode) child(f EvalFrame) *evalProfNode {
+	c := n.children[f]
+	if c == nil {
+		if n.children == nil {
+			n.children = map[EvalFrame]*evalProfNode{}
+		}
+		c = &evalProfNode{frame: f, parent: n}
+		n.children[f] = c
+	}
+	return c
+}
+
+var (
+	profileEval  atomic.Bool
+	evalProfRoot *evalProfNode
+	evalProfMu   sync.Mutex
+)
+
+// ProfileEval enables or disables the collection of an evaluation profile,
+// which records the time spent evaluating each value by the stack of
+// values that caused it to be evaluated. Enabling it discards any profile
+// that was collected before. It only affects OpContexts created afterwards.
+// Collecting it is relatively expensive.
+func ProfileEval(enable bool) {
+	if enable {
+		evalProfMu.Lock()
+		evalProfRoot = &evalProfNode{}
+		evalProfMu.Unlock()
+	}
+	profileEval.Store(enable)
+}
+
+// EvalProfile returns the evaluation profile collected since evaluation
+// profiling was enabled, ordered by stack.
+func EvalProfile() []EvalSample {
+	evalProfMu.Lock()
+	defer evalProfMu.Unlock()
+	var a []EvalSample
+	var walk func(n *evalProfNode)
+	walk = func(n *evalProfNode) {
+		if n.count > 0 {
+			var stack []EvalFrame
+			for p := n; p.parent != nil; p = p.parent {
+				stack = append(stack, p.frame)
+			}
+			a = append(a, EvalSample{Stack: stack, Count: n.count, Time: n.time})
+		}
+		keys := slices.SortedFunc(maps.Keys(n.children), func(a, b EvalFrame) int {
+			if c := cmp.Compare(a.Path, b.Path); c != 0 {
+				return c
+			}
+			return a.Pos.Compare(b.Pos)
+		})
+		for _, k := range keys {
+			walk(n.children[k])
+		}
+	}
+	if evalProfRoot != nil {
+		walk(evalProfRoot)
+	}
+	return a
+}
+
+// vertexPos returns the position of the first conjunct of v which has one.
+func vertexPos(v *Vertex) token.Pos {
+	for _, c := range v.Conjuncts {
+		if src := c.Source(); src != nil && src.Pos().IsValid() {
+			return src.Pos()
+		}
+	}
+	return token.NoPos
+}
+
+// profileEvalFrame attributes the time spent evaluating v, by the stack of
+// values being evaluated, until the returned function is called.
+func (c *OpContext) profileEvalFrame(v *Vertex) func() {
+	f := EvalFrame{Path: c.PathToString(v.Path()), Pos: vertexPos(v)}
+
+	evalProfMu.Lock()
+	defer evalProfMu.Unlock()
+	prev := c.evalProfNode
+	parent := prev
+	if parent == nil {
+		parent = evalProfRoot
+	} else if parent.frame == f {
+		return func() {}
+	}
+	now := time.Now()
+	if prev != nil {
+		prev.time += now.Sub(c.evalProfStart)
+	}
+	n := parent.child(f)
+	n.count++
+	c.evalProfNode, c.evalProfStart = n, now
+	return func() {
+		evalProfMu.Lock()
+		defer evalProfMu.Unlock()
+		now := time.Now()
+		n.time += now.Sub(c.evalProfStart)
+		c.evalProfNode, c.evalProfStart = prev, now
+	}
+}
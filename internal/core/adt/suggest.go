@@ -0,0 +1,85 @@
+// Copyright 2025 CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package adt
+
+import (
+	"slices"
+	"strings"
+)
+
+// maxSuggestions is the maximum number of suggestions returned by Suggest.
+const maxSuggestions = 3
+
+// Suggest returns the candidates which are most similar to name, for use in
+// "did you mean" suggestions, or nil if none are similar enough. A candidate
+// is similar if it differs from name by at most one edit, such as inserting,
+// deleting, replacing, or swapping adjacent characters, for every three
+// characters of name. Case differences alone always count as similar.
+func Suggest(name string, candidates []string) []string {
+	best := -1
+	var a []string
+	for _, c := range candidates {
+		if c == name || slices.Contains(a, c) {
+			continue
+		}
+		d := editDistance(name, c)
+		if strings.EqualFold(name, c) {
+			d = 0
+		}
+		if d*3 > len(name) && d > 0 {
+			continue
+		}
+		switch {
+		case best < 0 || d < best:
+			best = d
+			a = append(a[:0], c)
+		case d == best:
+			a = append(a, c)
+		}
+	}
+	slices.Sort(a)
+	if len(a) > maxSuggestions {
+		a = a[:maxSuggestions]
+	}
+	return a
+}
+
+// editDistance returns the optimal string alignment distance between a
+// and b: the number of insertions, deletions, substitutions, and
+// transpositions of adjacent bytes needed to change a into b.
+func editDistance(a, b string) int {
+	// d[i][j] is the distance between a[:i] and b[:j].
+	d := make([][]int, len(a)+1)
+	for i := range d {
+		d[i] = make([]int, len(b)+1)
+		d[i][0] = i
+	}
+	for j := range d[0] {
+		d[0][j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			d[i][j] = min(d[i-1][j]+1, d[i][j-1]+1, d[i-1][j-1]+cost)
+			if i > 1 && j > 1 && a[i-1] == b[j-2] && a[i-2] == b[j-1] {
+				d[i][j] = min(d[i][j], d[i-2][j-2]+1)
+			}
+		}
+	}
+	return d[len(a)][len(b)]
+}
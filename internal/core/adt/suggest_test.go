@@ -0,0 +1,41 @@
+// Copyright 2025 CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package adt
+
+import (
+	"testing"
+
+	"github.com/go-quicktest/qt"
+)
+
+func TestSuggest(t *testing.T) {
+	candidates := []string{"name", "names", "namespace", "replicas", "Image", "a", "b"}
+	tests := []struct {
+		name string
+		want []string
+	}{
+		{"nmae", []string{"name"}},
+		{"nam", []string{"name"}},
+		{"replcas", []string{"replicas"}},
+		{"image", []string{"Image"}},
+		{"nameSpace", []string{"namespace"}},
+		{"c", nil},                  // too short to be similar
+		{"foobar", nil},             // nothing similar
+		{"name", []string{"names"}}, // exact matches are not suggested
+	}
+	for _, tc := range tests {
+		qt.Check(t, qt.DeepEquals(Suggest(tc.name, candidates), tc.want), qt.Commentf("%s", tc.name))
+	}
+}
@@ -489,7 +489,7 @@ func (n *nodeContext) checkTypos() {
 	requiredCopy := make(reqSets, 0, len(required))
 	var replacements []replaceID
 
-	var err *Bottom
+	var notAllowed, allowed []*Vertex
 	// outer:
 	for _, a := range v.Arcs {
 		f := a.Label
@@ -520,13 +520,18 @@ func (n *nodeContext) checkTypos() {
 		}
 
 		if n.hasEvidenceForAll(required, na.conjunctInfo) {
+			allowed = append(allowed, a)
 			continue
 		}
+		notAllowed = append(notAllowed, a)
+	}
 
+	var err *Bottom
+	for _, a := range notAllowed {
 		// TODO: do not descend on optional?
 
 		// openDebugGraph(ctx, a, "NOT ALLOWED") // Uncomment for debugging.
-		if b := ctx.notAllowedError(a); b != nil && a.ArcType <= ArcRequired {
+		if b := ctx.notAllowedError(a, allowed); b != nil && a.ArcType <= ArcRequired {
 			err = CombineErrors(nil, err, b)
 		}
 	}
@@ -94,6 +94,12 @@ func (n *nodeContext) scheduleConjuncts() {
 	ctx := n.ctx
 
 	ctx.stats.Unifications++
+	if ctx.limits != nil {
+		ctx.addNodeLimit(v)
+	}
+	if ctx.profileNodes {
+		ctx.profileNode(v, 1, 0)
+	}
 
 	// Set the cache to a cycle error to ensure a cyclic reference will result
 	// in an error if applicable. A cyclic error may be ignored for
@@ -149,6 +155,13 @@ func (v *Vertex) unify(c *OpContext, needs condition, mode runMode, checkTypos b
 		return false
 	}
 
+	if c.profileNodes {
+		defer c.profilePackage(v)()
+	}
+	if c.evalProfile {
+		defer c.profileEvalFrame(v)()
+	}
+
 	// Note that the state of a node can be removed before the node is.
 	// This happens with the close builtin, for instance.
 	// See TestFromAPI in pkg export.
@@ -15,6 +15,7 @@
 package compile
 
 import (
+	"slices"
 	"strings"
 
 	"cuelang.org/go/cue/ast"
@@ -327,6 +328,56 @@ func (c *compiler) compileExpr(x ast.Expr) adt.Conjunct {
 	return adt.MakeRootConjunct(env, expr)
 }
 
+// predeclaredNames lists the predeclared identifiers which are suggested
+// for unresolved references.
+var predeclaredNames = []string{
+	"string", "bytes", "bool", "int", "float", "number",
+	"len", "close", "matchIf", "matchN", "and", "or", "div", "mod", "quo", "rem",
+}
+
+// identsInScope returns the names of the identifiers which may be
+// referenced at the current position, for suggesting alternatives for
+// an unresolved reference.
+func (c *compiler) identsInScope() []string {
+	names := slices.Clone(predeclaredNames)
+	addLabel := func(l ast.Label) {
+		if id, ok := l.(*ast.Ident); ok {
+			names = append(names, id.Name)
+		}
+	}
+	for _, f := range c.stack {
+		var decls []ast.Decl
+		switch x := f.scope.(type) {
+		case *ast.File:
+			decls = x.Decls
+		case *ast.StructLit:
+			decls = x.Elts
+		}
+		for _, d := range decls {
+			switch x := d.(type) {
+			case *ast.Field:
+				addLabel(x.Label)
+			case *ast.LetClause:
+				names = append(names, x.Ident.Name)
+			}
+		}
+		for name := range f.aliases {
+			names = append(names, name)
+		}
+	}
+	for f := range c.fileScope {
+		names = append(names, f.IdentString(c.index))
+	}
+	for p := c.Scope; p != nil; p = p.Parent() {
+		for _, a := range p.Vertex().Arcs {
+			if !a.Label.IsInt() {
+				names = append(names, a.Label.IdentString(c.index))
+			}
+		}
+	}
+	return names
+}
+
 // resolve assumes that all existing resolutions are legal. Validation should
 // be done in a separate step if required.
 //
@@ -393,7 +444,11 @@ func (c *compiler) resolve(n *ast.Ident) adt.Expr {
 			return p
 		}
 
-		return c.errf(n, "reference %q not found", n.Name)
+		b := c.errf(n, "reference %q not found", n.Name)
+		err := b.Err.(*compilerError)
+		err.code = errors.ReferenceNotFound
+		err.suggestions = adt.Suggest(n.Name, c.identsInScope())
+		return b
 	}
 
 	//   X in [X=x]: y  Scope: Field  Node: Expr (x)
@@ -924,7 +979,7 @@ func (c *compiler) expr(expr ast.Expr) adt.Expr {
 		return &adt.Bottom{
 			Src:  n,
 			Code: adt.UserError,
-			Err:  errors.Newf(n.Pos(), "explicit error (_|_ literal) in source"),
+			Err:  errors.WithCode(errors.Newf(n.Pos(), "explicit error (_|_ literal) in source"), errors.ExplicitError),
 		}
 
 	case *ast.BadExpr:
@@ -1111,6 +1166,9 @@ func parseString(c *compiler, node ast.Expr, q literal.QuoteInfo, s string) (n a
 		return c.errf(node, "invalid string: %v", err)
 	}
 	if q.IsDouble() {
+		if in, ok := c.index.(adt.StringInterner); ok {
+			str = in.InternString(str)
+		}
 		return &adt.String{Src: node, Str: str, RE: nil}
 	}
 	return &adt.Bytes{Src: node, B: []byte(str), RE: nil}
@@ -28,8 +28,17 @@ type compilerError struct {
 	n    ast.Node
 	path []string
 	errors.Message
+
+	code errors.Code
+
+	// suggestions holds the names of similar identifiers for an
+	// unresolved reference.
+	suggestions []string
 }
 
+func (e *compilerError) Code() errors.Code     { return e.code }
+func (e *compilerError) Suggestions() []string { return e.suggestions }
+
 func (e *compilerError) Position() token.Pos         { return e.n.Pos() }
 func (e *compilerError) InputPositions() []token.Pos { return nil }
 func (e *compilerError) Path() []string              { return e.path }
@@ -39,9 +39,19 @@ type Profile struct {
 	// TakeDefaults is used in Value mode to drop non-default values.
 	TakeDefaults bool
 
+	// DefaultsDepth, if positive, limits TakeDefaults to values that are
+	// fewer than DefaultsDepth levels of fields and list elements below
+	// the exported value. Deeper values retain their non-default values.
+	DefaultsDepth int
+
+	// ShowOptional includes optional fields in Value mode.
 	ShowOptional    bool
 	ShowDefinitions bool
 
+	// OmitOptional omits optional fields in Def mode, where they are
+	// otherwise always included.
+	OmitOptional bool
+
 	// ShowHidden forces the inclusion of hidden fields when these would
 	// otherwise be omitted. Only hidden fields from the current package are
 	// included.
@@ -71,6 +81,26 @@ type Profile struct {
 
 	// InlineImports expands references to non-builtin packages.
 	InlineImports bool
+
+	// InlineImport, if non-nil, reports whether references to the
+	// non-builtin package with the given import path should be expanded.
+	// It takes precedence over InlineImports.
+	InlineImport func(importPath string) bool
+}
+
+// inlinesImports reports whether references to any imported packages may
+// be expanded.
+func (p *Profile) inlinesImports() bool {
+	return p.InlineImports || p.InlineImport != nil
+}
+
+// inlineImport reports whether references to the package with the given
+// import path should be expanded.
+func (p *Profile) inlineImport(importPath string) bool {
+	if p.InlineImport != nil {
+		return p.InlineImport(importPath)
+	}
+	return p.InlineImports
 }
 
 var Simplified = &Profile{
@@ -289,6 +319,7 @@ type exporter struct {
 	stack []frame
 
 	inDefinition int // for close() wrapping.
+	depth        int // number of fields and elements below the exported value.
 	inExpression int // for inlining decisions.
 
 	// hidden label handling
@@ -381,7 +412,7 @@ func newExporter(p *Profile, r adt.Runtime, pkgID string, v adt.Value) *exporter
 // a new root, if needed.
 func (e *exporter) initPivot(n *adt.Vertex) {
 	switch {
-	case e.cfg.SelfContained, e.cfg.InlineImports:
+	case e.cfg.SelfContained, e.cfg.inlinesImports():
 		// Explicitly enabled.
 	case n.Parent == nil, e.cfg.Fragment:
 		return
@@ -236,6 +236,9 @@ func (x *exporter) mergeValues(label adt.Feature, src *adt.Vertex, a []conjunct,
 			continue
 		}
 		field := e.getField(f)
+		if field.arcType == adt.ArcOptional && x.cfg.OmitOptional {
+			continue
+		}
 		c := field.conjuncts
 
 		label := e.stringLabel(f)
@@ -80,7 +80,7 @@ func (e *exporter) stringLabel(f adt.Feature) ast.Label {
 func (e *exporter) identString(f adt.Feature) string {
 	s := f.IdentString(e.ctx)
 
-	if !f.IsHidden() || !e.cfg.InlineImports {
+	if !f.IsHidden() || !e.cfg.inlinesImports() {
 		return s
 	}
 
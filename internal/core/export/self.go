@@ -166,7 +166,7 @@ func (p *pivotter) markDeps(v *adt.Vertex, pkg *adt.ImportReference) {
 
 		case d.Import() != nil:
 			// Only record nodes within import if we want to expand imports.
-			if !p.x.cfg.InlineImports {
+			if !p.x.cfg.inlinesImports() {
 				return nil
 			}
 
@@ -180,7 +180,7 @@ func (p *pivotter) markDeps(v *adt.Vertex, pkg *adt.ImportReference) {
 			// and using a Runtime method to determine whether something is
 			// a core package, rather than relying on the presence of a dot.
 			path := d.Import().ImportPath.StringValue(p.x.ctx)
-			if !strings.ContainsRune(path, '.') {
+			if !strings.ContainsRune(path, '.') || !p.x.cfg.inlineImport(path) {
 				return nil
 			}
 
@@ -90,6 +90,15 @@ func TestSelfContained(t *testing.T) {
 			w = t.Writer("expand_imports")
 			self.InlineImports = true
 			test()
+			self.InlineImports = false
+		}
+
+		if path, ok := t.Value("inlineImport"); ok {
+			w = t.Writer("expand_import")
+			self.InlineImport = func(importPath string) bool {
+				return importPath == path
+			}
+			test()
 		}
 	})
 }
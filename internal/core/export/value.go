@@ -134,8 +134,15 @@ func (e *exporter) vertex(n *adt.Vertex) (result ast.Expr) {
 	return result
 }
 
+// takeDefaults reports whether to drop the non-default values of the value
+// currently being exported.
+func (e *exporter) takeDefaults() bool {
+	p := e.cfg
+	return p.TakeDefaults && (p.DefaultsDepth <= 0 || e.depth < p.DefaultsDepth)
+}
+
 func (e *exporter) value(n adt.Value, a ...adt.Conjunct) (result ast.Expr) {
-	if e.cfg.TakeDefaults {
+	if e.takeDefaults() {
 		n = adt.Default(n)
 	}
 	// Evaluate arc if needed?
@@ -344,7 +351,9 @@ func (e *exporter) listComposite(v *adt.Vertex) ast.Expr {
 		if !a.Label.IsInt() {
 			continue
 		}
+		e.depth++
 		elem := e.vertex(a)
+		e.depth--
 
 		if e.cfg.ShowDocs {
 			docs := ExtractDoc(a)
@@ -354,7 +363,7 @@ func (e *exporter) listComposite(v *adt.Vertex) ast.Expr {
 		l.Elts = append(l.Elts, elem)
 	}
 	m, ok := v.BaseValue.(*adt.ListMarker)
-	if !e.cfg.TakeDefaults && ok && m.IsOpen {
+	if !e.takeDefaults() && ok && m.IsOpen {
 		ellipsis := &ast.Ellipsis{}
 		typ := &adt.Vertex{
 			Parent: v,
@@ -462,7 +471,9 @@ func (e *exporter) structComposite(v *adt.Vertex, attrs []*ast.Attribute) ast.Ex
 
 		internal.SetConstraint(f, arc.ArcType.Token())
 
+		e.depth++
 		f.Value = e.vertex(arc.DerefValue())
+		e.depth--
 
 		if label.IsDef() {
 			e.inDefinition--
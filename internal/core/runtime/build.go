@@ -33,18 +33,71 @@ type Config struct {
 
 	Counts *stats.Counts
 
+	// Parallelism is the maximum number of instances built concurrently
+	// by a single call, or zero for the number of available CPUs.
+	Parallelism int
+
 	compile.Config
 }
 
 // Build builds b and all its transitive dependencies, insofar they have not
 // been build yet.
+//
+// Build may be called concurrently. An instance which is being built by
+// another goroutine, such as a dependency shared by several instances,
+// is built only once, and the other callers wait for its result.
 func (x *Runtime) Build(cfg *Config, b *build.Instance) (v *adt.Vertex, errs errors.Error) {
-	if err := b.Complete(); err != nil {
-		return nil, b.Err
-	}
 	if v := x.getNodeFromInstance(b); v != nil {
 		return v, b.Err
 	}
+	call, ok := x.startBuild(b)
+	if !ok {
+		<-call.done
+		return call.v, call.errs
+	}
+	defer x.finishBuild(b, call)
+	call.v, call.errs = x.build(cfg, b)
+	return call.v, call.errs
+}
+
+// buildCall is an in-progress or completed call of Build.
+type buildCall struct {
+	done chan struct{} // closed when the build has finished
+	v    *adt.Vertex
+	errs errors.Error
+}
+
+// startBuild registers a build of b. It reports false, along with the
+// call to wait for, if b is already being built by another goroutine.
+func (x *Runtime) startBuild(b *build.Instance) (*buildCall, bool) {
+	x.index.lock.Lock()
+	defer x.index.lock.Unlock()
+
+	if call, ok := x.index.building[b]; ok {
+		return call, false
+	}
+	call := &buildCall{done: make(chan struct{})}
+	if v := x.index.importsByBuild[b]; v != nil {
+		// Built by another goroutine since the check in Build.
+		call.v, call.errs = v, b.Err
+		close(call.done)
+		return call, false
+	}
+	x.index.building[b] = call
+	return call, true
+}
+
+func (x *Runtime) finishBuild(b *build.Instance, call *buildCall) {
+	x.index.lock.Lock()
+	delete(x.index.building, b)
+	x.index.lock.Unlock()
+	close(call.done)
+}
+
+func (x *Runtime) build(cfg *Config, b *build.Instance) (v *adt.Vertex, errs errors.Error) {
+	if err := b.Complete(); err != nil {
+		return nil, b.Err
+	}
 	// TODO: clear cache of old implementation.
 	// if s := b.ImportPath; s != "" {
 	// 	// Use cached result, if available.
@@ -82,6 +82,7 @@ type index struct {
 	imports        map[*adt.Vertex]*build.Instance
 	importsByPath  map[string]*adt.Vertex
 	importsByBuild map[*build.Instance]*adt.Vertex
+	building       map[*build.Instance]*buildCall // instances being built
 
 	nextUniqueID uint64
 
@@ -91,6 +92,10 @@ type index struct {
 	builtinShort map[string]string      // Commandline shorthand
 
 	typeCache sync.Map // map[reflect.Type]evaluated
+
+	// strings holds the interned string values; see Runtime.InternString.
+	stringsLock sync.Mutex
+	strings     map[string]string
 }
 
 func (i *index) getNextUniqueID() uint64 {
@@ -107,6 +112,8 @@ func newIndex() *index {
 		imports:        map[*adt.Vertex]*build.Instance{},
 		importsByPath:  map[string]*adt.Vertex{},
 		importsByBuild: map[*build.Instance]*adt.Vertex{},
+		building:       map[*build.Instance]*buildCall{},
+		strings:        map[string]string{},
 	}
 	return i
 }
@@ -140,6 +147,14 @@ func (r *Runtime) GetInstanceFromNode(key *adt.Vertex) *build.Instance {
 	return r.index.imports[key]
 }
 
+// PackagePath implements [adt.PackagePather].
+func (r *Runtime) PackagePath(root *adt.Vertex) string {
+	if b := r.GetInstanceFromNode(root); b != nil {
+		return b.ImportPath
+	}
+	return ""
+}
+
 func (r *Runtime) getNodeFromInstance(key *build.Instance) *adt.Vertex {
 	r.index.lock.RLock()
 	defer r.index.lock.RUnlock()
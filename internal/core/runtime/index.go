@@ -58,6 +58,43 @@ func (r *Runtime) Label(s string, isIdent bool) adt.Feature {
 	return f
 }
 
+const (
+	// maxInternLen is the length of the longest string which is interned
+	// by InternString. Longer strings are rarely repeated and would only
+	// grow the table.
+	maxInternLen = 64
+
+	// maxInterned is the number of strings the table of InternString
+	// holds before it is cleared, so that a long-lived Runtime which
+	// compiles many distinct strings does not keep them all alive.
+	maxInterned = 1 << 14
+)
+
+// InternString implements [adt.StringInterner]. Field labels are already
+// shared through the label index; InternString additionally lets equal
+// string values, such as those of keys repeated across many objects, share
+// memory.
+func (r *Runtime) InternString(s string) string {
+	x := r.index
+	if x == nil || s == "" || len(s) > maxInternLen {
+		return s
+	}
+	x.stringsLock.Lock()
+	t, ok := x.strings[s]
+	if !ok {
+		if len(x.strings) >= maxInterned {
+			clear(x.strings)
+		}
+		x.strings[s] = s
+		t = s
+	}
+	x.stringsLock.Unlock()
+	if ok {
+		adt.AddInternedString(len(s))
+	}
+	return t
+}
+
 // TODO: move to Runtime as fields.
 var (
 	labelMap = map[string]int{}
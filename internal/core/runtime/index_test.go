@@ -0,0 +1,56 @@
+// Copyright 2025 CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package runtime
+
+import (
+	"strconv"
+	"strings"
+	"testing"
+	"unsafe"
+
+	"cuelang.org/go/internal/core/adt"
+)
+
+func TestInternString(t *testing.T) {
+	r := New()
+	adt.ResetStats()
+
+	a := r.InternString(strings.Repeat("a", 10))
+	b := r.InternString(strings.Repeat("a", 10))
+	if unsafe.StringData(a) != unsafe.StringData(b) {
+		t.Errorf("equal strings do not share memory")
+	}
+
+	long := strings.Repeat("b", maxInternLen+1)
+	if got := r.InternString(long); unsafe.StringData(got) != unsafe.StringData(long) {
+		t.Errorf("long string was interned")
+	}
+
+	s := adt.TotalStats()
+	if s.InternedStrings != 1 || s.InternedBytes != 10 {
+		t.Errorf("got %d interned strings of %d bytes; want 1 of 10",
+			s.InternedStrings, s.InternedBytes)
+	}
+}
+
+func TestInternStringBounded(t *testing.T) {
+	r := New()
+	for i := range 3 * maxInterned {
+		r.InternString(strconv.Itoa(i))
+	}
+	if n := len(r.index.strings); n > maxInterned {
+		t.Errorf("table holds %d strings; want at most %d", n, maxInterned)
+	}
+}
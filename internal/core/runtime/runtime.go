@@ -36,6 +36,8 @@ type Runtime struct {
 	topoSort bool
 
 	flags cuedebug.Config
+
+	limits adt.Limits
 }
 
 func (r *Runtime) Settings() (internal.EvaluatorVersion, cuedebug.Config) {
@@ -46,6 +48,7 @@ func (r *Runtime) ConfigureOpCtx(ctx *adt.OpContext) {
 	ctx.Version = r.version
 	ctx.TopoSort = r.topoSort
 	ctx.Config = r.flags
+	ctx.SetLimits(r.limits)
 }
 
 func (r *Runtime) SetBuildData(b *build.Instance, x interface{}) {
@@ -106,6 +109,12 @@ func (r *Runtime) SetDebugOptions(flags *cuedebug.Config) {
 	r.topoSort = r.topoSort || r.flags.SortFields
 }
 
+// SetLimits sets the limits which apply to each evaluation with the
+// Runtime. It should only be set before evaluation starts.
+func (r *Runtime) SetLimits(l adt.Limits) {
+	r.limits = l
+}
+
 // IsInitialized reports whether the runtime has been initialized.
 func (r *Runtime) IsInitialized() bool {
 	return r.index != nil
@@ -24,6 +24,11 @@ type Logins struct {
 	// such as when our central registry starts using scopes.
 
 	Registries map[string]RegistryLogin `json:"registries"`
+
+	// CredentialHelper, if not empty, names the Docker credential helper,
+	// without its docker-credential- prefix, which holds the tokens of
+	// the registries. The file then only holds their expiry times.
+	CredentialHelper string `json:"credential_helper,omitempty"`
 }
 
 // RegistryLogin holds the login information for one registry.
@@ -75,6 +80,8 @@ func CacheDir(getenv func(string) string) (string, error) {
 	return filepath.Join(dir, "cue"), nil
 }
 
+// ReadLogins reads the logins.json file at path, along with the tokens
+// in its credential helper, if it has one.
 func ReadLogins(path string) (*Logins, error) {
 	// Note that we read logins.json without holding a file lock,
 	// as the file lock is only held for writes. Prevent ephemeral errors on Windows.
@@ -89,6 +96,21 @@ func ReadLogins(path string) (*Logins, error) {
 	if err := json.Unmarshal(body, logins); err != nil {
 		return nil, err
 	}
+	if helper := logins.CredentialHelper; helper != "" {
+		for regName, regLogin := range logins.Registries {
+			secret, err := getHelperLogin(helper, regName)
+			if errors.Is(err, errNoHelperLogin) {
+				// The token was removed from the credential store,
+				// such as by deleting it from a keychain.
+				delete(logins.Registries, regName)
+				continue
+			} else if err != nil {
+				return nil, err
+			}
+			secret.Expiry = regLogin.Expiry
+			logins.Registries[regName] = secret
+		}
+	}
 	// Sanity-check the read data.
 	for regName, regLogin := range logins.Registries {
 		if regLogin.AccessToken == "" {
@@ -113,6 +135,21 @@ func WriteLogins(path string, logins *Logins) error {
 }
 
 func writeLoginsUnlocked(path string, logins *Logins) error {
+	if helper := logins.CredentialHelper; helper != "" {
+		// Store the tokens with the helper, and only keep the
+		// expiry times in the file.
+		stored := &Logins{
+			Registries:       make(map[string]RegistryLogin, len(logins.Registries)),
+			CredentialHelper: helper,
+		}
+		for regName, regLogin := range logins.Registries {
+			if err := storeHelperLogin(helper, regName, regLogin); err != nil {
+				return err
+			}
+			stored.Registries[regName] = RegistryLogin{Expiry: regLogin.Expiry}
+		}
+		logins = stored
+	}
 	// Indenting and a trailing newline are not necessary, but nicer to humans.
 	body, err := json.MarshalIndent(logins, "", "\t")
 	if err != nil {
@@ -164,6 +201,72 @@ func UpdateRegistryLogin(path string, key string, new *oauth2.Token) (*Logins, e
 	return logins, nil
 }
 
+// RemoveRegistryLogin atomically removes a single registry token from the
+// logins.json file, and from its credential helper if it has one. It
+// reports whether there was a token for the registry.
+func RemoveRegistryLogin(path string, key string) (bool, error) {
+	unlock, err := lockedfile.MutexAt(path + ".lock").Lock()
+	if err != nil {
+		return false, err
+	}
+	defer unlock()
+
+	logins, err := ReadLogins(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return false, nil
+	} else if err != nil {
+		return false, err
+	}
+	if _, ok := logins.Registries[key]; !ok {
+		return false, nil
+	}
+	delete(logins.Registries, key)
+	if helper := logins.CredentialHelper; helper != "" {
+		if err := eraseHelperLogin(helper, key); err != nil {
+			return false, err
+		}
+	}
+	return true, writeLoginsUnlocked(path, logins)
+}
+
+// SetCredentialHelper atomically moves all registry tokens in the
+// logins.json file to the named credential helper, or into the file
+// itself if helper is empty.
+func SetCredentialHelper(path string, helper string) (*Logins, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0o777); err != nil {
+		return nil, err
+	}
+
+	unlock, err := lockedfile.MutexAt(path + ".lock").Lock()
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	logins, err := ReadLogins(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		logins = &Logins{Registries: make(map[string]RegistryLogin)}
+	} else if err != nil {
+		return nil, err
+	}
+	old := logins.CredentialHelper
+	if old == helper {
+		return logins, nil
+	}
+	logins.CredentialHelper = helper
+	if err := writeLoginsUnlocked(path, logins); err != nil {
+		return nil, err
+	}
+	if old != "" {
+		for regName := range logins.Registries {
+			if err := eraseHelperLogin(old, regName); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return logins, nil
+}
+
 // RegistryOAuthConfig returns the oauth2 configuration
 // suitable for talking to the central registry.
 func RegistryOAuthConfig(host modresolve.Host) oauth2.Config {
@@ -0,0 +1,131 @@
+// Copyright 2025 The CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cueconfig
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// Registry tokens may be kept in a native credential store, such as the
+// macOS keychain or the Secret Service on Linux, rather than in the
+// logins.json file. We talk to such stores through the same helper
+// programs as Docker, which are named docker-credential-<name> and
+// implement the protocol described at
+// https://github.com/docker/docker-credential-helpers.
+//
+// Tokens are stored under a server URL with a scheme of their own, so
+// that they never clash with the credentials which Docker keeps for the
+// same registry hosts.
+
+// credentialUsername is the user name stored alongside each token.
+const credentialUsername = "cue"
+
+// errCredentialsNotFound is printed by helpers which hold no credentials
+// for a server URL.
+const errCredentialsNotFound = "credentials not found in native keychain"
+
+// errNoHelperLogin is returned by getHelperLogin when the credential
+// helper holds no token for a registry.
+var errNoHelperLogin = errors.New("no token in credential helper")
+
+// credentials is the JSON encoding of the credentials exchanged with
+// helper programs.
+type credentials struct {
+	ServerURL string `json:"ServerURL,omitempty"`
+	Username  string `json:"Username"`
+	Secret    string `json:"Secret"`
+}
+
+func credentialServerURL(registry string) string {
+	return "cue-login://" + registry
+}
+
+// runCredentialHelper runs the given command of a credential helper with
+// input as its standard input, and returns its standard output.
+func runCredentialHelper(helper, command string, input []byte) ([]byte, error) {
+	var stdout, stderr bytes.Buffer
+	cmd := exec.Command("docker-credential-"+helper, command)
+	cmd.Stdin = bytes.NewReader(input)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		if !errors.As(err, new(*exec.ExitError)) {
+			return nil, fmt.Errorf("cannot run credential helper: %v", err)
+		}
+		// Helpers print their errors to stdout, but be lenient.
+		msg := strings.TrimSpace(stdout.String() + stderr.String())
+		return nil, fmt.Errorf("credential helper %s %s: %s", helper, command, msg)
+	}
+	return stdout.Bytes(), nil
+}
+
+// getHelperLogin returns the secret fields of the login for registry
+// from a credential helper.
+func getHelperLogin(helper, registry string) (RegistryLogin, error) {
+	out, err := runCredentialHelper(helper, "get", []byte(credentialServerURL(registry)))
+	if err != nil {
+		if strings.Contains(err.Error(), errCredentialsNotFound) {
+			return RegistryLogin{}, errNoHelperLogin
+		}
+		return RegistryLogin{}, err
+	}
+	var creds credentials
+	if err := json.Unmarshal(out, &creds); err != nil {
+		return RegistryLogin{}, fmt.Errorf("invalid output from credential helper %s: %v", helper, err)
+	}
+	var login RegistryLogin
+	if err := json.Unmarshal([]byte(creds.Secret), &login); err != nil {
+		return RegistryLogin{}, fmt.Errorf("invalid token for registry %s in credential helper %s: %v", registry, helper, err)
+	}
+	return login, nil
+}
+
+// storeHelperLogin stores the secret fields of login for registry with
+// a credential helper.
+func storeHelperLogin(helper, registry string, login RegistryLogin) error {
+	secret, err := json.Marshal(RegistryLogin{
+		AccessToken:  login.AccessToken,
+		TokenType:    login.TokenType,
+		RefreshToken: login.RefreshToken,
+	})
+	if err != nil {
+		return err
+	}
+	input, err := json.Marshal(credentials{
+		ServerURL: credentialServerURL(registry),
+		Username:  credentialUsername,
+		Secret:    string(secret),
+	})
+	if err != nil {
+		return err
+	}
+	_, err = runCredentialHelper(helper, "store", input)
+	return err
+}
+
+// eraseHelperLogin removes the login for registry from a credential
+// helper. It is not an error if there is no such login.
+func eraseHelperLogin(helper, registry string) error {
+	_, err := runCredentialHelper(helper, "erase", []byte(credentialServerURL(registry)))
+	if err != nil && strings.Contains(err.Error(), errCredentialsNotFound) {
+		return nil
+	}
+	return err
+}
@@ -12,12 +12,29 @@ var Flags Config
 // Config holds the set of known CUE_DEBUG flags.
 //
 // When adding, deleting, or modifying entries below,
-// update cmd/cue/cmd/help.go as well for `cue help environment`.
+// update Docs as well for `cue help debug`.
 type Config struct {
 	// HTTP enables JSON logging per HTTP request and response made
 	// when interacting with module registries.
 	HTTP bool
 
+	// HTTPFile causes the HTTP log to be appended to the named file
+	// rather than written to standard error. Setting it enables
+	// HTTP logging.
+	HTTPFile string
+
+	// HTTPLevel determines how much of each HTTP request and response
+	// is logged: one of "basic", "headers", or "bodies".
+	HTTPLevel string `envflag:"default:bodies"`
+
+	// HTTPMaxBody holds the maximum number of bytes of each body
+	// to include in the HTTP log. If it is zero, a default is used.
+	HTTPMaxBody int
+
+	// HTTPRedact holds a regular expression matching extra text to
+	// redact from the HTTP log, such as tokens specific to a registry.
+	HTTPRedact string
+
 	// TODO: consider moving these evaluator-related options into a separate
 	// struct, so that it can be used in an API. We should use embedding,
 	// or some other mechanism, in that case to allow for the full set of
@@ -64,3 +81,48 @@ func Init() error {
 var initOnce = sync.OnceValue(func() error {
 	return envflag.Init(&Flags, "CUE_DEBUG")
 })
+
+// Set applies settings in the same form as CUE_DEBUG, such as those given
+// with the --debug flag of the cue command, on top of Flags.
+// It calls Init first, so that the settings take precedence over CUE_DEBUG.
+func Set(settings string) error {
+	if err := Init(); err != nil {
+		return err
+	}
+	return envflag.Set(&Flags, settings)
+}
+
+// Describe returns the flags in Config, in the order of their fields.
+func Describe() []envflag.Flag {
+	flags, err := envflag.Describe[Config]()
+	if err != nil {
+		panic(err) // the struct tags in Config are fixed
+	}
+	return flags
+}
+
+// Docs holds a description of each flag in Config, keyed by its name as
+// given in CUE_DEBUG, as shown by `cue help debug`.
+var Docs = map[string]string{
+	"http": `Log a JSON message per HTTP request and response made
+when interacting with module registries.`,
+	"httpfile": `Append the HTTP log to the named file instead of
+standard error. This also enables the HTTP log.`,
+	"httplevel": `How much of each HTTP request and response to log:
+"basic" for the method, URL, and status code only,
+"headers" to add headers, or "bodies" to add bodies too.`,
+	"httpmaxbody": `The maximum number of bytes of each body to log;
+0 means a default of 1024.`,
+	"httpredact": `Replace any text matching the regular expression in
+the HTTP log with REDACTED. Authorization headers and
+URL query parameters are always redacted.`,
+	"strict": `Check the evaluator's invariants more aggressively.`,
+	"logeval": `Log the work of the evaluator to standard error
+when set to 1.`,
+	"sharing": `Share the structure of values in the evaluator
+rather than copying them.`,
+	"sortfields":  `Force fields in structs to be sorted lexicographically.`,
+	"opendef":     `Disable the check for closedness of definitions.`,
+	"toolsflow":   `Print task dependency mermaid graphs in 'cue cmd'.`,
+	"parsertrace": `Print a trace of parsed CUE productions.`,
+}
@@ -13,3 +13,19 @@ func TestInit(t *testing.T) {
 	qt.Assert(t, qt.IsNil(err))
 	qt.Assert(t, qt.IsTrue(Flags.HTTP))
 }
+
+func TestSet(t *testing.T) {
+	t.Setenv("CUE_DEBUG", "http")
+	err := Set("sortfields,httplevel=basic")
+	qt.Assert(t, qt.IsNil(err))
+	qt.Assert(t, qt.IsTrue(Flags.HTTP))
+	qt.Assert(t, qt.IsTrue(Flags.SortFields))
+	qt.Assert(t, qt.Equals(Flags.HTTPLevel, "basic"))
+}
+
+func TestDocs(t *testing.T) {
+	for _, f := range Describe() {
+		qt.Check(t, qt.Not(qt.Equals(Docs[f.Name], "")), qt.Commentf("flag %q", f.Name))
+	}
+	qt.Check(t, qt.HasLen(Docs, len(Describe())))
+}
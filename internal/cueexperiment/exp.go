@@ -1,6 +1,8 @@
 package cueexperiment
 
 import (
+	"reflect"
+	"strings"
 	"sync"
 
 	"cuelang.org/go/internal/envflag"
@@ -25,6 +27,55 @@ var Flags struct {
 	// and enabled by default in the upcoming v0.14 release.
 	CmdReferencePkg bool `envflag:"default:true"`
 
+	// ParallelPkgs compiles the packages given to commands such as
+	// cue export and cue vet concurrently. They are still evaluated one
+	// after the other.
+	//
+	// This experiment was introduced in the upcoming v0.14 release.
+	ParallelPkgs bool
+
+	// EvalCache caches the evaluated form of imported packages which do
+	// not depend on tags or extern interpreters under the cache directory,
+	// so that later invocations of the cue command can reuse them.
+	//
+	// This experiment was introduced in the upcoming v0.14 release.
+	EvalCache bool
+
+	// ExternProcess enables the @extern(process) interpreter, which
+	// implements functions by running programs as subprocesses.
+	// It is opt-in, as evaluating CUE then runs programs from its module.
+	//
+	// This experiment was introduced in the upcoming v0.14 release.
+	ExternProcess bool
+
+	// ExternJS enables the @extern(js) interpreter, which implements
+	// functions in JavaScript. It is opt-in, as it runs code from the
+	// module of the CUE being evaluated in an embedded interpreter.
+	//
+	// This experiment was introduced in the upcoming v0.14 release.
+	ExternJS bool
+
+	// ExternStarlark enables the @extern(starlark) interpreter, which
+	// implements functions in Starlark. It is opt-in, as it runs code from
+	// the module of the CUE being evaluated in an embedded interpreter.
+	//
+	// This experiment was introduced in the upcoming v0.14 release.
+	ExternStarlark bool
+
+	// EmbedRemote enables @embed(url=...), which embeds remote content
+	// pinned to its SHA-256 hash. It is opt-in, as evaluating CUE then
+	// depends on the network.
+	//
+	// This experiment was introduced in the upcoming v0.14 release.
+	EmbedRemote bool
+
+	// ExportCheckpoint enables the --checkpoint flag of cue export, which
+	// records the values exported so far, so that an interrupted export
+	// can resume without evaluating them again.
+	//
+	// This experiment was introduced in the upcoming v0.14 release.
+	ExportCheckpoint bool
+
 	// The flags below describe completed experiments; they can still be set
 	// as long as the value aligns with the final behavior once the experiment finished.
 	// Breaking users who set such a flag seems unnecessary,
@@ -83,3 +134,15 @@ func Init() error {
 var initOnce = sync.OnceValue(func() error {
 	return envflag.Init(&Flags, "CUE_EXPERIMENT")
 })
+
+// Values returns the value of each flag in Flags, keyed by its name in
+// lower case, as it is given in CUE_EXPERIMENT.
+func Values() map[string]bool {
+	values := make(map[string]bool)
+	fv := reflect.ValueOf(Flags)
+	ft := fv.Type()
+	for i := 0; i < ft.NumField(); i++ {
+		values[strings.ToLower(ft.Field(i).Name)] = fv.Field(i).Bool()
+	}
+	return values
+}
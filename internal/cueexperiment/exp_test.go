@@ -13,4 +13,8 @@ func TestInit(t *testing.T) {
 	qt.Assert(t, qt.IsNil(err))
 	qt.Assert(t, qt.IsFalse(Flags.EvalV3))
 	qt.Assert(t, qt.IsTrue(Flags.CmdReferencePkg))
+
+	values := Values()
+	qt.Assert(t, qt.IsFalse(values["evalv3"]))
+	qt.Assert(t, qt.IsTrue(values["cmdreferencepkg"]))
 }
@@ -0,0 +1,312 @@
+// Copyright 2025 The CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package cuetelemetry records local counters of how the cue command is
+// used, such as which commands and flags are used and which kinds of
+// errors are reported. Nothing is recorded unless the user opts in.
+//
+// Counters only have names chosen by the cue command itself; they never
+// include user data such as file names, CUE values, or the names of
+// user-defined commands. They are kept in one file per week under the
+// telemetry directory, which lives in the CUE configuration directory.
+// In the "on" mode, the files for past weeks may also be uploaded.
+package cuetelemetry
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"net/http"
+	"os"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/rogpeppe/go-internal/lockedfile"
+	"github.com/rogpeppe/go-internal/robustio"
+
+	"cuelang.org/go/internal/cueconfig"
+)
+
+// Mode determines whether counters are recorded and uploaded.
+type Mode string
+
+const (
+	// ModeOff records nothing. It is the default.
+	ModeOff Mode = "off"
+
+	// ModeLocal records counters, but never uploads them.
+	ModeLocal Mode = "local"
+
+	// ModeOn records counters, and allows them to be uploaded.
+	ModeOn Mode = "on"
+)
+
+// ParseMode returns the mode with the given name.
+func ParseMode(s string) (Mode, error) {
+	switch m := Mode(s); m {
+	case ModeOff, ModeLocal, ModeOn:
+		return m, nil
+	}
+	return "", fmt.Errorf("unknown telemetry mode %q; must be one of off, local, or on", s)
+}
+
+// Dir returns the directory which holds the telemetry mode and counters.
+func Dir(getenv func(string) string) (string, error) {
+	configDir, err := cueconfig.ConfigDir(getenv)
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(configDir, "telemetry"), nil
+}
+
+// ReadMode returns the mode set in dir, which is [ModeOff] if no mode
+// was ever set.
+func ReadMode(dir string) (Mode, error) {
+	data, err := os.ReadFile(filepath.Join(dir, "mode"))
+	if errors.Is(err, fs.ErrNotExist) {
+		return ModeOff, nil
+	} else if err != nil {
+		return "", err
+	}
+	return ParseMode(strings.TrimSpace(string(data)))
+}
+
+// WriteMode sets the mode in dir.
+func WriteMode(dir string, mode Mode) error {
+	if err := os.MkdirAll(dir, 0o777); err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(dir, "mode"), []byte(mode+"\n"), 0o666)
+}
+
+// A Report holds the counters recorded during one week.
+type Report struct {
+	// Week holds the Monday which starts the week, as YYYY-MM-DD in UTC.
+	Week string `json:"week"`
+
+	// Program is always "cmd/cue".
+	Program string `json:"program"`
+
+	GOOS   string `json:"goos"`
+	GOARCH string `json:"goarch"`
+
+	// Counters holds the value of each counter by name.
+	Counters map[string]int64 `json:"counters"`
+}
+
+// Week returns the week which t falls in, as used in [Report.Week].
+func Week(t time.Time) string {
+	t = t.UTC()
+	monday := t.AddDate(0, 0, -(int(t.Weekday())+6)%7)
+	return monday.Format(time.DateOnly)
+}
+
+// counters holds the counters recorded by this process. Recording is
+// only enabled if dir is set.
+var counters struct {
+	mu     sync.Mutex
+	dir    string
+	counts map[string]int64
+}
+
+// Start enables counting with [Inc] and [Add] if the user opted in,
+// and records the version of the cue command in use.
+func Start(getenv func(string) string, version string) error {
+	dir, err := Dir(getenv)
+	if err != nil {
+		return err
+	}
+	mode, err := ReadMode(dir)
+	if err != nil || mode == ModeOff {
+		return err
+	}
+	counters.mu.Lock()
+	counters.dir = dir
+	counters.counts = make(map[string]int64)
+	counters.mu.Unlock()
+	Inc("version:" + version)
+	return nil
+}
+
+// Inc increments the named counter.
+func Inc(name string) {
+	Add(name, 1)
+}
+
+// Add adds n to the named counter.
+// It does nothing unless counting was enabled by [Start].
+func Add(name string, n int64) {
+	counters.mu.Lock()
+	defer counters.mu.Unlock()
+	if counters.dir != "" {
+		counters.counts[name] += n
+	}
+}
+
+// Bucket returns the name of a counter for n in a histogram whose
+// buckets grow by powers of ten, such as "<1000" for 421.
+func Bucket(name string, n int64) string {
+	limit := int64(10)
+	for n >= limit && limit < 1e18 {
+		limit *= 10
+	}
+	return fmt.Sprintf("%s:<%d", name, limit)
+}
+
+// Flush adds the counters recorded since [Start] to the file for the
+// week of now, and resets them.
+func Flush(now time.Time) error {
+	counters.mu.Lock()
+	defer counters.mu.Unlock()
+	if counters.dir == "" || len(counters.counts) == 0 {
+		return nil
+	}
+	localDir := filepath.Join(counters.dir, "local")
+	if err := os.MkdirAll(localDir, 0o777); err != nil {
+		return err
+	}
+	week := Week(now)
+	path := filepath.Join(localDir, week+".json")
+	unlock, err := lockedfile.MutexAt(path + ".lock").Lock()
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	report, err := readReport(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		report = &Report{
+			Week:     week,
+			Program:  "cmd/cue",
+			GOOS:     runtime.GOOS,
+			GOARCH:   runtime.GOARCH,
+			Counters: make(map[string]int64),
+		}
+	} else if err != nil {
+		return err
+	}
+	for name, n := range counters.counts {
+		report.Counters[name] += n
+	}
+	body, err := json.MarshalIndent(report, "", "\t")
+	if err != nil {
+		return err
+	}
+	body = append(body, '\n')
+	// Write to a temp file and rename it, so that readers which don't
+	// hold the lock never see a partial file.
+	if err := os.WriteFile(path+".tmp", body, 0o666); err != nil {
+		return err
+	}
+	if err := robustio.Rename(path+".tmp", path); err != nil {
+		return err
+	}
+	clear(counters.counts)
+	return nil
+}
+
+func readReport(path string) (*Report, error) {
+	data, err := robustio.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var report Report
+	if err := json.Unmarshal(data, &report); err != nil {
+		return nil, fmt.Errorf("invalid telemetry report %s: %v", path, err)
+	}
+	if report.Counters == nil {
+		report.Counters = make(map[string]int64)
+	}
+	return &report, nil
+}
+
+// Reports returns the reports in dir which have not been uploaded,
+// ordered by week.
+func Reports(dir string) ([]*Report, error) {
+	paths, err := filepath.Glob(filepath.Join(dir, "local", "*.json"))
+	if err != nil {
+		return nil, err
+	}
+	slices.Sort(paths)
+	var reports []*Report
+	for _, path := range paths {
+		report, err := readReport(path)
+		if err != nil {
+			return nil, err
+		}
+		reports = append(reports, report)
+	}
+	return reports, nil
+}
+
+// Upload sends each report in dir for a week before the week of now to
+// url with a POST request, and then moves it to the uploaded directory
+// so that it is not sent again. It returns the number of reports sent.
+//
+// Reports are only uploaded in [ModeOn].
+func Upload(ctx context.Context, client *http.Client, dir, url string, now time.Time) (int, error) {
+	mode, err := ReadMode(dir)
+	if err != nil {
+		return 0, err
+	}
+	if mode != ModeOn {
+		return 0, fmt.Errorf("telemetry mode is %s; uploads need mode on", mode)
+	}
+	reports, err := Reports(dir)
+	if err != nil {
+		return 0, err
+	}
+	uploadedDir := filepath.Join(dir, "uploaded")
+	if err := os.MkdirAll(uploadedDir, 0o777); err != nil {
+		return 0, err
+	}
+	thisWeek := Week(now)
+	n := 0
+	for _, report := range reports {
+		if report.Week >= thisWeek {
+			// The week is not over yet, so more counters may be added.
+			continue
+		}
+		body, err := json.Marshal(report)
+		if err != nil {
+			return n, err
+		}
+		req, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewReader(body))
+		if err != nil {
+			return n, err
+		}
+		req.Header.Set("Content-Type", "application/json")
+		resp, err := client.Do(req)
+		if err != nil {
+			return n, err
+		}
+		resp.Body.Close()
+		if resp.StatusCode/100 != 2 {
+			return n, fmt.Errorf("cannot upload telemetry report for week %s: %s", report.Week, resp.Status)
+		}
+		name := report.Week + ".json"
+		if err := robustio.Rename(filepath.Join(dir, "local", name), filepath.Join(uploadedDir, name)); err != nil {
+			return n, err
+		}
+		n++
+	}
+	return n, nil
+}
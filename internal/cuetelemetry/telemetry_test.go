@@ -0,0 +1,130 @@
+// Copyright 2025 The CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cuetelemetry
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/go-quicktest/qt"
+)
+
+func TestWeek(t *testing.T) {
+	for date, want := range map[string]string{
+		"2025-06-02": "2025-06-02", // Monday
+		"2025-06-05": "2025-06-02",
+		"2025-06-08": "2025-06-02", // Sunday
+		"2025-01-01": "2024-12-30",
+	} {
+		d, err := time.Parse(time.DateOnly, date)
+		qt.Assert(t, qt.IsNil(err))
+		qt.Check(t, qt.Equals(Week(d), want), qt.Commentf("%s", date))
+	}
+}
+
+func TestBucket(t *testing.T) {
+	qt.Check(t, qt.Equals(Bucket("n", 0), "n:<10"))
+	qt.Check(t, qt.Equals(Bucket("n", 9), "n:<10"))
+	qt.Check(t, qt.Equals(Bucket("n", 10), "n:<100"))
+	qt.Check(t, qt.Equals(Bucket("n", 421), "n:<1000"))
+}
+
+func TestMode(t *testing.T) {
+	dir := t.TempDir()
+	mode, err := ReadMode(dir)
+	qt.Assert(t, qt.IsNil(err))
+	qt.Assert(t, qt.Equals(mode, ModeOff))
+
+	qt.Assert(t, qt.IsNil(WriteMode(dir, ModeLocal)))
+	mode, err = ReadMode(dir)
+	qt.Assert(t, qt.IsNil(err))
+	qt.Assert(t, qt.Equals(mode, ModeLocal))
+
+	_, err = ParseMode("sometimes")
+	qt.Assert(t, qt.ErrorMatches(err, `unknown telemetry mode "sometimes"; must be one of off, local, or on`))
+}
+
+func TestCountersAndUpload(t *testing.T) {
+	configDir := t.TempDir()
+	getenv := func(key string) string {
+		if key == "CUE_CONFIG_DIR" {
+			return configDir
+		}
+		return ""
+	}
+	dir := filepath.Join(configDir, "telemetry")
+	lastWeek := time.Date(2025, 6, 4, 12, 0, 0, 0, time.UTC)
+	thisWeek := lastWeek.AddDate(0, 0, 7)
+
+	// Nothing is recorded in the default mode.
+	qt.Assert(t, qt.IsNil(Start(getenv, "v0.99.0")))
+	Inc("cmd:export")
+	qt.Assert(t, qt.IsNil(Flush(lastWeek)))
+	_, err := os.Stat(filepath.Join(dir, "local"))
+	qt.Assert(t, qt.ErrorIs(err, os.ErrNotExist))
+
+	qt.Assert(t, qt.IsNil(WriteMode(dir, ModeLocal)))
+	for _, now := range []time.Time{lastWeek, lastWeek, thisWeek} {
+		qt.Assert(t, qt.IsNil(Start(getenv, "v0.99.0")))
+		Inc("cmd:export")
+		Add("flag:out", 2)
+		qt.Assert(t, qt.IsNil(Flush(now)))
+	}
+	reports, err := Reports(dir)
+	qt.Assert(t, qt.IsNil(err))
+	qt.Assert(t, qt.HasLen(reports, 2))
+	qt.Assert(t, qt.Equals(reports[0].Week, "2025-06-02"))
+	qt.Assert(t, qt.DeepEquals(reports[0].Counters, map[string]int64{
+		"version:v0.99.0": 2,
+		"cmd:export":      2,
+		"flag:out":        4,
+	}))
+	qt.Assert(t, qt.Equals(reports[1].Week, "2025-06-09"))
+
+	var received []Report
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		var report Report
+		qt.Check(t, qt.IsNil(json.NewDecoder(req.Body).Decode(&report)))
+		received = append(received, report)
+	}))
+	defer srv.Close()
+
+	ctx := context.Background()
+	_, err = Upload(ctx, srv.Client(), dir, srv.URL, thisWeek)
+	qt.Assert(t, qt.ErrorMatches(err, `telemetry mode is local; uploads need mode on`))
+
+	// Only the report for the week which is over is uploaded, and only once.
+	qt.Assert(t, qt.IsNil(WriteMode(dir, ModeOn)))
+	n, err := Upload(ctx, srv.Client(), dir, srv.URL, thisWeek)
+	qt.Assert(t, qt.IsNil(err))
+	qt.Assert(t, qt.Equals(n, 1))
+	n, err = Upload(ctx, srv.Client(), dir, srv.URL, thisWeek)
+	qt.Assert(t, qt.IsNil(err))
+	qt.Assert(t, qt.Equals(n, 0))
+	qt.Assert(t, qt.HasLen(received, 1))
+	qt.Assert(t, qt.Equals(received[0].Week, "2025-06-02"))
+	qt.Assert(t, qt.Equals(received[0].Counters["cmd:export"], int64(2)))
+
+	reports, err = Reports(dir)
+	qt.Assert(t, qt.IsNil(err))
+	qt.Assert(t, qt.HasLen(reports, 1))
+	qt.Assert(t, qt.Equals(reports[0].Week, "2025-06-09"))
+}
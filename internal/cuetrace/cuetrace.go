@@ -0,0 +1,451 @@
+// Copyright 2025 CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package cuetrace records OpenTelemetry trace spans for the phases of a
+// cue command and exports them using OTLP over HTTP with JSON encoding.
+//
+// Tracing is opt-in: it is enabled by [Init] only when an OTLP endpoint is
+// configured with the standard OTEL_EXPORTER_OTLP_ENDPOINT or
+// OTEL_EXPORTER_OTLP_TRACES_ENDPOINT environment variables. When it is
+// disabled, [Start] returns a nil *Span, whose methods do nothing.
+//
+// As cue commands are short-lived, spans are kept in memory and exported
+// in a single request when the command finishes.
+package cuetrace
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"cuelang.org/go/internal/cueversion"
+)
+
+// current holds the tracer set up by Init, if tracing is enabled.
+var current atomic.Pointer[tracer]
+
+type tracer struct {
+	endpoint string
+	headers  http.Header
+	service  string
+
+	traceID [16]byte
+	// parentID is the ID of the span which the root spans are children of,
+	// as given by $TRACEPARENT.
+	parentID [8]byte
+
+	mu     sync.Mutex
+	active []*Span // started spans which have not ended, in order
+	ended  []*Span
+}
+
+// Init enables tracing if it is configured in the environment, as
+// described in the package documentation. The returned function exports
+// the recorded spans and disables tracing again; it must be called once
+// the traced work is done.
+//
+// The following environment variables are supported:
+//
+//	OTEL_EXPORTER_OTLP_ENDPOINT          base URL; spans are sent to /v1/traces
+//	OTEL_EXPORTER_OTLP_TRACES_ENDPOINT   full URL, overriding the above
+//	OTEL_EXPORTER_OTLP_HEADERS           request headers, as key=value,...
+//	OTEL_EXPORTER_OTLP_TRACES_HEADERS    request headers, overriding the above
+//	OTEL_EXPORTER_OTLP_PROTOCOL          must be http/json if set
+//	OTEL_EXPORTER_OTLP_TRACES_PROTOCOL   must be http/json if set
+//	OTEL_SERVICE_NAME                    service name; "cue" by default
+//	OTEL_SDK_DISABLED                    disables tracing if "true"
+//	OTEL_TRACES_EXPORTER                 disables tracing if "none"
+//	TRACEPARENT                          W3C trace context of a parent span
+func Init(getenv func(string) string) (flush func(context.Context) error, err error) {
+	noop := func(context.Context) error { return nil }
+	if getenv("OTEL_SDK_DISABLED") == "true" || getenv("OTEL_TRACES_EXPORTER") == "none" {
+		return noop, nil
+	}
+	endpoint := getenv("OTEL_EXPORTER_OTLP_TRACES_ENDPOINT")
+	if endpoint == "" {
+		base := getenv("OTEL_EXPORTER_OTLP_ENDPOINT")
+		if base == "" {
+			return noop, nil
+		}
+		endpoint = strings.TrimSuffix(base, "/") + "/v1/traces"
+	}
+	for _, name := range []string{"OTEL_EXPORTER_OTLP_TRACES_PROTOCOL", "OTEL_EXPORTER_OTLP_PROTOCOL"} {
+		if p := getenv(name); p != "" {
+			if p != "http/json" {
+				return nil, fmt.Errorf("unsupported %s value %q; only http/json is supported", name, p)
+			}
+			break
+		}
+	}
+	headersEnv := getenv("OTEL_EXPORTER_OTLP_TRACES_HEADERS")
+	if headersEnv == "" {
+		headersEnv = getenv("OTEL_EXPORTER_OTLP_HEADERS")
+	}
+	headers, err := parseHeaders(headersEnv)
+	if err != nil {
+		return nil, err
+	}
+	t := &tracer{
+		endpoint: endpoint,
+		headers:  headers,
+		service:  getenv("OTEL_SERVICE_NAME"),
+	}
+	if t.service == "" {
+		t.service = "cue"
+	}
+	if !parseTraceParent(getenv("TRACEPARENT"), &t.traceID, &t.parentID) {
+		rand.Read(t.traceID[:])
+	}
+	current.Store(t)
+	return func(ctx context.Context) error {
+		current.CompareAndSwap(t, nil)
+		return t.export(ctx)
+	}, nil
+}
+
+func parseHeaders(s string) (http.Header, error) {
+	h := http.Header{}
+	for _, kv := range strings.Split(s, ",") {
+		if strings.TrimSpace(kv) == "" {
+			continue
+		}
+		k, v, ok := strings.Cut(kv, "=")
+		if !ok {
+			return nil, fmt.Errorf("invalid OTLP header %q; must be key=value", kv)
+		}
+		k, err1 := url.PathUnescape(strings.TrimSpace(k))
+		v, err2 := url.PathUnescape(strings.TrimSpace(v))
+		if err1 != nil || err2 != nil {
+			return nil, fmt.Errorf("invalid OTLP header %q", kv)
+		}
+		h.Add(k, v)
+	}
+	return h, nil
+}
+
+// parseTraceParent parses a W3C traceparent header value, such as
+// 00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01.
+func parseTraceParent(s string, traceID *[16]byte, parentID *[8]byte) bool {
+	parts := strings.Split(s, "-")
+	if len(parts) < 4 || len(parts[1]) != 32 || len(parts[2]) != 16 {
+		return false
+	}
+	if _, err := hex.Decode(traceID[:], []byte(parts[1])); err != nil {
+		return false
+	}
+	if _, err := hex.Decode(parentID[:], []byte(parts[2])); err != nil {
+		return false
+	}
+	return *traceID != [16]byte{}
+}
+
+// A Span records a single phase of work. A nil *Span is valid; its
+// methods do nothing.
+type Span struct {
+	t        *tracer
+	id       [8]byte
+	parentID [8]byte
+	name     string
+	kind     int
+	start    time.Time
+	end      time.Time
+	attrs    []attr
+	err      error
+}
+
+type attr struct {
+	key   string
+	value any // string or int64
+}
+
+// Span kinds as defined by OTLP.
+const (
+	kindInternal = 1
+	kindClient   = 3
+)
+
+type spanKey struct{}
+
+// Start starts a span with the given name. Its parent is the span in ctx,
+// if any, or otherwise the span which was started most recently and has
+// not yet ended. This allows work that is not passed a context, such as
+// loading packages, to be attributed to the enclosing phase.
+//
+// The returned context holds the new span. The span must be ended with
+// [Span.End].
+func Start(ctx context.Context, name string) (context.Context, *Span) {
+	s := start(ctx, name, kindInternal)
+	if s == nil {
+		return ctx, nil
+	}
+	return context.WithValue(ctx, spanKey{}, s), s
+}
+
+func start(ctx context.Context, name string, kind int) *Span {
+	t := current.Load()
+	if t == nil {
+		return nil
+	}
+	s := &Span{
+		t:     t,
+		name:  name,
+		kind:  kind,
+		start: time.Now(),
+	}
+	rand.Read(s.id[:])
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	var parent *Span
+	if ctx != nil {
+		parent, _ = ctx.Value(spanKey{}).(*Span)
+	}
+	switch {
+	case parent != nil && parent.t == t:
+		s.parentID = parent.id
+	case len(t.active) > 0:
+		s.parentID = t.active[len(t.active)-1].id
+	default:
+		s.parentID = t.parentID
+	}
+	t.active = append(t.active, s)
+	return s
+}
+
+// SetString records an attribute of the span with a string value.
+func (s *Span) SetString(key, value string) {
+	if s == nil {
+		return
+	}
+	s.t.mu.Lock()
+	s.attrs = append(s.attrs, attr{key, value})
+	s.t.mu.Unlock()
+}
+
+// SetInt records an attribute of the span with an integer value.
+func (s *Span) SetInt(key string, value int64) {
+	if s == nil {
+		return
+	}
+	s.t.mu.Lock()
+	s.attrs = append(s.attrs, attr{key, value})
+	s.t.mu.Unlock()
+}
+
+// SetError marks the span as failed with the given error, if it is
+// not nil.
+func (s *Span) SetError(err error) {
+	if s == nil || err == nil {
+		return
+	}
+	s.t.mu.Lock()
+	s.err = err
+	s.t.mu.Unlock()
+}
+
+// End ends the span. Calling End more than once has no effect.
+func (s *Span) End() {
+	if s == nil {
+		return
+	}
+	t := s.t
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if !s.end.IsZero() {
+		return
+	}
+	s.end = time.Now()
+	for i := len(t.active) - 1; i >= 0; i-- {
+		if t.active[i] == s {
+			t.active = append(t.active[:i], t.active[i+1:]...)
+			break
+		}
+	}
+	t.ended = append(t.ended, s)
+}
+
+// Transport returns an [http.RoundTripper] which records a client span
+// with the given name for each request made with rt.
+func Transport(name string, rt http.RoundTripper) http.RoundTripper {
+	return &transport{name: name, rt: rt}
+}
+
+type transport struct {
+	name string
+	rt   http.RoundTripper
+}
+
+func (t *transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	s := start(req.Context(), t.name, kindClient)
+	if s == nil {
+		return t.rt.RoundTrip(req)
+	}
+	defer s.End()
+	u := *req.URL
+	u.User = nil
+	u.RawQuery = ""
+	s.SetString("http.request.method", req.Method)
+	s.SetString("url.full", u.String())
+	resp, err := t.rt.RoundTrip(req)
+	if err != nil {
+		s.SetError(err)
+		return nil, err
+	}
+	s.SetInt("http.response.status_code", int64(resp.StatusCode))
+	if resp.StatusCode >= 400 {
+		s.SetError(fmt.Errorf("%s", resp.Status))
+	}
+	return resp, nil
+}
+
+// export sends the ended spans to the configured endpoint.
+func (t *tracer) export(ctx context.Context) error {
+	t.mu.Lock()
+	spans := t.ended
+	t.ended = nil
+	t.mu.Unlock()
+	if len(spans) == 0 {
+		return nil
+	}
+
+	var req otlpRequest
+	rs := otlpResourceSpans{
+		Resource: otlpResource{Attributes: []otlpAttr{
+			stringAttr("service.name", t.service),
+			stringAttr("service.version", cueversion.ModuleVersion()),
+		}},
+	}
+	ss := otlpScopeSpans{Scope: otlpScope{Name: "cuelang.org/go"}}
+	for _, s := range spans {
+		x := otlpSpan{
+			TraceID:           hex.EncodeToString(t.traceID[:]),
+			SpanID:            hex.EncodeToString(s.id[:]),
+			Name:              s.name,
+			Kind:              s.kind,
+			StartTimeUnixNano: strconv.FormatInt(s.start.UnixNano(), 10),
+			EndTimeUnixNano:   strconv.FormatInt(s.end.UnixNano(), 10),
+		}
+		if s.parentID != [8]byte{} {
+			x.ParentSpanID = hex.EncodeToString(s.parentID[:])
+		}
+		for _, a := range s.attrs {
+			switch v := a.value.(type) {
+			case string:
+				x.Attributes = append(x.Attributes, stringAttr(a.key, v))
+			case int64:
+				x.Attributes = append(x.Attributes, otlpAttr{
+					Key:   a.key,
+					Value: otlpValue{IntValue: strconv.FormatInt(v, 10)},
+				})
+			}
+		}
+		if s.err != nil {
+			x.Status = &otlpStatus{Code: 2, Message: s.err.Error()}
+		}
+		ss.Spans = append(ss.Spans, x)
+	}
+	rs.ScopeSpans = append(rs.ScopeSpans, ss)
+	req.ResourceSpans = append(req.ResourceSpans, rs)
+
+	body, err := json.Marshal(req)
+	if err != nil {
+		return err
+	}
+	hreq, err := http.NewRequestWithContext(ctx, "POST", t.endpoint, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("cannot export traces: %v", err)
+	}
+	for k, v := range t.headers {
+		hreq.Header[k] = v
+	}
+	hreq.Header.Set("Content-Type", "application/json")
+	resp, err := http.DefaultClient.Do(hreq)
+	if err != nil {
+		return fmt.Errorf("cannot export traces: %v", err)
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, resp.Body)
+	if resp.StatusCode/100 != 2 {
+		return fmt.Errorf("cannot export traces: %s", resp.Status)
+	}
+	return nil
+}
+
+// The types below encode the JSON form of an OTLP ExportTraceServiceRequest.
+// Note that OTLP/JSON encodes IDs as hexadecimal strings and 64-bit integers
+// as decimal strings.
+
+type otlpRequest struct {
+	ResourceSpans []otlpResourceSpans `json:"resourceSpans"`
+}
+
+type otlpResourceSpans struct {
+	Resource   otlpResource     `json:"resource"`
+	ScopeSpans []otlpScopeSpans `json:"scopeSpans"`
+}
+
+type otlpResource struct {
+	Attributes []otlpAttr `json:"attributes"`
+}
+
+type otlpScopeSpans struct {
+	Scope otlpScope  `json:"scope"`
+	Spans []otlpSpan `json:"spans"`
+}
+
+type otlpScope struct {
+	Name string `json:"name"`
+}
+
+type otlpSpan struct {
+	TraceID           string      `json:"traceId"`
+	SpanID            string      `json:"spanId"`
+	ParentSpanID      string      `json:"parentSpanId,omitempty"`
+	Name              string      `json:"name"`
+	Kind              int         `json:"kind"`
+	StartTimeUnixNano string      `json:"startTimeUnixNano"`
+	EndTimeUnixNano   string      `json:"endTimeUnixNano"`
+	Attributes        []otlpAttr  `json:"attributes,omitempty"`
+	Status            *otlpStatus `json:"status,omitempty"`
+}
+
+type otlpAttr struct {
+	Key   string    `json:"key"`
+	Value otlpValue `json:"value"`
+}
+
+type otlpValue struct {
+	StringValue string `json:"stringValue,omitempty"`
+	IntValue    string `json:"intValue,omitempty"`
+}
+
+type otlpStatus struct {
+	Code    int    `json:"code"`
+	Message string `json:"message,omitempty"`
+}
+
+func stringAttr(key, value string) otlpAttr {
+	return otlpAttr{Key: key, Value: otlpValue{StringValue: value}}
+}
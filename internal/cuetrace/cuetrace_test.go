@@ -0,0 +1,180 @@
+// Copyright 2025 CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cuetrace
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestExport(t *testing.T) {
+	var got otlpRequest
+	var gotHeader, gotContentType string
+	collector := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		gotHeader = req.Header.Get("Authorization")
+		gotContentType = req.Header.Get("Content-Type")
+		if err := json.NewDecoder(req.Body).Decode(&got); err != nil {
+			t.Errorf("cannot decode request: %v", err)
+		}
+	}))
+	defer collector.Close()
+	registry := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer registry.Close()
+
+	env := map[string]string{
+		"OTEL_EXPORTER_OTLP_ENDPOINT": collector.URL + "/",
+		"OTEL_EXPORTER_OTLP_HEADERS":  "Authorization=Bearer%20secret",
+		"TRACEPARENT":                 "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01",
+	}
+	flush, err := Init(func(key string) string { return env[key] })
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, root := Start(context.Background(), "cue export")
+	_, load := Start(context.Background(), "load") // child of root via the active span
+	client := &http.Client{Transport: Transport("registry fetch", http.DefaultTransport)}
+	resp, err := client.Get(registry.URL + "/v2/foo?secret=1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	load.End()
+	_, build := Start(ctx, "build")
+	build.SetError(errors.New("build failed"))
+	build.End()
+	root.End()
+	root.End() // no effect
+
+	if err := flush(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if _, span := Start(ctx, "after"); span != nil {
+		t.Errorf("tracing still enabled after flush")
+	}
+
+	if gotHeader != "Bearer secret" {
+		t.Errorf("got Authorization header %q; want %q", gotHeader, "Bearer secret")
+	}
+	if gotContentType != "application/json" {
+		t.Errorf("got Content-Type %q", gotContentType)
+	}
+	spans := map[string]otlpSpan{}
+	for _, s := range got.ResourceSpans[0].ScopeSpans[0].Spans {
+		if s.TraceID != "4bf92f3577b34da6a3ce929d0e0e4736" {
+			t.Errorf("span %q has trace ID %s", s.Name, s.TraceID)
+		}
+		spans[s.Name] = s
+	}
+	if len(spans) != 4 {
+		t.Fatalf("got %d spans; want 4", len(spans))
+	}
+	parents := map[string]string{
+		"cue export":     "00f067aa0ba902b7",
+		"load":           spans["cue export"].SpanID,
+		"registry fetch": spans["load"].SpanID,
+		"build":          spans["cue export"].SpanID,
+	}
+	for name, want := range parents {
+		if got := spans[name].ParentSpanID; got != want {
+			t.Errorf("span %q has parent %s; want %s", name, got, want)
+		}
+	}
+	if s := spans["build"]; s.Status == nil || s.Status.Code != 2 || s.Status.Message != "build failed" {
+		t.Errorf("span build has status %+v", s.Status)
+	}
+	attrs := map[string]otlpValue{}
+	for _, a := range spans["registry fetch"].Attributes {
+		attrs[a.Key] = a.Value
+	}
+	if got, want := attrs["url.full"].StringValue, registry.URL+"/v2/foo"; got != want {
+		t.Errorf("got url.full %q; want %q", got, want)
+	}
+	if got := attrs["http.response.status_code"].IntValue; got != "404" {
+		t.Errorf("got http.response.status_code %q; want 404", got)
+	}
+}
+
+func TestInit(t *testing.T) {
+	tests := []struct {
+		name    string
+		env     map[string]string
+		enabled bool
+		err     string
+	}{{
+		name: "Unset",
+	}, {
+		name: "Disabled",
+		env: map[string]string{
+			"OTEL_EXPORTER_OTLP_ENDPOINT": "http://localhost:4318",
+			"OTEL_SDK_DISABLED":           "true",
+		},
+	}, {
+		name: "NoExporter",
+		env: map[string]string{
+			"OTEL_EXPORTER_OTLP_ENDPOINT": "http://localhost:4318",
+			"OTEL_TRACES_EXPORTER":        "none",
+		},
+	}, {
+		name: "Enabled",
+		env: map[string]string{
+			"OTEL_EXPORTER_OTLP_TRACES_ENDPOINT": "http://localhost:4318/v1/traces",
+			"OTEL_EXPORTER_OTLP_PROTOCOL":        "http/json",
+		},
+		enabled: true,
+	}, {
+		name: "Protobuf",
+		env: map[string]string{
+			"OTEL_EXPORTER_OTLP_ENDPOINT": "http://localhost:4318",
+			"OTEL_EXPORTER_OTLP_PROTOCOL": "http/protobuf",
+		},
+		err: `unsupported OTEL_EXPORTER_OTLP_PROTOCOL value "http/protobuf"; only http/json is supported`,
+	}, {
+		name: "BadHeader",
+		env: map[string]string{
+			"OTEL_EXPORTER_OTLP_ENDPOINT": "http://localhost:4318",
+			"OTEL_EXPORTER_OTLP_HEADERS":  "foo",
+		},
+		err: `invalid OTLP header "foo"; must be key=value`,
+	}}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			flush, err := Init(func(key string) string { return tc.env[key] })
+			if tc.err != "" {
+				if err == nil || err.Error() != tc.err {
+					t.Fatalf("got error %v; want %q", err, tc.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			_, span := Start(context.Background(), "x")
+			if got := span != nil; got != tc.enabled {
+				t.Errorf("got enabled %v; want %v", got, tc.enabled)
+			}
+			// No spans have ended, so nothing is exported.
+			if err := flush(context.Background()); err != nil {
+				t.Fatal(err)
+			}
+		})
+	}
+}
@@ -26,6 +26,17 @@ type Profile struct {
 	// package.
 	SkipHidden bool
 
+	// Ordered reports the fields of a struct which appear in a different
+	// order relative to the other fields of the struct as removed from
+	// their position in x and added at their position in y.
+	Ordered bool
+
+	// SchemaOnly only reports differences which affect the schema of a
+	// value: values which are concrete and consist of regular fields only
+	// are considered identical, unless they are part of a definition or
+	// of an optional or required field.
+	SchemaOnly bool
+
 	// TODO: Use this method instead of SkipHidden. To do this, we need to have
 	// access the package associated with a hidden field, which is only
 	// accessible through the Iterator API. And we should probably get rid of
@@ -94,9 +105,16 @@ type Edit struct {
 
 type differ struct {
 	cfg Profile
+
+	// inSchema is set when comparing the values of definitions and of
+	// optional or required fields.
+	inSchema bool
 }
 
 func (d *differ) diffValue(x, y cue.Value) (Kind, *EditScript) {
+	if d.cfg.SchemaOnly && !d.inSchema && isData(x) && isData(y) {
+		return Identity, nil
+	}
 	if d.cfg.Concrete {
 		x, _ = x.Default()
 		y, _ = y.Default()
@@ -148,9 +166,76 @@ func (d *differ) collectFields(v cue.Value) []field {
 	return fields
 }
 
+// ignore reports whether the field or element with selector sel and value
+// v, which only exists in one of the values, is ignored.
+func (d *differ) ignore(sel cue.Selector, v cue.Value) bool {
+	return d.cfg.SchemaOnly && !d.inSchema &&
+		!sel.IsDefinition() && sel.ConstraintType() == 0 && isData(v)
+}
+
+// isData reports whether v is concrete and consists of regular fields only.
+func isData(v cue.Value) bool {
+	if !v.IsConcrete() {
+		return false
+	}
+	switch v.Kind() {
+	case cue.StructKind:
+		iter, _ := v.Fields(cue.Definitions(true), cue.Optional(true))
+		for iter.Next() {
+			sel := iter.Selector()
+			if sel.IsDefinition() || sel.ConstraintType() != 0 || !isData(iter.Value()) {
+				return false
+			}
+		}
+	case cue.ListKind:
+		for iter, _ := v.List(); iter.Next(); {
+			if !isData(iter.Value()) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// diffField compares the fields xf and yf, which have the same label.
+func (d *differ) diffField(xf, yf field) Edit {
+	if xf.sel.IsDefinition() != yf.sel.IsDefinition() || xf.sel.ConstraintType() != yf.sel.ConstraintType() {
+		return Edit{Modified, xf.sel, yf.sel, nil}
+	}
+	inSchema := d.inSchema
+	d.inSchema = inSchema || xf.sel.IsDefinition() || xf.sel.ConstraintType() != 0
+	defer func() { d.inSchema = inSchema }()
+
+	// TODO(perf): consider evaluating lazily.
+	kind, script := d.diffValue(xf.val, yf.val)
+	return Edit{kind, xf.sel, yf.sel, script}
+}
+
+// sameOrder reports whether the fields which x and y have in common appear
+// in the same order.
+func sameOrder(xFields, yFields []field) bool {
+	yPos := make(map[cue.Selector]int, len(yFields))
+	for i, f := range yFields {
+		yPos[f.sel] = i + 1
+	}
+	last := 0
+	for _, f := range xFields {
+		if p := yPos[f.sel]; p > 0 {
+			if p < last {
+				return false
+			}
+			last = p
+		}
+	}
+	return true
+}
+
 func (d *differ) diffStruct(x, y cue.Value) (Kind, *EditScript) {
 	xFields := d.collectFields(x)
 	yFields := d.collectFields(y)
+	if d.cfg.Ordered && !sameOrder(xFields, yFields) {
+		return d.diffStructOrdered(x, y, xFields, yFields)
+	}
 
 	// Best-effort topological sort, prioritizing x over y, using a variant of
 	// Kahn's algorithm (see, for instance
@@ -178,8 +263,10 @@ func (d *differ) diffStruct(x, y cue.Value) (Kind, *EditScript) {
 			if yp > 0 {
 				break
 			}
-			edits = append(edits, Edit{UniqueX, xf.sel, cue.Selector{}, nil})
-			differs = true
+			if !d.ignore(xf.sel, xf.val) {
+				edits = append(edits, Edit{UniqueX, xf.sel, cue.Selector{}, nil})
+				differs = true
+			}
 		}
 		for ; yi < len(yFields); yi++ {
 			yf := yFields[yi]
@@ -191,8 +278,10 @@ func (d *differ) diffStruct(x, y cue.Value) (Kind, *EditScript) {
 				break
 			}
 			yMap[yf.sel] = 0
-			edits = append(edits, Edit{UniqueY, cue.Selector{}, yf.sel, nil})
-			differs = true
+			if !d.ignore(yf.sel, yf.val) {
+				edits = append(edits, Edit{UniqueY, cue.Selector{}, yf.sel, nil})
+				differs = true
+			}
 		}
 
 		// Compare nodes
@@ -205,20 +294,60 @@ func (d *differ) diffStruct(x, y cue.Value) (Kind, *EditScript) {
 			// If yp != xi+1, the topological sort was not possible.
 			yMap[xf.sel] = 0
 
-			yf := yFields[yp-1]
+			e := d.diffField(xf, yFields[yp-1])
+			edits = append(edits, e)
+			differs = differs || e.Kind != Identity
+		}
+	}
+	if !differs {
+		return Identity, nil
+	}
+	return Modified, &EditScript{X: x, Y: y, Edits: edits}
+}
 
-			var kind Kind
-			var script *EditScript
-			switch {
-			case xf.sel.IsDefinition() != yf.sel.IsDefinition(), xf.sel.ConstraintType() != yf.sel.ConstraintType():
-				kind = Modified
-			default:
-				// TODO(perf): consider evaluating lazily.
-				kind, script = d.diffValue(xf.val, yf.val)
+// diffStructOrdered compares the fields of x and y which appear in the
+// longest common sequence of their labels, and reports the other fields as
+// only existing in either x or y.
+func (d *differ) diffStructOrdered(x, y cue.Value, xFields, yFields []field) (Kind, *EditScript) {
+	// lcs[i][j] is the length of the longest common sequence of the labels
+	// of xFields[i:] and yFields[j:].
+	lcs := make([][]int, len(xFields)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(yFields)+1)
+	}
+	for i := len(xFields) - 1; i >= 0; i-- {
+		for j := len(yFields) - 1; j >= 0; j-- {
+			if xFields[i].sel == yFields[j].sel {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
 			}
+		}
+	}
 
-			edits = append(edits, Edit{kind, xf.sel, yf.sel, script})
-			differs = differs || kind != Identity
+	edits := []Edit{}
+	differs := false
+	i, j := 0, 0
+	for i < len(xFields) || j < len(yFields) {
+		switch {
+		case i < len(xFields) && j < len(yFields) && xFields[i].sel == yFields[j].sel:
+			e := d.diffField(xFields[i], yFields[j])
+			edits = append(edits, e)
+			differs = differs || e.Kind != Identity
+			i++
+			j++
+		case j == len(yFields) || (i < len(xFields) && lcs[i+1][j] >= lcs[i][j+1]):
+			if !d.ignore(xFields[i].sel, xFields[i].val) {
+				edits = append(edits, Edit{UniqueX, xFields[i].sel, cue.Selector{}, nil})
+				differs = true
+			}
+			i++
+		default:
+			if !d.ignore(yFields[j].sel, yFields[j].val) {
+				edits = append(edits, Edit{UniqueY, cue.Selector{}, yFields[j].sel, nil})
+				differs = true
+			}
+			j++
 		}
 	}
 	if !differs {
@@ -244,8 +373,10 @@ func (d *differ) diffList(x, y cue.Value) (Kind, *EditScript) {
 		hasY := iy.Next()
 		if !hasX {
 			for hasY {
-				differs = true
-				edits = append(edits, Edit{UniqueY, cue.Selector{}, cue.Index(i), nil})
+				if !d.ignore(cue.Index(i), iy.Value()) {
+					differs = true
+					edits = append(edits, Edit{UniqueY, cue.Selector{}, cue.Index(i), nil})
+				}
 				hasY = iy.Next()
 				i++
 			}
@@ -253,8 +384,10 @@ func (d *differ) diffList(x, y cue.Value) (Kind, *EditScript) {
 		}
 		if !hasY {
 			for hasX {
-				differs = true
-				edits = append(edits, Edit{UniqueX, cue.Index(i), cue.Selector{}, nil})
+				if !d.ignore(cue.Index(i), ix.Value()) {
+					differs = true
+					edits = append(edits, Edit{UniqueX, cue.Index(i), cue.Selector{}, nil})
+				}
 				hasX = ix.Next()
 				i++
 			}
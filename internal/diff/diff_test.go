@@ -402,6 +402,53 @@ a: x: "hello"
 		name: "all errors are equal",
 		x:    `1 & 3`,
 		y:    `1 & 4`,
+	}, {
+		name:    "ordered identity",
+		x:       `{a: 1, b: 2, c: 3}`,
+		y:       `{a: 1, b: 2, c: 3}`,
+		profile: &Profile{Ordered: true},
+	}, {
+		name:    "ordered moved field",
+		x:       `{a: 1, b: 2, c: 3, d: 4}`,
+		y:       `{b: 2, c: 5, d: 4, a: 1}`,
+		profile: &Profile{Ordered: true},
+		kind:    Modified,
+		diff: `  {
+-     a: 1
+      b: 2
+-     c: 3
++     c: 5
+      d: 4
++     a: 1
+  }
+`,
+	}, {
+		name:    "schema only",
+		x:       `{a: 1, b: "x", l: [1], #D: {a: 1}, o?: int, s: {x: int, y: 1}}`,
+		y:       `{a: 2, c: true, l: [1, 2], #D: {a: 2}, o?: string, s: {x: string, y: 2}}`,
+		profile: &Profile{SchemaOnly: true},
+		kind:    Modified,
+		diff: `  {
+      a: 1
+      l: [1]
+      #D: {
+-         a: 1
++         a: 2
+      }
+-     o?: int
++     o?: string
+      s: {
+-         x: int
++         x: string
+          y: 1
+      }
+  }
+`,
+	}, {
+		name:    "schema only identity",
+		x:       `{a: 1, b: {c: [1, 2]}, #D: int}`,
+		y:       `{a: 2, d: "x", #D: int}`,
+		profile: &Profile{SchemaOnly: true},
 	}}
 	for _, tc := range testCases {
 		cuetdtest.FullMatrix.Run(t, tc.name, func(t *testing.T, m *cuetdtest.M) {
@@ -0,0 +1,128 @@
+// Copyright 2025 CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package diff
+
+import (
+	"encoding/json"
+	"slices"
+	"strconv"
+	"strings"
+
+	"cuelang.org/go/cue"
+)
+
+// A PatchOp is an operation of a JSON Patch, as defined by RFC 6902.
+type PatchOp struct {
+	Op    string          `json:"op"`
+	Path  string          `json:"path"`
+	Value json.RawMessage `json:"value,omitempty"`
+}
+
+// JSONPatch returns the JSON Patch which transforms the JSON encoding of
+// es.X into that of es.Y. Fields which are not part of the JSON encoding of
+// a value, such as definitions and hidden or optional fields, are ignored.
+// As the fields of a JSON object are unordered, es should not report the
+// fields which were moved, as with [Profile.Ordered].
+func JSONPatch(es *EditScript) ([]PatchOp, error) {
+	p := patcher{ops: []PatchOp{}}
+	if es != nil {
+		p.script(es, "")
+	}
+	return p.ops, p.err
+}
+
+type patcher struct {
+	ops []PatchOp
+	err error
+}
+
+var pointerEscaper = strings.NewReplacer("~", "~0", "/", "~1")
+
+func (p *patcher) add(op, path string, v cue.Value) {
+	patch := PatchOp{Op: op, Path: path}
+	if v.Exists() {
+		b, err := v.MarshalJSON()
+		if err != nil && p.err == nil {
+			p.err = err
+		}
+		patch.Value = b
+	}
+	p.ops = append(p.ops, patch)
+}
+
+func (p *patcher) script(es *EditScript, path string) {
+	switch es.X.Kind() {
+	case cue.StructKind:
+		p.edits(es, func(sel cue.Selector) (string, bool) {
+			if sel.LabelType() != cue.StringLabel || sel.ConstraintType() != 0 {
+				return "", false
+			}
+			return path + "/" + pointerEscaper.Replace(sel.Unquoted()), true
+		})
+	case cue.ListKind:
+		p.edits(es, func(sel cue.Selector) (string, bool) {
+			return path + "/" + strconv.Itoa(sel.Index()), true
+		})
+	default:
+		p.add("replace", path, es.Y)
+	}
+}
+
+// edits adds the operations for the edits of es, where pointer returns the
+// JSON Pointer of the field or element with the given selector, if it is
+// part of the JSON encoding.
+func (p *patcher) edits(es *EditScript, pointer func(cue.Selector) (string, bool)) {
+	// Elements are removed from the end of lists first, so that the
+	// indices of the other elements do not change.
+	var removed []string
+	for _, e := range es.Edits {
+		switch e.Kind {
+		case UniqueX:
+			if xp, ok := pointer(e.XSel); ok {
+				removed = append(removed, xp)
+			}
+		case Modified:
+			xp, xok := pointer(e.XSel)
+			if _, yok := pointer(e.YSel); xok && !yok {
+				removed = append(removed, xp)
+			}
+		}
+	}
+	slices.Reverse(removed)
+	for _, ptr := range removed {
+		p.ops = append(p.ops, PatchOp{Op: "remove", Path: ptr})
+	}
+
+	for _, e := range es.Edits {
+		switch e.Kind {
+		case UniqueY:
+			if yp, ok := pointer(e.YSel); ok {
+				p.add("add", yp, es.Y.LookupPath(cue.MakePath(e.YSel)))
+			}
+		case Modified:
+			yp, yok := pointer(e.YSel)
+			_, xok := pointer(e.XSel)
+			switch {
+			case !yok:
+			case !xok:
+				p.add("add", yp, es.Y.LookupPath(cue.MakePath(e.YSel)))
+			case e.Sub != nil:
+				p.script(e.Sub, yp)
+			default:
+				p.add("replace", yp, es.Y.LookupPath(cue.MakePath(e.YSel)))
+			}
+		}
+	}
+}
@@ -0,0 +1,80 @@
+// Copyright 2025 CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package diff
+
+import (
+	"encoding/json"
+	"testing"
+
+	"cuelang.org/go/cue"
+	"cuelang.org/go/cue/cuecontext"
+)
+
+func TestJSONPatch(t *testing.T) {
+	testCases := []struct {
+		name string
+		x, y string
+		want string
+	}{{
+		name: "identity",
+		x:    `{a: 1}`,
+		y:    `{a: 1}`,
+		want: `[]`,
+	}, {
+		name: "value",
+		x:    `1`,
+		y:    `"x"`,
+		want: `[{"op":"replace","path":"","value":"x"}]`,
+	}, {
+		name: "struct",
+		x:    `{a: 1, b: {c: 2, d: 3}, "e/f": 4, g: 5}`,
+		y:    `{a: 1, b: {c: 3, d: 3}, "e/f": 4, "h~": [6]}`,
+		want: `[{"op":"remove","path":"/g"},{"op":"replace","path":"/b/c","value":3},{"op":"add","path":"/h~0","value":[6]}]`,
+	}, {
+		name: "escaped",
+		x:    `{"e/f": 4}`,
+		y:    `{"e/f": 5}`,
+		want: `[{"op":"replace","path":"/e~1f","value":5}]`,
+	}, {
+		name: "list",
+		x:    `{l: [1, 2, 3, 4], m: [1]}`,
+		y:    `{l: [1, 5], m: [1, {a: 2}]}`,
+		want: `[{"op":"remove","path":"/l/3"},{"op":"remove","path":"/l/2"},{"op":"replace","path":"/l/1","value":5},{"op":"add","path":"/m/1","value":{"a":2}}]`,
+	}, {
+		name: "schema fields",
+		x:    `{a?: int, b: 1, #D: 1, _h: 1}`,
+		y:    `{a: 2, b?: int, #D: 2, _h: 2}`,
+		want: `[{"op":"remove","path":"/b"},{"op":"add","path":"/a","value":2}]`,
+	}}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx := cuecontext.New()
+			x := ctx.CompileString(tc.x, cue.Filename("x"))
+			y := ctx.CompileString(tc.y, cue.Filename("y"))
+			_, es := Schema.Diff(x, y)
+			ops, err := JSONPatch(es)
+			if err != nil {
+				t.Fatal(err)
+			}
+			b, err := json.Marshal(ops)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := string(b); got != tc.want {
+				t.Errorf("got\n%s\nwant\n%s", got, tc.want)
+			}
+		})
+	}
+}
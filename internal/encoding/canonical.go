@@ -0,0 +1,234 @@
+// Copyright 2025 CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package encoding
+
+import (
+	"bytes"
+	"cmp"
+	"encoding/json"
+	"slices"
+	"strings"
+
+	"github.com/cockroachdb/apd/v3"
+
+	"cuelang.org/go/cue"
+	"cuelang.org/go/cue/ast"
+	"cuelang.org/go/cue/format"
+	"cuelang.org/go/cue/literal"
+	"cuelang.org/go/cue/parser"
+	"cuelang.org/go/cue/token"
+)
+
+// The canonical forms of CUE and JSON are deterministic byte
+// representations of a value, such as for hashing and signing it: two
+// values which are equal have the same canonical form, regardless of the
+// order of their fields and the way their literals and comments were
+// written in the source.
+
+// canonicalCUE returns the canonical form of f: comments are removed,
+// fields are sorted by their labels and the operands of conjunctions by
+// their source, numbers and strings are written in a single normalized
+// form, and all formatting is left to the defaults of the formatter.
+//
+// The syntax of f may be shared with values, so a copy of it is rewritten.
+func canonicalCUE(f *ast.File, opts ...format.Option) ([]byte, error) {
+	b, err := format.Node(f)
+	if err != nil {
+		return nil, err
+	}
+	f, err = parser.ParseFile(f.Filename, b)
+	if err != nil {
+		return nil, err
+	}
+	ast.Walk(f, func(n ast.Node) bool {
+		ast.SetComments(n, nil)
+		ast.SetRelPos(n, token.NoRelPos)
+		switch n := n.(type) {
+		case *ast.File:
+			// The package clause and imports must come first.
+			i := 0
+			for i < len(n.Decls) {
+				switch n.Decls[i].(type) {
+				case *ast.Package, *ast.ImportDecl, *ast.CommentGroup, *ast.Attribute:
+					i++
+					continue
+				}
+				break
+			}
+			sortFields(n.Decls[i:])
+		case *ast.StructLit:
+			sortFields(n.Elts)
+		case *ast.BasicLit:
+			canonicalLit(n)
+		}
+		return true
+	}, func(n ast.Node) {
+		if x, ok := n.(*ast.BinaryExpr); ok && x.Op == token.AND {
+			sortConjuncts(x)
+		}
+	})
+	return format.Node(f, opts...)
+}
+
+// sortConjuncts sorts the operands of the conjunctions in x by their
+// source, as the order of the operands of a conjunction has no effect on
+// its value.
+func sortConjuncts(x *ast.BinaryExpr) {
+	var operands []ast.Expr
+	var flatten func(e ast.Expr)
+	flatten = func(e ast.Expr) {
+		if b, ok := e.(*ast.BinaryExpr); ok && b.Op == token.AND {
+			flatten(b.X)
+			flatten(b.Y)
+			return
+		}
+		operands = append(operands, e)
+	}
+	flatten(x)
+	keys := make(map[ast.Expr]string, len(operands))
+	for _, e := range operands {
+		b, _ := format.Node(e)
+		keys[e] = string(b)
+	}
+	slices.SortStableFunc(operands, func(a, b ast.Expr) int {
+		return cmp.Compare(keys[a], keys[b])
+	})
+	var y ast.Expr = operands[0]
+	for _, e := range operands[1 : len(operands)-1] {
+		y = &ast.BinaryExpr{X: y, Op: token.AND, Y: e}
+	}
+	x.X, x.Y = y, operands[len(operands)-1]
+}
+
+// sortFields sorts the fields of decls by their labels, keeping all other
+// declarations in place.
+func sortFields(decls []ast.Decl) {
+	var idx []int
+	var fields []*ast.Field
+	for i, d := range decls {
+		if f, ok := d.(*ast.Field); ok {
+			idx = append(idx, i)
+			fields = append(fields, f)
+		}
+	}
+	slices.SortStableFunc(fields, func(a, b *ast.Field) int {
+		return cmp.Compare(labelKey(a.Label), labelKey(b.Label))
+	})
+	for i, f := range fields {
+		decls[idx[i]] = f
+	}
+}
+
+// labelKey returns the name of a label, or its source if it has none, such
+// as for pattern constraints.
+func labelKey(l ast.Label) string {
+	if name, _, err := ast.LabelName(l); err == nil {
+		return name
+	}
+	b, _ := format.Node(l)
+	return string(b)
+}
+
+// canonicalLit normalizes the value of a number, string, or bytes literal.
+func canonicalLit(x *ast.BasicLit) {
+	switch x.Kind {
+	case token.INT, token.FLOAT:
+		var info literal.NumInfo
+		if err := literal.ParseNum(x.Value, &info); err != nil {
+			return
+		}
+		var d apd.Decimal
+		if err := info.Decimal(&d); err != nil {
+			return
+		}
+		x.Value = canonicalNumber(&d, !info.IsInt())
+	case token.STRING:
+		s, err := literal.Unquote(x.Value)
+		if err != nil {
+			return
+		}
+		if strings.HasPrefix(x.Value, "'") || strings.HasPrefix(strings.TrimLeft(x.Value, "#"), "'") {
+			x.Value = literal.Bytes.Quote(s)
+		} else {
+			x.Value = literal.String.Quote(s)
+		}
+	}
+}
+
+// canonicalNumber returns the shortest representation of d without
+// trailing zeros, using an exponent only for very large or small numbers.
+// Floats keep a decimal point or an exponent if keepFloat is set.
+func canonicalNumber(d *apd.Decimal, keepFloat bool) string {
+	var r apd.Decimal
+	r.Reduce(d)
+	var s string
+	if adj := int64(r.Exponent) + r.NumDigits() - 1; adj >= -6 && adj < 21 {
+		s = r.Text('f')
+	} else {
+		s = r.Text('e')
+	}
+	if s == "-0" {
+		s = "0"
+	}
+	if keepFloat && !strings.ContainsAny(s, ".e") {
+		s += ".0"
+	}
+	return s
+}
+
+// canonicalJSON returns the canonical JSON form of v: it has no
+// whitespace, object keys are sorted, and numbers are written as by
+// canonicalNumber, without distinguishing integers and floats.
+func canonicalJSON(v cue.Value, escapeHTML bool) ([]byte, error) {
+	b, err := v.MarshalJSON()
+	if err != nil {
+		return nil, err
+	}
+	d := json.NewDecoder(bytes.NewReader(b))
+	d.UseNumber()
+	var x any
+	if err := d.Decode(&x); err != nil {
+		return nil, err
+	}
+	x = canonicalJSONNumbers(x)
+
+	// Encoding maps sorts their keys.
+	var buf bytes.Buffer
+	e := json.NewEncoder(&buf)
+	e.SetEscapeHTML(escapeHTML)
+	if err := e.Encode(x); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func canonicalJSONNumbers(x any) any {
+	switch x := x.(type) {
+	case map[string]any:
+		for k, v := range x {
+			x[k] = canonicalJSONNumbers(v)
+		}
+	case []any:
+		for i, v := range x {
+			x[i] = canonicalJSONNumbers(v)
+		}
+	case json.Number:
+		var d apd.Decimal
+		if _, _, err := d.SetString(string(x)); err == nil {
+			return json.Number(canonicalNumber(&d, false))
+		}
+	}
+	return x
+}
@@ -0,0 +1,62 @@
+// Copyright 2025 CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package encoding
+
+import (
+	"testing"
+
+	"github.com/go-quicktest/qt"
+
+	"cuelang.org/go/cue/cuecontext"
+	"cuelang.org/go/cue/parser"
+)
+
+func TestCanonicalCUE(t *testing.T) {
+	testCases := []struct {
+		in, out string
+	}{{
+		in:  "b: 1\na: 2\n",
+		out: "a: 2\nb: 1\n",
+	}, {
+		in:  "// doc\nx: 1 // trailing\n",
+		out: "x: 1\n",
+	}, {
+		in:  "a: 0x10\nb: 1_000\nc: 1Ki\nd: 1.50\ne: 5e-1\nf: 1e30\ng: 2.0\n",
+		out: "a: 16\nb: 1000\nc: 1024\nd: 1.5\ne: 0.5\nf: 1e+30\ng: 2.0\n",
+	}, {
+		in:  "a: #\"x\"#\nb: \"\"\"\n\tline\n\t\"\"\"\nc: '\\x41'\n",
+		out: "a: \"x\"\nb: \"line\"\nc: 'A'\n",
+	}, {
+		in:  "x: string & =~\"^a\" & !=\"\"\n",
+		out: "x: !=\"\" & =~\"^a\" & string\n",
+	}, {
+		in:  "package p\n\nimport \"strings\"\n\nz: strings.ToUpper(\"a\")\ny: {d: 1, c: 2}\n",
+		out: "package p\n\nimport \"strings\"\n\ny: {\n\tc: 2\n\td: 1\n}\nz: strings.ToUpper(\"a\")\n",
+	}}
+	for _, tc := range testCases {
+		f, err := parser.ParseFile("in.cue", tc.in, parser.ParseComments)
+		qt.Assert(t, qt.IsNil(err))
+		b, err := canonicalCUE(f)
+		qt.Assert(t, qt.IsNil(err))
+		qt.Check(t, qt.Equals(string(b), tc.out), qt.Commentf("%s", tc.in))
+	}
+}
+
+func TestCanonicalJSON(t *testing.T) {
+	v := cuecontext.New().CompileString(`{z: 1.50, a: [2.0, 1e3, "<&>"], m: {y: null, x: true}}`)
+	b, err := canonicalJSON(v, false)
+	qt.Assert(t, qt.IsNil(err))
+	qt.Check(t, qt.Equals(string(b), `{"a":[2,1000,"<&>"],"m":{"x":true,"y":null},"z":1.5}`+"\n"))
+}
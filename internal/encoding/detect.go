@@ -15,12 +15,21 @@
 package encoding
 
 import (
+	"bytes"
+	stdjson "encoding/json"
+	"errors"
+	"io"
 	"net/url"
 	"path"
 	"strings"
 
+	"gopkg.in/yaml.v3"
+
 	"cuelang.org/go/cue"
+	"cuelang.org/go/cue/ast"
 	"cuelang.org/go/cue/build"
+	"cuelang.org/go/cue/parser"
+	"cuelang.org/go/encoding/toml"
 )
 
 // Detect detects the interpretation.
@@ -65,3 +74,86 @@ func isJSONSchema(v cue.Value) bool {
 	}
 	return true
 }
+
+// DetectEncoding detects the encoding of src, such as the contents of
+// standard input given without a file type, from its syntax. It returns
+// JSON or JSONL for JSON values, CUE for CUE which only refers to its own
+// fields and predeclared identifiers, TOML, or otherwise YAML. Content
+// which matches none of these is reported as CUE, so that errors are
+// reported for CUE syntax.
+func DetectEncoding(src []byte) build.Encoding {
+	if len(bytes.TrimSpace(src)) == 0 {
+		return build.CUE
+	}
+	if n, ok := countJSON(src); ok {
+		if n > 1 {
+			return build.JSONL
+		}
+		return build.JSON
+	}
+	// Text such as "a: b" is both valid CUE and YAML. Unless b is
+	// predeclared, CUE would fail to resolve it, so YAML is the better
+	// guess in that case.
+	f, err := parser.ParseFile("-", src)
+	if err == nil && allPredeclared(f.Unresolved) {
+		return build.CUE
+	}
+	if _, err := toml.NewDecoder("-", bytes.NewReader(src)).Decode(); err == nil {
+		return build.TOML
+	}
+	if isYAML(src) {
+		return build.YAML
+	}
+	return build.CUE
+}
+
+// countJSON reports the number of JSON values in src and whether src
+// consists of JSON values only.
+func countJSON(src []byte) (n int, ok bool) {
+	d := stdjson.NewDecoder(bytes.NewReader(src))
+	for {
+		var v stdjson.RawMessage
+		switch err := d.Decode(&v); {
+		case err == io.EOF:
+			return n, n > 0
+		case err != nil:
+			return n, false
+		}
+		n++
+	}
+}
+
+func isYAML(src []byte) bool {
+	d := yaml.NewDecoder(bytes.NewReader(src))
+	for {
+		var n yaml.Node
+		err := d.Decode(&n)
+		if errors.Is(err, io.EOF) {
+			return true
+		}
+		if err != nil {
+			return false
+		}
+	}
+}
+
+func allPredeclared(idents []*ast.Ident) bool {
+	for _, x := range idents {
+		if !predeclared[x.Name] && !strings.HasPrefix(x.Name, "__") {
+			return false
+		}
+	}
+	return true
+}
+
+// predeclared holds the identifiers predeclared by CUE.
+var predeclared = map[string]bool{
+	"_": true, "string": true, "bytes": true, "bool": true, "int": true,
+	"float": true, "number": true, "len": true, "close": true,
+	"matchIf": true, "matchN": true, "and": true, "or": true,
+	"div": true, "mod": true, "quo": true, "rem": true,
+	"rune": true, "byte": true, "uint": true,
+	"int8": true, "int16": true, "int32": true, "int64": true, "int128": true,
+	"uint8": true, "uint16": true, "uint32": true, "uint64": true, "uint128": true,
+	"float32": true, "float64": true,
+}
@@ -100,3 +100,67 @@ func TestDetect(t *testing.T) {
 		})
 	}
 }
+
+func TestDetectEncoding(t *testing.T) {
+	testCases := []struct {
+		name string
+		in   string
+		out  build.Encoding
+	}{{
+		name: "empty",
+		in:   "\n",
+		out:  build.CUE,
+	}, {
+		name: "jsonObject",
+		in:   `{"a": 1, "b": [true, null]}`,
+		out:  build.JSON,
+	}, {
+		name: "jsonScalar",
+		in:   `"foo"`,
+		out:  build.JSON,
+	}, {
+		name: "jsonStream",
+		in:   "{\"a\": 1}\n{\"a\": 2}\n",
+		out:  build.JSONL,
+	}, {
+		name: "cue",
+		in:   "a: int\nb: a + 1\n",
+		out:  build.CUE,
+	}, {
+		name: "cuePackage",
+		in:   "package foo\n\nimport \"strings\"\n\na: strings.ToUpper(\"x\")\n",
+		out:  build.CUE,
+	}, {
+		name: "cueNested",
+		in:   "a: b: c: 1\n",
+		out:  build.CUE,
+	}, {
+		name: "yaml",
+		in:   "name: web\nport: 80\n",
+		out:  build.YAML,
+	}, {
+		name: "yamlList",
+		in:   "- a\n- b\n",
+		out:  build.YAML,
+	}, {
+		name: "yamlStream",
+		in:   "a: 1\n---\na: 2\n",
+		out:  build.YAML,
+	}, {
+		name: "toml",
+		in:   "title = \"x\"\n\n[server]\nport = 80\n",
+		out:  build.TOML,
+	}, {
+		name: "invalid",
+		in:   "a: {\n",
+		out:  build.CUE,
+	}}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got := DetectEncoding([]byte(tc.in))
+			if got != tc.out {
+				t.Errorf("got %v; want %v", got, tc.out)
+			}
+		})
+	}
+}
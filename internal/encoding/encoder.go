@@ -29,12 +29,15 @@ import (
 	"cuelang.org/go/cue/errors"
 	"cuelang.org/go/cue/format"
 	"cuelang.org/go/cue/token"
+	cueencoding "cuelang.org/go/encoding"
+	cuejson "cuelang.org/go/encoding/json"
 	"cuelang.org/go/encoding/openapi"
 	"cuelang.org/go/encoding/protobuf/jsonpb"
 	"cuelang.org/go/encoding/protobuf/textproto"
 	"cuelang.org/go/encoding/toml"
 	"cuelang.org/go/encoding/yaml"
 	"cuelang.org/go/internal"
+	internalyaml "cuelang.org/go/internal/encoding/yaml"
 	"cuelang.org/go/internal/filetypes"
 )
 
@@ -69,6 +72,13 @@ func (e Encoder) Close() error {
 
 // NewEncoder writes content to the file with the given specification.
 func NewEncoder(ctx *cue.Context, f *build.File, cfg *Config) (*Encoder, error) {
+	switch {
+	case !cfg.Canonical:
+	case f.Encoding != build.CUE && f.Encoding != build.JSON && f.Encoding != build.JSONL:
+		return nil, fmt.Errorf("canonical output is only supported for CUE and JSON, not %s", f.Encoding)
+	case f.Interpretation != "":
+		return nil, fmt.Errorf("canonical output is not supported for %s", f.Interpretation)
+	}
 	w, close := writer(f, cfg)
 	e := &Encoder{
 		ctx:   ctx,
@@ -124,7 +134,9 @@ func NewEncoder(ctx *cue.Context, f *build.File, cfg *Config) (*Encoder, error)
 		)
 
 		opts := []format.Option{}
-		opts = append(opts, cfg.Format...)
+		if !cfg.Canonical {
+			opts = append(opts, cfg.Format...)
+		}
 
 		useSep := false
 		format := func(name string, n ast.Node) error {
@@ -154,7 +166,13 @@ func NewEncoder(ctx *cue.Context, f *build.File, cfg *Config) (*Encoder, error)
 				ast.SetComments(f, rest)
 				f.Decls = append([]ast.Decl{pkg}, f.Decls...)
 			}
-			b, err := format.Node(f, opts...)
+			var b []byte
+			var err error
+			if cfg.Canonical {
+				b, err = canonicalCUE(f, opts...)
+			} else {
+				b, err = format.Node(f, opts...)
+			}
 			if err != nil {
 				return err
 			}
@@ -168,6 +186,41 @@ func NewEncoder(ctx *cue.Context, f *build.File, cfg *Config) (*Encoder, error)
 
 	case build.JSON, build.JSONL:
 		e.concrete = true
+		original, err := originalFile(f, cfg)
+		if err != nil {
+			return nil, err
+		}
+		if cfg.Canonical {
+			e.encValue = func(v cue.Value) error {
+				b, err := canonicalJSON(v, cfg.EscapeHTML)
+				if err != nil {
+					return err
+				}
+				_, err = w.Write(b)
+				return err
+			}
+			break
+		}
+		if f.Encoding == build.JSON && original != nil {
+			e.encValue = func(v cue.Value) error {
+				var buf bytes.Buffer
+				d := json.NewEncoder(&buf)
+				d.SetEscapeHTML(cfg.EscapeHTML)
+				if err := d.Encode(v); err != nil {
+					if x, ok := err.(*json.MarshalerError); ok {
+						err = x.Err
+					}
+					return err
+				}
+				b, err := cuejson.FormatLike(buf.Bytes(), original)
+				if err != nil {
+					return err
+				}
+				_, err = w.Write(b)
+				return err
+			}
+			break
+		}
 		d := json.NewEncoder(w)
 		d.SetIndent("", "    ")
 		d.SetEscapeHTML(cfg.EscapeHTML)
@@ -181,6 +234,14 @@ func NewEncoder(ctx *cue.Context, f *build.File, cfg *Config) (*Encoder, error)
 
 	case build.YAML:
 		e.concrete = true
+		original, err := originalFile(f, cfg)
+		if err != nil {
+			return nil, err
+		}
+		docs, err := internalyaml.Documents(original)
+		if err != nil {
+			return nil, fmt.Errorf("cannot keep formatting of %s: %v", f.Filename, err)
+		}
 		streamed := false
 		// TODO(mvdan): use a NewEncoder API like in TOML below.
 		e.encValue = func(v cue.Value) error {
@@ -189,7 +250,16 @@ func NewEncoder(ctx *cue.Context, f *build.File, cfg *Config) (*Encoder, error)
 			}
 			streamed = true
 
-			b, err := yaml.Encode(v)
+			var b []byte
+			var err error
+			if len(docs) > 0 {
+				// Format each document like the corresponding one in
+				// the original file.
+				b, err = internalyaml.EncodeLike(v.Syntax(cue.Final()), docs[0])
+				docs = docs[1:]
+			} else {
+				b, err = yaml.Encode(v)
+			}
 			if err != nil {
 				return err
 			}
@@ -243,7 +313,15 @@ func NewEncoder(ctx *cue.Context, f *build.File, cfg *Config) (*Encoder, error)
 		}
 
 	default:
-		return nil, fmt.Errorf("unsupported encoding %q", f.Encoding)
+		custom, ok := cueencoding.Lookup(string(f.Encoding))
+		switch {
+		case !ok:
+			return nil, fmt.Errorf("unsupported encoding %q", f.Encoding)
+		case custom.NewEncoder == nil:
+			return nil, fmt.Errorf("encoding %q does not support encoding", f.Encoding)
+		}
+		e.concrete = true
+		e.encValue = custom.NewEncoder(w).Encode
 	}
 
 	return e, nil
@@ -290,6 +368,34 @@ func (e *Encoder) encodeFile(f *ast.File, interpret func(cue.Value) (*ast.File,
 	return e.encValue(v)
 }
 
+// originalFile returns the current contents of the file f is written
+// to, if cfg.Fidelity is set and the file exists.
+func originalFile(f *build.File, cfg *Config) ([]byte, error) {
+	if !cfg.Fidelity || cfg.Out != nil || f.Filename == "-" {
+		return nil, nil
+	}
+	b, err := os.ReadFile(f.Filename)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	return b, err
+}
+
+// Write writes data to the file f in the same way as an [Encoder] for f
+// would, ignoring cfg.Out.
+func Write(f *build.File, cfg *Config, data []byte) error {
+	c := *cfg
+	c.Out = nil
+	w, close := writer(f, &c)
+	if _, err := w.Write(data); err != nil {
+		return err
+	}
+	if close != nil {
+		return close()
+	}
+	return nil
+}
+
 func writer(f *build.File, cfg *Config) (_ io.Writer, close func() error) {
 	if cfg.Out != nil {
 		return cfg.Out, nil
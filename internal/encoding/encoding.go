@@ -30,6 +30,7 @@ import (
 	"cuelang.org/go/cue/literal"
 	"cuelang.org/go/cue/parser"
 	"cuelang.org/go/cue/token"
+	cueencoding "cuelang.org/go/encoding"
 	"cuelang.org/go/encoding/json"
 	"cuelang.org/go/encoding/jsonschema"
 	"cuelang.org/go/encoding/openapi"
@@ -139,12 +140,19 @@ type Config struct {
 	PkgName string // package name for files to generate
 
 	Force     bool // overwrite existing files
+	Fidelity  bool // format like the existing YAML or JSON file
+	Canonical bool // write the canonical form of CUE or JSON
 	Strict    bool // strict mode for jsonschema (deprecated)
 	Stream    bool // potentially write more than one document per file
 	AllErrors bool
 
 	Schema cue.Value // used for schema-based decoding
 
+	// SchemaScalars makes decoders of YAML and JSON data use Schema to
+	// resolve the types of scalars which the source leaves ambiguous,
+	// such as 1.0 for an int field, or on for a bool field in YAML.
+	SchemaScalars bool
+
 	EscapeHTML    bool
 	InlineImports bool // expand references to non-core imports
 	ProtoPath     []string
@@ -223,13 +231,20 @@ func NewDecoder(ctx *cue.Context, f *build.File, cfg *Config) *Decoder {
 	// TODO: perhaps each encoding could have a "binary" boolean attribute
 	// so that we can use that here rather than hard-coding which encodings are binary.
 	// In the near future, others like [build.BinaryProto] should also be treated as binary.
-	if f.Encoding != build.Binary {
+	custom, registered := cueencoding.Lookup(string(f.Encoding))
+	if f.Encoding != build.Binary && !(registered && custom.Binary) {
 		// TODO: this code also allows UTF16, which is too permissive for some
 		// encodings. Switch to unicode.UTF8Sig once available.
 		t := unicode.BOMOverride(unicode.UTF8.NewDecoder())
 		r = transform.NewReader(r, t)
 	}
 
+	// schema holds the schema for resolving the types of data scalars, if any.
+	var schema cue.Value
+	if cfg.SchemaScalars && (f.Interpretation == "" || f.Interpretation == build.Auto) {
+		schema = cfg.Schema
+	}
+
 	path := f.Filename
 	switch f.Encoding {
 	case build.CUE:
@@ -250,15 +265,25 @@ func NewDecoder(ctx *cue.Context, f *build.File, cfg *Config) *Decoder {
 		}
 		i.expr, i.err = json.Extract(path, b)
 		if i.err == nil {
+			resolveNumbers(i.expr, schema)
 			i.doInterpret()
 		}
 	case build.JSONL:
-		i.next = json.NewDecoder(nil, path, r).Extract
+		next := json.NewDecoder(nil, path, r).Extract
+		i.next = func() (ast.Expr, error) {
+			x, err := next()
+			if err == nil {
+				resolveNumbers(x, schema)
+			}
+			return x, err
+		}
 		i.Next()
 	case build.YAML:
 		b, err := io.ReadAll(r)
 		i.err = err
-		i.next = yaml.NewDecoder(path, b).Decode
+		d := yaml.NewDecoder(path, b)
+		d.UseSchema(schema)
+		i.next = d.Decode
 		i.Next()
 	case build.TOML:
 		i.next = toml.NewDecoder(path, r).Decode
@@ -294,7 +319,15 @@ func NewDecoder(ctx *cue.Context, f *build.File, cfg *Config) *Decoder {
 			i.expr, i.err = d.Parse(cfg.Schema, path, b)
 		}
 	default:
-		i.err = fmt.Errorf("unsupported encoding %q", f.Encoding)
+		switch {
+		case !registered:
+			i.err = fmt.Errorf("unsupported encoding %q", f.Encoding)
+		case custom.NewDecoder == nil:
+			i.err = fmt.Errorf("encoding %q does not support decoding", f.Encoding)
+		default:
+			i.next = custom.NewDecoder(path, r).Decode
+			i.Next()
+		}
 	}
 
 	return i
@@ -41,6 +41,42 @@ import (
 // Generate produces Go type definitions from exported CUE definitions.
 // See the help text for `cue help exp gengotypes`.
 func Generate(ctx *cue.Context, insts ...*build.Instance) error {
+	return GenerateWithConfig(ctx, &Config{}, insts...)
+}
+
+// A Config configures how [GenerateWithConfig] generates Go types.
+type Config struct {
+	// Generator is the command named in the header of the generated files.
+	// It defaults to "cue exp gengotypes".
+	Generator string
+
+	// Filename returns the path of the file to generate for inst. If it is
+	// nil or returns "", the file is cue_types*_gen.go in the directory of
+	// inst.
+	Filename func(inst *build.Instance) string
+
+	// Definition reports whether to generate the top-level definition sel
+	// of inst. If it is nil, all definitions are generated. Definitions
+	// referenced by those which are generated are generated regardless.
+	Definition func(inst *build.Instance, sel cue.Selector) bool
+
+	// WriteFile writes a generated file. It defaults to [os.WriteFile].
+	WriteFile func(path string, data []byte) error
+}
+
+// GenerateWithConfig is like [Generate], configured by cfg.
+func GenerateWithConfig(ctx *cue.Context, cfg *Config, insts ...*build.Instance) error {
+	genCmd := cfg.Generator
+	if genCmd == "" {
+		genCmd = "cue exp gengotypes"
+	}
+	writeFile := cfg.WriteFile
+	if writeFile == nil {
+		writeFile = func(path string, data []byte) error {
+			return os.WriteFile(path, data, 0o666)
+		}
+	}
+
 	// record which package instances have already been generated
 	instDone := make(map[*build.Instance]bool)
 
@@ -81,6 +117,9 @@ func Generate(ctx *cue.Context, insts ...*build.Instance) error {
 			if !sel.IsDefinition() {
 				continue
 			}
+			if cfg.Definition != nil && !cfg.Definition(inst, sel) {
+				continue
+			}
 			path := cue.MakePath(sel)
 			if _, err := g.genDef(path, iter.Value()); err != nil {
 				return err
@@ -99,7 +138,7 @@ func Generate(ctx *cue.Context, insts ...*build.Instance) error {
 		printf := func(format string, args ...any) {
 			buf = fmt.Appendf(buf, format, args...)
 		}
-		printf("// Code generated by \"cue exp gengotypes\"; DO NOT EDIT.\n\n")
+		printf("// Code generated by %q; DO NOT EDIT.\n\n", genCmd)
 		goPkgName := goPkgNameForInstance(inst, instVal)
 		if prev, ok := goPkgNamesDoneByDir[inst.Dir]; ok && prev != goPkgName {
 			return fmt.Errorf("cannot generate two Go packages in one directory; %s and %s", prev, goPkgName)
@@ -164,6 +203,11 @@ func Generate(ctx *cue.Context, insts ...*build.Instance) error {
 			basename = fmt.Sprintf("cue_types_%s_gen.go", inst.PkgName)
 		}
 		outpath := filepath.Join(inst.Dir, basename)
+		if cfg.Filename != nil {
+			if name := cfg.Filename(inst); name != "" {
+				outpath = name
+			}
+		}
 
 		formatted, err := goformat.Source(buf)
 		if err != nil {
@@ -177,7 +221,7 @@ func Generate(ctx *cue.Context, insts ...*build.Instance) error {
 			fmt.Fprintf(os.Stderr, "-- %s --\n%s\n--\n", filepath.ToSlash(outpath), withLineNums)
 			return err
 		}
-		if err := os.WriteFile(outpath, formatted, 0o666); err != nil {
+		if err := writeFile(outpath, formatted); err != nil {
 			return err
 		}
 	}
@@ -464,8 +508,20 @@ func (g *generator) emitType(val cue.Value, optionalStg optionalStrategy) (typeF
 			if optional {
 				omitEmpty = ",omitempty"
 			}
-			g.def.printf(" `json:\"%s%s\"`", cueName, omitEmpty)
-			g.def.printf("\n\n")
+			jsonTag := cueName + omitEmpty
+			if attr := val.Attribute("json"); attr.Err() == nil {
+				jsonTag = tagValue(&attr)
+			}
+			g.def.printf(" `json:%s", strconv.Quote(jsonTag))
+			for _, attr := range val.Attributes(cue.FieldAttr) {
+				if cueAttrs[attr.Name()] {
+					continue
+				}
+				if value := tagValue(&attr); !strings.Contains(value, "`") {
+					g.def.printf(" %s:%s", attr.Name(), strconv.Quote(value))
+				}
+			}
+			g.def.printf("`\n\n")
 		}
 		g.def.printf("}")
 	case cue.ListKind:
@@ -663,6 +719,32 @@ func goNameFromPath(path cue.Path, defsOnly bool) string {
 	return name
 }
 
+// cueAttrs holds the names of field attributes which are interpreted by
+// CUE or its tools, and which are hence not carried over as struct tags.
+// The json tag is generated separately.
+var cueAttrs = map[string]bool{
+	"deprecated": true,
+	"embed":      true,
+	"extern":     true,
+	"go":         true,
+	"json":       true,
+	"openapi":    true,
+	"overlay":    true,
+	"protobuf":   true,
+	"tag":        true,
+}
+
+// tagValue returns the value of the struct tag for a field attribute,
+// such as those recorded by cue get go: its contents, or the contents of
+// its only argument if that is quoted.
+func tagValue(attr *cue.Attribute) string {
+	if attr.NumArgs() == 1 {
+		s, _ := attr.String(0)
+		return s
+	}
+	return attr.Contents()
+}
+
 // goValueAttr is like [cue.Value.Attribute] with the string parameter "go",
 // but it supports [cue.DeclAttr] attributes as well and not just [cue.FieldAttr].
 //
@@ -0,0 +1,455 @@
+// Copyright 2025 CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package protogen generates Protocol Buffers definitions from CUE
+// definitions.
+//
+// Each definition of a struct becomes a message, and each definition of
+// a disjunction of strings becomes an enum. Definitions of other values,
+// such as #Port: int & >0, are not generated themselves, but determine
+// the type of the fields which refer to them. Fields of structs which
+// are not definitions become nested messages, lists become repeated
+// fields, and structs with a single pattern constraint, such as
+// [string]: int, become maps.
+//
+// Fields are numbered by their @protobuf attribute, as recorded when
+// importing .proto files, or otherwise in the order in which they are
+// declared.
+package protogen
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"unicode"
+
+	"cuelang.org/go/cue"
+)
+
+// A Config configures the generated file.
+type Config struct {
+	// Package is the package of the generated file, if any.
+	Package string
+
+	// GoPackage sets the go_package option of the generated file, if
+	// not empty.
+	GoPackage string
+
+	// Generator is the command named in the header of the generated file.
+	// If it is empty, the file has no header.
+	Generator string
+
+	// Definition reports whether to generate the top-level definition sel.
+	// If it is nil, all definitions are generated. Definitions referenced
+	// by those which are generated are generated regardless.
+	Definition func(sel cue.Selector) bool
+}
+
+// Generate returns a proto3 file with the messages and enums for the
+// definitions of v.
+func Generate(v cue.Value, cfg *Config) ([]byte, error) {
+	g := &generator{root: v, done: map[string]bool{}}
+	iter, err := v.Fields(cue.Definitions(true))
+	if err != nil {
+		return nil, err
+	}
+	for iter.Next() {
+		sel := iter.Selector()
+		if sel.IsDefinition() && (cfg.Definition == nil || cfg.Definition(sel)) {
+			g.queue(sel)
+		}
+	}
+
+	var decls []string
+	for len(g.todo) > 0 {
+		sel := g.todo[0]
+		g.todo = g.todo[1:]
+		decl, err := g.decl(defName(sel), v.LookupPath(cue.MakePath(sel)), "")
+		if err != nil {
+			return nil, err
+		}
+		if decl != "" {
+			decls = append(decls, decl)
+		}
+	}
+
+	var b strings.Builder
+	if cfg.Generator != "" {
+		fmt.Fprintf(&b, "// Code generated by %q; DO NOT EDIT.\n\n", cfg.Generator)
+	}
+	b.WriteString("syntax = \"proto3\";\n")
+	if cfg.Package != "" {
+		fmt.Fprintf(&b, "\npackage %s;\n", cfg.Package)
+	}
+	if cfg.GoPackage != "" {
+		fmt.Fprintf(&b, "\noption go_package = %q;\n", cfg.GoPackage)
+	}
+	for _, decl := range decls {
+		b.WriteString("\n")
+		b.WriteString(decl)
+	}
+	return []byte(b.String()), nil
+}
+
+type generator struct {
+	root cue.Value
+
+	// todo holds the top-level definitions yet to be generated, and done
+	// those which were queued already.
+	todo []cue.Selector
+	done map[string]bool
+}
+
+func (g *generator) queue(sel cue.Selector) {
+	if !g.done[sel.String()] {
+		g.done[sel.String()] = true
+		g.todo = append(g.todo, sel)
+	}
+}
+
+// decl returns the message or enum named name for the definition v, or
+// "" if v is neither a struct nor an enum.
+func (g *generator) decl(name string, v cue.Value, indent string) (string, error) {
+	if values, ok := enumValues(v); ok {
+		return enumDecl(name, values, v, indent), nil
+	}
+	if v.IncompleteKind() != cue.StructKind {
+		return "", nil
+	}
+	return g.message(name, v, indent)
+}
+
+func (g *generator) message(name string, v cue.Value, indent string) (string, error) {
+	var nested, fields []string
+	used := map[int]bool{}
+	type field struct {
+		sel cue.Selector
+		v   cue.Value
+		num int
+	}
+	var all []field
+	iter, err := v.Fields(cue.Definitions(true), cue.Optional(true))
+	if err != nil {
+		return "", err
+	}
+	for iter.Next() {
+		sel, fv := iter.Selector(), iter.Value()
+		if sel.IsDefinition() {
+			decl, err := g.decl(defName(sel), fv, indent+"\t")
+			if err != nil {
+				return "", err
+			}
+			if decl != "" {
+				nested = append(nested, decl)
+			}
+			continue
+		}
+		num := 0
+		if a := fv.Attribute("protobuf"); a.Err() == nil {
+			n, err := a.Int(0)
+			if err != nil {
+				return "", fmt.Errorf("%v: invalid @protobuf attribute: %v", fv.Path(), err)
+			}
+			num = int(n)
+			used[num] = true
+		}
+		all = append(all, field{sel, fv, num})
+	}
+
+	next := 1
+	for _, f := range all {
+		if f.num == 0 {
+			for used[next] {
+				next++
+			}
+			f.num = next
+			used[next] = true
+		}
+		typ, decl, err := g.fieldType(f.v, typeName(f.sel.Unquoted()), indent+"\t")
+		if err != nil {
+			return "", err
+		}
+		if decl != "" {
+			nested = append(nested, decl)
+		}
+		label := ""
+		if f.sel.ConstraintType() == cue.OptionalConstraint && isScalar(typ, f.v) {
+			label = "optional "
+		}
+		fieldName, opts := fieldName(f.sel.Unquoted())
+		fields = append(fields, docComment(f.v, indent+"\t")+
+			fmt.Sprintf("%s\t%s%s %s = %d%s;\n", indent, label, typ, fieldName, f.num, opts))
+	}
+
+	var b strings.Builder
+	b.WriteString(docComment(v, indent))
+	fmt.Fprintf(&b, "%smessage %s {\n", indent, name)
+	for i, decl := range nested {
+		if i > 0 {
+			b.WriteString("\n")
+		}
+		b.WriteString(decl)
+	}
+	if len(nested) > 0 && len(fields) > 0 {
+		b.WriteString("\n")
+	}
+	for _, f := range fields {
+		b.WriteString(f)
+	}
+	fmt.Fprintf(&b, "%s}\n", indent)
+	return b.String(), nil
+}
+
+// fieldType returns the type of a field with value v, along with the
+// declaration of a nested message or enum named name if the type requires
+// one.
+func (g *generator) fieldType(v cue.Value, name, indent string) (typ, decl string, err error) {
+	if ref, ok := g.reference(v); ok {
+		return ref, "", nil
+	}
+	if values, ok := enumValues(v); ok {
+		return name, enumDecl(name, values, cue.Value{}, indent), nil
+	}
+	switch v.IncompleteKind() &^ cue.NullKind {
+	case cue.StringKind:
+		return "string", "", nil
+	case cue.BytesKind:
+		return "bytes", "", nil
+	case cue.BoolKind:
+		return "bool", "", nil
+	case cue.IntKind:
+		return intType(v), "", nil
+	case cue.FloatKind, cue.NumberKind:
+		return "double", "", nil
+	case cue.ListKind:
+		elem := v.LookupPath(cue.MakePath(cue.AnyIndex))
+		if !elem.Exists() {
+			return "", "", fmt.Errorf("%v: cannot determine the type of the elements of a list", v.Path())
+		}
+		typ, decl, err := g.fieldType(elem, name, indent)
+		if err != nil {
+			return "", "", err
+		}
+		if strings.HasPrefix(typ, "repeated ") || strings.HasPrefix(typ, "map<") {
+			return "", "", fmt.Errorf("%v: lists of lists or maps are not supported", v.Path())
+		}
+		return "repeated " + typ, decl, nil
+	case cue.StructKind:
+		if elem, ok := mapValue(v); ok {
+			typ, decl, err := g.fieldType(elem, name, indent)
+			if err != nil {
+				return "", "", err
+			}
+			if strings.HasPrefix(typ, "repeated ") || strings.HasPrefix(typ, "map<") {
+				return "", "", fmt.Errorf("%v: maps of lists or maps are not supported", v.Path())
+			}
+			return "map<string, " + typ + ">", decl, nil
+		}
+		decl, err := g.message(name, v, indent)
+		return name, decl, err
+	default:
+		return "", "", fmt.Errorf("%v: cannot represent %v in protobuf", v.Path(), v)
+	}
+}
+
+// reference returns the name of the message or enum v refers to, if v is
+// a reference to a definition of the root value which is generated as a
+// message or enum, queueing it to be generated.
+func (g *generator) reference(v cue.Value) (string, bool) {
+	root, path := v.ReferencePath()
+	sels := path.Selectors()
+	if len(sels) == 0 || root != g.root {
+		return "", false
+	}
+	var names []string
+	for _, sel := range sels {
+		if !sel.IsDefinition() {
+			return "", false
+		}
+		names = append(names, defName(sel))
+	}
+	target := root.LookupPath(path)
+	if _, ok := enumValues(target); !ok && target.IncompleteKind() != cue.StructKind {
+		return "", false
+	}
+	if _, ok := mapValue(target); ok {
+		return "", false
+	}
+	g.queue(sels[0])
+	return strings.Join(names, "."), true
+}
+
+// mapValue returns the value of the fields of a struct which has no
+// fields other than those of a single pattern constraint on strings.
+func mapValue(v cue.Value) (cue.Value, bool) {
+	elem := v.LookupPath(cue.MakePath(cue.AnyString))
+	if !elem.Exists() {
+		return cue.Value{}, false
+	}
+	iter, err := v.Fields(cue.Optional(true), cue.Definitions(true))
+	if err != nil || iter.Next() {
+		return cue.Value{}, false
+	}
+	return elem, true
+}
+
+// enumValues returns the strings of a disjunction of strings.
+func enumValues(v cue.Value) ([]string, bool) {
+	op, args := v.Expr()
+	if op != cue.OrOp {
+		return nil, false
+	}
+	var values []string
+	for _, a := range args {
+		s, err := a.String()
+		if err != nil {
+			return nil, false
+		}
+		if !slices.Contains(values, s) {
+			values = append(values, s)
+		}
+	}
+	return values, true
+}
+
+func enumDecl(name string, values []string, doc cue.Value, indent string) string {
+	var b strings.Builder
+	b.WriteString(docComment(doc, indent))
+	fmt.Fprintf(&b, "%senum %s {\n", indent, name)
+	prefix := upperSnake(name) + "_"
+	for i, s := range values {
+		fmt.Fprintf(&b, "%s\t%s%s = %d;\n", indent, prefix, upperSnake(s), i)
+	}
+	fmt.Fprintf(&b, "%s}\n", indent)
+	return b.String()
+}
+
+var intTypes = []string{"int32", "uint32", "int64", "uint64"}
+
+// intType returns the smallest protobuf integer type which holds all the
+// values allowed by v.
+func intType(v cue.Value) string {
+	ctx := v.Context()
+	// Optional fields do not subsume, so v is first made a regular value.
+	// Defaults are ignored, as a field may be set to any value of its type.
+	v = ctx.CompileString("_").Unify(v)
+	for _, typ := range intTypes {
+		if ctx.CompileString(typ).Subsume(v, cue.Raw()) == nil {
+			return typ
+		}
+	}
+	return "int64"
+}
+
+// isScalar reports whether a field of the given type may be marked as
+// optional to track whether it is set.
+func isScalar(typ string, v cue.Value) bool {
+	if strings.HasPrefix(typ, "repeated ") || strings.HasPrefix(typ, "map<") {
+		return false
+	}
+	return v.IncompleteKind()&^cue.NullKind != cue.StructKind
+}
+
+// docComment returns the doc comments of v as protobuf comments.
+func docComment(v cue.Value, indent string) string {
+	if !v.Exists() {
+		return ""
+	}
+	var b strings.Builder
+	for _, cg := range v.Doc() {
+		for _, line := range strings.Split(strings.TrimSuffix(cg.Text(), "\n"), "\n") {
+			fmt.Fprintf(&b, "%s//%s\n", indent, strings.TrimRight(" "+line, " "))
+		}
+	}
+	return b.String()
+}
+
+// defName returns the name of the definition sel without its leading #.
+func defName(sel cue.Selector) string {
+	return strings.TrimPrefix(strings.TrimPrefix(sel.String(), "_"), "#")
+}
+
+// fieldName returns a valid protobuf field name for the CUE field name,
+// and the options which keep its JSON name if it differs.
+func fieldName(name string) (string, string) {
+	var b strings.Builder
+	for i, r := range name {
+		switch {
+		case r < unicode.MaxASCII && (unicode.IsLetter(r) || r == '_'):
+		case r < unicode.MaxASCII && unicode.IsDigit(r) && i > 0:
+		case r < unicode.MaxASCII && unicode.IsDigit(r):
+			b.WriteString("f_")
+		default:
+			r = '_'
+		}
+		b.WriteRune(r)
+	}
+	if s := b.String(); s != name {
+		return s, fmt.Sprintf(" [json_name = %q]", name)
+	}
+	return name, ""
+}
+
+// words splits a name into its words, such as "http" and "Port" for
+// httpPort, http_port, or http-port.
+func words(name string) []string {
+	var words []string
+	start := -1
+	runes := []rune(name)
+	for i, r := range runes {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			if start >= 0 {
+				words = append(words, string(runes[start:i]))
+			}
+			start = -1
+			continue
+		}
+		if start >= 0 && unicode.IsUpper(r) &&
+			(unicode.IsLower(runes[i-1]) || i+1 < len(runes) && unicode.IsLower(runes[i+1])) {
+			words = append(words, string(runes[start:i]))
+			start = -1
+		}
+		if start < 0 {
+			start = i
+		}
+	}
+	if start >= 0 {
+		words = append(words, string(runes[start:]))
+	}
+	return words
+}
+
+// typeName returns the name of the message or enum generated for the
+// value of a field, such as HttpPort for http_port.
+func typeName(field string) string {
+	var b strings.Builder
+	for _, w := range words(field) {
+		r := []rune(w)
+		b.WriteString(strings.ToUpper(string(r[0])) + string(r[1:]))
+	}
+	if s := b.String(); s != "" && unicode.IsLetter([]rune(s)[0]) {
+		return s
+	}
+	return "T" + b.String()
+}
+
+// upperSnake returns the name of an enum value for s, such as IN_PROGRESS
+// for in-progress or inProgress.
+func upperSnake(s string) string {
+	name := strings.ToUpper(strings.Join(words(s), "_"))
+	if name == "" || !unicode.IsLetter([]rune(name)[0]) {
+		name = "V" + name
+	}
+	return name
+}
@@ -0,0 +1,119 @@
+// Copyright 2025 CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package protogen_test
+
+import (
+	"testing"
+
+	"github.com/go-quicktest/qt"
+
+	"cuelang.org/go/cue"
+	"cuelang.org/go/cue/cuecontext"
+	"cuelang.org/go/internal/encoding/protogen"
+)
+
+func TestGenerate(t *testing.T) {
+	v := cuecontext.New().CompileString(`
+// A Server serves requests.
+#Server: {
+	name:       string
+	port:       #Port
+	"tls-mode": #Mode
+	replicas?:  int & >=0 & <=10
+	weight:     float
+	labels: [string]: string
+	routes: [...#Route]
+	limits: {
+		cpu:    string
+		memory: string
+	}
+	state: "up" | "draining"
+	id: int @protobuf(10)
+}
+
+#Port: int & >0 & <65536
+
+// A Mode selects how TLS is used.
+#Mode: "off" | "strict" | "permissive"
+
+#Route: {
+	path: string
+	data: bytes
+}
+
+#Unused: {a: bool}
+`)
+	qt.Assert(t, qt.IsNil(v.Err()))
+
+	b, err := protogen.Generate(v, &protogen.Config{
+		Package:   "example.v1",
+		GoPackage: "example.com/api/v1",
+		Generator: "cue gen",
+		Definition: func(sel cue.Selector) bool {
+			return sel.String() == "#Server"
+		},
+	})
+	qt.Assert(t, qt.IsNil(err))
+	qt.Assert(t, qt.Equals(string(b), `// Code generated by "cue gen"; DO NOT EDIT.
+
+syntax = "proto3";
+
+package example.v1;
+
+option go_package = "example.com/api/v1";
+
+// A Server serves requests.
+message Server {
+	message Limits {
+		string cpu = 1;
+		string memory = 2;
+	}
+
+	enum State {
+		STATE_UP = 0;
+		STATE_DRAINING = 1;
+	}
+
+	string name = 1;
+	int32 port = 2;
+	Mode tls_mode = 3 [json_name = "tls-mode"];
+	optional int32 replicas = 4;
+	double weight = 5;
+	map<string, string> labels = 6;
+	repeated Route routes = 7;
+	Limits limits = 8;
+	State state = 9;
+	int64 id = 10;
+}
+
+// A Mode selects how TLS is used.
+enum Mode {
+	MODE_OFF = 0;
+	MODE_STRICT = 1;
+	MODE_PERMISSIVE = 2;
+}
+
+message Route {
+	string path = 1;
+	bytes data = 2;
+}
+`))
+}
+
+func TestGenerateError(t *testing.T) {
+	v := cuecontext.New().CompileString(`#A: {x: _}`)
+	_, err := protogen.Generate(v, &protogen.Config{})
+	qt.Assert(t, qt.ErrorMatches(err, `#A.x: cannot represent _ in protobuf`))
+}
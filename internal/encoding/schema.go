@@ -0,0 +1,76 @@
+// Copyright 2025 CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package encoding
+
+import (
+	"cuelang.org/go/cue"
+	"cuelang.org/go/cue/ast"
+	"cuelang.org/go/cue/token"
+	"cuelang.org/go/internal"
+)
+
+// resolveNumbers rewrites the number literals in the JSON value x whose
+// kind is not allowed by the schema v: floats with an integral value,
+// such as 1.0, become ints where only ints are allowed, and ints become
+// floats where only floats are allowed.
+func resolveNumbers(x ast.Expr, v cue.Value) {
+	if !v.Exists() {
+		return
+	}
+	switch x := x.(type) {
+	case *ast.StructLit:
+		for _, d := range x.Elts {
+			f, ok := d.(*ast.Field)
+			if !ok {
+				continue
+			}
+			name, _, err := ast.LabelName(f.Label)
+			if err != nil {
+				continue
+			}
+			fv := v.LookupPath(cue.MakePath(cue.Str(name)))
+			if !fv.Exists() {
+				fv = v.LookupPath(cue.MakePath(cue.Str(name).Optional()))
+			}
+			if !fv.Exists() {
+				fv = v.LookupPath(cue.MakePath(cue.AnyString))
+			}
+			resolveNumbers(f.Value, fv)
+		}
+
+	case *ast.ListLit:
+		for i, e := range x.Elts {
+			ev := v.LookupPath(cue.MakePath(cue.Index(i)))
+			if !ev.Exists() {
+				ev = v.LookupPath(cue.MakePath(cue.AnyIndex))
+			}
+			resolveNumbers(e, ev)
+		}
+
+	case *ast.UnaryExpr:
+		resolveNumbers(x.X, v)
+
+	case *ast.BasicLit:
+		allowed := v.IncompleteKind()
+		switch {
+		case x.Kind == token.FLOAT && allowed&cue.FloatKind == 0 && allowed&cue.IntKind != 0:
+			if i, ok := internal.ExactInt(x.Value); ok {
+				x.Kind, x.Value = token.INT, i
+			}
+		case x.Kind == token.INT && allowed&cue.IntKind == 0 && allowed&cue.FloatKind != 0:
+			x.Kind, x.Value = token.FLOAT, x.Value+".0"
+		}
+	}
+}
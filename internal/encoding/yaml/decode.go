@@ -14,7 +14,9 @@ import (
 
 	"gopkg.in/yaml.v3"
 
+	"cuelang.org/go/cue"
 	"cuelang.org/go/cue/ast"
+	cueerrors "cuelang.org/go/cue/errors"
 	"cuelang.org/go/cue/literal"
 	"cuelang.org/go/cue/token"
 	"cuelang.org/go/internal"
@@ -64,6 +66,9 @@ type decoder struct {
 
 	// forceNewline ensures that the next position will be on a new line.
 	forceNewline bool
+
+	// schema, if it exists, is used to resolve the types of plain scalars.
+	schema cue.Value
 }
 
 // TODO(mvdan): this can be io.Reader really, except that token.Pos is offset-based,
@@ -145,12 +150,13 @@ func (d *decoder) Decode() (ast.Expr, error) {
 		} else {
 			return nil, err
 		}
-		err = errors.New(e)
+		err = cueerrors.WithCode(cueerrors.Promote(errors.New(e), ""), cueerrors.InvalidYAML)
 		// Any further Decode calls repeat this error.
 		d.decodeErr = err
 		return nil, err
 	}
 	d.yamlNonEmpty = true
+	resolveScalars(&yn, d.schema)
 	return d.extract(&yn)
 }
 
@@ -52,11 +52,15 @@ func Encode(n ast.Node) (b []byte, err error) {
 	if err != nil {
 		return nil, err
 	}
+	// Use idiomatic indentation.
+	return marshal(y, 2)
+}
+
+func marshal(y *yaml.Node, indent int) ([]byte, error) {
 	w := &bytes.Buffer{}
 	enc := yaml.NewEncoder(w)
-	// Use idiomatic indentation.
-	enc.SetIndent(2)
-	if err = enc.Encode(y); err != nil {
+	enc.SetIndent(indent)
+	if err := enc.Encode(y); err != nil {
 		return nil, err
 	}
 	return w.Bytes(), nil
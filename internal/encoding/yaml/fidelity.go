@@ -0,0 +1,209 @@
+// Copyright 2025 CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package yaml
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"reflect"
+	"slices"
+
+	"gopkg.in/yaml.v3"
+
+	"cuelang.org/go/cue/ast"
+)
+
+// Documents parses the documents of the YAML stream b, for use with
+// [EncodeLike].
+func Documents(b []byte) ([]*yaml.Node, error) {
+	var docs []*yaml.Node
+	d := yaml.NewDecoder(bytes.NewReader(b))
+	for {
+		doc := &yaml.Node{}
+		err := d.Decode(doc)
+		if errors.Is(err, io.EOF) {
+			return docs, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		docs = append(docs, doc)
+	}
+}
+
+// EncodeLike is like [Encode], but formats the result like the YAML
+// document doc, as returned by [Documents], where their structures
+// match. Comments, the order of keys, the styles of scalars, mappings
+// and sequences, and the indentation of doc are retained. Keys not in doc
+// follow the key preceding them in n. Scalars which are unchanged keep
+// their original representation, such as 0x10 for 16.
+//
+// If doc is nil, EncodeLike is equivalent to Encode.
+func EncodeLike(n ast.Node, doc *yaml.Node) ([]byte, error) {
+	y, err := encode(n)
+	if err != nil {
+		return nil, err
+	}
+	indent := 2
+	if doc != nil && doc.Kind == yaml.DocumentNode && len(doc.Content) == 1 {
+		restyle(y, doc.Content[0])
+		if i := indentOf(doc.Content[0]); i > 0 {
+			indent = i
+		}
+		y = &yaml.Node{
+			Kind:        yaml.DocumentNode,
+			Content:     []*yaml.Node{y},
+			HeadComment: doc.HeadComment,
+			LineComment: doc.LineComment,
+			FootComment: doc.FootComment,
+		}
+	}
+	return marshal(y, indent)
+}
+
+// restyle applies the formatting of orig to n.
+func restyle(n, orig *yaml.Node) {
+	if orig.Kind == yaml.AliasNode && orig.Alias != nil {
+		orig = orig.Alias
+	}
+	copyComments(n, orig)
+	if n.Kind != orig.Kind {
+		return
+	}
+	switch n.Kind {
+	case yaml.MappingNode:
+		n.Style |= orig.Style & yaml.FlowStyle
+		restyleMapping(n, orig)
+
+	case yaml.SequenceNode:
+		n.Style |= orig.Style & yaml.FlowStyle
+		for i, e := range n.Content {
+			if i < len(orig.Content) {
+				restyle(e, orig.Content[i])
+			}
+		}
+
+	case yaml.ScalarNode:
+		restyleScalar(n, orig)
+	}
+}
+
+// restyleMapping orders the keys of n like those of orig, and applies
+// the formatting of the entries of orig to those of n.
+func restyleMapping(n, orig *yaml.Node) {
+	type entry struct {
+		key, value *yaml.Node
+		index      int // index in orig, or -1
+	}
+	origIndex := make(map[string]int)
+	for i := 0; i+1 < len(orig.Content); i += 2 {
+		origIndex[orig.Content[i].Value] = i
+	}
+	var known, added []int
+	entries := make([]entry, 0, len(n.Content)/2)
+	for i := 0; i+1 < len(n.Content); i += 2 {
+		e := entry{n.Content[i], n.Content[i+1], -1}
+		if j, ok := origIndex[e.key.Value]; ok {
+			e.index = j
+			copyComments(e.key, orig.Content[j])
+			restyleScalar(e.key, orig.Content[j])
+			restyle(e.value, orig.Content[j+1])
+			known = append(known, len(entries))
+		} else {
+			added = append(added, len(entries))
+		}
+		entries = append(entries, e)
+	}
+
+	// Order the known entries as in orig, then insert each added entry
+	// after the entry which precedes it in n.
+	slices.SortStableFunc(known, func(a, b int) int {
+		return entries[a].index - entries[b].index
+	})
+	order := known
+	for _, a := range added {
+		pos := 0
+		if a > 0 {
+			pos = slices.Index(order, a-1) + 1
+		}
+		order = slices.Insert(order, pos, a)
+	}
+
+	n.Content = n.Content[:0]
+	for _, i := range order {
+		n.Content = append(n.Content, entries[i].key, entries[i].value)
+	}
+}
+
+// restyleScalar applies the representation of orig to n if they hold
+// the same value, or else the style of orig if both are strings.
+func restyleScalar(n, orig *yaml.Node) {
+	if orig.Kind != yaml.ScalarNode || n.Kind != yaml.ScalarNode {
+		return
+	}
+	if n.ShortTag() != orig.ShortTag() {
+		return
+	}
+	var a, b any
+	if n.Decode(&a) == nil && orig.Decode(&b) == nil && reflect.DeepEqual(a, b) {
+		n.Value = orig.Value
+		n.Style = orig.Style
+		n.Tag = orig.Tag
+		return
+	}
+	if n.ShortTag() == "!!str" && orig.Style != 0 {
+		n.Style = orig.Style
+	}
+}
+
+// copyComments copies the comments of orig to n, where n has none.
+func copyComments(n, orig *yaml.Node) {
+	if n.HeadComment == "" {
+		n.HeadComment = orig.HeadComment
+	}
+	if n.LineComment == "" {
+		n.LineComment = orig.LineComment
+	}
+	if n.FootComment == "" {
+		n.FootComment = orig.FootComment
+	}
+}
+
+// indentOf returns the indentation of the first nested block mapping
+// in n, or 0 if there is none.
+func indentOf(n *yaml.Node) int {
+	switch n.Kind {
+	case yaml.MappingNode:
+		for i := 0; i+1 < len(n.Content); i += 2 {
+			k, v := n.Content[i], n.Content[i+1]
+			if v.Kind == yaml.MappingNode && v.Style&yaml.FlowStyle == 0 && len(v.Content) > 0 {
+				if d := v.Content[0].Column - k.Column; d > 0 {
+					return d
+				}
+			}
+			if d := indentOf(v); d > 0 {
+				return d
+			}
+		}
+	case yaml.SequenceNode:
+		for _, e := range n.Content {
+			if d := indentOf(e); d > 0 {
+				return d
+			}
+		}
+	}
+	return 0
+}
@@ -0,0 +1,131 @@
+// Copyright 2025 CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package yaml
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/go-quicktest/qt"
+
+	"cuelang.org/go/cue/parser"
+)
+
+func TestEncodeLike(t *testing.T) {
+	testCases := []struct {
+		name string
+		orig string
+		in   string
+		out  string
+	}{{
+		name: "Unchanged",
+		orig: `
+# Service configuration.
+name: 'web'   # the name
+port: 0x50
+tags: [a, b]
+
+env:
+    # Debugging.
+    DEBUG: "1"
+    script: |
+        echo hi
+`,
+		in: `
+		name: "web"
+		port: 80
+		tags: ["a", "b"]
+		env: {
+			DEBUG: "1"
+			script: """
+				echo hi
+
+				"""
+		}
+		`,
+		out: `
+# Service configuration.
+name: 'web' # the name
+port: 0x50
+tags: [a, b]
+env:
+    # Debugging.
+    DEBUG: "1"
+    script: |
+        echo hi
+`,
+	}, {
+		name: "Modified",
+		orig: `
+# Service configuration.
+name: 'web'
+port: 80 # the port
+replicas: 1
+`,
+		in: `
+		replicas: 3
+		name: "api"
+		image: "nginx"
+		port: 80
+		`,
+		out: `
+# Service configuration.
+name: 'api'
+image: nginx
+port: 80 # the port
+replicas: 3
+`,
+	}, {
+		name: "AddedFirst",
+		orig: `
+b: 1 # b
+`,
+		in: `
+		a: 0
+		b: 1
+		`,
+		out: `
+a: 0
+b: 1 # b
+`,
+	}, {
+		name: "ChangedKind",
+		orig: `
+a: [1, 2] # a
+b: 'x'
+`,
+		in: `
+		a: {x: 1}
+		b: 2
+		`,
+		out: `
+a: {x: 1} # a
+b: 2
+`,
+	}}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			f, err := parser.ParseFile(tc.name, tc.in)
+			qt.Assert(t, qt.IsNil(err))
+			docs, err := Documents([]byte(strings.TrimPrefix(tc.orig, "\n")))
+			qt.Assert(t, qt.IsNil(err))
+			qt.Assert(t, qt.HasLen(docs, 1))
+
+			b, err := EncodeLike(f, docs[0])
+			qt.Assert(t, qt.IsNil(err))
+			qt.Assert(t, qt.Equals(string(b), strings.TrimPrefix(tc.out, "\n")))
+		})
+	}
+}
@@ -0,0 +1,144 @@
+// Copyright 2025 CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package yaml
+
+import (
+	"regexp"
+
+	"gopkg.in/yaml.v3"
+
+	"cuelang.org/go/cue"
+	"cuelang.org/go/internal"
+)
+
+// UseSchema makes d resolve the types of plain YAML scalars, such as
+// 1.0, 0755, or on, using the schema v for the values decoded by d.
+//
+// A plain scalar whose type, as resolved by YAML, is not allowed by the
+// schema is instead decoded as a bool, int, float, or string, in that
+// order, if the schema allows it and the scalar can represent it. Quoted
+// or explicitly tagged scalars are not affected.
+func (d *decoder) UseSchema(v cue.Value) {
+	d.schema = v
+}
+
+// yaml11Bools holds the YAML 1.1 boolean values which YAML 1.2 resolves
+// as strings.
+var yaml11Bools = map[string]bool{
+	"y": true, "Y": true, "yes": true, "Yes": true, "YES": true,
+	"on": true, "On": true, "ON": true,
+	"n": false, "N": false, "no": false, "No": false, "NO": false,
+	"off": false, "Off": false, "OFF": false,
+}
+
+var rxDecimalInt = regexp.MustCompile(`^[-+]?[0-9]+$`)
+
+// resolveScalars retags the plain scalars in yn whose resolved type does
+// not match the schema v.
+func resolveScalars(yn *yaml.Node, v cue.Value) {
+	if !v.Exists() {
+		return
+	}
+	switch yn.Kind {
+	case yaml.DocumentNode:
+		for _, c := range yn.Content {
+			resolveScalars(c, v)
+		}
+
+	case yaml.SequenceNode:
+		for i, c := range yn.Content {
+			resolveScalars(c, schemaElem(v, i))
+		}
+
+	case yaml.MappingNode:
+		for i := 0; i+1 < len(yn.Content); i += 2 {
+			yk, yv := yn.Content[i], yn.Content[i+1]
+			// Merge keys and aliases may share nodes between different
+			// paths, so leave them alone.
+			if isMerge(yk) || yk.Kind != yaml.ScalarNode {
+				continue
+			}
+			resolveScalars(yv, schemaField(v, yk.Value))
+		}
+
+	case yaml.ScalarNode:
+		resolveScalar(yn, v.IncompleteKind())
+	}
+}
+
+func resolveScalar(yn *yaml.Node, allowed cue.Kind) {
+	const notPlain = yaml.TaggedStyle | yaml.DoubleQuotedStyle | yaml.SingleQuotedStyle |
+		yaml.LiteralStyle | yaml.FoldedStyle
+	if yn.Style&notPlain != 0 {
+		return
+	}
+	var kind cue.Kind
+	switch yn.ShortTag() {
+	case strTag, timestampTag:
+		kind = cue.StringKind
+	case boolTag:
+		kind = cue.BoolKind
+	case intTag:
+		kind = cue.IntKind
+	case floatTag:
+		kind = cue.FloatKind
+	default:
+		return
+	}
+	if allowed&kind != 0 || allowed == cue.BottomKind {
+		return
+	}
+
+	if b, ok := yaml11Bools[yn.Value]; ok && allowed&cue.BoolKind != 0 {
+		yn.Tag, yn.Value = boolTag, "false"
+		if b {
+			yn.Value = "true"
+		}
+		return
+	}
+	if kind == cue.FloatKind && allowed&cue.IntKind != 0 {
+		if i, ok := internal.ExactInt(yn.Value); ok {
+			yn.Tag, yn.Value = intTag, i
+			return
+		}
+	}
+	if kind == cue.IntKind && allowed&cue.FloatKind != 0 && rxDecimalInt.MatchString(yn.Value) {
+		yn.Tag, yn.Value = floatTag, yn.Value+".0"
+		return
+	}
+	if allowed&cue.StringKind != 0 {
+		yn.Tag = strTag
+	}
+}
+
+// schemaField returns the schema for the field name of a struct with
+// schema v, taking optional fields and pattern constraints into account.
+func schemaField(v cue.Value, name string) cue.Value {
+	if f := v.LookupPath(cue.MakePath(cue.Str(name))); f.Exists() {
+		return f
+	}
+	if f := v.LookupPath(cue.MakePath(cue.Str(name).Optional())); f.Exists() {
+		return f
+	}
+	return v.LookupPath(cue.MakePath(cue.AnyString))
+}
+
+// schemaElem returns the schema for element i of a list with schema v.
+func schemaElem(v cue.Value, i int) cue.Value {
+	if e := v.LookupPath(cue.MakePath(cue.Index(i))); e.Exists() {
+		return e
+	}
+	return v.LookupPath(cue.MakePath(cue.AnyIndex))
+}
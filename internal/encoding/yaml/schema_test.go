@@ -0,0 +1,90 @@
+// Copyright 2025 CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package yaml_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/go-quicktest/qt"
+
+	"cuelang.org/go/cue/cuecontext"
+	"cuelang.org/go/cue/format"
+	"cuelang.org/go/internal/encoding/yaml"
+)
+
+func TestUseSchema(t *testing.T) {
+	testCases := []struct {
+		name   string
+		schema string
+		yaml   string
+		want   string
+	}{{
+		name:   "String",
+		schema: `a: string, b: string, c: string, d: string`,
+		yaml:   "a: 1.0\nb: 0755\nc: true\nd: null",
+		want:   `{ a: "1.0" b: "0755" c: "true" d: null }`,
+	}, {
+		name:   "Bool",
+		schema: `a: bool, b: bool, c: bool`,
+		yaml:   "a: on\nb: No\nc: maybe",
+		want:   `{ a: true b: false c: "maybe" }`,
+	}, {
+		name:   "Numbers",
+		schema: `a: int, b: float, c: int, d: float`,
+		yaml:   "a: 2.0\nb: 2\nc: 2.5\nd: 0x10",
+		want:   `{ a: 2 b: 2.0 c: 2.5 d: 0x10 }`,
+	}, {
+		name:   "Precision",
+		schema: `a: int, b: int, c: int, d: int`,
+		yaml:   "a: 1.00000000000000000001\nb: 123456789012345678901234567.0\nc: 1e30\nd: -0.0",
+		want:   `{ a: 1.00000000000000000001 b: 123456789012345678901234567 c: 1000000000000000000000000000000 d: 0 }`,
+	}, {
+		name:   "Allowed",
+		schema: `a: int | string, b: number, c: _`,
+		yaml:   "a: 1\nb: 1\nc: on",
+		want:   `{ a: 1 b: 1 c: "on" }`,
+	}, {
+		name:   "Quoted",
+		schema: `a: int, b: bool, c: string`,
+		yaml:   "a: '2.0'\nb: \"on\"\nc: !!float 1.5",
+		want:   `{ a: "2.0" b: "on" c: 1.5 }`,
+	}, {
+		name:   "Nested",
+		schema: `a: [...string], b?: [string]: {x: string}, c: [bool, ...int]`,
+		yaml:   "a: [1, 2.5]\nb: {k: {x: 3}}\nc: [yes, 1.0]",
+		want:   `{ a: ["1", "2.5"] b: {k: {x: "3"}} c: [true, 1] }`,
+	}, {
+		name:   "Unknown",
+		schema: `a: string`,
+		yaml:   "b: 1.0",
+		want:   `{ b: 1.0 }`,
+	}}
+	ctx := cuecontext.New()
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			v := ctx.CompileString(tc.schema)
+			qt.Assert(t, qt.IsNil(v.Err()))
+			d := yaml.NewDecoder(tc.name, []byte(tc.yaml))
+			d.UseSchema(v)
+			x, err := d.Decode()
+			qt.Assert(t, qt.IsNil(err))
+			b, err := format.Node(x)
+			qt.Assert(t, qt.IsNil(err))
+			got := strings.Join(strings.Fields(string(b)), " ")
+			qt.Assert(t, qt.Equals(got, tc.want))
+		})
+	}
+}
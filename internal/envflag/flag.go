@@ -18,6 +18,63 @@ func Init[T any](flags *T, envVar string) error {
 	return nil
 }
 
+// A Flag describes a field in a struct type which can be set by [Parse].
+type Flag struct {
+	// Name holds the name of the flag, which is the name of its field
+	// in lower case.
+	Name string
+
+	// Kind holds the kind of the field: bool, int, or string.
+	Kind reflect.Kind
+
+	// Default holds the default value of the flag.
+	Default any
+
+	// Deprecated reports whether the flag is deprecated,
+	// so that it cannot be changed from its default value.
+	Deprecated bool
+
+	index int
+}
+
+// Describe returns the flags in the struct type T in the order of their
+// fields, using the same struct field tags as [Parse].
+func Describe[T any]() ([]Flag, error) {
+	ft := reflect.TypeFor[T]()
+	flags := make([]Flag, 0, ft.NumField())
+	for i := 0; i < ft.NumField(); i++ {
+		field := ft.Field(i)
+		f := Flag{
+			Name:    strings.ToLower(field.Name),
+			Kind:    field.Type.Kind(),
+			Default: reflect.Zero(field.Type).Interface(),
+			index:   i,
+		}
+		if tagStr, ok := field.Tag.Lookup("envflag"); ok {
+			for _, tag := range strings.Split(tagStr, ",") {
+				key, rest, hasRest := strings.Cut(tag, ":")
+				switch key {
+				case "default":
+					val, err := parseValue(f.Name, f.Kind, rest)
+					if err != nil {
+						return nil, err
+					}
+					f.Default = val
+				case "deprecated":
+					if hasRest {
+						return nil, fmt.Errorf("cannot have a value for deprecated tag")
+					}
+					f.Deprecated = true
+				default:
+					return nil, fmt.Errorf("unknown envflag tag %q", tag)
+				}
+			}
+		}
+		flags = append(flags, f)
+	}
+	return flags, nil
+}
+
 // Parse initializes the fields in flags from the attached struct field tags as
 // well as the contents of the given string.
 //
@@ -35,37 +92,34 @@ func Init[T any](flags *T, envVar string) error {
 // Names are treated case insensitively. Boolean values are parsed via [strconv.ParseBool],
 // integers via [strconv.Atoi], and strings are accepted as-is.
 func Parse[T any](flags *T, env string) error {
-	// Collect the field indices and set the default values.
-	indexByName := make(map[string]int)
-	deprecated := make(map[string]bool)
+	desc, err := Describe[T]()
+	if err != nil {
+		return err
+	}
 	fv := reflect.ValueOf(flags).Elem()
-	ft := fv.Type()
-	for i := 0; i < ft.NumField(); i++ {
-		field := ft.Field(i)
-		name := strings.ToLower(field.Name)
-		if tagStr, ok := field.Tag.Lookup("envflag"); ok {
-			for _, f := range strings.Split(tagStr, ",") {
-				key, rest, hasRest := strings.Cut(f, ":")
-				switch key {
-				case "default":
-					val, err := parseValue(name, field.Type.Kind(), rest)
-					if err != nil {
-						return err
-					}
-					fv.Field(i).Set(reflect.ValueOf(val))
-				case "deprecated":
-					if hasRest {
-						return fmt.Errorf("cannot have a value for deprecated tag")
-					}
-					deprecated[name] = true
-				default:
-					return fmt.Errorf("unknown envflag tag %q", f)
-				}
-			}
-		}
-		indexByName[name] = i
+	for _, f := range desc {
+		fv.Field(f.index).Set(reflect.ValueOf(f.Default))
 	}
+	return set(fv, desc, env)
+}
 
+// Set is like [Parse], but it applies the settings in the given string
+// on top of the current values in flags rather than their defaults.
+// This allows settings from several sources to be layered, such as
+// command line flags on top of an environment variable.
+func Set[T any](flags *T, settings string) error {
+	desc, err := Describe[T]()
+	if err != nil {
+		return err
+	}
+	return set(reflect.ValueOf(flags).Elem(), desc, settings)
+}
+
+func set(fv reflect.Value, desc []Flag, env string) error {
+	byName := make(map[string]Flag, len(desc))
+	for _, f := range desc {
+		byName[f.Name] = f
+	}
 	var errs []error
 	for _, elem := range strings.Split(env, ",") {
 		if elem == "" {
@@ -79,12 +133,12 @@ func Parse[T any](flags *T, env string) error {
 		}
 		name, valueStr, hasValue := strings.Cut(elem, "=")
 
-		index, knownFlag := indexByName[name]
+		f, knownFlag := byName[name]
 		if !knownFlag {
 			errs = append(errs, fmt.Errorf("unknown flag %q", elem))
 			continue
 		}
-		field := fv.Field(index)
+		field := fv.Field(f.index)
 		var val any
 		if hasValue {
 			var err error
@@ -104,10 +158,10 @@ func Parse[T any](flags *T, env string) error {
 			continue
 		}
 
-		if deprecated[name] {
+		if f.Deprecated {
 			// We allow setting deprecated flags to their default value so that
 			// bold explorers will not be penalised for their experimentation.
-			if field.Interface() != val {
+			if f.Default != val {
 				errs = append(errs, fmt.Errorf("cannot change default value of deprecated flag %q", name))
 			}
 			continue
@@ -1,9 +1,11 @@
 package envflag
 
 import (
+	"reflect"
 	"testing"
 
 	"github.com/go-quicktest/qt"
+	"github.com/google/go-cmp/cmp/cmpopts"
 )
 
 type testFlags struct {
@@ -200,3 +202,34 @@ func TestInit(t *testing.T) {
 		})
 	}
 }
+
+func TestDescribe(t *testing.T) {
+	flags, err := Describe[testTypes]()
+	qt.Assert(t, qt.IsNil(err))
+	qt.Assert(t, qt.CmpEquals(flags, []Flag{
+		{Name: "stringdefaultfoo", Kind: reflect.String, Default: "foo"},
+		{Name: "intdefault5", Kind: reflect.Int, Default: 5},
+	}, cmpopts.IgnoreUnexported(Flag{})))
+
+	flags, err = Describe[deprecatedFlags]()
+	qt.Assert(t, qt.IsNil(err))
+	qt.Assert(t, qt.CmpEquals(flags, []Flag{
+		{Name: "foo", Kind: reflect.Bool, Default: false, Deprecated: true},
+		{Name: "bar", Kind: reflect.Bool, Default: true, Deprecated: true},
+	}, cmpopts.IgnoreUnexported(Flag{})))
+}
+
+func TestSet(t *testing.T) {
+	var x testTypes
+	err := Parse(&x, "intdefault5=1")
+	qt.Assert(t, qt.IsNil(err))
+	err = Set(&x, "stringdefaultfoo=bar")
+	qt.Assert(t, qt.IsNil(err))
+	qt.Assert(t, qt.Equals(x, testTypes{
+		StringDefaultFoo: "bar",
+		IntDefault5:      1,
+	}))
+	err = Set(&x, "intdefault5=x")
+	qt.Assert(t, qt.ErrorIs(err, ErrInvalid))
+	qt.Assert(t, qt.Equals(x.IntDefault5, 1))
+}
@@ -0,0 +1,422 @@
+// Copyright 2025 CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package evalservice
+
+import (
+	"encoding/binary"
+	"errors"
+)
+
+// The types below correspond to the messages of evalservice.proto. They
+// are encoded by hand, following the protocol buffer wire format
+// (https://protobuf.dev/programming-guides/encoding/), which keeps the
+// protobuf runtime out of the dependencies of the cue command.
+
+type compileRequest struct {
+	filename string
+	encoding string
+	data     []byte
+}
+
+func (m *compileRequest) marshal() []byte {
+	var b []byte
+	b = appendBytesField(b, 1, []byte(m.filename))
+	b = appendBytesField(b, 2, []byte(m.encoding))
+	b = appendBytesField(b, 3, m.data)
+	return b
+}
+
+func (m *compileRequest) unmarshal(b []byte) error {
+	return parseMessage(b, func(num, typ int, v uint64, data []byte) error {
+		switch {
+		case num == 1 && typ == wireBytes:
+			m.filename = string(data)
+		case num == 2 && typ == wireBytes:
+			m.encoding = string(data)
+		case num == 3 && typ == wireBytes:
+			m.data = data
+		}
+		return nil
+	})
+}
+
+type valueResponse struct {
+	handle uint64
+	errors []errorMessage
+}
+
+func (m *valueResponse) marshal() []byte {
+	var b []byte
+	b = appendVarintField(b, 1, m.handle)
+	for _, e := range m.errors {
+		b = appendMessageField(b, 2, e.marshal())
+	}
+	return b
+}
+
+func (m *valueResponse) unmarshal(b []byte) error {
+	return parseMessage(b, func(num, typ int, v uint64, data []byte) error {
+		switch {
+		case num == 1 && typ == wireVarint:
+			m.handle = v
+		case num == 2 && typ == wireBytes:
+			var e errorMessage
+			if err := e.unmarshal(data); err != nil {
+				return err
+			}
+			m.errors = append(m.errors, e)
+		}
+		return nil
+	})
+}
+
+type unifyRequest struct {
+	handles []uint64
+}
+
+func (m *unifyRequest) marshal() []byte {
+	return appendPackedField(nil, 1, m.handles)
+}
+
+func (m *unifyRequest) unmarshal(b []byte) error {
+	return parseMessage(b, func(num, typ int, v uint64, data []byte) (err error) {
+		if num == 1 {
+			m.handles, err = appendRepeated(m.handles, typ, v, data)
+		}
+		return err
+	})
+}
+
+type lookupRequest struct {
+	handle uint64
+	path   string
+}
+
+func (m *lookupRequest) marshal() []byte {
+	var b []byte
+	b = appendVarintField(b, 1, m.handle)
+	b = appendBytesField(b, 2, []byte(m.path))
+	return b
+}
+
+func (m *lookupRequest) unmarshal(b []byte) error {
+	return parseMessage(b, func(num, typ int, v uint64, data []byte) error {
+		switch {
+		case num == 1 && typ == wireVarint:
+			m.handle = v
+		case num == 2 && typ == wireBytes:
+			m.path = string(data)
+		}
+		return nil
+	})
+}
+
+type validateRequest struct {
+	handle   uint64
+	concrete bool
+}
+
+func (m *validateRequest) marshal() []byte {
+	var b []byte
+	b = appendVarintField(b, 1, m.handle)
+	if m.concrete {
+		b = appendVarintField(b, 2, 1)
+	}
+	return b
+}
+
+func (m *validateRequest) unmarshal(b []byte) error {
+	return parseMessage(b, func(num, typ int, v uint64, data []byte) error {
+		switch {
+		case num == 1 && typ == wireVarint:
+			m.handle = v
+		case num == 2 && typ == wireVarint:
+			m.concrete = v != 0
+		}
+		return nil
+	})
+}
+
+type validateResponse struct {
+	errors []errorMessage
+}
+
+func (m *validateResponse) marshal() []byte {
+	var b []byte
+	for _, e := range m.errors {
+		b = appendMessageField(b, 1, e.marshal())
+	}
+	return b
+}
+
+func (m *validateResponse) unmarshal(b []byte) error {
+	return parseMessage(b, func(num, typ int, v uint64, data []byte) error {
+		if num == 1 && typ == wireBytes {
+			var e errorMessage
+			if err := e.unmarshal(data); err != nil {
+				return err
+			}
+			m.errors = append(m.errors, e)
+		}
+		return nil
+	})
+}
+
+type exportRequest struct {
+	handle   uint64
+	encoding string
+}
+
+func (m *exportRequest) marshal() []byte {
+	var b []byte
+	b = appendVarintField(b, 1, m.handle)
+	b = appendBytesField(b, 2, []byte(m.encoding))
+	return b
+}
+
+func (m *exportRequest) unmarshal(b []byte) error {
+	return parseMessage(b, func(num, typ int, v uint64, data []byte) error {
+		switch {
+		case num == 1 && typ == wireVarint:
+			m.handle = v
+		case num == 2 && typ == wireBytes:
+			m.encoding = string(data)
+		}
+		return nil
+	})
+}
+
+type exportResponse struct {
+	data []byte
+}
+
+func (m *exportResponse) marshal() []byte {
+	return appendBytesField(nil, 1, m.data)
+}
+
+func (m *exportResponse) unmarshal(b []byte) error {
+	return parseMessage(b, func(num, typ int, v uint64, data []byte) error {
+		if num == 1 && typ == wireBytes {
+			m.data = data
+		}
+		return nil
+	})
+}
+
+type releaseRequest struct {
+	handles []uint64
+}
+
+func (m *releaseRequest) marshal() []byte {
+	return appendPackedField(nil, 1, m.handles)
+}
+
+func (m *releaseRequest) unmarshal(b []byte) error {
+	return parseMessage(b, func(num, typ int, v uint64, data []byte) (err error) {
+		if num == 1 {
+			m.handles, err = appendRepeated(m.handles, typ, v, data)
+		}
+		return err
+	})
+}
+
+type releaseResponse struct{}
+
+func (m *releaseResponse) marshal() []byte { return nil }
+
+func (m *releaseResponse) unmarshal(b []byte) error {
+	return parseMessage(b, func(num, typ int, v uint64, data []byte) error { return nil })
+}
+
+type errorMessage struct {
+	message   string
+	path      string
+	positions []position
+}
+
+func (m *errorMessage) marshal() []byte {
+	var b []byte
+	b = appendBytesField(b, 1, []byte(m.message))
+	b = appendBytesField(b, 2, []byte(m.path))
+	for _, p := range m.positions {
+		b = appendMessageField(b, 3, p.marshal())
+	}
+	return b
+}
+
+func (m *errorMessage) unmarshal(b []byte) error {
+	return parseMessage(b, func(num, typ int, v uint64, data []byte) error {
+		switch {
+		case num == 1 && typ == wireBytes:
+			m.message = string(data)
+		case num == 2 && typ == wireBytes:
+			m.path = string(data)
+		case num == 3 && typ == wireBytes:
+			var p position
+			if err := p.unmarshal(data); err != nil {
+				return err
+			}
+			m.positions = append(m.positions, p)
+		}
+		return nil
+	})
+}
+
+type position struct {
+	filename string
+	line     int32
+	column   int32
+}
+
+func (m *position) marshal() []byte {
+	var b []byte
+	b = appendBytesField(b, 1, []byte(m.filename))
+	// Negative int32 values are sign-extended to 64 bits.
+	b = appendVarintField(b, 2, uint64(int64(m.line)))
+	b = appendVarintField(b, 3, uint64(int64(m.column)))
+	return b
+}
+
+func (m *position) unmarshal(b []byte) error {
+	return parseMessage(b, func(num, typ int, v uint64, data []byte) error {
+		switch {
+		case num == 1 && typ == wireBytes:
+			m.filename = string(data)
+		case num == 2 && typ == wireVarint:
+			m.line = int32(v)
+		case num == 3 && typ == wireVarint:
+			m.column = int32(v)
+		}
+		return nil
+	})
+}
+
+// Wire types of the protocol buffer encoding.
+const (
+	wireVarint  = 0
+	wireFixed64 = 1
+	wireBytes   = 2
+	wireFixed32 = 5
+)
+
+var errInvalidMessage = errors.New("invalid protocol buffer message")
+
+func appendTag(b []byte, num, typ int) []byte {
+	return binary.AppendUvarint(b, uint64(num)<<3|uint64(typ))
+}
+
+// appendVarintField appends a varint field, unless v is the zero value,
+// which proto3 does not encode.
+func appendVarintField(b []byte, num int, v uint64) []byte {
+	if v == 0 {
+		return b
+	}
+	b = appendTag(b, num, wireVarint)
+	return binary.AppendUvarint(b, v)
+}
+
+// appendBytesField appends a string or bytes field, unless v is empty.
+func appendBytesField(b []byte, num int, v []byte) []byte {
+	if len(v) == 0 {
+		return b
+	}
+	return appendMessageField(b, num, v)
+}
+
+// appendMessageField appends an embedded message, which is encoded even
+// if it is empty.
+func appendMessageField(b []byte, num int, msg []byte) []byte {
+	b = appendTag(b, num, wireBytes)
+	b = binary.AppendUvarint(b, uint64(len(msg)))
+	return append(b, msg...)
+}
+
+// appendPackedField appends a repeated varint field in packed form.
+func appendPackedField(b []byte, num int, v []uint64) []byte {
+	if len(v) == 0 {
+		return b
+	}
+	var packed []byte
+	for _, x := range v {
+		packed = binary.AppendUvarint(packed, x)
+	}
+	return appendMessageField(b, num, packed)
+}
+
+// appendRepeated appends the elements of a repeated varint field to
+// list. Parsers must accept both the packed and the unpacked form.
+func appendRepeated(list []uint64, typ int, v uint64, data []byte) ([]uint64, error) {
+	switch typ {
+	case wireVarint:
+		return append(list, v), nil
+	case wireBytes:
+		for len(data) > 0 {
+			x, n := binary.Uvarint(data)
+			if n <= 0 {
+				return nil, errInvalidMessage
+			}
+			list = append(list, x)
+			data = data[n:]
+		}
+	}
+	return list, nil
+}
+
+// parseMessage calls f for each field of the message b, passing the value
+// of varint and fixed-size fields in v and the contents of
+// length-delimited fields in data. As in other protocol buffer
+// implementations, callers ignore fields they do not know.
+func parseMessage(b []byte, f func(num, typ int, v uint64, data []byte) error) error {
+	for len(b) > 0 {
+		tag, n := binary.Uvarint(b)
+		if n <= 0 || tag>>3 == 0 || tag>>3 > 1<<29-1 {
+			return errInvalidMessage
+		}
+		b = b[n:]
+		num, typ := int(tag>>3), int(tag&7)
+		var v uint64
+		var data []byte
+		switch typ {
+		case wireVarint:
+			if v, n = binary.Uvarint(b); n <= 0 {
+				return errInvalidMessage
+			}
+			b = b[n:]
+		case wireFixed64:
+			if len(b) < 8 {
+				return errInvalidMessage
+			}
+			v, b = binary.LittleEndian.Uint64(b), b[8:]
+		case wireFixed32:
+			if len(b) < 4 {
+				return errInvalidMessage
+			}
+			v, b = uint64(binary.LittleEndian.Uint32(b)), b[4:]
+		case wireBytes:
+			size, n := binary.Uvarint(b)
+			if n <= 0 || size > uint64(len(b)-n) {
+				return errInvalidMessage
+			}
+			data, b = b[n:n+int(size)], b[n+int(size):]
+		default:
+			// Groups are deprecated and not used by this service.
+			return errInvalidMessage
+		}
+		if err := f(num, typ, v, data); err != nil {
+			return err
+		}
+	}
+	return nil
+}
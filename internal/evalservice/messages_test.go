@@ -0,0 +1,252 @@
+// Copyright 2025 CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package evalservice
+
+import (
+	"encoding/binary"
+	"fmt"
+	"os"
+	"reflect"
+	"testing"
+
+	"github.com/emicklei/proto"
+	"github.com/go-quicktest/qt"
+	"github.com/google/go-cmp/cmp"
+)
+
+// conformanceMessages holds, for each message of evalservice.proto, a
+// value with all fields set and the same value keyed by the names of the
+// fields in the .proto. Repeated fields hold a []any, and embedded
+// messages a map[string]any.
+var conformanceMessages = map[string]struct {
+	msg    message
+	fields map[string]any
+}{
+	"CompileRequest": {
+		&compileRequest{filename: "a.cue", encoding: "cue", data: []byte("a: 1")},
+		map[string]any{"filename": "a.cue", "encoding": "cue", "data": []byte("a: 1")},
+	},
+	"ValueResponse": {
+		&valueResponse{handle: 300, errors: []errorMessage{{message: "m1"}, {message: "m2"}}},
+		map[string]any{"handle": uint64(300), "errors": []any{
+			map[string]any{"message": "m1"},
+			map[string]any{"message": "m2"},
+		}},
+	},
+	"UnifyRequest": {
+		&unifyRequest{handles: []uint64{1, 300, 1 << 40}},
+		map[string]any{"handles": []any{uint64(1), uint64(300), uint64(1 << 40)}},
+	},
+	"LookupRequest": {
+		&lookupRequest{handle: 2, path: "a.#B"},
+		map[string]any{"handle": uint64(2), "path": "a.#B"},
+	},
+	"ValidateRequest": {
+		&validateRequest{handle: 3, concrete: true},
+		map[string]any{"handle": uint64(3), "concrete": true},
+	},
+	"ValidateResponse": {
+		&validateResponse{errors: []errorMessage{{path: "a"}}},
+		map[string]any{"errors": []any{map[string]any{"path": "a"}}},
+	},
+	"ExportRequest": {
+		&exportRequest{handle: 4, encoding: "yaml"},
+		map[string]any{"handle": uint64(4), "encoding": "yaml"},
+	},
+	"ExportResponse": {
+		&exportResponse{data: []byte("{}")},
+		map[string]any{"data": []byte("{}")},
+	},
+	"ReleaseRequest": {
+		&releaseRequest{handles: []uint64{5, 6}},
+		map[string]any{"handles": []any{uint64(5), uint64(6)}},
+	},
+	"ReleaseResponse": {
+		&releaseResponse{},
+		map[string]any{},
+	},
+	"Error": {
+		&errorMessage{message: "conflict", path: "a.0", positions: []position{{filename: "a.cue", line: 1, column: 2}, {line: 3}}},
+		map[string]any{"message": "conflict", "path": "a.0", "positions": []any{
+			map[string]any{"filename": "a.cue", "line": int32(1), "column": int32(2)},
+			map[string]any{"line": int32(3)},
+		}},
+	},
+	"Position": {
+		// Negative values take ten bytes in the encoding.
+		&position{filename: "b.cue", line: 10, column: -1},
+		map[string]any{"filename": "b.cue", "line": int32(10), "column": int32(-1)},
+	},
+}
+
+// TestMessagesConformance checks the hand-written encoding of the
+// messages against their definitions in evalservice.proto: every message
+// is decoded using the field numbers and types of the .proto, and values
+// encoded from the .proto are decoded by unmarshal.
+func TestMessagesConformance(t *testing.T) {
+	f, err := os.Open("evalservice.proto")
+	qt.Assert(t, qt.IsNil(err))
+	defer f.Close()
+	def, err := proto.NewParser(f).Parse()
+	qt.Assert(t, qt.IsNil(err))
+
+	descs := map[string][]*proto.NormalField{}
+	var rpcTypes []string
+	proto.Walk(def,
+		proto.WithMessage(func(m *proto.Message) {
+			fields := []*proto.NormalField{}
+			for _, e := range m.Elements {
+				if f, ok := e.(*proto.NormalField); ok {
+					fields = append(fields, f)
+				}
+			}
+			descs[m.Name] = fields
+		}),
+		proto.WithRPC(func(r *proto.RPC) {
+			rpcTypes = append(rpcTypes, r.RequestType, r.ReturnsType)
+		}),
+	)
+	for _, name := range rpcTypes {
+		qt.Check(t, qt.IsNotNil(descs[name]), qt.Commentf("rpc type %s", name))
+	}
+	for name := range descs {
+		_, ok := conformanceMessages[name]
+		qt.Check(t, qt.IsTrue(ok), qt.Commentf("message %s has no test case", name))
+	}
+
+	cmpAll := cmp.Exporter(func(reflect.Type) bool { return true })
+	for name, test := range conformanceMessages {
+		t.Run(name, func(t *testing.T) {
+			fields, ok := descs[name]
+			qt.Assert(t, qt.IsTrue(ok), qt.Commentf("message %s is not in the .proto", name))
+			qt.Assert(t, qt.Equals(reflect.TypeOf(test.msg).Elem().NumField(), len(fields)))
+			for _, f := range fields {
+				_, ok := test.fields[f.Name]
+				qt.Assert(t, qt.IsTrue(ok), qt.Commentf("field %s is not set", f.Name))
+			}
+
+			got, err := decodeProto(descs, name, test.msg.marshal())
+			qt.Assert(t, qt.IsNil(err))
+			qt.Assert(t, qt.CmpEquals(got, test.fields))
+
+			m := reflect.New(reflect.TypeOf(test.msg).Elem()).Interface().(message)
+			qt.Assert(t, qt.IsNil(m.unmarshal(encodeProto(descs, name, test.fields))))
+			qt.Assert(t, qt.CmpEquals(m, test.msg, cmpAll))
+		})
+	}
+}
+
+// decodeProto decodes b as the message name of descs, in the
+// representation of conformanceMessages.
+func decodeProto(descs map[string][]*proto.NormalField, name string, b []byte) (map[string]any, error) {
+	fields := map[int]*proto.NormalField{}
+	for _, f := range descs[name] {
+		fields[f.Sequence] = f
+	}
+	result := map[string]any{}
+	err := parseMessage(b, func(num, typ int, v uint64, data []byte) error {
+		f := fields[num]
+		if f == nil {
+			return fmt.Errorf("unknown field %d", num)
+		}
+		var vals []any
+		switch f.Type {
+		case "uint64", "int32", "bool":
+			raw := []uint64{v}
+			switch {
+			case typ == wireBytes && f.Repeated:
+				var err error
+				if raw, err = appendRepeated(nil, typ, v, data); err != nil {
+					return err
+				}
+			case typ != wireVarint:
+				return fmt.Errorf("field %s has wire type %d", f.Name, typ)
+			}
+			for _, x := range raw {
+				switch f.Type {
+				case "uint64":
+					vals = append(vals, x)
+				case "int32":
+					if int64(x) != int64(int32(x)) {
+						return fmt.Errorf("field %s has int32 value %d not sign-extended", f.Name, x)
+					}
+					vals = append(vals, int32(x))
+				case "bool":
+					vals = append(vals, x != 0)
+				}
+			}
+		default:
+			if typ != wireBytes {
+				return fmt.Errorf("field %s has wire type %d", f.Name, typ)
+			}
+			switch f.Type {
+			case "string":
+				vals = append(vals, string(data))
+			case "bytes":
+				vals = append(vals, data)
+			default:
+				m, err := decodeProto(descs, f.Type, data)
+				if err != nil {
+					return fmt.Errorf("%s: %v", f.Name, err)
+				}
+				vals = append(vals, m)
+			}
+		}
+		if !f.Repeated {
+			result[f.Name] = vals[0]
+			return nil
+		}
+		list, _ := result[f.Name].([]any)
+		result[f.Name] = append(list, vals...)
+		return nil
+	})
+	return result, err
+}
+
+// encodeProto encodes values as the message name of descs. Unlike
+// marshal, it encodes repeated scalars unpacked, as older encoders do.
+func encodeProto(descs map[string][]*proto.NormalField, name string, values map[string]any) []byte {
+	var b []byte
+	for _, f := range descs[name] {
+		v, ok := values[f.Name]
+		if !ok {
+			continue
+		}
+		vals := []any{v}
+		if f.Repeated {
+			vals = v.([]any)
+		}
+		for _, v := range vals {
+			switch v := v.(type) {
+			case uint64:
+				b = appendTag(b, f.Sequence, wireVarint)
+				b = binary.AppendUvarint(b, v)
+			case int32:
+				b = appendTag(b, f.Sequence, wireVarint)
+				b = binary.AppendUvarint(b, uint64(int64(v)))
+			case bool:
+				b = appendTag(b, f.Sequence, wireVarint)
+				b = binary.AppendUvarint(b, map[bool]uint64{false: 0, true: 1}[v])
+			case string:
+				b = appendMessageField(b, f.Sequence, []byte(v))
+			case []byte:
+				b = appendMessageField(b, f.Sequence, v)
+			case map[string]any:
+				b = appendMessageField(b, f.Sequence, encodeProto(descs, f.Type, v))
+			}
+		}
+	}
+	return b
+}
@@ -0,0 +1,551 @@
+// Copyright 2025 CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package evalservice implements the gRPC evaluation service defined in
+// evalservice.proto, which lets programs in any language compile,
+// unify, validate and export CUE values over a persistent connection.
+//
+// The service speaks the gRPC protocol over HTTP/2
+// (https://github.com/grpc/grpc/blob/master/doc/PROTOCOL-HTTP2.md)
+// directly on top of net/http, without compression.
+package evalservice
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+
+	"cuelang.org/go/cue"
+	"cuelang.org/go/cue/ast"
+	"cuelang.org/go/cue/build"
+	cueerrors "cuelang.org/go/cue/errors"
+	"cuelang.org/go/cue/token"
+	"cuelang.org/go/internal/core/adt"
+	"cuelang.org/go/internal/encoding"
+	"cuelang.org/go/internal/filetypes"
+)
+
+// ServiceName is the fully qualified name of the service.
+const ServiceName = "cue.eval.v1.Evaluator"
+
+const (
+	// maxMessageSize limits the size of a request message, as the
+	// default of gRPC implementations does.
+	maxMessageSize = 4 << 20
+
+	// maxCompileSize limits the total size of the sources of a Compile
+	// call, which may be sent in any number of messages.
+	maxCompileSize = 64 << 20
+
+	// exportChunkSize is the size of the chunks in which Export sends
+	// its output.
+	exportChunkSize = 64 << 10
+
+	// maxClientHandles limits the number of handles which a client may
+	// hold at the same time.
+	maxClientHandles = 10000
+)
+
+// gRPC status codes.
+const (
+	codeOK                 = 0
+	codeInvalidArgument    = 3
+	codeNotFound           = 5
+	codeResourceExhausted  = 8
+	codeFailedPrecondition = 9
+	codeUnimplemented      = 12
+	codeInternal           = 13
+)
+
+// A statusError is an error with a gRPC status code.
+type statusError struct {
+	code int
+	msg  string
+}
+
+func (e *statusError) Error() string { return e.msg }
+
+func errorf(code int, format string, args ...any) error {
+	return &statusError{code, fmt.Sprintf(format, args...)}
+}
+
+// A Server serves the evaluation service. Values are kept for as long as
+// clients hold handles to them. Each client, identified by the address of
+// its connection, may hold up to 10000 handles at the same time.
+//
+// A cue.Context keeps every instance built in it, so that other
+// instances can import them, and each Compile call builds a new
+// instance. The server therefore replaces its context with a new one
+// whenever all handles have been released, at which point no value
+// refers to the old one. As long as clients hold on to some handles,
+// such as those of schemas, the memory used by the server grows with
+// each Compile call.
+type Server struct {
+	newContext func() *cue.Context
+
+	// maxHandles is the number of handles a client may hold.
+	maxHandles int
+
+	// mu serializes evaluation, as values of ctx may not be evaluated
+	// concurrently, and guards the fields below.
+	mu      sync.Mutex
+	ctx     *cue.Context
+	values  map[uint64]handle
+	handles map[string]int // number of handles held by each client
+	last    uint64         // last handle
+}
+
+// A handle is a value held by a client.
+type handle struct {
+	v      cue.Value
+	client string
+}
+
+// NewServer returns a server which evaluates values in contexts returned
+// by newContext.
+func NewServer(newContext func() *cue.Context) *Server {
+	return &Server{
+		newContext: newContext,
+		maxHandles: maxClientHandles,
+		ctx:        newContext(),
+		values:     map[uint64]handle{},
+		handles:    map[string]int{},
+	}
+}
+
+// ServeHTTP implements [http.Handler]. The server must be served over
+// HTTP/2.
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if r.ProtoMajor != 2 {
+		http.Error(w, "gRPC requires HTTP/2", http.StatusHTTPVersionNotSupported)
+		return
+	}
+	ct := r.Header.Get("Content-Type")
+	if ct != "application/grpc" && !strings.HasPrefix(ct, "application/grpc+proto") && !strings.HasPrefix(ct, "application/grpc;") {
+		http.Error(w, "unsupported content type", http.StatusUnsupportedMediaType)
+		return
+	}
+	w.Header().Set("Content-Type", "application/grpc")
+
+	err := s.call(w, r)
+	code, msg := codeOK, ""
+	if err != nil {
+		code, msg = codeInternal, err.Error()
+		if se, ok := err.(*statusError); ok {
+			code = se.code
+		}
+	}
+	// The status is sent in the trailers of the response.
+	w.Header().Set(http.TrailerPrefix+"Grpc-Status", strconv.Itoa(code))
+	if msg != "" {
+		w.Header().Set(http.TrailerPrefix+"Grpc-Message", encodeStatusMessage(msg))
+	}
+}
+
+// call calls the method of r. If evaluation exceeds a limit set on the
+// contexts of the server, the call fails with RESOURCE_EXHAUSTED.
+func (s *Server) call(w http.ResponseWriter, r *http.Request) (err error) {
+	defer func() {
+		switch x := recover().(type) {
+		case nil:
+		case *adt.LimitError:
+			err = errorf(codeResourceExhausted, "%v", x)
+		default:
+			panic(x)
+		}
+	}()
+	client := r.RemoteAddr
+	switch method, _ := strings.CutPrefix(r.URL.Path, "/"+ServiceName+"/"); method {
+	case "Compile":
+		return s.compile(w, r.Body, client)
+	case "Unify":
+		return unary(w, r.Body, client, s.unify)
+	case "Lookup":
+		return unary(w, r.Body, client, s.lookup)
+	case "Validate":
+		return unary(w, r.Body, client, s.validate)
+	case "Export":
+		return s.export(w, r.Body)
+	case "Release":
+		return unary(w, r.Body, client, s.release)
+	}
+	return errorf(codeUnimplemented, "unknown method %s", r.URL.Path)
+}
+
+// encodeStatusMessage percent-encodes msg for the grpc-message trailer.
+func encodeStatusMessage(msg string) string {
+	var b strings.Builder
+	for i := 0; i < len(msg); i++ {
+		if c := msg[i]; c < ' ' || c > '~' || c == '%' {
+			fmt.Fprintf(&b, "%%%02X", c)
+		} else {
+			b.WriteByte(c)
+		}
+	}
+	return b.String()
+}
+
+// readMessage reads a length-prefixed message. It returns io.EOF if the
+// request has no more messages.
+func readMessage(r io.Reader) ([]byte, error) {
+	var hdr [5]byte
+	if _, err := io.ReadFull(r, hdr[:]); err != nil {
+		if err == io.ErrUnexpectedEOF {
+			return nil, errorf(codeInvalidArgument, "truncated message")
+		}
+		return nil, err
+	}
+	if hdr[0] != 0 {
+		return nil, errorf(codeUnimplemented, "compressed messages are not supported")
+	}
+	size := binary.BigEndian.Uint32(hdr[1:])
+	if size > maxMessageSize {
+		return nil, errorf(codeResourceExhausted, "message of %d bytes exceeds the limit of %d bytes", size, maxMessageSize)
+	}
+	msg := make([]byte, size)
+	if _, err := io.ReadFull(r, msg); err != nil {
+		return nil, errorf(codeInvalidArgument, "truncated message")
+	}
+	return msg, nil
+}
+
+// writeMessage writes a length-prefixed message and flushes it to the
+// client.
+func writeMessage(w http.ResponseWriter, msg []byte) error {
+	var hdr [5]byte
+	binary.BigEndian.PutUint32(hdr[1:], uint32(len(msg)))
+	if _, err := w.Write(hdr[:]); err != nil {
+		return err
+	}
+	if _, err := w.Write(msg); err != nil {
+		return err
+	}
+	return http.NewResponseController(w).Flush()
+}
+
+type message interface {
+	marshal() []byte
+	unmarshal([]byte) error
+}
+
+// readRequest reads the single request message of a unary or server
+// streaming call into m.
+func readRequest(r io.Reader, m message) error {
+	msg, err := readMessage(r)
+	if err == io.EOF {
+		return errorf(codeInvalidArgument, "missing request message")
+	}
+	if err != nil {
+		return err
+	}
+	if err := m.unmarshal(msg); err != nil {
+		return errorf(codeInvalidArgument, "%v", err)
+	}
+	if _, err := readMessage(r); err != io.EOF {
+		return errorf(codeInvalidArgument, "more than one request message")
+	}
+	return nil
+}
+
+// unary serves a unary call of client by calling f with the request.
+func unary[Req any, PReq interface {
+	*Req
+	message
+}, Resp message](w http.ResponseWriter, r io.Reader, client string, f func(string, PReq) (Resp, error)) error {
+	req := PReq(new(Req))
+	if err := readRequest(r, req); err != nil {
+		return err
+	}
+	resp, err := f(client, req)
+	if err != nil {
+		return err
+	}
+	return writeMessage(w, resp.marshal())
+}
+
+// lookupLocked returns the value of handle h. s.mu must be held.
+func (s *Server) lookupLocked(h uint64) (cue.Value, error) {
+	hv, ok := s.values[h]
+	if !ok {
+		return cue.Value{}, errorf(codeNotFound, "unknown handle %d", h)
+	}
+	return hv.v, nil
+}
+
+// newValueLocked returns the response for a new value v held by client.
+// It fails with RESOURCE_EXHAUSTED if the client holds too many handles.
+// s.mu must be held.
+func (s *Server) newValueLocked(client string, v cue.Value) (*valueResponse, error) {
+	if err := v.Err(); err != nil {
+		return &valueResponse{errors: toErrors(err)}, nil
+	}
+	if s.handles[client] >= s.maxHandles {
+		return nil, errorf(codeResourceExhausted, "client holds %d handles, the maximum; release some first", s.maxHandles)
+	}
+	s.last++
+	s.values[s.last] = handle{v: v, client: client}
+	s.handles[client]++
+	return &valueResponse{handle: s.last}, nil
+}
+
+func (s *Server) compile(w http.ResponseWriter, r io.Reader, client string) error {
+	// Combine the chunks of each source.
+	var sources []*compileRequest
+	size := 0
+	for {
+		msg, err := readMessage(r)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		req := &compileRequest{}
+		if err := req.unmarshal(msg); err != nil {
+			return errorf(codeInvalidArgument, "%v", err)
+		}
+		if req.filename == "" {
+			return errorf(codeInvalidArgument, "source without filename")
+		}
+		if size += len(req.data); size > maxCompileSize {
+			return errorf(codeResourceExhausted, "sources exceed the limit of %d bytes", maxCompileSize)
+		}
+		if n := len(sources); n > 0 && sources[n-1].filename == req.filename {
+			last := sources[n-1]
+			if req.encoding != "" && req.encoding != last.encoding {
+				return errorf(codeInvalidArgument, "%s: conflicting encodings %q and %q", req.filename, last.encoding, req.encoding)
+			}
+			last.data = append(last.data, req.data...)
+			continue
+		}
+		sources = append(sources, req)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	inst := build.NewContext().NewInstance("", nil)
+	for _, src := range sources {
+		f, err := filetypes.ParseFileAndType(src.filename, src.encoding, filetypes.Input)
+		if err != nil {
+			return errorf(codeInvalidArgument, "%v", err)
+		}
+		f.Source = src.data
+		file, err := decode(s.ctx, f)
+		if err == nil {
+			err = inst.AddSyntax(file)
+		}
+		if err != nil {
+			return writeMessage(w, (&valueResponse{errors: toErrors(err)}).marshal())
+		}
+	}
+	resp, err := s.newValueLocked(client, s.ctx.BuildInstance(inst))
+	if err != nil {
+		return err
+	}
+	return writeMessage(w, resp.marshal())
+}
+
+// decode decodes the single value of f.
+func decode(ctx *cue.Context, f *build.File) (*ast.File, error) {
+	d := encoding.NewDecoder(ctx, f, &encoding.Config{Mode: filetypes.Input})
+	defer d.Close()
+	if err := d.Err(); err != nil {
+		return nil, err
+	}
+	file := d.File()
+	d.Next()
+	if err := d.Err(); err != nil {
+		return nil, err
+	}
+	if !d.Done() {
+		return nil, cueerrors.Newf(token.NoPos, "%s: streams of more than one value are not supported", f.Filename)
+	}
+	return file, nil
+}
+
+func (s *Server) unify(client string, req *unifyRequest) (*valueResponse, error) {
+	if len(req.handles) == 0 {
+		return nil, errorf(codeInvalidArgument, "no handles to unify")
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var result cue.Value
+	for i, h := range req.handles {
+		v, err := s.lookupLocked(h)
+		if err != nil {
+			return nil, err
+		}
+		if i == 0 {
+			result = v
+		} else {
+			result = result.Unify(v)
+		}
+	}
+	return s.newValueLocked(client, result)
+}
+
+func (s *Server) lookup(client string, req *lookupRequest) (*valueResponse, error) {
+	path := cue.ParsePath(req.path)
+	if err := path.Err(); err != nil {
+		return nil, errorf(codeInvalidArgument, "invalid path %q: %v", req.path, err)
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	v, err := s.lookupLocked(req.handle)
+	if err != nil {
+		return nil, err
+	}
+	return s.newValueLocked(client, v.LookupPath(path))
+}
+
+func (s *Server) validate(_ string, req *validateRequest) (*validateResponse, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	v, err := s.lookupLocked(req.handle)
+	if err != nil {
+		return nil, err
+	}
+	return &validateResponse{errors: toErrors(v.Validate(cue.Concrete(req.concrete)))}, nil
+}
+
+func (s *Server) export(w http.ResponseWriter, r io.Reader) error {
+	req := &exportRequest{}
+	if err := readRequest(r, req); err != nil {
+		return err
+	}
+	if req.encoding == "" {
+		req.encoding = "json"
+	}
+	f, err := filetypes.ParseFile(req.encoding+":-", filetypes.Export)
+	if err != nil {
+		return errorf(codeInvalidArgument, "%v", err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	v, err := s.lookupLocked(req.handle)
+	if err != nil {
+		return err
+	}
+	cw := &chunkWriter{w: w}
+	enc, err := encoding.NewEncoder(s.ctx, f, &encoding.Config{
+		Mode: filetypes.Export,
+		Out:  cw,
+	})
+	if err != nil {
+		return errorf(codeInvalidArgument, "%v", err)
+	}
+	if err := enc.Encode(v); err != nil {
+		if cw.err != nil {
+			return cw.err
+		}
+		return errorf(codeFailedPrecondition, "%v", cueerrors.Details(err, nil))
+	}
+	if err := enc.Close(); err != nil {
+		return err
+	}
+	return cw.flush()
+}
+
+// A chunkWriter sends the data written to it as a stream of
+// ExportResponse messages.
+type chunkWriter struct {
+	w   http.ResponseWriter
+	buf []byte
+	err error // error of writing to w
+}
+
+func (c *chunkWriter) Write(p []byte) (int, error) {
+	n := len(p)
+	for len(p) > 0 {
+		k := min(len(p), exportChunkSize-len(c.buf))
+		c.buf = append(c.buf, p[:k]...)
+		p = p[k:]
+		if len(c.buf) == exportChunkSize {
+			if err := c.flush(); err != nil {
+				return 0, err
+			}
+		}
+	}
+	return n, nil
+}
+
+func (c *chunkWriter) flush() error {
+	if c.err != nil || len(c.buf) == 0 {
+		return c.err
+	}
+	c.err = writeMessage(c.w, (&exportResponse{data: c.buf}).marshal())
+	c.buf = c.buf[:0]
+	return c.err
+}
+
+func (s *Server) release(_ string, req *releaseRequest) (*releaseResponse, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, h := range req.handles {
+		hv, ok := s.values[h]
+		if !ok {
+			continue
+		}
+		delete(s.values, h)
+		// Handles count against the client which created them, even if
+		// another client releases them.
+		if s.handles[hv.client]--; s.handles[hv.client] == 0 {
+			delete(s.handles, hv.client)
+		}
+	}
+	if len(s.values) == 0 {
+		// Drop the instances compiled so far, which the context keeps.
+		// Handles are never reused, so that released handles cannot
+		// refer to values of the new context.
+		s.ctx = s.newContext()
+	}
+	return &releaseResponse{}, nil
+}
+
+// toErrors converts err to Error messages.
+func toErrors(err error) []errorMessage {
+	var errs []errorMessage
+	for _, e := range cueerrors.Errors(err) {
+		m := errorMessage{
+			message: e.Error(),
+			path:    strings.Join(e.Path(), "."),
+		}
+		if format, args := e.Msg(); format != "" {
+			m.message = fmt.Sprintf(format, args...)
+		}
+		for _, pos := range cueerrors.Positions(e) {
+			p := pos.Position()
+			if !p.IsValid() {
+				continue
+			}
+			m.positions = append(m.positions, position{
+				filename: p.Filename,
+				line:     int32(p.Line),
+				column:   int32(p.Column),
+			})
+		}
+		errs = append(errs, m)
+	}
+	return errs
+}
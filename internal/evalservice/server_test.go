@@ -0,0 +1,348 @@
+// Copyright 2025 CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package evalservice
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"encoding/binary"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/go-quicktest/qt"
+	"github.com/google/go-cmp/cmp"
+	"golang.org/x/net/http2"
+	"golang.org/x/net/http2/h2c"
+
+	"cuelang.org/go/cue"
+	"cuelang.org/go/cue/cuecontext"
+)
+
+var cmpMessages = cmp.AllowUnexported(errorMessage{}, position{})
+
+// client is a minimal gRPC client for the service.
+type client struct {
+	t   *testing.T
+	url string
+	hc  *http.Client
+}
+
+func newTestClient(t *testing.T) *client {
+	return newServerClient(t, NewServer(func() *cue.Context { return cuecontext.New() }))
+}
+
+// newServerClient returns a client of s. Each client has its own
+// connection.
+func newServerClient(t *testing.T, s *Server) *client {
+	srv := httptest.NewServer(h2c.NewHandler(s, &http2.Server{}))
+	t.Cleanup(srv.Close)
+	return &client{t: t, url: srv.URL, hc: &http.Client{
+		Transport: &http2.Transport{
+			AllowHTTP: true,
+			DialTLSContext: func(ctx context.Context, network, addr string, _ *tls.Config) (net.Conn, error) {
+				var d net.Dialer
+				return d.DialContext(ctx, network, addr)
+			},
+		},
+	}}
+}
+
+// call calls method with the given request messages and returns the
+// response messages and the gRPC status.
+func (c *client) call(method string, reqs ...message) (resps [][]byte, code int, msg string) {
+	c.t.Helper()
+	var body bytes.Buffer
+	for _, req := range reqs {
+		b := req.marshal()
+		body.WriteByte(0)
+		binary.Write(&body, binary.BigEndian, uint32(len(b)))
+		body.Write(b)
+	}
+	req, err := http.NewRequest("POST", c.url+"/"+ServiceName+"/"+method, &body)
+	qt.Assert(c.t, qt.IsNil(err))
+	req.Header.Set("Content-Type", "application/grpc")
+	resp, err := c.hc.Do(req)
+	qt.Assert(c.t, qt.IsNil(err))
+	defer resp.Body.Close()
+	qt.Assert(c.t, qt.Equals(resp.StatusCode, http.StatusOK))
+	for {
+		m, err := readMessage(resp.Body)
+		if err == io.EOF {
+			break
+		}
+		qt.Assert(c.t, qt.IsNil(err))
+		resps = append(resps, m)
+	}
+	code, err = strconv.Atoi(resp.Trailer.Get("Grpc-Status"))
+	qt.Assert(c.t, qt.IsNil(err))
+	msg, err = url.PathUnescape(resp.Trailer.Get("Grpc-Message"))
+	qt.Assert(c.t, qt.IsNil(err))
+	return resps, code, msg
+}
+
+// unary calls a unary method which must succeed, and decodes its
+// response into resp.
+func (c *client) unary(method string, req, resp message) {
+	c.t.Helper()
+	resps, code, msg := c.call(method, req)
+	qt.Assert(c.t, qt.Equals(code, codeOK), qt.Commentf("%s", msg))
+	qt.Assert(c.t, qt.HasLen(resps, 1))
+	qt.Assert(c.t, qt.IsNil(resp.unmarshal(resps[0])))
+}
+
+func (c *client) compile(reqs ...message) *valueResponse {
+	c.t.Helper()
+	resps, code, msg := c.call("Compile", reqs...)
+	qt.Assert(c.t, qt.Equals(code, codeOK), qt.Commentf("%s", msg))
+	qt.Assert(c.t, qt.HasLen(resps, 1))
+	resp := &valueResponse{}
+	qt.Assert(c.t, qt.IsNil(resp.unmarshal(resps[0])))
+	return resp
+}
+
+func TestServer(t *testing.T) {
+	c := newTestClient(t)
+
+	// CUE files of a package share a scope, and large sources may be
+	// sent in chunks.
+	schema := c.compile(
+		&compileRequest{filename: "a.cue", data: []byte("package schema\n\n#Person: {\n\tname!: ")},
+		&compileRequest{filename: "a.cue", data: []byte("string\n\tage: int & >=0 & <=max\n}\n")},
+		&compileRequest{filename: "b.cue", data: []byte("package schema\n\nmax: 150\n")},
+	)
+	qt.Assert(t, qt.HasLen(schema.errors, 0))
+	qt.Assert(t, qt.Not(qt.Equals(schema.handle, 0)))
+
+	person := &valueResponse{}
+	c.unary("Lookup", &lookupRequest{handle: schema.handle, path: "#Person"}, person)
+	qt.Assert(t, qt.HasLen(person.errors, 0))
+
+	data := c.compile(&compileRequest{filename: "data.yaml", data: []byte("age: 30\n")})
+	qt.Assert(t, qt.HasLen(data.errors, 0))
+
+	unified := &valueResponse{}
+	c.unary("Unify", &unifyRequest{handles: []uint64{person.handle, data.handle}}, unified)
+	qt.Assert(t, qt.HasLen(unified.errors, 0))
+
+	validated := &validateResponse{}
+	c.unary("Validate", &validateRequest{handle: unified.handle, concrete: true}, validated)
+	qt.Assert(t, qt.CmpEquals(validated.errors, []errorMessage{{
+		message:   "field is required but not present",
+		path:      "#Person.name",
+		positions: []position{{filename: "a.cue", line: 4, column: 2}},
+	}}, cmpMessages))
+
+	// Conflicts are reported when unifying.
+	bad := c.compile(&compileRequest{filename: "bad.yaml", data: []byte("name: Alice\nage: 200\n")})
+	unified = &valueResponse{}
+	c.unary("Unify", &unifyRequest{handles: []uint64{person.handle, bad.handle}}, unified)
+	qt.Assert(t, qt.Equals(unified.handle, uint64(0)))
+	qt.Assert(t, qt.CmpEquals(unified.errors, []errorMessage{{
+		message: "invalid value 200 (out of bound <=150)",
+		path:    "#Person.age",
+		positions: []position{
+			{filename: "a.cue", line: 5, column: 19},
+			{filename: "bad.yaml", line: 2, column: 6},
+		},
+	}}, cmpMessages))
+
+	// Incomplete values are only errors if they must be concrete.
+	validated = &validateResponse{}
+	c.unary("Validate", &validateRequest{handle: person.handle}, validated)
+	qt.Assert(t, qt.HasLen(validated.errors, 0))
+
+	exported := exportString(t, c, &exportRequest{handle: bad.handle, encoding: "yaml"})
+	qt.Assert(t, qt.Equals(exported, "name: Alice\nage: 200\n"))
+
+	// Export fails for values which are not concrete.
+	_, code, msg := c.call("Export", &exportRequest{handle: person.handle})
+	qt.Assert(t, qt.Equals(code, codeFailedPrecondition))
+	qt.Assert(t, qt.StringContains(msg, "#Person.age: incomplete value"))
+
+	// Released handles are gone.
+	c.unary("Release", &releaseRequest{handles: []uint64{data.handle, unified.handle}}, &releaseResponse{})
+	_, code, msg = c.call("Validate", &validateRequest{handle: data.handle})
+	qt.Assert(t, qt.Equals(code, codeNotFound))
+	qt.Assert(t, qt.Equals(msg, "unknown handle "+strconv.FormatUint(data.handle, 10)))
+}
+
+func TestServerErrors(t *testing.T) {
+	c := newTestClient(t)
+
+	// Errors in sources are part of the response.
+	resp := c.compile(&compileRequest{filename: "x.cue", data: []byte("a: 1\na: 2\n")})
+	qt.Assert(t, qt.Equals(resp.handle, uint64(0)))
+	qt.Assert(t, qt.HasLen(resp.errors, 1))
+	qt.Assert(t, qt.Equals(resp.errors[0].message, "conflicting values 2 and 1"))
+
+	resp = c.compile(&compileRequest{filename: "x.json", data: []byte(`{"a": `)})
+	qt.Assert(t, qt.HasLen(resp.errors, 1))
+
+	resp = c.compile(&compileRequest{filename: "x", encoding: "yaml", data: []byte("a: 1\n---\na: 2\n")})
+	qt.Assert(t, qt.HasLen(resp.errors, 1))
+	qt.Assert(t, qt.StringContains(resp.errors[0].message, "streams of more than one value are not supported"))
+
+	ok := c.compile(&compileRequest{filename: "ok.cue", data: []byte("a: 1\n")})
+	qt.Assert(t, qt.HasLen(ok.errors, 0))
+	resp = &valueResponse{}
+	c.unary("Lookup", &lookupRequest{handle: ok.handle, path: "b"}, resp)
+	qt.Assert(t, qt.Equals(resp.handle, uint64(0)))
+	qt.Assert(t, qt.HasLen(resp.errors, 1))
+
+	// Invalid requests fail with a status.
+	for _, test := range []struct {
+		method string
+		reqs   []message
+		code   int
+	}{
+		{"Compile", []message{&compileRequest{data: []byte("a: 1")}}, codeInvalidArgument},
+		{"Compile", []message{&compileRequest{filename: "x.unknown"}}, codeInvalidArgument},
+		{"Unify", []message{&unifyRequest{}}, codeInvalidArgument},
+		{"Unify", []message{&unifyRequest{handles: []uint64{ok.handle, 1000}}}, codeNotFound},
+		{"Lookup", []message{&lookupRequest{handle: ok.handle, path: "a."}}, codeInvalidArgument},
+		{"Validate", nil, codeInvalidArgument},
+		{"Validate", []message{&validateRequest{handle: ok.handle}, &validateRequest{handle: ok.handle}}, codeInvalidArgument},
+		{"Export", []message{&exportRequest{handle: ok.handle, encoding: "nope"}}, codeInvalidArgument},
+		{"Evaluate", []message{&validateRequest{}}, codeUnimplemented},
+	} {
+		_, code, msg := c.call(test.method, test.reqs...)
+		qt.Check(t, qt.Equals(code, test.code), qt.Commentf("%s: %s", test.method, msg))
+	}
+}
+
+func TestServerExportChunks(t *testing.T) {
+	c := newTestClient(t)
+	large := strings.Repeat("x", 3*exportChunkSize)
+	resp := c.compile(&compileRequest{filename: "large.cue", data: []byte("s: " + strconv.Quote(large))})
+	qt.Assert(t, qt.HasLen(resp.errors, 0))
+
+	resps, code, msg := c.call("Export", &exportRequest{handle: resp.handle})
+	qt.Assert(t, qt.Equals(code, codeOK), qt.Commentf("%s", msg))
+	qt.Assert(t, qt.HasLen(resps, 4))
+	var out bytes.Buffer
+	for _, r := range resps {
+		var m exportResponse
+		qt.Assert(t, qt.IsNil(m.unmarshal(r)))
+		qt.Assert(t, qt.IsTrue(len(m.data) <= exportChunkSize))
+		out.Write(m.data)
+	}
+	qt.Assert(t, qt.Equals(out.String(), "{\n    \"s\": \""+large+"\"\n}\n"))
+}
+
+func TestServerCompileLimit(t *testing.T) {
+	c := newTestClient(t)
+	chunk := []byte("// " + strings.Repeat("x", maxMessageSize-1024) + "\n")
+	var reqs []message
+	for size := 0; size <= maxCompileSize; size += len(chunk) {
+		reqs = append(reqs, &compileRequest{filename: "x.cue", data: chunk})
+	}
+	_, code, msg := c.call("Compile", reqs...)
+	qt.Assert(t, qt.Equals(code, codeResourceExhausted))
+	qt.Assert(t, qt.Equals(msg, "sources exceed the limit of 67108864 bytes"))
+}
+
+func TestServerReleaseContext(t *testing.T) {
+	s := NewServer(func() *cue.Context { return cuecontext.New() })
+	s.newValueLocked("c", s.ctx.CompileString("a: 1"))
+	s.newValueLocked("c", s.ctx.CompileString("b: 1"))
+	ctx := s.ctx
+
+	// The context is kept as long as some values are.
+	_, err := s.release("c", &releaseRequest{handles: []uint64{1}})
+	qt.Assert(t, qt.IsNil(err))
+	qt.Assert(t, qt.Equals(s.ctx, ctx))
+
+	_, err = s.release("c", &releaseRequest{handles: []uint64{2}})
+	qt.Assert(t, qt.IsNil(err))
+	qt.Assert(t, qt.Not(qt.Equals(s.ctx, ctx)))
+
+	// Handles of released values are not reused.
+	resp, err := s.newValueLocked("c", s.ctx.CompileString("c: 1"))
+	qt.Assert(t, qt.IsNil(err))
+	qt.Assert(t, qt.Equals(resp.handle, uint64(3)))
+}
+
+func TestServerHandleLimit(t *testing.T) {
+	s := NewServer(func() *cue.Context { return cuecontext.New() })
+	s.maxHandles = 2
+	c1 := newServerClient(t, s)
+	c2 := newServerClient(t, s)
+
+	req := func(src string) message {
+		return &compileRequest{filename: "x.cue", data: []byte(src)}
+	}
+	h1 := c1.compile(req("a: 1")).handle
+	c1.compile(req("b: 1"))
+	resps, code, msg := c1.call("Compile", req("c: 1"))
+	qt.Assert(t, qt.HasLen(resps, 0))
+	qt.Assert(t, qt.Equals(code, codeResourceExhausted))
+	qt.Assert(t, qt.Equals(msg, "client holds 2 handles, the maximum; release some first"))
+	_, code, _ = c1.call("Lookup", &lookupRequest{handle: h1, path: "a"})
+	qt.Assert(t, qt.Equals(code, codeResourceExhausted))
+
+	// Other clients have their own limit.
+	c2.compile(req("d: 1"))
+
+	// Releasing a handle makes room for another.
+	c1.unary("Release", &releaseRequest{handles: []uint64{h1}}, &releaseResponse{})
+	c1.compile(req("c: 1"))
+}
+
+func exportString(t *testing.T, c *client, req *exportRequest) string {
+	t.Helper()
+	resps, code, msg := c.call("Export", req)
+	qt.Assert(t, qt.Equals(code, codeOK), qt.Commentf("%s", msg))
+	var out bytes.Buffer
+	for _, r := range resps {
+		var m exportResponse
+		qt.Assert(t, qt.IsNil(m.unmarshal(r)))
+		out.Write(m.data)
+	}
+	return out.String()
+}
+
+func TestMessages(t *testing.T) {
+	// Repeated fields may be sent packed or unpacked.
+	var b []byte
+	b = appendVarintField(b, 1, 3)
+	b = appendPackedField(b, 1, []uint64{4, 300})
+	var req unifyRequest
+	qt.Assert(t, qt.IsNil(req.unmarshal(b)))
+	qt.Assert(t, qt.DeepEquals(req.handles, []uint64{3, 4, 300}))
+
+	// Unknown fields are skipped.
+	b = appendBytesField(nil, 9, []byte("unknown"))
+	b = appendVarintField(b, 1, 7)
+	var v validateRequest
+	qt.Assert(t, qt.IsNil(v.unmarshal(b)))
+	qt.Assert(t, qt.Equals(v.handle, uint64(7)))
+
+	// Truncated messages are rejected.
+	b = appendBytesField(nil, 2, []byte("path"))
+	var l lookupRequest
+	qt.Assert(t, qt.ErrorIs(l.unmarshal(b[:len(b)-1]), errInvalidMessage))
+
+	p := position{filename: "f", line: -1, column: 2}
+	var p2 position
+	qt.Assert(t, qt.IsNil(p2.unmarshal(p.marshal())))
+	qt.Assert(t, qt.Equals(p2, p))
+}
@@ -71,6 +71,10 @@ type FileInfo = internal.FileInfo
 //
 //	json: foo.data bar.data json+schema: bar.schema
 func ParseArgs(args []string) (files []*build.File, err error) {
+	return parseArgs(nil, args)
+}
+
+func parseArgs(m *TypeMap, args []string) (files []*build.File, err error) {
 	qualifier := ""
 	hasFiles := false
 
@@ -82,7 +86,7 @@ func ParseArgs(args []string) (files []*build.File, err error) {
 			if s == "" {
 				return nil, errors.Newf(token.NoPos, "empty file name")
 			}
-			f, err := toFile(Input, sc, s)
+			f, err := m.toFile(Input, sc, s)
 			if err != nil {
 				return nil, err
 			}
@@ -142,6 +146,10 @@ func DefaultTagsForInterpretation(interp build.Interpretation, mode Mode) map[st
 //
 //	cue eval -o yaml:foo.data
 func ParseFile(s string, mode Mode) (*build.File, error) {
+	return parseFile(nil, s, mode)
+}
+
+func parseFile(m *TypeMap, s string, mode Mode) (*build.File, error) {
 	scope := ""
 	file := s
 
@@ -160,16 +168,20 @@ func ParseFile(s string, mode Mode) (*build.File, error) {
 		return nil, errors.Newf(token.NoPos, "empty file name")
 	}
 
-	return ParseFileAndType(file, scope, mode)
+	return parseFileAndType(m, file, scope, mode)
 }
 
 // ParseFileAndType parses a file and type combo.
 func ParseFileAndType(file, scope string, mode Mode) (*build.File, error) {
+	return parseFileAndType(nil, file, scope, mode)
+}
+
+func parseFileAndType(m *TypeMap, file, scope string, mode Mode) (*build.File, error) {
 	sc, err := parseScope(scope)
 	if err != nil {
 		return nil, err
 	}
-	return toFile(mode, sc, file)
+	return m.toFile(mode, sc, file)
 }
 
 // scope holds attributes that influence encoding and decoding.
@@ -181,6 +193,11 @@ type scope struct {
 	subsidiaryString map[string]string
 }
 
+// isEmpty reports whether sc has no tags.
+func (sc *scope) isEmpty() bool {
+	return len(sc.topLevel) == 0 && len(sc.subsidiaryBool) == 0 && len(sc.subsidiaryString) == 0
+}
+
 func parseScope(scopeStr string) (*scope, error) {
 	if scopeStr == "" {
 		return &scope{}, nil
@@ -214,7 +231,13 @@ func parseScope(scopeStr string) (*scope, error) {
 			}
 			sc.subsidiaryString[tagName] = tagVal
 		default:
-			return nil, errors.Newf(token.NoPos, "unknown filetype %s", tagName)
+			if !IsRegistered(build.Encoding(tagName)) {
+				return nil, errors.Newf(token.NoPos, "unknown filetype %s", tagName)
+			}
+			if hasValue {
+				return nil, errors.Newf(token.NoPos, "cannot specify value for tag %q", tagName)
+			}
+			sc.topLevel[tagName] = true
 		}
 	}
 	return &sc, nil
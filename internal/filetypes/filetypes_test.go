@@ -16,6 +16,7 @@ package filetypes
 
 import (
 	"strings"
+	"sync"
 	"testing"
 
 	"github.com/go-quicktest/qt"
@@ -397,6 +398,54 @@ func TestParseFile(t *testing.T) {
 	}
 }
 
+var registerTestEncoding = sync.OnceValue(func() error {
+	return RegisterEncoding("testlines", []string{".testlines"})
+})
+
+func TestParseFileRegistered(t *testing.T) {
+	qt.Assert(t, qt.IsNil(registerTestEncoding()))
+	testCases := []struct {
+		in   string
+		mode Mode
+		out  interface{}
+	}{{
+		in:   "x.testlines",
+		mode: Input,
+		out: &build.File{
+			Filename:       "x.testlines",
+			Encoding:       "testlines",
+			Interpretation: build.Auto,
+		},
+	}, {
+		in:   "testlines:x.txt",
+		mode: Input,
+		out: &build.File{
+			Filename: "x.txt",
+			Encoding: "testlines",
+		},
+	}, {
+		// Combining a registered encoding with a built-in one is an
+		// error, regardless of the order in which the tags are checked.
+		in:   "testlines+yaml:x.txt",
+		mode: Input,
+		out:  `cannot combine encodings testlines and yaml`,
+	}, {
+		in:   "yaml+testlines:x.txt",
+		mode: Input,
+		out:  `cannot combine encodings testlines and yaml`,
+	}}
+	for _, tc := range testCases {
+		t.Run(tc.in, func(t *testing.T) {
+			// Tags are held in a map, so parse repeatedly to cover
+			// different orders of iteration.
+			for range 20 {
+				f, err := ParseFile(tc.in, tc.mode)
+				check(t, tc.out, f, err)
+			}
+		})
+	}
+}
+
 func TestParseArgs(t *testing.T) {
 	testCases := []struct {
 		in  string
@@ -511,3 +560,69 @@ func TestDefaultTagsForInterpretation(t *testing.T) {
 		"strictKeywords": false,
 	}))
 }
+
+func TestTypeMap(t *testing.T) {
+	m, err := NewTypeMap(map[string]string{
+		"*.yaml":      "json",
+		"*.tmpl.yaml": "text",
+		"Tiltfile":    "text",
+		"*.schema":    "json+jsonschema",
+	})
+	qt.Assert(t, qt.IsNil(err))
+
+	testCases := []struct {
+		in  string
+		out interface{}
+	}{{
+		in: "a.tmpl.yaml b.yaml dir/Tiltfile c.json",
+		out: []*build.File{
+			{Filename: "a.tmpl.yaml", Encoding: build.Text, Form: build.Data},
+			{Filename: "b.yaml", Encoding: build.JSON},
+			{Filename: "dir/Tiltfile", Encoding: build.Text, Form: build.Data},
+			{Filename: "c.json", Encoding: build.JSON, Interpretation: build.Auto},
+		},
+	}, {
+		in: "x.schema yaml: a.tmpl.yaml",
+		out: []*build.File{
+			{
+				Filename:       "x.schema",
+				Encoding:       build.JSON,
+				Form:           build.Schema,
+				Interpretation: "jsonschema",
+				BoolTags: map[string]bool{
+					"strict":         false,
+					"strictFeatures": false,
+					"strictKeywords": false,
+				},
+			},
+			{Filename: "a.tmpl.yaml", Encoding: build.YAML},
+		},
+	}}
+	for _, tc := range testCases {
+		t.Run(tc.in, func(t *testing.T) {
+			files, err := m.ParseArgs(strings.Split(tc.in, " "))
+			check(t, tc.out, files, err)
+		})
+	}
+
+	_, err = NewTypeMap(map[string]string{"a/*.yaml": "text"})
+	qt.Assert(t, qt.ErrorMatches(err, `invalid file type pattern "a/\*.yaml": may not contain '/'`))
+	_, err = NewTypeMap(map[string]string{"*.yaml": "nope"})
+	qt.Assert(t, qt.ErrorMatches(err, `invalid file type for pattern "\*.yaml": unknown filetype nope`))
+}
+
+func TestTypeMapWithStdin(t *testing.T) {
+	var m *TypeMap
+	m, err := m.WithStdin("yaml")
+	qt.Assert(t, qt.IsNil(err))
+
+	files, err := m.ParseArgs([]string{"-", "a.json", "json:", "-"})
+	check(t, []*build.File{
+		{Filename: "-", Encoding: build.YAML},
+		{Filename: "a.json", Encoding: build.JSON, Interpretation: build.Auto},
+		{Filename: "-", Encoding: build.JSON},
+	}, files, err)
+
+	_, err = m.WithStdin("nope")
+	qt.Assert(t, qt.ErrorMatches(err, `invalid file type for standard input: unknown filetype nope`))
+}
@@ -0,0 +1,165 @@
+// Copyright 2025 CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package filetypes
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+
+	"cuelang.org/go/cue/build"
+	"cuelang.org/go/cue/errors"
+	"cuelang.org/go/cue/token"
+)
+
+// registry holds the encodings added with [RegisterEncoding].
+var registry struct {
+	mu sync.RWMutex
+
+	// names holds the names of the registered encodings.
+	names map[string]bool
+
+	// exts maps file extensions to the names of their encodings.
+	exts map[string]string
+}
+
+// RegisterEncoding registers a data encoding with the given name and
+// file extensions, which must not be used by any built-in file type or
+// other registered encoding. Each extension must start with a dot.
+//
+// A registered encoding may be used wherever a built-in data encoding
+// such as json may be used, both as a tag and by file extension, and
+// may be combined with the same tags, such as jsonschema.
+func RegisterEncoding(name string, exts []string) error {
+	if name == "" || strings.ContainsAny(name, "+:=") {
+		return fmt.Errorf("invalid encoding name %q", name)
+	}
+	if tagTypes[name] != TagUnknown {
+		return fmt.Errorf("encoding name %q is already used by a built-in tag", name)
+	}
+	for _, ext := range exts {
+		if !strings.HasPrefix(ext, ".") || len(ext) == 1 {
+			return fmt.Errorf("invalid file extension %q for encoding %q", ext, name)
+		}
+		if isBuiltinExt(ext) {
+			return fmt.Errorf("file extension %q is already used by a built-in file type", ext)
+		}
+	}
+
+	registry.mu.Lock()
+	defer registry.mu.Unlock()
+	if registry.names[name] {
+		return fmt.Errorf("encoding %q registered twice", name)
+	}
+	for _, ext := range exts {
+		if other, ok := registry.exts[ext]; ok {
+			return fmt.Errorf("file extension %q is already used by encoding %q", ext, other)
+		}
+	}
+	if registry.names == nil {
+		registry.names = make(map[string]bool)
+		registry.exts = make(map[string]string)
+	}
+	registry.names[name] = true
+	for _, ext := range exts {
+		registry.exts[ext] = name
+	}
+	return nil
+}
+
+// IsRegistered reports whether e was registered with
+// [RegisterEncoding].
+func IsRegistered(e build.Encoding) bool {
+	registry.mu.RLock()
+	defer registry.mu.RUnlock()
+	return registry.names[string(e)]
+}
+
+// registeredNames returns the names of the registered encodings.
+func registeredNames() []string {
+	registry.mu.RLock()
+	defer registry.mu.RUnlock()
+	names := make([]string, 0, len(registry.names))
+	for name := range registry.names {
+		names = append(names, name)
+	}
+	return names
+}
+
+// registeredEncoding returns the name of the registered encoding
+// selected by the top-level tags of sc, or by the extension of filename
+// if they select no encoding, and whether there was one.
+func registeredEncoding(sc *scope, filename string) (string, bool) {
+	registry.mu.RLock()
+	defer registry.mu.RUnlock()
+	// Check for registered tags first, so that combinations with other
+	// encodings are reported as errors regardless of the order of tags.
+	for tag := range sc.topLevel {
+		if registry.names[tag] {
+			return tag, true
+		}
+	}
+	for tag := range sc.topLevel {
+		if isEncodingTag(tag) {
+			return "", false
+		}
+	}
+	name, ok := registry.exts[fileExt(filename)]
+	return name, ok
+}
+
+// toRegisteredFile returns the file for a registered encoding. It is
+// determined as if the encoding were json, so that the same tags and
+// defaults apply.
+func toRegisteredFile(mode Mode, sc *scope, filename, name string) (*build.File, error) {
+	registry.mu.RLock()
+	defer registry.mu.RUnlock()
+	// Without a tag for the encoding, it was selected by extension.
+	jsonScope := *sc
+	jsonFilename := "x.json"
+	if sc.topLevel[name] {
+		jsonScope.topLevel = map[string]bool{string(build.JSON): true}
+		jsonFilename = "-"
+		for tag := range sc.topLevel {
+			switch {
+			case tag == name:
+			case registry.names[tag] || isEncodingTag(tag):
+				return nil, errors.Newf(token.NoPos, "cannot combine encodings %s and %s", name, tag)
+			default:
+				jsonScope.topLevel[tag] = true
+			}
+		}
+	}
+	f, err := toFileGenerated(mode, &jsonScope, jsonFilename)
+	if err != nil {
+		return nil, err
+	}
+	f.Filename = filename
+	f.Encoding = build.Encoding(name)
+	return f, nil
+}
+
+// fromRegisteredFile returns the file info for a file with a registered
+// encoding, which is that of an equivalent json file.
+func fromRegisteredFile(b *build.File, mode Mode) (*FileInfo, error) {
+	jsonFile := *b
+	jsonFile.Encoding = build.JSON
+	fi, err := fromFileGenerated(&jsonFile, mode)
+	if err != nil {
+		return nil, err
+	}
+	fi.Encoding = b.Encoding
+	return fi, nil
+}
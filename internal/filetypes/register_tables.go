@@ -0,0 +1,50 @@
+// Copyright 2025 CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build !bootstrap
+
+package filetypes
+
+import (
+	"slices"
+
+	"cuelang.org/go/cue/build"
+)
+
+// isBuiltinExt reports whether ext is the extension of a built-in file
+// type.
+func isBuiltinExt(ext string) bool {
+	_, ok := allFileExts_rev[ext]
+	return ok
+}
+
+// isEncodingTag reports whether the top-level tag tag selects an
+// encoding, rather than an interpretation or form.
+func isEncodingTag(tag string) bool {
+	_, ok := allEncodings_rev[build.Encoding(tag)]
+	return ok
+}
+
+// Encodings returns the names of the built-in encodings and of the
+// encodings registered with [RegisterEncoding], in sorted order.
+func Encodings() []string {
+	names := registeredNames()
+	for _, e := range allEncodings {
+		if e != "" {
+			names = append(names, string(e))
+		}
+	}
+	slices.Sort(names)
+	return names
+}
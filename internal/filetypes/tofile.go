@@ -21,6 +21,9 @@ import (
 //go:generate go run -tags bootstrap ./generate.go
 
 func toFile(mode Mode, sc *scope, filename string) (*build.File, error) {
+	if name, ok := registeredEncoding(sc, filename); ok {
+		return toRegisteredFile(mode, sc, filename, name)
+	}
 	return toFileGenerated(mode, sc, filename)
 }
 
@@ -29,5 +32,8 @@ func toFile(mode Mode, sc *scope, filename string) (*build.File, error) {
 // by [ParseArgs] or similar.
 // The b.Encoding field must be non-empty.
 func FromFile(b *build.File, mode Mode) (*FileInfo, error) {
+	if IsRegistered(b.Encoding) {
+		return fromRegisteredFile(b, mode)
+	}
 	return fromFileGenerated(b, mode)
 }
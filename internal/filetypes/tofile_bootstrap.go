@@ -25,3 +25,15 @@ func toFileGenerated(mode Mode, sc *scope, filename string) (*build.File, error)
 func fromFileGenerated(b *build.File, mode Mode) (*FileInfo, error) {
 	panic("never called")
 }
+
+func isBuiltinExt(ext string) bool {
+	panic("never called")
+}
+
+func isEncodingTag(tag string) bool {
+	panic("never called")
+}
+
+func Encodings() []string {
+	panic("never called")
+}
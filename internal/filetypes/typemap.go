@@ -0,0 +1,155 @@
+// Copyright 2025 CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package filetypes
+
+import (
+	"cmp"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"cuelang.org/go/cue/build"
+	"cuelang.org/go/cue/errors"
+	"cuelang.org/go/cue/token"
+)
+
+// A TypeMap maps patterns matching the base names of files to the file
+// types of those files, such as configured by the filetypes field of a
+// module file. A mapping is used for a file instead of its extension if
+// no file type is given explicitly.
+//
+// A nil *TypeMap maps no files.
+type TypeMap struct {
+	// mappings is ordered from the longest pattern to the shortest.
+	mappings []typeMapping
+
+	// stdin holds the file type of "-", if any.
+	stdin *typeMapping
+}
+
+type typeMapping struct {
+	pattern   string
+	qualifier string
+	scope     *scope
+}
+
+// NewTypeMap returns a TypeMap for m, which maps patterns, using the
+// syntax of [path.Match], to file type qualifiers, such as "yaml" or
+// "json+jsonschema". If more than one pattern matches a file, the
+// longest is used.
+func NewTypeMap(m map[string]string) (*TypeMap, error) {
+	if len(m) == 0 {
+		return nil, nil
+	}
+	tm := &TypeMap{}
+	for pattern, qualifier := range m {
+		if strings.Contains(pattern, "/") {
+			return nil, errors.Newf(token.NoPos, "invalid file type pattern %q: may not contain '/'", pattern)
+		}
+		if _, err := path.Match(pattern, ""); err != nil {
+			return nil, errors.Newf(token.NoPos, "invalid file type pattern %q: %v", pattern, err)
+		}
+		if qualifier == "" {
+			return nil, errors.Newf(token.NoPos, "empty file type for pattern %q", pattern)
+		}
+		sc, err := parseScope(qualifier)
+		if err != nil {
+			return nil, errors.Wrapf(err, token.NoPos, "invalid file type for pattern %q", pattern)
+		}
+		tm.mappings = append(tm.mappings, typeMapping{pattern, qualifier, sc})
+	}
+	slices.SortFunc(tm.mappings, func(a, b typeMapping) int {
+		if c := cmp.Compare(len(b.pattern), len(a.pattern)); c != 0 {
+			return c
+		}
+		return cmp.Compare(a.pattern, b.pattern)
+	})
+	return tm, nil
+}
+
+// WithStdin returns a copy of m which maps the file "-", which stands for
+// standard input, to the file type qualifier, such as "yaml".
+func (m *TypeMap) WithStdin(qualifier string) (*TypeMap, error) {
+	sc, err := parseScope(qualifier)
+	if err != nil {
+		return nil, errors.Wrapf(err, token.NoPos, "invalid file type for standard input")
+	}
+	tm := &TypeMap{}
+	if m != nil {
+		*tm = *m
+	}
+	tm.stdin = &typeMapping{"-", qualifier, sc}
+	return tm, nil
+}
+
+// Lookup returns the file type qualifier for filename, and reports
+// whether there is one.
+func (m *TypeMap) Lookup(filename string) (string, bool) {
+	if tm := m.lookup(filename); tm != nil {
+		return tm.qualifier, true
+	}
+	return "", false
+}
+
+func (m *TypeMap) lookup(filename string) *typeMapping {
+	if m == nil {
+		return nil
+	}
+	if filename == "-" {
+		return m.stdin
+	}
+	base := filepath.Base(filename)
+	for i := range m.mappings {
+		tm := &m.mappings[i]
+		if ok, _ := path.Match(tm.pattern, base); ok {
+			return tm
+		}
+	}
+	return nil
+}
+
+// toFile is like the toFile function, but uses the scope mapped to
+// filename if sc is empty.
+func (m *TypeMap) toFile(mode Mode, sc *scope, filename string) (*build.File, error) {
+	if sc.isEmpty() {
+		if tm := m.lookup(filename); tm != nil {
+			f, err := toFile(mode, tm.scope, filename)
+			if err != nil {
+				return nil, errors.Wrapf(err, token.NoPos, "file type %q for %s", tm.qualifier, filename)
+			}
+			return f, nil
+		}
+	}
+	return toFile(mode, sc, filename)
+}
+
+// ParseArgs is like the [ParseArgs] function, but uses the file types
+// of m for files without a qualifier.
+func (m *TypeMap) ParseArgs(args []string) ([]*build.File, error) {
+	return parseArgs(m, args)
+}
+
+// ParseFile is like the [ParseFile] function, but uses the file types
+// of m for files without a qualifier.
+func (m *TypeMap) ParseFile(s string, mode Mode) (*build.File, error) {
+	return parseFile(m, s, mode)
+}
+
+// ParseFileAndType is like the [ParseFileAndType] function, but uses
+// the file types of m if scope is empty.
+func (m *TypeMap) ParseFileAndType(file, scope string, mode Mode) (*build.File, error) {
+	return parseFileAndType(m, file, scope, mode)
+}
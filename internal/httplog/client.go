@@ -3,10 +3,12 @@ package httplog
 import (
 	"bytes"
 	"context"
+	"fmt"
 	"io"
 	"maps"
 	"net/http"
 	"net/url"
+	"regexp"
 	"slices"
 	"strings"
 	"sync/atomic"
@@ -41,6 +43,45 @@ type TransportConfig struct {
 	// Use [RedactRequestBody] or [RedactResponseBody]
 	// to cause body data to be omitted entirely.
 	MaxBodySize int
+
+	// Level determines how much of each request and response is
+	// logged. The zero value logs everything.
+	Level Level
+
+	// Redact holds additional patterns to redact from the logged data.
+	// Any text matching one of them in URLs, header values, and bodies
+	// is replaced with "REDACTED".
+	Redact []*regexp.Regexp
+}
+
+// Level determines how much of each request and response is logged.
+type Level int
+
+const (
+	// LevelBodies logs the headers and bodies of requests and responses.
+	LevelBodies Level = iota
+
+	// LevelHeaders logs the headers of requests and responses,
+	// but not their bodies.
+	LevelHeaders
+
+	// LevelBasic only logs the method and URL of requests,
+	// and the status code of responses.
+	LevelBasic
+)
+
+// ParseLevel returns the level with the given name:
+// "bodies", "headers" or "basic".
+func ParseLevel(s string) (Level, error) {
+	switch s {
+	case "bodies":
+		return LevelBodies, nil
+	case "headers":
+		return LevelHeaders, nil
+	case "basic":
+		return LevelBasic, nil
+	}
+	return 0, fmt.Errorf("unknown HTTP log level %q; must be one of basic, headers, or bodies", s)
 }
 
 // Transport returns an [http.RoundTripper] implementation that
@@ -80,14 +121,15 @@ func (t *loggingTransport) RoundTrip(req *http.Request) (*http.Response, error)
 	} else {
 		reqURL = RedactedURL(ctx, req.URL).String()
 	}
-	t.cfg.Logger.Log(ctx, KindClientSendRequest, fromHTTPRequest(ctx, id, reqURL, req, true, t.cfg.MaxBodySize))
+	reqURL = t.redact(reqURL)
+	t.cfg.Logger.Log(ctx, KindClientSendRequest, t.fromHTTPRequest(ctx, id, reqURL, req, true))
 	resp, err := t.cfg.Transport.RoundTrip(req)
 	if err != nil {
 		t.cfg.Logger.Log(ctx, KindClientRecvResponse, &Response{
 			ID:     id,
 			Method: req.Method,
 			URL:    reqURL,
-			Error:  err.Error(),
+			Error:  t.redact(err.Error()),
 		})
 		return nil, err
 	}
@@ -95,27 +137,61 @@ func (t *loggingTransport) RoundTrip(req *http.Request) (*http.Response, error)
 		ID:         id,
 		Method:     req.Method,
 		URL:        reqURL,
-		Header:     resp.Header,
 		StatusCode: resp.StatusCode,
 	}
-	resp.Body = logResp.BodyData.init(ctx, resp.Body, true, false, t.cfg.MaxBodySize)
+	if t.cfg.Level <= LevelHeaders {
+		logResp.Header = t.redactHeader(resp.Header)
+	}
+	if t.cfg.Level == LevelBodies {
+		resp.Body = logResp.BodyData.init(ctx, resp.Body, true, false, t.cfg.MaxBodySize)
+		logResp.Body = t.redact(logResp.Body)
+	}
 	t.cfg.Logger.Log(ctx, KindClientRecvResponse, logResp)
 	return resp, nil
 }
 
-func fromHTTPRequest(ctx context.Context, id int64, reqURL string, req *http.Request, closeBody bool, maxBodySize int) *Request {
+func (t *loggingTransport) fromHTTPRequest(ctx context.Context, id int64, reqURL string, req *http.Request, closeBody bool) *Request {
 	logReq := &Request{
 		ID:            id,
 		URL:           reqURL,
 		Method:        req.Method,
-		Header:        redactAuthorization(req.Header),
 		ContentLength: req.ContentLength,
 	}
-	req.Body = logReq.BodyData.init(ctx, req.Body, closeBody, true, maxBodySize)
-
+	if t.cfg.Level <= LevelHeaders {
+		logReq.Header = t.redactHeader(redactAuthorization(req.Header))
+	}
+	if t.cfg.Level == LevelBodies {
+		req.Body = logReq.BodyData.init(ctx, req.Body, closeBody, true, t.cfg.MaxBodySize)
+		logReq.Body = t.redact(logReq.Body)
+	}
 	return logReq
 }
 
+// redact replaces the text in s which matches any of the patterns in
+// [TransportConfig.Redact].
+func (t *loggingTransport) redact(s string) string {
+	for _, re := range t.cfg.Redact {
+		s = re.ReplaceAllLiteralString(s, "REDACTED")
+	}
+	return s
+}
+
+// redactHeader applies [loggingTransport.redact] to the values of h.
+func (t *loggingTransport) redactHeader(h http.Header) http.Header {
+	if len(t.cfg.Redact) == 0 {
+		return h
+	}
+	h2 := make(http.Header, len(h))
+	for k, vs := range h {
+		vs = slices.Clone(vs)
+		for i, v := range vs {
+			vs[i] = t.redact(v)
+		}
+		h2[k] = vs
+	}
+	return h2
+}
+
 func redactAuthorization(h http.Header) http.Header {
 	auths, ok := h["Authorization"]
 	if !ok {
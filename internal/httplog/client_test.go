@@ -9,6 +9,7 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"net/url"
+	"regexp"
 	"strings"
 	"testing"
 
@@ -247,6 +248,121 @@ func TestOmitBody(t *testing.T) {
 	}))
 }
 
+func TestLevel(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		w.Write([]byte("response body"))
+	}))
+	for _, level := range []Level{LevelHeaders, LevelBasic} {
+		seq.Store(10)
+		var recorder logRecorder
+		client := &http.Client{
+			Transport: Transport(&TransportConfig{
+				Logger: &recorder,
+				Level:  level,
+			}),
+		}
+		req, err := http.NewRequest("PUT", srv.URL+"/foo/bar", strings.NewReader("request body"))
+		qt.Assert(t, qt.IsNil(err))
+		resp, err := client.Do(req)
+		qt.Assert(t, qt.IsNil(err))
+		data, err := io.ReadAll(resp.Body)
+		qt.Assert(t, qt.IsNil(err))
+		resp.Body.Close()
+		qt.Assert(t, qt.Equals(string(data), "response body"))
+
+		wantReq := &Request{
+			ID:            11,
+			Method:        "PUT",
+			ContentLength: 12,
+			URL:           "http://localhost/foo/bar",
+		}
+		wantResp := &Response{
+			ID:         11,
+			Method:     "PUT",
+			URL:        "http://localhost/foo/bar",
+			StatusCode: http.StatusOK,
+		}
+		if level == LevelHeaders {
+			wantReq.Header = http.Header{}
+			wantResp.Header = http.Header{
+				"Content-Length": {"13"},
+				"Content-Type":   {"text/plain; charset=utf-8"},
+				"Date":           {"now"},
+			}
+		}
+		qt.Assert(t, qt.DeepEquals(recorder.Events, []RequestOrResponse{wantReq, wantResp}), qt.Commentf("level %d", level))
+	}
+}
+
+func TestParseLevel(t *testing.T) {
+	for name, want := range map[string]Level{
+		"bodies":  LevelBodies,
+		"headers": LevelHeaders,
+		"basic":   LevelBasic,
+	} {
+		got, err := ParseLevel(name)
+		qt.Assert(t, qt.IsNil(err))
+		qt.Assert(t, qt.Equals(got, want))
+	}
+	_, err := ParseLevel("all")
+	qt.Assert(t, qt.ErrorMatches(err, `unknown HTTP log level "all"; must be one of basic, headers, or bodies`))
+}
+
+func TestRedactPatterns(t *testing.T) {
+	seq.Store(10)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		w.Header().Set("X-Session", "session-1234")
+		w.Write([]byte(`{"token":"secret-5678"}`))
+	}))
+
+	var recorder logRecorder
+	client := &http.Client{
+		Transport: Transport(&TransportConfig{
+			Logger: &recorder,
+			Redact: []*regexp.Regexp{
+				regexp.MustCompile(`(session|secret)-[0-9]+`),
+			},
+			IncludeAllQueryParams: true,
+		}),
+	}
+	req, err := http.NewRequest("POST", srv.URL+"/foo?key=secret-42", strings.NewReader("secret-99 and more"))
+	qt.Assert(t, qt.IsNil(err))
+	req.Header.Set("X-Session", "session-1234")
+	resp, err := client.Do(req)
+	qt.Assert(t, qt.IsNil(err))
+	resp.Body.Close()
+	qt.Assert(t, qt.Equals(req.Header.Get("X-Session"), "session-1234"))
+	qt.Assert(t, qt.DeepEquals(recorder.Events, []RequestOrResponse{
+		&Request{
+			ID:            11,
+			Method:        "POST",
+			ContentLength: 18,
+			URL:           "http://localhost/foo?key=REDACTED",
+			Header: http.Header{
+				"X-Session": {"REDACTED"},
+			},
+			BodyData: BodyData{
+				Body: "REDACTED and more",
+			},
+		},
+		&Response{
+			ID:         11,
+			Method:     "POST",
+			URL:        "http://localhost/foo?key=REDACTED",
+			StatusCode: http.StatusOK,
+			Header: http.Header{
+				"Content-Length": {"23"},
+				"Content-Type":   {"text/plain; charset=utf-8"},
+				"Date":           {"now"},
+				"X-Session":      {"REDACTED"},
+			},
+			BodyData: BodyData{
+				Body: `{"token":"REDACTED"}`,
+			},
+		},
+	}))
+}
+
 func TestLongBody(t *testing.T) {
 	seq.Store(10)
 	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
@@ -94,6 +94,28 @@ var ErrIncomplete = errors.New("incomplete value")
 // BaseContext is used as CUE's default context for arbitrary-precision decimals.
 var BaseContext = Context{*apd.BaseContext.WithPrecision(34)}
 
+// maxIntDigits is the number of digits of the largest integer returned by
+// ExactInt.
+const maxIntDigits = 1000
+
+// ExactInt returns the integer literal equal to the decimal number s, such
+// as "100" for "1e2" or "1.0". It reports false if s is not a finite
+// decimal number, or if its value is not exactly an integer.
+func ExactInt(s string) (string, bool) {
+	var d apd.Decimal
+	if _, _, err := d.SetString(s); err != nil || d.Form != apd.Finite {
+		return "", false
+	}
+	d.Reduce(&d)
+	switch {
+	case d.IsZero():
+		return "0", true
+	case d.Exponent < 0 || d.NumDigits()+int64(d.Exponent) > maxIntDigits:
+		return "", false
+	}
+	return d.Text('f'), true
+}
+
 // APIVersionSupported is the back version until which deprecated features
 // are still supported.
 var APIVersionSupported = Version(MinorSupported, PatchSupported)
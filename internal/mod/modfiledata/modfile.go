@@ -54,6 +54,7 @@ type File struct {
 	Language        *Language                 `json:"language,omitempty"`
 	Source          *Source                   `json:"source,omitempty"`
 	Deps            map[string]*Dep           `json:"deps,omitempty"`
+	Filetypes       map[string]string         `json:"filetypes,omitempty"`
 	Custom          map[string]map[string]any `json:"custom,omitempty"`
 	versions        []module.Version
 	versionByModule map[string]module.Version
@@ -152,11 +152,12 @@ func modfileFromRequirements(old *modfile.File, rs *modrequirements.Requirements
 	// want to just copy the entirety of old because that includes
 	// private fields too.
 	mf := &modfile.File{
-		Module:   old.Module,
-		Language: old.Language,
-		Deps:     make(map[string]*modfile.Dep),
-		Source:   old.Source,
-		Custom:   old.Custom,
+		Module:    old.Module,
+		Language:  old.Language,
+		Deps:      make(map[string]*modfile.Dep),
+		Source:    old.Source,
+		Filetypes: old.Filetypes,
+		Custom:    old.Custom,
 	}
 	defaults := rs.DefaultMajorVersions()
 	for _, v := range rs.RootModules() {
@@ -402,6 +402,37 @@ custom: "somewhere.com": foo: true
 	wantDefaults: map[string]string{
 		"foo.com/bar": "v0",
 	},
+}, {
+	testName: "Filetypes",
+	parse:    Parse,
+	data: `
+module: "foo.com/bar@v0"
+language: version: "v0.14.0"
+filetypes: {
+	"*.tmpl.yaml": "text"
+	"Tiltfile":    "text"
+}
+`,
+	want: &File{
+		Module:   "foo.com/bar@v0",
+		Language: &Language{Version: "v0.14.0"},
+		Filetypes: map[string]string{
+			"*.tmpl.yaml": "text",
+			"Tiltfile":    "text",
+		},
+	},
+	wantDefaults: map[string]string{
+		"foo.com/bar": "v0",
+	},
+}, {
+	testName: "WithEarlierVersionAndFiletypes",
+	parse:    Parse,
+	data: `
+module: "foo.com/bar@v0"
+language: version: "v0.13.0"
+filetypes: "*.tmpl.yaml": "text"
+`,
+	wantError: `invalid module file: filetypes field is not allowed at this language version; need at least v0.14.0`,
 }, {
 	testName: "FixLegacyWithModulePath",
 	parse:    FixLegacy,
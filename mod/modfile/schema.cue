@@ -51,6 +51,13 @@ versions: "v0.8.0-alpha.0": {
 }
 
 versions: "v0.9.0-alpha.0": {
+	versions["v0.14.0"]
+
+	// The filetypes field was added in v0.14.0.
+	#File: filetypes?: _errorFiletypesFieldRequiredVersion
+}
+
+versions: "v0.14.0": {
 	#File: {
 		// module indicates the module's path.
 		module?: #Module | ""
@@ -72,6 +79,14 @@ versions: "v0.9.0-alpha.0": {
 		// deps holds dependency information for modules, keyed by module path.
 		deps?: [#Module]: #Dep
 
+		// filetypes maps patterns matching the base names of files in
+		// the module to the file types of those files, given as file
+		// type qualifiers such as "yaml" or "json+jsonschema". It is used
+		// for files without an explicit qualifier instead of their file
+		// extension. Patterns use the syntax of Go's path.Match; if
+		// more than one matches a file, the longest is used.
+		filetypes?: [string]: string
+
 		// custom holds arbitrary data intended for use by third-party tools.
 		// Each field at the top level represents a tooling namespace,
 		// conventionally a module or domain name. Data migrated from legacy
@@ -145,3 +160,6 @@ versions: "v0.9.0-alpha.0": {
 
 //error: source field is not allowed at this language version; need at least v0.9.0-alpha.0
 let _errorSourceFieldRequiredVersion = 1 & 2
+
+//error: filetypes field is not allowed at this language version; need at least v0.14.0
+let _errorFiletypesFieldRequiredVersion = 1 & 2
@@ -0,0 +1,256 @@
+// Copyright 2025 The CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package compat classifies the changes between two versions of a schema
+// by whether they are compatible with data written for the other version.
+//
+// A new version of a schema is backward compatible if it accepts all the
+// data the old version accepts, so that existing data remains valid. It is
+// forward compatible if the old version accepts all the data the new
+// version accepts, so that consumers which still use the old version can
+// read data written for the new one. A change which is neither is breaking.
+package compat
+
+import (
+	"fmt"
+	"slices"
+
+	"cuelang.org/go/cue"
+)
+
+// Compat describes the compatibility of a new version of a schema with an
+// old one.
+type Compat int
+
+const (
+	// Breaking means that the versions accept different data.
+	Breaking Compat = 0
+
+	// Backward means that the new version accepts all the data the old
+	// version accepts.
+	Backward Compat = 1
+
+	// Forward means that the old version accepts all the data the new
+	// version accepts.
+	Forward Compat = 2
+
+	// Full means that both versions accept the same data.
+	Full = Backward | Forward
+)
+
+func (c Compat) String() string {
+	switch c {
+	case Backward:
+		return "backward compatible"
+	case Forward:
+		return "forward compatible"
+	case Full:
+		return "fully compatible"
+	}
+	return "breaking"
+}
+
+// Config configures Check.
+type Config struct {
+	// API compares the versions as APIs, as Value.Subsume does with the
+	// cue.Schema option: the closedness of structs is ignored, so that
+	// adding fields to a closed struct is compatible.
+	API bool
+}
+
+// A Change is a difference between two versions of a schema.
+type Change struct {
+	// Path holds the path of the changed field.
+	Path cue.Path
+
+	// Kind describes the change, such as "added optional field".
+	Kind string
+
+	// Old and New hold the values of the field in the old and new
+	// versions, or the zero Value if the field does not exist.
+	Old, New cue.Value
+
+	// Compat holds the compatibility of the change.
+	Compat Compat
+}
+
+// Describe returns a short description of a change for reports, such as
+// "added required field" or "changed from int to string".
+func (ch Change) Describe() string {
+	if ch.Kind != "changed" {
+		return ch.Kind
+	}
+	if ch.Old.IncompleteKind() == cue.StructKind && ch.New.IncompleteKind() == cue.StructKind {
+		return "changed struct"
+	}
+	return fmt.Sprintf("changed from %v to %v", ch.Old, ch.New)
+}
+
+// A Report holds the result of Check.
+type Report struct {
+	// Compat holds the compatibility of the new version as a whole.
+	Compat Compat
+
+	// Changes holds the changes which are not fully compatible, in the
+	// order of the fields of the versions.
+	Changes []Change
+}
+
+// Check compares the old and new versions of a schema. Both values must
+// have been built with the same cue.Context.
+func Check(old, new cue.Value, cfg *Config) *Report {
+	if cfg == nil {
+		cfg = &Config{}
+	}
+	c := &checker{cfg: cfg, top: old.Context().CompileString("_")}
+	compat := c.compare(nil, old, new)
+	return &Report{Compat: compat, Changes: c.changes}
+}
+
+type checker struct {
+	cfg     *Config
+	top     cue.Value
+	changes []Change
+}
+
+// subsumes reports the compatibility of new with old as a whole.
+func (c *checker) subsumes(old, new cue.Value) Compat {
+	var opts []cue.Option
+	if c.cfg.API {
+		opts = append(opts, cue.Schema())
+	}
+	// Whether fields are optional is compared separately, so their
+	// values are compared as regular fields.
+	old, new = c.top.Unify(old), c.top.Unify(new)
+	compat := Breaking
+	if new.Subsume(old, opts...) == nil {
+		compat |= Backward
+	}
+	if old.Subsume(new, opts...) == nil {
+		compat |= Forward
+	}
+	return compat
+}
+
+// compare records the changes between old and new at path and reports
+// their compatibility. The changes of structs are those of their fields,
+// unless only the structs themselves differ, such as in closedness.
+func (c *checker) compare(path []cue.Selector, old, new cue.Value) Compat {
+	if old.IncompleteKind() != cue.StructKind || new.IncompleteKind() != cue.StructKind {
+		compat := c.subsumes(old, new)
+		c.add(path, "changed", old, new, compat)
+		return compat
+	}
+
+	n := len(c.changes)
+	fieldCompat := Full
+	newFields := fields(new)
+	for _, f := range fields(old) {
+		p := append(slices.Clip(path), cue.Str(f.name))
+		i := slices.IndexFunc(newFields, func(nf field) bool { return nf.name == f.name })
+		if i < 0 {
+			// Data without the field remains valid if it was optional,
+			// and data with it remains valid if the new version allows it.
+			compat := Breaking
+			if c.allows(new, f.name) {
+				compat |= Backward
+			}
+			if f.optional {
+				compat |= Forward
+			}
+			c.add(p, "removed "+f.kind(), f.value, cue.Value{}, compat)
+			fieldCompat &= compat
+			continue
+		}
+		nf := newFields[i]
+		newFields = slices.Delete(newFields, i, i+1)
+		compat := c.compare(p, f.value, nf.value)
+		if f.optional != nf.optional {
+			if nf.optional {
+				compat &^= Forward
+			} else {
+				compat &^= Backward
+			}
+			c.add(p, "made "+nf.kind(), f.value, nf.value, compat)
+		}
+		fieldCompat &= compat
+	}
+	for _, nf := range newFields {
+		p := append(slices.Clip(path), cue.Str(nf.name))
+		compat := Breaking
+		if nf.optional {
+			compat |= Backward
+		}
+		if c.allows(old, nf.name) {
+			compat |= Forward
+		}
+		c.add(p, "added "+nf.kind(), cue.Value{}, nf.value, compat)
+		fieldCompat &= compat
+	}
+	if len(c.changes) > n {
+		return fieldCompat
+	}
+	compat := c.subsumes(old, new)
+	c.add(path, "changed", old, new, compat)
+	return compat
+}
+
+// allows reports whether data for v may have a field with the given name.
+func (c *checker) allows(v cue.Value, name string) bool {
+	return c.cfg.API || v.Allows(cue.Str(name))
+}
+
+func (c *checker) add(path []cue.Selector, kind string, old, new cue.Value, compat Compat) {
+	if compat == Full {
+		return
+	}
+	c.changes = append(c.changes, Change{
+		Path:   cue.MakePath(path...),
+		Kind:   kind,
+		Old:    old,
+		New:    new,
+		Compat: compat,
+	})
+}
+
+type field struct {
+	name     string
+	optional bool
+	value    cue.Value
+}
+
+func (f field) kind() string {
+	if f.optional {
+		return "optional field"
+	}
+	return "required field"
+}
+
+// fields returns the regular, optional, and required fields of v.
+func fields(v cue.Value) []field {
+	iter, err := v.Fields(cue.Optional(true))
+	if err != nil {
+		return nil
+	}
+	var fields []field
+	for iter.Next() {
+		sel := iter.Selector()
+		fields = append(fields, field{
+			name:     sel.Unquoted(),
+			optional: sel.ConstraintType() == cue.OptionalConstraint,
+			value:    iter.Value(),
+		})
+	}
+	return fields
+}
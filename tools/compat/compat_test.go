@@ -0,0 +1,113 @@
+// Copyright 2025 The CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package compat_test
+
+import (
+	"testing"
+
+	"github.com/go-quicktest/qt"
+
+	"cuelang.org/go/cue"
+	"cuelang.org/go/cue/cuecontext"
+	"cuelang.org/go/tools/compat"
+)
+
+func TestCheck(t *testing.T) {
+	testCases := []struct {
+		name     string
+		old, new string
+		api      bool
+		compat   compat.Compat
+		changes  []string
+	}{{
+		name:   "Unchanged",
+		old:    `#A: {name: string, port?: int}`,
+		new:    `#A: {name: string, port?: int}`,
+		compat: compat.Full,
+	}, {
+		name:   "Widened",
+		old:    `#A: {name: string, port: int & <100}`,
+		new:    `#A: {name: string, port: int | string}`,
+		compat: compat.Backward,
+		changes: []string{
+			`port: changed from int & <100 to int | string: backward compatible`,
+		},
+	}, {
+		name:   "Narrowed",
+		old:    `#A: {name: string}`,
+		new:    `#A: {name: =~"^[a-z]+$"}`,
+		compat: compat.Forward,
+		changes: []string{
+			`name: changed from string to =~"^[a-z]+$": forward compatible`,
+		},
+	}, {
+		name:   "AddedOptional",
+		old:    `#A: {name: string}`,
+		new:    `#A: {name: string, port?: int}`,
+		compat: compat.Backward,
+		changes: []string{
+			`port: added optional field: backward compatible`,
+		},
+	}, {
+		name:   "AddedRequired",
+		old:    `#A: {name: string, ...}`,
+		new:    `#A: {name: string, port!: int, ...}`,
+		compat: compat.Forward,
+		changes: []string{
+			`port: added required field: forward compatible`,
+		},
+	}, {
+		name:   "Breaking",
+		old:    `#A: {name: string, sub: {a: int, b?: string}}`,
+		new:    `#A: {name: int, sub: {a: int, c: string}}`,
+		compat: compat.Breaking,
+		changes: []string{
+			`name: changed from string to int: breaking`,
+			`sub.b: removed optional field: forward compatible`,
+			`sub.c: added required field: breaking`,
+		},
+	}, {
+		name:   "MadeOptional",
+		old:    `#A: {name: string}`,
+		new:    `#A: {name?: string}`,
+		compat: compat.Backward,
+		changes: []string{
+			`name: made optional field: backward compatible`,
+		},
+	}, {
+		name:   "APIAddedOptional",
+		old:    `#A: {name: string}`,
+		new:    `#A: {name: string, port?: int}`,
+		api:    true,
+		compat: compat.Full,
+	}}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx := cuecontext.New()
+			old := ctx.CompileString(tc.old).LookupPath(cue.ParsePath("#A"))
+			new := ctx.CompileString(tc.new).LookupPath(cue.ParsePath("#A"))
+			qt.Assert(t, qt.IsNil(old.Err()))
+			qt.Assert(t, qt.IsNil(new.Err()))
+
+			r := compat.Check(old, new, &compat.Config{API: tc.api})
+			var changes []string
+			for _, ch := range r.Changes {
+				changes = append(changes, ch.Path.String()+": "+ch.Describe()+": "+ch.Compat.String())
+			}
+			qt.Check(t, qt.DeepEquals(changes, tc.changes))
+			qt.Check(t, qt.Equals(r.Compat, tc.compat))
+		})
+	}
+}
@@ -0,0 +1,309 @@
+// Copyright 2025 CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package doc extracts the documentation of CUE values as structured
+// records, ready to be rendered by documentation tools.
+//
+// A record is produced for each regular field and definition, including
+// optional and required fields. Records are produced for the fields of
+// nested structs, except for fields whose value refers to another
+// definition: these are documented where the definition is declared.
+//
+// The type, constraints, and defaults of a field are derived from the
+// expressions with which the field is declared, rather than from its
+// evaluated value, so that they read as they were written.
+package doc
+
+import (
+	"strings"
+
+	"cuelang.org/go/cue"
+	"cuelang.org/go/cue/ast"
+	"cuelang.org/go/cue/build"
+	"cuelang.org/go/cue/format"
+	"cuelang.org/go/cue/token"
+)
+
+// A Field holds the documentation of a field.
+type Field struct {
+	// Path is the path of the field, relative to the extracted value.
+	Path cue.Path
+
+	// Doc is the text of the doc comments of the field.
+	Doc string
+
+	// Type describes the type of the field: the definition it refers
+	// to, such as #Port, or the kind of its values, such as int or
+	// string.
+	Type string
+
+	// Constraints holds the constraints on the values of the field
+	// beyond its type, such as >=1 or =~"^[a-z]+$".
+	Constraints []string
+
+	// Default is the default value of the field, or "" if it has none.
+	Default string
+
+	// Optional and Required report whether the field is declared with
+	// a ? or a ! marker.
+	Optional bool
+	Required bool
+
+	// Definition reports whether the field is a definition.
+	Definition bool
+
+	// Attributes holds the field attributes of the field.
+	Attributes []Attribute
+}
+
+// An Attribute is an attribute of a field, such as @go(Port).
+type Attribute struct {
+	Name     string
+	Contents string
+}
+
+// Extract returns the documentation of the fields of v, recursively, in
+// the order in which they are declared.
+func Extract(v cue.Value) ([]Field, error) {
+	if err := v.Err(); err != nil {
+		return nil, err
+	}
+	var fields []Field
+	if err := extract(&fields, v, nil); err != nil {
+		return nil, err
+	}
+	return fields, nil
+}
+
+// Instance builds inst in ctx and returns the documentation of the
+// fields of the resulting package.
+func Instance(ctx *cue.Context, inst *build.Instance) ([]Field, error) {
+	return Extract(ctx.BuildInstance(inst))
+}
+
+func extract(fields *[]Field, v cue.Value, path []cue.Selector) error {
+	iter, err := v.Fields(cue.Definitions(true), cue.Optional(true))
+	if err != nil {
+		return err
+	}
+	for iter.Next() {
+		sel := iter.Selector()
+		fv := iter.Value()
+		p := append(path[:len(path):len(path)], label(sel))
+		f := Field{
+			Path:       cue.MakePath(p...),
+			Doc:        docText(fv.Doc()),
+			Optional:   sel.ConstraintType() == cue.OptionalConstraint,
+			Required:   sel.ConstraintType() == cue.RequiredConstraint,
+			Definition: sel.IsDefinition(),
+		}
+		for _, a := range fv.Attributes(cue.FieldAttr) {
+			f.Attributes = append(f.Attributes, Attribute{
+				Name:     a.Name(),
+				Contents: a.Contents(),
+			})
+		}
+		var d describer
+		d.conjunct(fv.Syntax(cue.Raw()))
+		f.Type = d.typ(fv)
+		f.Constraints = d.constraints
+		f.Default = strings.Join(d.defaults, " | ")
+		*fields = append(*fields, f)
+
+		if d.ref == "" && fv.IncompleteKind() == cue.StructKind {
+			if err := extract(fields, fv, p); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// label returns sel without its optional or required marker.
+func label(sel cue.Selector) cue.Selector {
+	switch {
+	case sel.ConstraintType() == 0:
+		return sel
+	case sel.IsDefinition():
+		return cue.Def(sel.Unquoted())
+	}
+	return cue.Str(sel.Unquoted())
+}
+
+func docText(docs []*ast.CommentGroup) string {
+	var texts []string
+	for _, c := range docs {
+		texts = append(texts, strings.TrimSpace(c.Text()))
+	}
+	return strings.Join(texts, "\n\n")
+}
+
+// A describer classifies the conjuncts of the expression declaring a
+// field.
+type describer struct {
+	ref         string
+	basic       []string
+	list        string
+	constraints []string
+	defaults    []string
+}
+
+// basicTypes holds the identifiers of the predeclared types.
+var basicTypes = map[string]bool{
+	"_":      true,
+	"null":   true,
+	"bool":   true,
+	"int":    true,
+	"float":  true,
+	"number": true,
+	"string": true,
+	"bytes":  true,
+}
+
+func (d *describer) conjunct(n ast.Node) {
+	switch x := n.(type) {
+	case *ast.File:
+		// Expressions which refer to imported packages are represented
+		// as files which import them.
+		var decls []ast.Decl
+		for _, decl := range x.Decls {
+			switch decl.(type) {
+			case *ast.Package, *ast.ImportDecl:
+			default:
+				decls = append(decls, decl)
+			}
+		}
+		if len(decls) == 1 {
+			if e, ok := decls[0].(*ast.EmbedDecl); ok {
+				d.conjunct(e.Expr)
+				return
+			}
+		}
+		d.conjunct(&ast.StructLit{Elts: decls})
+		return
+
+	case *ast.ParenExpr:
+		d.conjunct(x.X)
+		return
+
+	case *ast.BinaryExpr:
+		switch x.Op {
+		case token.AND:
+			d.conjunct(x.X)
+			d.conjunct(x.Y)
+			return
+		case token.OR:
+			d.disjunction(x)
+			return
+		}
+
+	case *ast.StructLit:
+		// Several conjuncts of a field are represented as embeddings of
+		// a struct. Other structs determine the type of the field.
+		var embeds []ast.Expr
+		for _, e := range x.Elts {
+			e, ok := e.(*ast.EmbedDecl)
+			if !ok {
+				return
+			}
+			embeds = append(embeds, e.Expr)
+		}
+		for _, e := range embeds {
+			d.conjunct(e)
+		}
+		return
+
+	case *ast.Ident:
+		if basicTypes[x.Name] {
+			d.basic = append(d.basic, x.Name)
+			return
+		}
+		if d.ref == "" {
+			d.ref = x.Name
+		}
+		return
+
+	case *ast.SelectorExpr:
+		if d.ref == "" {
+			d.ref = formatNode(x)
+		}
+		return
+
+	case *ast.ListLit:
+		if len(x.Elts) == 1 {
+			if _, ok := x.Elts[0].(*ast.Ellipsis); ok {
+				d.list = formatNode(x)
+				return
+			}
+		}
+	}
+	d.constraints = append(d.constraints, formatNode(n))
+}
+
+// disjunction records the defaults of a disjunction and the constraint
+// imposed by its other disjuncts.
+func (d *describer) disjunction(x *ast.BinaryExpr) {
+	var disjuncts []ast.Expr
+	var collect func(e ast.Expr)
+	collect = func(e ast.Expr) {
+		if b, ok := e.(*ast.BinaryExpr); ok && b.Op == token.OR {
+			collect(b.X)
+			collect(b.Y)
+			return
+		}
+		if u, ok := e.(*ast.UnaryExpr); ok && u.Op == token.MUL {
+			d.defaults = append(d.defaults, formatNode(u.X))
+			return
+		}
+		disjuncts = append(disjuncts, e)
+	}
+	collect(x)
+
+	switch len(disjuncts) {
+	case 0:
+	case 1:
+		d.conjunct(disjuncts[0])
+	default:
+		parts := make([]string, len(disjuncts))
+		for i, e := range disjuncts {
+			parts[i] = formatNode(e)
+		}
+		d.constraints = append(d.constraints, strings.Join(parts, " | "))
+	}
+}
+
+// typ returns the type of a field with value v.
+func (d *describer) typ(v cue.Value) string {
+	switch {
+	case d.ref != "":
+		return d.ref
+	case d.list != "":
+		return d.list
+	case len(d.basic) > 0 && d.basic[0] != "_":
+		return d.basic[0]
+	}
+	k := v.IncompleteKind()
+	if k == cue.TopKind {
+		return "_"
+	}
+	return k.String()
+}
+
+func formatNode(n ast.Node) string {
+	b, err := format.Node(n)
+	if err != nil {
+		return ""
+	}
+	return string(b)
+}
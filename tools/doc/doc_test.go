@@ -0,0 +1,91 @@
+// Copyright 2025 CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package doc_test
+
+import (
+	"testing"
+
+	"github.com/go-quicktest/qt"
+	"github.com/google/go-cmp/cmp/cmpopts"
+
+	"cuelang.org/go/cue/cuecontext"
+	"cuelang.org/go/tools/doc"
+)
+
+func TestExtract(t *testing.T) {
+	ctx := cuecontext.New()
+	v := ctx.CompileString(`
+// #Server configures a server.
+#Server: {
+	// port is the port to listen on.
+	port: int & >=1 & <=65535 | *8080 @go(Port)
+	port: <10000
+
+	host?: string @json(,omitempty)
+	mode!: "dev" | "prod" | *"dev"
+	tags: [...string]
+	tls: {
+		cert: string
+	}
+}
+
+server: #Server & {host: "localhost"}
+`)
+	fields, err := doc.Extract(v)
+	qt.Assert(t, qt.IsNil(err))
+
+	var paths []string
+	for _, f := range fields {
+		paths = append(paths, f.Path.String())
+	}
+	qt.Assert(t, qt.DeepEquals(paths, []string{
+		"#Server",
+		"#Server.port",
+		"#Server.host",
+		"#Server.mode",
+		"#Server.tags",
+		"#Server.tls",
+		"#Server.tls.cert",
+		"server",
+	}))
+	qt.Assert(t, qt.CmpEquals(fields, []doc.Field{{
+		Doc:        "#Server configures a server.",
+		Type:       "struct",
+		Definition: true,
+	}, {
+		Doc:         "port is the port to listen on.",
+		Type:        "int",
+		Constraints: []string{">=1", "<=65535", "<10000"},
+		Default:     "8080",
+		Attributes:  []doc.Attribute{{Name: "go", Contents: "Port"}},
+	}, {
+		Type:       "string",
+		Optional:   true,
+		Attributes: []doc.Attribute{{Name: "json", Contents: ",omitempty"}},
+	}, {
+		Type:        "string",
+		Constraints: []string{`"dev" | "prod"`},
+		Default:     `"dev"`,
+		Required:    true,
+	}, {
+		Type: "[...string]",
+	}, {
+		Type: "struct",
+	}, {
+		Type: "string",
+	}, {
+		Type: "#Server",
+	}}, cmpopts.IgnoreFields(doc.Field{}, "Path")))
+}
@@ -22,6 +22,7 @@ package fix
 import (
 	"cuelang.org/go/cue/ast"
 	"cuelang.org/go/cue/ast/astutil"
+	"cuelang.org/go/cue/errors"
 	"cuelang.org/go/cue/token"
 )
 
@@ -29,6 +30,7 @@ type Option func(*options)
 
 type options struct {
 	simplify bool
+	rules    []Rule
 }
 
 // Simplify enables fixes that simplify the code, but are not strictly
@@ -38,7 +40,16 @@ func Simplify() Option {
 }
 
 // File applies fixes to f and returns it. It alters the original f.
+//
+// Rules which fail leave f unchanged; use [Instances] to report their
+// errors.
 func File(f *ast.File, o ...Option) *ast.File {
+	f, _ = file(f, o...)
+	return f
+}
+
+// file implements [File], returning the errors of the rules.
+func file(f *ast.File, o ...Option) (*ast.File, errors.Error) {
 	var options options
 	for _, f := range o {
 		f(&options)
@@ -107,6 +118,8 @@ func File(f *ast.File, o ...Option) *ast.File {
 		return true
 	}).(*ast.File)
 
+	errs := applyRules(f, &options)
+
 	if options.simplify {
 		f = simplify(f)
 	}
@@ -121,7 +134,7 @@ func File(f *ast.File, o ...Option) *ast.File {
 	if err != nil {
 		panic(err)
 	}
-	return f
+	return f, errs
 }
 
 func expandConcats(exprs ...ast.Expr) (result []ast.Expr) {
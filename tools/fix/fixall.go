@@ -34,7 +34,10 @@ func Instances(a []*build.Instance, o ...Option) errors.Error {
 		cwd:       cwd,
 	}
 
-	p.visitAll(func(f *ast.File) { File(f, o...) })
+	p.visitAll(func(f *ast.File) {
+		_, err := file(f, o...)
+		p.err = errors.Append(p.err, err)
+	})
 
 	return p.err
 }
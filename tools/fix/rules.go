@@ -0,0 +1,325 @@
+// Copyright 2025 CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fix
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+
+	"cuelang.org/go/cue"
+	"cuelang.org/go/cue/ast"
+	"cuelang.org/go/cue/errors"
+	"cuelang.org/go/cue/token"
+)
+
+// A Rule is a user-defined rewrite which is applied by [File] and
+// [Instances] in addition to the built-in fixes. Rules allow
+// organizations to ship their own schema migrations, such as renaming
+// deprecated fields, using the same machinery as cue fix.
+type Rule struct {
+	// Name identifies the rule in error messages.
+	Name string
+
+	// Fix rewrites f in place. If it cannot apply the rule to f, it
+	// returns an error and leaves f unchanged.
+	Fix func(f *ast.File) error
+}
+
+var (
+	registryMu sync.Mutex
+	registry   []Rule
+)
+
+// Register registers a rule which is applied by all subsequent calls
+// to [File] and [Instances], after the built-in fixes. It is intended
+// to be called from the init function of a Go package which is linked
+// into a program using this package, such as a custom build of the
+// cue command.
+//
+// Register panics if a rule with the same name was already registered.
+func Register(r Rule) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	if slices.ContainsFunc(registry, func(r1 Rule) bool { return r1.Name == r.Name }) {
+		panic(fmt.Sprintf("fix: rule %q registered twice", r.Name))
+	}
+	registry = append(registry, r)
+}
+
+func registeredRules() []Rule {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	return slices.Clone(registry)
+}
+
+// Rules adds the given rules to those which are applied, after the
+// built-in and registered fixes.
+func Rules(rules ...Rule) Option {
+	return func(o *options) { o.rules = append(o.rules, rules...) }
+}
+
+// ruleSpec defines the CUE representation of a single rule,
+// as decoded by [ParseRules].
+type ruleSpec struct {
+	Name            string      `json:"name"`
+	RenameField     *renameSpec `json:"renameField,omitempty"`
+	RenameAttribute *renameSpec `json:"renameAttribute,omitempty"`
+}
+
+type renameSpec struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+}
+
+// ParseRules decodes the rules declared in the rules field of v.
+// Each rule has a name and exactly one action:
+//
+//	rules: [{
+//		name: "replicas"
+//		// Rename the field replicaCount to replicas wherever
+//		// it appears directly within a field labeled #Deployment.
+//		renameField: {from: "#Deployment.replicaCount", to: "replicas"}
+//	}, {
+//		name: "go-attr"
+//		// Rename @golang(...) attributes to @go(...).
+//		renameAttribute: {from: "golang", to: "go"}
+//	}]
+//
+// The from path of renameField is a dot-separated list of labels. The
+// last label names the field to rename; any preceding labels must match
+// the labels of the directly enclosing fields. Note that the matching is
+// purely syntactic: fields which are defined by unifying with a
+// definition elsewhere are not affected.
+//
+// References to a renamed field are updated as well, where they can be
+// resolved syntactically: identifiers referring to the field, selectors
+// of the field on a reference to its enclosing struct, such as
+// #Deployment.replicaCount, and, for a path of a single label,
+// identifiers referring to a field of another file of the package.
+// Renaming a referenced field to a name which is not a valid identifier
+// fails, as the references could not refer to it anymore.
+func ParseRules(v cue.Value) ([]Rule, error) {
+	var specs []ruleSpec
+	if err := v.LookupPath(cue.MakePath(cue.Str("rules"))).Decode(&specs); err != nil {
+		return nil, err
+	}
+	rules := make([]Rule, 0, len(specs))
+	for i, spec := range specs {
+		r, err := spec.rule()
+		if err != nil {
+			name := spec.Name
+			if name == "" {
+				name = fmt.Sprintf("#%d", i)
+			}
+			return nil, errors.Wrapf(err, v.Pos(), "invalid rule %s", name)
+		}
+		rules = append(rules, r)
+	}
+	return rules, nil
+}
+
+func (spec *ruleSpec) rule() (Rule, error) {
+	var fix func(f *ast.File) error
+	n := 0
+	if r := spec.RenameField; r != nil {
+		n++
+		if r.From == "" || r.To == "" {
+			return Rule{}, fmt.Errorf("renameField requires non-empty from and to")
+		}
+		fix = func(f *ast.File) error { return renameField(f, strings.Split(r.From, "."), r.To) }
+	}
+	if r := spec.RenameAttribute; r != nil {
+		n++
+		if !ast.IsValidIdent(r.From) || !ast.IsValidIdent(r.To) {
+			return Rule{}, fmt.Errorf("renameAttribute requires valid attribute names")
+		}
+		fix = func(f *ast.File) error {
+			renameAttribute(f, r.From, r.To)
+			return nil
+		}
+	}
+	if n != 1 {
+		return Rule{}, fmt.Errorf("rule must specify exactly one action")
+	}
+	return Rule{Name: spec.Name, Fix: fix}, nil
+}
+
+// renameField renames all fields whose label matches the last element
+// of path and whose enclosing field labels match the remainder of path,
+// along with the references to them.
+func renameField(f *ast.File, path []string, to string) error {
+	from := path[len(path)-1]
+
+	// renamed maps the values of the fields to rename to their fields, as
+	// the parser resolves references to fields to their values.
+	renamed := map[ast.Node]*ast.Field{}
+	var stack []string
+	ast.Walk(f, func(n ast.Node) bool {
+		field, ok := n.(*ast.Field)
+		if !ok {
+			return true
+		}
+		name, _ := fieldName(field)
+		stack = append(stack, name)
+		if len(stack) >= len(path) && slices.Equal(stack[len(stack)-len(path):], path) {
+			renamed[field.Value] = field
+		}
+		return true
+	}, func(n ast.Node) {
+		if _, ok := n.(*ast.Field); ok {
+			stack = stack[:len(stack)-1]
+		}
+	})
+	if len(renamed) == 0 {
+		return nil
+	}
+
+	var refs []*ast.Ident
+	ast.Walk(f, func(n ast.Node) bool {
+		switch x := n.(type) {
+		case *ast.Ident:
+			if x.Name == from && renamed[x.Node] != nil {
+				refs = append(refs, x)
+			}
+		case *ast.SelectorExpr:
+			sel, ok := x.Sel.(*ast.Ident)
+			if !ok || sel.Name != from {
+				break
+			}
+			if s, ok := resolveValue(x.X).(*ast.StructLit); ok {
+				if field := lookupField(s, from); field != nil && renamed[field.Value] != nil {
+					refs = append(refs, sel)
+				}
+			}
+		}
+		return true
+	}, nil)
+	if len(path) == 1 {
+		// Unresolved identifiers may refer to fields of other files of
+		// the package, which the rule renames as well.
+		for _, ident := range f.Unresolved {
+			if ident.Name == from {
+				refs = append(refs, ident)
+			}
+		}
+	}
+
+	if !ast.IsValidIdent(to) && len(refs) > 0 {
+		return errors.Newf(refs[0].Pos(), "cannot rename referenced field %s to %q, which is not a valid identifier", from, to)
+	}
+	for _, field := range renamed {
+		label := field.Label
+		alias, _ := label.(*ast.Alias)
+		if alias != nil {
+			label, _ = alias.Expr.(ast.Label)
+		}
+		var newLabel interface {
+			ast.Label
+			ast.Expr
+		}
+		if ast.IsValidIdent(to) {
+			newLabel = ast.NewIdent(to)
+		} else {
+			newLabel = ast.NewString(to)
+		}
+		ast.SetPos(newLabel, label.Pos())
+		if alias != nil {
+			alias.Expr = newLabel
+		} else {
+			field.Label = newLabel
+		}
+	}
+	for _, ident := range refs {
+		ident.Name = to
+	}
+	return nil
+}
+
+// fieldName returns the name of the label of field, looking through
+// an alias.
+func fieldName(field *ast.Field) (string, bool) {
+	label := field.Label
+	if alias, ok := label.(*ast.Alias); ok {
+		label, _ = alias.Expr.(ast.Label)
+	}
+	name, _, err := ast.LabelName(label)
+	return name, err == nil
+}
+
+// resolveValue returns the value which the reference x refers to, if it
+// can be resolved syntactically, or nil otherwise.
+func resolveValue(x ast.Expr) ast.Node {
+	switch x := x.(type) {
+	case *ast.Ident:
+		if field, ok := x.Node.(*ast.Field); ok {
+			// x refers to an alias of the field.
+			return field.Value
+		}
+		return x.Node
+	case *ast.SelectorExpr:
+		s, ok := resolveValue(x.X).(*ast.StructLit)
+		if !ok {
+			return nil
+		}
+		name, _, err := ast.LabelName(x.Sel)
+		if err != nil {
+			return nil
+		}
+		if field := lookupField(s, name); field != nil {
+			return field.Value
+		}
+	}
+	return nil
+}
+
+// lookupField returns the field of s with the given name, or nil if
+// there is none.
+func lookupField(s *ast.StructLit, name string) *ast.Field {
+	for _, d := range s.Elts {
+		if field, ok := d.(*ast.Field); ok {
+			if n, ok := fieldName(field); ok && n == name {
+				return field
+			}
+		}
+	}
+	return nil
+}
+
+// renameAttribute renames all attributes with key from to have key to.
+func renameAttribute(f *ast.File, from, to string) {
+	ast.Walk(f, func(n ast.Node) bool {
+		a, ok := n.(*ast.Attribute)
+		if !ok {
+			return true
+		}
+		if key, body := a.Split(); key == from {
+			a.Text = "@" + to + "(" + body + ")"
+		}
+		return true
+	}, nil)
+}
+
+// applyRules applies the registered rules followed by the rules in o.
+func applyRules(f *ast.File, o *options) errors.Error {
+	var errs errors.Error
+	for _, r := range slices.Concat(registeredRules(), o.rules) {
+		if err := r.Fix(f); err != nil {
+			errs = errors.Append(errs, errors.Wrapf(err, token.NoPos, "rule %s", r.Name))
+		}
+	}
+	return errs
+}
@@ -0,0 +1,197 @@
+// Copyright 2025 CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fix
+
+import (
+	"testing"
+
+	"github.com/go-quicktest/qt"
+
+	"cuelang.org/go/cue/ast"
+	"cuelang.org/go/cue/build"
+	"cuelang.org/go/cue/cuecontext"
+	"cuelang.org/go/cue/format"
+	"cuelang.org/go/cue/parser"
+)
+
+func TestRules(t *testing.T) {
+	testCases := []struct {
+		name  string
+		rules string
+		in    string
+		out   string
+		err   string
+	}{{
+		name: "rename field",
+		rules: `rules: [{
+	name: "replicas"
+	renameField: {from: "#Deployment.replicaCount", to: "replicas"}
+}]`,
+		in: `#Deployment: {
+	replicaCount: int
+	other: replicaCount: int
+}
+replicaCount: 1
+`,
+		out: `#Deployment: {
+	replicas: int
+	other: replicaCount: int
+}
+replicaCount: 1
+`,
+	}, {
+		name: "rename references",
+		rules: `rules: [{
+	name: "replicas"
+	renameField: {from: "#Deployment.replicaCount", to: "replicas"}
+}]`,
+		in: `D=#Deployment: {
+	replicaCount: int
+	max:          replicaCount * 2
+	other: replicaCount: int
+	min: other.replicaCount
+}
+a: #Deployment.replicaCount
+b: D.replicaCount
+c: #Deployment.other.replicaCount
+d: #Deployment & {replicaCount: 3}
+`,
+		out: `D=#Deployment: {
+	replicas: int
+	max:      replicas * 2
+	other: replicaCount: int
+	min: other.replicaCount
+}
+a: #Deployment.replicas
+b: D.replicas
+c: #Deployment.other.replicaCount
+d: #Deployment & {replicaCount: 3}
+`,
+	}, {
+		name: "rename references of other files",
+		rules: `rules: [{
+	name: "port"
+	renameField: {from: "port", to: "listenPort"}
+}]`,
+		in: `// port is declared in another file.
+a: port
+b: {port: 1, c: port}.c
+`,
+		out: `// port is declared in another file.
+a: listenPort
+b: {listenPort: 1, c: listenPort}.c
+`,
+	}, {
+		name: "rename referenced field to non-identifier",
+		rules: `rules: [{
+	name: "old"
+	renameField: {from: "old", to: "new-name"}
+}]`,
+		in: `old: 1
+a:   old
+`,
+		err: `rule old: cannot rename referenced field old to "new-name", which is not a valid identifier`,
+	}, {
+		name: "rename field anywhere",
+		rules: `rules: [{
+	name: "old"
+	renameField: {from: "old", to: "new-name"}
+}]`,
+		in: `a: old: 1
+X=old: 2
+b: {old?: X}
+`,
+		out: `a: "new-name": 1
+X="new-name": 2
+b: {"new-name"?: X}
+`,
+	}, {
+		name: "rename attribute",
+		rules: `rules: [{
+	name: "go-attr"
+	renameAttribute: {from: "golang", to: "go"}
+}]`,
+		in: `@golang(pkg)
+
+a: int @golang(A) @json(a)
+`,
+		out: `@go(pkg)
+
+a: int @go(A) @json(a)
+`,
+	}, {
+		name: "no action",
+		rules: `rules: [{
+	name: "nothing"
+}]`,
+		err: `invalid rule nothing: rule must specify exactly one action`,
+	}, {
+		name: "multiple actions",
+		rules: `rules: [{
+	name: "both"
+	renameField: {from: "a", to: "b"}
+	renameAttribute: {from: "a", to: "b"}
+}]`,
+		err: `invalid rule both: rule must specify exactly one action`,
+	}}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			v := cuecontext.New().CompileString(tc.rules)
+			qt.Assert(t, qt.IsNil(v.Err()))
+			rules, err := ParseRules(v)
+			if err == nil {
+				f, perr := parser.ParseFile("", tc.in, parser.ParseComments)
+				qt.Assert(t, qt.IsNil(perr))
+				err = Instances([]*build.Instance{{Files: []*ast.File{f}}}, Rules(rules...))
+				if err == nil {
+					b, ferr := format.Node(f)
+					qt.Assert(t, qt.IsNil(ferr))
+					qt.Assert(t, qt.Equals(string(b), tc.out))
+					return
+				}
+				// Rules which fail leave the file unchanged.
+				b, ferr := format.Node(f)
+				qt.Assert(t, qt.IsNil(ferr))
+				qt.Assert(t, qt.Equals(string(b), tc.in))
+			}
+			qt.Assert(t, qt.ErrorMatches(err, tc.err))
+		})
+	}
+}
+
+func TestRegister(t *testing.T) {
+	Register(Rule{
+		Name: "test-register",
+		Fix: func(f *ast.File) error {
+			return renameField(f, []string{"registered"}, "renamed")
+		},
+	})
+	defer func() {
+		registryMu.Lock()
+		registry = nil
+		registryMu.Unlock()
+	}()
+
+	f, err := parser.ParseFile("", "registered: 1\n")
+	qt.Assert(t, qt.IsNil(err))
+	File(f)
+	b, err := format.Node(f)
+	qt.Assert(t, qt.IsNil(err))
+	qt.Assert(t, qt.Equals(string(b), "renamed: 1\n"))
+
+	qt.Assert(t, qt.PanicMatches(func() {
+		Register(Rule{Name: "test-register"})
+	}, `fix: rule "test-register" registered twice`))
+}
@@ -0,0 +1,487 @@
+// Copyright 2025 The CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package merge implements a three-way merge of configuration files,
+// such as is needed to combine the changes made to a file on two
+// branches since their common ancestor.
+//
+// Unlike a line-based merge, the files are merged structurally: two
+// changes only conflict if they change the same field, or the same
+// embedded value, differently. The changes to the fields of structs
+// which were changed on both sides are merged recursively. Lists and
+// other values are merged as a whole.
+//
+// Conflicts are reported with the usual conflict markers of version
+// control tools, which only ever surround complete declarations, so
+// that each side of a conflict can be read as CUE or YAML. As the
+// markers are not valid syntax, a file with conflicts cannot be used
+// by accident.
+package merge
+
+import (
+	"bytes"
+	"cmp"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	"cuelang.org/go/cue/ast"
+	"cuelang.org/go/cue/cuecontext"
+	"cuelang.org/go/cue/format"
+	"cuelang.org/go/cue/token"
+	"cuelang.org/go/encoding/yaml"
+)
+
+// Config configures a merge.
+type Config struct {
+	// OursLabel and TheirsLabel name the two sides of a conflict in the
+	// conflict markers. They default to "ours" and "theirs".
+	OursLabel   string
+	TheirsLabel string
+}
+
+// A Result holds the result of a merge.
+type Result struct {
+	// Data holds the merged file, including conflict markers if there
+	// are any conflicts.
+	Data []byte
+
+	// Conflicts holds the conflicts in the order in which they appear
+	// in Data.
+	Conflicts []Conflict
+}
+
+// A Conflict describes a declaration which was changed differently by
+// both sides of a merge.
+type Conflict struct {
+	// Path holds the labels of the conflicting field separated by dots,
+	// such as "servers.web.port". For values embedded in a struct, it
+	// holds the path of that struct, which is empty for the top level.
+	Path string
+}
+
+// Files merges the changes made from base to ours and from base to
+// theirs, and returns the formatted result. The files are not modified.
+func Files(base, ours, theirs *ast.File, cfg *Config) (*Result, error) {
+	m := newMerger(cfg)
+	f := &ast.File{
+		Filename: ours.Filename,
+		Decls:    m.mergeDecls(nil, base.Decls, ours.Decls, theirs.Decls),
+	}
+	if len(f.Decls) > 0 && hasConflict(f.Decls) {
+		onOwnLines(f.Decls[1:])
+	}
+	ast.SetComments(f, ours.Comments())
+	b, err := format.Node(f)
+	if err != nil {
+		return nil, err
+	}
+	return m.result(b, source)
+}
+
+// YAML merges the changes made from base to ours and from base to
+// theirs in YAML documents, and returns the result encoded as YAML
+// in the style of ours. Only the first document of a stream is
+// merged.
+func YAML(base, ours, theirs []byte, cfg *Config) (*Result, error) {
+	var files [3]*ast.File
+	for i, src := range [][]byte{base, ours, theirs} {
+		f, err := yaml.Extract("", src)
+		if err != nil {
+			return nil, err
+		}
+		files[i] = f
+	}
+	m := newMerger(cfg)
+	m.fieldPlaceholders = true
+	f := &ast.File{Decls: m.mergeDecls(nil, files[0].Decls, files[1].Decls, files[2].Decls)}
+	encode := func(f *ast.File, like []byte) ([]byte, error) {
+		v := cuecontext.New().BuildFile(f)
+		if err := v.Err(); err != nil {
+			return nil, err
+		}
+		if like != nil {
+			return yaml.EncodeLike(v, like)
+		}
+		return yaml.Encode(v)
+	}
+	b, err := encode(f, ours)
+	if err != nil {
+		return nil, err
+	}
+	return m.result(b, func(n ast.Node) ([]byte, error) {
+		return encode(&ast.File{Decls: []ast.Decl{n.(ast.Decl)}}, nil)
+	})
+}
+
+type merger struct {
+	cfg       Config
+	conflicts []conflict
+
+	// fieldPlaceholders makes placeholders fields rather than embedded
+	// identifiers, so that the merged file can be evaluated.
+	fieldPlaceholders bool
+}
+
+type conflict struct {
+	path         string
+	ours, theirs ast.Node
+}
+
+func newMerger(cfg *Config) *merger {
+	m := &merger{}
+	if cfg != nil {
+		m.cfg = *cfg
+	}
+	if m.cfg.OursLabel == "" {
+		m.cfg.OursLabel = "ours"
+	}
+	if m.cfg.TheirsLabel == "" {
+		m.cfg.TheirsLabel = "theirs"
+	}
+	return m
+}
+
+// placeholderPrefix starts the identifiers which stand in for conflicts
+// until the merged file is formatted.
+const placeholderPrefix = "cue_merge_conflict_"
+
+// result replaces the placeholder of each conflict in the formatted
+// data b with conflict markers around both sides of the conflict, as
+// formatted by render.
+func (m *merger) result(b []byte, render func(ast.Node) ([]byte, error)) (*Result, error) {
+	r := &Result{}
+	if len(m.conflicts) == 0 {
+		r.Data = b
+		return r, nil
+	}
+	var buf bytes.Buffer
+	for _, line := range bytes.SplitAfter(b, []byte("\n")) {
+		indent, i, ok := placeholderIndex(string(line))
+		if !ok || i >= len(m.conflicts) {
+			buf.Write(line)
+			continue
+		}
+		c := m.conflicts[i]
+		r.Conflicts = append(r.Conflicts, Conflict{Path: c.path})
+		fmt.Fprintf(&buf, "<<<<<<< %s\n", m.cfg.OursLabel)
+		if err := writeIndented(&buf, indent, c.ours, render); err != nil {
+			return nil, err
+		}
+		buf.WriteString("=======\n")
+		if err := writeIndented(&buf, indent, c.theirs, render); err != nil {
+			return nil, err
+		}
+		fmt.Fprintf(&buf, ">>>>>>> %s\n", m.cfg.TheirsLabel)
+	}
+	r.Data = buf.Bytes()
+	return r, nil
+}
+
+// placeholderIndex reports whether line only holds the placeholder of a
+// conflict, and if so returns its indentation and the conflict index.
+func placeholderIndex(line string) (indent string, i int, ok bool) {
+	s := strings.TrimLeft(line, " \t")
+	indent = line[:len(line)-len(s)]
+	s, ok = strings.CutPrefix(strings.TrimSpace(s), placeholderPrefix)
+	if !ok {
+		return "", 0, false
+	}
+	s = strings.TrimSuffix(s, ": null")
+	i, err := strconv.Atoi(s)
+	return indent, i, err == nil
+}
+
+func writeIndented(buf *bytes.Buffer, indent string, n ast.Node, render func(ast.Node) ([]byte, error)) error {
+	if n == nil {
+		return nil
+	}
+	b, err := render(n)
+	if err != nil {
+		return err
+	}
+	for _, line := range strings.Split(strings.TrimRight(string(b), "\n"), "\n") {
+		if line != "" {
+			buf.WriteString(indent)
+		}
+		buf.WriteString(line)
+		buf.WriteByte('\n')
+	}
+	return nil
+}
+
+// addConflict records a conflict and returns the placeholder which
+// stands in for it.
+func (m *merger) addConflict(path []string, ours, theirs ast.Node) ast.Node {
+	ident := ast.NewIdent(fmt.Sprintf("%s%d", placeholderPrefix, len(m.conflicts)))
+	m.conflicts = append(m.conflicts, conflict{
+		path:   strings.Join(path, "."),
+		ours:   ours,
+		theirs: theirs,
+	})
+	// An embedded identifier does not affect the alignment of the
+	// fields around it.
+	var d ast.Decl = &ast.EmbedDecl{Expr: ident}
+	if m.fieldPlaceholders {
+		d = &ast.Field{Label: ident, Value: ast.NewNull()}
+	}
+	// Without a position, a placeholder at the start of a file would
+	// be preceded by an empty line. See onOwnLines.
+	ast.SetRelPos(d, token.NoSpace)
+	return d
+}
+
+// entry is a declaration in a list of declarations, keyed so that the
+// same declaration can be found in the other versions of the list.
+type entry struct {
+	key  string
+	node ast.Node
+}
+
+// keyed returns the entries of decls. The import declarations are
+// split into an entry per import.
+func keyed(decls []ast.Decl) []entry {
+	var entries []entry
+	seen := map[string]int{}
+	add := func(key string, node ast.Node) {
+		n := seen[key]
+		seen[key]++
+		if n > 0 {
+			key += "#" + strconv.Itoa(n)
+		}
+		entries = append(entries, entry{key, node})
+	}
+	for _, d := range decls {
+		switch d := d.(type) {
+		case *ast.Package:
+			add("package", d)
+		case *ast.ImportDecl:
+			for _, spec := range d.Specs {
+				add("import "+spec.Path.Value, spec)
+			}
+		case *ast.Field:
+			add("field "+labelString(d.Label)+d.Constraint.String(), d)
+		case *ast.LetClause:
+			add("let "+d.Ident.Name, d)
+		case *ast.EmbedDecl:
+			add("embed", d)
+		default:
+			// Other declarations, such as comprehensions, are only
+			// added or deleted.
+			b, _ := source(d)
+			add("decl "+string(b), d)
+		}
+	}
+	return entries
+}
+
+func labelString(l ast.Label) string {
+	if name, _, err := ast.LabelName(l); err == nil {
+		if ast.IsValidIdent(name) {
+			return name
+		}
+		return strconv.Quote(name)
+	}
+	b, _ := source(l)
+	return string(b)
+}
+
+// source returns the formatted source of n.
+func source(n ast.Node) ([]byte, error) {
+	if spec, ok := n.(*ast.ImportSpec); ok {
+		n = &ast.ImportDecl{Specs: []*ast.ImportSpec{spec}}
+	}
+	return format.Node(n)
+}
+
+func equal(a, b ast.Node) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	as, err := source(a)
+	if err != nil {
+		return false
+	}
+	bs, err := source(b)
+	return err == nil && bytes.Equal(as, bs)
+}
+
+// mergeDecls merges the declarations of the struct or file at path.
+func (m *merger) mergeDecls(path []string, base, ours, theirs []ast.Decl) []ast.Decl {
+	b, o, t := keyed(base), keyed(ours), keyed(theirs)
+	find := func(entries []entry, key string) ast.Node {
+		for _, e := range entries {
+			if e.key == key {
+				return e.node
+			}
+		}
+		return nil
+	}
+
+	var result []entry
+	for _, e := range o {
+		if n := m.mergeDecl(path, find(b, e.key), e.node, find(t, e.key)); n != nil {
+			result = append(result, entry{e.key, n})
+		}
+	}
+	for i, e := range t {
+		if find(o, e.key) != nil {
+			continue
+		}
+		n := m.mergeDecl(path, find(b, e.key), nil, e.node)
+		if n == nil {
+			continue
+		}
+		// Insert the declaration after the closest declaration
+		// preceding it in theirs, or else before the closest one
+		// following it.
+		// Imports are only placed among imports.
+		at := -1
+		for j := i - 1; j >= 0 && at < 0; j-- {
+			if k := indexOf(result, t[j].key); k >= 0 && isImport(t[j].key) == isImport(e.key) {
+				at = k + 1
+			}
+		}
+		for j := i + 1; j < len(t) && at < 0; j++ {
+			if isImport(t[j].key) == isImport(e.key) {
+				at = indexOf(result, t[j].key)
+			}
+		}
+		if at < 0 {
+			at = len(result)
+		}
+		result = append(result[:at], append([]entry{{e.key, n}}, result[at:]...)...)
+	}
+
+	decls := make([]ast.Decl, 0, len(result))
+	var imports []*ast.ImportSpec
+	for _, e := range result {
+		spec, ok := e.node.(*ast.ImportSpec)
+		if !ok {
+			decls = append(decls, e.node.(ast.Decl))
+			continue
+		}
+		if imports == nil {
+			// Put all imports in one declaration, where the first
+			// import was.
+			decls = append(decls, &ast.ImportDecl{})
+		}
+		imports = append(imports, spec)
+	}
+	for _, d := range decls {
+		if d, ok := d.(*ast.ImportDecl); ok {
+			d.Specs = imports
+			if len(imports) > 1 {
+				d.Lparen = token.NoSpace.Pos()
+				for _, spec := range imports {
+					ast.SetRelPos(spec, token.Newline)
+				}
+				d.Rparen = token.Newline.Pos()
+			}
+		}
+	}
+	return decls
+}
+
+func isImport(key string) bool {
+	return strings.HasPrefix(key, "import ")
+}
+
+func indexOf(entries []entry, key string) int {
+	return slices.IndexFunc(entries, func(e entry) bool { return e.key == key })
+}
+
+// mergeDecl merges the versions of one declaration or import, any of which
+// may be nil if it does not exist in that version. It returns nil if
+// the declaration should be deleted.
+func (m *merger) mergeDecl(path []string, base, ours, theirs ast.Node) ast.Node {
+	switch {
+	case equal(ours, theirs), equal(base, theirs):
+		return ours
+	case equal(base, ours):
+		return theirs
+	}
+	if f, ok := cmp.Or(ours, theirs).(*ast.Field); ok {
+		path = append(path, labelString(f.Label))
+	}
+	of, os := structField(ours)
+	tf, ts := structField(theirs)
+	_, bs := structField(base)
+	if os == nil || ts == nil || (base != nil && bs == nil) ||
+		!equal(fieldWithout(of), fieldWithout(tf)) {
+		return m.addConflict(path, ours, theirs)
+	}
+	var baseElts []ast.Decl
+	if bs != nil {
+		baseElts = bs.Elts
+	}
+	s := *os
+	s.Elts = m.mergeDecls(path, baseElts, os.Elts, ts.Elts)
+	if hasConflict(s.Elts) {
+		onOwnLines(s.Elts)
+		s.Rbrace = token.Newline.Pos()
+	}
+	f := *of
+	f.Value = &s
+	return &f
+}
+
+// structField returns d as a field and its value if d is a field whose
+// value is a struct literal.
+func structField(n ast.Node) (*ast.Field, *ast.StructLit) {
+	f, ok := n.(*ast.Field)
+	if !ok {
+		return nil, nil
+	}
+	s, ok := f.Value.(*ast.StructLit)
+	if !ok {
+		return nil, nil
+	}
+	return f, s
+}
+
+// fieldWithout returns a copy of f without its value, so that the other
+// parts of fields can be compared.
+func fieldWithout(f *ast.Field) *ast.Field {
+	c := *f
+	c.Value = &ast.StructLit{}
+	return &c
+}
+
+// onOwnLines puts each of decls on a line of its own, as conflict
+// markers must be on lines of their own.
+func onOwnLines(decls []ast.Decl) {
+	for _, d := range decls {
+		if d.Pos().RelPos() < token.Newline {
+			ast.SetRelPos(d, token.Newline)
+		}
+	}
+}
+
+func hasConflict(decls []ast.Decl) bool {
+	for _, d := range decls {
+		var x ast.Node
+		switch d := d.(type) {
+		case *ast.Field:
+			x = d.Label
+		case *ast.EmbedDecl:
+			x = d.Expr
+		}
+		if id, ok := x.(*ast.Ident); ok && strings.HasPrefix(id.Name, placeholderPrefix) {
+			return true
+		}
+	}
+	return false
+}
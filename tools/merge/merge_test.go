@@ -0,0 +1,206 @@
+// Copyright 2025 The CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package merge_test
+
+import (
+	"testing"
+
+	"github.com/go-quicktest/qt"
+
+	"cuelang.org/go/cue/parser"
+	"cuelang.org/go/tools/merge"
+)
+
+func TestFiles(t *testing.T) {
+	testCases := []struct {
+		name              string
+		base, ours, their string
+		want              string
+		wantConflicts     []merge.Conflict
+	}{{
+		name: "Disjoint",
+		base: `
+package config
+
+import "strings"
+
+a: 1
+b: {
+	x: 1
+	y: 2
+}
+c: [1, 2]
+`,
+		ours: `
+package config
+
+import (
+	"list"
+	"strings"
+)
+
+a: 2
+b: {
+	x: 1
+	y: 3
+}
+c: [1, 2]
+`,
+		their: `
+package config
+
+import (
+	"math"
+	"strings"
+)
+
+a: 1
+b: {
+	x: 2
+	y: 2
+	z: 3
+}
+d: strings.ToUpper("d")
+`,
+		want: `
+package config
+
+import (
+	"list"
+	"math"
+	"strings"
+)
+
+a: 2
+b: {
+	x: 2
+	y: 3
+	z: 3
+}
+d: strings.ToUpper("d")
+`,
+	}, {
+		name: "Conflicts",
+		base: `
+a: 1
+b: {
+	x: 1
+	y: [1]
+}
+c: 1
+`,
+		ours: `
+a: 2
+b: {
+	x: 1
+	y: [1, 2]
+}
+`,
+		their: `
+a: 3
+b: {
+	x: 2
+	y: [1, 3]
+}
+c: 2
+`,
+		want: `
+<<<<<<< ours
+a: 2
+=======
+a: 3
+>>>>>>> theirs
+b: {
+	x: 2
+<<<<<<< ours
+	y: [1, 2]
+=======
+	y: [1, 3]
+>>>>>>> theirs
+}
+<<<<<<< ours
+=======
+c: 2
+>>>>>>> theirs
+`,
+		wantConflicts: []merge.Conflict{{Path: "a"}, {Path: "b.y"}, {Path: "c"}},
+	}}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			base, err := parser.ParseFile("base.cue", tc.base, parser.ParseComments)
+			qt.Assert(t, qt.IsNil(err))
+			ours, err := parser.ParseFile("ours.cue", tc.ours, parser.ParseComments)
+			qt.Assert(t, qt.IsNil(err))
+			theirs, err := parser.ParseFile("theirs.cue", tc.their, parser.ParseComments)
+			qt.Assert(t, qt.IsNil(err))
+
+			r, err := merge.Files(base, ours, theirs, nil)
+			qt.Assert(t, qt.IsNil(err))
+			qt.Check(t, qt.Equals(string(r.Data), tc.want[1:]))
+			qt.Check(t, qt.DeepEquals(r.Conflicts, tc.wantConflicts))
+		})
+	}
+}
+
+func TestYAML(t *testing.T) {
+	base := `
+# The web server.
+name: web
+replicas: 1
+ports:
+  http: 80
+`[1:]
+	ours := `
+# The web server.
+name: web
+replicas: 2
+ports:
+  http: 80
+`[1:]
+	theirs := `
+name: web
+replicas: 1
+ports:
+  http: 8080
+  https: 443
+`[1:]
+	r, err := merge.YAML([]byte(base), []byte(ours), []byte(theirs), nil)
+	qt.Assert(t, qt.IsNil(err))
+	qt.Check(t, qt.Equals(string(r.Data), `
+# The web server.
+name: web
+replicas: 2
+ports:
+  http: 8080
+  https: 443
+`[1:]))
+	qt.Check(t, qt.HasLen(r.Conflicts, 0))
+
+	r, err = merge.YAML([]byte(base), []byte(ours), []byte("name: web\nreplicas: 3\n"), &merge.Config{
+		OursLabel:   "HEAD",
+		TheirsLabel: "feature",
+	})
+	qt.Assert(t, qt.IsNil(err))
+	qt.Check(t, qt.Equals(string(r.Data), `
+# The web server.
+name: web
+<<<<<<< HEAD
+replicas: 2
+=======
+replicas: 3
+>>>>>>> feature
+`[1:]))
+	qt.Check(t, qt.DeepEquals(r.Conflicts, []merge.Conflict{{Path: "replicas"}}))
+}
@@ -0,0 +1,292 @@
+// Copyright 2025 The CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package migrate upgrades data to the latest version of a versioned
+// schema.
+//
+// A versioned schema is a struct with a field for each version of the
+// schema, named v1, v2, and so on. Each version after the first may
+// declare how to migrate data from the version before it with a field
+// named from_ followed by the name of that version, which holds a
+// struct with an input field "in" and an output field "out":
+//
+//	#Config: {
+//		v1: {
+//			name: string
+//			port: int
+//		}
+//		v2: {
+//			name: string
+//			server: port: int
+//
+//			from_v1: {
+//				in: v1
+//				out: {
+//					name: in.name
+//					server: port: in.port
+//				}
+//			}
+//		}
+//	}
+//
+// Data is migrated by placing it in the "in" field of each migration
+// in turn and taking its "out" field, which must be valid for the next
+// version. The from_ fields are not part of the schema of a version.
+package migrate
+
+import (
+	"bytes"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	"cuelang.org/go/cue"
+	"cuelang.org/go/cue/ast"
+	"cuelang.org/go/cue/errors"
+)
+
+// A Schema holds the versions of a versioned schema.
+type Schema struct {
+	value    cue.Value
+	versions []string
+}
+
+// NewSchema returns the versioned schema v. It is an error if v has no
+// fields named as versions.
+func NewSchema(v cue.Value) (*Schema, error) {
+	s := &Schema{value: v}
+	iter, err := v.Fields(cue.Definitions(false))
+	if err != nil {
+		return nil, err
+	}
+	for iter.Next() {
+		if _, ok := versionNumber(iter.Selector().String()); ok {
+			s.versions = append(s.versions, iter.Selector().String())
+		}
+	}
+	if len(s.versions) == 0 {
+		return nil, fmt.Errorf("schema has no versions; expected fields named v1, v2, and so on")
+	}
+	slices.SortFunc(s.versions, func(a, b string) int {
+		x, _ := versionNumber(a)
+		y, _ := versionNumber(b)
+		return x - y
+	})
+	return s, nil
+}
+
+// versionNumber returns the number of the version with the given name,
+// and whether it is a valid version name.
+func versionNumber(name string) (int, bool) {
+	s, ok := strings.CutPrefix(name, "v")
+	if !ok {
+		return 0, false
+	}
+	n, err := strconv.Atoi(s)
+	return n, err == nil && n > 0 && strconv.Itoa(n) == s
+}
+
+// Versions returns the names of the versions of the schema, from the
+// oldest to the latest.
+func (s *Schema) Versions() []string {
+	return slices.Clone(s.versions)
+}
+
+// Latest returns the name of the latest version of the schema.
+func (s *Schema) Latest() string {
+	return s.versions[len(s.versions)-1]
+}
+
+// Validate reports whether data is valid for the given version.
+func (s *Schema) Validate(data cue.Value, version string) error {
+	if !slices.Contains(s.versions, version) {
+		return fmt.Errorf("unknown schema version %q", version)
+	}
+	return validate(s.value.LookupPath(cue.MakePath(cue.Str(version))), data)
+}
+
+// validate reports whether data is valid for schema, ignoring the
+// migrations in schema.
+func validate(schema, data cue.Value) error {
+	v := schema.Unify(data)
+	if err := v.Validate(); err != nil {
+		return err
+	}
+	iter, err := v.Fields()
+	if err != nil {
+		return err
+	}
+	for iter.Next() {
+		if isMigration(iter.Selector()) {
+			continue
+		}
+		if err := iter.Value().Validate(cue.Concrete(true)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func isMigration(sel cue.Selector) bool {
+	return sel.IsString() && strings.HasPrefix(sel.Unquoted(), "from_")
+}
+
+// Detect returns the latest version for which data is valid.
+func (s *Schema) Detect(data cue.Value) (string, error) {
+	for _, version := range slices.Backward(s.versions) {
+		if s.Validate(data, version) == nil {
+			return version, nil
+		}
+	}
+	return "", fmt.Errorf("data is not valid for any version of the schema")
+}
+
+// A Result holds the result of a migration.
+type Result struct {
+	// Value holds the migrated data.
+	Value cue.Value
+
+	// From and To hold the versions the data was migrated from and to.
+	From, To string
+
+	// Unmapped holds the fields of the data which were dropped by a
+	// migration, as their values had no effect on its output.
+	Unmapped []Unmapped
+}
+
+// Unmapped describes a field which a migration did not carry over.
+type Unmapped struct {
+	// Version holds the version of the data the field was part of.
+	Version string
+
+	// Path holds the path of the field in that data.
+	Path cue.Path
+}
+
+// Migrate migrates data from the version from to the latest version.
+// If from is empty, the version is found with [Schema.Detect].
+func (s *Schema) Migrate(data cue.Value, from string) (*Result, error) {
+	if from == "" {
+		var err error
+		if from, err = s.Detect(data); err != nil {
+			return nil, err
+		}
+	} else if err := s.Validate(data, from); err != nil {
+		return nil, fmt.Errorf("data is not valid for version %s: %w", from, err)
+	}
+	r := &Result{From: from, To: s.Latest()}
+	for i := slices.Index(s.versions, from); i < len(s.versions)-1; i++ {
+		prev, next := s.versions[i], s.versions[i+1]
+		nextSchema := s.value.LookupPath(cue.MakePath(cue.Str(next)))
+		m := nextSchema.LookupPath(cue.MakePath(cue.Str("from_" + prev)))
+		if !m.Exists() {
+			return nil, fmt.Errorf("no migration from version %s to %s; expected a field %s.from_%s", prev, next, next, prev)
+		}
+		out, err := migrate(m, data)
+		if err != nil {
+			return nil, fmt.Errorf("cannot migrate from version %s to %s: %w", prev, next, err)
+		}
+		if err := validate(nextSchema, out); err != nil {
+			return nil, fmt.Errorf("migration from version %s to %s produced invalid data: %w", prev, next, err)
+		}
+		for _, p := range unmapped(m, data, out) {
+			r.Unmapped = append(r.Unmapped, Unmapped{Version: prev, Path: p})
+		}
+		data = out
+	}
+	r.Value = data
+	return r, nil
+}
+
+var (
+	inPath  = cue.MakePath(cue.Str("in"))
+	outPath = cue.MakePath(cue.Str("out"))
+)
+
+// migrate returns the output of the migration m for data.
+func migrate(m, data cue.Value) (cue.Value, error) {
+	out := m.FillPath(inPath, data).LookupPath(outPath)
+	if !out.Exists() {
+		return out, errors.Newf(m.Pos(), "migration has no out field")
+	}
+	if err := out.Validate(cue.Concrete(true)); err != nil {
+		return out, err
+	}
+	// Make the output plain data, which is not closed like the
+	// schema that it was defined in.
+	expr, ok := out.Syntax(cue.Final(), cue.Concrete(true)).(ast.Expr)
+	if !ok {
+		return out, errors.Newf(out.Pos(), "migration output is not an expression")
+	}
+	out = m.Context().BuildExpr(expr)
+	return out, out.Err()
+}
+
+// unmapped returns the paths of the fields of data whose removal does
+// not change the output out of the migration m.
+func unmapped(m, data, out cue.Value) []cue.Path {
+	want, err := out.MarshalJSON()
+	if err != nil {
+		return nil
+	}
+	var paths []cue.Path
+	var walk func(v cue.Value, path []cue.Selector)
+	walk = func(v cue.Value, path []cue.Selector) {
+		iter, err := v.Fields()
+		if err != nil {
+			return
+		}
+		for iter.Next() {
+			p := append(slices.Clip(path), iter.Selector())
+			without, ok := withoutField(data, p)
+			if !ok {
+				continue
+			}
+			got, err := migrate(m, without)
+			if err == nil {
+				if b, err := got.MarshalJSON(); err == nil && bytes.Equal(b, want) {
+					paths = append(paths, cue.MakePath(p...))
+					continue
+				}
+			}
+			// The field is used, but some of its fields may not be.
+			if iter.Value().IncompleteKind() == cue.StructKind {
+				walk(iter.Value(), p)
+			}
+		}
+	}
+	walk(data, nil)
+	return paths
+}
+
+// withoutField returns data without the field at path.
+func withoutField(data cue.Value, path []cue.Selector) (cue.Value, bool) {
+	var x any
+	if err := data.Decode(&x); err != nil {
+		return data, false
+	}
+	m, ok := x.(map[string]any)
+	for _, sel := range path[:len(path)-1] {
+		if !ok {
+			return data, false
+		}
+		m, ok = m[sel.Unquoted()].(map[string]any)
+	}
+	if !ok {
+		return data, false
+	}
+	delete(m, path[len(path)-1].Unquoted())
+	return data.Context().Encode(x), true
+}
@@ -0,0 +1,141 @@
+// Copyright 2025 The CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package migrate_test
+
+import (
+	"testing"
+
+	"github.com/go-quicktest/qt"
+
+	"cuelang.org/go/cue"
+	"cuelang.org/go/cue/cuecontext"
+	"cuelang.org/go/tools/migrate"
+)
+
+const schemaSrc = `
+#Config: {
+	v1: {
+		name:   string
+		port:   int
+		debug?: bool
+	}
+	v2: {
+		name: string
+		server: port: int
+
+		from_v1: {
+			in: v1
+			out: {
+				name: in.name
+				server: port: in.port
+			}
+		}
+	}
+	v3: {
+		name: string
+		server: {
+			port: int
+			tls:  bool
+		}
+
+		from_v2: {
+			in: v2
+			out: {
+				name: in.name
+				server: port: in.server.port
+				server: tls:  in.server.port == 443
+			}
+		}
+	}
+}
+`
+
+func TestMigrate(t *testing.T) {
+	ctx := cuecontext.New()
+	v := ctx.CompileString(schemaSrc)
+	qt.Assert(t, qt.IsNil(v.Err()))
+	s, err := migrate.NewSchema(v.LookupPath(cue.ParsePath("#Config")))
+	qt.Assert(t, qt.IsNil(err))
+	qt.Assert(t, qt.DeepEquals(s.Versions(), []string{"v1", "v2", "v3"}))
+	qt.Assert(t, qt.Equals(s.Latest(), "v3"))
+
+	testCases := []struct {
+		name         string
+		data         string
+		from         string
+		wantFrom     string
+		want         string
+		wantUnmapped []string
+		wantErr      string
+	}{{
+		name:         "DetectV1",
+		data:         `{name: "web", port: 443, debug: true}`,
+		wantFrom:     "v1",
+		want:         `{"name":"web","server":{"port":443,"tls":true}}`,
+		wantUnmapped: []string{"v1: debug"},
+	}, {
+		name:     "DetectV2",
+		data:     `{name: "web", server: port: 80}`,
+		wantFrom: "v2",
+		want:     `{"name":"web","server":{"port":80,"tls":false}}`,
+	}, {
+		name:     "Latest",
+		data:     `{name: "web", server: {port: 80, tls: true}}`,
+		wantFrom: "v3",
+		want:     `{"name":"web","server":{"port":80,"tls":true}}`,
+	}, {
+		name:    "Invalid",
+		data:    `{name: "web"}`,
+		wantErr: `data is not valid for any version of the schema`,
+	}, {
+		name:    "WrongVersion",
+		data:    `{name: "web", port: 80}`,
+		from:    "v2",
+		wantErr: `data is not valid for version v2: .*`,
+	}}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			data := ctx.CompileString(tc.data)
+			qt.Assert(t, qt.IsNil(data.Err()))
+			r, err := s.Migrate(data, tc.from)
+			if tc.wantErr != "" {
+				qt.Assert(t, qt.ErrorMatches(err, tc.wantErr))
+				return
+			}
+			qt.Assert(t, qt.IsNil(err))
+			qt.Check(t, qt.Equals(r.From, tc.wantFrom))
+			qt.Check(t, qt.Equals(r.To, "v3"))
+			b, err := r.Value.MarshalJSON()
+			qt.Assert(t, qt.IsNil(err))
+			qt.Check(t, qt.Equals(string(b), tc.want))
+			var unmapped []string
+			for _, u := range r.Unmapped {
+				unmapped = append(unmapped, u.Version+": "+u.Path.String())
+			}
+			qt.Check(t, qt.DeepEquals(unmapped, tc.wantUnmapped))
+		})
+	}
+}
+
+func TestMissingMigration(t *testing.T) {
+	v := cuecontext.New().CompileString(`{v1: {a: int}, v2: {b: int}}`)
+	s, err := migrate.NewSchema(v)
+	qt.Assert(t, qt.IsNil(err))
+	_, err = s.Migrate(v.Context().CompileString(`{a: 1}`), "")
+	qt.Assert(t, qt.ErrorMatches(err, `no migration from version v1 to v2; expected a field v2.from_v1`))
+
+	_, err = migrate.NewSchema(v.LookupPath(cue.ParsePath("v1")))
+	qt.Assert(t, qt.ErrorMatches(err, `schema has no versions; .*`))
+}
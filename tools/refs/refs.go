@@ -0,0 +1,350 @@
+// Copyright 2025 CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package refs finds the references to fields of CUE packages. It is the
+// inverse of identifier resolution: given the path of a field, such as a
+// definition, it reports every location in the source which refers to it.
+//
+// References are found syntactically. An identifier refers to the field
+// it resolves to, and a selector x.y refers to the field y within the
+// field referred to by x. Each prefix of a selector chain is a reference
+// of its own: a.b.c refers to a, a.b, and a.b.c. References to fields of
+// imported packages are found through the qualified identifiers of the
+// import. References which can only be determined by evaluation, such as
+// those to a field obtained by unifying with a definition, are not
+// reported.
+package refs
+
+import (
+	"cmp"
+	"slices"
+	"strings"
+
+	"cuelang.org/go/cue"
+	"cuelang.org/go/cue/ast"
+	"cuelang.org/go/cue/ast/astutil"
+	"cuelang.org/go/cue/build"
+	"cuelang.org/go/cue/token"
+)
+
+// A Reference is a location in the source referring to a field.
+type Reference struct {
+	// Instance is the instance holding the reference.
+	Instance *build.Instance
+
+	// Expr is the referring expression: an *ast.Ident or an
+	// *ast.SelectorExpr.
+	Expr ast.Expr
+
+	// Pos is the position of the identifier naming the field: the
+	// identifier itself or the selector of a selector expression.
+	Pos token.Pos
+
+	// ImportPath is the import path of the package defining the field,
+	// in canonical form and without a major version suffix.
+	ImportPath string
+
+	// Path is the path of the field within its package.
+	Path cue.Path
+}
+
+// An Index holds the references made by a set of instances.
+type Index struct {
+	refs  []Reference
+	byKey map[key][]int
+}
+
+type key struct {
+	importPath string
+	path       string
+}
+
+// decl identifies the field declared by a node.
+type decl struct {
+	importPath string
+	path       []cue.Selector
+}
+
+// New indexes the references made by the given instances and the
+// instances they import, directly or indirectly.
+func New(insts []*build.Instance) *Index {
+	x := &Index{byKey: map[key][]int{}}
+
+	// The same package may be loaded both as one of insts and as an
+	// import, so instances are identified by their import path.
+	var all []*build.Instance
+	seen := map[*build.Instance]bool{}
+	seenPath := map[string]bool{}
+	var add func(b *build.Instance)
+	add = func(b *build.Instance) {
+		if b == nil || seen[b] {
+			return
+		}
+		seen[b] = true
+		if p := canonical(b.ImportPath); p != "" {
+			if seenPath[p] {
+				return
+			}
+			seenPath[p] = true
+		}
+		all = append(all, b)
+		for _, imp := range b.Imports {
+			add(imp)
+		}
+	}
+	for _, b := range insts {
+		add(b)
+	}
+
+	// Nodes to which identifiers resolve for fields are shared between
+	// the files of a package once it is built, so the declarations of
+	// all instances are collected before any reference is resolved.
+	decls := map[ast.Node]decl{}
+	for _, b := range all {
+		for _, f := range b.Files {
+			declare(decls, canonical(b.ImportPath), f.Decls, nil)
+		}
+	}
+	for _, b := range all {
+		x.index(b, decls)
+	}
+	return x
+}
+
+// Find returns the references to the field with the given path in the
+// package with the given import path, sorted by position.
+func (x *Index) Find(importPath string, p cue.Path) []Reference {
+	var refs []Reference
+	for _, i := range x.byKey[key{canonical(importPath), p.String()}] {
+		refs = append(refs, x.refs[i])
+	}
+	slices.SortFunc(refs, func(a, b Reference) int {
+		return comparePos(a.Pos, b.Pos)
+	})
+	return refs
+}
+
+// All returns all references in the index, sorted by position.
+func (x *Index) All() []Reference {
+	refs := slices.Clone(x.refs)
+	slices.SortFunc(refs, func(a, b Reference) int {
+		return comparePos(a.Pos, b.Pos)
+	})
+	return refs
+}
+
+// canonical returns the canonical form of an import path, without a
+// major version suffix.
+func canonical(importPath string) string {
+	if importPath == "" {
+		return ""
+	}
+	ip := ast.ParseImportPath(importPath).Canonical()
+	ip.Version = ""
+	return ip.String()
+}
+
+func comparePos(a, b token.Pos) int {
+	if c := cmp.Compare(a.Filename(), b.Filename()); c != 0 {
+		return c
+	}
+	return cmp.Compare(a.Offset(), b.Offset())
+}
+
+// declare records the paths of the fields declared in decls, which are
+// at path p within the package with the given import path.
+func declare(decls map[ast.Node]decl, importPath string, list []ast.Decl, p []cue.Selector) {
+	for _, d := range list {
+		switch d := d.(type) {
+		case *ast.Field:
+			sel, ok := selector(d.Label, importPath)
+			if !ok {
+				continue
+			}
+			fp := append(slices.Clip(p), sel)
+			// An identifier resolves to the value of a field, or to the
+			// field itself if it refers to the alias of its label.
+			decls[d] = decl{importPath, fp}
+			decls[d.Value] = decl{importPath, fp}
+			declareExpr(decls, importPath, d.Value, fp)
+
+		case *ast.EmbedDecl:
+			declareExpr(decls, importPath, d.Expr, p)
+
+		case *ast.Comprehension:
+			// Fields of comprehensions are added to the enclosing
+			// struct.
+			declareExpr(decls, importPath, d.Value, p)
+		}
+	}
+}
+
+func declareExpr(decls map[ast.Node]decl, importPath string, x ast.Expr, p []cue.Selector) {
+	switch x := x.(type) {
+	case *ast.StructLit:
+		declare(decls, importPath, x.Elts, p)
+
+	case *ast.ListLit:
+		for i, e := range x.Elts {
+			if _, ok := e.(*ast.Ellipsis); ok {
+				break
+			}
+			declareExpr(decls, importPath, e, append(slices.Clip(p), cue.Index(i)))
+		}
+
+	case *ast.BinaryExpr:
+		// The operands of unifications and disjunctions define the
+		// fields of the same value.
+		if x.Op == token.AND || x.Op == token.OR {
+			declareExpr(decls, importPath, x.X, p)
+			declareExpr(decls, importPath, x.Y, p)
+		}
+
+	case *ast.ParenExpr:
+		declareExpr(decls, importPath, x.X, p)
+
+	case *ast.UnaryExpr:
+		// A default marker.
+		if x.Op == token.MUL {
+			declareExpr(decls, importPath, x.X, p)
+		}
+	}
+}
+
+// selector returns the selector for a field label, reporting whether the
+// label is a fixed name.
+func selector(l ast.Label, importPath string) (cue.Selector, bool) {
+	name, isIdent, err := ast.LabelName(l)
+	if err != nil {
+		return cue.Selector{}, false
+	}
+	switch {
+	case !isIdent:
+		return cue.Str(name), true
+	case strings.HasPrefix(name, "_"):
+		if importPath == "" {
+			importPath = "_"
+		}
+		return cue.Hid(name, importPath), true
+	case strings.HasPrefix(name, "#"):
+		return cue.Def(name), true
+	}
+	return cue.Str(name), true
+}
+
+// index adds the references made by the files of b.
+func (x *Index) index(b *build.Instance, decls map[ast.Node]decl) {
+	// Identifiers referring to fields declared in other files of the
+	// package are not resolved until the package is built.
+	importPath := canonical(b.ImportPath)
+	top := map[string]bool{}
+	for _, f := range b.Files {
+		for _, d := range f.Decls {
+			if f, ok := d.(*ast.Field); ok {
+				if name, isIdent, _ := ast.LabelName(f.Label); isIdent {
+					top[name] = true
+				}
+			}
+		}
+	}
+
+	for _, f := range b.Files {
+		unresolved := map[*ast.Ident]bool{}
+		for _, id := range f.Unresolved {
+			if top[id.Name] {
+				unresolved[id] = true
+			}
+		}
+
+		r := &resolver{
+			b:          b,
+			importPath: importPath,
+			decls:      decls,
+			unresolved: unresolved,
+		}
+		ast.Walk(f, func(n ast.Node) bool {
+			switch n := n.(type) {
+			case *ast.Ident:
+				x.add(b, n, n.Pos(), r.resolve(n))
+			case *ast.SelectorExpr:
+				x.add(b, n, n.Sel.Pos(), r.resolve(n))
+			}
+			return true
+		}, nil)
+	}
+}
+
+func (x *Index) add(b *build.Instance, e ast.Expr, pos token.Pos, d *decl) {
+	if d == nil || len(d.path) == 0 {
+		return
+	}
+	p := cue.MakePath(d.path...)
+	k := key{d.importPath, p.String()}
+	x.byKey[k] = append(x.byKey[k], len(x.refs))
+	x.refs = append(x.refs, Reference{
+		Instance:   b,
+		Expr:       e,
+		Pos:        pos,
+		ImportPath: d.importPath,
+		Path:       p,
+	})
+}
+
+type resolver struct {
+	b          *build.Instance
+	importPath string
+	decls      map[ast.Node]decl
+	unresolved map[*ast.Ident]bool
+}
+
+// resolve returns the field to which an expression refers, or nil if it
+// does not refer to a field. An identifier of an import refers to the
+// imported package, with an empty path.
+func (r *resolver) resolve(e ast.Expr) *decl {
+	switch e := e.(type) {
+	case *ast.Ident:
+		switch n := e.Node.(type) {
+		case nil:
+			if r.unresolved[e] {
+				sel, _ := selector(e, r.importPath)
+				return &decl{r.importPath, []cue.Selector{sel}}
+			}
+		case *ast.ImportSpec:
+			info, err := astutil.ParseImportSpec(n)
+			if err != nil {
+				return nil
+			}
+			return &decl{importPath: canonical(info.ID)}
+		default:
+			if d, ok := r.decls[n]; ok {
+				return &d
+			}
+		}
+
+	case *ast.SelectorExpr:
+		d := r.resolve(e.X)
+		if d == nil {
+			return nil
+		}
+		sel, ok := selector(e.Sel, d.importPath)
+		if !ok {
+			return nil
+		}
+		return &decl{d.importPath, append(slices.Clip(d.path), sel)}
+
+	case *ast.ParenExpr:
+		return r.resolve(e.X)
+	}
+	return nil
+}
@@ -0,0 +1,117 @@
+// Copyright 2025 CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package refs_test
+
+import (
+	"fmt"
+	"path/filepath"
+	"testing"
+
+	"github.com/go-quicktest/qt"
+	"golang.org/x/tools/txtar"
+
+	"cuelang.org/go/cue"
+	"cuelang.org/go/internal/cuetxtar"
+	"cuelang.org/go/tools/refs"
+)
+
+const module = `
+-- cue.mod/module.cue --
+module: "mod.test/app@v0"
+language: version: "v0.12.0"
+-- lib/lib.cue --
+package lib
+
+#Port: {
+	min: int
+	max: int
+}
+default: #Port.min
+-- lib/more.cue --
+package lib
+
+range: [#Port, (#Port).max]
+-- main.cue --
+package app
+
+import "mod.test/app/lib"
+
+port: lib.#Port & {min: 80}
+a: b: c: 1
+x: a.b.c
+y: port.min
+z: [for p in [lib.#Port] {p}]
+-- other.cue --
+package app
+
+w: a.b
+let L = {#Port: 1}
+v: L.#Port
+`
+
+func TestFind(t *testing.T) {
+	a := txtar.Parse([]byte(module))
+	insts := cuetxtar.Load(a, t.TempDir(), "./...")
+	for _, b := range insts {
+		qt.Assert(t, qt.IsNil(b.Err))
+	}
+	x := refs.New(insts)
+
+	tests := []struct {
+		importPath string
+		path       string
+		want       []string
+	}{{
+		importPath: "mod.test/app/lib",
+		path:       "#Port",
+		want: []string{
+			"lib.cue:7:10",
+			"more.cue:3:9",
+			"more.cue:3:17",
+			"main.cue:5:11",
+			"main.cue:9:19",
+		},
+	}, {
+		importPath: "mod.test/app/lib",
+		path:       "#Port.max",
+		want:       []string{"more.cue:3:24"},
+	}, {
+		importPath: "mod.test/app",
+		path:       "a",
+		want:       []string{"main.cue:7:4", "other.cue:3:4"},
+	}, {
+		importPath: "mod.test/app",
+		path:       "a.b",
+		want:       []string{"main.cue:7:6", "other.cue:3:6"},
+	}, {
+		importPath: "mod.test/app",
+		path:       "port.min",
+		want:       []string{"main.cue:8:9"},
+	}, {
+		importPath: "mod.test/app",
+		path:       "#Port",
+		want:       nil,
+	}}
+	for _, test := range tests {
+		t.Run(test.importPath+"/"+test.path, func(t *testing.T) {
+			var got []string
+			for _, r := range x.Find(test.importPath, cue.ParsePath(test.path)) {
+				got = append(got, fmt.Sprintf("%s:%d:%d",
+					filepath.Base(r.Pos.Filename()), r.Pos.Line(), r.Pos.Column()))
+			}
+			qt.Assert(t, qt.DeepEquals(got, test.want))
+		})
+	}
+}
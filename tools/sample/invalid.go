@@ -0,0 +1,223 @@
+// Copyright 2025 The CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sample
+
+import (
+	"math"
+	"math/big"
+	"slices"
+
+	"cuelang.org/go/cue"
+	"cuelang.org/go/cue/ast"
+	"cuelang.org/go/cue/token"
+)
+
+// A Mutation is an instance of a schema which was changed in one place
+// so that it is no longer valid, such as to test that consumers of data
+// reject what the schema does not allow.
+type Mutation struct {
+	// Path holds the path of the changed value.
+	Path cue.Path
+
+	// Change describes the change, such as "above the upper bound".
+	Change string
+
+	// Value holds the changed data.
+	Value cue.Value
+}
+
+// unexpectedField is the name of the field added to structs to check
+// whether they are closed.
+const unexpectedField = "unexpected_field"
+
+// Invalid returns mutations of data, which must be an instance of schema,
+// each of which is just outside what schema allows: numbers just beyond
+// their bounds, strings which do not match their constraints, values of
+// the wrong type, missing required fields, and unexpected fields in closed
+// structs. Only changes which make data invalid are returned.
+func Invalid(schema, data cue.Value) []Mutation {
+	expr, ok := data.Syntax(cue.Final(), cue.Concrete(true)).(ast.Expr)
+	if !ok {
+		return nil
+	}
+	m := &mutator{schema: schema, data: expr, ctx: data.Context(), seen: map[string]bool{}}
+	m.walk(schema, data, nil)
+	return m.mutations
+}
+
+type mutator struct {
+	schema    cue.Value
+	data      ast.Expr
+	ctx       *cue.Context
+	mutations []Mutation
+	seen      map[string]bool
+}
+
+// add records the mutation of data which replaces the value at path
+// with the result of f, if it makes data invalid.
+func (m *mutator) add(path []cue.Selector, change string, f func(ast.Expr) ast.Expr) {
+	v := m.ctx.BuildExpr(replace(m.data, path, f))
+	if v.Err() != nil || m.schema.Unify(v).Validate(cue.Concrete(true)) == nil {
+		return
+	}
+	p := cue.MakePath(path...)
+	key := p.String() + "\x00" + change
+	if m.seen[key] {
+		return
+	}
+	m.seen[key] = true
+	m.mutations = append(m.mutations, Mutation{Path: p, Change: change, Value: v})
+}
+
+// set records the mutations which replace the value at path with each
+// of values.
+func (m *mutator) set(path []cue.Selector, change string, values ...any) {
+	for _, x := range values {
+		m.add(path, change, func(ast.Expr) ast.Expr {
+			e, _ := m.ctx.Encode(x).Syntax().(ast.Expr)
+			return e
+		})
+	}
+}
+
+func (m *mutator) walk(schema, data cue.Value, path []cue.Selector) {
+	switch data.Kind() {
+	case cue.StructKind:
+		iter, err := schema.Fields(cue.Optional(true))
+		if err != nil {
+			return
+		}
+		for iter.Next() {
+			name := iter.Selector().Unquoted()
+			d := data.LookupPath(cue.MakePath(cue.Str(name)))
+			if !d.Exists() {
+				continue
+			}
+			p := append(slices.Clip(path), cue.Str(name))
+			if iter.Selector().ConstraintType() != cue.OptionalConstraint {
+				m.add(p, "missing field", func(ast.Expr) ast.Expr { return nil })
+			}
+			m.walk(iter.Value(), d, p)
+		}
+		m.add(path, "unexpected field", func(x ast.Expr) ast.Expr {
+			s, ok := x.(*ast.StructLit)
+			if !ok {
+				return x
+			}
+			c := *s
+			c.Elts = append(slices.Clip(s.Elts), &ast.Field{
+				Label: ast.NewIdent(unexpectedField),
+				Value: ast.NewLit(token.INT, "1"),
+			})
+			return &c
+		})
+		m.set(path, "wrong type", "struct")
+
+	case cue.ListKind:
+		iter, err := data.List()
+		if err != nil {
+			return
+		}
+		for i := 0; iter.Next(); i++ {
+			elem := schema.LookupPath(cue.MakePath(cue.Index(i)))
+			if !elem.Exists() {
+				elem = schema.LookupPath(cue.MakePath(cue.AnyIndex))
+			}
+			m.walk(elem, iter.Value(), append(slices.Clip(path), cue.Index(i)))
+		}
+		m.set(path, "wrong type", "list")
+
+	case cue.IntKind, cue.FloatKind:
+		// The bounds themselves come first, as they are invalid for
+		// exclusive bounds.
+		lo, hi := bounds(schema)
+		isInt := data.Kind() == cue.IntKind
+		if !math.IsInf(lo, 0) {
+			m.set(path, "below the lower bound", numbers(isInt, lo, math.Ceil(lo)-1, math.Nextafter(lo, math.Inf(-1)))...)
+		}
+		if !math.IsInf(hi, 0) {
+			m.set(path, "above the upper bound", numbers(isInt, hi, math.Floor(hi)+1, math.Nextafter(hi, math.Inf(1)))...)
+		}
+		if isInt {
+			m.set(path, "not an integer", 0.5)
+		}
+		m.set(path, "wrong type", "1")
+
+	case cue.StringKind:
+		s, _ := data.String()
+		m.set(path, "string not allowed", "", s+"!")
+		m.set(path, "wrong type", 1)
+
+	case cue.BoolKind:
+		b, _ := data.Bool()
+		m.set(path, "bool not allowed", !b)
+		m.set(path, "wrong type", "true")
+
+	default:
+		m.set(path, "wrong type", 1, "")
+	}
+}
+
+// replace returns x with the value at path replaced by the result of f.
+// Fields for which f returns nil are removed. The syntax of x is not
+// modified.
+func replace(x ast.Expr, path []cue.Selector, f func(ast.Expr) ast.Expr) ast.Expr {
+	if len(path) == 0 {
+		return f(x)
+	}
+	sel := path[0]
+	switch x := x.(type) {
+	case *ast.StructLit:
+		c := *x
+		c.Elts = nil
+		for _, elt := range x.Elts {
+			field, ok := elt.(*ast.Field)
+			if ok && sel.Type() == cue.StringLabel {
+				if name, _, err := ast.LabelName(field.Label); err == nil && name == sel.Unquoted() {
+					fc := *field
+					if fc.Value = replace(field.Value, path[1:], f); fc.Value == nil {
+						continue
+					}
+					elt = &fc
+				}
+			}
+			c.Elts = append(c.Elts, elt)
+		}
+		return &c
+	case *ast.ListLit:
+		c := *x
+		c.Elts = slices.Clone(x.Elts)
+		if sel.Type() == cue.IndexLabel && sel.Index() < len(c.Elts) {
+			c.Elts[sel.Index()] = replace(c.Elts[sel.Index()], path[1:], f)
+		}
+		return &c
+	}
+	return x
+}
+
+// numbers returns xs as numbers of the given kind, leaving out those
+// which are not integers if isInt is set.
+func numbers(isInt bool, xs ...float64) []any {
+	var res []any
+	for _, x := range xs {
+		switch {
+		case !isInt:
+			res = append(res, x)
+		case x == math.Trunc(x):
+			res = append(res, big.NewInt(int64(x)))
+		}
+	}
+	return res
+}
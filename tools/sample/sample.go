@@ -0,0 +1,557 @@
+// Copyright 2025 The CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package sample generates concrete sample data which satisfies a
+// schema, such as for examples in documentation or for test fixtures.
+//
+// Defaults are always used. Otherwise, the first alternative of a
+// disjunction is chosen, numbers are the smallest allowed value closest
+// to zero, strings are the name of their field if it is allowed, or are
+// generated from the regular expressions which constrain them, and open
+// lists have a single element. Optional fields are left out. Values
+// which are computed from other fields, such as interpolations, are
+// left to be evaluated from the values generated for those fields.
+//
+// With [Config.Random], choices are made at random instead, from a
+// source seeded with [Config.Seed], so that the same seed always
+// generates the same data.
+package sample
+
+import (
+	"math"
+	"math/big"
+	"math/rand/v2"
+	"regexp"
+	"regexp/syntax"
+	"strings"
+	"unicode"
+
+	"cuelang.org/go/cue"
+	"cuelang.org/go/cue/ast"
+	"cuelang.org/go/cue/errors"
+)
+
+// Config configures how data is generated.
+type Config struct {
+	// Random makes choices at random rather than choosing the simplest
+	// values. It also includes optional fields at random, and gives
+	// open lists up to three elements.
+	Random bool
+
+	// Seed seeds the choices made with Random.
+	Seed uint64
+}
+
+// Generate returns concrete data which is an instance of the schema v.
+// It returns an error if it cannot find such data.
+func Generate(v cue.Value, cfg *Config) (cue.Value, error) {
+	g := &generator{}
+	if cfg != nil {
+		g.cfg = *cfg
+	}
+	g.rand = rand.New(rand.NewPCG(g.cfg.Seed, g.cfg.Seed))
+	data, err := g.fill(v, cue.Path{}, "")
+	if err != nil {
+		return data, err
+	}
+	if err := data.Validate(cue.Concrete(true), cue.Final()); err != nil {
+		return data, err
+	}
+	// Filling in fields may change their order, so restore the order
+	// of the schema.
+	data = v.Context().BuildExpr(ordered(v, data))
+	return data, data.Err()
+}
+
+// ordered returns the syntax of data with the fields of structs in the
+// order of schema.
+func ordered(schema, data cue.Value) ast.Expr {
+	switch data.Kind() {
+	case cue.StructKind:
+		var fields []any
+		seen := map[string]bool{}
+		add := func(iter *cue.Iterator, err error, schemaOf func(string) cue.Value) {
+			if err != nil {
+				return
+			}
+			for iter.Next() {
+				name := iter.Selector().Unquoted()
+				d := data.LookupPath(cue.MakePath(cue.Str(name)))
+				if seen[name] || !d.Exists() {
+					continue
+				}
+				seen[name] = true
+				fields = append(fields, name, ordered(schemaOf(name), d))
+			}
+		}
+		iter, err := schema.Fields(cue.Optional(true))
+		add(iter, err, func(string) cue.Value { return iter.Value() })
+		iter, err = data.Fields()
+		add(iter, err, func(name string) cue.Value { return data.LookupPath(cue.MakePath(cue.Str(name))) })
+		return ast.NewStruct(fields...)
+	case cue.ListKind:
+		var elems []ast.Expr
+		iter, _ := data.List()
+		for i := 0; iter.Next(); i++ {
+			elem := schema.LookupPath(cue.MakePath(cue.Index(i)))
+			if !elem.Exists() {
+				elem = schema.LookupPath(cue.MakePath(cue.AnyIndex))
+			}
+			elems = append(elems, ordered(elem, iter.Value()))
+		}
+		return ast.NewList(elems...)
+	}
+	return data.Syntax(cue.Final(), cue.Concrete(true)).(ast.Expr)
+}
+
+type generator struct {
+	cfg  Config
+	rand *rand.Rand
+}
+
+// maxPasses bounds the number of passes over the fields of a struct,
+// each of which makes more values concrete.
+const maxPasses = 10
+
+// fill fills in the value at path within root with concrete data, and
+// returns the new root. name is the name of the field at path, if any.
+func (g *generator) fill(root cue.Value, path cue.Path, name string) (cue.Value, error) {
+	v := root.LookupPath(path)
+	if err := v.Err(); err != nil {
+		return root, err
+	}
+	// Open lists have an empty list as an implicit default, which is
+	// not useful as an example.
+	if d, ok := v.Default(); ok && isDone(d) && !isOpenList(v) {
+		return root.FillPath(path, d), nil
+	}
+	if isDone(v) {
+		return root, nil
+	}
+	if alts := disjuncts(v); len(alts) > 0 {
+		// Try each alternative in turn, starting with the chosen one.
+		start := 0
+		if g.cfg.Random {
+			start = g.rand.IntN(len(alts))
+		}
+		var firstErr error
+		for i := range alts {
+			alt := alts[(start+i)%len(alts)]
+			r, err := g.fill(root.FillPath(path, alt), path, name)
+			if err == nil {
+				return r, nil
+			}
+			if firstErr == nil {
+				firstErr = err
+			}
+		}
+		return root, firstErr
+	}
+	switch k := v.IncompleteKind(); {
+	case k == cue.StructKind:
+		return g.fillStruct(root, path)
+	case k == cue.ListKind:
+		return g.fillList(root, path)
+	}
+	if derived(v) {
+		// Leave the value to be computed from the values of the
+		// fields it depends on.
+		return root, nil
+	}
+	for _, c := range g.candidates(v, name) {
+		if r := root.FillPath(path, c); r.LookupPath(path).Validate(cue.Concrete(true)) == nil && r.Err() == nil {
+			return r, nil
+		}
+	}
+	return root, errors.Newf(v.Pos(), "%s: cannot generate a value for %v", pathString(path), v)
+}
+
+// isDone reports whether v is concrete data. Open lists are not, even
+// though they are concrete, so that elements are generated for them.
+func isDone(v cue.Value) bool {
+	if !v.IsConcrete() || v.Validate(cue.Concrete(true)) != nil {
+		return false
+	}
+	return !isOpenList(v)
+}
+
+func isOpenList(v cue.Value) bool {
+	return v.IncompleteKind() == cue.ListKind && v.Allows(cue.AnyIndex)
+}
+
+func pathString(p cue.Path) string {
+	if len(p.Selectors()) == 0 {
+		return "top level"
+	}
+	return p.String()
+}
+
+// disjuncts returns the alternatives of v if it is a disjunction
+// without a default.
+func disjuncts(v cue.Value) []cue.Value {
+	op, args := v.Expr()
+	if op != cue.OrOp {
+		return nil
+	}
+	return args
+}
+
+// derived reports whether v is computed from other values, rather than
+// just constrained.
+func derived(v cue.Value) bool {
+	op, args := v.Expr()
+	switch op {
+	case cue.NoOp, cue.OrOp, cue.RegexMatchOp, cue.NotRegexMatchOp,
+		cue.NotEqualOp, cue.LessThanOp, cue.LessThanEqualOp,
+		cue.GreaterThanOp, cue.GreaterThanEqualOp:
+		return false
+	case cue.AndOp:
+		for _, a := range args {
+			if derived(a) {
+				return true
+			}
+		}
+		return false
+	}
+	return true
+}
+
+func (g *generator) fillStruct(root cue.Value, path cue.Path) (cue.Value, error) {
+	// Fill in the fields in passes, as the values of some fields may
+	// only become concrete once the fields they depend on are filled in.
+	var lastErr error
+	for range maxPasses {
+		v := root.LookupPath(path)
+		iter, err := v.Fields(cue.Optional(true))
+		if err != nil {
+			return root, err
+		}
+		progress := false
+		pending := false
+		for iter.Next() {
+			sel := iter.Selector()
+			if sel.ConstraintType() == cue.OptionalConstraint &&
+				(!g.cfg.Random || g.rand.IntN(2) == 0) {
+				continue
+			}
+			if isDone(iter.Value()) {
+				continue
+			}
+			name := sel.Unquoted()
+			p := appendPath(path, cue.Str(name))
+			if sel.ConstraintType() != 0 {
+				// Make optional and required fields regular ones.
+				root = root.FillPath(p, top(root))
+			}
+			r, err := g.fill(root, p, name)
+			if err != nil {
+				lastErr = err
+				pending = true
+				continue
+			}
+			if !isDone(r.LookupPath(p)) {
+				pending = true
+			} else {
+				progress = true
+			}
+			root = r
+		}
+		if !pending {
+			return root, nil
+		}
+		if !progress {
+			break
+		}
+	}
+	if lastErr != nil {
+		return root, lastErr
+	}
+	return root, root.LookupPath(path).Validate(cue.Concrete(true))
+}
+
+// top returns the top value, which unifies with any value.
+func top(v cue.Value) cue.Value {
+	return v.Context().CompileString("_")
+}
+
+func appendPath(p cue.Path, sel cue.Selector) cue.Path {
+	return cue.MakePath(append(p.Selectors(), sel)...)
+}
+
+func (g *generator) fillList(root cue.Value, path cue.Path) (cue.Value, error) {
+	v := root.LookupPath(path)
+	if !v.Allows(cue.AnyIndex) {
+		// A list of fixed length.
+		n, err := v.Len().Int64()
+		if err != nil {
+			return root, err
+		}
+		for i := range int(n) {
+			r, err := g.fill(root, appendPath(path, cue.Index(i)), "")
+			if err != nil {
+				return root, err
+			}
+			root = r
+		}
+		return root, nil
+	}
+	n := 1
+	if g.cfg.Random {
+		n = g.rand.IntN(4)
+	}
+	elem := v.LookupPath(cue.MakePath(cue.AnyIndex))
+	elems := make([]cue.Value, 0, n)
+	for range n {
+		e, err := g.fill(elem, cue.Path{}, "")
+		if err != nil {
+			return root, err
+		}
+		elems = append(elems, e)
+	}
+	list := v.Context().NewList(elems...)
+	r := root.FillPath(path, list)
+	if err := r.LookupPath(path).Validate(cue.Concrete(true)); err != nil {
+		if n != 0 {
+			// The list may not allow that many elements.
+			return root.FillPath(path, v.Context().NewList()), nil
+		}
+		return root, err
+	}
+	return r, nil
+}
+
+// candidates returns values which may be instances of v, in the order
+// in which they should be tried.
+func (g *generator) candidates(v cue.Value, name string) []any {
+	k := v.IncompleteKind()
+	var c []any
+	if k&cue.StringKind != 0 {
+		for _, re := range regexps(v) {
+			c = append(c, g.matching(re))
+		}
+		if name == "" {
+			name = "example"
+		}
+		if g.cfg.Random {
+			c = append(c, name+"-"+g.matching("[a-z]{3}"))
+		}
+		c = append(c, name, "")
+	}
+	if k&(cue.IntKind|cue.FloatKind) != 0 {
+		c = append(c, g.numbers(v, k&cue.FloatKind == 0)...)
+	}
+	if k&cue.BoolKind != 0 {
+		b := false
+		if g.cfg.Random {
+			b = g.rand.IntN(2) == 0
+		}
+		c = append(c, b, !b)
+	}
+	if k&cue.BytesKind != 0 {
+		c = append(c, []byte(name), []byte{})
+	}
+	if k&cue.NullKind != 0 {
+		c = append(c, nil)
+	}
+	return c
+}
+
+// regexps returns the regular expressions which values of v must match.
+func regexps(v cue.Value) []string {
+	op, args := v.Expr()
+	switch op {
+	case cue.RegexMatchOp:
+		if s, err := args[0].String(); err == nil {
+			return []string{s}
+		}
+	case cue.AndOp:
+		var res []string
+		for _, a := range args {
+			res = append(res, regexps(a)...)
+		}
+		return res
+	}
+	return nil
+}
+
+// bounds returns the lower and upper bounds of the numbers allowed by v,
+// which are infinite if there are none. Whether the bounds themselves
+// are allowed is left to unification.
+func bounds(v cue.Value) (lo, hi float64) {
+	lo, hi = math.Inf(-1), math.Inf(1)
+	var walk func(v cue.Value)
+	walk = func(v cue.Value) {
+		op, args := v.Expr()
+		if op == cue.AndOp {
+			for _, a := range args {
+				walk(a)
+			}
+			return
+		}
+		if len(args) != 1 {
+			return
+		}
+		x, err := args[0].Float64()
+		if err != nil {
+			return
+		}
+		switch op {
+		case cue.GreaterThanOp, cue.GreaterThanEqualOp:
+			lo = max(lo, x)
+		case cue.LessThanOp, cue.LessThanEqualOp:
+			hi = min(hi, x)
+		}
+	}
+	walk(v)
+	return lo, hi
+}
+
+// numbers returns numbers which may be within the bounds of v.
+func (g *generator) numbers(v cue.Value, isInt bool) []any {
+	lo, hi := bounds(v)
+	var c []float64
+	if g.cfg.Random {
+		// Pick from the allowed range, or from a range of a hundred
+		// numbers next to a single bound, or around zero otherwise.
+		from, to := lo, hi
+		switch {
+		case math.IsInf(from, -1) && math.IsInf(to, 1):
+			from, to = -50, 50
+		case math.IsInf(from, -1):
+			from = to - 100
+		case math.IsInf(to, 1):
+			to = from + 100
+		}
+		x := from + g.rand.Float64()*(to-from)
+		if isInt {
+			x = math.Round(x)
+		} else {
+			x = math.Round(x*100) / 100
+		}
+		c = append(c, x)
+	}
+	// Prefer round numbers closest to zero.
+	switch {
+	case lo >= 0:
+		c = append(c, lo, math.Ceil(lo), math.Floor(lo)+1, lo+0.5, math.Nextafter(lo, hi))
+	case hi <= 0:
+		c = append(c, hi, math.Floor(hi), math.Ceil(hi)-1, hi-0.5, math.Nextafter(hi, lo))
+	default:
+		c = append(c, 0, 1, -1)
+	}
+	var res []any
+	for _, x := range c {
+		switch {
+		case math.IsInf(x, 0):
+		case isInt && x == math.Trunc(x):
+			res = append(res, big.NewInt(int64(x)))
+		case !isInt:
+			res = append(res, x)
+		}
+	}
+	return res
+}
+
+// matching returns a string which matches the regular expression re,
+// or re itself if it cannot be parsed.
+func (g *generator) matching(re string) string {
+	r, err := syntax.Parse(re, syntax.Perl)
+	if err != nil {
+		return re
+	}
+	var sb strings.Builder
+	g.writeMatch(&sb, r.Simplify())
+	s := sb.String()
+	if ok, _ := regexp.MatchString(re, s); !ok {
+		return re
+	}
+	return s
+}
+
+func (g *generator) writeMatch(sb *strings.Builder, r *syntax.Regexp) {
+	// repeat writes r.Sub[0] between min and max times.
+	repeat := func(min, max int) {
+		n := min
+		if g.cfg.Random {
+			if max < 0 || max > min+3 {
+				max = min + 3
+			}
+			n += g.rand.IntN(max - min + 1)
+		}
+		for range n {
+			g.writeMatch(sb, r.Sub[0])
+		}
+	}
+	switch r.Op {
+	case syntax.OpLiteral:
+		for _, c := range r.Rune {
+			if r.Flags&syntax.FoldCase != 0 && g.cfg.Random && g.rand.IntN(2) == 0 {
+				c = unicode.SimpleFold(c)
+			}
+			sb.WriteRune(c)
+		}
+	case syntax.OpCharClass:
+		sb.WriteRune(g.classRune(r.Rune))
+	case syntax.OpAnyChar, syntax.OpAnyCharNotNL:
+		sb.WriteRune(g.classRune([]rune{'a', 'z'}))
+	case syntax.OpCapture:
+		g.writeMatch(sb, r.Sub[0])
+	case syntax.OpStar:
+		repeat(0, -1)
+	case syntax.OpPlus:
+		repeat(1, -1)
+	case syntax.OpQuest:
+		repeat(0, 1)
+	case syntax.OpRepeat:
+		repeat(r.Min, r.Max)
+	case syntax.OpConcat:
+		for _, sub := range r.Sub {
+			g.writeMatch(sb, sub)
+		}
+	case syntax.OpAlternate:
+		i := 0
+		if g.cfg.Random {
+			i = g.rand.IntN(len(r.Sub))
+		}
+		g.writeMatch(sb, r.Sub[i])
+	}
+}
+
+// classRune returns a rune in the character class given by pairs of
+// inclusive ranges, preferring letters and digits.
+func (g *generator) classRune(ranges []rune) rune {
+	var printable []rune
+	for i := 0; i+1 < len(ranges); i += 2 {
+		for c := ranges[i]; c <= ranges[i+1] && c < 0x80; c++ {
+			if unicode.IsLetter(c) || unicode.IsDigit(c) {
+				printable = append(printable, c)
+			}
+		}
+	}
+	if len(printable) == 0 {
+		for i := 0; i+1 < len(ranges); i += 2 {
+			if unicode.IsPrint(ranges[i]) {
+				return ranges[i]
+			}
+		}
+		if len(ranges) == 0 {
+			return 'a'
+		}
+		return ranges[0]
+	}
+	if g.cfg.Random {
+		return printable[g.rand.IntN(len(printable))]
+	}
+	return printable[0]
+}
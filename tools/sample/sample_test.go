@@ -0,0 +1,115 @@
+// Copyright 2025 The CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sample_test
+
+import (
+	"testing"
+
+	"github.com/go-quicktest/qt"
+
+	"cuelang.org/go/cue"
+	"cuelang.org/go/cue/cuecontext"
+	"cuelang.org/go/tools/sample"
+)
+
+const schemaSrc = `
+#Server: {
+	name:      string
+	host:      =~"^[a-z]+\\.example\\.com$"
+	port:      int & >1024 & <=65535
+	weight:    float & >0.5
+	replicas:  *3 | int
+	protocol:  "tcp" | "udp"
+	enabled:   bool
+	url:       "http://\(host):\(port)"
+	tags: [...string]
+	limits: {
+		cpu!:    int & >=1
+		memory?: string
+	}
+	pair: [int & <0, string]
+}
+`
+
+func TestGenerate(t *testing.T) {
+	ctx := cuecontext.New()
+	v := ctx.CompileString(schemaSrc)
+	qt.Assert(t, qt.IsNil(v.Err()))
+	schema := v.LookupPath(cue.ParsePath("#Server"))
+
+	got, err := sample.Generate(schema, nil)
+	qt.Assert(t, qt.IsNil(err))
+	b, err := got.MarshalJSON()
+	qt.Assert(t, qt.IsNil(err))
+	qt.Check(t, qt.Equals(string(b), `{"name":"name","host":"a.example.com","port":1025,"weight":1,"replicas":3,"protocol":"tcp","enabled":false,"url":"http://a.example.com:1025","tags":["example"],"limits":{"cpu":1},"pair":[-1,"example"]}`))
+
+	// Random data is always valid, and the same for the same seed.
+	for seed := range uint64(20) {
+		cfg := &sample.Config{Random: true, Seed: seed}
+		got, err := sample.Generate(schema, cfg)
+		qt.Assert(t, qt.IsNil(err), qt.Commentf("seed %d", seed))
+		qt.Assert(t, qt.IsNil(schema.Unify(got).Validate(cue.Concrete(true))))
+		again, err := sample.Generate(schema, cfg)
+		qt.Assert(t, qt.IsNil(err))
+		qt.Assert(t, qt.IsTrue(got.Equals(again)))
+	}
+}
+
+func TestGenerateError(t *testing.T) {
+	v := cuecontext.New().CompileString(`{a: =~"^a$" & !="a"}`)
+	_, err := sample.Generate(v, nil)
+	qt.Assert(t, qt.ErrorMatches(err, `a: cannot generate a value for .*`))
+}
+
+func TestInvalid(t *testing.T) {
+	ctx := cuecontext.New()
+	v := ctx.CompileString(`
+#S: {
+	port:  int & >=1 & <=10
+	mode:  "a" | "b"
+	name?: =~"^x"
+	sub: {on: bool, ...}
+}
+`)
+	qt.Assert(t, qt.IsNil(v.Err()))
+	schema := v.LookupPath(cue.ParsePath("#S"))
+	data := ctx.CompileString(`{port: 1, mode: "a", name: "x", sub: on: true}`)
+
+	var got []string
+	for _, m := range sample.Invalid(schema, data) {
+		qt.Assert(t, qt.IsNotNil(schema.Unify(m.Value).Validate(cue.Concrete(true))))
+		b, err := m.Value.MarshalJSON()
+		qt.Assert(t, qt.IsNil(err))
+		got = append(got, m.Path.String()+": "+m.Change+": "+string(b))
+	}
+	qt.Assert(t, qt.DeepEquals(got, []string{
+		`port: missing field: {"mode":"a","name":"x","sub":{"on":true}}`,
+		`port: below the lower bound: {"port":0,"mode":"a","name":"x","sub":{"on":true}}`,
+		`port: above the upper bound: {"port":11,"mode":"a","name":"x","sub":{"on":true}}`,
+		`port: not an integer: {"port":0.5,"mode":"a","name":"x","sub":{"on":true}}`,
+		`port: wrong type: {"port":"1","mode":"a","name":"x","sub":{"on":true}}`,
+		`mode: missing field: {"port":1,"name":"x","sub":{"on":true}}`,
+		`mode: string not allowed: {"port":1,"mode":"","name":"x","sub":{"on":true}}`,
+		`mode: wrong type: {"port":1,"mode":1,"name":"x","sub":{"on":true}}`,
+		`name: string not allowed: {"port":1,"mode":"a","name":"","sub":{"on":true}}`,
+		`name: wrong type: {"port":1,"mode":"a","name":1,"sub":{"on":true}}`,
+		`sub: missing field: {"port":1,"mode":"a","name":"x"}`,
+		`sub.on: missing field: {"port":1,"mode":"a","name":"x","sub":{}}`,
+		`sub.on: wrong type: {"port":1,"mode":"a","name":"x","sub":{"on":"true"}}`,
+		`sub: wrong type: {"port":1,"mode":"a","name":"x","sub":"struct"}`,
+		`: unexpected field: {"port":1,"mode":"a","name":"x","sub":{"on":true},"unexpected_field":1}`,
+		`: wrong type: "struct"`,
+	}))
+}
@@ -15,10 +15,13 @@
 package trim
 
 import (
+	"errors"
 	"io"
 
 	"cuelang.org/go/cue"
 	"cuelang.org/go/cue/ast"
+	"cuelang.org/go/cue/format"
+	"cuelang.org/go/cue/token"
 	"cuelang.org/go/internal"
 	"cuelang.org/go/internal/core/runtime"
 )
@@ -27,14 +30,102 @@ import (
 type Config struct {
 	Trace       bool
 	TraceWriter io.Writer
+
+	// Aggressiveness determines which redundant values may be removed.
+	// The zero value is [Aggressive].
+	Aggressiveness Aggressiveness
+
+	// Keep holds the paths of values which must be kept as written,
+	// along with all the values beneath them.
+	Keep []cue.Path
+
+	// KeepAttribute, if set, is the name of an attribute which marks
+	// fields to be kept as written, along with all the values beneath
+	// them. For example, with KeepAttribute set to "keep", the field
+	//
+	//	replicas: 1 @keep()
+	//
+	// is never removed.
+	KeepAttribute string
+
+	// DryRun causes the files to be left unchanged. The [Result]
+	// reports the values which would have been removed.
+	DryRun bool
+}
+
+// Aggressiveness determines which redundant values trim may remove.
+type Aggressiveness int
+
+const (
+	// Aggressive removes any redundant value. Redundant parts of
+	// expressions which cannot be deleted, such as one side of a
+	// unification, are replaced with _ or an empty struct.
+	Aggressive Aggressiveness = iota
+
+	// Conservative only removes whole declarations from structs and
+	// files, such as redundant fields, and leaves expressions as they
+	// are written.
+	Conservative
+)
+
+// Result describes the values removed by [Trim].
+type Result struct {
+	// Removed holds the removed values, in the order of the files
+	// and the order in which they appear in each file.
+	Removed []Removal
 }
 
+// A Removal describes a value removed by [Trim].
+type Removal struct {
+	// Pos holds the position of the removed value in its file.
+	Pos token.Pos
+
+	// Source holds the removed value, formatted as CUE.
+	Source string
+
+	// Replacement holds what the value was replaced with, such as "_",
+	// or the empty string if it was deleted.
+	Replacement string
+}
+
+// Files trims fields in the given files that can be implied from other fields,
+// as can be derived from the evaluated values in inst.
 func Files(files []*ast.File, inst cue.InstanceOrValue, cfg *Config) error {
+	_, err := Trim(files, inst, cfg)
+	return err
+}
+
+// Trim is like [Files], but also reports the values which were removed.
+// With [Config.DryRun], the files are left unchanged, and the values
+// which would have been removed are reported instead.
+//
+// A nil cfg is equivalent to a pointer to a zero Config.
+func Trim(files []*ast.File, inst cue.InstanceOrValue, cfg *Config) (*Result, error) {
+	if cfg == nil {
+		cfg = &Config{}
+	}
 	val := inst.Value()
 	version, _ := (*runtime.Runtime)(val.Context()).Settings()
 	if version == internal.EvalV3 {
 		return filesV3(files, val, cfg)
-	} else {
-		return filesV2(files, val, cfg)
 	}
+	if len(cfg.Keep) > 0 || cfg.KeepAttribute != "" {
+		return nil, errors.New("trim: Keep and KeepAttribute require the v3 evaluator")
+	}
+	return filesV2(files, val, cfg)
+}
+
+// newRemoval returns the description of n being removed in favor of
+// replacement, which is nil if n is deleted.
+func newRemoval(n, replacement ast.Node) Removal {
+	r := Removal{Pos: n.Pos()}
+	if b, err := format.Node(n); err == nil {
+		r.Source = string(b)
+	}
+	if replacement != nil {
+		if b, err := format.Node(replacement); err == nil {
+			r.Replacement = string(b)
+		}
+	}
+	return r
 }
@@ -16,10 +16,16 @@ package trim_test
 
 import (
 	"bytes"
+	"path/filepath"
 	"testing"
 
+	"cuelang.org/go/cue"
+	"cuelang.org/go/cue/ast"
 	"cuelang.org/go/cue/build"
+	"cuelang.org/go/cue/cuecontext"
 	"cuelang.org/go/cue/errors"
+	"cuelang.org/go/cue/format"
+	"cuelang.org/go/cue/parser"
 	"cuelang.org/go/internal/cuetdtest"
 	"cuelang.org/go/internal/cuetxtar"
 	"cuelang.org/go/tools/trim"
@@ -87,3 +93,109 @@ func TestTrimFiles(t *testing.T) {
 		}
 	})
 }
+
+func TestTrimOptions(t *testing.T) {
+	const src = `
+#server: {
+	role: string
+	cpus: *1 | int
+	port: 8080
+	tags: [...string]
+}
+servers: [string]: #server
+servers: web: {role: "web", cpus: 1, port: 8080 @keep()}
+servers: db: {role: "db", cpus: 1, port: 8080}
+servers: cache: {role: "cache", cpus: 1, tags: ["a", "b"] & ["a", string]}
+`
+	testCases := []struct {
+		name        string
+		cfg         *trim.Config
+		want        string
+		wantRemoved []string
+	}{{
+		name: "Default",
+		want: `
+#server: {
+	role: string
+	cpus: *1 | int
+	port: 8080
+	tags: [...string]
+}
+servers: [string]: #server
+servers: web: {role: "web"}
+servers: db: {role: "db"}
+servers: cache: {role: "cache", tags: ["a", "b"] & [_, _]}
+`,
+		wantRemoved: []string{"cpus: 1", "port: 8080 @keep()", "cpus: 1", "port: 8080", "cpus: 1", `"a" -> _`, "string -> _"},
+	}, {
+		name: "Conservative",
+		cfg:  &trim.Config{Aggressiveness: trim.Conservative},
+		want: `
+#server: {
+	role: string
+	cpus: *1 | int
+	port: 8080
+	tags: [...string]
+}
+servers: [string]: #server
+servers: web: {role: "web"}
+servers: db: {role: "db"}
+servers: cache: {role: "cache", tags: ["a", "b"] & ["a", string]}
+`,
+		wantRemoved: []string{"cpus: 1", "port: 8080 @keep()", "cpus: 1", "port: 8080", "cpus: 1"},
+	}, {
+		name: "KeepAttributeAndPaths",
+		cfg: &trim.Config{
+			KeepAttribute: "keep",
+			Keep:          []cue.Path{cue.ParsePath("servers.db")},
+		},
+		want: `
+#server: {
+	role: string
+	cpus: *1 | int
+	port: 8080
+	tags: [...string]
+}
+servers: [string]: #server
+servers: web: {role: "web", port: 8080 @keep()}
+servers: db: {role: "db", cpus: 1, port: 8080}
+servers: cache: {role: "cache", tags: ["a", "b"] & [_, _]}
+`,
+		wantRemoved: []string{"cpus: 1", "cpus: 1", `"a" -> _`, "string -> _"},
+	}, {
+		name:        "DryRun",
+		cfg:         &trim.Config{DryRun: true, Aggressiveness: trim.Conservative},
+		want:        src,
+		wantRemoved: []string{"cpus: 1", "port: 8080 @keep()", "cpus: 1", "port: 8080", "cpus: 1"},
+	}}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			// Only files within the directory of the instance are trimmed.
+			dir := t.TempDir()
+			f, err := parser.ParseFile(filepath.Join(dir, "in.cue"), src)
+			qt.Assert(t, qt.IsNil(err))
+			inst := build.NewContext().NewInstance(dir, nil)
+			qt.Assert(t, qt.IsNil(inst.AddSyntax(f)))
+			val := cuecontext.New().BuildInstance(inst)
+			qt.Assert(t, qt.IsNil(val.Err()))
+
+			result, err := trim.Trim([]*ast.File{f}, val, tc.cfg)
+			qt.Assert(t, qt.IsNil(err))
+			var removed []string
+			for _, r := range result.Removed {
+				s := r.Source
+				if r.Replacement != "" {
+					s += " -> " + r.Replacement
+				}
+				removed = append(removed, s)
+			}
+			qt.Check(t, qt.DeepEquals(removed, tc.wantRemoved))
+
+			out, err := format.Node(f)
+			qt.Assert(t, qt.IsNil(err))
+			want, err := format.Source([]byte(tc.want))
+			qt.Assert(t, qt.IsNil(err))
+			qt.Check(t, qt.Equals(string(out), string(want)))
+		})
+	}
+}
@@ -33,7 +33,7 @@ import (
 // as can be derived from the evaluated values in inst.
 // Trimming is done on a best-effort basis and only when the removed field
 // is clearly implied by another field, rather than equal sibling fields.
-func filesV2(files []*ast.File, val cue.Value, cfg *Config) error {
+func filesV2(files []*ast.File, val cue.Value, cfg *Config) (*Result, error) {
 	r, v := value.ToInternal(val)
 
 	t := &trimmerV2{
@@ -72,19 +72,23 @@ func filesV2(files []*ast.File, val cue.Value, cfg *Config) error {
 	t.findSubordinates(d, v, pickedDefault)
 
 	// Remove subordinate values from files.
+	result := &Result{}
 	for _, f := range files {
 		astutil.Apply(f, func(c astutil.Cursor) bool {
 			if f, ok := c.Node().(*ast.Field); ok && t.remove[f.Value] && !t.exclude[f.Value] {
-				c.Delete()
+				result.Removed = append(result.Removed, newRemoval(f, nil))
+				if !cfg.DryRun {
+					c.Delete()
+				}
 			}
 			return true
 		}, nil)
 		if err := astutil.Sanitize(f); err != nil {
-			return err
+			return nil, err
 		}
 	}
 
-	return nil
+	return result, nil
 }
 
 type trimmerV2 struct {
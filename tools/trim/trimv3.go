@@ -147,7 +147,7 @@ import (
 	"cuelang.org/go/internal/value"
 )
 
-func filesV3(files []*ast.File, val cue.Value, cfg *Config) error {
+func filesV3(files []*ast.File, val cue.Value, cfg *Config) (*Result, error) {
 	dir := val.BuildInstance().Dir
 	dir = strings.TrimRight(dir, string(os.PathSeparator)) +
 		string(os.PathSeparator)
@@ -161,9 +161,16 @@ func filesV3(files []*ast.File, val cue.Value, cfg *Config) error {
 	t := &trimmerV3{
 		r:     r,
 		ctx:   ctx,
+		cfg:   cfg,
 		nodes: make(map[ast.Node]*nodeMeta),
 		trace: cfg.TraceWriter,
 	}
+	for _, p := range cfg.Keep {
+		if err := p.Err(); err != nil {
+			return nil, err
+		}
+		t.keep = append(t.keep, p.Selectors())
+	}
 
 	t.logf("\nStarting trim in dir %q with files:", dir)
 	for i, file := range files {
@@ -315,8 +322,12 @@ func (a *nodeMeta) isAncestorOf(b *nodeMeta) bool {
 type trimmerV3 struct {
 	r     *runtime.Runtime
 	ctx   *adt.OpContext
+	cfg   *Config
 	nodes map[ast.Node]*nodeMeta
 
+	// keep holds the selectors of [Config.Keep].
+	keep [][]cue.Selector
+
 	undecided []nodeMetas
 
 	// depth is purely for debugging trace indentation level.
@@ -370,6 +381,7 @@ func (t *trimmerV3) findStaticDependencies(files []*ast.File) {
 
 	var ancestors []*nodeMeta
 	callCount := 0
+	keepCount := 0
 	for _, f := range files {
 		t.logf("%s", f.Filename)
 		ast.Walk(f, func(n ast.Node) bool {
@@ -393,6 +405,13 @@ func (t *trimmerV3) findStaticDependencies(files []*ast.File) {
 			if _, ok := n.(*ast.CallExpr); ok {
 				callCount++
 			}
+			if t.hasKeepAttribute(n) {
+				keepCount++
+			}
+			if keepCount > 0 {
+				t.logf(" keeping because of @%s", t.cfg.KeepAttribute)
+				nm.markRequired()
+			}
 			if callCount > 0 {
 				// This is somewhat unfortunate, but for now, as soon as
 				// we're in the arguments for a function call, we prevent
@@ -409,12 +428,52 @@ func (t *trimmerV3) findStaticDependencies(files []*ast.File) {
 			if _, ok := n.(*ast.CallExpr); ok {
 				callCount--
 			}
+			if t.hasKeepAttribute(n) {
+				keepCount--
+			}
 			ancestors = ancestors[:len(ancestors)-1]
 			t.dec()
 		})
 	}
 }
 
+// hasKeepAttribute reports whether n is a field with the attribute
+// named by [Config.KeepAttribute].
+func (t *trimmerV3) hasKeepAttribute(n ast.Node) bool {
+	field, ok := n.(*ast.Field)
+	if !ok || t.cfg.KeepAttribute == "" {
+		return false
+	}
+	for _, a := range field.Attrs {
+		if name, _ := a.Split(); name == t.cfg.KeepAttribute {
+			return true
+		}
+	}
+	return false
+}
+
+// isKept reports whether the path of v is one of [Config.Keep],
+// or is beneath one of them.
+func (t *trimmerV3) isKept(v *adt.Vertex) bool {
+	if len(t.keep) == 0 {
+		return false
+	}
+	path := v.Path()
+outer:
+	for _, sels := range t.keep {
+		if len(sels) > len(path) {
+			continue
+		}
+		for i, sel := range sels {
+			if sel.String() != path[i].SelectorString(t.r) {
+				continue outer
+			}
+		}
+		return true
+	}
+	return false
+}
+
 // Discovers patterns by walking vertices and their arcs recursively.
 //
 // Conjuncts that originate from the pattern constraint must be
@@ -614,6 +673,11 @@ func (t *trimmerV3) findRedundancies(v *adt.Vertex, keepAll bool) {
 	t.inc()
 	defer t.dec()
 
+	if !keepAll && t.isKept(v) {
+		t.logf("keeping path %v", v.Path())
+		keepAll = true
+	}
+
 	_, isDisjunct := v.BaseValue.(*adt.Disjunction)
 	for _, si := range v.Structs {
 		if src := si.StructLit.Src; src != nil {
@@ -1067,10 +1131,11 @@ func (nms nodeMetas) hasRequired() bool {
 // After all the analysis is complete, trim finally modifies the AST,
 // removing (or simplifying) nodes which have not been found to be
 // required.
-func (t *trimmerV3) trim(files []*ast.File, dir string) error {
+func (t *trimmerV3) trim(files []*ast.File, dir string) (*Result, error) {
 	t.inc()
 	defer t.dec()
 
+	result := &Result{}
 	for _, f := range files {
 		if !strings.HasPrefix(f.Filename, dir) {
 			continue
@@ -1096,6 +1161,15 @@ func (t *trimmerV3) trim(files []*ast.File, dir string) error {
 						}
 					}
 				}
+				if replacement != nil && t.cfg.Aggressiveness == Conservative {
+					t.logf("keeping node %p::%T %v (conservative)", n, n, n.Pos())
+					return true
+				}
+				result.Removed = append(result.Removed, newRemoval(n, replacement))
+				if t.cfg.DryRun {
+					// Don't look for removals within a removed node.
+					return false
+				}
 				if replacement == nil {
 					t.logf("deleting node %p::%T %v", n, n, n.Pos())
 					c.Delete()
@@ -1109,9 +1183,9 @@ func (t *trimmerV3) trim(files []*ast.File, dir string) error {
 			return true
 		}, nil)
 		if err := astutil.Sanitize(f); err != nil {
-			return err
+			return nil, err
 		}
 		t.dec()
 	}
-	return nil
+	return result, nil
 }